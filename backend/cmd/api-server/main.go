@@ -1,12 +1,25 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/chspring1/mya-platform/backend/internal/api/routes"
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/cache"
 	"github.com/chspring1/mya-platform/backend/pkg/config"
 	"github.com/chspring1/mya-platform/backend/pkg/database"
 	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/metrics"
 )
 
 func main() {
@@ -15,16 +28,114 @@ func main() {
 
 	// 初始化日志
 	logger.Init()
+
+	// 配置文件变化时热加载日志级别、限流和RPC地址
+	config.Watch()
 	logger.Info("🚀 Starting MYA Platform API Server")
+	if cfg.Blockchain.Mock.Enabled {
+		logger.Info("🧪 Mock chain mode enabled, no RPC calls will be made")
+	}
+
+	// 初始化数据库，数据库不可用时直接退出而不是带着空连接运行
+	if err := database.Init(models.All()...); err != nil {
+		logger.Error(fmt.Sprintf("Database initialization failed: %v", err))
+		os.Exit(1)
+	}
+
+	// 初始化Redis缓存
+	cache.Init()
+
+	// 核心仓储在这里统一构建后注入各层
+	repos := repository.NewRepositories(database.GetDB())
 
-	// 初始化数据库
-	database.Init()
+	// 预热缓存，完成后才开始接收请求
+	if cfg.Cache.WarmUp {
+		warmCtx, warmCancel := context.WithTimeout(context.Background(), time.Duration(cfg.Cache.WarmUpTimeout)*time.Second)
+		service.WarmCache(warmCtx, repos)
+		warmCancel()
+	}
+
+	// /metrics 在抓取时汇总TVL、APY等业务指标
+	metrics.Registry.MustRegister(service.NewBusinessCollectorWith(repos))
 
 	// 设置并启动Gin服务器
-	router := routes.SetupRouter()
+	router := routes.SetupRouter(repos)
+	server := &http.Server{
+		Addr:         ":" + cfg.Server.Port,
+		Handler:      router,
+		ReadTimeout:  time.Duration(cfg.Server.ReadTimeout) * time.Second,
+		WriteTimeout: time.Duration(cfg.Server.WriteTimeout) * time.Second,
+		IdleTimeout:  time.Duration(cfg.Server.IdleTimeout) * time.Second,
+	}
+
+	redirect, err := configureTLS(server, cfg.Server.TLS)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Invalid TLS configuration: %v", err))
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 2)
+	go func() {
+		var err error
+		if cfg.Server.TLS.Enabled() {
+			logger.Info(fmt.Sprintf("🔒 Server running on port %s (HTTPS)", cfg.Server.Port))
+			// autocert 通过TLSConfig提供证书，此时文件路径为空
+			err = server.ListenAndServeTLS(cfg.Server.TLS.CertFile, cfg.Server.TLS.KeyFile)
+		} else {
+			logger.Info(fmt.Sprintf("🌐 Server running on port %s", cfg.Server.Port))
+			err = server.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+	}()
+
+	servers := []*http.Server{server}
+	if redirect != nil {
+		servers = append(servers, redirect)
+		go func() {
+			logger.Info(fmt.Sprintf("↪️ Redirecting HTTP on port %s to HTTPS", cfg.Server.TLS.HTTPPort))
+			if err := redirect.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				serveErr <- err
+			}
+		}()
+	}
+
+	exitCode := 0
+	select {
+	case <-ctx.Done():
+		logger.Info("🛑 Shutdown signal received, draining connections")
+	case err := <-serveErr:
+		logger.Error(fmt.Sprintf("Server failed: %v", err))
+		exitCode = 1
+	}
+
+	shutdown(servers, time.Duration(cfg.Server.ShutdownTimeout)*time.Second)
+	os.Exit(exitCode)
+}
+
+// shutdown 停止接收新连接并等待进行中的请求，最后关闭数据库、Redis和RPC连接。
+// 定时任务由 cmd/worker 运行
+func shutdown(servers []*http.Server, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	for _, server := range servers {
+		if err := server.Shutdown(ctx); err != nil {
+			logger.Error(fmt.Sprintf("HTTP server %s did not drain in time: %v", server.Addr, err))
+		}
+	}
 
-	logger.Info(fmt.Sprintf("🌐 Server running on port %s", cfg.Server.Port))
+	if err := database.Close(); err != nil {
+		logger.Error(fmt.Sprintf("Failed to close database: %v", err))
+	}
+	if err := cache.Close(); err != nil {
+		logger.Error(fmt.Sprintf("Failed to close Redis: %v", err))
+	}
+	blockchain.Close()
 
-	// 启动服务器
-	router.Run(":" + cfg.Server.Port)
+	logger.Info("👋 Server stopped")
 }
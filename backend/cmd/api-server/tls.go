@@ -0,0 +1,67 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// configureTLS 为server配置证书，返回需要一并启动的HTTP跳转服务器，未启用TLS或未配置http_port时返回nil
+func configureTLS(server *http.Server, cfg config.TLSConfig) (*http.Server, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	_, port, _ := net.SplitHostPort(server.Addr)
+	challenge := redirectHTTPS(port)
+
+	switch {
+	case cfg.Autocert:
+		if len(cfg.Domains) == 0 {
+			return nil, errors.New("server.tls.autocert requires server.tls.domains")
+		}
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.Domains...),
+			Cache:      autocert.DirCache(cfg.CacheDir),
+			Email:      cfg.Email,
+		}
+		server.TLSConfig = manager.TLSConfig()
+		server.TLSConfig.MinVersion = tls.VersionTLS12
+		challenge = manager.HTTPHandler(challenge)
+	case cfg.CertFile != "":
+		if cfg.KeyFile == "" {
+			return nil, errors.New("server.tls.cert_file requires server.tls.key_file")
+		}
+		server.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	if cfg.HTTPPort == "" {
+		return nil, nil
+	}
+	return &http.Server{
+		Addr:              ":" + cfg.HTTPPort,
+		Handler:           challenge,
+		ReadHeaderTimeout: 10 * time.Second,
+		IdleTimeout:       time.Minute,
+	}, nil
+}
+
+// redirectHTTPS 将HTTP请求永久跳转到同一主机HTTPS端口上的相同地址
+func redirectHTTPS(port string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	})
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/worker"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+)
+
+const usage = `Usage: dlq <command>
+
+Commands:
+  list [n]    print the oldest n dead-lettered events as JSON (default 50)
+  replay      republish all dead-lettered events to the events topic`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	cfg := config.Load().Kafka
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch os.Args[1] {
+	case "list":
+		limit := 50
+		if len(os.Args) > 2 {
+			n, err := strconv.Atoi(os.Args[2])
+			if err != nil || n <= 0 {
+				fail(fmt.Errorf("invalid count %q", os.Args[2]))
+			}
+			limit = n
+		}
+		letters, err := worker.ListDeadLetters(ctx, cfg, limit)
+		if err != nil {
+			fail(err)
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(letters); err != nil {
+			fail(err)
+		}
+
+	case "replay":
+		replayed, err := worker.Replay(ctx, cfg, 5*time.Second)
+		fmt.Printf("replayed %d event(s) to %s\n", replayed, cfg.EventsTopic)
+		if err != nil {
+			fail(err)
+		}
+
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "dlq:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/chspring1/mya-platform/backend/migrations"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+)
+
+const usage = `Usage: migrate <command>
+
+Commands:
+  up          apply all pending migrations
+  down [n]    roll back the last n migrations (default 1)
+  status      list migrations and whether they are applied`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	config.Load()
+	logger.Init()
+
+	// 迁移脚本使用Postgres语法，SQLite内存库在启动时按模型建表
+	if config.Load().Database.Driver == database.DriverSQLite {
+		fail(errors.New("migrations only apply to postgres; the sqlite schema is automigrated on startup"))
+	}
+
+	// 迁移命令自己负责执行，避免Init按auto_migrate重复执行
+	config.Load().Database.AutoMigrate = false
+	if err := database.Init(); err != nil {
+		fail(err)
+	}
+	defer database.Close()
+
+	sqlDB, err := database.GetDB().DB()
+	if err != nil {
+		fail(err)
+	}
+
+	migrator, err := migrations.New(sqlDB)
+	if err != nil {
+		fail(err)
+	}
+
+	switch os.Args[1] {
+	case "up":
+		applied, err := migrator.Up()
+		for _, m := range applied {
+			fmt.Printf("applied  %06d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fail(err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+
+	case "down":
+		steps := 1
+		if len(os.Args) > 2 {
+			steps, err = strconv.Atoi(os.Args[2])
+			if err != nil || steps <= 0 {
+				fail(fmt.Errorf("invalid step count %q", os.Args[2]))
+			}
+		}
+		reverted, err := migrator.Down(steps)
+		for _, m := range reverted {
+			fmt.Printf("reverted %06d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fail(err)
+		}
+
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			fail(err)
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%06d_%-40s %s\n", s.Version, s.Name, state)
+		}
+
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "migrate:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"github.com/chspring1/mya-platform/backend/internal/jobs"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+)
+
+// scheduledJobs worker负责的全部定时任务，默认按配置的间隔执行，可在 jobs.schedules 中改为cron表达式。
+// 间隔为0且未配置表达式的任务由jobs.Start跳过
+func scheduledJobs(cfg *config.Config, repos *repository.Repositories) []jobs.Job {
+	// 模拟链上没有可读取的合约资产，改为按脚本曲线更新APY
+	vaultSync := service.NewVaultSyncServiceWith(repos).SyncAll
+	if cfg.Blockchain.Mock.Enabled {
+		vaultSync = service.NewMockChainServiceWith(repos).Tick
+	}
+
+	return []jobs.Job{
+		{
+			Name:     "rebalance",
+			Schedule: jobs.Every(cfg.Rebalance.Interval),
+			Run:      service.NewRebalanceServiceWith(repos).ProposeAll,
+		},
+		{
+			// 策略APY快照，同时同步资金库APY并检查用户提醒
+			Name:     "strategy-snapshot",
+			Schedule: jobs.Every(cfg.Snapshot.Interval),
+			Run:      service.NewStrategyServiceWith(repos).SnapshotAll,
+		},
+		{
+			// 从链上读取资金库和策略资产，校正事件累计的TVL
+			Name:     "vault-sync",
+			Schedule: jobs.Every(cfg.Snapshot.SyncInterval),
+			Run:      vaultSync,
+		},
+		{
+			Name:     "apy-retention",
+			Schedule: jobs.Every(cfg.Retention.Interval),
+			Run:      service.NewRetentionService().Run,
+		},
+		{
+			Name:     "price-history",
+			Schedule: jobs.Every(cfg.Prices.HistoryInterval),
+			Run:      service.NewPriceHistoryService().RecordDaily,
+		},
+		{
+			// 跟踪跨链存款的到账状态
+			Name:     "bridge-tracker",
+			Schedule: jobs.Every(cfg.Bridge.TrackInterval),
+			Run:      service.NewBridgeServiceWith(repos).TrackPending,
+		},
+		{
+			// 从交易回执补齐收获的实际gas成本，用于收益归因
+			Name:     "harvest-gas",
+			Schedule: jobs.Every(cfg.Gas.HarvestInterval),
+			Run:      service.NewHarvestServiceWith(repos).FillGas,
+		},
+		{
+			// 为外部keeper生成收获和再平衡任务
+			Name:     "keeper-jobs",
+			Schedule: jobs.Every(cfg.Keeper.JobInterval),
+			Run:      service.NewKeeperServiceWith(repos).GenerateJobs,
+		},
+		{
+			// 清除用户申请删除的链下个人数据
+			Name:     "data-purge",
+			Schedule: jobs.Every(cfg.Privacy.PurgeInterval),
+			Run:      service.NewPrivacyServiceWith(repos).PurgePending,
+		},
+		{
+			// 把前一天的平台汇总报告加入队列，已生成时跳过
+			Name:     "daily-report",
+			Schedule: jobs.Every(cfg.Reports.Interval),
+			Run:      service.NewReportServiceWith(repos).EnqueueDaily,
+		},
+		{
+			// 按日汇总APY预测各资金库之后7天的APY
+			Name:     "apy-forecast",
+			Schedule: jobs.Every(cfg.Forecast.Interval),
+			Run:      service.NewForecastServiceWith(repos).Run,
+		},
+		{
+			// 按链上状态核对资金库TVL、份额总量和用户份额，差异超过容差时通知运维
+			Name:     "reconciliation",
+			Schedule: jobs.Every(cfg.Reconciliation.Interval),
+			Run:      service.NewReconciliationServiceWith(repos).Run,
+		},
+		{
+			// 记录Uniswap v3集中流动性头寸的区间和手续费增长，用于手续费APR
+			Name:     "uniswap-v3",
+			Schedule: jobs.Every(cfg.UniswapV3.Interval),
+			Run:      service.NewUniswapV3ServiceWith(repos).SnapshotAll,
+		},
+		{
+			// 读取Morpho市场的利率、利用率和预言机价格，更新借贷策略APY
+			Name:     "morpho",
+			Schedule: jobs.Every(cfg.Morpho.Interval),
+			Run:      service.NewMorphoServiceWith(repos).SyncAll,
+		},
+		{
+			// 读取Maker DSR和sDAI兑换率，更新 maker-dsr 策略APY
+			Name:     "dsr",
+			Schedule: jobs.Every(cfg.DSR.Interval),
+			Run:      service.NewDSRServiceWith(repos).SyncAll,
+		},
+		{
+			// 读取Pendle市场隐含利率，更新固定利率策略APY
+			Name:     "pendle",
+			Schedule: jobs.Every(cfg.Pendle.Interval),
+			Run:      service.NewPendleServiceWith(repos).SyncAll,
+		},
+		{
+			// 登记资金库底层资产等代币的链上元数据
+			Name:     "tokens",
+			Schedule: jobs.Every(cfg.Tokens.Interval),
+			Run:      service.NewTokenServiceWith(repos).SyncAll,
+		},
+		{
+			// 对比订阅用户的持仓与同链同资产的其他资金库，生成调仓建议并通知
+			Name:     "rebalance-suggestions",
+			Schedule: jobs.Every(cfg.Suggestions.Interval),
+			Run:      service.NewSuggestionServiceWith(repos).EvaluateAll,
+		},
+		{
+			// 检查数据库、各链索引、价格源和keeper，记录状态页的组件状态变化
+			Name:     "status-check",
+			Schedule: jobs.Every(cfg.Status.Interval),
+			Run:      service.NewStatusServiceWith(repos).Check,
+		},
+		{
+			// 检查Chainlink Automation / Gelato任务的余额、暂停和执行情况
+			Name:     "automation-monitor",
+			Schedule: jobs.Every(cfg.Automation.CheckInterval),
+			Run:      service.NewAutomationServiceWith(repos).CheckAll,
+		},
+	}
+}
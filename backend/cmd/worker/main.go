@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/jobs"
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/queue"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/internal/worker"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/cache"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/events"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+)
+
+func main() {
+	// 初始化配置
+	cfg := config.Load()
+
+	// 初始化日志
+	logger.Init()
+
+	// 配置文件变化时热加载日志级别、限流和RPC地址
+	config.Watch()
+	logger.Info("🚀 Starting MYA Platform worker")
+	if cfg.Blockchain.Mock.Enabled {
+		logger.Info("🧪 Mock chain mode enabled, no RPC calls will be made")
+	}
+
+	if !cfg.Worker.Consumer && !cfg.Worker.Jobs && !cfg.Worker.Queue {
+		logger.Error("Nothing to run: worker.consumer, worker.jobs and worker.queue are all disabled")
+		os.Exit(1)
+	}
+
+	// 事件schema各版本必须兼容，否则拒绝启动
+	if cfg.Worker.Consumer {
+		if err := events.CheckCompatibility(); err != nil {
+			logger.Error(fmt.Sprintf("Event schema check failed: %v", err))
+			os.Exit(1)
+		}
+	}
+
+	// 初始化数据库
+	if err := database.Init(models.All()...); err != nil {
+		logger.Error(fmt.Sprintf("Database initialization failed: %v", err))
+		os.Exit(1)
+	}
+
+	// 初始化Redis缓存，用于物化后失效API缓存和任务分布式锁
+	cache.Init()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// 核心仓储在这里统一构建后注入定时任务、队列和事件消费
+	repos := repository.NewRepositories(database.GetDB())
+
+	// 定时任务和队列使用独立的context，收到退出信号后等当前一轮执行完
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	var running []*sync.WaitGroup
+	if cfg.Worker.Jobs {
+		running = append(running, jobs.Start(jobCtx, scheduledJobs(cfg, repos)...))
+	}
+	if cfg.Worker.Queue {
+		running = append(running, queue.NewWorker(queueHandlers(repos)...).Start(jobCtx))
+	}
+
+	// 消费链上事件并写入交易、持仓和资金库统计
+	var consumer *worker.Consumer
+	consumerErr := make(chan error, 1)
+	if cfg.Worker.Consumer {
+		consumer = worker.NewConsumer(cfg.Kafka, service.NewEventServiceWith(repos).Apply)
+		go func() {
+			consumerErr <- consumer.Run(ctx)
+		}()
+	}
+
+	exitCode := 0
+	select {
+	case <-ctx.Done():
+		logger.Info("🛑 Shutdown signal received, stopping worker")
+		if consumer != nil {
+			// 当前消息处理完(或放弃提交)后Run返回
+			<-consumerErr
+		}
+	case err := <-consumerErr:
+		if err != nil {
+			logger.Error(fmt.Sprintf("Event consumer stopped: %v", err))
+			exitCode = 1
+		}
+	}
+
+	if consumer != nil {
+		if err := consumer.Close(); err != nil {
+			logger.Error(fmt.Sprintf("Failed to close event consumer: %v", err))
+		}
+	}
+	shutdown(stopJobs, running, time.Duration(cfg.Worker.ShutdownTimeout)*time.Second)
+	os.Exit(exitCode)
+}
+
+// shutdown 停止定时任务和队列并等待正在执行的任务结束，最后关闭数据库、Redis和RPC连接
+func shutdown(stopJobs context.CancelFunc, running []*sync.WaitGroup, timeout time.Duration) {
+	stopJobs()
+	done := make(chan struct{})
+	go func() {
+		for _, wg := range running {
+			wg.Wait()
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		logger.Error("Background jobs did not stop in time")
+	}
+
+	if err := database.Close(); err != nil {
+		logger.Error(fmt.Sprintf("Failed to close database: %v", err))
+	}
+	if err := cache.Close(); err != nil {
+		logger.Error(fmt.Sprintf("Failed to close Redis: %v", err))
+	}
+	blockchain.Close()
+
+	logger.Info("Worker stopped")
+}
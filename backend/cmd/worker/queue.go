@@ -0,0 +1,38 @@
+package main
+
+import (
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/queue"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/internal/service"
+)
+
+// queueHandlers worker执行的队列任务类型，未注册的类型留在队列中不会被领取
+func queueHandlers(repos *repository.Repositories) []queue.Handler {
+	return []queue.Handler{
+		{
+			// 生成并推送某天的平台汇总报告
+			Kind: service.QueueReportGenerate,
+			Run:  service.NewReportServiceWith(repos).RunQueued,
+		},
+		{
+			// 投递用户webhook通知，重试沿用同一 delivery_id
+			Kind:    service.QueueWebhookDeliver,
+			Timeout: time.Minute,
+			Run:     service.NewNotificationServiceWith(repos).RunQueuedWebhook,
+		},
+		{
+			// 回放资金库历史事件，按区块段写断点，可能持续数小时
+			Kind:    service.QueueBackfill,
+			Timeout: 6 * time.Hour,
+			Run:     service.NewBackfillServiceWith(repos).RunQueued,
+		},
+		{
+			// 私有通道提交的运维交易超时未上链时广播到公共内存池
+			Kind:    service.QueueRelayFallback,
+			Timeout: time.Minute,
+			Run:     service.RunQueuedRelayFallback,
+		},
+	}
+}
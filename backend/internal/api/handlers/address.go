@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/chspring1/mya-platform/backend/pkg/address"
+
+	"github.com/gin-gonic/gin"
+)
+
+// normalizeAddress 校验请求体或查询参数中的地址并转为小写，格式错误时直接写入错误响应
+func normalizeAddress(c *gin.Context, field, value string) (string, bool) {
+	normalized, err := address.Normalize(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   fmt.Sprintf("Invalid %s", field),
+			"details": err.Error(),
+		})
+		return "", false
+	}
+	return normalized, true
+}
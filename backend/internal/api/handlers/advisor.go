@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Advise 按风险偏好(conservative/balanced/aggressive)把一笔美元金额分配到多个资金库，
+// 返回各资金库的金额、预期的加权净APY和组合的风险指标
+func (h *Handlers) Advise(c *gin.Context) {
+	var req AdvisorRequest
+	if !bindJSON(c, &req, "Invalid advisor request") {
+		return
+	}
+
+	portfolio, err := h.advisorService.Advise(c.Request.Context(), req.RiskTolerance, req.Amount, req.Assets, req.ChainID)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{
+			"portfolio": portfolio,
+		})
+	case errors.Is(err, service.ErrUnknownRiskTolerance):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrRouteAssetUnknown):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		logger.Error(fmt.Sprintf("Failed to build %s portfolio: %v", req.RiskTolerance, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to build portfolio",
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// allowlistVault 获取管理白名单的目标资金库，不存在时直接写入错误响应
+func (h *Handlers) allowlistVault(c *gin.Context) (*models.Vault, bool) {
+	vault, err := h.vaultService.GetVaultDetail(c.Request.Context(), c.Param("address"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch vault details",
+		})
+		return nil, false
+	}
+	if vault == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Vault not found",
+		})
+		return nil, false
+	}
+	return vault, true
+}
+
+// GetVaultAllowlist 获取资金库的存款白名单
+func (h *Handlers) GetVaultAllowlist(c *gin.Context) {
+	vault, ok := h.allowlistVault(c)
+	if !ok {
+		return
+	}
+
+	entries, err := h.allowlistService.List(vault)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch allowlist",
+		})
+		return
+	}
+
+	body := listed(c, entries, len(entries), len(entries))
+	body["vault"] = vault.Address
+	body["enabled"] = vault.AllowlistEnabled
+	c.JSON(http.StatusOK, body)
+}
+
+// SetVaultAllowlist 开启或关闭资金库的存款白名单
+func (h *Handlers) SetVaultAllowlist(c *gin.Context) {
+	var req SetAllowlistRequest
+	if !bindJSON(c, &req, "Invalid allowlist request") {
+		return
+	}
+
+	vault, ok := h.allowlistVault(c)
+	if !ok {
+		return
+	}
+
+	vault, err := h.allowlistService.SetEnabled(c.Request.Context(), vault, c.GetString("admin_address"), *req.Enabled, req.Reason)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to set allowlist of vault %s: %v", c.Param("address"), err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update allowlist",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"vault": h.vaultService.WithUSD(c.Request.Context(), vault),
+	})
+}
+
+// AddVaultAllowlist 把地址加入资金库的存款白名单
+func (h *Handlers) AddVaultAllowlist(c *gin.Context) {
+	var req AddAllowlistRequest
+	if !bindJSON(c, &req, "Invalid allowlist request") {
+		return
+	}
+	addresses := make([]string, len(req.Addresses))
+	for i, address := range req.Addresses {
+		addresses[i] = strings.ToLower(address)
+	}
+
+	vault, ok := h.allowlistVault(c)
+	if !ok {
+		return
+	}
+
+	added, err := h.allowlistService.Add(vault, c.GetString("admin_address"), addresses, req.Note)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to add allowlist entries to vault %s: %v", vault.Address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update allowlist",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"vault": vault.Address,
+		"added": added,
+	})
+}
+
+// RemoveVaultAllowlist 从资金库的存款白名单移除地址
+func (h *Handlers) RemoveVaultAllowlist(c *gin.Context) {
+	user, ok := normalizeAddress(c, "user address", c.Param("user"))
+	if !ok {
+		return
+	}
+
+	vault, ok := h.allowlistVault(c)
+	if !ok {
+		return
+	}
+
+	removed, err := h.allowlistService.Remove(vault, c.GetString("admin_address"), user)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to remove %s from allowlist of vault %s: %v", user, vault.Address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update allowlist",
+		})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Address is not on the allowlist",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAutomationTasks 列出登记的Chainlink Automation / Gelato任务，?vault= 按资金库过滤
+func (h *Handlers) GetAutomationTasks(c *gin.Context) {
+	vault := c.Query("vault")
+	if vault != "" {
+		var ok bool
+		if vault, ok = normalizeAddress(c, "vault", vault); !ok {
+			return
+		}
+	}
+
+	tasks, err := h.automationService.List(vault)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch automation tasks",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, listed(c, tasks, len(tasks), len(tasks)))
+}
+
+// RegisterAutomationTask 登记平台上已创建的任务，登记时读取一次链上状态
+func (h *Handlers) RegisterAutomationTask(c *gin.Context) {
+	var req RegisterAutomationRequest
+	if !bindJSON(c, &req, "Invalid automation task request") {
+		return
+	}
+
+	task, err := h.automationService.Register(c.Request.Context(), c.GetString("admin_address"), service.AutomationTaskRequest{
+		Provider:        req.Provider,
+		TaskID:          req.TaskID,
+		Action:          req.Action,
+		VaultAddress:    strings.ToLower(req.VaultAddress),
+		StrategyAddress: strings.ToLower(req.StrategyAddress),
+		Interval:        req.Interval,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAutomationVaultNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Vault not found"})
+		case errors.Is(err, service.ErrAutomationTaskExists):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrAutomationInvalidTask),
+			errors.Is(err, service.ErrAutomationStrategy):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrAutomationNotConfigured),
+			errors.Is(err, service.ErrAutomationTaskUnknown):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			logger.Error(fmt.Sprintf("Failed to register %s task %s: %v", req.Provider, req.TaskID, err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register automation task"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"task": task,
+	})
+}
+
+// DeleteAutomationTask 取消登记，不影响平台上的任务本身
+func (h *Handlers) DeleteAutomationTask(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid task id",
+		})
+		return
+	}
+
+	if err := h.automationService.Remove(c.GetString("admin_address"), uint(id)); err != nil {
+		if errors.Is(err, service.ErrAutomationTaskNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Automation task not found"})
+			return
+		}
+		logger.Error(fmt.Sprintf("Failed to remove automation task %d: %v", id, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to remove automation task",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BatchDeposit 批量存款：一次签名把存款按权重拆分到多个资金库，返回合并报价和一笔 multicall 交易或按顺序发送的多笔交易
+func (h *Handlers) BatchDeposit(c *gin.Context) {
+	userAddress := c.GetString("user_address")
+
+	var req BatchDepositRequest
+	if !bindJSON(c, &req, "Invalid batch deposit request") {
+		return
+	}
+	if (req.Amount == nil) == (req.AmountWei == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": service.ErrInvalidAmount.Error()})
+		return
+	}
+	allocations := make([]service.IntentAllocation, len(req.Allocations))
+	for i, allocation := range req.Allocations {
+		allocations[i] = service.IntentAllocation{Vault: strings.ToLower(allocation.Vault), WeightBps: allocation.WeightBps}
+	}
+	if err := service.CheckAllocations(allocations); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	intent := service.Intent{
+		Kind:        service.IntentBatchDeposit,
+		Amount:      req.Amount.text(),
+		AmountWei:   req.AmountWei,
+		Allocations: allocations,
+		SlippageBps: req.SlippageBps,
+		Deadline:    req.Deadline,
+		Nonce:       req.Nonce,
+		ExpiresAt:   req.ExpiresAt,
+		Signature:   req.Signature,
+	}
+	if !h.verifyIntent(c, userAddress, intent) {
+		return
+	}
+
+	vaults := make([]*models.Vault, len(allocations))
+	for i, allocation := range allocations {
+		vault, err := h.vaultService.GetVaultDetail(c.Request.Context(), allocation.Vault)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch vault details",
+			})
+			return
+		}
+		if vault == nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Vault not found",
+				"vault": allocation.Vault,
+			})
+			return
+		}
+		if !h.checkDepositAllowed(c, vault, userAddress) {
+			return
+		}
+		vaults[i] = vault
+	}
+	amount, err := h.amountService.Assets(c.Request.Context(), vaults[0], req.Amount.value(), req.AmountWei)
+	if !h.checkAmount(c, err) {
+		return
+	}
+
+	quote, err := h.batchDepositService.Plan(c.Request.Context(), vaults, allocations, amount, req.SlippageBps)
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrBatchMixedVaults),
+		errors.Is(err, service.ErrBatchTooSmall),
+		errors.Is(err, service.ErrInvalidSlippage),
+		errors.Is(err, service.ErrPreviewPrecision):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	default:
+		logger.Error(fmt.Sprintf("Failed to quote batch deposit of %s: %v", userAddress, err))
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to preview deposits on chain"})
+		return
+	}
+	// 任何一个资金库超过剩余额度时整批拒绝，批量存款不做部分截断
+	for i, vault := range vaults {
+		if remaining := vault.RemainingCapacity(); remaining != nil && quote.Legs[i].Amount.GreaterThan(*remaining) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":              "Deposit exceeds vault capacity",
+				"vault":              vault.Address,
+				"remaining_capacity": remaining,
+			})
+			return
+		}
+	}
+
+	batch, err := h.batchDepositService.Prepare(vaults, userAddress, quote, intent)
+	if err != nil {
+		if errors.Is(err, service.ErrDeadlineUnsupported) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrNonceUsed) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "nonce_used"})
+			return
+		}
+		logger.Error(fmt.Sprintf("Failed to prepare batch deposit of %s: %v", userAddress, err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to prepare batch deposit"})
+		return
+	}
+
+	// 模拟链模式下各笔存款立即确认
+	if blockchain.MockEnabled() {
+		for i, vault := range vaults {
+			event, err := h.mockChainService.Deposit(c.Request.Context(), vault, userAddress, quote.Legs[i].Amount)
+			if err != nil {
+				logger.Error(fmt.Sprintf("Mock deposit to %s failed: %v", vault.Address, err))
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process deposit"})
+				return
+			}
+			shareDecimals, err := h.amountService.ShareDecimals(c.Request.Context(), vault)
+			if !h.checkAmount(c, err) {
+				return
+			}
+			h.settleMockIntent(&batch.Intents[i], event, event.Shares, shareDecimals)
+		}
+		if settled, err := h.batchDepositService.Get(userAddress, batch.ID); err == nil {
+			batch.Status = settled.Status
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"batch":    batch,
+		"amount":   amount.Value.String(),
+		"decimals": amount.Decimals,
+	})
+}
+
+// GetBatchDeposit 查看批量存款的整体状态和各资金库的意向
+func (h *Handlers) GetBatchDeposit(c *gin.Context) {
+	batch, err := h.batchDepositService.Get(c.GetString("user_address"), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrBatchNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch batch deposit",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"batch": batch,
+	})
+}
+
+// ReportBatchExecution 上报批量存款的执行交易，multicall 交易一次结束全部意向，按顺序发送时逐笔上报。
+// 任何一笔违反最少份额或 deadline 时批量状态为 rejected
+func (h *Handlers) ReportBatchExecution(c *gin.Context) {
+	id := c.Param("id")
+
+	var req ReportIntentRequest
+	if !bindJSON(c, &req, "Invalid execution report") {
+		return
+	}
+
+	batch, err := h.batchDepositService.Report(c.Request.Context(), c.GetString("user_address"), id, strings.ToLower(req.TxHash))
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"batch": batch})
+	case errors.Is(err, service.ErrBatchNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTxPending):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTxMismatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		logger.Error(fmt.Sprintf("Failed to verify execution of batch %s: %v", id, err))
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to verify transaction on chain"})
+	}
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/pkg/bridge"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bridgeVault 获取跨链存款的目标资金库并检查是否接受该用户存款，不满足时直接写入错误响应
+func (h *Handlers) bridgeVault(c *gin.Context, userAddress string) (*models.Vault, bool) {
+	vault, err := h.vaultService.GetVaultDetail(c.Request.Context(), c.Param("address"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch vault details",
+		})
+		return nil, false
+	}
+	if vault == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Vault not found",
+		})
+		return nil, false
+	}
+	return vault, h.checkDepositAllowed(c, vault, userAddress)
+}
+
+// GetBridgeQuote 报价从其他链跨链存入资金库的路线：跨链交易、费用、预计到账时间和到账后的存款预览
+func (h *Handlers) GetBridgeQuote(c *gin.Context) {
+	userAddress := c.GetString("user_address")
+
+	var req BridgeQuoteRequest
+	if !bindJSON(c, &req, "Invalid bridge quote request") {
+		return
+	}
+
+	vault, ok := h.bridgeVault(c, userAddress)
+	if !ok {
+		return
+	}
+
+	quote, err := h.bridgeService.Quote(c.Request.Context(), vault, userAddress, req.FromChainID, strings.ToLower(req.FromToken), req.Amount, req.SlippageBps)
+	switch {
+	case err == nil:
+	case errors.Is(err, service.ErrBridgeSameChain), errors.Is(err, service.ErrBridgeUnsupported),
+		errors.Is(err, service.ErrInvalidSlippage), errors.Is(err, service.ErrPreviewPrecision):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	case errors.Is(err, bridge.ErrNoRoute):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": "No bridge route found for this token and amount",
+		})
+		return
+	default:
+		logger.Error(fmt.Sprintf("Failed to quote bridge into vault %s: %v", vault.Address, err))
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error": "Failed to fetch bridge quote",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"quote": quote,
+	})
+}
+
+// TrackBridgeTransaction 登记用户在源链提交的跨链交易，到账状态由后台任务更新
+func (h *Handlers) TrackBridgeTransaction(c *gin.Context) {
+	userAddress := c.GetString("user_address")
+
+	var req TrackBridgeRequest
+	if !bindJSON(c, &req, "Invalid bridge transaction") {
+		return
+	}
+
+	vault, ok := h.bridgeVault(c, userAddress)
+	if !ok {
+		return
+	}
+
+	transaction, err := h.bridgeService.Track(c.Request.Context(), vault, userAddress, req.FromChainID, strings.ToLower(req.TxHash), req.Bridge, req.Amount)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrBridgeSameChain), errors.Is(err, service.ErrBridgeUnsupported),
+			errors.Is(err, service.ErrBridgeSource):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrBridgeMismatch), errors.Is(err, service.ErrTxPending):
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			logger.Error(fmt.Sprintf("Failed to track bridge %s: %v", req.TxHash, err))
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to record bridge transaction",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"transaction": transaction,
+	})
+}
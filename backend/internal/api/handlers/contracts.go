@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/pkg/contracts"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetContracts 列出登记的合约(不含ABI正文)，?chain_id= 和 ?interface= 过滤
+func (h *Handlers) GetContracts(c *gin.Context) {
+	chainID, err := strconv.ParseUint(c.DefaultQuery("chain_id", "0"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid chain_id",
+		})
+		return
+	}
+	iface := c.Query("interface")
+	if iface != "" && !contracts.ValidInterface(iface) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "interface must be one of erc4626, strategy, oracle",
+		})
+		return
+	}
+
+	list, err := h.contractService.List(uint(chainID), iface)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch contracts",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, listed(c, list, len(list), len(list)))
+}
+
+// GetContract 获取登记的合约及其ABI
+func (h *Handlers) GetContract(c *gin.Context) {
+	chainID, ok := contractChainID(c)
+	if !ok {
+		return
+	}
+
+	contract, err := h.contractService.Get(chainID, c.Param("address"))
+	if err != nil {
+		if errors.Is(err, service.ErrContractNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Contract not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch contract",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"contract": contract,
+	})
+}
+
+// RegisterContract 上传合约ABI、接口类型和部署区块，已登记时整体覆盖
+func (h *Handlers) RegisterContract(c *gin.Context) {
+	chainID, ok := contractChainID(c)
+	if !ok {
+		return
+	}
+	var req RegisterContractRequest
+	if !bindJSON(c, &req, "Invalid contract request") {
+		return
+	}
+
+	address := c.Param("address")
+	contract, err := h.contractService.Register(c.GetString("admin_address"), service.ContractUpload{
+		ChainID:         chainID,
+		Address:         address,
+		Interface:       req.Interface,
+		Name:            req.Name,
+		ABI:             req.ABI,
+		DeploymentBlock: req.DeploymentBlock,
+	})
+	if err != nil {
+		if errors.Is(err, contracts.ErrInvalidABI) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Error(fmt.Sprintf("Failed to register contract %s on chain %d: %v", address, chainID, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to register contract",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"contract": contract,
+	})
+}
+
+// DeleteContract 删除登记，之后该合约按内置ABI解码
+func (h *Handlers) DeleteContract(c *gin.Context) {
+	chainID, ok := contractChainID(c)
+	if !ok {
+		return
+	}
+
+	address := c.Param("address")
+	if err := h.contractService.Remove(c.GetString("admin_address"), chainID, address); err != nil {
+		if errors.Is(err, service.ErrContractNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Contract not found"})
+			return
+		}
+		logger.Error(fmt.Sprintf("Failed to remove contract %s on chain %d: %v", address, chainID, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to remove contract",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"removed": true,
+	})
+}
+
+func contractChainID(c *gin.Context) (uint, bool) {
+	chainID, err := strconv.ParseUint(c.Param("chain_id"), 10, 64)
+	if err != nil || chainID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid chain_id",
+		})
+		return 0, false
+	}
+	return uint(chainID), true
+}
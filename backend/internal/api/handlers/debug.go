@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 进程启动时间，用于计算运行时长
+var startedAt = time.Now()
+
+// GetRuntimeStats 获取进程运行时信息：goroutine数量、堆内存和最近的GC暂停
+func (h *Handlers) GetRuntimeStats(c *gin.Context) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	// PauseNs 是环形缓冲区，最近一次GC位于 (NumGC+255)%256
+	recent := int(mem.NumGC)
+	if recent > 10 {
+		recent = 10
+	}
+	pauses := make([]string, 0, recent)
+	for i := 0; i < recent; i++ {
+		idx := (int(mem.NumGC) - 1 - i + len(mem.PauseNs)) % len(mem.PauseNs)
+		pauses = append(pauses, time.Duration(mem.PauseNs[idx]).String())
+	}
+
+	var lastGC *time.Time
+	if mem.LastGC > 0 {
+		t := time.Unix(0, int64(mem.LastGC))
+		lastGC = &t
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"runtime": gin.H{
+			"go_version":     runtime.Version(),
+			"uptime":         time.Since(startedAt).Round(time.Second).String(),
+			"num_cpu":        runtime.NumCPU(),
+			"gomaxprocs":     runtime.GOMAXPROCS(0),
+			"num_goroutine":  runtime.NumGoroutine(),
+			"num_cgo_call":   runtime.NumCgoCall(),
+			"heap_alloc":     mem.HeapAlloc,
+			"heap_inuse":     mem.HeapInuse,
+			"heap_idle":      mem.HeapIdle,
+			"heap_released":  mem.HeapReleased,
+			"heap_objects":   mem.HeapObjects,
+			"heap_sys":       mem.HeapSys,
+			"total_alloc":    mem.TotalAlloc,
+			"sys":            mem.Sys,
+			"next_gc":        mem.NextGC,
+			"num_gc":         mem.NumGC,
+			"gc_cpu_percent": mem.GCCPUFraction * 100,
+			"pause_total":    time.Duration(mem.PauseTotalNs).String(),
+			"recent_pauses":  pauses,
+			"last_gc":        lastGC,
+		},
+	})
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetVaultFees 设置资金库的管理费和业绩费，只影响之后的收获
+func (h *Handlers) SetVaultFees(c *gin.Context) {
+	vaultAddress := c.Param("address")
+
+	var req SetVaultFeesRequest
+	if !bindJSON(c, &req, "Invalid vault fees request") {
+		return
+	}
+
+	vault, err := h.vaultService.GetVaultDetail(c.Request.Context(), vaultAddress)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch vault details",
+		})
+		return
+	}
+	if vault == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Vault not found",
+		})
+		return
+	}
+
+	vault, err = h.feeService.SetFees(c.Request.Context(), vault, c.GetString("admin_address"), *req.ManagementFeeBps, *req.PerformanceFeeBps, req.Reason)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidFees) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		logger.Error(fmt.Sprintf("Failed to set fees of vault %s: %v", vaultAddress, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to set vault fees",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"vault": vault,
+	})
+}
+
+// GetRevenue 协议费用收入报表，默认最近30天按天汇总，?period=day|week|month
+func (h *Handlers) GetRevenue(c *gin.Context) {
+	from, to, ok := parseTimeRange(c, 30*24*time.Hour)
+	if !ok {
+		return
+	}
+
+	revenue, err := h.feeService.Revenue(from, to, c.DefaultQuery("period", "day"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPeriod) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		logger.Error(fmt.Sprintf("Failed to get revenue: %v", err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch revenue",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, revenue)
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/prices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fxQuote ?currency=参数对应的汇率信息
+type fxQuote struct {
+	Currency string  `json:"currency"`
+	Rate     float64 `json:"rate"`
+	Date     string  `json:"date"`
+}
+
+// parseCurrency 解析?currency=参数，未指定时返回nil；不支持或汇率不可用时直接写入错误响应
+func (h *Handlers) parseCurrency(c *gin.Context) (*fxQuote, bool) {
+	currency := strings.ToUpper(c.Query("currency"))
+	if currency == "" {
+		return nil, true
+	}
+
+	if !prices.IsSupported(currency) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("currency must be one of %s", strings.Join(prices.SupportedCurrencies, ", ")),
+		})
+		return nil, false
+	}
+
+	rate, date, err := h.fxService.Rate(c.Request.Context(), currency)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get FX rate for %s: %v", currency, err))
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "FX rate temporarily unavailable",
+		})
+		return nil, false
+	}
+
+	return &fxQuote{Currency: currency, Rate: rate, Date: date}, true
+}
@@ -1,24 +1,135 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
 	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
 	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/prices"
+	"github.com/chspring1/mya-platform/backend/pkg/rates"
+	"github.com/chspring1/mya-platform/backend/pkg/signature"
+	"github.com/chspring1/mya-platform/backend/pkg/units"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Handlers struct {
-	vaultService *service.VaultService
-	userService  *service.UserService
+	vaultService          *service.VaultService
+	userService           *service.UserService
+	rebalanceService      *service.RebalanceService
+	strategyService       *service.StrategyService
+	simulationService     *service.SimulationService
+	priceService          *prices.Service
+	positionService       *service.PositionService
+	statsService          *service.StatsService
+	priceHistoryService   *service.PriceHistoryService
+	fxService             *prices.FXService
+	notificationService   *service.NotificationService
+	monitoringService     *service.MonitoringService
+	healthService         *service.HealthService
+	vaultControlService   *service.VaultControlService
+	feeService            *service.FeeService
+	allowlistService      *service.AllowlistService
+	previewService        *service.PreviewService
+	bridgeService         *service.BridgeService
+	gasService            *service.GasService
+	harvestService        *service.HarvestService
+	lpService             *service.LPService
+	keeperService         *service.KeeperService
+	automationService     *service.AutomationService
+	privacyService        *service.PrivacyService
+	watchlistService      *service.WatchlistService
+	reportService         *service.ReportService
+	searchService         *service.SearchService
+	mockChainService      *service.MockChainService
+	intentService         *service.IntentService
+	screeningService      *service.ScreeningService
+	reconciliationService *service.ReconciliationService
+	ledgerService         *service.LedgerService
+	forecastService       *service.ForecastService
+	jobService            *service.JobService
+	queueService          *service.QueueService
+	backfillService       *service.BackfillService
+	contractService       *service.ContractService
+	uniswapV3Service      *service.UniswapV3Service
+	morphoService         *service.MorphoService
+	pendleService         *service.PendleService
+	metadataService       *service.MetadataService
+	tokenService          *service.TokenService
+	amountService         *service.AmountService
+	exitPreviewService    *service.ExitPreviewService
+	quoteService          *service.QuoteService
+	zapService            *service.ZapService
+	batchDepositService   *service.BatchDepositService
+	routingService        *service.RoutingService
+	advisorService        *service.AdvisorService
+	suggestionService     *service.SuggestionService
+	statusService         *service.StatusService
+	incidentService       *service.IncidentService
 }
 
-func NewHandlers() *Handlers {
+// NewHandlers 构建全部处理器，使用核心仓储的服务都通过repos注入
+func NewHandlers(repos *repository.Repositories) *Handlers {
 	return &Handlers{
-		vaultService: service.NewVaultService(),
-		userService:  service.NewUserService(),
+		vaultService:          service.NewVaultServiceWith(repos),
+		userService:           service.NewUserServiceWith(repos),
+		rebalanceService:      service.NewRebalanceServiceWith(repos),
+		strategyService:       service.NewStrategyServiceWith(repos),
+		simulationService:     service.NewSimulationService(),
+		priceService:          prices.Default(),
+		positionService:       service.NewPositionServiceWith(repos),
+		statsService:          service.NewStatsServiceWith(repos),
+		priceHistoryService:   service.NewPriceHistoryService(),
+		fxService:             prices.DefaultFX(),
+		notificationService:   service.NewNotificationServiceWith(repos),
+		monitoringService:     service.NewMonitoringService(),
+		healthService:         service.NewHealthService(),
+		vaultControlService:   service.NewVaultControlServiceWith(repos),
+		feeService:            service.NewFeeServiceWith(repos),
+		allowlistService:      service.NewAllowlistServiceWith(repos),
+		previewService:        service.NewPreviewService(),
+		bridgeService:         service.NewBridgeServiceWith(repos),
+		gasService:            service.NewGasServiceWith(repos),
+		harvestService:        service.NewHarvestServiceWith(repos),
+		lpService:             service.NewLPServiceWith(repos),
+		keeperService:         service.NewKeeperServiceWith(repos),
+		automationService:     service.NewAutomationServiceWith(repos),
+		privacyService:        service.NewPrivacyServiceWith(repos),
+		watchlistService:      service.NewWatchlistServiceWith(repos),
+		reportService:         service.NewReportServiceWith(repos),
+		searchService:         service.NewSearchServiceWith(repos),
+		mockChainService:      service.NewMockChainServiceWith(repos),
+		intentService:         service.NewIntentServiceWith(repos),
+		screeningService:      service.NewScreeningService(),
+		reconciliationService: service.NewReconciliationServiceWith(repos),
+		ledgerService:         service.NewLedgerService(),
+		forecastService:       service.NewForecastServiceWith(repos),
+		jobService:            service.NewJobService(),
+		queueService:          service.NewQueueService(),
+		backfillService:       service.NewBackfillServiceWith(repos),
+		contractService:       service.NewContractService(),
+		uniswapV3Service:      service.NewUniswapV3ServiceWith(repos),
+		morphoService:         service.NewMorphoServiceWith(repos),
+		pendleService:         service.NewPendleServiceWith(repos),
+		metadataService:       service.NewMetadataService(),
+		tokenService:          service.NewTokenServiceWith(repos),
+		amountService:         service.NewAmountServiceWith(repos),
+		exitPreviewService:    service.NewExitPreviewServiceWith(repos),
+		quoteService:          service.NewQuoteServiceWith(repos),
+		zapService:            service.NewZapService(),
+		batchDepositService:   service.NewBatchDepositServiceWith(repos),
+		routingService:        service.NewRoutingServiceWith(repos),
+		advisorService:        service.NewAdvisorServiceWith(repos),
+		suggestionService:     service.NewSuggestionServiceWith(repos),
+		statusService:         service.NewStatusServiceWith(repos),
+		incidentService:       service.NewIncidentServiceWith(repos),
 	}
 }
 
@@ -32,9 +143,60 @@ func (h *Handlers) HealthCheck(c *gin.Context) {
 	})
 }
 
+// Liveness 存活探针，进程能处理请求即返回200，不检查外部依赖
+func (h *Handlers) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": "alive",
+	})
+}
+
+// Readiness 就绪探针，任一依赖不可用时返回503，编排系统据此暂停向该实例转发流量
+func (h *Handlers) Readiness(c *gin.Context) {
+	readiness := h.healthService.Readiness(c.Request.Context())
+
+	status, code := "ready", http.StatusOK
+	if !readiness.Ready {
+		status, code = "not_ready", http.StatusServiceUnavailable
+	}
+	c.JSON(code, gin.H{
+		"status": status,
+		"checks": readiness.Checks,
+	})
+}
+
 // GetVaults 获取所有资金库
 func (h *Handlers) GetVaults(c *gin.Context) {
-	vaults, err := h.vaultService.GetVaults()
+	fx, ok := h.parseCurrency(c)
+	if !ok {
+		return
+	}
+
+	rateType := c.Query("rate_type")
+	if rateType != "" && !service.ValidRateType(rateType) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Invalid rate_type %q", rateType),
+		})
+		return
+	}
+	category := c.Query("category")
+	if category != "" && !models.ValidVaultCategory(category) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Invalid category %q", category),
+		})
+		return
+	}
+	var tags []string
+	if raw := c.Query("tags"); raw != "" {
+		var err error
+		if tags, err = service.NormalizeVaultTags(strings.Split(raw, ",")); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
+
+	vaults, err := h.vaultService.GetVaults(c.Request.Context())
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to get vaults: %v", err))
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -43,16 +205,38 @@ func (h *Handlers) GetVaults(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"vaults": vaults,
-	})
+	views := h.vaultService.WithUSDList(c.Request.Context(), vaults)
+	if rateType != "" || category != "" || len(tags) > 0 {
+		filtered := views[:0]
+		for _, view := range views {
+			if (rateType == "" || view.RateType == rateType) &&
+				(category == "" || view.Category == category) && view.HasTags(tags) {
+				filtered = append(filtered, view)
+			}
+		}
+		views = filtered
+	}
+	if fx != nil {
+		for i := range views {
+			views[i].ApplyFX(fx.Rate)
+		}
+	}
+
+	body := listed(c, views, len(views), len(views))
+	body["fx"] = fx
+	c.JSON(http.StatusOK, body)
 }
 
 // GetVaultDetail 获取资金库详情
 func (h *Handlers) GetVaultDetail(c *gin.Context) {
 	address := c.Param("address")
 
-	vault, err := h.vaultService.GetVaultDetail(address)
+	fx, ok := h.parseCurrency(c)
+	if !ok {
+		return
+	}
+
+	vault, err := h.vaultService.GetVaultDetail(c.Request.Context(), address)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to get vault detail for %s: %v", address, err))
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -68,44 +252,73 @@ func (h *Handlers) GetVaultDetail(c *gin.Context) {
 		return
 	}
 
+	view := h.vaultService.WithUSD(c.Request.Context(), vault)
+	if fx != nil {
+		view.ApplyFX(fx.Rate)
+	}
+
+	// 预测只是附加信息，读取失败时不影响详情
+	forecast, err := h.forecastService.Get(vault.Address)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get APY forecast for %s: %v", vault.Address, err))
+	}
+
+	// 展示资料同样不影响详情，读取失败时返回空
+	metadata, err := h.metadataService.Get(vault.Address)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get display metadata for %s: %v", vault.Address, err))
+	}
+	strategyMetadata, err := h.metadataService.ForStrategies(vault.Strategies)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get strategy display metadata for %s: %v", vault.Address, err))
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"vault": vault,
+		"vault":             view,
+		"allocations":       h.vaultService.GetAllocations(vault),
+		"fx":                fx,
+		"apy_forecast":      forecast,
+		"metadata":          metadata,
+		"strategy_metadata": strategyMetadata,
 	})
 }
 
 // GetStrategies 获取所有策略
 func (h *Handlers) GetStrategies(c *gin.Context) {
 	// 暂时返回空数据，后续可以添加 StrategyService
-	c.JSON(http.StatusOK, gin.H{
-		"strategies": []gin.H{},
-	})
+	c.JSON(http.StatusOK, listed(c, []gin.H{}, 0, 0))
 }
 
-// GetAPYData 获取APY数据
+// GetAPYData 获取各资金库的APY及换算的APR，?rate=apr|apy 只返回一种口径
 func (h *Handlers) GetAPYData(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"apy_data": []gin.H{
-			{
-				"vault":   "0xVault1",
-				"apy_7d":  "0.0521",
-				"apy_30d": "0.0518",
-				"apy_90d": "0.0505",
-			},
-			{
-				"vault":   "0xVault2",
-				"apy_7d":  "0.0415",
-				"apy_30d": "0.0422",
-				"apy_90d": "0.0410",
-			},
-		},
-	})
+	kind, ok := parseRate(c, "")
+	if !ok {
+		return
+	}
+
+	data, err := h.vaultService.GetAPYData(c.Request.Context())
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get APY data: %v", err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch APY data",
+		})
+		return
+	}
+
+	for i := range data {
+		data[i] = data[i].Only(kind)
+	}
+
+	body := listed(c, data, len(data), len(data))
+	body["compounding_periods"] = rates.Periods()
+	c.JSON(http.StatusOK, body)
 }
 
 // GetUserInfo 获取用户信息
 func (h *Handlers) GetUserInfo(c *gin.Context) {
 	userAddress := c.Param("address")
 
-	user, err := h.userService.GetUserInfo(userAddress)
+	user, err := h.userService.GetUserInfo(c.Request.Context(), userAddress)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to get user info for %s: %v", userAddress, err))
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -113,6 +326,52 @@ func (h *Handlers) GetUserInfo(c *gin.Context) {
 		})
 		return
 	}
+	// 邮箱只返回给本人
+	if !strings.EqualFold(userAddress, c.GetString("user_address")) {
+		user.Email = ""
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user": user,
+	})
+}
+
+// UpdateUserProfile 修改用户资料，需要钱包对资料原文的签名
+func (h *Handlers) UpdateUserProfile(c *gin.Context) {
+	address, ok := requireSelf(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateProfileRequest
+	if !bindJSON(c, &req, "Invalid profile request") {
+		return
+	}
+
+	user, err := h.userService.UpdateProfile(address, service.ProfileUpdate{
+		Nickname:  req.Nickname,
+		AvatarURL: req.AvatarURL,
+		Email:     req.Email,
+		IssuedAt:  req.IssuedAt,
+		Signature: req.Signature,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidNickname),
+			errors.Is(err, service.ErrInvalidAvatarURL),
+			errors.Is(err, signature.ErrMalformed):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, signature.ErrMismatch),
+			errors.Is(err, service.ErrSignatureExpired):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrNicknameTaken):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			logger.Error(fmt.Sprintf("Failed to update profile of %s: %v", address, err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
+		}
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"user": user,
@@ -123,78 +382,383 @@ func (h *Handlers) GetUserInfo(c *gin.Context) {
 func (h *Handlers) GetUserPositions(c *gin.Context) {
 	userAddress := c.Param("address")
 
-	// 暂时返回模拟数据，后续可以添加 PositionService
-	c.JSON(http.StatusOK, gin.H{
-		"positions": []gin.H{
-			{"user_address": userAddress,
-				"vault_address": "0xVault1",
-				"vault_name":    "USDC Yield Vault",
-				"shares":        "25000.000000",
-				"assets":        "25625.000000",
-				"apy":           "0.0525",
-				"value_usd":     "25625.00",
-			},
-			{"user_address": userAddress,
-				"vault_address": "0xVault2",
-				"vault_name":    "ETH Staking Vault",
-				"shares":        "1.500000",
-				"assets":        "1.530000",
-				"apy":           "0.0420",
-				"value_usd":     "2800.00",
-			},
-		},
-	})
+	fx, ok := h.parseCurrency(c)
+	if !ok {
+		return
+	}
+
+	positions, err := h.positionService.GetUserPositions(c.Request.Context(), userAddress)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get positions for %s: %v", userAddress, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch user positions",
+		})
+		return
+	}
+
+	if fx != nil {
+		for i := range positions {
+			positions[i].ApplyFX(fx.Rate)
+		}
+	}
+
+	body := listed(c, positions, len(positions), len(positions))
+	body["fx"] = fx
+	c.JSON(http.StatusOK, body)
 }
 
 // DepositToVault 存款到资金库
 func (h *Handlers) DepositToVault(c *gin.Context) {
 	vaultAddress := c.Param("address")
-	userAddress, _ := c.Get("user_address")
+	userAddress := c.GetString("user_address")
+
+	var req DepositRequest
+	if !bindJSON(c, &req, "Invalid deposit request") {
+		return
+	}
+	if (req.Amount == nil) == (req.AmountWei == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": service.ErrInvalidAmount.Error()})
+		return
+	}
+	intent := service.Intent{
+		Kind:         service.IntentDeposit,
+		Vault:        vaultAddress,
+		AmountWei:    req.AmountWei,
+		AllowPartial: req.AllowPartial,
+		Amount:       req.Amount.text(),
+		MinOut:       req.MinSharesOut.text(),
+		MinOutWei:    req.MinSharesOutWei,
+		Deadline:     req.Deadline,
+		QuoteID:      req.QuoteID,
+		Nonce:        req.Nonce,
+		ExpiresAt:    req.ExpiresAt,
+		Signature:    req.Signature,
+	}
+	if !h.verifyIntent(c, userAddress, intent) {
+		return
+	}
+
+	vault, err := h.vaultService.GetVaultDetail(c.Request.Context(), vaultAddress)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch vault details",
+		})
+		return
+	}
+	if vault == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Vault not found",
+		})
+		return
+	}
+	if !h.checkDepositAllowed(c, vault, userAddress) {
+		return
+	}
+	requested, err := h.amountService.Assets(c.Request.Context(), vault, req.Amount.value(), req.AmountWei)
+	if !h.checkAmount(c, err) {
+		return
+	}
+	minSharesOut, minSharesOutWei := req.MinSharesOut.value(), req.MinSharesOutWei
+	if req.QuoteID != "" {
+		quote, ok := h.checkQuote(c, userAddress, req.QuoteID, vault, requested)
+		if !ok {
+			return
+		}
+		if minSharesOut == nil && minSharesOutWei == "" {
+			minSharesOutWei = quote.MinSharesWei
+		}
+	}
+
+	// 超过存款上限时按allow_partial截断到剩余额度(按资产精度向下取整)或拒绝
+	amount := requested
+	if remaining := vault.RemainingCapacity(); remaining != nil && amount.Value.GreaterThan(*remaining) {
+		truncated := remaining.Truncate(amount.Decimals)
+		if !req.AllowPartial || !truncated.IsPositive() {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":              "Deposit exceeds vault capacity",
+				"remaining_capacity": remaining,
+			})
+			return
+		}
+		if amount, err = units.FromValue(truncated, amount.Decimals); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process deposit"})
+			return
+		}
+	}
+	minShares, err := optionalAmount(c.Request.Context(), vault, h.amountService.Shares, minSharesOut, minSharesOutWei)
+	if !h.checkAmount(c, err) {
+		return
+	}
+	minShares = scaleMinOut(minShares, requested, amount)
+	prepared, ok := h.prepareIntent(c, vault, userAddress, service.IntentDeposit, amount, minShares, intent)
+	if !ok {
+		return
+	}
+
+	// 模拟链模式下存款立即确认并写入交易记录
+	if blockchain.MockEnabled() {
+		event, err := h.mockChainService.Deposit(c.Request.Context(), vault, userAddress, amount.Value)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Mock deposit to %s failed: %v", vaultAddress, err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process deposit"})
+			return
+		}
+		shareDecimals, err := h.amountService.ShareDecimals(c.Request.Context(), vault)
+		if !h.checkAmount(c, err) {
+			return
+		}
+		h.settleMockIntent(prepared, event, event.Shares, shareDecimals)
+		c.JSON(http.StatusOK, gin.H{
+			"transaction": gin.H{
+				"hash":         event.TxHash,
+				"status":       "confirmed",
+				"block_number": event.BlockNumber,
+				"intent_id":    prepared.ID,
+				"vault":        vaultAddress,
+				"user":         userAddress,
+				"amount":       amount.Value.String(),
+				"amount_wei":   amount.BaseString(),
+				"decimals":     amount.Decimals,
+				"type":         "deposit",
+
+				"requested_amount":     requested.Value.String(),
+				"requested_amount_wei": requested.BaseString(),
+			},
+		})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"transaction": gin.H{
-			"hash":   "0xTxHash123",
-			"status": "pending",
-			"vault":  vaultAddress,
-			"user":   userAddress,
-			"amount": "1000.00",
-			"type":   "deposit",
+			"status":     "pending",
+			"intent_id":  prepared.ID,
+			"chain_id":   prepared.ChainID,
+			"to":         prepared.To,
+			"data":       prepared.Data,
+			"value":      "0",
+			"approve":    service.IntentApproval(prepared, vault),
+			"vault":      vaultAddress,
+			"user":       userAddress,
+			"amount":     amount.Value.String(),
+			"amount_wei": amount.BaseString(),
+			"decimals":   amount.Decimals,
+			"type":       "deposit",
+
+			"min_shares_out_wei": prepared.MinOutWei,
+			"deadline":           prepared.Deadline,
+
+			"requested_amount":     requested.Value.String(), // 按存款上限截断时大于amount
+			"requested_amount_wei": requested.BaseString(),
 		},
 	})
 }
 
+// verifyIntent 校验存取款意向的钱包签名，nonce只检查不消耗，失败时直接写入错误响应
+func (h *Handlers) verifyIntent(c *gin.Context, userAddress string, in service.Intent) bool {
+	err := h.intentService.Verify(userAddress, in)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, signature.ErrMalformed),
+		errors.Is(err, service.ErrIntentExpiry):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, signature.ErrMismatch),
+		errors.Is(err, service.ErrIntentExpired):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNonceUsed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "nonce_used"})
+	default:
+		logger.Error(fmt.Sprintf("Failed to verify %s intent of %s: %v", in.Kind, userAddress, err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify intent signature"})
+	}
+	return false
+}
+
+// checkAmount 处理存取款数量的换算错误，失败时直接写入错误响应
+func (h *Handlers) checkAmount(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, service.ErrInvalidAmount),
+		errors.Is(err, units.ErrTooPrecise),
+		errors.Is(err, units.ErrInvalidBase):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		logger.Error(fmt.Sprintf("Failed to read token decimals: %v", err))
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to read token decimals from chain"})
+	}
+	return false
+}
+
+// checkDepositAllowed 检查资金库当前是否接受该用户的存款，不接受时直接写入错误响应
+func (h *Handlers) checkDepositAllowed(c *gin.Context, vault *models.Vault, userAddress string) bool {
+	// 冻结或只允许取款时不再接受存款意向
+	if !vault.AcceptsDeposits() {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Vault is paused and not accepting deposits",
+			"mode":  vault.Mode,
+		})
+		return false
+	}
+	// 开启白名单的机构资金库只接受白名单中的地址，code便于前端区分其他拒绝原因
+	allowed, err := h.allowlistService.IsAllowed(vault, userAddress)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to check vault allowlist",
+		})
+		return false
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Address is not allowlisted for this vault",
+			"code":  "not_allowlisted",
+		})
+		return false
+	}
+	return h.checkScreening(c, vault, userAddress)
+}
+
 // WithdrawFromVault 从资金库取款
 func (h *Handlers) WithdrawFromVault(c *gin.Context) {
 	vaultAddress := c.Param("address")
 	userAddress, _ := c.Get("user_address")
 
+	var req WithdrawRequest
+	if !bindJSON(c, &req, "Invalid withdraw request") {
+		return
+	}
+	if (req.Shares == nil) == (req.SharesWei == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": service.ErrInvalidAmount.Error()})
+		return
+	}
+	intent := service.Intent{
+		Kind:      service.IntentWithdraw,
+		Vault:     vaultAddress,
+		AmountWei: req.SharesWei,
+		Amount:    req.Shares.text(),
+		MinOut:    req.MinAssetsOut.text(),
+		MinOutWei: req.MinAssetsOutWei,
+		Deadline:  req.Deadline,
+		Nonce:     req.Nonce,
+		ExpiresAt: req.ExpiresAt,
+		Signature: req.Signature,
+	}
+	if !h.verifyIntent(c, c.GetString("user_address"), intent) {
+		return
+	}
+
+	vault, err := h.vaultService.GetVaultDetail(c.Request.Context(), vaultAddress)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch vault details",
+		})
+		return
+	}
+	if vault == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Vault not found",
+		})
+		return
+	}
+	// 冻结或只允许存款时不再接受取款意向
+	if !vault.AcceptsWithdrawals() {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Vault is paused and not accepting withdrawals",
+			"mode":  vault.Mode,
+		})
+		return
+	}
+	shares, err := h.amountService.Shares(c.Request.Context(), vault, req.Shares.value(), req.SharesWei)
+	if !h.checkAmount(c, err) {
+		return
+	}
+	minAssets, err := optionalAmount(c.Request.Context(), vault, h.amountService.Assets, req.MinAssetsOut.value(), req.MinAssetsOutWei)
+	if !h.checkAmount(c, err) {
+		return
+	}
+	prepared, ok := h.prepareIntent(c, vault, c.GetString("user_address"), service.IntentWithdraw, shares, minAssets, intent)
+	if !ok {
+		return
+	}
+
+	// 模拟链模式下取款立即确认并写入交易记录
+	if blockchain.MockEnabled() {
+		event, err := h.mockChainService.Withdraw(c.Request.Context(), vault, c.GetString("user_address"), shares.Value)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Mock withdrawal from %s failed: %v", vaultAddress, err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process withdrawal"})
+			return
+		}
+		assetDecimals, err := h.amountService.AssetDecimals(c.Request.Context(), vault)
+		if !h.checkAmount(c, err) {
+			return
+		}
+		// 模拟链按1:1换算，资产数量超出资产精度的部分向下取整
+		assets, _ := units.FromValue(event.Assets.Truncate(assetDecimals), assetDecimals)
+		h.settleMockIntent(prepared, event, assets.Value, assetDecimals)
+		c.JSON(http.StatusOK, gin.H{
+			"transaction": gin.H{
+				"hash":         event.TxHash,
+				"status":       "confirmed",
+				"block_number": event.BlockNumber,
+				"intent_id":    prepared.ID,
+				"vault":        vaultAddress,
+				"user":         userAddress,
+				"shares":       shares.Value.String(),
+				"shares_wei":   shares.BaseString(),
+				"assets":       assets.Value.String(),
+				"assets_wei":   assets.BaseString(),
+				"type":         "withdraw",
+			},
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"transaction": gin.H{
-			"hash":   "0xTxHash456",
-			"status": "pending",
-			"vault":  vaultAddress,
-			"user":   userAddress,
-			"amount": "500.00",
-			"type":   "withdraw",
+			"status":     "pending",
+			"intent_id":  prepared.ID,
+			"chain_id":   prepared.ChainID,
+			"to":         prepared.To,
+			"data":       prepared.Data,
+			"value":      "0",
+			"approve":    service.IntentApproval(prepared, vault),
+			"vault":      vaultAddress,
+			"user":       userAddress,
+			"shares":     shares.Value.String(),
+			"shares_wei": shares.BaseString(),
+			"decimals":   shares.Decimals,
+			"type":       "withdraw",
+
+			"min_assets_out_wei": prepared.MinOutWei,
+			"deadline":           prepared.Deadline,
 		},
 	})
 }
 
 // GetSystemStats 获取系统统计
 func (h *Handlers) GetSystemStats(c *gin.Context) {
-	// 暂时返回模拟数据
+	fx, ok := h.parseCurrency(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.statsService.GetSystemStats(c.Request.Context())
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get system stats: %v", err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch system stats",
+		})
+		return
+	}
+
+	if fx != nil {
+		stats.ApplyFX(fx.Rate)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"stats": gin.H{
-			"total_tvl":         "1500000.00",
-			"total_users":       125,
-			"total_vaults":      3,
-			"total_strategies":  5,
-			"total_deposits":    "2500000.00",
-			"total_withdrawals": "1000000.00",
-			"total_yield":       "75000.00",
-			"avg_apy":           "0.0485",
-			"updated_at":        "2024-01-20T12:00:00Z",
-		},
+		"stats": stats,
+		"fx":    fx,
 	})
 }
 
@@ -202,31 +766,131 @@ func (h *Handlers) GetSystemStats(c *gin.Context) {
 func (h *Handlers) EmergencyStopVault(c *gin.Context) {
 	vaultAddress := c.Param("address")
 
+	var req EmergencyStopRequest
+	if !bindJSON(c, &req, "Invalid emergency stop request") {
+		return
+	}
+
+	vault, err := h.vaultService.GetVaultDetail(c.Request.Context(), vaultAddress)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch vault details",
+		})
+		return
+	}
+	if vault == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Vault not found",
+		})
+		return
+	}
+
+	result, err := h.vaultControlService.EmergencyStop(c.Request.Context(), vault, c.GetString("admin_address"), req.Reason, req.OnChain)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to emergency stop vault %s: %v", vaultAddress, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to stop vault",
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"action":  "emergency_stop",
-		"vault":   vaultAddress,
-		"status":  "stopped",
-		"message": "Vault has been emergency stopped",
+		"action":         "emergency_stop",
+		"vault":          result.Vault,
+		"status":         "stopped",
+		"tx_hash":        result.TxHash,
+		"on_chain_error": result.OnChainError,
+		"audit_id":       result.AuditID,
 	})
 }
 
-// GetRiskAlerts 获取风险警报
-func (h *Handlers) GetRiskAlerts(c *gin.Context) {
+// SetVaultMode 切换资金库运行模式：active、withdraw_only、deposit_only、frozen
+func (h *Handlers) SetVaultMode(c *gin.Context) {
+	vaultAddress := c.Param("address")
+
+	var req SetVaultModeRequest
+	if !bindJSON(c, &req, "Invalid vault mode request") {
+		return
+	}
+
+	vault, err := h.vaultService.GetVaultDetail(c.Request.Context(), vaultAddress)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch vault details",
+		})
+		return
+	}
+	if vault == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Vault not found",
+		})
+		return
+	}
+
+	vault, err = h.vaultControlService.SetMode(c.Request.Context(), vault, c.GetString("admin_address"), req.Mode, req.Reason)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidVaultMode) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "mode must be one of active, withdraw_only, deposit_only, frozen",
+			})
+			return
+		}
+		logger.Error(fmt.Sprintf("Failed to set mode of vault %s: %v", vaultAddress, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to set vault mode",
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"alerts": []gin.H{
-			{
-				"id":        "alert-1",
-				"level":     "medium",
-				"type":      "liquidity",
-				"message":   "Low liquidity in AAVE pool",
-				"vault":     "0xVault1",
-				"strategy":  "0xStrategy1",
-				"timestamp": "2024-01-20T11:30:00Z",
-			},
-		},
+		"vault": vault,
+		"mode":  vault.Mode,
 	})
 }
 
+// GetAuditLog 获取管理操作审计日志，可按资金库过滤
+func (h *Handlers) GetAuditLog(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "limit must be between 1 and 200",
+		})
+		return
+	}
+
+	vault := c.Query("vault")
+	if vault != "" {
+		var ok bool
+		if vault, ok = normalizeAddress(c, "vault", vault); !ok {
+			return
+		}
+	}
+
+	entries, err := h.vaultControlService.GetAuditLog(vault, limit)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get audit log: %v", err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch audit log",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, listed(c, entries, len(entries), limit))
+}
+
+// GetRiskAlerts 获取风险警报，目前来自状态异常的Chainlink Automation / Gelato任务
+func (h *Handlers) GetRiskAlerts(c *gin.Context) {
+	alerts, err := h.automationService.Alerts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch risk alerts",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, listed(c, alerts, len(alerts), len(alerts)))
+}
+
 // CheckStrategyRisk 检查策略风险
 func (h *Handlers) CheckStrategyRisk(c *gin.Context) {
 	strategyAddress := c.Param("address")
@@ -247,13 +911,6 @@ func (h *Handlers) CheckStrategyRisk(c *gin.Context) {
 // GetMonitoringData 获取监控数据
 func (h *Handlers) GetMonitoringData(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
-		"monitoring": gin.H{
-			"active_connections":   45,
-			"request_per_minute":   120,
-			"error_rate":           "0.02",
-			"response_time_avg":    "45ms",
-			"database_connections": 12,
-			"last_updated":         "2024-01-20T12:00:00Z",
-		},
+		"monitoring": h.monitoringService.GetMonitoringData(c.Request.Context()),
 	})
 }
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetVaultHarvests 按时间倒序分页获取资金库的收获记录，?strategy= 可只看单个策略
+func (h *Handlers) GetVaultHarvests(c *gin.Context) {
+	address := c.Param("address")
+
+	strategy := ""
+	if value := c.Query("strategy"); value != "" {
+		var ok bool
+		if strategy, ok = normalizeAddress(c, "strategy", value); !ok {
+			return
+		}
+	}
+	h.listHarvests(c, address, strategy)
+}
+
+// GetStrategyHarvests 按时间倒序分页获取策略的收获记录
+func (h *Handlers) GetStrategyHarvests(c *gin.Context) {
+	h.listHarvests(c, "", c.Param("address"))
+}
+
+func (h *Handlers) listHarvests(c *gin.Context, vault, strategy string) {
+	cursor, limit, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	harvests, page, err := h.harvestService.List(vault, strategy, cursor, limit)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get harvests of vault %q strategy %q: %v", vault, strategy, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch harvests",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, paged(c, harvests, limit, page))
+}
+
+// GetVaultHarvestAttribution 按策略拆分资金库在 [from, to) 内的收益、费用和gas成本，默认最近30天
+func (h *Handlers) GetVaultHarvestAttribution(c *gin.Context) {
+	address := c.Param("address")
+
+	from, to, ok := parseTimeRange(c, 30*24*time.Hour)
+	if !ok {
+		return
+	}
+
+	vault, err := h.vaultService.GetVaultDetail(c.Request.Context(), address)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch vault details",
+		})
+		return
+	}
+	if vault == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Vault not found",
+		})
+		return
+	}
+
+	attribution, err := h.harvestService.Attribution(c.Request.Context(), vault, from, to)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to attribute harvests of vault %s: %v", address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to compute yield attribution",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"attribution": attribution,
+	})
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/rates"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetVaultTransactions 按时间倒序分页获取资金库的存取款记录
+func (h *Handlers) GetVaultTransactions(c *gin.Context) {
+	address := c.Param("address")
+
+	cursor, limit, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	transactions, page, err := h.vaultService.GetVaultTransactions(address, cursor, limit)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get transactions for vault %s: %v", address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch vault transactions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, paged(c, transactions, limit, page))
+}
+
+// GetVaultEvents 按时间倒序分页获取资金库的运行时间线，?type= 可用逗号分隔筛选多个类型
+func (h *Handlers) GetVaultEvents(c *gin.Context) {
+	address := c.Param("address")
+
+	cursor, limit, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	var types []string
+	if raw := c.Query("type"); raw != "" {
+		for _, t := range strings.Split(raw, ",") {
+			t = strings.TrimSpace(t)
+			if !models.ValidVaultEventType(t) {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": fmt.Sprintf("Invalid event type %q", t),
+				})
+				return
+			}
+			types = append(types, t)
+		}
+	}
+
+	vaultEvents, page, err := h.vaultService.GetVaultEvents(address, types, cursor, limit)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get events for vault %s: %v", address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch vault events",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, paged(c, vaultEvents, limit, page))
+}
+
+// GetVaultAPYHistory 按时间倒序分页获取资金库的原始APY记录
+func (h *Handlers) GetVaultAPYHistory(c *gin.Context) {
+	address := c.Param("address")
+
+	kind, ok := parseRate(c, rates.APY)
+	if !ok {
+		return
+	}
+	cursor, limit, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	history, page, err := h.vaultService.GetAPYHistory(address, kind, cursor, limit)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get APY history for vault %s: %v", address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch APY history",
+		})
+		return
+	}
+
+	body := paged(c, history, limit, page)
+	body["rate"] = rates.NewBasis(kind)
+	c.JSON(http.StatusOK, body)
+}
+
+// parseRate 解析 ?rate=apr|apy 收益率口径，未指定时返回def
+func parseRate(c *gin.Context, def string) (string, bool) {
+	kind := c.DefaultQuery("rate", def)
+	if kind != def && !rates.Valid(kind) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "rate must be apr or apy",
+		})
+		return "", false
+	}
+	return kind, true
+}
+
+// GetVaultSharePrice 获取资金库当前份额价格，?at=RFC3339时间 返回该时刻的份额价格
+func (h *Handlers) GetVaultSharePrice(c *gin.Context) {
+	address := c.Param("address")
+
+	var at *time.Time
+	if value := c.Query("at"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "at must be an RFC3339 timestamp",
+			})
+			return
+		}
+		at = &parsed
+	}
+
+	price, err := h.vaultService.GetSharePrice(address, at)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get share price for vault %s: %v", address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch share price",
+		})
+		return
+	}
+	if price == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "No share price recorded for this vault",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"share_price": price,
+	})
+}
+
+// GetVaultSharePriceHistory 按时间倒序分页获取资金库的份额价格历史
+func (h *Handlers) GetVaultSharePriceHistory(c *gin.Context) {
+	address := c.Param("address")
+
+	cursor, limit, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	history, page, err := h.vaultService.GetSharePriceHistory(address, cursor, limit)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get share price history for vault %s: %v", address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch share price history",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, paged(c, history, limit, page))
+}
+
+// GetUserTransactions 按时间倒序分页获取用户的存取款记录
+func (h *Handlers) GetUserTransactions(c *gin.Context) {
+	userAddress := c.Param("address")
+
+	cursor, limit, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	transactions, page, err := h.userService.GetUserTransactions(userAddress, cursor, limit)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get transactions for %s: %v", userAddress, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch user transactions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, paged(c, transactions, limit, page))
+}
+
+// GetUserActivity 按时间倒序分页获取用户动态，包含告警触发，只能查看自己的动态
+func (h *Handlers) GetUserActivity(c *gin.Context) {
+	userAddress, ok := requireSelf(c)
+	if !ok {
+		return
+	}
+
+	cursor, limit, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	items, page, err := h.userService.GetUserActivity(userAddress, cursor, limit)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get activity for %s: %v", userAddress, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch user activity",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, paged(c, items, limit, page))
+}
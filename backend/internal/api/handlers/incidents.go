@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetIncidents 列出事件，?status=open|resolved 按状态过滤，?component= 按组件过滤
+func (h *Handlers) GetIncidents(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "limit must be between 1 and 200",
+		})
+		return
+	}
+	status := c.Query("status")
+	if status != "" && status != repository.IncidentFilterOpen && status != repository.IncidentFilterResolved {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "status must be open or resolved",
+		})
+		return
+	}
+
+	incidents, err := h.incidentService.List(status, c.Query("component"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch incidents",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, listed(c, incidents, len(incidents), limit))
+}
+
+// GetIncident 获取事件及其全部进展和关联的告警
+func (h *Handlers) GetIncident(c *gin.Context) {
+	id, ok := incidentID(c)
+	if !ok {
+		return
+	}
+
+	incident, err := h.incidentService.Get(id)
+	if err != nil {
+		h.incidentError(c, id, err, "Failed to fetch incident")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"incident": incident,
+	})
+}
+
+// CreateIncident 手动创建事件
+func (h *Handlers) CreateIncident(c *gin.Context) {
+	var req CreateIncidentRequest
+	if !bindJSON(c, &req, "Invalid incident request") {
+		return
+	}
+
+	incident, err := h.incidentService.Create(c.Request.Context(), c.GetString("admin_address"), service.IncidentInput{
+		Component: req.Component,
+		Severity:  req.Severity,
+		Title:     req.Title,
+		Message:   req.Message,
+		StartedAt: req.StartedAt,
+		Notify:    req.Notify,
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create incident for %s: %v", req.Component, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create incident",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"incident": incident,
+	})
+}
+
+// UpdateIncident 修改事件的严重程度或标题
+func (h *Handlers) UpdateIncident(c *gin.Context) {
+	id, ok := incidentID(c)
+	if !ok {
+		return
+	}
+	var req UpdateIncidentRequest
+	if !bindJSON(c, &req, "Invalid incident request") {
+		return
+	}
+
+	incident, err := h.incidentService.Update(c.GetString("admin_address"), id, req.Severity, req.Title)
+	if err != nil {
+		h.incidentError(c, id, err, "Failed to update incident")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"incident": incident,
+	})
+}
+
+// PostIncidentUpdate 记录事件进展，状态为 resolved 时结束事件，其他状态重新打开已结束的事件
+func (h *Handlers) PostIncidentUpdate(c *gin.Context) {
+	id, ok := incidentID(c)
+	if !ok {
+		return
+	}
+	var req IncidentUpdateRequest
+	if !bindJSON(c, &req, "Invalid incident update") {
+		return
+	}
+
+	incident, err := h.incidentService.PostUpdate(c.Request.Context(), c.GetString("admin_address"), id, req.Status, req.Message, req.Notify)
+	if err != nil {
+		h.incidentError(c, id, err, "Failed to add incident update")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"incident": incident,
+	})
+}
+
+// DeleteIncident 删除事件及其进展，关联的告警保留
+func (h *Handlers) DeleteIncident(c *gin.Context) {
+	id, ok := incidentID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.incidentService.Delete(c.GetString("admin_address"), id); err != nil {
+		h.incidentError(c, id, err, "Failed to delete incident")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func incidentID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid incident id",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (h *Handlers) incidentError(c *gin.Context, id uint, err error, message string) {
+	if errors.Is(err, service.ErrIncidentNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Incident not found"})
+		return
+	}
+	logger.Error(fmt.Sprintf("%s %d: %v", message, id, err))
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": message,
+	})
+}
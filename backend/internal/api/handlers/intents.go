@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/pkg/events"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/units"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+// GetIntent 查看当前用户的存取款意向、生成的交易和执行结果
+func (h *Handlers) GetIntent(c *gin.Context) {
+	id, ok := intentID(c)
+	if !ok {
+		return
+	}
+
+	intent, err := h.intentService.Get(c.GetString("user_address"), id)
+	if err != nil {
+		if errors.Is(err, service.ErrIntentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch intent",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"intent": intent,
+	})
+}
+
+// ReportIntentExecution 上报执行意向的交易哈希。交易得到的份额或资产低于 min_out、或在 deadline 之后上链时
+// 意向记为 rejected 并返回 422；交易尚未上链返回 409，交易与意向不对应返回 400，可以重新上报
+func (h *Handlers) ReportIntentExecution(c *gin.Context) {
+	id, ok := intentID(c)
+	if !ok {
+		return
+	}
+
+	var req ReportIntentRequest
+	if !bindJSON(c, &req, "Invalid execution report") {
+		return
+	}
+
+	intent, err := h.intentService.Report(c.Request.Context(), c.GetString("user_address"), id, strings.ToLower(req.TxHash))
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"intent": intent})
+	case errors.Is(err, service.ErrIntentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrIntentViolation):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "intent": intent})
+	case errors.Is(err, service.ErrIntentFinished):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "intent": intent})
+	case errors.Is(err, service.ErrTxPending):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTxMismatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		logger.Error(fmt.Sprintf("Failed to verify execution of intent %d: %v", id, err))
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to verify transaction on chain"})
+	}
+}
+
+// prepareIntent 检查最少输出在当前链上状态下能否满足，再生成并保存意向的交易，同时消耗签名的nonce，失败时直接写入错误响应
+func (h *Handlers) prepareIntent(c *gin.Context, vault *models.Vault, userAddress, kind string, amount, minOut *units.Amount, signed service.Intent) (*models.Intent, bool) {
+	err := h.intentService.CheckMinOut(c.Request.Context(), vault, kind, amount, minOut)
+	if err == nil {
+		var intent *models.Intent
+		if intent, err = h.intentService.Prepare(vault, userAddress, kind, amount, minOut, signed); err == nil {
+			return intent, true
+		}
+	}
+
+	switch {
+	case errors.Is(err, service.ErrMinOutNotMet),
+		errors.Is(err, service.ErrDeadlineUnsupported):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrPreviewPrecision):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrQuoteUsed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "quote_used"})
+	case errors.Is(err, service.ErrNonceUsed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "nonce_used"})
+	default:
+		logger.Error(fmt.Sprintf("Failed to prepare %s intent on %s: %v", kind, vault.Address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to prepare %s transaction", kind)})
+	}
+	return nil, false
+}
+
+// settleMockIntent 模拟链上交易已立即执行，直接记录意向的执行结果
+func (h *Handlers) settleMockIntent(intent *models.Intent, event *events.ChainEvent, out decimal.Decimal, decimals int32) {
+	raw, err := units.ToBase(out.Truncate(decimals), decimals)
+	if err == nil {
+		_, err = h.intentService.Settle(intent, event.TxHash, event.LogIndex, raw)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to settle mock intent %d: %v", intent.ID, err))
+	}
+}
+
+// scaleMinOut 存款按剩余额度截断时按截断比例缩小最少份额(向下取整)，
+// 否则按原数量计算的最少份额(包括报价的 min_shares)在截断后的存款中必然不满足
+func scaleMinOut(minOut, requested, amount *units.Amount) *units.Amount {
+	if minOut == nil || amount.Base.Cmp(requested.Base) == 0 {
+		return minOut
+	}
+	base := new(big.Int).Mul(minOut.Base, amount.Base)
+	base.Quo(base, requested.Base)
+	return &units.Amount{Value: units.FromBase(base, minOut.Decimals), Base: base, Decimals: minOut.Decimals}
+}
+
+// optionalAmount 解析可选的数量参数，两种形式都未给出时返回nil
+func optionalAmount(ctx context.Context, vault *models.Vault, resolve amountResolver, value *decimal.Decimal, base string) (*units.Amount, error) {
+	if value == nil && base == "" {
+		return nil, nil
+	}
+	return resolve(ctx, vault, value, base)
+}
+
+func intentID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid intent id",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetJobs 列出worker定时任务的调度表达式、暂停状态、下一次执行时间和最近一次执行结果
+func (h *Handlers) GetJobs(c *gin.Context) {
+	jobs, err := h.jobService.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch jobs",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, listed(c, jobs, len(jobs), len(jobs)))
+}
+
+// TriggerJob 请求立即执行一次任务，worker在几秒内领取执行
+func (h *Handlers) TriggerJob(c *gin.Context) {
+	name := c.Param("name")
+	if err := h.jobService.Trigger(c.GetString("admin_address"), name); err != nil {
+		switch {
+		case errors.Is(err, service.ErrJobNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		case errors.Is(err, service.ErrJobRunning):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			logger.Error(fmt.Sprintf("Failed to trigger job %s: %v", name, err))
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to trigger job",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"name":      name,
+		"triggered": true,
+	})
+}
+
+// PauseJob 暂停任务的定时执行
+func (h *Handlers) PauseJob(c *gin.Context) {
+	h.setJobPaused(c, true)
+}
+
+// ResumeJob 恢复任务的定时执行
+func (h *Handlers) ResumeJob(c *gin.Context) {
+	h.setJobPaused(c, false)
+}
+
+func (h *Handlers) setJobPaused(c *gin.Context, paused bool) {
+	name := c.Param("name")
+	job, err := h.jobService.SetPaused(c.GetString("admin_address"), name, paused)
+	if err != nil {
+		if errors.Is(err, service.ErrJobNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		logger.Error(fmt.Sprintf("Failed to set paused=%t on job %s: %v", paused, name, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update job",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"job": job,
+	})
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KeeperRequired 校验 X-Keeper-Key 头中的API Key，通过后把keeper写入上下文
+func (h *Handlers) KeeperRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keeper, err := h.keeperService.Authenticate(c.GetHeader("X-Keeper-Key"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to authenticate keeper",
+			})
+			c.Abort()
+			return
+		}
+		if keeper == nil {
+			logger.Info(fmt.Sprintf("Keeper authentication failed from %s", c.ClientIP()))
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Valid X-Keeper-Key header required",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set("keeper", keeper)
+		c.Next()
+	}
+}
+
+func currentKeeper(c *gin.Context) *models.Keeper {
+	keeper, _ := c.MustGet("keeper").(*models.Keeper)
+	return keeper
+}
+
+// GetKeeperJobs 列出可领取的任务，?type= 为逗号分隔的任务类型，?chain_id= 按链过滤
+func (h *Handlers) GetKeeperJobs(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "limit must be between 1 and 200",
+		})
+		return
+	}
+	chainID, err := strconv.ParseUint(c.DefaultQuery("chain_id", "0"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid chain_id",
+		})
+		return
+	}
+	var types []string
+	if raw := c.Query("type"); raw != "" {
+		types = strings.Split(raw, ",")
+	}
+
+	jobs, err := h.keeperService.AvailableJobs(types, uint(chainID), limit)
+	if err != nil {
+		if errors.Is(err, service.ErrKeeperJobType) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "type must be a comma separated list of harvest, rebalance",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch keeper jobs",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, listed(c, jobs, len(jobs), limit))
+}
+
+// ClaimKeeperJob 以租约方式领取任务
+func (h *Handlers) ClaimKeeperJob(c *gin.Context) {
+	id, ok := parseKeeperJobID(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.keeperService.Claim(id, currentKeeper(c))
+	if err != nil {
+		respondKeeperJobError(c, id, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"job": job,
+	})
+}
+
+// ReportKeeperJob 上报已领取任务的执行结果
+func (h *Handlers) ReportKeeperJob(c *gin.Context) {
+	id, ok := parseKeeperJobID(c)
+	if !ok {
+		return
+	}
+
+	var req KeeperReportRequest
+	if !bindJSON(c, &req, "Invalid keeper report") {
+		return
+	}
+	for i := range req.Transactions {
+		req.Transactions[i].StrategyAddress = strings.ToLower(req.Transactions[i].StrategyAddress)
+	}
+
+	job, err := h.keeperService.Report(c.Request.Context(), id, currentKeeper(c), service.KeeperResult{
+		Success:      *req.Success,
+		TxHash:       strings.ToLower(req.TxHash),
+		Error:        req.Error,
+		Transactions: req.Transactions,
+	})
+	if err != nil {
+		respondKeeperJobError(c, id, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"job": job,
+	})
+}
+
+// GetKeepers 列出登记的keeper
+func (h *Handlers) GetKeepers(c *gin.Context) {
+	keepers, err := h.keeperService.ListKeepers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch keepers",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, listed(c, keepers, len(keepers), len(keepers)))
+}
+
+// RegisterKeeper 登记keeper，响应中的 api_key 只返回这一次
+func (h *Handlers) RegisterKeeper(c *gin.Context) {
+	var req RegisterKeeperRequest
+	if !bindJSON(c, &req, "Invalid keeper request") {
+		return
+	}
+
+	keeper, key, err := h.keeperService.Register(c.GetString("admin_address"), strings.ToLower(req.Address), req.Name)
+	if err != nil {
+		if errors.Is(err, service.ErrKeeperExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Error(fmt.Sprintf("Failed to register keeper %s: %v", req.Address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to register keeper",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"keeper":  keeper,
+		"api_key": key,
+	})
+}
+
+// RevokeKeeper 停用keeper，API Key立即失效
+func (h *Handlers) RevokeKeeper(c *gin.Context) {
+	var req RevokeKeeperRequest
+	if !bindJSON(c, &req, "Invalid revoke request") {
+		return
+	}
+
+	address := c.Param("address")
+	if err := h.keeperService.Revoke(c.GetString("admin_address"), address, req.Reason); err != nil {
+		if errors.Is(err, service.ErrKeeperNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Keeper not found"})
+			return
+		}
+		logger.Error(fmt.Sprintf("Failed to revoke keeper %s: %v", address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to revoke keeper",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"address": address,
+		"active":  false,
+	})
+}
+
+// GetKeeperJobHistory 管理员按状态查看keeper任务
+func (h *Handlers) GetKeeperJobHistory(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "limit must be between 1 and 200",
+		})
+		return
+	}
+
+	jobs, err := h.keeperService.ListJobs(c.Query("status"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch keeper jobs",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, listed(c, jobs, len(jobs), limit))
+}
+
+func parseKeeperJobID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid job id",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func respondKeeperJobError(c *gin.Context, id uint, err error) {
+	switch {
+	case errors.Is(err, service.ErrKeeperJobNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Keeper job not found"})
+	case errors.Is(err, service.ErrKeeperJobClaimed),
+		errors.Is(err, service.ErrKeeperLeaseLost),
+		errors.Is(err, service.ErrProposalNotReady),
+		errors.Is(err, service.ErrTxPending):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrKeeperResult),
+		errors.Is(err, service.ErrKeeperTx),
+		errors.Is(err, service.ErrKeeperExecutions),
+		errors.Is(err, service.ErrMissingTxHash):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		logger.Error(fmt.Sprintf("Failed to update keeper job %d: %v", id, err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update keeper job"})
+	}
+}
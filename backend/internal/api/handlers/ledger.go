@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetLedgerJournals 按记账时间倒序分页获取复式记账凭证，?vault= 按资金库、?kind= 按类型过滤
+func (h *Handlers) GetLedgerJournals(c *gin.Context) {
+	vault, ok := ledgerVault(c)
+	if !ok {
+		return
+	}
+	cursor, limit, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	journals, page, err := h.ledgerService.ListJournals(vault, c.Query("kind"), cursor, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidJournalKind) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch ledger journals",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, paged(c, journals, limit, page))
+}
+
+// GetLedgerBalances 获取各账户余额及试算平衡结果，?vault= 只汇总单个资金库
+func (h *Handlers) GetLedgerBalances(c *gin.Context) {
+	vault, ok := ledgerVault(c)
+	if !ok {
+		return
+	}
+
+	trial, err := h.ledgerService.TrialBalance(vault)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch ledger balances",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, trial)
+}
+
+// GetLedgerTotals 按账本重算资金库截至 ?at=RFC3339时间(缺省为当前)的累计存取款、收获和费用
+func (h *Handlers) GetLedgerTotals(c *gin.Context) {
+	vault, ok := ledgerVault(c)
+	if !ok {
+		return
+	}
+	at := time.Now()
+	if value := c.Query("at"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "at must be an RFC3339 timestamp",
+			})
+			return
+		}
+		at = parsed
+	}
+
+	totals, err := h.ledgerService.Totals(vault, at)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch ledger totals",
+		})
+		return
+	}
+
+	body := listed(c, totals, len(totals), len(totals))
+	body["at"] = at
+	c.JSON(http.StatusOK, body)
+}
+
+// GetLedgerDrift 列出资金库和策略上保存的汇总字段与账本不一致的项目
+func (h *Handlers) GetLedgerDrift(c *gin.Context) {
+	drift, err := h.ledgerService.Drift()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to check ledger totals",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, listed(c, drift, len(drift), len(drift)))
+}
+
+// RecomputeLedgerTotals 按账本重写全部汇总字段，返回被更正的项目
+func (h *Handlers) RecomputeLedgerTotals(c *gin.Context) {
+	corrected, err := h.ledgerService.RecomputeTotals(c.Request.Context(), c.GetString("admin_address"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to recompute ledger totals",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"corrected": corrected,
+	})
+}
+
+// ledgerVault 解析可选的 ?vault= 过滤条件
+func ledgerVault(c *gin.Context) (string, bool) {
+	vault := c.Query("vault")
+	if vault == "" {
+		return "", true
+	}
+	return normalizeAddress(c, "vault", vault)
+}
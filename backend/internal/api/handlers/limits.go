@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetRateLimits 调用方出口IP在各限流策略下的限额、剩余次数和重置时间，便于客户端自行控制请求速度。
+// 公开接口不读取 X-User-Address，按地址计数的配额见 GetUserRateLimits；本请求计入公开接口策略
+func (h *Handlers) GetRateLimits(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"ip":             c.ClientIP(),
+		"window_seconds": int(time.Minute.Seconds()),
+		"limits":         middleware.Quotas(c.ClientIP(), ""),
+	})
+}
+
+// GetUserRateLimits 已认证用户的配额：在IP策略之外加上按地址计数的已认证接口，只能查看自己的配额；本请求计入认证接口的默认策略
+func (h *Handlers) GetUserRateLimits(c *gin.Context) {
+	address, ok := requireSelf(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"ip":             c.ClientIP(),
+		"address":        address,
+		"window_seconds": int(time.Minute.Seconds()),
+		"limits":         middleware.Quotas(c.ClientIP(), address),
+	})
+}
@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetStrategyImpermanentLoss 获取LP策略当前的估算无常损失
+func (h *Handlers) GetStrategyImpermanentLoss(c *gin.Context) {
+	strategy, ok := h.lpStrategy(c)
+	if !ok {
+		return
+	}
+	if strategy.LPPoolType == "" {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Strategy is not an LP strategy",
+		})
+		return
+	}
+
+	estimate, err := h.lpService.Estimate(c.Request.Context(), strategy)
+	if err != nil {
+		if errors.Is(err, service.ErrLPPriceUnavailable) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		logger.Error(fmt.Sprintf("Failed to estimate impermanent loss of strategy %s: %v", strategy.Address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to estimate impermanent loss",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"strategy":         strategy.Address,
+		"impermanent_loss": estimate,
+	})
+}
+
+// SetStrategyLPPosition 把策略标记为LP策略并以当前价格重置无常损失基准
+func (h *Handlers) SetStrategyLPPosition(c *gin.Context) {
+	var req SetLPPositionRequest
+	if !bindJSON(c, &req, "Invalid LP position request") {
+		return
+	}
+
+	strategy, ok := h.lpStrategy(c)
+	if !ok {
+		return
+	}
+
+	tokens := make([]string, len(req.Tokens))
+	for i, token := range req.Tokens {
+		tokens[i] = strings.ToLower(token)
+	}
+
+	estimate, err := h.lpService.SetPosition(c.Request.Context(), strategy, c.GetString("admin_address"), req.PoolType, tokens, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidLPPosition):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrLPPriceUnavailable):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			logger.Error(fmt.Sprintf("Failed to set LP position of strategy %s: %v", strategy.Address, err))
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to set LP position",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"strategy":         strategy,
+		"impermanent_loss": estimate,
+	})
+}
+
+// SetStrategyUniswapV3Position 登记集中流动性策略的Uniswap v3头寸，之后由worker定期记录区间和手续费
+func (h *Handlers) SetStrategyUniswapV3Position(c *gin.Context) {
+	var req SetUniswapV3PositionRequest
+	if !bindJSON(c, &req, "Invalid Uniswap v3 position request") {
+		return
+	}
+
+	strategy, ok := h.lpStrategy(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.uniswapV3Service.SetPosition(c.Request.Context(), strategy, c.GetString("admin_address"),
+		strings.ToLower(req.PositionManager), req.TokenID, strings.ToLower(req.Pool))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidUniswapV3Position):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrUniswapV3Unreadable):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			logger.Error(fmt.Sprintf("Failed to set Uniswap v3 position of strategy %s: %v", strategy.Address, err))
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to set Uniswap v3 position",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"strategy":               strategy,
+		"concentrated_liquidity": status,
+	})
+}
+
+// SetStrategyMorphoMarket 登记借贷策略供应资金的Morpho Blue市场，之后由worker定期同步利率和风险输入
+func (h *Handlers) SetStrategyMorphoMarket(c *gin.Context) {
+	var req SetMorphoMarketRequest
+	if !bindJSON(c, &req, "Invalid Morpho market request") {
+		return
+	}
+
+	strategy, ok := h.lpStrategy(c)
+	if !ok {
+		return
+	}
+
+	lending, err := h.morphoService.SetMarket(c.Request.Context(), strategy, c.GetString("admin_address"),
+		strings.ToLower(req.Morpho), req.MarketID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidMorphoMarket):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrMorphoUnreadable):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			logger.Error(fmt.Sprintf("Failed to set Morpho market of strategy %s: %v", strategy.Address, err))
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to set Morpho market",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"strategy": strategy,
+		"morpho":   lending,
+	})
+}
+
+// SetStrategyPendleMarket 登记固定利率策略持有PT的Pendle市场，之后由worker定期同步隐含利率
+func (h *Handlers) SetStrategyPendleMarket(c *gin.Context) {
+	var req SetPendleMarketRequest
+	if !bindJSON(c, &req, "Invalid Pendle market request") {
+		return
+	}
+
+	strategy, ok := h.lpStrategy(c)
+	if !ok {
+		return
+	}
+
+	fixed, err := h.pendleService.SetMarket(c.Request.Context(), strategy, c.GetString("admin_address"), strings.ToLower(req.Market))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidPendleMarket):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrPendleUnreadable):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			logger.Error(fmt.Sprintf("Failed to set Pendle market of strategy %s: %v", strategy.Address, err))
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to set Pendle market",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"strategy":   strategy,
+		"fixed_rate": fixed,
+	})
+}
+
+func (h *Handlers) lpStrategy(c *gin.Context) (*models.Strategy, bool) {
+	address := c.Param("address")
+	strategy, err := h.strategyService.GetStrategy(address)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch strategy",
+		})
+		return nil, false
+	}
+	if strategy == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Strategy not found",
+		})
+		return nil, false
+	}
+	return strategy, true
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetVaultMetadata 设置资金库的展示资料
+func (h *Handlers) SetVaultMetadata(c *gin.Context) {
+	var req SetMetadataRequest
+	if !bindJSON(c, &req, "Invalid metadata request") {
+		return
+	}
+
+	address := c.Param("address")
+	vault, err := h.vaultService.GetVaultDetail(c.Request.Context(), address)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch vault details",
+		})
+		return
+	}
+	if vault == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Vault not found",
+		})
+		return
+	}
+
+	h.setMetadata(c, models.MetadataKindVault, vault.Address, req)
+}
+
+// SetStrategyMetadata 设置策略的展示资料
+func (h *Handlers) SetStrategyMetadata(c *gin.Context) {
+	var req SetMetadataRequest
+	if !bindJSON(c, &req, "Invalid metadata request") {
+		return
+	}
+
+	strategy, ok := h.lpStrategy(c)
+	if !ok {
+		return
+	}
+
+	h.setMetadata(c, models.MetadataKindStrategy, strategy.Address, req)
+}
+
+func (h *Handlers) setMetadata(c *gin.Context, kind, address string, req SetMetadataRequest) {
+	update := service.MetadataUpdate{
+		Description: req.Description,
+		LogoURL:     req.LogoURL,
+	}
+	// 日期格式已由 binding 校验
+	if req.InceptionDate != "" {
+		date, _ := time.Parse(time.DateOnly, req.InceptionDate)
+		update.InceptionDate = &date
+	}
+	for _, link := range req.Links {
+		update.Links = append(update.Links, models.MetadataLink{Label: link.Label, URL: link.URL})
+	}
+	for _, audit := range req.Audits {
+		update.Audits = append(update.Audits, models.MetadataAudit{Auditor: audit.Auditor, URL: audit.URL, Date: audit.Date})
+	}
+
+	metadata, err := h.metadataService.Set(kind, address, c.GetString("admin_address"), update)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidMetadataURL) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		logger.Error(fmt.Sprintf("Failed to set display metadata of %s %s: %v", kind, address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to set metadata",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"metadata": metadata,
+	})
+}
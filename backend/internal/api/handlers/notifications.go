@@ -0,0 +1,371 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetNotificationSettings 获取用户的通知渠道与订阅
+func (h *Handlers) GetNotificationSettings(c *gin.Context) {
+	address, ok := requireSelf(c)
+	if !ok {
+		return
+	}
+
+	settings, err := h.notificationService.GetSettings(address)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get notification settings for %s: %v", address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch notification settings",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"notifications": settings,
+	})
+}
+
+// RegisterNotificationChannel 登记邮箱、Telegram或webhook渠道并发送验证码，webhook渠道的签名密钥只在此时返回
+func (h *Handlers) RegisterNotificationChannel(c *gin.Context) {
+	address, ok := requireSelf(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Type   string `json:"type" binding:"required,oneof=email telegram webhook"`
+		Target string `json:"target" binding:"required,max=255"`
+	}
+	if !bindJSON(c, &req, "Invalid channel request") {
+		return
+	}
+
+	channel, err := h.notificationService.RegisterChannel(c.Request.Context(), address, req.Type, req.Target)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidChannel) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		logger.Error(fmt.Sprintf("Failed to register %s channel for %s: %v", req.Type, address, err))
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error": "Failed to send verification code",
+		})
+		return
+	}
+
+	resp := gin.H{
+		"channel": channel,
+		"message": "Verification code sent",
+	}
+	if channel.Secret != "" {
+		resp["secret"] = channel.Secret
+	}
+	c.JSON(http.StatusAccepted, resp)
+}
+
+// VerifyNotificationChannel 提交验证码完成渠道验证
+func (h *Handlers) VerifyNotificationChannel(c *gin.Context) {
+	address, ok := requireSelf(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Code string `json:"code" binding:"required,len=6,numeric"`
+	}
+	if !bindJSON(c, &req, "Invalid verification request") {
+		return
+	}
+
+	err := h.notificationService.VerifyChannel(address, c.Param("type"), req.Code)
+	switch {
+	case errors.Is(err, service.ErrChannelNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidCode):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	case err != nil:
+		logger.Error(fmt.Sprintf("Failed to verify channel for %s: %v", address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to verify channel",
+		})
+	default:
+		c.JSON(http.StatusOK, gin.H{
+			"verified": true,
+		})
+	}
+}
+
+// DeleteNotificationChannel 删除通知渠道
+func (h *Handlers) DeleteNotificationChannel(c *gin.Context) {
+	address, ok := requireSelf(c)
+	if !ok {
+		return
+	}
+
+	err := h.notificationService.DeleteChannel(address, c.Param("type"))
+	if errors.Is(err, service.ErrChannelNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to delete channel for %s: %v", address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete channel",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RotateWebhookSecret 更换webhook签名密钥，新密钥只在响应中返回一次
+func (h *Handlers) RotateWebhookSecret(c *gin.Context) {
+	address, ok := requireSelf(c)
+	if !ok {
+		return
+	}
+
+	secret, err := h.notificationService.RotateWebhookSecret(address)
+	if errors.Is(err, service.ErrChannelNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Webhook channel not found",
+		})
+		return
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to rotate webhook secret for %s: %v", address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to rotate webhook secret",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret": secret,
+	})
+}
+
+// GetWebhookDeliveries 获取webhook最近的投递尝试及响应状态码
+func (h *Handlers) GetWebhookDeliveries(c *gin.Context) {
+	address, ok := requireSelf(c)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "limit must be between 1 and 100",
+		})
+		return
+	}
+
+	deliveries, err := h.notificationService.ListWebhookDeliveries(address, limit)
+	if errors.Is(err, service.ErrChannelNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Webhook channel not found",
+		})
+		return
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to list webhook deliveries for %s: %v", address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch webhook deliveries",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, listed(c, deliveries, len(deliveries), limit))
+}
+
+// CreateNotificationSubscription 订阅事件通知
+func (h *Handlers) CreateNotificationSubscription(c *gin.Context) {
+	address, ok := requireSelf(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Event        string   `json:"event" binding:"required,oneof=apy_below deposit_confirmed withdraw_confirmed vault_paused rebalance_suggestion"`
+		VaultAddress string   `json:"vault_address" binding:"omitempty,eth_address"`
+		Threshold    *float64 `json:"threshold"`
+	}
+	if !bindJSON(c, &req, "Invalid subscription request") {
+		return
+	}
+	req.VaultAddress = strings.ToLower(req.VaultAddress)
+
+	subscription := &models.NotificationSubscription{
+		UserAddress:  address,
+		Event:        req.Event,
+		VaultAddress: req.VaultAddress,
+		Threshold:    req.Threshold,
+	}
+	if err := h.notificationService.Subscribe(subscription); err != nil {
+		if errors.Is(err, service.ErrInvalidSubscription) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		logger.Error(fmt.Sprintf("Failed to subscribe %s to %s: %v", address, req.Event, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create subscription",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"subscription": subscription,
+	})
+}
+
+// DeleteNotificationSubscription 取消订阅
+func (h *Handlers) DeleteNotificationSubscription(c *gin.Context) {
+	address, ok := requireSelf(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid subscription ID",
+		})
+		return
+	}
+
+	deleted, err := h.notificationService.Unsubscribe(address, uint(id))
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to unsubscribe %s from %d: %v", address, id, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete subscription",
+		})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Subscription not found",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CreateAPYAlertRule 创建APY告警规则
+func (h *Handlers) CreateAPYAlertRule(c *gin.Context) {
+	address, ok := requireSelf(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		VaultAddress string   `json:"vault_address" binding:"required,eth_address"`
+		Window       string   `json:"window" binding:"required,oneof=current 7d"`
+		Direction    string   `json:"direction" binding:"required,oneof=above below"`
+		Threshold    *float64 `json:"threshold" binding:"required"`
+	}
+	if !bindJSON(c, &req, "Invalid alert rule request") {
+		return
+	}
+	req.VaultAddress = strings.ToLower(req.VaultAddress)
+
+	rule := &models.APYAlertRule{
+		UserAddress:  address,
+		VaultAddress: req.VaultAddress,
+		Window:       req.Window,
+		Direction:    req.Direction,
+		Threshold:    *req.Threshold,
+	}
+	if err := h.notificationService.CreateRule(rule); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidAlertRule):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrTooManyAlertRules):
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrDuplicateAlertRule):
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+				"code":  "duplicate_rule",
+			})
+		default:
+			logger.Error(fmt.Sprintf("Failed to create alert rule for %s: %v", address, err))
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to create alert rule",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"rule": rule,
+	})
+}
+
+// DeleteAPYAlertRule 删除APY告警规则
+func (h *Handlers) DeleteAPYAlertRule(c *gin.Context) {
+	address, ok := requireSelf(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid rule ID",
+		})
+		return
+	}
+
+	deleted, err := h.notificationService.DeleteRule(address, uint(id))
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to delete alert rule %d for %s: %v", id, address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete alert rule",
+		})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Alert rule not found",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// requireSelf 用户只能管理自己的设置和资料，返回路径中的地址
+func requireSelf(c *gin.Context) (string, bool) {
+	address := c.Param("address")
+	if !strings.EqualFold(address, c.GetString("user_address")) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You can only manage your own account",
+		})
+		return "", false
+	}
+	return address, true
+}
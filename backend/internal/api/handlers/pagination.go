@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parsePage 解析?limit=和?cursor=参数，cursor为上一次响应中 pagination 的 next 或 prev；参数错误时直接写入错误响应
+func parsePage(c *gin.Context) (*repository.Cursor, int, bool) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "limit must be between 1 and 200",
+		})
+		return nil, 0, false
+	}
+
+	cursor, err := repository.DecodeCursor(c.Query("cursor"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid cursor",
+		})
+		return nil, 0, false
+	}
+	return cursor, limit, true
+}
+
+// Pagination 列表接口统一的分页信息。cursor 为本页请求携带的游标，next/prev 为相邻页的游标，没有时为null
+type Pagination struct {
+	Total  int64   `json:"total"`
+	Limit  int     `json:"limit"`
+	Cursor *string `json:"cursor"`
+	Next   *string `json:"next"`
+	Prev   *string `json:"prev"`
+}
+
+// paged 构建列表接口统一的 {data, pagination} 响应，调用方可再附加字段
+func paged(c *gin.Context, data interface{}, limit int, page *repository.Page) gin.H {
+	pagination := Pagination{Limit: limit, Total: page.Total, Next: encodeCursor(page.Next), Prev: encodeCursor(page.Prev)}
+	if cursor := c.Query("cursor"); cursor != "" {
+		pagination.Cursor = &cursor
+	}
+	return gin.H{
+		"data":       data,
+		"pagination": pagination,
+	}
+}
+
+// listed 不分页的列表同样返回 {data, pagination}：一次返回全部结果，total 为结果数，没有相邻页。
+// limit 为接口的数量上限，没有上限的接口传结果数
+func listed(c *gin.Context, data interface{}, count, limit int) gin.H {
+	return paged(c, data, limit, &repository.Page{Total: int64(count)})
+}
+
+func encodeCursor(cursor *repository.Cursor) *string {
+	if cursor == nil {
+		return nil
+	}
+	encoded := cursor.Encode()
+	return &encoded
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/units"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+// GetVaultPreviewDeposit 按链上 previewDeposit 计算存入 ?amount= (或最小单位 ?amount_wei=) 底层资产可获得的份额
+func (h *Handlers) GetVaultPreviewDeposit(c *gin.Context) {
+	h.previewVault(c, "amount", h.amountService.Assets, h.previewService.PreviewDeposit)
+}
+
+// GetVaultPreviewRedeem 按链上 previewRedeem 计算赎回 ?shares= (或最小单位 ?shares_wei=) 份额可取回的底层资产
+func (h *Handlers) GetVaultPreviewRedeem(c *gin.Context) {
+	h.previewVault(c, "shares", h.amountService.Shares, h.previewService.PreviewRedeem)
+}
+
+type amountResolver func(context.Context, *models.Vault, *decimal.Decimal, string) (*units.Amount, error)
+
+func (h *Handlers) previewVault(c *gin.Context, param string, resolve amountResolver, preview func(context.Context, *models.Vault, decimal.Decimal) (*service.Preview, error)) {
+	vault, amount, ok := h.previewInput(c, param, resolve)
+	if !ok {
+		return
+	}
+
+	result, err := preview(c.Request.Context(), vault, amount.Value)
+	if err != nil {
+		h.previewFailed(c, vault.Address, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"preview": result,
+	})
+}
+
+// GetVaultWithdrawPreview 预览赎回 ?shares= (或 ?shares_wei=) 的退出成本：预期资产、退出费用、gas估算，
+// 以及按 ?slippage_bps= (默认 slippage.default_bps) 计算的最少可得资产
+func (h *Handlers) GetVaultWithdrawPreview(c *gin.Context) {
+	var slippageBps uint16
+	if value := c.Query("slippage_bps"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 16)
+		if err != nil || parsed > 10000 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "slippage_bps must be an integer between 0 and 10000",
+			})
+			return
+		}
+		slippageBps = uint16(parsed)
+	}
+
+	vault, shares, ok := h.previewInput(c, "shares", h.amountService.Shares)
+	if !ok {
+		return
+	}
+
+	result, err := h.exitPreviewService.Preview(c.Request.Context(), vault, shares.Value, slippageBps)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSlippage) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		h.previewFailed(c, vault.Address, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"preview": result,
+	})
+}
+
+// previewInput 读取资金库和 param (或 param_wei) 指定的数量，出错时已写入响应
+func (h *Handlers) previewInput(c *gin.Context, param string, resolve amountResolver) (*models.Vault, *units.Amount, bool) {
+	address := c.Param("address")
+
+	var value *decimal.Decimal
+	if raw := c.Query(param); raw != "" {
+		amount, err := decimal.NewFromString(raw)
+		if err != nil || !amount.IsPositive() {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("%s must be a positive number", param),
+			})
+			return nil, nil, false
+		}
+		value = &amount
+	}
+	base := c.Query(param + "_wei")
+	if (value == nil) == (base == "") {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("exactly one of %s and %s_wei is required", param, param),
+		})
+		return nil, nil, false
+	}
+
+	vault, err := h.vaultService.GetVaultDetail(c.Request.Context(), address)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch vault details",
+		})
+		return nil, nil, false
+	}
+	if vault == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Vault not found",
+		})
+		return nil, nil, false
+	}
+
+	amount, err := resolve(c.Request.Context(), vault, value, base)
+	if !h.checkAmount(c, err) {
+		return nil, nil, false
+	}
+	return vault, amount, true
+}
+
+func (h *Handlers) previewFailed(c *gin.Context, address string, err error) {
+	if errors.Is(err, service.ErrPreviewPrecision) || errors.Is(err, service.ErrInvalidAmount) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	logger.Error(fmt.Sprintf("Failed to preview vault %s: %v", address, err))
+	c.JSON(http.StatusBadGateway, gin.H{
+		"error": "Failed to read preview from chain",
+	})
+}
+
+// GetVaultGasComparison 比较同一资产在各链上资金库的存取gas成本，?days= 为持有天数(默认30)，用于计算收益覆盖gas的存款规模
+func (h *Handlers) GetVaultGasComparison(c *gin.Context) {
+	address := c.Param("address")
+
+	days := 30
+	if value := c.Query("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 3650 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "days must be an integer between 1 and 3650",
+			})
+			return
+		}
+		days = parsed
+	}
+
+	vault, err := h.vaultService.GetVaultDetail(c.Request.Context(), address)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch vault details",
+		})
+		return
+	}
+	if vault == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Vault not found",
+		})
+		return
+	}
+
+	comparison, err := h.gasService.Compare(c.Request.Context(), vault, days)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to compare gas costs for vault %s: %v", address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to compare gas costs",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, comparison)
+}
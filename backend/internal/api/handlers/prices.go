@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/prices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTokenPrice 获取代币USD价格
+func (h *Handlers) GetTokenPrice(c *gin.Context) {
+	token := c.Query("token")
+	chainID, err := strconv.ParseUint(c.DefaultQuery("chain_id", "1"), 10, 64)
+	if token == "" || err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "token and a numeric chain_id are required",
+		})
+		return
+	}
+
+	price, err := h.priceService.GetPrice(c.Request.Context(), token, uint(chainID))
+	if err != nil {
+		if errors.Is(err, prices.ErrUnknownToken) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Token has no configured price source",
+			})
+			return
+		}
+		logger.Error(fmt.Sprintf("Failed to get price for %s on chain %d: %v", token, chainID, err))
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Price temporarily unavailable",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"price": price,
+	})
+}
+
+// GetTokenPriceHistory 获取代币历史价格
+func (h *Handlers) GetTokenPriceHistory(c *gin.Context) {
+	token := c.Query("token")
+	chainID, err := strconv.ParseUint(c.DefaultQuery("chain_id", "1"), 10, 64)
+	if token == "" || err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "token and a numeric chain_id are required",
+		})
+		return
+	}
+
+	from, to, ok := parseTimeRange(c, 90*24*time.Hour)
+	if !ok {
+		return
+	}
+
+	history, err := h.priceHistoryService.GetRange(token, uint(chainID), from, to)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get price history for %s: %v", token, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch price history",
+		})
+		return
+	}
+
+	body := listed(c, history, len(history), len(history))
+	body["token"] = token
+	body["chain_id"] = chainID
+	c.JSON(http.StatusOK, body)
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/signature"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportUserData 以JSON附件导出用户在平台上存储的全部数据
+func (h *Handlers) ExportUserData(c *gin.Context) {
+	address, ok := requireSelf(c)
+	if !ok {
+		return
+	}
+
+	export, err := h.privacyService.Export(address)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to export data of %s: %v", address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to export user data",
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="mya-export-%s.json"`, address))
+	c.JSON(http.StatusOK, export)
+}
+
+// RequestDataDeletion 申请删除链下个人数据，由worker异步执行
+func (h *Handlers) RequestDataDeletion(c *gin.Context) {
+	address, ok := requireSelf(c)
+	if !ok {
+		return
+	}
+
+	var req DataDeletionRequest
+	if !bindJSON(c, &req, "Invalid deletion request") {
+		return
+	}
+
+	request, err := h.privacyService.RequestDeletion(address, req.IssuedAt, req.Signature)
+	if err != nil {
+		switch {
+		case errors.Is(err, signature.ErrMalformed):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, signature.ErrMismatch),
+			errors.Is(err, service.ErrSignatureExpired):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrDeletionPending):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			logger.Error(fmt.Sprintf("Failed to request data deletion for %s: %v", address, err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to request data deletion"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"request": request,
+	})
+}
+
+// GetDataDeletionRequests 查看删除请求的处理状态
+func (h *Handlers) GetDataDeletionRequests(c *gin.Context) {
+	address, ok := requireSelf(c)
+	if !ok {
+		return
+	}
+
+	requests, err := h.privacyService.DeletionRequests(address)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch deletion requests",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, listed(c, requests, len(requests), len(requests)))
+}
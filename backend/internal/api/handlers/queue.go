@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetQueueJobs 按创建时间倒序分页列出队列任务，可按 status 和 kind 过滤
+func (h *Handlers) GetQueueJobs(c *gin.Context) {
+	cursor, limit, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	jobs, page, err := h.queueService.List(c.Query("status"), c.Query("kind"), cursor, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidQueueJob) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch queue jobs",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, paged(c, jobs, limit, page))
+}
+
+// GetQueueStats 按类型和状态统计队列任务数
+func (h *Handlers) GetQueueStats(c *gin.Context) {
+	stats, err := h.queueService.Stats()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch queue stats",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"stats": stats,
+	})
+}
+
+// RetryQueueJob 把最终失败的任务重新放回队列
+func (h *Handlers) RetryQueueJob(c *gin.Context) {
+	h.transitionQueueJob(c, "retry", h.queueService.Retry)
+}
+
+// CancelQueueJob 取消尚未执行的任务
+func (h *Handlers) CancelQueueJob(c *gin.Context) {
+	h.transitionQueueJob(c, "cancel", h.queueService.Cancel)
+}
+
+func (h *Handlers) transitionQueueJob(c *gin.Context, op string, apply func(actor string, id uint) (*models.QueueJob, error)) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid job id",
+		})
+		return
+	}
+
+	job, err := apply(c.GetString("admin_address"), uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrQueueJobNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Queue job not found"})
+		case errors.Is(err, service.ErrQueueJobState):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			logger.Error(fmt.Sprintf("Failed to %s queue job %d: %v", op, id, err))
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to " + op + " queue job",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"job": job,
+	})
+}
+
+// QueueBackfill 把资金库历史事件回放加入队列，由worker执行
+func (h *Handlers) QueueBackfill(c *gin.Context) {
+	var req BackfillRequest
+	if !bindJSON(c, &req, "Invalid backfill request") {
+		return
+	}
+
+	job, err := h.backfillService.Enqueue(c.GetString("admin_address"), service.BackfillJob{
+		Vault:         strings.ToLower(req.VaultAddress),
+		FromBlock:     req.FromBlock,
+		ToBlock:       req.ToBlock,
+		Confirmations: req.Confirmations,
+		ChunkSize:     req.ChunkSize,
+		RPS:           req.RPS,
+		Restart:       req.Restart,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrBackfillVaultNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Vault not found"})
+		case errors.Is(err, service.ErrBackfillQueued):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrInvalidBackfill):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			logger.Error(fmt.Sprintf("Failed to queue backfill of %s: %v", req.VaultAddress, err))
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to queue backfill",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"job": job,
+	})
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/swap"
+	"github.com/chspring1/mya-platform/backend/pkg/units"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetDepositQuote 报价直接存入底层资产：返回报价ID、预期份额、按滑点扣除的最少份额和gas，报价在 intents.quote_ttl 秒后过期
+func (h *Handlers) GetDepositQuote(c *gin.Context) {
+	vaultAddress := c.Param("address")
+	userAddress := c.GetString("user_address")
+
+	var req DepositQuoteRequest
+	if !bindJSON(c, &req, "Invalid deposit quote request") {
+		return
+	}
+	if (req.Amount == nil) == (req.AmountWei == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": service.ErrInvalidAmount.Error()})
+		return
+	}
+
+	vault, err := h.vaultService.GetVaultDetail(c.Request.Context(), vaultAddress)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch vault details",
+		})
+		return
+	}
+	if vault == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Vault not found",
+		})
+		return
+	}
+	if !h.checkDepositAllowed(c, vault, userAddress) {
+		return
+	}
+	amount, err := h.amountService.Assets(c.Request.Context(), vault, req.Amount, req.AmountWei)
+	if !h.checkAmount(c, err) {
+		return
+	}
+	if remaining := vault.RemainingCapacity(); remaining != nil && amount.Value.GreaterThan(*remaining) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":              "Deposit exceeds vault capacity",
+			"remaining_capacity": remaining,
+		})
+		return
+	}
+
+	quote, err := h.quoteService.Deposit(c.Request.Context(), vault, userAddress, amount, req.SlippageBps)
+	if !quoteFailed(c, "deposit into vault "+vaultAddress, err) {
+		c.JSON(http.StatusOK, gin.H{
+			"quote": quote,
+		})
+	}
+}
+
+// RefreshQuote 重新报价：沿用报价的ID和请求参数，只刷新预期份额、最少份额、兑换路由和gas等易变结果并顺延过期时间。
+// 不再检查白名单和存款额度，它们在存款时仍会校验
+func (h *Handlers) RefreshQuote(c *gin.Context) {
+	id := c.Param("id")
+
+	quote, err := h.quoteService.Refresh(c.Request.Context(), c.GetString("user_address"), id)
+	switch {
+	case errors.Is(err, service.ErrQuoteNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrQuoteUsed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "quote_used"})
+	case !quoteFailed(c, "refresh of "+id, err):
+		c.JSON(http.StatusOK, gin.H{
+			"quote": quote,
+		})
+	}
+}
+
+// checkQuote 存款时校验报价，报价过期、已使用或与存款不符时直接写入错误响应
+func (h *Handlers) checkQuote(c *gin.Context, userAddress, id string, vault *models.Vault, amount *units.Amount) (*service.DepositQuote, bool) {
+	quote, err := h.quoteService.Check(userAddress, id, vault.Address, amount.Value)
+	switch {
+	case err == nil:
+		return quote, true
+	case errors.Is(err, service.ErrQuoteNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrQuoteMismatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrQuoteExpired):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "quote_expired"})
+	case errors.Is(err, service.ErrQuoteUsed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "code": "quote_used"})
+	default:
+		logger.Error(fmt.Sprintf("Failed to check quote %s: %v", id, err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process deposit"})
+	}
+	return nil, false
+}
+
+// quoteFailed 处理报价和重新报价的错误，err 为nil时返回false，否则写入错误响应
+func quoteFailed(c *gin.Context, subject string, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, service.ErrZapSameToken), errors.Is(err, service.ErrInvalidSlippage), errors.Is(err, service.ErrPreviewPrecision):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrZapUnsupported), errors.Is(err, service.ErrZapUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, swap.ErrNoRoute):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": "No swap route found for this token and amount",
+		})
+	default:
+		logger.Error(fmt.Sprintf("Failed to quote %s: %v", subject, err))
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error": "Failed to fetch quote",
+		})
+	}
+	return true
+}
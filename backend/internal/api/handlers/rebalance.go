@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetRebalanceProposals 获取再平衡提案列表
+func (h *Handlers) GetRebalanceProposals(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "limit must be between 1 and 200",
+		})
+		return
+	}
+
+	proposals, err := h.rebalanceService.ListProposals(c.Query("status"), limit)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to list rebalance proposals: %v", err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch rebalance proposals",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, listed(c, proposals, len(proposals), limit))
+}
+
+// GetRebalanceProposal 获取再平衡提案详情
+func (h *Handlers) GetRebalanceProposal(c *gin.Context) {
+	id, ok := parseProposalID(c)
+	if !ok {
+		return
+	}
+
+	proposal, err := h.rebalanceService.GetProposal(id)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get rebalance proposal %d: %v", id, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch rebalance proposal",
+		})
+		return
+	}
+
+	if proposal == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Rebalance proposal not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"proposal": proposal,
+	})
+}
+
+// ApproveRebalanceProposal 批准再平衡提案
+func (h *Handlers) ApproveRebalanceProposal(c *gin.Context) {
+	h.reviewRebalanceProposal(c, true)
+}
+
+// RejectRebalanceProposal 拒绝再平衡提案
+func (h *Handlers) RejectRebalanceProposal(c *gin.Context) {
+	h.reviewRebalanceProposal(c, false)
+}
+
+func (h *Handlers) reviewRebalanceProposal(c *gin.Context, approve bool) {
+	id, ok := parseProposalID(c)
+	if !ok {
+		return
+	}
+
+	proposal, err := h.rebalanceService.Review(id, approve, c.GetString("admin_address"))
+	if err != nil {
+		respondRebalanceError(c, id, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"proposal": proposal,
+	})
+}
+
+// ExecuteRebalanceProposal 登记已批准提案的链上执行交易
+func (h *Handlers) ExecuteRebalanceProposal(c *gin.Context) {
+	id, ok := parseProposalID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Transactions []service.RebalanceExecution `json:"transactions" binding:"dive"`
+	}
+	if !bindJSON(c, &req, "Invalid request body") {
+		return
+	}
+	for i := range req.Transactions {
+		req.Transactions[i].StrategyAddress = strings.ToLower(req.Transactions[i].StrategyAddress)
+	}
+
+	proposal, err := h.rebalanceService.Execute(id, c.GetString("admin_address"), req.Transactions)
+	if err != nil {
+		respondRebalanceError(c, id, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"proposal": proposal,
+	})
+}
+
+func parseProposalID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid proposal id",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func respondRebalanceError(c *gin.Context, id uint, err error) {
+	switch {
+	case errors.Is(err, service.ErrProposalNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rebalance proposal not found"})
+	case errors.Is(err, service.ErrProposalNotPending),
+		errors.Is(err, service.ErrProposalNotReady):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrMissingTxHash):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		logger.Error(fmt.Sprintf("Failed to update rebalance proposal %d: %v", id, err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update rebalance proposal"})
+	}
+}
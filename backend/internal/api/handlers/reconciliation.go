@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetReconciliationReports 按时间倒序分页获取对账报告，列表不含差异明细
+func (h *Handlers) GetReconciliationReports(c *gin.Context) {
+	cursor, limit, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	reports, page, err := h.reconciliationService.List(cursor, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch reconciliation reports",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, paged(c, reports, limit, page))
+}
+
+// GetReconciliationReport 获取单次对账的差异明细和读取失败
+func (h *Handlers) GetReconciliationReport(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid report ID",
+		})
+		return
+	}
+
+	report, err := h.reconciliationService.Get(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch reconciliation report",
+		})
+		return
+	}
+	if report == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Reconciliation report not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"report": report,
+	})
+}
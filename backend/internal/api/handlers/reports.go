@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetReports 按日期倒序分页获取每日汇总报告
+func (h *Handlers) GetReports(c *gin.Context) {
+	cursor, limit, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	reports, page, err := h.reportService.List(cursor, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch reports",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, paged(c, reports, limit, page))
+}
+
+// GetReport 获取某一天(YYYY-MM-DD, UTC)的报告
+func (h *Handlers) GetReport(c *gin.Context) {
+	day, err := time.Parse(time.DateOnly, c.Param("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "date must be YYYY-MM-DD",
+		})
+		return
+	}
+
+	report, err := h.reportService.Get(day)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch report",
+		})
+		return
+	}
+	if report == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Report not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"report": report,
+	})
+}
+
+// GenerateReport 把某天的报告加入队列生成，用于补齐worker停机期间缺失的报告
+func (h *Handlers) GenerateReport(c *gin.Context) {
+	day, err := time.Parse(time.DateOnly, c.Param("date"))
+	if err != nil || !day.Before(time.Now().UTC().Truncate(24*time.Hour)) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "date must be a past day in YYYY-MM-DD",
+		})
+		return
+	}
+
+	existing, err := h.reportService.Get(day)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch report",
+		})
+		return
+	}
+	if existing != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Report already exists",
+		})
+		return
+	}
+
+	queued, err := h.reportService.Enqueue(day)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to queue report",
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"date":   day.Format(time.DateOnly),
+		"queued": queued,
+	})
+}
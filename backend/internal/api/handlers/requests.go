@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/service"
+
+	"github.com/shopspring/decimal"
+)
+
+// 请求体定义，校验规则见 validation.go；地址字段在通过校验后由处理器转为小写
+
+// SignedDecimal 签名意向中的十进制数量，Text 为请求中的原文(字符串去掉引号)。
+// 签名原文使用 Text 而不是规范化后的数值，"1.50" 和 "1.5" 签出的消息不同
+type SignedDecimal struct {
+	decimal.Decimal
+	Text string
+}
+
+func (d *SignedDecimal) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+	}
+	value, err := decimal.NewFromString(text)
+	if err != nil {
+		return fmt.Errorf("invalid decimal %s: %w", data, err)
+	}
+	d.Decimal, d.Text = value, text
+	return nil
+}
+
+// value 数值，未给出时为nil
+func (d *SignedDecimal) value() *decimal.Decimal {
+	if d == nil {
+		return nil
+	}
+	return &d.Decimal
+}
+
+// text 签名原文中的写法，未给出时为空
+func (d *SignedDecimal) text() string {
+	if d == nil {
+		return ""
+	}
+	return d.Text
+}
+
+// DepositRequest 存款意向，amount 为底层资产数量，也可以用 amount_wei 给出最小单位的整数字符串，两者只能给一个。
+// min_shares_out(或 min_shares_out_wei) 和 deadline 可选，写入生成的交易。
+// signature 为地址本人对 service.IntentMessage 原文的 personal_sign 签名
+type DepositRequest struct {
+	Amount          *SignedDecimal `json:"amount" binding:"omitempty,gt=0"`
+	AmountWei       string         `json:"amount_wei" binding:"max=78"`
+	AllowPartial    bool           `json:"allow_partial"` // 超过存款上限时按剩余额度截断，否则拒绝
+	MinSharesOut    *SignedDecimal `json:"min_shares_out" binding:"omitempty,gt=0"`
+	MinSharesOutWei string         `json:"min_shares_out_wei" binding:"max=78"`
+	Deadline        int64          `json:"deadline" binding:"omitempty,gt=0"` // 交易截止时间(Unix秒)
+	QuoteID         string         `json:"quote_id" binding:"max=34"`         // 未给出最少份额时使用报价中的 min_shares
+	Nonce           uint64         `json:"nonce" binding:"required"`
+	ExpiresAt       int64          `json:"expires_at" binding:"required"`
+	Signature       string         `json:"signature" binding:"required"`
+}
+
+// WithdrawRequest 取款意向，shares 为赎回的份额数量，也可以用 shares_wei 给出最小单位，
+// min_assets_out(或 min_assets_out_wei)、deadline 和签名同存款
+type WithdrawRequest struct {
+	Shares          *SignedDecimal `json:"shares" binding:"omitempty,gt=0"`
+	SharesWei       string         `json:"shares_wei" binding:"max=78"`
+	MinAssetsOut    *SignedDecimal `json:"min_assets_out" binding:"omitempty,gt=0"`
+	MinAssetsOutWei string         `json:"min_assets_out_wei" binding:"max=78"`
+	Deadline        int64          `json:"deadline" binding:"omitempty,gt=0"`
+	Nonce           uint64         `json:"nonce" binding:"required"`
+	ExpiresAt       int64          `json:"expires_at" binding:"required"`
+	Signature       string         `json:"signature" binding:"required"`
+}
+
+// BatchDepositRequest 批量存款意向：amount 按 allocations 的权重拆分存入多个资金库，
+// 各资金库须在同一条链上且底层资产相同，权重合计10000基点
+type BatchDepositRequest struct {
+	Amount      *SignedDecimal    `json:"amount" binding:"omitempty,gt=0"`
+	AmountWei   string            `json:"amount_wei" binding:"max=78"`
+	Allocations []BatchAllocation `json:"allocations" binding:"required,min=2,max=10,dive"`
+	SlippageBps uint16            `json:"slippage_bps" binding:"lte=10000"` // 为0时使用默认滑点
+	Deadline    int64             `json:"deadline" binding:"omitempty,gt=0"`
+	Nonce       uint64            `json:"nonce" binding:"required"`
+	ExpiresAt   int64             `json:"expires_at" binding:"required"`
+	Signature   string            `json:"signature" binding:"required"`
+}
+
+type BatchAllocation struct {
+	Vault     string `json:"vault" binding:"required,eth_address"`
+	WeightBps uint16 `json:"weight_bps" binding:"gt=0,lte=10000"`
+}
+
+// AdvisorRequest 组合建议：amount 为美元金额，assets 为偏好的资产符号或地址，为空时考虑所有资产
+type AdvisorRequest struct {
+	RiskTolerance string          `json:"risk_tolerance" binding:"required,oneof=conservative balanced aggressive"`
+	Amount        decimal.Decimal `json:"amount" binding:"gt=0"`
+	Assets        []string        `json:"assets" binding:"max=10,dive,required,max=42"`
+	ChainID       uint            `json:"chain_id"`
+}
+
+// ReportIntentRequest 用户上报执行意向的交易
+type ReportIntentRequest struct {
+	TxHash string `json:"tx_hash" binding:"required,len=66,startswith=0x,hexadecimal"`
+}
+
+// EmergencyStopRequest 紧急停止资金库
+type EmergencyStopRequest struct {
+	Reason  string `json:"reason" binding:"required,max=500"`
+	OnChain bool   `json:"on_chain"` // 同时通过运维账户调用合约 pause()
+}
+
+// SetVaultModeRequest 切换资金库运行模式
+type SetVaultModeRequest struct {
+	Mode   string `json:"mode" binding:"required,oneof=active withdraw_only deposit_only frozen"`
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// SetAllocationsRequest 设置策略目标配比，target_bps 为万分比
+type SetAllocationsRequest struct {
+	Allocations []AllocationRequest `json:"allocations" binding:"required,min=1,dive"`
+}
+
+type AllocationRequest struct {
+	StrategyAddress string `json:"strategy_address" binding:"required,eth_address"`
+	TargetBps       uint16 `json:"target_bps" binding:"lte=10000"`
+}
+
+// SetVaultFeesRequest 设置资金库费率，均为万分比
+type SetVaultFeesRequest struct {
+	ManagementFeeBps  *uint16 `json:"management_fee_bps" binding:"required,lte=500"`
+	PerformanceFeeBps *uint16 `json:"performance_fee_bps" binding:"required,lte=5000"`
+	Reason            string  `json:"reason" binding:"required,max=500"`
+}
+
+// SetDepositCapRequest 设置存款上限，deposit_cap 为null表示取消上限
+type SetDepositCapRequest struct {
+	DepositCap *decimal.Decimal `json:"deposit_cap" binding:"omitempty,gte=0"`
+	Reason     string           `json:"reason" binding:"required,max=500"`
+}
+
+// SetVaultCategoryRequest 设置资金库分类和标签，category 为空表示取消分类，tags 整体替换原有标签
+type SetVaultCategoryRequest struct {
+	Category string   `json:"category" binding:"max=20"`
+	Tags     []string `json:"tags"`
+}
+
+// SetMetadataRequest 设置资金库或策略的展示资料，整体替换原有内容，inception_date 格式为 YYYY-MM-DD
+type SetMetadataRequest struct {
+	Description   string                 `json:"description" binding:"max=5000"`
+	LogoURL       string                 `json:"logo_url" binding:"omitempty,url,max=512"`
+	Links         []MetadataLinkRequest  `json:"links" binding:"max=20,dive"`
+	Audits        []MetadataAuditRequest `json:"audits" binding:"max=20,dive"`
+	InceptionDate string                 `json:"inception_date" binding:"omitempty,datetime=2006-01-02"`
+}
+
+type MetadataLinkRequest struct {
+	Label string `json:"label" binding:"required,max=64"`
+	URL   string `json:"url" binding:"required,url,max=512"`
+}
+
+type MetadataAuditRequest struct {
+	Auditor string `json:"auditor" binding:"required,max=64"`
+	URL     string `json:"url" binding:"required,url,max=512"`
+	Date    string `json:"date" binding:"omitempty,datetime=2006-01-02"`
+}
+
+// SetAllowlistRequest 开启或关闭资金库的存款白名单
+type SetAllowlistRequest struct {
+	Enabled *bool  `json:"enabled" binding:"required"`
+	Reason  string `json:"reason" binding:"required,max=500"`
+}
+
+// AddAllowlistRequest 批量加入白名单地址
+type AddAllowlistRequest struct {
+	Addresses []string `json:"addresses" binding:"required,min=1,max=500,dive,eth_address"`
+	Note      string   `json:"note" binding:"max=255"`
+}
+
+// ZapQuoteRequest 用任意代币存入资金库的报价请求，token_in 为原生代币时使用 0xEeee...EEeE
+type ZapQuoteRequest struct {
+	TokenIn     string          `json:"token_in" binding:"required,eth_address"`
+	Amount      decimal.Decimal `json:"amount" binding:"gt=0"`
+	SlippageBps uint16          `json:"slippage_bps" binding:"lte=10000"` // 为0时使用默认滑点
+}
+
+// DepositQuoteRequest 直接存入底层资产的报价请求，amount 与 amount_wei 二选一
+type DepositQuoteRequest struct {
+	Amount      *decimal.Decimal `json:"amount" binding:"omitempty,gt=0"`
+	AmountWei   string           `json:"amount_wei" binding:"max=78"`
+	SlippageBps uint16           `json:"slippage_bps" binding:"lte=10000"` // 为0时使用默认滑点
+}
+
+// BridgeQuoteRequest 从其他链跨链存入资金库的报价请求
+type BridgeQuoteRequest struct {
+	FromChainID uint            `json:"from_chain_id" binding:"required"`
+	FromToken   string          `json:"from_token" binding:"required,eth_address"`
+	Amount      decimal.Decimal `json:"amount" binding:"gt=0"`
+	SlippageBps uint16          `json:"slippage_bps" binding:"lte=10000"` // 为0时使用默认滑点
+}
+
+// TrackBridgeRequest 登记用户已在源链提交的跨链交易
+type TrackBridgeRequest struct {
+	FromChainID uint            `json:"from_chain_id" binding:"required"`
+	TxHash      string          `json:"tx_hash" binding:"required,len=66,startswith=0x,hexadecimal"`
+	Bridge      string          `json:"bridge" binding:"max=50"`
+	Amount      decimal.Decimal `json:"amount" binding:"gt=0"` // 源链转出的代币数量
+}
+
+// SetLPPositionRequest 把策略标记为LP策略并以当前价格作为无常损失的基准
+type SetLPPositionRequest struct {
+	PoolType string   `json:"pool_type" binding:"required,oneof=uniswap_v2 curve"`
+	Tokens   []string `json:"tokens" binding:"required,min=2,max=8,dive,eth_address"`
+	Reason   string   `json:"reason" binding:"required,max=500"`
+}
+
+// SetUniswapV3PositionRequest 登记集中流动性策略持有的Uniswap v3头寸，token_id 为 NonfungiblePositionManager 的NFT编号
+type SetUniswapV3PositionRequest struct {
+	PositionManager string `json:"position_manager" binding:"required,eth_address"`
+	TokenID         string `json:"token_id" binding:"required,numeric,max=78"`
+	Pool            string `json:"pool" binding:"required,eth_address"`
+}
+
+// SetMorphoMarketRequest 登记借贷策略供应资金的Morpho Blue市场，morpho 为空时使用配置的合约地址
+type SetMorphoMarketRequest struct {
+	Morpho   string `json:"morpho" binding:"omitempty,eth_address"`
+	MarketID string `json:"market_id" binding:"required,len=66,startswith=0x"`
+}
+
+// SetPendleMarketRequest 登记固定利率策略持有PT的Pendle市场
+type SetPendleMarketRequest struct {
+	Market string `json:"market" binding:"required,eth_address"`
+}
+
+// RegisterKeeperRequest 登记外部keeper，address 为keeper发送交易使用的地址
+type RegisterKeeperRequest struct {
+	Address string `json:"address" binding:"required,eth_address"`
+	Name    string `json:"name" binding:"required,max=100"`
+}
+
+// RevokeKeeperRequest 停用keeper
+type RevokeKeeperRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// KeeperReportRequest keeper上报任务结果。收获任务成功时需要 tx_hash，
+// 再平衡任务成功时需要逐个策略的 transactions，失败时需要 error
+type KeeperReportRequest struct {
+	Success      *bool                        `json:"success" binding:"required"`
+	TxHash       string                       `json:"tx_hash" binding:"omitempty,len=66,startswith=0x,hexadecimal"`
+	Error        string                       `json:"error" binding:"max=1000"`
+	Transactions []service.RebalanceExecution `json:"transactions" binding:"dive"`
+}
+
+// RegisterAutomationRequest 登记已在Chainlink Automation或Gelato上创建的任务，
+// interval 为预期的执行间隔(秒)，收获任务需要 strategy_address
+type RegisterAutomationRequest struct {
+	Provider        string `json:"provider" binding:"required,oneof=chainlink gelato"`
+	TaskID          string `json:"task_id" binding:"required,max=78"`
+	Action          string `json:"action" binding:"required,oneof=harvest rebalance"`
+	VaultAddress    string `json:"vault_address" binding:"required,eth_address"`
+	StrategyAddress string `json:"strategy_address" binding:"omitempty,eth_address"`
+	Interval        uint   `json:"interval" binding:"required,gte=60"`
+}
+
+// UpdateProfileRequest 修改用户资料，整体覆盖，空字段表示清除。
+// signature 为地址本人对 service.ProfileMessage 原文的 personal_sign 签名
+type UpdateProfileRequest struct {
+	Nickname  string `json:"nickname" binding:"max=32"`
+	AvatarURL string `json:"avatar_url" binding:"omitempty,url,max=512"`
+	Email     string `json:"email" binding:"omitempty,email,max=255"`
+	IssuedAt  int64  `json:"issued_at" binding:"required"`
+	Signature string `json:"signature" binding:"required"`
+}
+
+// DataDeletionRequest 申请删除链下个人数据，signature 为对 service.DeletionMessage 原文的 personal_sign 签名
+type DataDeletionRequest struct {
+	IssuedAt  int64  `json:"issued_at" binding:"required"`
+	Signature string `json:"signature" binding:"required"`
+}
+
+// BackfillRequest 在worker中回放资金库历史事件，参数与 cmd/backfill 相同，未填写时使用相同的默认值。
+// from_block 未填写时从合约登记的部署区块开始
+type BackfillRequest struct {
+	VaultAddress  string  `json:"vault_address" binding:"required,eth_address"`
+	FromBlock     uint64  `json:"from_block"`
+	ToBlock       uint64  `json:"to_block" binding:"omitempty,gtefield=FromBlock"`
+	Confirmations uint64  `json:"confirmations"`
+	ChunkSize     uint64  `json:"chunk_size" binding:"omitempty,max=10000"`
+	RPS           float64 `json:"rps" binding:"omitempty,gt=0,max=100"`
+	Restart       bool    `json:"restart"`
+}
+
+// RegisterContractRequest 上传合约ABI。abi 为编译产物中的abi数组，需包含接口类型要求的方法和事件；
+// deployment_block 用作回放的默认起点
+type RegisterContractRequest struct {
+	Interface       string          `json:"interface" binding:"required,oneof=erc4626 strategy oracle"`
+	Name            string          `json:"name" binding:"max=100"`
+	ABI             json.RawMessage `json:"abi" binding:"required"`
+	DeploymentBlock uint64          `json:"deployment_block"`
+}
+
+// CreateIncidentRequest 创建事件，message 为第一条进展；started_at 为空时取当前时间，notify 为是否告警运维
+type CreateIncidentRequest struct {
+	Component string     `json:"component" binding:"required,max=40"`
+	Severity  string     `json:"severity" binding:"required,oneof=minor major critical"`
+	Title     string     `json:"title" binding:"required,max=200"`
+	Message   string     `json:"message" binding:"required,max=2000"`
+	StartedAt *time.Time `json:"started_at"`
+	Notify    bool       `json:"notify"`
+}
+
+// UpdateIncidentRequest 修改事件，未填写的字段不变
+type UpdateIncidentRequest struct {
+	Severity string `json:"severity" binding:"omitempty,oneof=minor major critical"`
+	Title    string `json:"title" binding:"max=200"`
+}
+
+// IncidentUpdateRequest 记录事件进展，status 为记录后事件的状态
+type IncidentUpdateRequest struct {
+	Status  string `json:"status" binding:"required,oneof=investigating identified monitoring resolved"`
+	Message string `json:"message" binding:"required,max=2000"`
+	Notify  bool   `json:"notify"`
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+// GetRoute 推荐存入某个资产的资金库(或额度不足时的拆分)，按扣除费用后的APY、风险分、剩余额度和gas成本评分，并返回推荐理由
+func (h *Handlers) GetRoute(c *gin.Context) {
+	asset := strings.TrimSpace(c.Query("asset"))
+	if asset == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "asset is required",
+		})
+		return
+	}
+	amount, err := decimal.NewFromString(c.Query("amount"))
+	if err != nil || !amount.IsPositive() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "amount must be a positive decimal",
+		})
+		return
+	}
+	var chainID uint64
+	if raw := c.Query("chain"); raw != "" {
+		if chainID, err = strconv.ParseUint(raw, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "chain must be numeric",
+			})
+			return
+		}
+	}
+
+	route, err := h.routingService.Route(c.Request.Context(), asset, uint(chainID), amount)
+	if err != nil {
+		if errors.Is(err, service.ErrRouteAssetUnknown) || errors.Is(err, service.ErrRouteNoVault) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Error(fmt.Sprintf("Failed to route %s %s: %v", amount.String(), asset, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to compute route",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"route": route,
+	})
+}
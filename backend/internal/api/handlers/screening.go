@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// checkScreening 制裁筛查命中或提供方不可用时拒绝存款意向，直接写入错误响应
+func (h *Handlers) checkScreening(c *gin.Context, vault *models.Vault, userAddress string) bool {
+	decision, err := h.screeningService.ScreenDeposit(c.Request.Context(), userAddress, vault.Address)
+	switch {
+	case errors.Is(err, service.ErrScreeningUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": err.Error(),
+			"code":  "screening_unavailable",
+		})
+		return false
+	case err != nil:
+		logger.Error(fmt.Sprintf("Failed to screen %s: %v", userAddress, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to screen address",
+		})
+		return false
+	case decision != nil && decision.Decision == models.ScreeningBlocked:
+		// 不透露命中的名单，详情只在合规复核接口中可见
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Address is not eligible to deposit",
+			"code":  "address_blocked",
+		})
+		return false
+	}
+	return true
+}
+
+// GetScreeningDecisions 管理员查看制裁筛查结论，可按 decision 和 user 过滤
+func (h *Handlers) GetScreeningDecisions(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "limit must be between 1 and 200",
+		})
+		return
+	}
+	decision := c.Query("decision")
+	switch decision {
+	case "", models.ScreeningAllowed, models.ScreeningBlocked, models.ScreeningUnavailable:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "decision must be allowed, blocked or unavailable",
+		})
+		return
+	}
+
+	decisions, err := h.screeningService.ListDecisions(decision, c.Query("user"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch screening decisions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, listed(c, decisions, len(decisions), limit))
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Search 供前端搜索框使用，?q= 为查询词，?type=vault|strategy 限定类型，?limit= 默认20条
+func (h *Handlers) Search(c *gin.Context) {
+	kind := c.Query("type")
+	if kind != "" && kind != service.SearchVault && kind != service.SearchStrategy {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "type must be vault or strategy",
+		})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 50 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "limit must be between 1 and 50",
+		})
+		return
+	}
+
+	query := c.Query("q")
+	results, err := h.searchService.Search(c.Request.Context(), query, kind, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrSearchQueryTooShort) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Error(fmt.Sprintf("Failed to search for %q: %v", query, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to search",
+		})
+		return
+	}
+
+	body := listed(c, results, len(results), limit)
+	body["query"] = query
+	c.JSON(http.StatusOK, body)
+}
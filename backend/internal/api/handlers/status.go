@@ -0,0 +1,12 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetStatus 公开状态页：各组件(API、数据库、各链索引、价格源、keeper)的当前状态和最近的异常记录
+func (h *Handlers) GetStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, h.statusService.Get())
+}
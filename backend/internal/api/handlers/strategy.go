@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/rates"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetStrategyHistory 获取策略历史表现
+func (h *Handlers) GetStrategyHistory(c *gin.Context) {
+	address := c.Param("address")
+
+	from, to, ok := parseTimeRange(c, 30*24*time.Hour)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "1000"))
+	if err != nil || limit <= 0 || limit > 5000 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "limit must be between 1 and 5000",
+		})
+		return
+	}
+
+	kind, ok := parseRate(c, rates.APY)
+	if !ok {
+		return
+	}
+
+	history, err := h.strategyService.GetHistory(c.Request.Context(), address, kind, from, to, limit)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get strategy history for %s: %v", address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch strategy history",
+		})
+		return
+	}
+
+	if history == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Strategy not found",
+		})
+		return
+	}
+
+	metadata, err := h.metadataService.Get(history.Strategy.Address)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get display metadata for %s: %v", address, err))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"strategy":               history.Strategy,
+		"history":                history.Snapshots,
+		"apy_change":             history.APYChange,
+		"earnings_change":        history.EarningsChange,
+		"impermanent_loss":       history.LP,
+		"rate":                   rates.NewBasis(kind),
+		"from":                   from,
+		"to":                     to,
+		"concentrated_liquidity": history.ConcentratedLiquidity,
+		"morpho":                 history.Morpho,
+		"savings_rate":           history.SavingsRate,
+		"fixed_rate":             history.FixedRate,
+		"metadata":               metadata,
+	})
+}
+
+// SimulateStrategy 基于历史协议利率回测假设收益
+func (h *Handlers) SimulateStrategy(c *gin.Context) {
+	var req service.SimulationRequest
+	if !bindJSON(c, &req, "Invalid simulation request") {
+		return
+	}
+	req.AssetAddress = strings.ToLower(req.AssetAddress)
+
+	if !req.From.Before(req.To) || req.To.After(time.Now().Add(time.Minute)) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "from must be before to, and to cannot be in the future",
+		})
+		return
+	}
+
+	result, err := h.simulationService.Simulate(req)
+	if err != nil {
+		if errors.Is(err, service.ErrNoRecordedRates) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		logger.Error(fmt.Sprintf("Failed to simulate %s: %v", req.Adapter, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to run simulation",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"simulation": result,
+	})
+}
+
+// parseTimeRange 解析from/to查询参数(RFC3339)，缺省为最近defaultWindow
+func parseTimeRange(c *gin.Context, defaultWindow time.Duration) (time.Time, time.Time, bool) {
+	to := time.Now()
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "to must be an RFC3339 timestamp",
+			})
+			return time.Time{}, time.Time{}, false
+		}
+		to = t
+	}
+
+	from := to.Add(-defaultWindow)
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "from must be an RFC3339 timestamp",
+			})
+			return time.Time{}, time.Time{}, false
+		}
+		from = t
+	}
+
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "from must be before to",
+		})
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetRebalanceSuggestions 获取持仓的调仓建议。建议由worker定期评估，只为订阅了 rebalance_suggestion 的用户生成
+func (h *Handlers) GetRebalanceSuggestions(c *gin.Context) {
+	address, ok := requireSelf(c)
+	if !ok {
+		return
+	}
+
+	suggestions, optedIn, err := h.suggestionService.List(address)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get rebalance suggestions for %s: %v", address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch suggestions",
+		})
+		return
+	}
+
+	body := listed(c, suggestions, len(suggestions), len(suggestions))
+	body["opted_in"] = optedIn
+	c.JSON(http.StatusOK, body)
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTokens 列出登记的代币，可按 chain_id 和 symbol 筛选
+func (h *Handlers) GetTokens(c *gin.Context) {
+	var chainID uint64
+	if raw := c.Query("chain_id"); raw != "" {
+		var err error
+		if chainID, err = strconv.ParseUint(raw, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "chain_id must be numeric",
+			})
+			return
+		}
+	}
+
+	tokens, err := h.tokenService.List(uint(chainID), strings.TrimSpace(c.Query("symbol")))
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to list tokens: %v", err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch tokens",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, listed(c, tokens, len(tokens), len(tokens)))
+}
+
+// GetToken 获取单个登记的代币
+func (h *Handlers) GetToken(c *gin.Context) {
+	chainID, err := strconv.ParseUint(c.Param("chain_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "chain_id must be numeric",
+		})
+		return
+	}
+	address := strings.ToLower(c.Param("address"))
+
+	token, err := h.tokenService.Get(uint(chainID), address)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get token %d:%s: %v", chainID, address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch token",
+		})
+		return
+	}
+	if token == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Token not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token": token,
+	})
+}
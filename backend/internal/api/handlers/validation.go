@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/pkg/address"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/shopspring/decimal"
+)
+
+// FieldError 单个字段的校验错误，field 为JSON中的字段路径，如 allocations[0].strategy_address
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// 在gin的默认校验器上注册项目通用的规则：
+//   - 错误中的字段名使用json标签
+//   - eth_address: 以太坊地址，大小写混合时校验EIP-55
+//   - decimal.Decimal 和 SignedDecimal 按数值参与 gt、lte 等比较
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	v.RegisterValidation("eth_address", func(fl validator.FieldLevel) bool {
+		_, err := address.Normalize(fl.Field().String())
+		return err == nil
+	})
+	v.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		switch d := field.Interface().(type) {
+		case decimal.Decimal:
+			return d.InexactFloat64()
+		case SignedDecimal:
+			return d.InexactFloat64()
+		}
+		return nil
+	}, decimal.Decimal{}, SignedDecimal{})
+}
+
+// bindJSON 解析并校验请求体，失败时返回400和逐字段的错误详情:
+//
+//	{"error": "Invalid deposit request", "details": [{"field": "amount", "rule": "gt", "message": "must be greater than 0"}]}
+func bindJSON(c *gin.Context, req interface{}, message string) bool {
+	err := c.ShouldBindJSON(req)
+	if err == nil {
+		return true
+	}
+
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":   message,
+		"details": fieldErrors(err),
+	})
+	return false
+}
+
+func fieldErrors(err error) []FieldError {
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+
+	switch {
+	case errors.As(err, &validationErrs):
+		details := make([]FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			details = append(details, FieldError{
+				Field:   fieldPath(fe),
+				Rule:    fe.Tag(),
+				Message: fieldMessage(fe),
+			})
+		}
+		return details
+	case errors.As(err, &typeErr):
+		return []FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: fmt.Sprintf("must be a %s", typeErr.Type.Kind()),
+		}}
+	case errors.As(err, &syntaxErr):
+		return []FieldError{{Field: "body", Rule: "json", Message: fmt.Sprintf("malformed JSON at offset %d", syntaxErr.Offset)}}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return []FieldError{{Field: "body", Rule: "json", Message: "truncated JSON"}}
+	case errors.Is(err, io.EOF):
+		return []FieldError{{Field: "body", Rule: "required", Message: "request body is required"}}
+	}
+	return []FieldError{{Field: "body", Rule: "json", Message: err.Error()}}
+}
+
+// fieldPath 去掉命名空间开头的结构体名
+func fieldPath(fe validator.FieldError) string {
+	_, path, found := strings.Cut(fe.Namespace(), ".")
+	if !found {
+		return fe.Field()
+	}
+	return path
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	unit := "characters"
+	if kind := fe.Kind(); kind == reflect.Slice || kind == reflect.Map || kind == reflect.Array {
+		unit = "items"
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "eth_address":
+		return "must be a valid Ethereum address"
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "gte":
+		return "must be at least " + fe.Param()
+	case "lt":
+		return "must be less than " + fe.Param()
+	case "lte":
+		return "must be at most " + fe.Param()
+	case "min":
+		if fe.Kind() == reflect.String || unit == "items" {
+			return fmt.Sprintf("must contain at least %s %s", fe.Param(), unit)
+		}
+		return "must be at least " + fe.Param()
+	case "max":
+		if fe.Kind() == reflect.String || unit == "items" {
+			return fmt.Sprintf("must contain at most %s %s", fe.Param(), unit)
+		}
+		return "must be at most " + fe.Param()
+	case "len":
+		return fmt.Sprintf("must contain exactly %s %s", fe.Param(), unit)
+	case "numeric":
+		return "must be numeric"
+	case "startswith":
+		return "must start with " + fe.Param()
+	case "hexadecimal":
+		return "must be hexadecimal"
+	case "url":
+		return "must be a valid URL"
+	case "email":
+		return "must be a valid email address"
+	}
+	return fmt.Sprintf("failed %s validation", fe.Tag())
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetVaultAllocations 设置资金库在各策略上的目标分配
+func (h *Handlers) SetVaultAllocations(c *gin.Context) {
+	address := c.Param("address")
+
+	var req SetAllocationsRequest
+	if !bindJSON(c, &req, "Invalid allocation request") {
+		return
+	}
+
+	vault, err := h.vaultService.GetVaultDetail(c.Request.Context(), address)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch vault details",
+		})
+		return
+	}
+	if vault == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Vault not found",
+		})
+		return
+	}
+
+	targets := make(map[string]uint16, len(req.Allocations))
+	for _, a := range req.Allocations {
+		targets[strings.ToLower(a.StrategyAddress)] = a.TargetBps
+	}
+
+	if err := h.vaultService.SetTargetAllocations(vault, targets); err != nil {
+		if errors.Is(err, service.ErrInvalidAllocation) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		logger.Error(fmt.Sprintf("Failed to set allocations for vault %s: %v", address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update allocations",
+		})
+		return
+	}
+
+	vault, err = h.vaultService.GetVaultDetail(c.Request.Context(), address)
+	if err != nil || vault == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch vault details",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"vault":       address,
+		"allocations": h.vaultService.GetAllocations(vault),
+	})
+}
+
+// SetVaultDepositCap 设置或取消资金库的存款上限
+func (h *Handlers) SetVaultDepositCap(c *gin.Context) {
+	address := c.Param("address")
+
+	var req SetDepositCapRequest
+	if !bindJSON(c, &req, "Invalid deposit cap request") {
+		return
+	}
+
+	vault, err := h.vaultService.GetVaultDetail(c.Request.Context(), address)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch vault details",
+		})
+		return
+	}
+	if vault == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Vault not found",
+		})
+		return
+	}
+
+	vault, err = h.vaultControlService.SetDepositCap(c.Request.Context(), vault, c.GetString("admin_address"), req.DepositCap, req.Reason)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to set deposit cap of vault %s: %v", address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to set deposit cap",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"vault": h.vaultService.WithUSD(c.Request.Context(), vault),
+	})
+}
+
+// SetVaultCategory 设置资金库的分类和标签
+func (h *Handlers) SetVaultCategory(c *gin.Context) {
+	address := c.Param("address")
+
+	var req SetVaultCategoryRequest
+	if !bindJSON(c, &req, "Invalid category request") {
+		return
+	}
+
+	vault, err := h.vaultService.GetVaultDetail(c.Request.Context(), address)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch vault details",
+		})
+		return
+	}
+	if vault == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Vault not found",
+		})
+		return
+	}
+
+	vault, err = h.vaultControlService.SetCategory(c.Request.Context(), vault, c.GetString("admin_address"), req.Category, req.Tags)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidVaultCategory) || errors.Is(err, service.ErrInvalidVaultTags) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		logger.Error(fmt.Sprintf("Failed to set category of vault %s: %v", address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to set category",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"vault": h.vaultService.WithUSD(c.Request.Context(), vault),
+	})
+}
+
+// GetVaultCategories 各分类和标签下的活跃资金库数量，前端据此构建浏览页导航
+func (h *Handlers) GetVaultCategories(c *gin.Context) {
+	vaults, err := h.vaultService.GetVaults(c.Request.Context())
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get vaults: %v", err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch vaults",
+		})
+		return
+	}
+
+	categories := make([]gin.H, 0, len(models.VaultCategories))
+	for _, category := range models.VaultCategories {
+		count := 0
+		for i := range vaults {
+			if vaults[i].Category == category {
+				count++
+			}
+		}
+		categories = append(categories, gin.H{"category": category, "count": count})
+	}
+	tags := make(map[string]int)
+	for i := range vaults {
+		for _, tag := range vaults[i].Tags {
+			tags[tag]++
+		}
+	}
+
+	body := listed(c, categories, len(categories), len(categories))
+	body["tags"] = tags
+	c.JSON(http.StatusOK, body)
+}
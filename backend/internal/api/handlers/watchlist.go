@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/chspring1/mya-platform/backend/internal/service"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetWatchlist 获取收藏的资金库及其实时APY、TVL，支持 ?currency= 换算法币
+func (h *Handlers) GetWatchlist(c *gin.Context) {
+	address, ok := requireSelf(c)
+	if !ok {
+		return
+	}
+	fx, ok := h.parseCurrency(c)
+	if !ok {
+		return
+	}
+
+	items, err := h.watchlistService.List(c.Request.Context(), address)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get watchlist for %s: %v", address, err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch watchlist",
+		})
+		return
+	}
+	if fx != nil {
+		for i := range items {
+			items[i].ApplyFX(fx.Rate)
+		}
+	}
+
+	body := listed(c, items, len(items), len(items))
+	body["fx"] = fx
+	c.JSON(http.StatusOK, body)
+}
+
+// AddToWatchlist 收藏资金库，已收藏时返回200
+func (h *Handlers) AddToWatchlist(c *gin.Context) {
+	address, ok := requireSelf(c)
+	if !ok {
+		return
+	}
+	vault, ok := normalizeAddress(c, "vault address", c.Param("vault"))
+	if !ok {
+		return
+	}
+
+	added, err := h.watchlistService.Add(c.Request.Context(), address, vault)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrWatchlistVaultNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Vault not found"})
+		case errors.Is(err, service.ErrWatchlistFull):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			logger.Error(fmt.Sprintf("Failed to add %s to watchlist of %s: %v", vault, address, err))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update watchlist"})
+		}
+		return
+	}
+
+	status := http.StatusOK
+	if added {
+		status = http.StatusCreated
+	}
+	c.JSON(status, gin.H{
+		"vault_address": vault,
+		"watched":       true,
+	})
+}
+
+// RemoveFromWatchlist 取消收藏资金库
+func (h *Handlers) RemoveFromWatchlist(c *gin.Context) {
+	address, ok := requireSelf(c)
+	if !ok {
+		return
+	}
+	vault, ok := normalizeAddress(c, "vault address", c.Param("vault"))
+	if !ok {
+		return
+	}
+
+	removed, err := h.watchlistService.Remove(address, vault)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update watchlist",
+		})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Vault is not in watchlist",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetZapQuote 报价用其他代币一键存入资金库：返回兑换路由、预期份额、价格影响和可直接签名的交易。
+// 交易由用户钱包直接提交，平台无法拒绝过期的执行，因此不保存报价，也没有报价ID和有效期，只以 min_shares 保护
+func (h *Handlers) GetZapQuote(c *gin.Context) {
+	vaultAddress := c.Param("address")
+	userAddress := c.GetString("user_address")
+
+	var req ZapQuoteRequest
+	if !bindJSON(c, &req, "Invalid zap quote request") {
+		return
+	}
+
+	vault, err := h.vaultService.GetVaultDetail(c.Request.Context(), vaultAddress)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch vault details",
+		})
+		return
+	}
+	if vault == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Vault not found",
+		})
+		return
+	}
+	if !h.checkDepositAllowed(c, vault, userAddress) {
+		return
+	}
+
+	quote, err := h.zapService.Quote(c.Request.Context(), vault, userAddress, strings.ToLower(req.TokenIn), req.Amount, req.SlippageBps)
+	if quoteFailed(c, "zap into vault "+vaultAddress, err) {
+		return
+	}
+
+	// 预期存入的资产超过剩余额度时交易会在链上失败
+	if remaining := vault.RemainingCapacity(); remaining != nil && quote.ExpectedAssets.GreaterThan(*remaining) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":              "Deposit exceeds vault capacity",
+			"remaining_capacity": remaining,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"quote": quote,
+	})
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/pkg/address"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 用户路由的 :address 也接受ENS名称(如 vitalik.eth)
+const userRoutePrefix = "/api/v1/users/:address"
+
+// NormalizeAddresses 校验路径中的 :address 参数(含EIP-55校验和)，并改为小写供后续查询使用。
+// 用户路由上的ENS名称先解析为地址，原名称保存在上下文的 ens_name 中
+func NormalizeAddresses() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for i, param := range c.Params {
+			if param.Key != "address" {
+				continue
+			}
+
+			value := param.Value
+			if strings.HasPrefix(c.FullPath(), userRoutePrefix) && blockchain.IsENSName(strings.ToLower(value)) {
+				resolved, ok := resolveENS(c, value)
+				if !ok {
+					return
+				}
+				c.Set("ens_name", strings.ToLower(value))
+				value = resolved
+			}
+
+			normalized, err := address.Normalize(value)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+					"error":   "Invalid Ethereum address",
+					"details": err.Error(),
+				})
+				return
+			}
+			c.Params[i].Value = normalized
+		}
+		c.Next()
+	}
+}
+
+// resolveENS 解析ENS名称，失败时直接写入错误响应
+func resolveENS(c *gin.Context, name string) (string, bool) {
+	resolved, err := blockchain.ResolveENS(c.Request.Context(), name)
+	switch {
+	case err == nil:
+		return resolved, true
+	case errors.Is(err, blockchain.ErrENSNotFound):
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+			"error": fmt.Sprintf("ENS name %s does not resolve to an address", name),
+		})
+	case errors.Is(err, blockchain.ErrENSDisabled):
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "ENS names are not supported, use a hex address",
+		})
+	default:
+		logger.Error(fmt.Sprintf("Failed to resolve ENS name %s: %v", name, err))
+		c.AbortWithStatusJSON(http.StatusBadGateway, gin.H{
+			"error": "Failed to resolve ENS name",
+		})
+	}
+	return "", false
+}
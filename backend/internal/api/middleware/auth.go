@@ -3,7 +3,9 @@ package middleware
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
+	"github.com/chspring1/mya-platform/backend/pkg/address"
 	"github.com/chspring1/mya-platform/backend/pkg/logger"
 	"github.com/gin-gonic/gin"
 )
@@ -21,15 +23,17 @@ func AuthRequired() gin.HandlerFunc {
 			return
 		}
 
-		// 简单的地址格式验证
-		if len(userAddress) != 42 || userAddress[:2] != "0x" {
-			logger.Info(fmt.Sprintf("Authentication failed: invalid address format %s", userAddress))
+		// 校验地址格式和EIP-55校验和，之后统一使用小写地址
+		normalized, err := address.Normalize(userAddress)
+		if err != nil {
+			logger.Info(fmt.Sprintf("Authentication failed: %v: %s", err, userAddress))
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": "Invalid Ethereum address format",
 			})
 			c.Abort()
 			return
 		}
+		userAddress = normalized
 
 		// 设置用户地址到上下文
 		c.Set("user_address", userAddress)
@@ -38,16 +42,16 @@ func AuthRequired() gin.HandlerFunc {
 	}
 }
 
+// adminAddresses 临时实现：特定管理员地址(小写)
+var adminAddresses = map[string]bool{
+	"0xadminaddress": true,
+	"0x742d35cc6634c0532925a3b8dc9f1a37cd7e8b5d": true, // 示例地址
+}
+
 // AdminRequired 需要管理员权限的中间件
 func AdminRequired() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userAddress := c.GetHeader("X-User-Address")
-
-		// 临时实现：检查特定管理员地址
-		adminAddresses := map[string]bool{
-			"0xAdminAddress": true,
-			"0x742d35Cc6634C0532925a3b8Dc9F1a37cD7e8b5d": true, // 示例地址
-		}
+		userAddress := strings.ToLower(c.GetHeader("X-User-Address"))
 
 		if !adminAddresses[userAddress] {
 			logger.Info(fmt.Sprintf("Admin access denied for: %s", userAddress))
@@ -58,6 +62,7 @@ func AdminRequired() gin.HandlerFunc {
 			return
 		}
 
+		c.Set("admin_address", userAddress)
 		logger.Info(fmt.Sprintf("Admin access granted: %s", userAddress))
 		c.Next()
 	}
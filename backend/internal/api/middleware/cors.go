@@ -1,19 +1,111 @@
 package middleware
 
-import "github.com/gin-gonic/gin"
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// originMatcher 匹配单个允许的来源：精确匹配、"*"，或 "https://*.example.com" 子域名通配
+type originMatcher struct {
+	any    bool
+	exact  string
+	prefix string // 通配符之前的部分，如 "https://"
+	suffix string // 通配符之后的部分，如 ".example.com"
+}
+
+func newOriginMatcher(pattern string) originMatcher {
+	pattern = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(pattern), "/"))
+	if pattern == "*" {
+		return originMatcher{any: true}
+	}
+	if prefix, suffix, ok := strings.Cut(pattern, "*."); ok {
+		return originMatcher{prefix: prefix, suffix: "." + suffix}
+	}
+	return originMatcher{exact: pattern}
+}
+
+func (m originMatcher) match(origin string) bool {
+	switch {
+	case m.any:
+		return true
+	case m.exact != "":
+		return origin == m.exact
+	}
+	if !strings.HasPrefix(origin, m.prefix) || !strings.HasSuffix(origin, m.suffix) {
+		return false
+	}
+	// 通配部分只能是子域名，不能借助路径、端口或userinfo绕过
+	sub := origin[len(m.prefix) : len(origin)-len(m.suffix)]
+	return sub != "" && !strings.ContainsAny(sub, "/:@?#")
+}
+
+// CORS 按配置的白名单处理跨域请求。允许的来源原样回显在 Access-Control-Allow-Origin 中，
+// 不在白名单中的来源不返回CORS头，预检请求返回403
+func CORS(cfg config.CORSConfig) gin.HandlerFunc {
+	matchers := make([]originMatcher, 0, len(cfg.AllowedOrigins))
+	for _, origin := range cfg.AllowedOrigins {
+		matchers = append(matchers, newOriginMatcher(origin))
+	}
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	exposed := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(cfg.MaxAge)
+
+	allowed := func(origin string) bool {
+		origin = strings.ToLower(origin)
+		for _, m := range matchers {
+			if m.match(origin) {
+				return true
+			}
+		}
+		return false
+	}
 
-func CORS() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-User-Address")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Add("Vary", "Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		if !allowed(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
 			return
 		}
 
+		header.Set("Access-Control-Allow-Origin", origin)
+		if cfg.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+			header.Set("Access-Control-Allow-Methods", methods)
+			header.Set("Access-Control-Allow-Headers", headers)
+			if cfg.MaxAge > 0 {
+				header.Set("Access-Control-Max-Age", maxAge)
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		if exposed != "" {
+			header.Set("Access-Control-Expose-Headers", exposed)
+		}
 		c.Next()
 	}
 }
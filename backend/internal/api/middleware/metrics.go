@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/pkg/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics 记录请求数和耗时，按路由模板而不是实际路径打标签以控制基数
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		latency := time.Since(start)
+		status := c.Writer.Status()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(status)).Inc()
+		metrics.HTTPDuration.WithLabelValues(c.Request.Method, route).Observe(latency.Seconds())
+		metrics.Requests.Observe(latency, status >= 500)
+	}
+}
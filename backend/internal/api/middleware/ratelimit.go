@@ -2,10 +2,12 @@ package middleware
 
 import (
 	"fmt"
+	"math"
 	"net/http"
 	"sync"
 	"time"
 
+	"github.com/chspring1/mya-platform/backend/pkg/config"
 	"github.com/chspring1/mya-platform/backend/pkg/logger"
 	"github.com/gin-gonic/gin"
 )
@@ -43,7 +45,35 @@ func NewRateLimiter(requestsPerMinute int) *RateLimiter {
 func (rl *RateLimiter) Allow(clientIP string) bool {
 	rl.mutex.Lock()
 	defer rl.mutex.Unlock()
+	return rl.allow(clientIP)
+}
+
+// AllowAll 所有键都还有剩余次数时各计数一次并返回true；否则都不计数，返回第一个已用完的键
+func (rl *RateLimiter) AllowAll(keys ...string) (string, bool) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	now := time.Now()
+	for _, key := range keys {
+		client, exists := rl.clients[key]
+		if !exists {
+			continue
+		}
+		client.mutex.Lock()
+		exhausted := now.Sub(client.lastReset) < rl.window && client.requests >= rl.limit
+		client.mutex.Unlock()
+		if exhausted {
+			return key, false
+		}
+	}
+	for _, key := range keys {
+		rl.allow(key)
+	}
+	return "", true
+}
 
+// allow 调用方需持有 rl.mutex
+func (rl *RateLimiter) allow(clientIP string) bool {
 	client, exists := rl.clients[clientIP]
 	if !exists {
 		client = &Client{
@@ -75,6 +105,20 @@ func (rl *RateLimiter) Allow(clientIP string) bool {
 	return true
 }
 
+// Limit 当前每分钟允许的请求数
+func (rl *RateLimiter) Limit() int {
+	rl.mutex.RLock()
+	defer rl.mutex.RUnlock()
+	return rl.limit
+}
+
+// SetLimit 修改每分钟允许的请求数，已计数的请求保留
+func (rl *RateLimiter) SetLimit(requestsPerMinute int) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	rl.limit = requestsPerMinute
+}
+
 // GetRemainingRequests 获取剩余请求次数
 func (rl *RateLimiter) GetRemainingRequests(clientIP string) int {
 	rl.mutex.RLock()
@@ -100,6 +144,30 @@ func (rl *RateLimiter) GetRemainingRequests(clientIP string) int {
 	return remaining
 }
 
+// Quota 获取剩余请求次数和计数窗口的重置时间，没有进行中的窗口时按下一个请求现在开始计算
+func (rl *RateLimiter) Quota(clientIP string) (int, time.Time) {
+	rl.mutex.RLock()
+	defer rl.mutex.RUnlock()
+
+	now := time.Now()
+	client, exists := rl.clients[clientIP]
+	if !exists {
+		return rl.limit, now.Add(rl.window)
+	}
+
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	if now.Sub(client.lastReset) >= rl.window {
+		return rl.limit, now.Add(rl.window)
+	}
+	remaining := rl.limit - client.requests
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining, client.lastReset.Add(rl.window)
+}
+
 // cleanup 定期清理过期的客户端记录
 func (rl *RateLimiter) cleanup() {
 	ticker := time.NewTicker(5 * time.Minute)
@@ -119,42 +187,191 @@ func (rl *RateLimiter) cleanup() {
 	}
 }
 
-var globalRateLimiter *RateLimiter
+// 限流策略，按路由组挂载，各策略独立计数
+const (
+	PolicyGlobal  = "global"  // 挂在路由引擎上，所有请求在认证之前按IP计数
+	PolicyDefault = "default" // 未归入其他策略的接口，限额为 server.rate_limit
+	PolicyPublic  = "public"  // 公开的只读接口
+	PolicyWrite   = "write"   // 需要认证的写接口
+	PolicyAdmin   = "admin"   // 管理员接口
+	PolicyExport  = "export"  // 数据导出等开销大的接口
+)
+
+var (
+	rateLimiters   = make(map[string]*RateLimiter)
+	rateLimitersMu sync.Mutex
+	subscribeOnce  sync.Once
+)
+
+// policyLimit 策略的每分钟请求数，未配置(0)时使用 server.rate_limit
+func policyLimit(cfg *config.Config, policy string) int {
+	var limit int
+	switch policy {
+	case PolicyGlobal:
+		limit = cfg.Server.RateLimits.Global
+	case PolicyPublic:
+		limit = cfg.Server.RateLimits.Public
+	case PolicyWrite:
+		limit = cfg.Server.RateLimits.Write
+	case PolicyAdmin:
+		limit = cfg.Server.RateLimits.Admin
+	case PolicyExport:
+		limit = cfg.Server.RateLimits.Export
+	}
+	if limit <= 0 {
+		limit = cfg.Server.RateLimit
+	}
+	return limit
+}
+
+// policyLimiter 获取或创建策略的限流器，限额随 server.rate_limit 和 server.rate_limits 热加载
+func policyLimiter(policy string) *RateLimiter {
+	rateLimitersMu.Lock()
+	defer rateLimitersMu.Unlock()
+
+	subscribeOnce.Do(func() {
+		config.Subscribe(func(prev, next *config.Config) {
+			rateLimitersMu.Lock()
+			defer rateLimitersMu.Unlock()
+			for name, limiter := range rateLimiters {
+				limit := policyLimit(next, name)
+				if limit > 0 && limit != policyLimit(prev, name) {
+					limiter.SetLimit(limit)
+					logger.Info(fmt.Sprintf("Rate limit of %s policy changed to %d requests per minute", name, limit))
+				}
+			}
+		})
+	})
+
+	limiter, ok := rateLimiters[policy]
+	if !ok {
+		limiter = NewRateLimiter(policyLimit(config.Load(), policy))
+		rateLimiters[policy] = limiter
+	}
+	return limiter
+}
+
+// rateLimitKeys 所有请求都按IP计数；已认证的请求同时按地址计数，两者都有剩余次数才放行。
+// 认证地址来自未经签名校验的请求头，只按地址计数时轮换地址即可绕过限额
+func rateLimitKeys(c *gin.Context) []string {
+	keys := []string{"ip:" + c.ClientIP()}
+	if address := c.GetString("user_address"); address != "" {
+		keys = append(keys, "user:"+address)
+	} else if address := c.GetString("admin_address"); address != "" {
+		keys = append(keys, "user:"+address)
+	}
+	return keys
+}
 
-// RateLimit 速率限制中间件
-func RateLimit(requestsPerMinute int) gin.HandlerFunc {
-	if globalRateLimiter == nil {
-		globalRateLimiter = NewRateLimiter(requestsPerMinute)
+// tightestQuota 多个计数键中剩余次数最少的一个的剩余次数和重置时间
+func tightestQuota(limiter *RateLimiter, keys []string) (int, time.Time) {
+	remaining, reset := limiter.Quota(keys[0])
+	for _, key := range keys[1:] {
+		if r, t := limiter.Quota(key); r < remaining {
+			remaining, reset = r, t
+		}
 	}
+	return remaining, reset
+}
+
+// RateLimit 按策略限流的中间件。认证路由组需挂在认证中间件之后，才能按地址计数
+func RateLimit(policy string) gin.HandlerFunc {
+	limiter := policyLimiter(policy)
 
 	return func(c *gin.Context) {
-		clientIP := c.ClientIP()
+		keys := rateLimitKeys(c)
+		requestsPerMinute := limiter.Limit()
 
-		if !globalRateLimiter.Allow(clientIP) {
-			remaining := globalRateLimiter.GetRemainingRequests(clientIP)
+		if key, ok := limiter.AllowAll(keys...); !ok {
+			remaining, reset := limiter.Quota(key)
 
 			// 记录速率限制日志
-			logger.Info(fmt.Sprintf("Rate limit exceeded for IP: %s", clientIP))
+			logger.Info(fmt.Sprintf("Rate limit of %s policy exceeded for %s", policy, key))
 
+			c.Header("X-RateLimit-Policy", policy)
 			c.Header("X-RateLimit-Limit", fmt.Sprintf("%d", requestsPerMinute))
 			c.Header("X-RateLimit-Remaining", fmt.Sprintf("%d", remaining))
-			c.Header("X-RateLimit-Reset", fmt.Sprintf("%d", time.Now().Add(time.Minute).Unix()))
+			c.Header("X-RateLimit-Reset", fmt.Sprintf("%d", reset.Unix()))
 
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error":       "Rate limit exceeded",
 				"message":     fmt.Sprintf("Too many requests. Limit: %d requests per minute", requestsPerMinute),
-				"retry_after": 60,
+				"policy":      policy,
+				"retry_after": secondsUntil(reset),
 			})
 			c.Abort()
 			return
 		}
 
-		// 添加速率限制头信息
-		remaining := globalRateLimiter.GetRemainingRequests(clientIP)
+		// 添加速率限制头信息，同一请求经过多个策略时以最后(最具体)的策略为准
+		remaining, reset := tightestQuota(limiter, keys)
+		c.Header("X-RateLimit-Policy", policy)
 		c.Header("X-RateLimit-Limit", fmt.Sprintf("%d", requestsPerMinute))
 		c.Header("X-RateLimit-Remaining", fmt.Sprintf("%d", remaining))
-		c.Header("X-RateLimit-Reset", fmt.Sprintf("%d", time.Now().Add(time.Minute).Unix()))
+		c.Header("X-RateLimit-Reset", fmt.Sprintf("%d", reset.Unix()))
 
 		c.Next()
 	}
 }
+
+// Quota 客户端在一个限流策略下的当前配额。Key 为计数依据：ip 只按出口IP，address 同时按认证地址和出口IP，
+// Remaining 取两者中较少的一个
+type Quota struct {
+	Policy    string    `json:"policy"`
+	Key       string    `json:"key"`
+	Scope     string    `json:"scope"`
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"`
+	ResetIn   int       `json:"reset_in"` // 距重置的秒数
+}
+
+// Quotas 获取客户端在各策略下的配额，不计入任何策略。未认证的请求(健康检查、公开接口、keeper接口)按IP计数；
+// userAddress 非空时加上按地址计数的已认证接口，调用方须已通过 AuthRequired。不返回管理员策略，避免泄露地址是否为管理员
+func Quotas(clientIP, userAddress string) []Quota {
+	type scope struct {
+		policy, key, description string
+	}
+	scopes := []scope{
+		{PolicyGlobal, "ip", "All requests, counted before authentication"},
+		{PolicyDefault, "ip", "Health checks, metrics and keeper endpoints"},
+		{PolicyPublic, "ip", "Public read endpoints"},
+	}
+	if userAddress != "" {
+		scopes = append(scopes,
+			scope{PolicyDefault, "address", "Authenticated endpoints, including writes and exports"},
+			scope{PolicyWrite, "address", "Authenticated write endpoints"},
+			scope{PolicyExport, "address", "User data export"},
+		)
+	}
+
+	quotas := make([]Quota, 0, len(scopes))
+	for _, s := range scopes {
+		// 与 rateLimitKeys 的计数键一致
+		keys := []string{"ip:" + clientIP}
+		if s.key == "address" {
+			keys = append(keys, "user:"+userAddress)
+		}
+		limiter := policyLimiter(s.policy)
+		remaining, reset := tightestQuota(limiter, keys)
+		quotas = append(quotas, Quota{
+			Policy:    s.policy,
+			Key:       s.key,
+			Scope:     s.description,
+			Limit:     limiter.Limit(),
+			Remaining: remaining,
+			ResetAt:   reset,
+			ResetIn:   secondsUntil(reset),
+		})
+	}
+	return quotas
+}
+
+// secondsUntil 距t的整秒数，不足一秒按一秒计
+func secondsUntil(t time.Time) int {
+	seconds := int(math.Ceil(time.Until(t).Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
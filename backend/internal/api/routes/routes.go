@@ -1,12 +1,18 @@
 package routes
 
 import (
+	"net/http/pprof"
+
 	"github.com/chspring1/mya-platform/backend/internal/api/handlers"
 	"github.com/chspring1/mya-platform/backend/internal/api/middleware"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/metrics"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRouter() *gin.Engine {
+// SetupRouter 注册中间件和全部路由，repos 为main基于数据库连接构建的核心仓储
+func SetupRouter(repos *repository.Repositories) *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 
 	router := gin.New()
@@ -14,47 +20,208 @@ func SetupRouter() *gin.Engine {
 	// 使用中间件
 	router.Use(gin.Recovery())
 	router.Use(middleware.Logger())
-	router.Use(middleware.CORS())
+	router.Use(middleware.Metrics())
+	router.Use(middleware.Compress(config.Load().Server.Compression))
+	router.Use(middleware.CORS(config.Load().Server.CORS))
 	router.Use(middleware.Security())
-	router.Use(middleware.RateLimit(60))
+	// 全局限流在认证之前按IP计数，认证失败、404等请求同样受限
+	router.Use(middleware.RateLimit(middleware.PolicyGlobal))
+	router.Use(middleware.BodyLimit(config.Load().Server.MaxBodySize))
+	router.Use(middleware.NormalizeAddresses())
 
 	// 创建 handlers
-	handlers := handlers.NewHandlers()
+	handlers := handlers.NewHandlers(repos)
 
-	// 健康检查
-	router.GET("/health", handlers.HealthCheck)
+	// 各路由组再按策略限流，认证路由组挂在认证之后同时按地址计数，策略见 server.rate_limits
+	ops := router.Group("/")
+	ops.Use(middleware.RateLimit(middleware.PolicyDefault))
+	{
+		// 健康检查
+		ops.GET("/health", handlers.HealthCheck)
+		ops.GET("/health/live", handlers.Liveness)
+		ops.GET("/health/ready", handlers.Readiness)
+
+		// Prometheus指标
+		ops.GET("/metrics", gin.WrapH(metrics.Handler()))
+	}
 
 	// API v1 路由组
 	v1 := router.Group("/api/v1")
 	{
 		// 公开路由
-		v1.GET("/vaults", handlers.GetVaults)
-		v1.GET("/vaults/:address", handlers.GetVaultDetail)
-		v1.GET("/strategies", handlers.GetStrategies)
-		v1.GET("/apy", handlers.GetAPYData)
+		public := v1.Group("/")
+		public.Use(middleware.RateLimit(middleware.PolicyPublic))
+		{
+			public.GET("/vaults", handlers.GetVaults)
+			public.GET("/vaults/categories", handlers.GetVaultCategories)
+			public.GET("/vaults/:address", handlers.GetVaultDetail)
+			public.GET("/vaults/:address/transactions", handlers.GetVaultTransactions)
+			public.GET("/vaults/:address/events", handlers.GetVaultEvents)
+			public.GET("/vaults/:address/harvests", handlers.GetVaultHarvests)
+			public.GET("/vaults/:address/harvests/attribution", handlers.GetVaultHarvestAttribution)
+			public.GET("/vaults/:address/apy-history", handlers.GetVaultAPYHistory)
+			public.GET("/vaults/:address/share-price", handlers.GetVaultSharePrice)
+			public.GET("/vaults/:address/share-price/history", handlers.GetVaultSharePriceHistory)
+			public.GET("/vaults/:address/preview-deposit", handlers.GetVaultPreviewDeposit)
+			public.GET("/vaults/:address/preview-redeem", handlers.GetVaultPreviewRedeem)
+			public.GET("/vaults/:address/withdraw-preview", handlers.GetVaultWithdrawPreview)
+			public.GET("/vaults/:address/gas-comparison", handlers.GetVaultGasComparison)
+			public.GET("/strategies", handlers.GetStrategies)
+			public.GET("/strategies/:address/history", handlers.GetStrategyHistory)
+			public.GET("/strategies/:address/harvests", handlers.GetStrategyHarvests)
+			public.GET("/strategies/:address/impermanent-loss", handlers.GetStrategyImpermanentLoss)
+			public.POST("/strategies/simulate", handlers.SimulateStrategy)
+			public.GET("/apy", handlers.GetAPYData)
+			public.GET("/search", handlers.Search)
+			public.GET("/route", handlers.GetRoute)
+			public.POST("/advisor", handlers.Advise)
+			public.GET("/status", handlers.GetStatus)
+			public.GET("/limits", handlers.GetRateLimits)
+			public.GET("/prices", handlers.GetTokenPrice)
+			public.GET("/prices/history", handlers.GetTokenPriceHistory)
+			public.GET("/tokens", handlers.GetTokens)
+			public.GET("/tokens/:chain_id/:address", handlers.GetToken)
+		}
 
-		// 需要认证的路由组
+		// 需要认证的路由组，认证后同时按IP和地址计数；读接口按默认策略限流
 		auth := v1.Group("/")
 		auth.Use(middleware.AuthRequired())
+		auth.Use(middleware.RateLimit(middleware.PolicyDefault))
 		{
 			auth.GET("/users/:address", handlers.GetUserInfo)
+			auth.GET("/users/:address/export", middleware.RateLimit(middleware.PolicyExport), handlers.ExportUserData)
+			auth.GET("/users/:address/deletion", handlers.GetDataDeletionRequests)
 			auth.GET("/users/:address/positions", handlers.GetUserPositions)
-			auth.POST("/vaults/:address/deposit", handlers.DepositToVault)
-			auth.POST("/vaults/:address/withdraw", handlers.WithdrawFromVault)
+			auth.GET("/users/:address/transactions", handlers.GetUserTransactions)
+			auth.GET("/users/:address/activity", handlers.GetUserActivity)
+			auth.GET("/users/:address/watchlist", handlers.GetWatchlist)
+			auth.GET("/users/:address/suggestions", handlers.GetRebalanceSuggestions)
+			auth.GET("/users/:address/notifications", handlers.GetNotificationSettings)
+			auth.GET("/users/:address/notifications/webhook/deliveries", handlers.GetWebhookDeliveries)
+			auth.GET("/users/:address/limits", handlers.GetUserRateLimits)
+			auth.GET("/intents/:id", handlers.GetIntent)
+			auth.GET("/deposits/batch/:id", handlers.GetBatchDeposit)
+		}
+
+		// 需要认证的写接口，同时计入默认策略
+		write := auth.Group("/")
+		write.Use(middleware.RateLimit(middleware.PolicyWrite))
+		{
+			write.PUT("/users/:address/profile", handlers.UpdateUserProfile)
+			write.POST("/users/:address/deletion", handlers.RequestDataDeletion)
+			write.POST("/users/:address/watchlist/:vault", handlers.AddToWatchlist)
+			write.DELETE("/users/:address/watchlist/:vault", handlers.RemoveFromWatchlist)
+			write.POST("/users/:address/notifications/channels", handlers.RegisterNotificationChannel)
+			write.POST("/users/:address/notifications/channels/:type/verify", handlers.VerifyNotificationChannel)
+			write.DELETE("/users/:address/notifications/channels/:type", handlers.DeleteNotificationChannel)
+			write.POST("/users/:address/notifications/webhook/secret", handlers.RotateWebhookSecret)
+			write.POST("/users/:address/notifications/subscriptions", handlers.CreateNotificationSubscription)
+			write.DELETE("/users/:address/notifications/subscriptions/:id", handlers.DeleteNotificationSubscription)
+			write.POST("/users/:address/notifications/rules", handlers.CreateAPYAlertRule)
+			write.DELETE("/users/:address/notifications/rules/:id", handlers.DeleteAPYAlertRule)
+			write.POST("/vaults/:address/quote", handlers.GetDepositQuote)
+			write.POST("/vaults/:address/deposit", handlers.DepositToVault)
+			write.POST("/vaults/:address/zap/quote", handlers.GetZapQuote)
+			write.POST("/vaults/:address/bridge/quote", handlers.GetBridgeQuote)
+			write.POST("/vaults/:address/bridge/transactions", handlers.TrackBridgeTransaction)
+			write.POST("/vaults/:address/withdraw", handlers.WithdrawFromVault)
+			write.POST("/quotes/:id/refresh", handlers.RefreshQuote)
+			write.POST("/deposits/batch", handlers.BatchDeposit)
+			write.POST("/deposits/batch/:id/execution", handlers.ReportBatchExecution)
+			write.POST("/intents/:id/execution", handlers.ReportIntentExecution)
 		}
 
 		// 管理员路由组
 		admin := v1.Group("/admin")
 		admin.Use(middleware.AdminRequired())
+		admin.Use(middleware.RateLimit(middleware.PolicyAdmin))
 		{
 			admin.GET("/stats", handlers.GetSystemStats)
 			admin.POST("/vaults/:address/emergency-stop", handlers.EmergencyStopVault)
+			admin.PUT("/vaults/:address/mode", handlers.SetVaultMode)
+			admin.GET("/audit-log", handlers.GetAuditLog)
+			admin.GET("/compliance/screenings", handlers.GetScreeningDecisions)
+			admin.PUT("/vaults/:address/allocations", handlers.SetVaultAllocations)
+			admin.PUT("/vaults/:address/fees", handlers.SetVaultFees)
+			admin.PUT("/vaults/:address/deposit-cap", handlers.SetVaultDepositCap)
+			admin.PUT("/vaults/:address/category", handlers.SetVaultCategory)
+			admin.PUT("/vaults/:address/metadata", handlers.SetVaultMetadata)
+			admin.GET("/vaults/:address/allowlist", handlers.GetVaultAllowlist)
+			admin.PUT("/vaults/:address/allowlist", handlers.SetVaultAllowlist)
+			admin.POST("/vaults/:address/allowlist", handlers.AddVaultAllowlist)
+			admin.DELETE("/vaults/:address/allowlist/:user", handlers.RemoveVaultAllowlist)
+			admin.PUT("/strategies/:address/lp-position", handlers.SetStrategyLPPosition)
+			admin.PUT("/strategies/:address/uniswap-v3", handlers.SetStrategyUniswapV3Position)
+			admin.PUT("/strategies/:address/morpho", handlers.SetStrategyMorphoMarket)
+			admin.PUT("/strategies/:address/pendle", handlers.SetStrategyPendleMarket)
+			admin.PUT("/strategies/:address/metadata", handlers.SetStrategyMetadata)
+			admin.GET("/revenue", handlers.GetRevenue)
 			admin.GET("/monitoring", handlers.GetMonitoringData)
+			admin.GET("/reports", handlers.GetReports)
+			admin.GET("/reports/:date", handlers.GetReport)
+			admin.POST("/reports/:date/generate", handlers.GenerateReport)
+			admin.GET("/reconciliation", handlers.GetReconciliationReports)
+			admin.GET("/reconciliation/:id", handlers.GetReconciliationReport)
+			admin.GET("/ledger/journals", handlers.GetLedgerJournals)
+			admin.GET("/ledger/balances", handlers.GetLedgerBalances)
+			admin.GET("/ledger/totals", handlers.GetLedgerTotals)
+			admin.GET("/ledger/drift", handlers.GetLedgerDrift)
+			admin.POST("/ledger/recompute", handlers.RecomputeLedgerTotals)
+			admin.GET("/jobs", handlers.GetJobs)
+			admin.POST("/jobs/:name/trigger", handlers.TriggerJob)
+			admin.POST("/jobs/:name/pause", handlers.PauseJob)
+			admin.POST("/jobs/:name/resume", handlers.ResumeJob)
+			admin.GET("/queue", handlers.GetQueueJobs)
+			admin.GET("/queue/stats", handlers.GetQueueStats)
+			admin.POST("/queue/:id/retry", handlers.RetryQueueJob)
+			admin.POST("/queue/:id/cancel", handlers.CancelQueueJob)
+			admin.POST("/backfills", handlers.QueueBackfill)
+			admin.GET("/contracts", handlers.GetContracts)
+			admin.GET("/contracts/:chain_id/:address", handlers.GetContract)
+			admin.PUT("/contracts/:chain_id/:address", handlers.RegisterContract)
+			admin.DELETE("/contracts/:chain_id/:address", handlers.DeleteContract)
+			admin.GET("/runtime", handlers.GetRuntimeStats)
+
+			admin.GET("/keepers", handlers.GetKeepers)
+			admin.POST("/keepers", handlers.RegisterKeeper)
+			admin.POST("/keepers/:address/revoke", handlers.RevokeKeeper)
+			admin.GET("/keeper-jobs", handlers.GetKeeperJobHistory)
+			admin.GET("/automation/tasks", handlers.GetAutomationTasks)
+			admin.POST("/automation/tasks", handlers.RegisterAutomationTask)
+			admin.DELETE("/automation/tasks/:id", handlers.DeleteAutomationTask)
+
+			admin.GET("/rebalances", handlers.GetRebalanceProposals)
+			admin.GET("/rebalances/:id", handlers.GetRebalanceProposal)
+			admin.POST("/rebalances/:id/approve", handlers.ApproveRebalanceProposal)
+			admin.POST("/rebalances/:id/reject", handlers.RejectRebalanceProposal)
+			admin.POST("/rebalances/:id/execute", handlers.ExecuteRebalanceProposal)
+
+			admin.GET("/incidents", handlers.GetIncidents)
+			admin.GET("/incidents/:id", handlers.GetIncident)
+			admin.POST("/incidents", handlers.CreateIncident)
+			admin.PUT("/incidents/:id", handlers.UpdateIncident)
+			admin.POST("/incidents/:id/updates", handlers.PostIncidentUpdate)
+			admin.DELETE("/incidents/:id", handlers.DeleteIncident)
+
+			if config.Load().Server.Pprof {
+				registerPprof(admin.Group("/debug/pprof"))
+			}
+		}
+
+		// 外部keeper路由，凭管理员登记时下发的API Key访问
+		keeper := v1.Group("/keeper")
+		keeper.Use(handlers.KeeperRequired())
+		keeper.Use(middleware.RateLimit(middleware.PolicyDefault))
+		{
+			keeper.GET("/jobs", handlers.GetKeeperJobs)
+			keeper.POST("/jobs/:id/claim", handlers.ClaimKeeperJob)
+			keeper.POST("/jobs/:id/report", handlers.ReportKeeperJob)
 		}
 
 		// 风控路由
 		risk := v1.Group("/risk")
 		risk.Use(middleware.AuthRequired())
+		risk.Use(middleware.RateLimit(middleware.PolicyDefault))
 		{
 			risk.GET("/alerts", handlers.GetRiskAlerts)
 			risk.POST("/strategies/:address/check", handlers.CheckStrategyRisk)
@@ -63,3 +230,16 @@ func SetupRouter() *gin.Engine {
 
 	return router
 }
+
+// registerPprof 挂载pprof接口，profile和trace会阻塞seconds参数指定的时长
+func registerPprof(debug *gin.RouterGroup) {
+	debug.GET("/", gin.WrapF(pprof.Index))
+	debug.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	debug.GET("/profile", gin.WrapF(pprof.Profile))
+	debug.GET("/symbol", gin.WrapF(pprof.Symbol))
+	debug.POST("/symbol", gin.WrapF(pprof.Symbol))
+	debug.GET("/trace", gin.WrapF(pprof.Trace))
+	for _, name := range []string{"allocs", "block", "goroutine", "heap", "mutex", "threadcreate"} {
+		debug.GET("/"+name, gin.WrapH(pprof.Handler(name)))
+	}
+}
@@ -0,0 +1,287 @@
+package backfill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/contracts"
+	"github.com/chspring1/mya-platform/backend/pkg/events"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/shopspring/decimal"
+	"golang.org/x/time/rate"
+)
+
+// Handler 物化单个事件，必须幂等：断点之后、中断之前已写入的事件会被再次回放
+type Handler func(ctx context.Context, event *events.ChainEvent) error
+
+// Options 回放参数
+type Options struct {
+	FromBlock     uint64  // 0表示从合约登记的部署区块开始
+	ToBlock       uint64  // 0表示最新区块减去Confirmations
+	Confirmations uint64  // ToBlock为0时与链头保持的距离，避免回放可能重组的区块
+	ChunkSize     uint64  // 每次 eth_getLogs 查询的区块数，节点报错时自动减半
+	RPS           float64 // 每秒最多发起的RPC请求数
+	Restart       bool    // 忽略已有断点，从FromBlock重新开始
+}
+
+// Backfiller 按区块范围回放单个资金库的历史事件
+type Backfiller struct {
+	vault       *models.Vault
+	opts        Options
+	handle      Handler
+	checkpoints *repository.BackfillRepository
+
+	abi           *abi.ABI
+	topics        []common.Hash
+	client        *ethclient.Client
+	limiter       *rate.Limiter
+	assetDecimals int32
+	shareDecimals int32
+	blockTimes    map[uint64]time.Time
+}
+
+func New(vault *models.Vault, opts Options, handle Handler) (*Backfiller, error) {
+	if opts.ChunkSize == 0 {
+		return nil, errors.New("chunk size must be positive")
+	}
+	if opts.RPS <= 0 {
+		return nil, errors.New("rps must be positive")
+	}
+
+	// 合约登记了ABI时按登记的ABI解码，否则按本项目合约的内置ABI
+	vaultABI := contracts.Default(contracts.InterfaceVault)
+	registered, err := repository.NewContractRepository().Get(vault.ChainID, vault.Address)
+	if err != nil {
+		return nil, err
+	}
+	if registered != nil {
+		if registered.Interface != contracts.InterfaceVault {
+			return nil, fmt.Errorf("contract %s is registered as %s, not %s", vault.Address, registered.Interface, contracts.InterfaceVault)
+		}
+		if vaultABI, err = contracts.Parse(registered.Interface, string(registered.ABI)); err != nil {
+			return nil, fmt.Errorf("registered ABI of %s: %w", vault.Address, err)
+		}
+		if opts.FromBlock == 0 {
+			opts.FromBlock = registered.DeploymentBlock
+		}
+	}
+	if opts.FromBlock == 0 {
+		return nil, errors.New("from block is required unless the vault contract is registered with its deployment block")
+	}
+
+	client, err := blockchain.GetClient(vault.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Backfiller{
+		vault:       vault,
+		opts:        opts,
+		handle:      handle,
+		checkpoints: repository.NewBackfillRepository(),
+		abi:         vaultABI,
+		topics:      contracts.VaultTopics(vaultABI),
+		client:      client,
+		limiter:     rate.NewLimiter(rate.Limit(opts.RPS), 1),
+		blockTimes:  make(map[uint64]time.Time),
+	}, nil
+}
+
+// Run 回放到目标区块，每完成一段区块写入一次断点；中断后再次运行会从断点继续
+func (b *Backfiller) Run(ctx context.Context) (*models.BackfillCheckpoint, error) {
+	to := b.opts.ToBlock
+	if to == 0 {
+		head, err := b.blockNumber(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("get latest block: %w", err)
+		}
+		if head < b.opts.Confirmations {
+			return nil, fmt.Errorf("chain head %d is below %d confirmations", head, b.opts.Confirmations)
+		}
+		to = head - b.opts.Confirmations
+	}
+	if to < b.opts.FromBlock {
+		return nil, fmt.Errorf("to block %d is before from block %d", to, b.opts.FromBlock)
+	}
+
+	checkpoint := &models.BackfillCheckpoint{
+		VaultAddress: b.vault.Address,
+		ChainID:      b.vault.ChainID,
+		FromBlock:    b.opts.FromBlock,
+		ToBlock:      to,
+	}
+	start := b.opts.FromBlock
+	if !b.opts.Restart {
+		saved, err := b.checkpoints.GetCheckpoint(b.vault.Address)
+		if err != nil {
+			return nil, err
+		}
+		if saved != nil && saved.LastBlock >= start && saved.LastBlock < to {
+			start = saved.LastBlock + 1
+			checkpoint.Events = saved.Events
+			logger.Info(fmt.Sprintf("Resuming backfill of %s from checkpoint at block %d", b.vault.Address, saved.LastBlock))
+		} else if saved != nil && saved.LastBlock >= to && saved.FromBlock <= start {
+			logger.Info(fmt.Sprintf("Vault %s already backfilled to block %d", b.vault.Address, saved.LastBlock))
+			return saved, nil
+		}
+	}
+
+	var err error
+	if b.assetDecimals, err = blockchain.TokenDecimals(ctx, b.vault.ChainID, b.vault.AssetAddress); err != nil {
+		return nil, fmt.Errorf("read asset decimals: %w", err)
+	}
+	if b.shareDecimals, err = blockchain.TokenDecimals(ctx, b.vault.ChainID, b.vault.Address); err != nil {
+		return nil, fmt.Errorf("read share decimals: %w", err)
+	}
+
+	logger.Info(fmt.Sprintf("⏪ Backfilling %s on chain %d, blocks %d-%d", b.vault.Address, b.vault.ChainID, start, to))
+
+	chunk := b.opts.ChunkSize
+	for start <= to {
+		end := start + chunk - 1
+		if end > to || end < start {
+			end = to
+		}
+
+		logs, err := b.filterLogs(ctx, start, end)
+		if err != nil {
+			if ctx.Err() != nil {
+				return checkpoint, ctx.Err()
+			}
+			// 节点通常按结果数或区块跨度限制 eth_getLogs，缩小范围后重试
+			if chunk > 1 {
+				chunk /= 2
+				logger.Info(fmt.Sprintf("eth_getLogs %d-%d failed, retrying with %d blocks: %v", start, end, chunk, err))
+				continue
+			}
+			return checkpoint, fmt.Errorf("get logs for block %d: %w", start, err)
+		}
+
+		applied := 0
+		for _, log := range logs {
+			event, err := b.decode(ctx, log)
+			if errors.Is(err, events.ErrInvalidEvent) {
+				// 历史上的零收益收获等事件无法物化，跳过而不是中止整个回放
+				logger.Error(fmt.Sprintf("Skipping invalid event %s#%d: %v", log.TxHash.Hex(), log.Index, err))
+				continue
+			}
+			if err != nil {
+				return checkpoint, fmt.Errorf("decode log %s#%d: %w", log.TxHash.Hex(), log.Index, err)
+			}
+			if err := b.handle(ctx, event); err != nil {
+				return checkpoint, fmt.Errorf("apply %s %s#%d: %w", event.Type, event.TxHash, event.LogIndex, err)
+			}
+			applied++
+		}
+
+		checkpoint.LastBlock = end
+		checkpoint.Events += applied
+		if err := b.checkpoints.SaveCheckpoint(checkpoint); err != nil {
+			return checkpoint, err
+		}
+		logger.Info(fmt.Sprintf("Backfilled blocks %d-%d of %d: %d event(s), %d skipped", start, end, to, applied, len(logs)-applied))
+
+		// 区块时间只在当前分段内复用
+		clear(b.blockTimes)
+		start = end + 1
+	}
+
+	logger.Info(fmt.Sprintf("✅ Backfill of %s finished at block %d, %d event(s) in total", b.vault.Address, to, checkpoint.Events))
+	return checkpoint, nil
+}
+
+func (b *Backfiller) filterLogs(ctx context.Context, from, to uint64) ([]types.Log, error) {
+	if err := b.wait(ctx); err != nil {
+		return nil, err
+	}
+	return b.client.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+		Addresses: []common.Address{common.HexToAddress(b.vault.Address)},
+		Topics:    [][]common.Hash{b.topics},
+	})
+}
+
+// decode 按资金库ABI将日志转换为与索引器相同格式的链上事件，金额按代币精度换算
+func (b *Backfiller) decode(ctx context.Context, log types.Log) (*events.ChainEvent, error) {
+	decoded, err := contracts.DecodeVaultLog(b.abi, log)
+	if err != nil {
+		return nil, err
+	}
+	event := &events.ChainEvent{
+		Type:        decoded.Type,
+		ChainID:     b.vault.ChainID,
+		BlockNumber: log.BlockNumber,
+		TxHash:      log.TxHash.Hex(),
+		LogIndex:    log.Index,
+		Vault:       b.vault.Address,
+	}
+
+	switch decoded.Type {
+	case events.TypeStrategyAdded, events.TypeStrategyRemoved:
+		event.Strategy = decoded.Strategy.Hex()
+	case events.TypeDeposit, events.TypeWithdraw:
+		event.User = decoded.User.Hex()
+		event.Assets = decimal.NewFromBigInt(decoded.Assets, -b.assetDecimals)
+		event.Shares = decimal.NewFromBigInt(decoded.Shares, -b.shareDecimals)
+	case events.TypeHarvest:
+		// 收获事件自带时间戳，不需要查询区块时间
+		event.Assets = decimal.NewFromBigInt(decoded.Assets, -b.assetDecimals)
+		event.Timestamp = time.Unix(decoded.Timestamp.Int64(), 0)
+	}
+
+	if event.Timestamp.IsZero() {
+		timestamp, err := b.blockTime(ctx, log.BlockNumber)
+		if err != nil {
+			return nil, err
+		}
+		event.Timestamp = timestamp
+	}
+	if err := event.Validate(); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (b *Backfiller) blockTime(ctx context.Context, number uint64) (time.Time, error) {
+	if timestamp, ok := b.blockTimes[number]; ok {
+		return timestamp, nil
+	}
+	if err := b.wait(ctx); err != nil {
+		return time.Time{}, err
+	}
+	header, err := b.client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("get header %d: %w", number, err)
+	}
+	timestamp := time.Unix(int64(header.Time), 0)
+	b.blockTimes[number] = timestamp
+	return timestamp, nil
+}
+
+func (b *Backfiller) blockNumber(ctx context.Context) (uint64, error) {
+	if err := b.wait(ctx); err != nil {
+		return 0, err
+	}
+	return b.client.BlockNumber(ctx)
+}
+
+// wait 同时遵守本次回放的-rps和全局的RPC限流
+func (b *Backfiller) wait(ctx context.Context) error {
+	if err := b.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	return blockchain.Wait(ctx, b.vault.ChainID)
+}
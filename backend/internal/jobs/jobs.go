@@ -0,0 +1,182 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/cache"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/scheduler"
+)
+
+// controlInterval worker轮询API写入的暂停和手动触发请求的间隔
+const controlInterval = 5 * time.Second
+
+// Job 后台任务，Schedule 为默认的调度表达式(见 scheduler.Parse)，为空表示停用。
+// jobs.schedules 中按任务名配置的表达式优先
+type Job struct {
+	Name     string
+	Schedule string
+	Run      func(ctx context.Context) error
+}
+
+// Every 把以分钟配置的间隔转换为调度表达式，间隔 <= 0 时返回空(停用)
+func Every(minutes int) string {
+	if minutes <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("@every %dm", minutes)
+}
+
+// Start 把任务注册到调度器并启动，ctx取消时全部退出。
+// 任务状态写入 scheduled_jobs 表，API进程写入的暂停和手动触发请求由后台轮询生效。
+// 返回的WaitGroup在所有任务(包括正在执行的一次)结束后完成，用于优雅退出
+func Start(ctx context.Context, jobs ...Job) *sync.WaitGroup {
+	cfg := config.Load().Jobs
+	repo := repository.NewScheduledJobRepository()
+
+	sched := scheduler.New()
+	for _, job := range jobs {
+		spec := job.Schedule
+		if override, ok := cfg.Schedules[job.Name]; ok {
+			spec = override
+		}
+		if spec == "" {
+			logger.Info(fmt.Sprintf("Job %s disabled (no schedule)", job.Name))
+			continue
+		}
+		err := sched.Register(scheduler.Job{
+			Name:    job.Name,
+			Spec:    spec,
+			Timeout: time.Duration(cfg.Timeout) * time.Second,
+			Run:     job.Run,
+		})
+		if err != nil {
+			logger.Error(fmt.Sprintf("Job %s not scheduled: %v", job.Name, err))
+			continue
+		}
+		logger.Info(fmt.Sprintf("⏱️ Job %s scheduled (%s)", job.Name, spec))
+	}
+
+	sched.OnStatus = func(status scheduler.Status) {
+		repo.SaveState(status)
+	}
+	sched.OnFinish = func(status scheduler.Status) {
+		if status.LastStatus == scheduler.StatusSuccess {
+			logger.Info(fmt.Sprintf("Job %s finished in %v", status.Name, status.LastDuration))
+		} else {
+			logger.Error(fmt.Sprintf("Job %s %s after %v: %s", status.Name, status.LastStatus, status.LastDuration, status.LastError))
+		}
+		repo.RecordRun(status)
+	}
+	if cfg.DistributedLock {
+		if cache.Client == nil {
+			logger.Error("jobs.distributed_lock is enabled but Redis is not available: every scheduled run will be skipped until Redis is configured, disable jobs.distributed_lock for a single instance without Redis")
+		}
+		sched.Guard = acquire
+	}
+
+	// 先应用已保存的暂停状态，避免重启后暂停的任务被执行
+	control(sched, repo)
+	wg := sched.Start(ctx)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(controlInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Info("Job scheduler stopped")
+				return
+			case <-ticker.C:
+				control(sched, repo)
+			}
+		}
+	}()
+	return wg
+}
+
+// control 同步API写入的暂停状态并领取手动触发请求。
+// 只处理本实例注册的任务，其他实例或已停用的任务留给对应实例
+func control(sched *scheduler.Scheduler, repo *repository.ScheduledJobRepository) {
+	rows, err := repo.ListAll()
+	if err != nil {
+		return
+	}
+	registered := make(map[string]bool)
+	for _, status := range sched.Statuses() {
+		registered[status.Name] = status.Paused
+	}
+
+	for _, row := range rows {
+		paused, ok := registered[row.Name]
+		if !ok {
+			continue
+		}
+		if row.Paused != paused {
+			if row.Paused {
+				sched.Pause(row.Name)
+				logger.Info(fmt.Sprintf("Job %s paused", row.Name))
+			} else {
+				sched.Resume(row.Name)
+				logger.Info(fmt.Sprintf("Job %s resumed", row.Name))
+			}
+		}
+		if row.TriggerRequestedAt == nil {
+			continue
+		}
+		claimed, err := repo.ClaimTrigger(row.Name)
+		if err != nil || !claimed {
+			continue
+		}
+		if err := sched.Trigger(row.Name); err != nil {
+			if errors.Is(err, scheduler.ErrRunning) {
+				logger.Info(fmt.Sprintf("Manual trigger of job %s ignored, already running", row.Name))
+				continue
+			}
+			logger.Error(fmt.Sprintf("Failed to trigger job %s: %v", row.Name, err))
+			continue
+		}
+		logger.Info(fmt.Sprintf("Job %s triggered manually", row.Name))
+	}
+}
+
+// acquire 获取任务本次触发的锁，保证多实例部署时每次触发只有一个实例执行。
+// 锁按计划触发时刻区分，有效期为距下一次执行的时长，执行时间超过该时长时持续续期；执行结束后不释放，
+// 由锁自然过期，稍晚到点的实例不会再次执行同一次触发
+func acquire(ctx context.Context, name string, tick time.Time, ttl time.Duration) (func(), bool) {
+	lock, err := cache.TryLock(ctx, fmt.Sprintf("lock:job:%s:%d", name, tick.Unix()), ttl)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Job %s skipped, failed to acquire lock: %v", name, err))
+		return nil, false
+	}
+	if lock == nil {
+		logger.Info(fmt.Sprintf("Job %s skipped, already run by another instance", name))
+		return nil, false
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(ttl / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if ok, err := lock.Refresh(ctx); err != nil || !ok {
+					logger.Error(fmt.Sprintf("Job %s lost its lock: %v", name, err))
+					return
+				}
+			}
+		}
+	}()
+	return func() { close(done) }, true
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// 用户动态的条目来源
+const (
+	ActivityTransaction = "transaction"
+	ActivityAlert       = "alert"
+	ActivityVaultEvent  = "vault_event"
+)
+
+// ActivityItem 用户动态中的一条记录，由交易、告警触发和持仓资金库的时间线事件合并而成。
+// Type 为来源记录自身的类型(deposit、apy_below、harvest等)，RefID 为来源记录的id
+type ActivityItem struct {
+	ID           uint             `json:"-"` // 合并后的排序键，用于分页
+	Kind         string           `json:"kind"`
+	Type         string           `json:"type"`
+	RefID        uint             `json:"ref_id"`
+	VaultAddress string           `json:"vault_address"`
+	TxHash       string           `json:"tx_hash,omitempty"`
+	Amount       *decimal.Decimal `json:"amount,omitempty"`
+	Shares       *decimal.Decimal `json:"shares,omitempty"`
+	Status       string           `json:"status,omitempty"`
+	Details      json.RawMessage  `json:"details,omitempty"`
+	OccurredAt   time.Time        `json:"occurred_at"`
+}
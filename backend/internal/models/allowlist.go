@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// VaultAllowlistEntry 允许向白名单资金库存款的地址
+type VaultAllowlistEntry struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	VaultAddress string    `gorm:"size:42;not null;uniqueIndex:idx_vault_allowlist_vault_user,priority:1" json:"vault_address"`
+	UserAddress  string    `gorm:"size:42;not null;uniqueIndex:idx_vault_allowlist_vault_user,priority:2" json:"user_address"`
+	AddedBy      string    `gorm:"size:42;not null" json:"added_by"`
+	Note         string    `gorm:"size:255;not null;default:''" json:"note,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (VaultAllowlistEntry) TableName() string {
+	return "vault_allowlist"
+}
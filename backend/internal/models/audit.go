@@ -0,0 +1,54 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 审计日志记录的管理操作
+const (
+	AuditEmergencyStop = "vault.emergency_stop"
+	AuditSetVaultMode  = "vault.set_mode"
+	AuditSetVaultFees  = "vault.set_fees"
+	AuditSetDepositCap = "vault.set_deposit_cap"
+	AuditSetAllowlist  = "vault.set_allowlist"
+	AuditSetCategory   = "vault.set_category"
+	AuditAllowlistAdd  = "vault.allowlist_add"
+	AuditAllowlistDel  = "vault.allowlist_remove"
+	AuditSetStrategyLP = "strategy.set_lp_position"
+	AuditSetUniswapV3  = "strategy.set_uniswap_v3"
+	AuditSetMorpho     = "strategy.set_morpho"
+	AuditSetPendle     = "strategy.set_pendle"
+	AuditSetMetadata   = "metadata.set"
+	AuditKeeperAdd     = "keeper.register"
+	AuditKeeperRevoke  = "keeper.revoke"
+	AuditAutomationAdd = "automation.register"
+	AuditAutomationDel = "automation.remove"
+	AuditLedgerRebuild = "ledger.recompute_totals"
+	AuditJobTrigger    = "job.trigger"
+	AuditJobPause      = "job.pause"
+	AuditJobResume     = "job.resume"
+	AuditQueueRetry    = "queue.retry"
+	AuditQueueCancel   = "queue.cancel"
+	AuditQueueEnqueue  = "queue.enqueue"
+	AuditContractSave  = "contract.register"
+	AuditContractDel   = "contract.remove"
+	AuditIncidentOpen  = "incident.create"
+	AuditIncidentEdit  = "incident.update"
+	AuditIncidentPost  = "incident.post_update"
+	AuditIncidentDel   = "incident.delete"
+)
+
+// AuditLog 管理操作审计日志
+type AuditLog struct {
+	ID        uint            `gorm:"primaryKey" json:"id"`
+	Actor     string          `gorm:"size:42;not null" json:"actor"`
+	Action    string          `gorm:"size:50;not null" json:"action"`
+	Target    string          `gorm:"size:66;not null;default:''" json:"target"`
+	Details   json.RawMessage `gorm:"type:jsonb" json:"details,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// 定时任务的执行平台
+const (
+	AutomationChainlink = "chainlink" // Chainlink Automation upkeep，task_id 为十进制的upkeep ID
+	AutomationGelato    = "gelato"    // Gelato Automate 任务，task_id 为bytes32
+)
+
+// 定时任务触发的操作
+const (
+	AutomationHarvest   = "harvest"
+	AutomationRebalance = "rebalance"
+)
+
+// 定时任务状态，除 active 外都视为异常并作为风险警报展示
+const (
+	AutomationStatusUnknown     = "unknown" // 尚未检查
+	AutomationStatusActive      = "active"
+	AutomationStatusPaused      = "paused"
+	AutomationStatusUnderfunded = "underfunded" // 余额低于执行所需的最低余额
+	AutomationStatusCancelled   = "cancelled"
+	AutomationStatusStale       = "stale" // 超过预期间隔仍未执行对应操作
+	AutomationStatusUnreachable = "unreachable"
+)
+
+// ValidAutomationProvider 是否为支持的执行平台
+func ValidAutomationProvider(p string) bool {
+	return p == AutomationChainlink || p == AutomationGelato
+}
+
+// AutomationTask 在外部自动化平台上创建的收获或再平衡任务，
+// interval 为预期的执行间隔(秒)，用于判断任务是否停止执行
+type AutomationTask struct {
+	ID                 uint             `gorm:"primaryKey" json:"id"`
+	Provider           string           `gorm:"size:20;not null;uniqueIndex:idx_automation_tasks_task,priority:1" json:"provider"`
+	ChainID            uint             `gorm:"not null;uniqueIndex:idx_automation_tasks_task,priority:2" json:"chain_id"`
+	TaskID             string           `gorm:"size:78;not null;uniqueIndex:idx_automation_tasks_task,priority:3" json:"task_id"`
+	Action             string           `gorm:"size:20;not null" json:"action"`
+	VaultAddress       string           `gorm:"size:42;not null;index" json:"vault_address"`
+	StrategyAddress    string           `gorm:"size:42;not null;default:''" json:"strategy_address,omitempty"`
+	Interval           uint             `gorm:"not null" json:"interval"`
+	Status             string           `gorm:"size:20;not null;default:unknown" json:"status"`
+	StatusReason       string           `gorm:"not null;default:''" json:"status_reason,omitempty"`
+	Balance            *decimal.Decimal `gorm:"type:decimal(36,18)" json:"balance,omitempty"` // Chainlink upkeep的LINK余额
+	MinBalance         *decimal.Decimal `gorm:"type:decimal(36,18)" json:"min_balance,omitempty"`
+	LastPerformedBlock *uint64          `json:"last_performed_block,omitempty"`
+	LastCheckedAt      *time.Time       `json:"last_checked_at,omitempty"`
+	CreatedBy          string           `gorm:"size:42;not null" json:"created_by"`
+	CreatedAt          time.Time        `json:"created_at"`
+	UpdatedAt          time.Time        `json:"updated_at"`
+}
+
+// Failing 任务是否处于异常状态
+func (t AutomationTask) Failing() bool {
+	return t.Status != AutomationStatusActive && t.Status != AutomationStatusUnknown
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Contract 登记的合约ABI和部署区块，按 链ID+地址 唯一。
+// 回放和适配器据此解码事件，未登记的合约使用 contracts.Default 的内置ABI
+type Contract struct {
+	ID              uint            `gorm:"primaryKey" json:"id"`
+	ChainID         uint            `gorm:"not null;uniqueIndex:idx_contracts_chain_address,priority:1" json:"chain_id"`
+	Address         string          `gorm:"size:42;not null;uniqueIndex:idx_contracts_chain_address,priority:2" json:"address"`
+	Interface       string          `gorm:"size:20;not null;index" json:"interface"`
+	Name            string          `gorm:"size:100;not null;default:''" json:"name"`
+	ABI             json.RawMessage `gorm:"column:abi;type:jsonb;not null" json:"abi,omitempty"`
+	DeploymentBlock uint64          `gorm:"not null;default:0" json:"deployment_block"`
+	UploadedBy      string          `gorm:"size:42;not null;default:''" json:"uploaded_by"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+}
+
+func (Contract) TableName() string {
+	return "contracts"
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// DSRRate 一次Maker DSR读取。Chi 为Pot的累计利率因子，只在有人调用drip时更新；
+// SDAIRate 为1个sDAI可赎回的DAI，按当前时间计息，未配置sDAI的链为空
+type DSRRate struct {
+	ID          uint             `gorm:"primaryKey" json:"-"`
+	ChainID     uint             `gorm:"not null;index:idx_dsr_rates_chain_time,priority:1" json:"chain_id"`
+	APY         float64          `gorm:"type:decimal(10,8);not null" json:"apy"`
+	Chi         decimal.Decimal  `gorm:"type:decimal(36,27);not null" json:"chi"`
+	SDAIRate    *decimal.Decimal `gorm:"column:sdai_rate;type:decimal(36,18)" json:"sdai_rate"`
+	BlockNumber uint64           `gorm:"not null" json:"block_number"`
+	Timestamp   time.Time        `gorm:"not null;index:idx_dsr_rates_chain_time,priority:2" json:"timestamp"`
+}
+
+func (DSRRate) TableName() string {
+	return "dsr_rates"
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// 费用类型
+const (
+	FeeManagement  = "management"  // 管理费：按收获间隔内的资产规模年化计提
+	FeePerformance = "performance" // 业绩费：按收获收益计提
+)
+
+// FeeAccrual 一次收获计提的费用，(harvest_id, type) 唯一。
+// 管理费的 base 为期末资产规模，业绩费的 base 为收获收益
+type FeeAccrual struct {
+	ID           uint            `gorm:"primaryKey" json:"id"`
+	VaultAddress string          `gorm:"size:42;not null" json:"vault_address"`
+	HarvestID    uint            `gorm:"not null" json:"harvest_id"`
+	Type         string          `gorm:"size:20;not null" json:"type"`
+	FeeBps       uint16          `gorm:"not null" json:"fee_bps"`
+	Base         decimal.Decimal `gorm:"type:decimal(36,18);not null" json:"base"`
+	Amount       decimal.Decimal `gorm:"type:decimal(36,18);not null" json:"amount"`
+	PeriodStart  time.Time       `gorm:"not null" json:"period_start"`
+	PeriodEnd    time.Time       `gorm:"not null" json:"period_end"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
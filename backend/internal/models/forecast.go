@@ -0,0 +1,27 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ForecastEWMA 指数加权移动平均预测
+const ForecastEWMA = "ewma"
+
+// APYForecast 资金库未来几天APY的统计预测，每个资金库只保留最近一次结果。
+// 由历史日汇总APY外推得出，只是估计，不代表未来收益
+type APYForecast struct {
+	ID           uint            `gorm:"primaryKey" json:"-"`
+	VaultAddress string          `gorm:"size:42;not null;uniqueIndex" json:"vault_address"`
+	Method       string          `gorm:"size:20;not null" json:"method"`
+	Alpha        float64         `gorm:"not null" json:"alpha"`
+	Confidence   float64         `gorm:"not null" json:"confidence"`
+	Samples      int             `gorm:"not null" json:"samples"` // 参与拟合的日汇总天数
+	Sigma        float64         `gorm:"not null" json:"sigma"`   // 一步预测残差的均方根
+	Points       json.RawMessage `gorm:"type:jsonb;not null" json:"points"`
+	GeneratedAt  time.Time       `gorm:"not null" json:"generated_at"`
+}
+
+func (APYForecast) TableName() string {
+	return "apy_forecasts"
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Harvest 资金库收获记录，(tx_hash, log_index) 唯一
+type Harvest struct {
+	ID              uint            `gorm:"primaryKey" json:"id"`
+	VaultAddress    string          `gorm:"size:42;not null" json:"vault_address"`
+	StrategyAddress string          `gorm:"size:42;not null;default:''" json:"strategy_address,omitempty"`
+	ChainID         uint            `gorm:"not null" json:"chain_id"`
+	Amount          decimal.Decimal `gorm:"type:decimal(36,18);not null" json:"amount"`
+	TxHash          string          `gorm:"size:66;not null" json:"tx_hash"`
+	LogIndex        uint            `gorm:"not null" json:"log_index"`
+	BlockNumber     uint64          `gorm:"not null" json:"block_number"`
+	HarvestedAt     time.Time       `gorm:"not null" json:"harvested_at"`
+	CreatedAt       time.Time       `json:"created_at"`
+
+	// 收获交易实际支付的gas，一笔交易包含多次收获时平均分摊；尚未从回执补齐时为空
+	GasUsed    *uint64          `json:"gas_used"`
+	GasCost    *decimal.Decimal `gorm:"type:decimal(36,18)" json:"gas_cost"` // 原生代币数量
+	GasCostUSD *decimal.Decimal `gorm:"column:gas_cost_usd;type:decimal(36,18)" json:"gas_cost_usd"`
+	// 取不到回执的次数，达到上限后不再补齐
+	GasAttempts int `gorm:"not null;default:0" json:"-"`
+}
+
+// BackfillCheckpoint 历史数据回放断点，LastBlock及之前的区块已全部物化
+type BackfillCheckpoint struct {
+	VaultAddress string    `gorm:"primaryKey;size:42" json:"vault_address"`
+	ChainID      uint      `gorm:"not null" json:"chain_id"`
+	FromBlock    uint64    `gorm:"not null" json:"from_block"`
+	ToBlock      uint64    `gorm:"not null" json:"to_block"`
+	LastBlock    uint64    `gorm:"not null" json:"last_block"`
+	Events       int       `gorm:"not null;default:0" json:"events"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
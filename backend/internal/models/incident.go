@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// 事件严重程度，按影响范围递增
+const (
+	IncidentMinor    = "minor"    // 部分功能降级
+	IncidentMajor    = "major"    // 组件中断
+	IncidentCritical = "critical" // 资金或核心流程受影响，只能人工设置
+)
+
+// 事件处理状态，resolved 以外均视为未结束
+const (
+	IncidentInvestigating = "investigating"
+	IncidentIdentified    = "identified"
+	IncidentMonitoring    = "monitoring"
+	IncidentResolved      = "resolved"
+)
+
+// Incident 事件记录，用于复盘。Automatic 为状态检查持续失败时自动创建，组件恢复时自动结束；
+// CreatedBy 为创建事件的管理员，自动创建时为空
+type Incident struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	Component  string     `gorm:"size:40;not null;index" json:"component"`
+	Severity   string     `gorm:"size:10;not null" json:"severity"`
+	Status     string     `gorm:"size:20;not null" json:"status"`
+	Title      string     `gorm:"size:200;not null" json:"title"`
+	Automatic  bool       `gorm:"not null;default:false" json:"automatic"`
+	CreatedBy  string     `gorm:"size:42;not null;default:''" json:"created_by,omitempty"`
+	StartedAt  time.Time  `gorm:"not null" json:"started_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	Updates []IncidentUpdate `gorm:"foreignKey:IncidentID" json:"updates,omitempty"`
+	Alerts  []OperatorAlert  `gorm:"foreignKey:IncidentID" json:"alerts,omitempty"`
+}
+
+func (Incident) TableName() string {
+	return "incidents"
+}
+
+// IncidentUpdate 事件的一条进展，Author 为空表示系统自动记录
+type IncidentUpdate struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	IncidentID uint      `gorm:"not null;index" json:"incident_id"`
+	Status     string    `gorm:"size:20;not null" json:"status"`
+	Message    string    `gorm:"type:text;not null" json:"message"`
+	Author     string    `gorm:"size:42;not null;default:''" json:"author,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (IncidentUpdate) TableName() string {
+	return "incident_updates"
+}
+
+// OperatorAlert 发送给运维人员的告警，由事件产生时 IncidentID 指向该事件
+type OperatorAlert struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	IncidentID *uint     `gorm:"index" json:"incident_id,omitempty"`
+	Subject    string    `gorm:"size:255;not null" json:"subject"`
+	Body       string    `gorm:"type:text;not null" json:"body"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (OperatorAlert) TableName() string {
+	return "operator_alerts"
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// 存取款意向状态：pending 已生成交易等待用户执行并上报，executed 上报的交易满足意向约束，
+// rejected 上报的交易违反 min_out 或 deadline
+const (
+	IntentPending  = "pending"
+	IntentExecuted = "executed"
+	IntentRejected = "rejected"
+)
+
+// Intent 签名通过的存取款意向和为其生成的交易。数量均为最小单位：存款时 AmountWei 为资产、MinOutWei 为最少份额，
+// 取款时 AmountWei 为份额、MinOutWei 为最少资产；MinOutWei 为空、Deadline 为nil表示不限制
+type Intent struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	BatchID      string     `gorm:"size:34;not null;default:'';index" json:"batch_id,omitempty"` // 批量存款中的各笔意向共用
+	UserAddress  string     `gorm:"size:42;not null;index" json:"user_address"`
+	VaultAddress string     `gorm:"size:42;not null" json:"vault_address"`
+	ChainID      uint       `gorm:"not null;uniqueIndex:idx_intents_tx_log,priority:1,where:tx_hash <> ''" json:"chain_id"`
+	Kind         string     `gorm:"size:10;not null" json:"kind"`
+	AmountWei    string     `gorm:"size:78;not null" json:"amount_wei"`
+	MinOutWei    string     `gorm:"size:78;not null;default:''" json:"min_out_wei"`
+	Deadline     *time.Time `json:"deadline"`
+	To           string     `gorm:"column:to_address;size:42;not null" json:"to"` // 交易目标：资金库，或带deadline时的路由合约
+	Data         string     `gorm:"type:text;not null" json:"data"`
+	Status       string     `gorm:"size:10;not null;default:pending" json:"status"`
+	TxHash       string     `gorm:"size:66;not null;default:'';uniqueIndex:idx_intents_tx_log,priority:2" json:"tx_hash,omitempty"`
+	LogIndex     *uint      `gorm:"uniqueIndex:idx_intents_tx_log,priority:3" json:"log_index,omitempty"` // 结算该意向的存取款事件，同一事件只能结算一个意向
+	OutWei       string     `gorm:"size:78;not null;default:''" json:"out_wei,omitempty"`                 // 执行实际得到的份额或资产
+	RejectReason string     `gorm:"size:200;not null;default:''" json:"reject_reason,omitempty"`
+	ExecutedAt   *time.Time `json:"executed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// keeper任务类型
+const (
+	KeeperJobHarvest   = "harvest"   // 调用策略合约 harvest()
+	KeeperJobRebalance = "rebalance" // 按已批准的再平衡提案调仓
+)
+
+// keeper任务状态：pending 等待领取，claimed 已被领取且租约未过期，
+// 租约过期的 claimed 任务可被其他keeper重新领取；目标已不需要执行(如策略已被收获)时任务被取消
+const (
+	KeeperJobPending   = "pending"
+	KeeperJobClaimed   = "claimed"
+	KeeperJobSucceeded = "succeeded"
+	KeeperJobFailed    = "failed"
+	KeeperJobCancelled = "cancelled"
+)
+
+// ValidKeeperJobType 是否为支持的任务类型
+func ValidKeeperJobType(t string) bool {
+	return t == KeeperJobHarvest || t == KeeperJobRebalance
+}
+
+// Keeper 登记的外部执行机器人，凭API Key访问 /api/v1/keeper，只保存Key的哈希
+type Keeper struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	Address    string     `gorm:"size:42;not null;uniqueIndex" json:"address"` // keeper发送交易使用的地址
+	Name       string     `gorm:"size:100;not null" json:"name"`
+	APIKeyHash string     `gorm:"column:api_key_hash;size:64;not null;uniqueIndex" json:"-"`
+	Active     bool       `gorm:"not null;default:true" json:"active"`
+	CreatedBy  string     `gorm:"size:42;not null" json:"created_by"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// KeeperJob 待外部keeper执行的链上任务。target 为收获的策略地址或再平衡提案ID，
+// payload 包含执行所需的合约地址和调用数据
+type KeeperJob struct {
+	ID             uint            `gorm:"primaryKey" json:"id"`
+	Type           string          `gorm:"size:20;not null" json:"type"`
+	ChainID        uint            `gorm:"not null" json:"chain_id"`
+	VaultAddress   string          `gorm:"size:42;not null" json:"vault_address"`
+	Target         string          `gorm:"size:66;not null" json:"target"`
+	Payload        json.RawMessage `gorm:"type:jsonb" json:"payload,omitempty"`
+	Status         string          `gorm:"size:20;not null;default:pending" json:"status"`
+	KeeperID       *uint           `json:"keeper_id,omitempty"`
+	LeaseExpiresAt *time.Time      `json:"lease_expires_at,omitempty"`
+	Attempts       int             `gorm:"not null;default:0" json:"attempts"`
+	TxHash         string          `gorm:"size:66;not null;default:''" json:"tx_hash,omitempty"`
+	Error          string          `gorm:"not null;default:''" json:"error,omitempty"`
+	CompletedAt    *time.Time      `json:"completed_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
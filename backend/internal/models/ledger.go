@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// 账户类型，金额均为所属资金库底层资产的数量
+const (
+	AccountVault    = "vault"    // 资金库持有的资产
+	AccountUser     = "user"     // 用户存入资金库的本金，即平台对用户的负债
+	AccountYield    = "yield"    // 资金库收获的收益，归全体存款人，计提费用时转入 treasury
+	AccountTreasury = "treasury" // 平台的费用收入，按资金库分账
+)
+
+// 记账方向
+const (
+	LedgerDebit  = "debit"
+	LedgerCredit = "credit"
+)
+
+// 分录类型
+const (
+	JournalDeposit  = "deposit"
+	JournalWithdraw = "withdraw"
+	JournalHarvest  = "harvest"
+	JournalFee      = "fee"
+)
+
+// LedgerJournal 一笔复式记账凭证，借贷合计必须相等；(kind, reference) 唯一，同一业务事件只记一次。
+// 凭证和分录只追加不修改(Postgres 上由触发器保证)，资金库存取款总额和策略累计收益均由凭证汇总得出
+type LedgerJournal struct {
+	ID              uint          `gorm:"primaryKey" json:"id"`
+	Kind            string        `gorm:"size:20;not null;uniqueIndex:idx_ledger_journals_kind_reference,priority:1" json:"kind"`
+	Reference       string        `gorm:"size:100;not null;uniqueIndex:idx_ledger_journals_kind_reference,priority:2" json:"reference"` // 交易哈希、收获的 tx_hash#log_index 或费用计提ID
+	VaultAddress    string        `gorm:"size:42;not null;index" json:"vault_address"`
+	StrategyAddress string        `gorm:"size:42;not null;default:''" json:"strategy_address,omitempty"` // 仅收获凭证，策略累计收益按此汇总
+	PostedAt        time.Time     `gorm:"not null" json:"posted_at"`
+	Entries         []LedgerEntry `gorm:"foreignKey:JournalID" json:"entries,omitempty"`
+}
+
+func (LedgerJournal) TableName() string {
+	return "ledger_journals"
+}
+
+// Balanced 凭证至少有一借一贷、金额均为正且借贷合计相等
+func (j *LedgerJournal) Balanced() bool {
+	debits, credits := decimal.Zero, decimal.Zero
+	for _, entry := range j.Entries {
+		if !entry.Amount.IsPositive() {
+			return false
+		}
+		switch entry.Side {
+		case LedgerDebit:
+			debits = debits.Add(entry.Amount)
+		case LedgerCredit:
+			credits = credits.Add(entry.Amount)
+		default:
+			return false
+		}
+	}
+	return debits.IsPositive() && debits.Equal(credits)
+}
+
+// LedgerEntry 凭证中的一行。账户由 (account_type, account_owner, vault_address) 确定，
+// account_owner 为用户地址，vault、yield、treasury 账户为空
+type LedgerEntry struct {
+	ID           uint            `gorm:"primaryKey" json:"id"`
+	JournalID    uint            `gorm:"not null;index" json:"-"`
+	AccountType  string          `gorm:"size:20;not null;index:idx_ledger_entries_account,priority:1" json:"account_type"`
+	AccountOwner string          `gorm:"size:42;not null;default:'';index:idx_ledger_entries_account,priority:3" json:"account_owner,omitempty"`
+	VaultAddress string          `gorm:"size:42;not null;index:idx_ledger_entries_account,priority:2" json:"vault_address"`
+	Side         string          `gorm:"size:10;not null" json:"side"`
+	Amount       decimal.Decimal `gorm:"type:decimal(36,18);not null" json:"amount"`
+	PostedAt     time.Time       `gorm:"not null" json:"posted_at"`
+}
+
+func (LedgerEntry) TableName() string {
+	return "ledger_entries"
+}
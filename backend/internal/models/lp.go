@@ -0,0 +1,26 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+// LP策略的池类型，均按等权重估算无常损失
+const (
+	LPPoolUniswapV2 = "uniswap_v2" // 恒定乘积，两种代币各占50%
+	LPPoolCurve     = "curve"      // StableSwap，按恒定乘积估算，结果是实际损失的上限
+)
+
+// ValidLPPoolType 是否为支持的LP池类型
+func ValidLPPoolType(t string) bool {
+	return t == LPPoolUniswapV2 || t == LPPoolCurve
+}
+
+// StrategyLPToken LP策略池中的代币及建仓时的USD价格
+type StrategyLPToken struct {
+	ID              uint            `gorm:"primaryKey" json:"-"`
+	StrategyAddress string          `gorm:"size:42;not null;uniqueIndex:idx_strategy_lp_tokens,priority:1" json:"-"`
+	TokenAddress    string          `gorm:"size:42;not null;uniqueIndex:idx_strategy_lp_tokens,priority:2" json:"token_address"`
+	EntryPriceUSD   decimal.Decimal `gorm:"column:entry_price_usd;type:decimal(36,18);not null" json:"entry_price_usd"`
+}
+
+func (StrategyLPToken) TableName() string {
+	return "strategy_lp_tokens"
+}
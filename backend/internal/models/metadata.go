@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// 展示资料所属的对象类型
+const (
+	MetadataKindVault    = "vault"
+	MetadataKindStrategy = "strategy"
+)
+
+// DisplayMetadata 资金库或策略的展示资料，由管理员维护，详情接口原样返回，前端不再硬编码这些内容
+type DisplayMetadata struct {
+	Address       string          `gorm:"primaryKey;size:42" json:"address"`
+	Kind          string          `gorm:"size:10;not null" json:"kind"` // 见 MetadataKind*
+	Description   string          `gorm:"type:text;not null;default:''" json:"description"`
+	LogoURL       string          `gorm:"column:logo_url;size:512;not null;default:''" json:"logo_url"`
+	Links         []MetadataLink  `gorm:"serializer:json;type:jsonb" json:"links"`  // 协议官网、文档等
+	Audits        []MetadataAudit `gorm:"serializer:json;type:jsonb" json:"audits"` // 审计报告
+	InceptionDate *time.Time      `gorm:"type:date" json:"inception_date"`
+	UpdatedBy     string          `gorm:"size:42;not null" json:"updated_by"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// MetadataLink 带标签的外部链接
+type MetadataLink struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+// MetadataAudit 一份审计报告，Date 为报告日期(YYYY-MM-DD)，未知时为空
+type MetadataAudit struct {
+	Auditor string `json:"auditor"`
+	URL     string `json:"url"`
+	Date    string `json:"date,omitempty"`
+}
+
+func (DisplayMetadata) TableName() string {
+	return "display_metadata"
+}
@@ -3,81 +3,193 @@ package models
 import (
 	"time"
 
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
 // User 用户模型
 type User struct {
-	ID        uint           `gorm:"primaryKey" json:"id"`
-	Address   string         `gorm:"uniqueIndex;size:42;not null" json:"address"`
-	TotalTVL  float64        `gorm:"type:decimal(36,18);default:0" json:"total_tvl"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
-}
+	ID        uint            `gorm:"primaryKey" json:"id"`
+	Address   string          `gorm:"uniqueIndex;size:42;not null" json:"address"`
+	TotalTVL  decimal.Decimal `gorm:"type:decimal(36,18);default:0" json:"total_tvl"`
+	ENSName   string          `gorm:"-" json:"ens_name,omitempty"` // 反向解析的主ENS名称，不落库
+	Nickname  string          `gorm:"size:32;not null;default:''" json:"nickname,omitempty"`
+	AvatarURL string          `gorm:"size:512;not null;default:''" json:"avatar_url,omitempty"`
+	Email     string          `gorm:"size:255;not null;default:''" json:"email,omitempty"` // 只返回给本人
 
-// Vault 资金库模型
-type Vault struct {
-	ID               uint           `gorm:"primaryKey" json:"id"`
-	Address          string         `gorm:"uniqueIndex;size:42;not null" json:"address"`
-	Name             string         `gorm:"size:100;not null" json:"name"`
-	Symbol           string         `gorm:"size:20;not null" json:"symbol"`
-	ChainID          uint           `gorm:"not null" json:"chain_id"`
-	AssetAddress     string         `gorm:"size:42;not null" json:"asset_address"`
-	StrategyAddress  string         `gorm:"size:42" json:"strategy_address"`
-	TVL              float64        `gorm:"type:decimal(36,18);default:0" json:"tvl"`
-	APYCurrent       float64        `gorm:"type:decimal(10,8);default:0" json:"apy_current"`
-	APYWeekly        float64        `gorm:"type:decimal(10,8);default:0" json:"apy_weekly"`
-	TotalDeposits    float64        `gorm:"type:decimal(36,18);default:0" json:"total_deposits"`
-	TotalWithdrawals float64        `gorm:"type:decimal(36,18);default:0" json:"total_withdrawals"`
-	IsActive         bool           `gorm:"default:true" json:"is_active"`
+	ProfileUpdatedAt *time.Time     `json:"profile_updated_at,omitempty"`           // 上次修改资料时签名中的时间，更早的签名不再接受
+	IntentNonce      uint64         `gorm:"not null;default:0" json:"intent_nonce"` // 存取款意向已使用的最大nonce
 	CreatedAt        time.Time      `json:"created_at"`
 	UpdatedAt        time.Time      `json:"updated_at"`
 	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// Vault 资金库模型
+type Vault struct {
+	ID                uint             `gorm:"primaryKey" json:"id"`
+	Address           string           `gorm:"uniqueIndex;size:42;not null" json:"address"`
+	Name              string           `gorm:"size:100;not null" json:"name"`
+	Symbol            string           `gorm:"size:20;not null" json:"symbol"`
+	ChainID           uint             `gorm:"not null" json:"chain_id"`
+	AssetAddress      string           `gorm:"size:42;not null" json:"asset_address"`
+	StrategyAddress   string           `gorm:"size:42" json:"strategy_address"`
+	TVL               decimal.Decimal  `gorm:"type:decimal(36,18);default:0" json:"tvl"`
+	APYCurrent        float64          `gorm:"type:decimal(10,8);default:0" json:"apy_current"`
+	APYWeekly         float64          `gorm:"type:decimal(10,8);default:0" json:"apy_weekly"`
+	TotalDeposits     decimal.Decimal  `gorm:"type:decimal(36,18);default:0" json:"total_deposits"`
+	TotalWithdrawals  decimal.Decimal  `gorm:"type:decimal(36,18);default:0" json:"total_withdrawals"`
+	IsActive          bool             `gorm:"default:true" json:"is_active"`
+	Mode              string           `gorm:"size:20;not null;default:active" json:"mode"`     // active, withdraw_only, deposit_only, frozen
+	ManagementFeeBps  uint16           `gorm:"not null;default:0" json:"management_fee_bps"`    // 年化管理费，万分比
+	PerformanceFeeBps uint16           `gorm:"not null;default:0" json:"performance_fee_bps"`   // 收益业绩费，万分比
+	DepositCap        *decimal.Decimal `gorm:"type:decimal(36,18)" json:"deposit_cap"`          // 存款上限，为空表示不限制
+	AllowlistEnabled  bool             `gorm:"not null;default:false" json:"allowlist_enabled"` // 只接受白名单地址存款
+	CreatedAt         time.Time        `json:"created_at"`
+	UpdatedAt         time.Time        `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt   `gorm:"index" json:"-"`
+
+	// 分类和标签，用于前端筛选和构建浏览页
+	Category string   `gorm:"size:20;not null;default:''" json:"category"` // 见 VaultCategory*，未分类为空
+	Tags     []string `gorm:"serializer:json;type:jsonb" json:"tags,omitempty"`
 
 	// 关联关系
 	Strategies []Strategy `gorm:"foreignKey:VaultAddress;references:Address" json:"strategies,omitempty"`
 }
 
+// 资金库运行模式
+const (
+	VaultModeActive       = "active"
+	VaultModeWithdrawOnly = "withdraw_only" // 暂停存款，允许取款
+	VaultModeDepositOnly  = "deposit_only"  // 暂停取款，允许存款
+	VaultModeFrozen       = "frozen"        // 存取款全部暂停
+)
+
+// RemainingCapacity 距存款上限的剩余额度，未设置上限时返回nil
+func (v *Vault) RemainingCapacity() *decimal.Decimal {
+	if v.DepositCap == nil {
+		return nil
+	}
+	remaining := v.DepositCap.Sub(v.TVL)
+	if remaining.IsNegative() {
+		remaining = decimal.Zero
+	}
+	return &remaining
+}
+
+// 资金库分类，前端按分类构建浏览页
+const (
+	VaultCategoryStablecoin = "stablecoin"
+	VaultCategoryETH        = "eth"
+	VaultCategoryLST        = "lst"   // 流动性质押代币
+	VaultCategoryRWA        = "rwa"   // 现实世界资产
+	VaultCategoryDegen      = "degen" // 高风险高收益
+)
+
+// VaultCategories 全部分类，按展示顺序排列
+var VaultCategories = []string{VaultCategoryStablecoin, VaultCategoryETH, VaultCategoryLST, VaultCategoryRWA, VaultCategoryDegen}
+
+// ValidVaultCategory 是否为合法的资金库分类
+func ValidVaultCategory(category string) bool {
+	for _, c := range VaultCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTags 资金库是否带有全部指定标签
+func (v *Vault) HasTags(tags []string) bool {
+	for _, tag := range tags {
+		found := false
+		for _, t := range v.Tags {
+			if t == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidVaultMode 是否为合法的运行模式
+func ValidVaultMode(mode string) bool {
+	switch mode {
+	case VaultModeActive, VaultModeWithdrawOnly, VaultModeDepositOnly, VaultModeFrozen:
+		return true
+	}
+	return false
+}
+
+// AcceptsDeposits 当前模式是否接受存款
+func (v *Vault) AcceptsDeposits() bool {
+	return v.IsActive && (v.Mode == VaultModeActive || v.Mode == VaultModeDepositOnly || v.Mode == "")
+}
+
+// AcceptsWithdrawals 当前模式是否接受取款
+func (v *Vault) AcceptsWithdrawals() bool {
+	return v.IsActive && (v.Mode == VaultModeActive || v.Mode == VaultModeWithdrawOnly || v.Mode == "")
+}
+
 // Strategy 策略模型
 type Strategy struct {
-	ID            uint           `gorm:"primaryKey" json:"id"`
-	Address       string         `gorm:"uniqueIndex;size:42;not null" json:"address"`
-	Name          string         `gorm:"size:100;not null" json:"name"`
-	VaultAddress  string         `gorm:"size:42;not null" json:"vault_address"`
-	APY           float64        `gorm:"type:decimal(10,8);default:0" json:"apy"`
-	RiskScore     uint8          `gorm:"default:1" json:"risk_score"`
-	TotalAssets   float64        `gorm:"type:decimal(36,18);default:0" json:"total_assets"`
-	TotalEarnings float64        `gorm:"type:decimal(36,18);default:0" json:"total_earnings"`
-	IsActive      bool           `gorm:"default:true" json:"is_active"`
-	LastHarvest   *time.Time     `json:"last_harvest"`
-	CreatedAt     time.Time      `json:"created_at"`
-	UpdatedAt     time.Time      `json:"updated_at"`
-	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+	ID            uint            `gorm:"primaryKey" json:"id"`
+	Address       string          `gorm:"uniqueIndex;size:42;not null" json:"address"`
+	Name          string          `gorm:"size:100;not null" json:"name"`
+	VaultAddress  string          `gorm:"size:42;not null" json:"vault_address"`
+	Protocol      string          `gorm:"size:50" json:"protocol"` // 底层协议适配器，如 aave-v3、compound-v3
+	APY           float64         `gorm:"type:decimal(10,8);default:0" json:"apy"`
+	APR           float64         `gorm:"-" json:"apr"` // 由APY按 rates.compounding_periods 换算，不入库
+	RiskScore     uint8           `gorm:"default:1" json:"risk_score"`
+	MaxAllocBps   uint16          `gorm:"default:10000" json:"max_alloc_bps"` // 该策略可占资金库的最大比例(基点)
+	TargetBps     uint16          `gorm:"default:0" json:"target_alloc_bps"`  // 目标分配比例(基点)，实际比例由total_assets计算
+	TotalAssets   decimal.Decimal `gorm:"type:decimal(36,18);default:0" json:"total_assets"`
+	TotalEarnings decimal.Decimal `gorm:"type:decimal(36,18);default:0" json:"total_earnings"`
+	IsActive      bool            `gorm:"default:true" json:"is_active"`
+	LastHarvest   *time.Time      `json:"last_harvest"`
+	LPPoolType    string          `gorm:"column:lp_pool_type;size:20;not null;default:''" json:"lp_pool_type,omitempty"` // LP类策略的池类型，见 LPPool*
+	LPEntryAt     *time.Time      `gorm:"column:lp_entry_at" json:"lp_entry_at,omitempty"`                               // 估算无常损失的基准时间
+	Maturity      *time.Time      `gorm:"column:maturity" json:"maturity,omitempty"`                                     // 固定利率策略(如Pendle PT)的到期时间，浮动利率策略为空
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt  `gorm:"index" json:"-"`
 }
 
 // Transaction 交易模型
 type Transaction struct {
-	ID           uint           `gorm:"primaryKey" json:"id"`
-	UserAddress  string         `gorm:"size:42;not null" json:"user_address"`
-	VaultAddress string         `gorm:"size:42;not null" json:"vault_address"`
-	Type         string         `gorm:"size:20;not null" json:"type"` // deposit, withdraw
-	Amount       float64        `gorm:"type:decimal(36,18);not null" json:"amount"`
-	Shares       float64        `gorm:"type:decimal(36,18);not null" json:"shares"`
-	TxHash       string         `gorm:"uniqueIndex;size:66;not null" json:"tx_hash"`
-	BlockNumber  uint64         `gorm:"not null" json:"block_number"`
-	Status       string         `gorm:"size:20;default:pending" json:"status"` // pending, confirmed, failed
-	CreatedAt    time.Time      `json:"created_at"`
-	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+	ID           uint            `gorm:"primaryKey" json:"id"`
+	UserAddress  string          `gorm:"size:42;not null" json:"user_address"`
+	VaultAddress string          `gorm:"size:42;not null" json:"vault_address"`
+	Type         string          `gorm:"size:20;not null" json:"type"` // deposit, withdraw, rebalance, bridge
+	Amount       decimal.Decimal `gorm:"type:decimal(36,18);not null" json:"amount"`
+	Shares       decimal.Decimal `gorm:"type:decimal(36,18);not null" json:"shares"`
+	TxHash       string          `gorm:"size:66;not null;uniqueIndex:idx_transactions_tx_log,priority:1;uniqueIndex:idx_transactions_tx_unindexed,where:log_index IS NULL" json:"tx_hash"`
+	LogIndex     *uint           `gorm:"uniqueIndex:idx_transactions_tx_log,priority:2" json:"log_index,omitempty"` // 存取款事件在交易中的位置，不来自事件的记录为空
+	BlockNumber  uint64          `gorm:"not null" json:"block_number"`
+	Status       string          `gorm:"size:20;default:pending" json:"status"` // pending, confirmed, failed
+	CreatedAt    time.Time       `json:"created_at"`
+	DeletedAt    gorm.DeletedAt  `gorm:"index" json:"-"`
+
+	// 跨链存款(type=bridge)：TxHash为源链交易，资产到达资金库所在链后记录目标链交易
+	SourceChainID     *uint  `json:"source_chain_id,omitempty"`
+	Bridge            string `gorm:"size:50;not null;default:''" json:"bridge,omitempty"`
+	DestinationTxHash string `gorm:"size:66;not null;default:''" json:"destination_tx_hash,omitempty"`
 }
 
+// TransactionBridge 跨链存款的源链交易类型
+const TransactionBridge = "bridge"
+
 // APYHistory APY历史记录模型
 type APYHistory struct {
-	ID           uint      `gorm:"primaryKey" json:"id"`
-	VaultAddress string    `gorm:"size:42;not null" json:"vault_address"`
-	APYValue     float64   `gorm:"type:decimal(10,8);not null" json:"apy_value"`
-	TVL          float64   `gorm:"type:decimal(36,18);not null" json:"tvl"`
-	Timestamp    time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"timestamp"`
+	ID           uint            `gorm:"primaryKey" json:"id"`
+	VaultAddress string          `gorm:"size:42;not null" json:"vault_address"`
+	APYValue     float64         `gorm:"type:decimal(10,8);not null" json:"apy_value"`
+	Rate         float64         `gorm:"-" json:"rate"` // 按 ?rate= 口径返回的收益率，不入库
+	TVL          decimal.Decimal `gorm:"type:decimal(36,18);not null" json:"tvl"`
+	Timestamp    time.Time       `gorm:"default:CURRENT_TIMESTAMP" json:"timestamp"`
 }
 
 // 表名映射
@@ -100,3 +212,65 @@ func (Transaction) TableName() string {
 func (APYHistory) TableName() string {
 	return "apy_history"
 }
+
+// APYDaily APY历史的日汇总，原始记录过了保留期后只保留该汇总
+type APYDaily struct {
+	ID           uint            `gorm:"primaryKey" json:"id"`
+	VaultAddress string          `gorm:"size:42;not null;uniqueIndex:idx_apy_daily_vault_day,priority:1" json:"vault_address"`
+	Day          time.Time       `gorm:"type:date;not null;uniqueIndex:idx_apy_daily_vault_day,priority:2" json:"day"`
+	AvgAPY       float64         `gorm:"type:decimal(10,8);not null" json:"avg_apy"`
+	MinAPY       float64         `gorm:"type:decimal(10,8);not null" json:"min_apy"`
+	MaxAPY       float64         `gorm:"type:decimal(10,8);not null" json:"max_apy"`
+	AvgTVL       decimal.Decimal `gorm:"type:decimal(36,18);not null" json:"avg_tvl"`
+	Samples      int             `gorm:"not null" json:"samples"`
+}
+
+func (APYDaily) TableName() string {
+	return "apy_history_daily"
+}
+
+// StrategySnapshot 策略表现快照
+type StrategySnapshot struct {
+	ID              uint            `gorm:"primaryKey" json:"id"`
+	StrategyAddress string          `gorm:"size:42;not null;index:idx_strategy_snapshots_address_ts,priority:1" json:"strategy_address"`
+	APY             float64         `gorm:"type:decimal(10,8);not null" json:"apy"`
+	Rate            float64         `gorm:"-" json:"rate"` // 按 ?rate= 口径返回的收益率，不入库
+	TotalAssets     decimal.Decimal `gorm:"type:decimal(36,18);not null" json:"total_assets"`
+	TotalEarnings   decimal.Decimal `gorm:"type:decimal(36,18);not null" json:"total_earnings"`
+	TotalAssetsUSD  *float64        `gorm:"type:decimal(36,8)" json:"total_assets_usd"` // 按快照时价格计算，价格不可用时为空
+	ImpermanentLoss *float64        `gorm:"type:decimal(10,8)" json:"impermanent_loss"` // LP策略相对持币不动的估算损失(负数)，非LP策略为空
+	Timestamp       time.Time       `gorm:"not null;index:idx_strategy_snapshots_address_ts,priority:2" json:"timestamp"`
+}
+
+func (StrategySnapshot) TableName() string {
+	return "strategy_snapshots"
+}
+
+// ProtocolRate 协议利率记录，用于回测和横向比较
+type ProtocolRate struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Protocol     string    `gorm:"size:50;not null;index:idx_protocol_rates_lookup,priority:1" json:"protocol"`
+	ChainID      uint      `gorm:"not null;index:idx_protocol_rates_lookup,priority:2" json:"chain_id"`
+	AssetAddress string    `gorm:"size:42;not null;index:idx_protocol_rates_lookup,priority:3" json:"asset_address"`
+	APY          float64   `gorm:"type:decimal(10,8);not null" json:"apy"`
+	Timestamp    time.Time `gorm:"not null;index:idx_protocol_rates_lookup,priority:4" json:"timestamp"`
+}
+
+func (ProtocolRate) TableName() string {
+	return "protocol_rates"
+}
+
+// TokenPrice 代币历史价格，按天及交易发生时记录
+type TokenPrice struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	ChainID      uint      `gorm:"not null;index:idx_token_prices_lookup,priority:1" json:"chain_id"`
+	TokenAddress string    `gorm:"size:42;not null;index:idx_token_prices_lookup,priority:2" json:"token_address"`
+	PriceUSD     float64   `gorm:"type:decimal(36,18);not null" json:"price_usd"`
+	Source       string    `gorm:"size:20;not null" json:"source"`
+	Kind         string    `gorm:"size:20;not null" json:"kind"` // daily, transaction
+	Timestamp    time.Time `gorm:"not null;index:idx_token_prices_lookup,priority:3" json:"timestamp"`
+}
+
+func (TokenPrice) TableName() string {
+	return "token_prices"
+}
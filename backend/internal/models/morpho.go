@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// MorphoMarket 策略供应资金的Morpho Blue市场及最近一次读取的状态。
+// 市场参数和代币精度在登记时读取，利率和利用率由worker定期覆盖，历史随策略快照保留
+type MorphoMarket struct {
+	StrategyAddress    string          `gorm:"primaryKey;size:42" json:"strategy_address"`
+	Morpho             string          `gorm:"size:42;not null" json:"morpho"`
+	MarketID           string          `gorm:"size:66;not null" json:"market_id"`
+	LoanToken          string          `gorm:"size:42;not null" json:"loan_token"`
+	CollateralToken    string          `gorm:"size:42;not null" json:"collateral_token"`
+	Oracle             string          `gorm:"size:42;not null" json:"oracle"`
+	IRM                string          `gorm:"column:irm;size:42;not null" json:"irm"`
+	LLTV               float64         `gorm:"column:lltv;type:decimal(10,8);not null" json:"lltv"`
+	LoanDecimals       int32           `gorm:"not null" json:"loan_decimals"`
+	CollateralDecimals int32           `gorm:"not null" json:"collateral_decimals"`
+	SupplyAPY          float64         `gorm:"column:supply_apy;type:decimal(10,8);not null;default:0" json:"supply_apy"`
+	BorrowAPY          float64         `gorm:"column:borrow_apy;type:decimal(10,8);not null;default:0" json:"borrow_apy"`
+	Utilization        float64         `gorm:"type:decimal(10,8);not null;default:0" json:"utilization"`
+	TotalSupplyAssets  decimal.Decimal `gorm:"type:decimal(36,18);not null;default:0" json:"total_supply_assets"`
+	TotalBorrowAssets  decimal.Decimal `gorm:"type:decimal(36,18);not null;default:0" json:"total_borrow_assets"`
+	OraclePrice        *float64        `json:"oracle_price"` // 1个抵押品折合的借出资产数量，预言机不可读时为空
+	BlockNumber        uint64          `gorm:"not null;default:0" json:"block_number"`
+	SyncedAt           time.Time       `gorm:"not null" json:"synced_at"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+}
+
+func (MorphoMarket) TableName() string {
+	return "morpho_markets"
+}
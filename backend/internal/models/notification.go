@@ -0,0 +1,116 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 可订阅的通知事件
+const (
+	NotifyAPYBelow          = "apy_below"
+	NotifyDepositConfirmed  = "deposit_confirmed"
+	NotifyWithdrawConfirmed = "withdraw_confirmed"
+	NotifyVaultPaused       = "vault_paused"
+	NotifyRebalanceSuggest  = "rebalance_suggestion" // 订阅即参与持仓调仓建议的评估
+)
+
+// NotificationChannel 用户的通知渠道(邮箱、Telegram或webhook)，验证通过后才会接收通知
+type NotificationChannel struct {
+	ID                    uint       `gorm:"primaryKey" json:"id"`
+	UserAddress           string     `gorm:"size:42;not null;uniqueIndex:idx_notification_channels_user_type,priority:1" json:"user_address"`
+	Type                  string     `gorm:"size:20;not null;uniqueIndex:idx_notification_channels_user_type,priority:2" json:"type"`
+	Target                string     `gorm:"size:255;not null" json:"target"`
+	Verified              bool       `gorm:"not null;default:false" json:"verified"`
+	VerificationCodeHash  string     `gorm:"size:64" json:"-"`
+	VerificationExpiresAt *time.Time `json:"-"`
+	VerificationAttempts  int        `gorm:"not null;default:0" json:"-"`          // 当前验证码已尝试的次数
+	Secret                string     `gorm:"size:80;not null;default:''" json:"-"` // webhook签名密钥，只在登记和轮换时返回一次
+	VerifiedAt            *time.Time `json:"verified_at,omitempty"`
+	CreatedAt             time.Time  `json:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at"`
+}
+
+func (NotificationChannel) TableName() string {
+	return "notification_channels"
+}
+
+// WebhookDelivery webhook的一次投递尝试，StatusCode 为0表示未收到响应(连接失败或超时)
+type WebhookDelivery struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ChannelID   uint      `gorm:"not null;index:idx_webhook_deliveries_channel_time,priority:1" json:"-"`
+	UserAddress string    `gorm:"size:42;not null" json:"-"`
+	DeliveryID  string    `gorm:"size:32;not null" json:"delivery_id"` // 与请求头 X-Webhook-ID 相同
+	Event       string    `gorm:"size:30;not null" json:"event"`
+	StatusCode  int       `gorm:"not null;default:0" json:"status_code"`
+	Error       string    `gorm:"type:text;not null;default:''" json:"error,omitempty"`
+	DurationMs  int64     `gorm:"not null;default:0" json:"duration_ms"`
+	AttemptedAt time.Time `gorm:"not null;index:idx_webhook_deliveries_channel_time,priority:2" json:"attempted_at"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// NotificationSubscription 用户订阅的事件，VaultAddress为空表示所有资金库
+type NotificationSubscription struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	UserAddress  string     `gorm:"size:42;not null" json:"user_address"`
+	Event        string     `gorm:"size:30;not null" json:"event"`
+	VaultAddress string     `gorm:"size:42;not null;default:''" json:"vault_address"`
+	Threshold    *float64   `gorm:"type:decimal(10,8)" json:"threshold,omitempty"` // apy_below 的阈值
+	TriggeredAt  *time.Time `json:"triggered_at,omitempty"`                        // apy_below 已触发且尚未恢复
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func (NotificationSubscription) TableName() string {
+	return "notification_subscriptions"
+}
+
+// APY告警规则的统计窗口和方向
+const (
+	AlertWindowCurrent = "current"
+	AlertWindow7d      = "7d"
+
+	AlertAbove = "above"
+	AlertBelow = "below"
+)
+
+// APYAlertRule 用户的APY告警规则，条件成立时通知一次，条件解除后重新生效
+type APYAlertRule struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	UserAddress    string     `gorm:"size:42;not null;uniqueIndex:idx_apy_alert_rules_rule,priority:1" json:"user_address"`
+	VaultAddress   string     `gorm:"size:42;not null;index;uniqueIndex:idx_apy_alert_rules_rule,priority:2" json:"vault_address"`
+	Window         string     `gorm:"column:apy_window;size:10;not null;uniqueIndex:idx_apy_alert_rules_rule,priority:3" json:"window"` // current, 7d
+	Direction      string     `gorm:"size:10;not null;uniqueIndex:idx_apy_alert_rules_rule,priority:4" json:"direction"`                // above, below
+	Threshold      float64    `gorm:"type:decimal(10,8);not null;uniqueIndex:idx_apy_alert_rules_rule,priority:5" json:"threshold"`
+	TriggeredAt    *time.Time `json:"triggered_at,omitempty"` // 条件成立且尚未解除
+	LastNotifiedAt *time.Time `json:"last_notified_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func (APYAlertRule) TableName() string {
+	return "apy_alert_rules"
+}
+
+// 告警触发的来源
+const (
+	AlertSourceSubscription = "subscription"
+	AlertSourceRule         = "rule"
+)
+
+// AlertTrigger APY订阅或告警规则的一次触发，冷却期内只记录不通知时 Notified 为false
+type AlertTrigger struct {
+	ID           uint            `gorm:"primaryKey" json:"id"`
+	UserAddress  string          `gorm:"size:42;not null" json:"user_address"`
+	VaultAddress string          `gorm:"size:42;not null" json:"vault_address"`
+	Event        string          `gorm:"size:30;not null" json:"event"` // apy_below, apy_above
+	Source       string          `gorm:"size:20;not null" json:"source"`
+	SourceID     uint            `gorm:"not null" json:"source_id"`
+	Details      json.RawMessage `gorm:"type:jsonb" json:"details,omitempty"`
+	Notified     bool            `gorm:"not null;default:true" json:"notified"`
+	TriggeredAt  time.Time       `gorm:"not null" json:"triggered_at"`
+}
+
+func (AlertTrigger) TableName() string {
+	return "alert_triggers"
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// PendleMarket 固定利率策略持有PT的Pendle市场及最近一次读取的隐含利率。
+// 到期时间同时写入策略的 maturity，资金库列表据此区分固定和浮动利率
+type PendleMarket struct {
+	StrategyAddress string    `gorm:"primaryKey;size:42" json:"strategy_address"`
+	Market          string    `gorm:"size:42;not null" json:"market"`
+	SY              string    `gorm:"column:sy;size:42;not null" json:"sy"`
+	PT              string    `gorm:"column:pt;size:42;not null" json:"pt"`
+	YT              string    `gorm:"column:yt;size:42;not null" json:"yt"`
+	Expiry          time.Time `gorm:"not null" json:"expiry"`
+	ImpliedAPY      float64   `gorm:"column:implied_apy;type:decimal(10,8);not null;default:0" json:"implied_apy"`
+	PTPrice         float64   `gorm:"column:pt_price;type:decimal(20,18);not null;default:0" json:"pt_price"` // 1个PT以底层资产计的价格
+	BlockNumber     uint64    `gorm:"not null;default:0" json:"block_number"`
+	SyncedAt        time.Time `gorm:"not null" json:"synced_at"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+func (PendleMarket) TableName() string {
+	return "pendle_markets"
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// 个人数据删除请求状态
+const (
+	DeletionPending   = "pending"
+	DeletionCompleted = "completed"
+	DeletionFailed    = "failed"
+)
+
+// DataDeletionRequest 用户发起的链下个人数据删除请求，处理完成后保留作为删除记录
+type DataDeletionRequest struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	UserAddress string     `gorm:"size:42;not null" json:"user_address"`
+	Status      string     `gorm:"size:20;not null;default:pending" json:"status"`
+	Error       string     `gorm:"not null;default:''" json:"error,omitempty"`
+	RequestedAt time.Time  `gorm:"not null" json:"requested_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
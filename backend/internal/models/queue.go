@@ -0,0 +1,35 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 队列任务状态。失败后等待重试的任务回到 available，run_at 为下一次尝试时间
+const (
+	QueueJobAvailable = "available"
+	QueueJobRunning   = "running"
+	QueueJobCompleted = "completed"
+	QueueJobFailed    = "failed"
+)
+
+// QueueJob 持久化队列中的一个异步任务。worker领取后在 lease_until 之前必须结束，
+// 过期仍处于 running 的任务视为执行中的实例已退出，重新放回队列
+type QueueJob struct {
+	ID          uint            `gorm:"primaryKey" json:"id"`
+	Kind        string          `gorm:"size:50;not null;index:idx_queue_jobs_kind_status,priority:1" json:"kind"`
+	Payload     json.RawMessage `gorm:"type:jsonb;not null" json:"payload"`
+	Status      string          `gorm:"size:20;not null;index:idx_queue_jobs_kind_status,priority:2;index:idx_queue_jobs_status_run_at,priority:1" json:"status"`
+	UniqueKey   *string         `gorm:"size:100" json:"unique_key,omitempty"` // 同一键同时只有一个未结束的任务
+	Attempts    int             `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts int             `gorm:"not null" json:"max_attempts"`
+	RunAt       time.Time       `gorm:"not null;index:idx_queue_jobs_status_run_at,priority:2" json:"run_at"`
+	LeaseUntil  *time.Time      `json:"lease_until,omitempty"`
+	LastError   string          `gorm:"type:text;not null;default:''" json:"last_error,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+}
+
+func (QueueJob) TableName() string {
+	return "queue_jobs"
+}
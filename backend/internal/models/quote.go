@@ -0,0 +1,37 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// 报价类型。早期版本保存的 "zap" 报价不能重新报价，也不能用于存款
+const (
+	QuoteDeposit = "deposit" // 直接存入底层资产
+)
+
+// Quote 存款报价。请求参数(资金库、代币、数量、滑点)创建后不变，预期份额和gas等
+// 易变结果保存在 Payload 中，重新报价时只刷新 Payload 并顺延 ExpiresAt。UsedAt 非空表示已被存款意向使用
+type Quote struct {
+	ID           string          `gorm:"primaryKey;size:34" json:"id"`
+	Kind         string          `gorm:"size:10;not null" json:"kind"`
+	UserAddress  string          `gorm:"size:42;not null;index" json:"user_address"`
+	VaultAddress string          `gorm:"size:42;not null" json:"vault_address"`
+	ChainID      uint            `gorm:"not null" json:"chain_id"`
+	TokenIn      string          `gorm:"size:42;not null" json:"token_in"`
+	Amount       decimal.Decimal `gorm:"type:decimal(36,18);not null" json:"amount"` // 存入的底层资产或卖出的代币数量
+	SlippageBps  uint16          `gorm:"not null" json:"slippage_bps"`
+	Payload      json.RawMessage `gorm:"type:jsonb;not null" json:"payload"`
+	Refreshes    int             `gorm:"not null;default:0" json:"refreshes"`
+	ExpiresAt    time.Time       `gorm:"not null" json:"expires_at"`
+	UsedAt       *time.Time      `json:"used_at,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+// Expired 在at时刻是否已过期
+func (q *Quote) Expired(at time.Time) bool {
+	return !at.Before(q.ExpiresAt)
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// 再平衡提案状态
+const (
+	RebalanceStatusPending    = "pending"
+	RebalanceStatusApproved   = "approved"
+	RebalanceStatusRejected   = "rejected"
+	RebalanceStatusExecuted   = "executed"
+	RebalanceStatusSuperseded = "superseded"
+)
+
+// RebalanceProposal 再平衡提案模型
+type RebalanceProposal struct {
+	ID           uint            `gorm:"primaryKey" json:"id"`
+	VaultAddress string          `gorm:"size:42;not null;index" json:"vault_address"`
+	Status       string          `gorm:"size:20;not null;default:pending;index" json:"status"`
+	TotalAssets  decimal.Decimal `gorm:"type:decimal(36,18);not null" json:"total_assets"`
+	CurrentAPY   float64         `gorm:"type:decimal(10,8);not null" json:"current_apy"`
+	ProposedAPY  float64         `gorm:"type:decimal(10,8);not null" json:"proposed_apy"`
+	Reason       string          `gorm:"size:255" json:"reason"`
+	ReviewedBy   string          `gorm:"size:42" json:"reviewed_by,omitempty"`
+	ReviewedAt   *time.Time      `json:"reviewed_at,omitempty"`
+	ExecutedAt   *time.Time      `json:"executed_at,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+
+	// 关联关系
+	Items []RebalanceItem `gorm:"foreignKey:ProposalID" json:"items,omitempty"`
+}
+
+// RebalanceItem 再平衡提案中单个策略的调整
+type RebalanceItem struct {
+	ID              uint            `gorm:"primaryKey" json:"id"`
+	ProposalID      uint            `gorm:"not null;index" json:"proposal_id"`
+	StrategyAddress string          `gorm:"size:42;not null" json:"strategy_address"`
+	StrategyAPY     float64         `gorm:"type:decimal(10,8);not null" json:"strategy_apy"`
+	RiskScore       uint8           `gorm:"not null" json:"risk_score"`
+	CurrentAssets   decimal.Decimal `gorm:"type:decimal(36,18);not null" json:"current_assets"`
+	TargetAssets    decimal.Decimal `gorm:"type:decimal(36,18);not null" json:"target_assets"`
+	CurrentBps      uint16          `gorm:"not null" json:"current_bps"`
+	TargetBps       uint16          `gorm:"not null" json:"target_bps"`
+	TxHash          string          `gorm:"size:66" json:"tx_hash,omitempty"`
+}
+
+func (RebalanceProposal) TableName() string {
+	return "rebalance_proposals"
+}
+
+func (RebalanceItem) TableName() string {
+	return "rebalance_items"
+}
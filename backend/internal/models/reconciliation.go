@@ -0,0 +1,31 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 对账结果
+const (
+	ReconciliationOK            = "ok"
+	ReconciliationDiscrepancies = "discrepancies" // 存在超过容差的差异
+	ReconciliationIncomplete    = "incomplete"    // 部分合约读取失败，未读取的部分未核对
+)
+
+// ReconciliationReport 一次链上状态与数据库的对账，Discrepancies 为超过容差的差异明细
+type ReconciliationReport struct {
+	ID               uint            `gorm:"primaryKey" json:"id"`
+	Status           string          `gorm:"size:20;not null" json:"status"`
+	Tolerance        float64         `gorm:"not null" json:"tolerance"`
+	VaultsChecked    int             `gorm:"not null;default:0" json:"vaults_checked"`
+	UsersChecked     int             `gorm:"not null;default:0" json:"users_checked"`
+	DiscrepancyCount int             `gorm:"not null;default:0" json:"discrepancy_count"`
+	Discrepancies    json.RawMessage `gorm:"type:jsonb" json:"discrepancies,omitempty"`
+	Errors           json.RawMessage `gorm:"type:jsonb" json:"errors,omitempty"` // 读取失败的合约及原因
+	StartedAt        time.Time       `gorm:"not null;index" json:"started_at"`
+	FinishedAt       time.Time       `gorm:"not null" json:"finished_at"`
+}
+
+func (ReconciliationReport) TableName() string {
+	return "reconciliation_reports"
+}
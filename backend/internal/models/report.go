@@ -0,0 +1,19 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DailyReport 某一天(UTC)的平台汇总报告，Summary 为生成时的完整快照
+type DailyReport struct {
+	ID          uint            `gorm:"primaryKey" json:"id"`
+	ReportDate  time.Time       `gorm:"type:date;not null;uniqueIndex" json:"report_date"`
+	Summary     json.RawMessage `gorm:"type:jsonb;not null" json:"summary"`
+	DeliveredAt *time.Time      `json:"delivered_at,omitempty"` // 推送到Slack/Telegram的时间
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+func (DailyReport) TableName() string {
+	return "daily_reports"
+}
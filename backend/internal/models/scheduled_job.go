@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// ScheduledJob worker中定时任务的调度状态。worker执行后写入状态，
+// API进程通过 paused 和 trigger_requested_at 暂停或手动触发任务，由worker轮询生效
+type ScheduledJob struct {
+	Name               string     `gorm:"primaryKey;size:50" json:"name"`
+	Schedule           string     `gorm:"size:100;not null" json:"schedule"`
+	Timeout            int64      `gorm:"not null;default:0" json:"timeout"` // 秒，0表示不限制
+	Paused             bool       `gorm:"not null;default:false" json:"paused"`
+	Running            bool       `gorm:"not null;default:false" json:"running"`
+	TriggerRequestedAt *time.Time `json:"trigger_requested_at,omitempty"`
+	NextRunAt          *time.Time `json:"next_run_at,omitempty"`
+	LastStartedAt      *time.Time `json:"last_started_at,omitempty"`
+	LastFinishedAt     *time.Time `json:"last_finished_at,omitempty"`
+	LastStatus         string     `gorm:"size:20;not null;default:''" json:"last_status"`
+	LastError          string     `gorm:"type:text;not null;default:''" json:"last_error"`
+	LastDurationMs     int64      `gorm:"not null;default:0" json:"last_duration_ms"`
+	Runs               int64      `gorm:"not null;default:0" json:"runs"`
+	Failures           int64      `gorm:"not null;default:0" json:"failures"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+func (ScheduledJob) TableName() string {
+	return "scheduled_jobs"
+}
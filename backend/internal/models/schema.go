@@ -0,0 +1,60 @@
+package models
+
+// All 全部持久化模型，database.driver=sqlite 时按这些模型自动建表。
+// 新增表时需要同时添加迁移脚本和这里的模型
+func All() []interface{} {
+	return []interface{}{
+		&User{},
+		&Vault{},
+		&Strategy{},
+		&Transaction{},
+		&APYHistory{},
+		&APYDaily{},
+		&APYForecast{},
+		&StrategySnapshot{},
+		&ProtocolRate{},
+		&TokenPrice{},
+		&SharePrice{},
+		&Harvest{},
+		&FeeAccrual{},
+		&LedgerJournal{},
+		&LedgerEntry{},
+		&StrategyLPToken{},
+		&VaultEvent{},
+		&VaultAllowlistEntry{},
+		&RebalanceProposal{},
+		&RebalanceItem{},
+		&NotificationChannel{},
+		&NotificationSubscription{},
+		&WebhookDelivery{},
+		&APYAlertRule{},
+		&AlertTrigger{},
+		&WatchlistEntry{},
+		&Keeper{},
+		&KeeperJob{},
+		&AutomationTask{},
+		&AuditLog{},
+		&DataDeletionRequest{},
+		&ScreeningDecision{},
+		&DailyReport{},
+		&ReconciliationReport{},
+		&BackfillCheckpoint{},
+		&ScheduledJob{},
+		&QueueJob{},
+		&Contract{},
+		&UniswapV3Position{},
+		&UniswapV3Snapshot{},
+		&MorphoMarket{},
+		&DSRRate{},
+		&PendleMarket{},
+		&DisplayMetadata{},
+		&Token{},
+		&Intent{},
+		&Quote{},
+		&RebalanceSuggestion{},
+		&StatusPeriod{},
+		&Incident{},
+		&IncidentUpdate{},
+		&OperatorAlert{},
+	}
+}
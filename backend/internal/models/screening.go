@@ -0,0 +1,30 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 制裁筛查结论
+const (
+	ScreeningAllowed     = "allowed"
+	ScreeningBlocked     = "blocked"
+	ScreeningUnavailable = "unavailable" // 提供方不可用且未配置放行，存款被拒绝
+)
+
+// ScreeningDecision 一次存款意向的制裁筛查结论，供合规复核，不随用户数据删除请求清除
+type ScreeningDecision struct {
+	ID              uint            `gorm:"primaryKey" json:"id"`
+	UserAddress     string          `gorm:"size:42;not null;index" json:"user_address"`
+	VaultAddress    string          `gorm:"size:42;not null" json:"vault_address"`
+	Decision        string          `gorm:"size:20;not null" json:"decision"`
+	Provider        string          `gorm:"size:30;not null" json:"provider"` // chainalysis, blocklist
+	Identifications json.RawMessage `gorm:"type:jsonb" json:"identifications,omitempty"`
+	Cached          bool            `gorm:"not null;default:false" json:"cached"`
+	Error           string          `gorm:"type:text;not null;default:''" json:"error,omitempty"`
+	CreatedAt       time.Time       `gorm:"index" json:"created_at"`
+}
+
+func (ScreeningDecision) TableName() string {
+	return "screening_decisions"
+}
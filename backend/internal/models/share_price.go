@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// SharePrice 资金库份额价格快照，price_per_share = total_assets / total_supply，尚无份额时为1
+type SharePrice struct {
+	ID            uint            `gorm:"primaryKey" json:"id"`
+	VaultAddress  string          `gorm:"size:42;not null" json:"vault_address"`
+	TotalAssets   decimal.Decimal `gorm:"type:decimal(36,18);not null" json:"total_assets"`
+	TotalSupply   decimal.Decimal `gorm:"type:decimal(36,18);not null" json:"total_supply"`
+	PricePerShare decimal.Decimal `gorm:"type:decimal(36,18);not null" json:"price_per_share"`
+	Timestamp     time.Time       `gorm:"not null" json:"timestamp"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// 状态页的组件状态，按严重程度递增
+const (
+	StatusOperational = "operational"
+	StatusDegraded    = "degraded"
+	StatusOutage      = "outage"
+)
+
+// StatusPeriod 组件处于某一状态的一段时间。状态变化时结束当前记录并开始新记录，
+// EndedAt 为空表示当前状态；CheckedAt 为该状态下最近一次检查的时间
+type StatusPeriod struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	Component string     `gorm:"size:40;not null;index:idx_status_history_component_started,priority:1" json:"component"` // database, prices, keeper, indexer:<链ID>
+	Status    string     `gorm:"size:20;not null" json:"status"`
+	Message   string     `gorm:"size:255;not null;default:''" json:"message,omitempty"`
+	StartedAt time.Time  `gorm:"not null;index:idx_status_history_component_started,priority:2" json:"started_at"`
+	CheckedAt time.Time  `gorm:"not null" json:"checked_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+}
+
+func (StatusPeriod) TableName() string {
+	return "status_history"
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// RebalanceSuggestion 用户持仓的调仓建议：把 FromVault 中的持仓转到同链同资产、风险分不更高且净APY更高的 ToVault。
+// 每个持仓最多一条，每次评估整体替换，建议不再成立时删除。NotifiedAt 为已通知的时间，同一去向只通知一次
+type RebalanceSuggestion struct {
+	ID               uint            `gorm:"primaryKey" json:"id"`
+	UserAddress      string          `gorm:"size:42;not null;uniqueIndex:idx_rebalance_suggestions_user_vault,priority:1" json:"user_address"`
+	FromVault        string          `gorm:"size:42;not null;uniqueIndex:idx_rebalance_suggestions_user_vault,priority:2" json:"from_vault"`
+	ToVault          string          `gorm:"size:42;not null" json:"to_vault"`
+	ChainID          uint            `gorm:"not null" json:"chain_id"`
+	Assets           decimal.Decimal `gorm:"type:decimal(36,18);not null" json:"assets"`
+	ValueUSD         float64         `gorm:"not null" json:"value_usd"`
+	CurrentNetAPY    float64         `gorm:"type:decimal(10,8);not null" json:"current_net_apy"`
+	TargetNetAPY     float64         `gorm:"type:decimal(10,8);not null" json:"target_net_apy"`
+	CurrentRiskScore float64         `gorm:"not null" json:"current_risk_score"`
+	TargetRiskScore  float64         `gorm:"not null" json:"target_risk_score"`
+	GasCostUSD       float64         `gorm:"not null" json:"gas_cost_usd"`    // 取出并存入新资金库的gas成本
+	AnnualGainUSD    float64         `gorm:"not null" json:"annual_gain_usd"` // 多出的年化收益，未扣除gas
+	BreakEvenDays    float64         `gorm:"not null" json:"break_even_days"` // 多出的收益覆盖gas所需天数
+	NotifiedAt       *time.Time      `json:"notified_at,omitempty"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+}
+
+func (RebalanceSuggestion) TableName() string {
+	return "rebalance_suggestions"
+}
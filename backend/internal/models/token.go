@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Token 代币登记表，资金库底层资产(Vault.AssetAddress)和价格配置中的代币由worker读取链上元数据写入。
+// 金额换算以这里的 decimals 为准，不再假设18位精度
+type Token struct {
+	ChainID     uint      `gorm:"primaryKey" json:"chain_id"`
+	Address     string    `gorm:"primaryKey;size:42" json:"address"`
+	Symbol      string    `gorm:"size:32;not null;default:'';index" json:"symbol"`
+	Name        string    `gorm:"size:100;not null;default:''" json:"name"`
+	Decimals    int32     `gorm:"not null" json:"decimals"`
+	LogoURL     string    `gorm:"column:logo_url;size:512;not null;default:''" json:"logo_url"`
+	CoinGeckoID string    `gorm:"column:coingecko_id;size:100;not null;default:''" json:"coingecko_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (Token) TableName() string {
+	return "tokens"
+}
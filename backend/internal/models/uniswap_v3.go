@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// UniswapV3Position 集中流动性策略持有的Uniswap v3头寸(NonfungiblePositionManager 的NFT)。
+// 代币精度在登记时读取，之后计算价格和数量不再请求链上
+type UniswapV3Position struct {
+	StrategyAddress string    `gorm:"primaryKey;size:42" json:"strategy_address"`
+	PositionManager string    `gorm:"size:42;not null" json:"position_manager"`
+	TokenID         string    `gorm:"size:78;not null" json:"token_id"`
+	Pool            string    `gorm:"size:42;not null" json:"pool"`
+	Token0          string    `gorm:"size:42;not null" json:"token0"`
+	Token1          string    `gorm:"size:42;not null" json:"token1"`
+	Decimals0       int32     `gorm:"not null" json:"decimals0"`
+	Decimals1       int32     `gorm:"not null" json:"decimals1"`
+	Fee             uint32    `gorm:"not null" json:"fee"` // 池费率，百万分之一
+	TickLower       int32     `gorm:"not null" json:"tick_lower"`
+	TickUpper       int32     `gorm:"not null" json:"tick_upper"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+func (UniswapV3Position) TableName() string {
+	return "uniswap_v3_positions"
+}
+
+// UniswapV3Snapshot 头寸的定期快照。Fees0/Fees1 为登记以来累计赚取的手续费(含已领取的)，
+// 由相邻两次快照的区间内手续费增长乘以流动性累加得到
+type UniswapV3Snapshot struct {
+	ID               uint            `gorm:"primaryKey" json:"-"`
+	StrategyAddress  string          `gorm:"size:42;not null;index:idx_uniswap_v3_snapshots_strategy_time,priority:1" json:"-"`
+	BlockNumber      uint64          `gorm:"not null" json:"block_number"`
+	Tick             int32           `gorm:"not null" json:"tick"`
+	InRange          bool            `gorm:"not null" json:"in_range"`
+	Liquidity        decimal.Decimal `gorm:"type:decimal(39,0);not null" json:"liquidity"`
+	Amount0          decimal.Decimal `gorm:"type:decimal(36,18);not null" json:"amount0"`
+	Amount1          decimal.Decimal `gorm:"type:decimal(36,18);not null" json:"amount1"`
+	FeeGrowthInside0 decimal.Decimal `gorm:"type:decimal(78,0);not null" json:"-"`
+	FeeGrowthInside1 decimal.Decimal `gorm:"type:decimal(78,0);not null" json:"-"`
+	Fees0            decimal.Decimal `gorm:"type:decimal(36,18);not null" json:"fees0"`
+	Fees1            decimal.Decimal `gorm:"type:decimal(36,18);not null" json:"fees1"`
+	Price0USD        *float64        `gorm:"column:price0_usd" json:"price0_usd"`
+	Price1USD        *float64        `gorm:"column:price1_usd" json:"price1_usd"`
+	Timestamp        time.Time       `gorm:"not null;index:idx_uniswap_v3_snapshots_strategy_time,priority:2" json:"timestamp"`
+}
+
+func (UniswapV3Snapshot) TableName() string {
+	return "uniswap_v3_snapshots"
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 资金库时间线事件类型
+const (
+	VaultEventStrategyAdded   = "strategy_added"
+	VaultEventStrategyRemoved = "strategy_removed"
+	VaultEventHarvest         = "harvest"
+	VaultEventRebalance       = "rebalance"
+	VaultEventFeeChange       = "fee_change"
+	VaultEventPause           = "pause"
+	VaultEventUnpause         = "unpause"
+	VaultEventEmergencyStop   = "emergency_stop"
+)
+
+// ValidVaultEventType 是否为已知的时间线事件类型
+func ValidVaultEventType(t string) bool {
+	switch t {
+	case VaultEventStrategyAdded, VaultEventStrategyRemoved, VaultEventHarvest, VaultEventRebalance,
+		VaultEventFeeChange, VaultEventPause, VaultEventUnpause, VaultEventEmergencyStop:
+		return true
+	}
+	return false
+}
+
+// VaultEvent 资金库运行时间线上的一条事件。链上事件带 tx_hash/log_index 并据此去重，
+// 管理操作记录操作人 actor
+type VaultEvent struct {
+	ID           uint            `gorm:"primaryKey" json:"id"`
+	VaultAddress string          `gorm:"size:42;not null" json:"vault_address"`
+	Type         string          `gorm:"size:30;not null" json:"type"`
+	Actor        string          `gorm:"size:42;not null;default:''" json:"actor,omitempty"`
+	TxHash       string          `gorm:"size:66;not null;default:''" json:"tx_hash,omitempty"`
+	LogIndex     uint            `gorm:"not null;default:0" json:"log_index,omitempty"`
+	Details      json.RawMessage `gorm:"type:jsonb" json:"details,omitempty"`
+	OccurredAt   time.Time       `gorm:"not null" json:"occurred_at"`
+}
+
+func (VaultEvent) TableName() string {
+	return "vault_events"
+}
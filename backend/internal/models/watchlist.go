@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// WatchlistEntry 用户收藏的资金库
+type WatchlistEntry struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	UserAddress  string    `gorm:"size:42;not null;uniqueIndex:idx_vault_watchlist_user_vault,priority:1" json:"user_address"`
+	VaultAddress string    `gorm:"size:42;not null;uniqueIndex:idx_vault_watchlist_user_vault,priority:2" json:"vault_address"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (WatchlistEntry) TableName() string {
+	return "vault_watchlist"
+}
@@ -0,0 +1,216 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+)
+
+// leaseGrace 租约在执行超时之外多留的时间，超时的任务返回前不会被其他实例重复领取
+const leaseGrace = time.Minute
+
+// rescueInterval 检查租约过期任务的间隔
+const rescueInterval = time.Minute
+
+// Options 入队选项
+type Options struct {
+	RunAt       time.Time // 最早执行时间，零值表示立即执行
+	MaxAttempts int       // 0 表示使用 queue.max_attempts
+	UniqueKey   string    // 非空时同一键同时只有一个未结束的任务，重复入队被忽略
+}
+
+// Enqueue 把任务写入队列，payload 序列化为JSON。返回false表示 UniqueKey 相同的任务尚未结束，本次未入队
+func Enqueue(kind string, payload interface{}, opts Options) (*models.QueueJob, bool, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, false, fmt.Errorf("encode %s payload: %w", kind, err)
+	}
+	job := &models.QueueJob{
+		Kind:        kind,
+		Payload:     raw,
+		Status:      models.QueueJobAvailable,
+		MaxAttempts: opts.MaxAttempts,
+		RunAt:       opts.RunAt,
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = config.Load().Queue.MaxAttempts
+	}
+	if job.RunAt.IsZero() {
+		job.RunAt = time.Now()
+	}
+	if opts.UniqueKey != "" {
+		job.UniqueKey = &opts.UniqueKey
+	}
+
+	created, err := repository.NewQueueRepository().Enqueue(job)
+	if err != nil {
+		return nil, false, err
+	}
+	return job, created, nil
+}
+
+// permanentError 不应重试的失败
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent 标记错误不可重试(如负载无效、目标已删除)，任务直接标记为失败
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// Handler 一类任务的执行函数。Run 需要响应ctx取消，返回错误时按退避策略重试
+type Handler struct {
+	Kind    string
+	Timeout time.Duration // 单次执行超时，0 表示使用 queue.timeout
+	Run     func(ctx context.Context, payload json.RawMessage) error
+}
+
+// Worker 从队列领取并执行已注册类型的任务，多个实例可同时运行
+type Worker struct {
+	repo     *repository.QueueRepository
+	handlers map[string]Handler
+	leases   map[string]time.Duration
+	cfg      config.QueueConfig
+}
+
+func NewWorker(handlers ...Handler) *Worker {
+	cfg := config.Load().Queue
+	w := &Worker{
+		repo:     repository.NewQueueRepository(),
+		handlers: make(map[string]Handler, len(handlers)),
+		leases:   make(map[string]time.Duration, len(handlers)),
+		cfg:      cfg,
+	}
+	for _, h := range handlers {
+		if h.Timeout <= 0 {
+			h.Timeout = time.Duration(cfg.Timeout) * time.Second
+		}
+		w.handlers[h.Kind] = h
+		w.leases[h.Kind] = h.Timeout + leaseGrace
+	}
+	return w
+}
+
+// Start 启动 queue.concurrency 个执行goroutine和一个租约检查goroutine，ctx取消时停止领取新任务。
+// 返回的WaitGroup在正在执行的任务结束后完成，被中断的任务放回队列
+func (w *Worker) Start(ctx context.Context) *sync.WaitGroup {
+	var wg sync.WaitGroup
+	for i := 0; i < max(w.cfg.Concurrency, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(rescueInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if n, err := w.repo.RescueExpired(); err == nil && n > 0 {
+					logger.Info(fmt.Sprintf("♻️ Rescued %d queue job(s) with expired leases", n))
+				}
+			}
+		}
+	}()
+
+	logger.Info(fmt.Sprintf("📬 Queue worker started with %d slot(s) for %d job kind(s)", max(w.cfg.Concurrency, 1), len(w.handlers)))
+	return &wg
+}
+
+func (w *Worker) loop(ctx context.Context) {
+	poll := time.Duration(w.cfg.PollInterval) * time.Millisecond
+	for ctx.Err() == nil {
+		job, err := w.repo.Claim(w.leases)
+		if err != nil || job == nil {
+			select {
+			case <-ctx.Done():
+			case <-time.After(poll):
+			}
+			continue
+		}
+		w.process(ctx, job)
+	}
+}
+
+// process 执行一个已领取的任务并按结果完成、重试或标记失败
+func (w *Worker) process(ctx context.Context, job *models.QueueJob) {
+	handler := w.handlers[job.Kind]
+	runCtx, cancel := context.WithTimeout(ctx, handler.Timeout)
+	start := time.Now()
+	err := call(runCtx, handler.Run, job.Payload)
+	cancel()
+
+	var permanent *permanentError
+	var outcome error
+	switch {
+	case err == nil:
+		outcome = w.repo.Complete(job)
+		logger.Info(fmt.Sprintf("Queue job %d (%s) finished in %v", job.ID, job.Kind, time.Since(start)))
+	case ctx.Err() != nil:
+		// worker退出中断了执行，放回队列由下一个实例重新执行
+		outcome = w.repo.Release(job)
+		logger.Info(fmt.Sprintf("Queue job %d (%s) interrupted by shutdown, released", job.ID, job.Kind))
+	case errors.As(err, &permanent) || job.Attempts >= job.MaxAttempts:
+		outcome = w.repo.Fail(job, err.Error())
+		logger.Error(fmt.Sprintf("Queue job %d (%s) failed after %d attempt(s): %v", job.ID, job.Kind, job.Attempts, err))
+	default:
+		delay := Backoff(job.Attempts, w.cfg)
+		outcome = w.repo.Retry(job, err.Error(), time.Now().Add(delay))
+		logger.Error(fmt.Sprintf("Queue job %d (%s) attempt %d/%d failed, retrying in %v: %v",
+			job.ID, job.Kind, job.Attempts, job.MaxAttempts, delay.Round(time.Second), err))
+	}
+
+	// 结果未能保存时任务仍是 running，租约过期后由 RescueExpired 放回队列
+	switch {
+	case errors.Is(outcome, repository.ErrQueueLeaseLost):
+		logger.Error(fmt.Sprintf("Queue job %d (%s) attempt %d outlived its lease; the result was discarded", job.ID, job.Kind, job.Attempts))
+	case outcome != nil:
+		logger.Error(fmt.Sprintf("Failed to save result of queue job %d (%s): %v", job.ID, job.Kind, outcome))
+	}
+}
+
+// call 执行任务并把panic转换为错误，panic视为可重试的失败
+func call(ctx context.Context, run func(ctx context.Context, payload json.RawMessage) error, payload json.RawMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return run(ctx, payload)
+}
+
+// Backoff 第attempt次失败后的等待：retry_base 按次数翻倍，不超过 retry_max，
+// 再在 [d/2, d] 内随机取值，避免同时失败的任务同时重试
+func Backoff(attempt int, cfg config.QueueConfig) time.Duration {
+	base := time.Duration(cfg.RetryBase) * time.Second
+	limit := time.Duration(cfg.RetryMax) * time.Second
+	d := base
+	for i := 1; i < attempt && d < limit; i++ {
+		d *= 2
+	}
+	d = min(d, limit)
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + rand.N(d/2+1)
+}
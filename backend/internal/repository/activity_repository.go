@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// activityQuery 合并用户的交易、告警触发，以及用户存入过的资金库自首次存入以来的时间线事件。
+// 排序键 id = 来源记录id * 3 + 来源序号，不同来源在同一时刻的记录也有确定的顺序，可以直接复用 (时间, id) 游标
+const activityQuery = `
+SELECT 'transaction' AS kind, t.type, t.id AS ref_id, t.id * 3 AS id, t.vault_address, t.tx_hash,
+       t.amount, t.shares, t.status, CAST(NULL AS jsonb) AS details, t.created_at AS occurred_at
+FROM transactions t
+WHERE t.user_address = ? AND t.deleted_at IS NULL
+UNION ALL
+SELECT 'alert', a.event, a.id, a.id * 3 + 1, a.vault_address, '',
+       NULL, NULL, '', a.details, a.triggered_at
+FROM alert_triggers a
+WHERE a.user_address = ?
+UNION ALL
+SELECT 'vault_event', e.type, e.id, e.id * 3 + 2, e.vault_address, e.tx_hash,
+       NULL, NULL, '', e.details, e.occurred_at
+FROM vault_events e
+JOIN (
+    SELECT vault_address, MIN(created_at) AS since
+    FROM transactions
+    WHERE user_address = ? AND type = 'deposit' AND status = 'confirmed' AND deleted_at IS NULL
+    GROUP BY vault_address
+) p ON p.vault_address = e.vault_address AND e.occurred_at >= p.since`
+
+type ActivityRepository struct {
+	db *gorm.DB
+}
+
+func NewActivityRepository() *ActivityRepository {
+	return &ActivityRepository{
+		db: database.GetDB(),
+	}
+}
+
+// GetUserActivity 按时间倒序分页获取用户动态
+func (r *ActivityRepository) GetUserActivity(userAddress string, cursor *Cursor, limit int) ([]models.ActivityItem, *Page, error) {
+	query := r.db.Table("(?) AS activity", gorm.Expr(activityQuery, userAddress, userAddress, userAddress))
+	items, page, err := paginate(query, "occurred_at", cursor, limit, func(item models.ActivityItem) Cursor {
+		return Cursor{Time: item.OccurredAt, ID: item.ID}
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get activity of %s: %v", userAddress, err))
+		return nil, nil, err
+	}
+	return items, page, nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AllowlistRepository struct {
+	db *gorm.DB
+}
+
+func NewAllowlistRepository() *AllowlistRepository {
+	return &AllowlistRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Add 批量加入白名单，已存在的地址保持不变，返回新加入的数量
+func (r *AllowlistRepository) Add(entries []models.VaultAllowlistEntry) (int64, error) {
+	if len(entries) == 0 {
+		return 0, nil
+	}
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(entries, batchSize)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to add allowlist entries: %v", result.Error))
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// Remove 从白名单移除地址，返回false表示地址不在白名单中
+func (r *AllowlistRepository) Remove(vaultAddress, userAddress string) (bool, error) {
+	result := r.db.Where("vault_address = ? AND user_address = ?", vaultAddress, userAddress).
+		Delete(&models.VaultAllowlistEntry{})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to remove %s from allowlist of %s: %v", userAddress, vaultAddress, result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// List 获取资金库的白名单，按加入时间排序
+func (r *AllowlistRepository) List(vaultAddress string) ([]models.VaultAllowlistEntry, error) {
+	var entries []models.VaultAllowlistEntry
+	result := r.db.Where("vault_address = ?", vaultAddress).Order("created_at ASC, id ASC").Find(&entries)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to list allowlist of %s: %v", vaultAddress, result.Error))
+		return nil, result.Error
+	}
+	return entries, nil
+}
+
+// Contains 地址是否在资金库白名单中
+func (r *AllowlistRepository) Contains(vaultAddress, userAddress string) (bool, error) {
+	var count int64
+	result := r.db.Model(&models.VaultAllowlistEntry{}).
+		Where("vault_address = ? AND user_address = ?", vaultAddress, userAddress).Count(&count)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to check allowlist of %s: %v", vaultAddress, result.Error))
+		return false, result.Error
+	}
+	return count > 0, nil
+}
+
+// ListByUser 获取地址所在的全部白名单记录
+func (r *AllowlistRepository) ListByUser(userAddress string) ([]models.VaultAllowlistEntry, error) {
+	var entries []models.VaultAllowlistEntry
+	result := r.db.Where("user_address = ?", userAddress).Order("created_at ASC, id ASC").Find(&entries)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to list allowlist entries of %s: %v", userAddress, result.Error))
+		return nil, result.Error
+	}
+	return entries, nil
+}
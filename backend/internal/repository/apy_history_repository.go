@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+type APYHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewAPYHistoryRepository() *APYHistoryRepository {
+	return &APYHistoryRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 写入一条APY历史记录
+func (r *APYHistoryRepository) Create(record *models.APYHistory) error {
+	result := r.db.Create(record)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to create APY history: %v", result.Error))
+		return result.Error
+	}
+	return nil
+}
+
+// GetVaultHistory 按时间倒序分页获取资金库的原始APY记录
+func (r *APYHistoryRepository) GetVaultHistory(vaultAddress string, cursor *Cursor, limit int) ([]models.APYHistory, *Page, error) {
+	query := r.db.Model(&models.APYHistory{}).Where("vault_address = ?", vaultAddress)
+	records, page, err := paginate(query, "timestamp", cursor, limit, func(record models.APYHistory) Cursor {
+		return Cursor{Time: record.Timestamp, ID: record.ID}
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get APY history for %s: %v", vaultAddress, err))
+		return nil, nil, err
+	}
+	return records, page, nil
+}
+
+// RollupDaily 将before之前的原始记录按资金库和日期汇总写入日汇总表，
+// 从已汇总的最后一天开始重算，已存在的日期会被覆盖
+func (r *APYHistoryRepository) RollupDaily(before time.Time) (int64, error) {
+	// 尚无日汇总时从最早的记录开始，SQLite没有 -infinity
+	earliest := "'-infinity'::date"
+	if database.IsSQLite() {
+		earliest = "'0001-01-01'"
+	}
+	result := r.db.Exec(`
+		INSERT INTO apy_history_daily (vault_address, day, avg_apy, min_apy, max_apy, avg_tvl, samples)
+		SELECT vault_address, DATE(timestamp), AVG(apy_value), MIN(apy_value), MAX(apy_value), AVG(tvl), COUNT(*)
+		FROM apy_history
+		WHERE timestamp < ?
+		  AND timestamp >= COALESCE((SELECT MAX(day) FROM apy_history_daily), `+earliest+`)
+		GROUP BY vault_address, DATE(timestamp)
+		ON CONFLICT (vault_address, day) DO UPDATE SET
+			avg_apy = EXCLUDED.avg_apy,
+			min_apy = EXCLUDED.min_apy,
+			max_apy = EXCLUDED.max_apy,
+			avg_tvl = EXCLUDED.avg_tvl,
+			samples = EXCLUDED.samples`, before)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to roll up APY history: %v", result.Error))
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// PruneBefore 删除before之前的原始记录
+func (r *APYHistoryRepository) PruneBefore(before time.Time) (int64, error) {
+	result := r.db.Where("timestamp < ?", before).Delete(&models.APYHistory{})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to prune APY history: %v", result.Error))
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// GetDaily 获取资金库在时间区间内的日汇总，按日期升序
+func (r *APYHistoryRepository) GetDaily(vaultAddress string, from, to time.Time) ([]models.APYDaily, error) {
+	var days []models.APYDaily
+	result := r.db.Where("vault_address = ? AND day BETWEEN ? AND ?", vaultAddress, from, to).
+		Order("day ASC").Find(&days)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get daily APY for %s: %v", vaultAddress, result.Error))
+		return nil, result.Error
+	}
+	return days, nil
+}
+
+// TrailingAPY 资金库在最近7/30/90天日汇总上的平均APY，无数据时为空
+type TrailingAPY struct {
+	VaultAddress string
+	APY7d        *float64 `gorm:"column:apy_7d"`
+	APY30d       *float64 `gorm:"column:apy_30d"`
+	APY90d       *float64 `gorm:"column:apy_90d"`
+}
+
+// GetTrailingAverages 按资金库计算截至asOf的7/30/90天平均APY
+func (r *APYHistoryRepository) GetTrailingAverages(asOf time.Time) ([]TrailingAPY, error) {
+	var averages []TrailingAPY
+	result := r.db.Model(&models.APYDaily{}).
+		Select(`vault_address,
+			AVG(avg_apy) FILTER (WHERE day >= ?) AS apy_7d,
+			AVG(avg_apy) FILTER (WHERE day >= ?) AS apy_30d,
+			AVG(avg_apy) AS apy_90d`, asOf.AddDate(0, 0, -7), asOf.AddDate(0, 0, -30)).
+		Where("day >= ? AND day < ?", asOf.AddDate(0, 0, -90), asOf).
+		Group("vault_address").
+		Scan(&averages)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to compute trailing APY: %v", result.Error))
+		return nil, result.Error
+	}
+	return averages, nil
+}
+
+// GetAverageSince 计算资金库自since以来原始记录的平均APY，无记录时返回nil
+func (r *APYHistoryRepository) GetAverageSince(vaultAddress string, since time.Time) (*float64, error) {
+	var avg *float64
+	result := r.db.Model(&models.APYHistory{}).
+		Select("AVG(apy_value)").
+		Where("vault_address = ? AND timestamp >= ?", vaultAddress, since).
+		Scan(&avg)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to average APY for %s: %v", vaultAddress, result.Error))
+		return nil, result.Error
+	}
+	return avg, nil
+}
+
+// GetLatestBefore 每个资金库在at之前的最后一条原始记录
+func (r *APYHistoryRepository) GetLatestBefore(at time.Time) ([]models.APYHistory, error) {
+	var records []models.APYHistory
+	var result *gorm.DB
+	if database.IsSQLite() {
+		// SQLite不支持 DISTINCT ON，排除同一资金库在at之前还有更晚记录的行
+		result = r.db.Raw(`SELECT * FROM apy_history h
+			WHERE h.timestamp < ?
+			  AND NOT EXISTS (SELECT 1 FROM apy_history n
+				WHERE n.vault_address = h.vault_address AND n.timestamp < ?
+				  AND (n.timestamp > h.timestamp OR (n.timestamp = h.timestamp AND n.id > h.id)))`, at, at).Scan(&records)
+	} else {
+		result = r.db.Raw(`SELECT DISTINCT ON (vault_address) *
+			FROM apy_history
+			WHERE timestamp < ?
+			ORDER BY vault_address, timestamp DESC`, at).Scan(&records)
+	}
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get latest APY records before %v: %v", at, result.Error))
+		return nil, result.Error
+	}
+	return records, nil
+}
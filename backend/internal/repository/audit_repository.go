@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository() *AuditRepository {
+	return &AuditRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 写入一条审计日志
+func (r *AuditRepository) Create(entry *models.AuditLog) error {
+	result := r.db.Create(entry)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to write audit log: %v", result.Error))
+		return result.Error
+	}
+	return nil
+}
+
+// List 按时间倒序获取审计日志，target为空时不限对象
+func (r *AuditRepository) List(target string, limit int) ([]models.AuditLog, error) {
+	var entries []models.AuditLog
+	query := r.db.Order("created_at DESC, id DESC").Limit(limit)
+	if target != "" {
+		query = query.Where("target = ?", target)
+	}
+	if err := query.Find(&entries).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to list audit logs: %v", err))
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+type AutomationRepository struct {
+	db *gorm.DB
+}
+
+func NewAutomationRepository() *AutomationRepository {
+	return &AutomationRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 登记定时任务
+func (r *AutomationRepository) Create(task *models.AutomationTask) error {
+	if err := r.db.Create(task).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to create %s automation task %s: %v", task.Provider, task.TaskID, err))
+		return err
+	}
+	return nil
+}
+
+// Get 根据平台、链和任务ID获取定时任务
+func (r *AutomationRepository) Get(provider string, chainID uint, taskID string) (*models.AutomationTask, error) {
+	var task models.AutomationTask
+	result := r.db.Where("provider = ? AND chain_id = ? AND task_id = ?", provider, chainID, taskID).First(&task)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		logger.Error(fmt.Sprintf("Failed to get %s automation task %s: %v", provider, taskID, result.Error))
+		return nil, result.Error
+	}
+	return &task, nil
+}
+
+// List 列出定时任务，vaultAddress为空时返回全部
+func (r *AutomationRepository) List(vaultAddress string) ([]models.AutomationTask, error) {
+	var tasks []models.AutomationTask
+	query := r.db.Order("created_at ASC, id ASC")
+	if vaultAddress != "" {
+		query = query.Where("vault_address = ?", vaultAddress)
+	}
+	if err := query.Find(&tasks).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to list automation tasks: %v", err))
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// ListFailing 列出处于异常状态的定时任务，最近检查的在前
+func (r *AutomationRepository) ListFailing() ([]models.AutomationTask, error) {
+	var tasks []models.AutomationTask
+	result := r.db.Where("status NOT IN ?", []string{models.AutomationStatusActive, models.AutomationStatusUnknown}).
+		Order("last_checked_at DESC, id DESC").Find(&tasks)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to list failing automation tasks: %v", result.Error))
+		return nil, result.Error
+	}
+	return tasks, nil
+}
+
+// UpdateStatus 保存一次检查的结果
+func (r *AutomationRepository) UpdateStatus(task *models.AutomationTask) error {
+	result := r.db.Model(&models.AutomationTask{}).Where("id = ?", task.ID).Updates(map[string]interface{}{
+		"status":               task.Status,
+		"status_reason":        task.StatusReason,
+		"balance":              task.Balance,
+		"min_balance":          task.MinBalance,
+		"last_performed_block": task.LastPerformedBlock,
+		"last_checked_at":      task.LastCheckedAt,
+	})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to update automation task %d: %v", task.ID, result.Error))
+		return result.Error
+	}
+	return nil
+}
+
+// Delete 删除定时任务的登记，返回被删除的记录，不存在时返回nil
+func (r *AutomationRepository) Delete(id uint) (*models.AutomationTask, error) {
+	var task models.AutomationTask
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&task, id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&task).Error
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		logger.Error(fmt.Sprintf("Failed to delete automation task %d: %v", id, err))
+		return nil, err
+	}
+	return &task, nil
+}
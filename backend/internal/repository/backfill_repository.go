@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BackfillRepository struct {
+	db *gorm.DB
+}
+
+func NewBackfillRepository() *BackfillRepository {
+	return &BackfillRepository{
+		db: database.GetDB(),
+	}
+}
+
+// GetCheckpoint 获取资金库的回放断点
+func (r *BackfillRepository) GetCheckpoint(vaultAddress string) (*models.BackfillCheckpoint, error) {
+	var checkpoint models.BackfillCheckpoint
+	result := r.db.Where("vault_address = ?", vaultAddress).First(&checkpoint)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		logger.Error(fmt.Sprintf("Failed to get backfill checkpoint for %s: %v", vaultAddress, result.Error))
+		return nil, result.Error
+	}
+	return &checkpoint, nil
+}
+
+// SaveCheckpoint 写入或覆盖资金库的回放断点
+func (r *BackfillRepository) SaveCheckpoint(checkpoint *models.BackfillCheckpoint) error {
+	result := r.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(checkpoint)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to save backfill checkpoint for %s: %v", checkpoint.VaultAddress, result.Error))
+		return result.Error
+	}
+	return nil
+}
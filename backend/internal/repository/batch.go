@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// batchSize 每条INSERT/UPDATE语句处理的行数，避免超过Postgres单条语句65535个参数的上限
+const batchSize = 500
+
+// bulkUpdateByAddress 用 UPDATE ... FROM (VALUES ...) 按地址批量更新一个数值列。
+// 地址排序后写入，多个任务同时更新时加锁顺序一致，避免死锁
+func bulkUpdateByAddress(tx *gorm.DB, table, column string, values map[string]decimal.Decimal) error {
+	addresses := make([]string, 0, len(values))
+	for address := range values {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	// SQLite不支持带列名的 VALUES 别名，开发用的内存库数据量小，逐行更新
+	if database.IsSQLite() {
+		for _, address := range addresses {
+			err := tx.Table(table).Where("address = ?", address).
+				Updates(map[string]interface{}{column: values[address], "updated_at": time.Now()}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for start := 0; start < len(addresses); start += batchSize {
+		end := min(start+batchSize, len(addresses))
+
+		rows := make([]string, 0, end-start)
+		args := make([]interface{}, 0, 2*(end-start))
+		for _, address := range addresses[start:end] {
+			rows = append(rows, "(?, CAST(? AS NUMERIC))")
+			args = append(args, address, values[address])
+		}
+
+		sql := fmt.Sprintf("UPDATE %s AS t SET %s = data.value, updated_at = NOW() FROM (VALUES %s) AS data(address, value) WHERE t.address = data.address",
+			table, column, strings.Join(rows, ", "))
+		if err := tx.Exec(sql, args...).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ContractRepository struct {
+	db *gorm.DB
+}
+
+func NewContractRepository() *ContractRepository {
+	return &ContractRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Upsert 登记合约，同一链上的同一地址再次上传时覆盖接口类型、名称、ABI和部署区块
+func (r *ContractRepository) Upsert(contract *models.Contract) error {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chain_id"}, {Name: "address"}},
+		DoUpdates: clause.AssignmentColumns([]string{"interface", "name", "abi", "deployment_block", "uploaded_by", "updated_at"}),
+	}).Create(contract)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to save contract %s on chain %d: %v", contract.Address, contract.ChainID, result.Error))
+		return result.Error
+	}
+	return nil
+}
+
+// Get 获取已登记的合约，未登记时返回nil
+func (r *ContractRepository) Get(chainID uint, address string) (*models.Contract, error) {
+	var contracts []models.Contract
+	result := r.db.Where("chain_id = ? AND address = ?", chainID, address).Limit(1).Find(&contracts)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get contract %s on chain %d: %v", address, chainID, result.Error))
+		return nil, result.Error
+	}
+	if len(contracts) == 0 {
+		return nil, nil
+	}
+	return &contracts[0], nil
+}
+
+// List 列出已登记的合约，不含ABI正文。chainID为0、iface为空时不按该条件过滤
+func (r *ContractRepository) List(chainID uint, iface string) ([]models.Contract, error) {
+	query := r.db.Omit("abi")
+	if chainID != 0 {
+		query = query.Where("chain_id = ?", chainID)
+	}
+	if iface != "" {
+		query = query.Where("interface = ?", iface)
+	}
+
+	var contracts []models.Contract
+	if result := query.Order("chain_id ASC, address ASC").Find(&contracts); result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to list contracts: %v", result.Error))
+		return nil, result.Error
+	}
+	return contracts, nil
+}
+
+// Delete 删除登记，返回false表示合约未登记
+func (r *ContractRepository) Delete(chainID uint, address string) (bool, error) {
+	result := r.db.Where("chain_id = ? AND address = ?", chainID, address).Delete(&models.Contract{})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to delete contract %s on chain %d: %v", address, chainID, result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor 按 (时间, id) 倒序分页的位置，对外以不透明字符串传递。
+// 相比offset分页，翻到深处时不需要扫描并丢弃前面的行。Backward 为真时取该位置之前(更新)的一页，用于上一页
+type Cursor struct {
+	Time     time.Time
+	ID       uint
+	Backward bool
+}
+
+// Page 一页结果的分页信息：符合条件的总行数，以及下一页、上一页的游标(没有时为nil)
+type Page struct {
+	Total int64
+	Next  *Cursor
+	Prev  *Cursor
+}
+
+// Encode 编码为URL安全的不透明字符串
+func (c Cursor) Encode() string {
+	raw := fmt.Sprintf("%d:%d", c.Time.UnixNano(), c.ID)
+	if c.Backward {
+		raw += ":prev"
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor 解析客户端传回的游标，空字符串表示第一页
+func DecodeCursor(value string) (*Cursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) < 2 || len(parts) > 3 || (len(parts) == 3 && parts[2] != "prev") {
+		return nil, ErrInvalidCursor
+	}
+	ts, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	parsedID, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &Cursor{Time: time.Unix(0, ts).UTC(), ID: uint(parsedID), Backward: len(parts) == 3}, nil
+}
+
+// keyset 按 (timeColumn, id) 倒序取cursor之后的limit+1行，多取的一行用于判断是否还有下一页。
+// 向前翻页时按正序取cursor之前的行，由 paginate 倒回倒序
+func keyset(query *gorm.DB, timeColumn string, cursor *Cursor, limit int) *gorm.DB {
+	switch {
+	case cursor == nil:
+	case cursor.Backward:
+		return query.Where("("+timeColumn+", id) > (?, ?)", cursor.Time, cursor.ID).
+			Order(timeColumn + " ASC").Order("id ASC").Limit(limit + 1)
+	default:
+		query = query.Where("("+timeColumn+", id) < (?, ?)", cursor.Time, cursor.ID)
+	}
+	return query.Order(timeColumn + " DESC").Order("id DESC").Limit(limit + 1)
+}
+
+// paginate 统计query的总行数并按cursor取一页，返回倒序的行和上下页游标。
+// query 须指定 Model 或 Table；scopes 只作用于取数查询(如 Preload)，不参与计数
+func paginate[T any](query *gorm.DB, timeColumn string, cursor *Cursor, limit int, position func(T) Cursor, scopes ...func(*gorm.DB) *gorm.DB) ([]T, *Page, error) {
+	page := &Page{}
+	if err := query.Session(&gorm.Session{}).Count(&page.Total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var rows []T
+	if err := keyset(query.Session(&gorm.Session{}), timeColumn, cursor, limit).Scopes(scopes...).Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+	more := len(rows) > limit
+	if more {
+		rows = rows[:limit]
+	}
+	if len(rows) == 0 {
+		return rows, page, nil
+	}
+
+	backward := cursor != nil && cursor.Backward
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+	// 从游标往后翻时游标之前必有数据，往前翻时游标之后必有数据
+	if backward || more {
+		next := position(rows[len(rows)-1])
+		page.Next = &next
+	}
+	if (backward && more) || (!backward && cursor != nil) {
+		prev := position(rows[0])
+		prev.Backward = true
+		page.Prev = &prev
+	}
+	return rows, page, nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+type DSRRepository struct {
+	db *gorm.DB
+}
+
+func NewDSRRepository() *DSRRepository {
+	return &DSRRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 写入一次DSR读取
+func (r *DSRRepository) Create(rate *models.DSRRate) error {
+	if result := r.db.Create(rate); result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to save DSR rate on chain %d: %v", rate.ChainID, result.Error))
+		return result.Error
+	}
+	return nil
+}
+
+// Latest 获取链上最近一次读取，没有记录时返回nil
+func (r *DSRRepository) Latest(chainID uint) (*models.DSRRate, error) {
+	return r.first(r.db.Where("chain_id = ?", chainID).Order("timestamp DESC, id DESC"))
+}
+
+// FirstSince 获取链上since及之后的第一次读取，用作实际收益率窗口的起点
+func (r *DSRRepository) FirstSince(chainID uint, since time.Time) (*models.DSRRate, error) {
+	return r.first(r.db.Where("chain_id = ? AND timestamp >= ?", chainID, since).Order("timestamp ASC, id ASC"))
+}
+
+func (r *DSRRepository) first(query *gorm.DB) (*models.DSRRate, error) {
+	var rates []models.DSRRate
+	if result := query.Limit(1).Find(&rates); result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get DSR rate: %v", result.Error))
+		return nil, result.Error
+	}
+	if len(rates) == 0 {
+		return nil, nil
+	}
+	return &rates[0], nil
+}
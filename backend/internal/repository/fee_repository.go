@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// FeeTotals 一组费用计提的合计
+type FeeTotals struct {
+	Management  decimal.Decimal `json:"management"`
+	Performance decimal.Decimal `json:"performance"`
+	Total       decimal.Decimal `json:"total"`
+}
+
+// VaultRevenue 单个资金库的费用收入
+type VaultRevenue struct {
+	VaultAddress string `json:"vault"`
+	FeeTotals
+}
+
+// PeriodRevenue 单个周期的费用收入
+type PeriodRevenue struct {
+	Period time.Time `json:"period"`
+	FeeTotals
+}
+
+// 按类型拆分费用合计
+const feeTotalsSelect = `
+	COALESCE(SUM(amount) FILTER (WHERE type = 'management'), 0) AS management,
+	COALESCE(SUM(amount) FILTER (WHERE type = 'performance'), 0) AS performance,
+	COALESCE(SUM(amount), 0) AS total`
+
+type FeeRepository struct {
+	db *gorm.DB
+}
+
+func NewFeeRepository() *FeeRepository {
+	return &FeeRepository{
+		db: database.GetDB(),
+	}
+}
+
+func (r *FeeRepository) inRange(from, to time.Time) *gorm.DB {
+	return r.db.Model(&models.FeeAccrual{}).Where("period_end >= ? AND period_end < ?", from, to)
+}
+
+// Totals 统计 [from, to) 内计提的费用合计，以计提周期结束时间归属
+func (r *FeeRepository) Totals(from, to time.Time) (FeeTotals, error) {
+	var totals FeeTotals
+	result := r.inRange(from, to).Select(feeTotalsSelect).Scan(&totals)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to sum fee accruals: %v", result.Error))
+		return FeeTotals{}, result.Error
+	}
+	return totals, nil
+}
+
+// ByVault 按资金库统计 [from, to) 内的费用，收入高的在前
+func (r *FeeRepository) ByVault(from, to time.Time) ([]VaultRevenue, error) {
+	var rows []VaultRevenue
+	result := r.inRange(from, to).
+		Select("vault_address," + feeTotalsSelect).
+		Group("vault_address").Order("total DESC").Scan(&rows)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to sum fee accruals by vault: %v", result.Error))
+		return nil, result.Error
+	}
+	return rows, nil
+}
+
+// ByPeriod 按 day、week 或 month 统计 [from, to) 内的费用，按时间正序
+func (r *FeeRepository) ByPeriod(from, to time.Time, period string) ([]PeriodRevenue, error) {
+	if database.IsSQLite() {
+		return r.byPeriodSQLite(from, to, period)
+	}
+	var rows []PeriodRevenue
+	result := r.inRange(from, to).
+		Select("date_trunc(?, period_end) AS period,"+feeTotalsSelect, period).
+		Group("period").Order("period ASC").Scan(&rows)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to sum fee accruals by %s: %v", period, result.Error))
+		return nil, result.Error
+	}
+	return rows, nil
+}
+
+// sqlitePeriodStart SQLite没有date_trunc，按周期取起始时间(周从周一开始，与Postgres一致)
+var sqlitePeriodStart = map[string]string{
+	"day":   "DATETIME(period_end, 'start of day')",
+	"week":  "DATETIME(period_end, 'start of day', '-' || ((CAST(STRFTIME('%w', period_end) AS INTEGER) + 6) % 7) || ' days')",
+	"month": "DATETIME(period_end, 'start of month')",
+}
+
+// byPeriodSQLite ByPeriod的SQLite写法，DATETIME返回文本，扫描后再解析为时间
+func (r *FeeRepository) byPeriodSQLite(from, to time.Time, period string) ([]PeriodRevenue, error) {
+	var rows []struct {
+		Period string
+		FeeTotals
+	}
+	result := r.inRange(from, to).
+		Select(sqlitePeriodStart[period] + " AS period," + feeTotalsSelect).
+		Group("period").Order("period ASC").Scan(&rows)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to sum fee accruals by %s: %v", period, result.Error))
+		return nil, result.Error
+	}
+
+	revenue := make([]PeriodRevenue, 0, len(rows))
+	for _, row := range rows {
+		start, err := time.Parse(time.DateTime, row.Period)
+		if err != nil {
+			return nil, fmt.Errorf("parse period %q: %w", row.Period, err)
+		}
+		revenue = append(revenue, PeriodRevenue{Period: start, FeeTotals: row.FeeTotals})
+	}
+	return revenue, nil
+}
+
+// ByHarvest 按收获汇总计提的费用，没有计提费用的收获不在结果中
+func (r *FeeRepository) ByHarvest(harvestIDs []uint) (map[uint]FeeTotals, error) {
+	totals := make(map[uint]FeeTotals, len(harvestIDs))
+	if len(harvestIDs) == 0 {
+		return totals, nil
+	}
+
+	var rows []struct {
+		HarvestID uint
+		FeeTotals
+	}
+	result := r.db.Model(&models.FeeAccrual{}).Where("harvest_id IN ?", harvestIDs).
+		Select("harvest_id," + feeTotalsSelect).Group("harvest_id").Scan(&rows)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to sum fee accruals by harvest: %v", result.Error))
+		return nil, result.Error
+	}
+	for _, row := range rows {
+		totals[row.HarvestID] = row.FeeTotals
+	}
+	return totals, nil
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ForecastRepository struct {
+	db *gorm.DB
+}
+
+func NewForecastRepository() *ForecastRepository {
+	return &ForecastRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Save 写入资金库最新的预测，覆盖上一次结果
+func (r *ForecastRepository) Save(forecast *models.APYForecast) error {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "vault_address"}},
+		DoUpdates: clause.AssignmentColumns([]string{"method", "alpha", "confidence", "samples", "sigma", "points", "generated_at"}),
+	}).Create(forecast)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to save APY forecast for %s: %v", forecast.VaultAddress, result.Error))
+		return result.Error
+	}
+	return nil
+}
+
+// Delete 删除资金库的预测，历史数据不足以重新预测时调用，避免展示过期结果
+func (r *ForecastRepository) Delete(vaultAddress string) error {
+	result := r.db.Where("vault_address = ?", vaultAddress).Delete(&models.APYForecast{})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to delete APY forecast for %s: %v", vaultAddress, result.Error))
+		return result.Error
+	}
+	return nil
+}
+
+// GetByVault 获取资金库的预测，没有时返回nil
+func (r *ForecastRepository) GetByVault(vaultAddress string) (*models.APYForecast, error) {
+	var forecasts []models.APYForecast
+	result := r.db.Where("vault_address = ?", vaultAddress).Limit(1).Find(&forecasts)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get APY forecast for %s: %v", vaultAddress, result.Error))
+		return nil, result.Error
+	}
+	if len(forecasts) == 0 {
+		return nil, nil
+	}
+	return &forecasts[0], nil
+}
+
+// ListAll 获取全部资金库的预测
+func (r *ForecastRepository) ListAll() ([]models.APYForecast, error) {
+	var forecasts []models.APYForecast
+	if err := r.db.Find(&forecasts).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to list APY forecasts: %v", err))
+		return nil, err
+	}
+	return forecasts, nil
+}
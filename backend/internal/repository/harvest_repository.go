@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StrategyYield 策略在一段时间内的收获汇总，费用为这些收获计提的费用。
+// GasCostUSD 只包含已补齐gas且有原生代币价格的收获，GasUnpriced 为其余收获的数量
+type StrategyYield struct {
+	StrategyAddress string          `json:"strategy_address"`
+	Harvests        int             `json:"harvests"`
+	Gross           decimal.Decimal `json:"gross"`
+	ManagementFee   decimal.Decimal `json:"management_fee"`
+	PerformanceFee  decimal.Decimal `json:"performance_fee"`
+	GasCostUSD      decimal.Decimal `gorm:"column:gas_cost_usd" json:"gas_cost_usd"`
+	GasUnpriced     int             `json:"gas_unpriced"`
+}
+
+// 按收获合计费用后再按策略汇总，避免一次收获的多条费用把收获金额重复累加
+const strategyYieldQuery = `
+SELECT h.strategy_address,
+       COUNT(*) AS harvests,
+       SUM(h.amount) AS gross,
+       COALESCE(SUM(f.management), 0) AS management_fee,
+       COALESCE(SUM(f.performance), 0) AS performance_fee,
+       COALESCE(SUM(h.gas_cost_usd), 0) AS gas_cost_usd,
+       COUNT(*) - COUNT(h.gas_cost_usd) AS gas_unpriced
+FROM harvests h
+LEFT JOIN (
+    SELECT harvest_id,
+           SUM(amount) FILTER (WHERE type = 'management') AS management,
+           SUM(amount) FILTER (WHERE type = 'performance') AS performance
+    FROM fee_accruals
+    GROUP BY harvest_id
+) f ON f.harvest_id = h.id
+WHERE h.vault_address = ? AND h.harvested_at >= ? AND h.harvested_at < ?
+GROUP BY h.strategy_address
+ORDER BY gross DESC`
+
+type HarvestRepository struct {
+	db *gorm.DB
+}
+
+func NewHarvestRepository() *HarvestRepository {
+	return &HarvestRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Record 写入收获记录、本次计提的费用及对应凭证，并按账本重算策略累计收益，同一事件重复写入时返回false
+func (r *HarvestRepository) Record(harvest *models.Harvest, fees []models.FeeAccrual) (bool, error) {
+	applied := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(harvest)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		applied = true
+
+		if len(fees) > 0 {
+			for i := range fees {
+				fees[i].HarvestID = harvest.ID
+			}
+			if err := tx.Create(&fees).Error; err != nil {
+				return fmt.Errorf("fee accruals: %w", err)
+			}
+		}
+
+		posted, err := postJournal(tx, harvestJournal(harvest))
+		if err != nil {
+			return err
+		}
+		for i := range fees {
+			if _, err := postJournal(tx, feeJournal(&fees[i])); err != nil {
+				return err
+			}
+		}
+
+		if harvest.StrategyAddress == "" {
+			return nil
+		}
+		if posted {
+			if err := deriveTotal(tx, &models.Strategy{}, harvest.StrategyAddress, "total_earnings", derivedEarnings); err != nil {
+				return err
+			}
+		}
+		// SQLite没有GREATEST，多参数的MAX即取较大值
+		greatest := "GREATEST"
+		if database.IsSQLite() {
+			greatest = "MAX"
+		}
+		return tx.Model(&models.Strategy{}).Where("address = ?", harvest.StrategyAddress).
+			Update("last_harvest", gorm.Expr(greatest+"(COALESCE(last_harvest, ?), ?)", harvest.HarvestedAt, harvest.HarvestedAt)).Error
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to record harvest %s#%d: %v", harvest.TxHash, harvest.LogIndex, err))
+		return false, err
+	}
+	return applied, nil
+}
+
+// PreviousHarvestAt 返回资金库在before之前最近一次收获的时间，没有时返回nil
+func (r *HarvestRepository) PreviousHarvestAt(vaultAddress string, before time.Time) (*time.Time, error) {
+	var harvest models.Harvest
+	result := r.db.Where("vault_address = ? AND harvested_at < ?", vaultAddress, before).
+		Order("harvested_at DESC").First(&harvest)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		logger.Error(fmt.Sprintf("Failed to get previous harvest of %s: %v", vaultAddress, result.Error))
+		return nil, result.Error
+	}
+	return &harvest.HarvestedAt, nil
+}
+
+// List 按时间倒序分页获取收获记录，vaultAddress、strategyAddress 为空时不按其筛选
+func (r *HarvestRepository) List(vaultAddress, strategyAddress string, cursor *Cursor, limit int) ([]models.Harvest, *Page, error) {
+	query := r.db.Model(&models.Harvest{})
+	if vaultAddress != "" {
+		query = query.Where("vault_address = ?", vaultAddress)
+	}
+	if strategyAddress != "" {
+		query = query.Where("strategy_address = ?", strategyAddress)
+	}
+
+	harvests, page, err := paginate(query, "harvested_at", cursor, limit, func(h models.Harvest) Cursor {
+		return Cursor{Time: h.HarvestedAt, ID: h.ID}
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to list harvests of vault %q strategy %q: %v", vaultAddress, strategyAddress, err))
+		return nil, nil, err
+	}
+	return harvests, page, nil
+}
+
+// YieldByStrategy 按策略汇总资金库在 [from, to) 内的收获，收益高的在前
+func (r *HarvestRepository) YieldByStrategy(vaultAddress string, from, to time.Time) ([]StrategyYield, error) {
+	var rows []StrategyYield
+	result := r.db.Raw(strategyYieldQuery, vaultAddress, from, to).Scan(&rows)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to sum harvests of %s by strategy: %v", vaultAddress, result.Error))
+		return nil, result.Error
+	}
+	return rows, nil
+}
+
+// PendingGas 返回尚未补齐gas成本、取回执失败不到 maxAttempts 次的收获，失败次数少的优先，其次按id正序
+func (r *HarvestRepository) PendingGas(maxAttempts, limit int) ([]models.Harvest, error) {
+	var harvests []models.Harvest
+	result := r.db.Where("gas_used IS NULL AND gas_attempts < ?", maxAttempts).
+		Order("gas_attempts ASC, id ASC").Limit(limit).Find(&harvests)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get harvests pending gas: %v", result.Error))
+		return nil, result.Error
+	}
+	return harvests, nil
+}
+
+// RecordGasAttempt 记录一次取不到收获交易回执，返回记录后的失败次数
+func (r *HarvestRepository) RecordGasAttempt(chainID uint, txHash string) (int, error) {
+	query := r.db.Model(&models.Harvest{}).Where("chain_id = ? AND tx_hash = ? AND gas_used IS NULL", chainID, txHash)
+	if err := query.Update("gas_attempts", gorm.Expr("gas_attempts + 1")).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to record gas attempt of harvest tx %s: %v", txHash, err))
+		return 0, err
+	}
+	var attempts int
+	err := r.db.Model(&models.Harvest{}).Where("chain_id = ? AND tx_hash = ?", chainID, txHash).
+		Select("COALESCE(MAX(gas_attempts), 0)").Scan(&attempts).Error
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get gas attempts of harvest tx %s: %v", txHash, err))
+		return 0, err
+	}
+	return attempts, nil
+}
+
+// SetGas 写入收获交易的gas成本，同一交易中的多次收获平均分摊。costUSD为nil表示缺少原生代币价格
+func (r *HarvestRepository) SetGas(chainID uint, txHash string, gasUsed uint64, cost decimal.Decimal, costUSD *decimal.Decimal) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&models.Harvest{}).Where("chain_id = ? AND tx_hash = ?", chainID, txHash)
+		var count int64
+		if err := query.Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return nil
+		}
+
+		n := decimal.NewFromInt(count)
+		updates := map[string]interface{}{
+			"gas_used": gasUsed / uint64(count),
+			"gas_cost": cost.Div(n),
+		}
+		if costUSD != nil {
+			updates["gas_cost_usd"] = costUSD.Div(n)
+		}
+		return tx.Model(&models.Harvest{}).Where("chain_id = ? AND tx_hash = ?", chainID, txHash).Updates(updates).Error
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to set gas of harvest tx %s: %v", txHash, err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// 事件列表按状态过滤
+const (
+	IncidentFilterOpen     = "open"
+	IncidentFilterResolved = "resolved"
+)
+
+type IncidentRepository struct {
+	db *gorm.DB
+}
+
+func NewIncidentRepository() *IncidentRepository {
+	return &IncidentRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 创建事件及其第一条进展
+func (r *IncidentRepository) Create(incident *models.Incident, update *models.IncidentUpdate) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Updates", "Alerts").Create(incident).Error; err != nil {
+			return err
+		}
+		update.IncidentID = incident.ID
+		return tx.Create(update).Error
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create incident for %s: %v", incident.Component, err))
+		return err
+	}
+	incident.Updates = []models.IncidentUpdate{*update}
+	return nil
+}
+
+// Get 获取事件及其全部进展和告警，不存在时返回nil
+func (r *IncidentRepository) Get(id uint) (*models.Incident, error) {
+	var incident models.Incident
+	result := r.db.
+		Preload("Updates", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Alerts", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		First(&incident, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		logger.Error(fmt.Sprintf("Failed to get incident %d: %v", id, result.Error))
+		return nil, result.Error
+	}
+	return &incident, nil
+}
+
+// List 按开始时间倒序列出事件，不含进展和告警。status 为 open/resolved 或空，component 为空时不过滤
+func (r *IncidentRepository) List(status, component string, limit int) ([]models.Incident, error) {
+	var incidents []models.Incident
+	query := r.db.Order("started_at DESC, id DESC").Limit(limit)
+	switch status {
+	case IncidentFilterOpen:
+		query = query.Where("resolved_at IS NULL")
+	case IncidentFilterResolved:
+		query = query.Where("resolved_at IS NOT NULL")
+	}
+	if component != "" {
+		query = query.Where("component = ?", component)
+	}
+	if err := query.Find(&incidents).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to list incidents: %v", err))
+		return nil, err
+	}
+	return incidents, nil
+}
+
+// LatestAutomatic 获取组件最近一次自动创建的事件(无论是否结束)，没有时返回nil
+func (r *IncidentRepository) LatestAutomatic(component string) (*models.Incident, error) {
+	var incidents []models.Incident
+	result := r.db.Where("component = ? AND automatic = ?", component, true).
+		Order("id DESC").Limit(1).Find(&incidents)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to find latest incident for %s: %v", component, result.Error))
+		return nil, result.Error
+	}
+	if len(incidents) == 0 {
+		return nil, nil
+	}
+	return &incidents[0], nil
+}
+
+// Update 修改事件字段
+func (r *IncidentRepository) Update(id uint, fields map[string]interface{}) error {
+	if err := r.db.Model(&models.Incident{}).Where("id = ?", id).Updates(fields).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to update incident %d: %v", id, err))
+		return err
+	}
+	return nil
+}
+
+// AddUpdate 添加一条进展，并在同一事务中修改事件字段(状态、结束时间等)
+func (r *IncidentRepository) AddUpdate(update *models.IncidentUpdate, fields map[string]interface{}) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(update).Error; err != nil {
+			return err
+		}
+		if len(fields) == 0 {
+			return nil
+		}
+		return tx.Model(&models.Incident{}).Where("id = ?", update.IncidentID).Updates(fields).Error
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to add update to incident %d: %v", update.IncidentID, err))
+		return err
+	}
+	return nil
+}
+
+// Delete 删除事件及其进展，关联的告警保留但不再指向该事件。返回被删除的事件，不存在时返回nil
+func (r *IncidentRepository) Delete(id uint) (*models.Incident, error) {
+	var deleted *models.Incident
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var incident models.Incident
+		if err := tx.First(&incident, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			}
+			return err
+		}
+		if err := tx.Where("incident_id = ?", id).Delete(&models.IncidentUpdate{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.OperatorAlert{}).Where("incident_id = ?", id).Update("incident_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&incident).Error; err != nil {
+			return err
+		}
+		deleted = &incident
+		return nil
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to delete incident %d: %v", id, err))
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// CreateAlert 记录一条发送给运维的告警
+func (r *IncidentRepository) CreateAlert(alert *models.OperatorAlert) error {
+	if err := r.db.Create(alert).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to record operator alert %q: %v", alert.Subject, err))
+		return err
+	}
+	return nil
+}
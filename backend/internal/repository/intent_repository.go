@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNonceClaimed = errors.New("intent nonce was already used")                    // 签名的nonce在保存时已被其他意向使用
+	ErrQuoteClaimed = errors.New("quote has expired or was already used")            // 意向使用的报价在保存时已过期或已被其他意向使用
+	ErrLogClaimed   = errors.New("transaction event already settled another intent") // 上报的交易事件已结算了其他意向
+)
+
+// IntentClaim 保存意向时在同一事务中消耗的一次性凭证：用户签名的nonce和存款使用的报价(QuoteID 为空表示未使用报价)。
+// 意向保存前的任何检查失败都不会消耗它们，同一签名请求也不能保存两次
+type IntentClaim struct {
+	UserAddress string
+	Nonce       uint64
+	QuoteID     string
+}
+
+type IntentRepository struct {
+	db *gorm.DB
+}
+
+func NewIntentRepository() *IntentRepository {
+	return &IntentRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 保存存取款意向并消耗 claim，nonce已被使用时返回 ErrNonceClaimed，报价已过期或已被使用时返回 ErrQuoteClaimed，
+// 两种情况都不保存意向
+func (r *IntentRepository) Create(intent *models.Intent, claim IntentClaim) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := r.claim(tx, claim); err != nil {
+			return err
+		}
+		return tx.Create(intent).Error
+	})
+	if err != nil && !errors.Is(err, ErrNonceClaimed) && !errors.Is(err, ErrQuoteClaimed) {
+		logger.Error(fmt.Sprintf("Failed to create %s intent for %s: %v", intent.Kind, intent.UserAddress, err))
+	}
+	return err
+}
+
+// CreateBatch 在一个事务中保存批量存款的各笔意向并消耗 claim，全部成功或全部失败
+func (r *IntentRepository) CreateBatch(intents []models.Intent, claim IntentClaim) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := r.claim(tx, claim); err != nil {
+			return err
+		}
+		return tx.Create(&intents).Error
+	})
+	if err != nil && !errors.Is(err, ErrNonceClaimed) {
+		logger.Error(fmt.Sprintf("Failed to create intent batch %s: %v", intents[0].BatchID, err))
+	}
+	return err
+}
+
+func (r *IntentRepository) claim(tx *gorm.DB, claim IntentClaim) error {
+	consumed, err := consumeNonce(tx, claim.UserAddress, claim.Nonce)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return ErrNonceClaimed
+	}
+	if claim.QuoteID == "" {
+		return nil
+	}
+	marked, err := markQuoteUsed(tx, claim.QuoteID, time.Now())
+	if err != nil {
+		return err
+	}
+	if !marked {
+		return ErrQuoteClaimed
+	}
+	return nil
+}
+
+// ListBatch 按创建顺序获取用户某个批量存款的意向，不存在或不属于该用户时返回空列表
+func (r *IntentRepository) ListBatch(userAddress, batchID string) ([]models.Intent, error) {
+	var intents []models.Intent
+	if err := r.db.Where("batch_id = ? AND user_address = ?", batchID, userAddress).Order("id").Find(&intents).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to list intent batch %s: %v", batchID, err))
+		return nil, err
+	}
+	return intents, nil
+}
+
+// Get 获取用户的意向，不存在或不属于该用户时返回nil
+func (r *IntentRepository) Get(userAddress string, id uint) (*models.Intent, error) {
+	var intents []models.Intent
+	result := r.db.Where("id = ? AND user_address = ?", id, userAddress).Limit(1).Find(&intents)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get intent %d: %v", id, result.Error))
+		return nil, result.Error
+	}
+	if len(intents) == 0 {
+		return nil, nil
+	}
+	return &intents[0], nil
+}
+
+// ClaimedLogs 同一链上该交易中已结算其他意向的事件序号。交易已结算过未记录事件序号的旧意向时返回 ErrLogClaimed
+func (r *IntentRepository) ClaimedLogs(chainID uint, txHash string, excludeID uint) (map[uint]bool, error) {
+	var settled []models.Intent
+	result := r.db.Select("id", "log_index").
+		Where("chain_id = ? AND tx_hash = ? AND id <> ?", chainID, txHash, excludeID).Find(&settled)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get intents settled by %s: %v", txHash, result.Error))
+		return nil, result.Error
+	}
+
+	claimed := make(map[uint]bool, len(settled))
+	for _, intent := range settled {
+		if intent.LogIndex == nil {
+			return nil, ErrLogClaimed
+		}
+		claimed[*intent.LogIndex] = true
+	}
+	return claimed, nil
+}
+
+// Finish 记录上报的执行结果，只有 pending 状态的意向可以结束。返回false表示意向已结束；
+// 该事件已结算其他意向时返回 ErrLogClaimed，并发上报同一事件时由唯一索引兜底
+func (r *IntentRepository) Finish(intent *models.Intent, status, txHash string, logIndex uint, outWei, reason string, executedAt time.Time) (bool, error) {
+	var finished bool
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var claimed int64
+		err := tx.Model(&models.Intent{}).
+			Where("chain_id = ? AND tx_hash = ? AND (log_index = ? OR log_index IS NULL) AND id <> ?", intent.ChainID, txHash, logIndex, intent.ID).
+			Count(&claimed).Error
+		if err != nil {
+			return err
+		}
+		if claimed > 0 {
+			return ErrLogClaimed
+		}
+
+		result := tx.Model(&models.Intent{}).
+			Where("id = ? AND status = ?", intent.ID, models.IntentPending).
+			Updates(map[string]interface{}{
+				"status":        status,
+				"tx_hash":       txHash,
+				"log_index":     logIndex,
+				"out_wei":       outWei,
+				"reject_reason": reason,
+				"executed_at":   executedAt,
+			})
+		finished = result.RowsAffected > 0
+		return result.Error
+	})
+	if err != nil && !errors.Is(err, ErrLogClaimed) {
+		logger.Error(fmt.Sprintf("Failed to finish intent %d: %v", intent.ID, err))
+	}
+	return finished, err
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// VaultRepo 资金库数据访问，服务层依赖该接口以便替换存储实现或在测试中注入替身
+type VaultRepo interface {
+	WithContext(ctx context.Context) VaultRepo
+	Create(vault *models.Vault) error
+	GetByAddress(address string) (*models.Vault, error)
+	ListAll() ([]models.Vault, error)
+	GetActiveVaults() ([]models.Vault, error)
+	UpdateTVL(address string, tvl decimal.Decimal) error
+	BulkUpdateTVL(tvls map[string]decimal.Decimal) error
+	UpdateAPY(address string, apyCurrent, apyWeekly float64) error
+	SetFees(address string, managementBps, performanceBps uint16) (bool, error)
+	SetDepositCap(address string, cap *decimal.Decimal) (bool, error)
+	SetAllowlistEnabled(address string, enabled bool) (bool, error)
+	SetMode(address, mode string) (bool, error)
+	SetCategory(address, category string, tags []string) (bool, error)
+}
+
+// StrategyRepo 策略数据访问
+type StrategyRepo interface {
+	WithContext(ctx context.Context) StrategyRepo
+	Create(strategy *models.Strategy) error
+	GetByAddress(address string) (*models.Strategy, error)
+	GetByVault(vaultAddress string) ([]models.Strategy, error)
+	ListActive() ([]models.Strategy, error)
+	UpdateAPY(address string, apy float64) error
+	UpdateAssets(address string, totalAssets decimal.Decimal) error
+	BulkUpdateAssets(assets map[string]decimal.Decimal) error
+	UpdateTargetAllocations(vaultAddress string, targets map[string]uint16) error
+	SetActive(vaultAddress, address string, active bool) error
+}
+
+// UserRepo 用户数据访问
+type UserRepo interface {
+	WithContext(ctx context.Context) UserRepo
+	Create(user *models.User) error
+	GetByAddress(address string) (*models.User, error)
+	GetOrCreate(address string) (*models.User, error)
+	GetByNickname(nickname string) (*models.User, error)
+	ListAll() ([]models.User, error)
+	Count() (int64, error)
+	UpdateTVL(address string, tvl decimal.Decimal) error
+	UpdateProfile(address, nickname, avatarURL, email string, signedAt time.Time) error
+}
+
+// TxRepo 交易记录数据访问
+type TxRepo interface {
+	WithContext(ctx context.Context) TxRepo
+	Create(transaction *models.Transaction) error
+	GetByTxHash(txHash string) (*models.Transaction, error)
+	GetUserTransactions(userAddress string, cursor *Cursor, limit int) ([]models.Transaction, *Page, error)
+	ListByUser(userAddress string) ([]models.Transaction, error)
+	GetVaultTransactions(vaultAddress string, cursor *Cursor, limit int) ([]models.Transaction, *Page, error)
+	UpdateStatus(txHash string, status string) error
+	GetPendingBridges(limit int) ([]models.Transaction, error)
+	CompleteBridge(id uint, status, destinationTxHash string) error
+	GetUserPositionTotals(userAddress string) ([]PositionTotal, error)
+	GetVaultShareBalances(vaultAddress string) ([]UserShares, error)
+	GetUserVaultHistory(userAddress, vaultAddress string) ([]models.Transaction, error)
+	ConfirmTransfer(transaction *models.Transaction) (bool, error)
+	GetNetOutflow(vaultAddress string, since time.Time) (decimal.Decimal, error)
+	GetFlows(from, to time.Time) ([]VaultFlow, error)
+}
+
+var (
+	_ VaultRepo    = (*VaultRepository)(nil)
+	_ StrategyRepo = (*StrategyRepository)(nil)
+	_ UserRepo     = (*UserRepository)(nil)
+	_ TxRepo       = (*TransactionRepository)(nil)
+)
+
+// Repositories 服务层依赖的核心数据访问接口，由main按数据库连接构建后逐层注入
+type Repositories struct {
+	Vaults       VaultRepo
+	Strategies   StrategyRepo
+	Users        UserRepo
+	Transactions TxRepo
+}
+
+// NewRepositories 基于指定连接构建全部核心仓储
+func NewRepositories(db *gorm.DB) *Repositories {
+	return &Repositories{
+		Vaults:       NewVaultRepositoryWithDB(db),
+		Strategies:   NewStrategyRepositoryWithDB(db),
+		Users:        NewUserRepositoryWithDB(db),
+		Transactions: NewTransactionRepositoryWithDB(db),
+	}
+}
+
+// Default 基于全局数据库连接构建核心仓储，供未显式注入依赖的构造函数使用
+func Default() *Repositories {
+	return NewRepositories(database.GetDB())
+}
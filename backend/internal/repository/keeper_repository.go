@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type KeeperRepository struct {
+	db *gorm.DB
+}
+
+func NewKeeperRepository() *KeeperRepository {
+	return &KeeperRepository{
+		db: database.GetDB(),
+	}
+}
+
+// CreateKeeper 登记keeper
+func (r *KeeperRepository) CreateKeeper(keeper *models.Keeper) error {
+	if err := r.db.Create(keeper).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to create keeper %s: %v", keeper.Address, err))
+		return err
+	}
+	return nil
+}
+
+// GetKeeperByAddress 根据地址获取keeper，包括已停用的
+func (r *KeeperRepository) GetKeeperByAddress(address string) (*models.Keeper, error) {
+	var keeper models.Keeper
+	result := r.db.Where("address = ?", address).First(&keeper)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		logger.Error(fmt.Sprintf("Failed to get keeper %s: %v", address, result.Error))
+		return nil, result.Error
+	}
+	return &keeper, nil
+}
+
+// GetActiveKeeperByKeyHash 根据API Key哈希获取启用中的keeper
+func (r *KeeperRepository) GetActiveKeeperByKeyHash(hash string) (*models.Keeper, error) {
+	var keeper models.Keeper
+	result := r.db.Where("api_key_hash = ? AND active = ?", hash, true).First(&keeper)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		logger.Error(fmt.Sprintf("Failed to get keeper by key: %v", result.Error))
+		return nil, result.Error
+	}
+	return &keeper, nil
+}
+
+// ListKeepers 列出全部keeper，按登记时间排序
+func (r *KeeperRepository) ListKeepers() ([]models.Keeper, error) {
+	var keepers []models.Keeper
+	if err := r.db.Order("created_at ASC, id ASC").Find(&keepers).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to list keepers: %v", err))
+		return nil, err
+	}
+	return keepers, nil
+}
+
+// DeactivateKeeper 停用keeper，返回false表示keeper不存在或已停用。
+// 其持有的租约不会被收回，到期后任务自然可被重新领取
+func (r *KeeperRepository) DeactivateKeeper(address string) (bool, error) {
+	result := r.db.Model(&models.Keeper{}).
+		Where("address = ? AND active = ?", address, true).
+		Update("active", false)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to deactivate keeper %s: %v", address, result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// TouchKeeper 记录keeper最近一次访问时间
+func (r *KeeperRepository) TouchKeeper(id uint, at time.Time) error {
+	result := r.db.Model(&models.Keeper{}).Where("id = ?", id).UpdateColumn("last_seen_at", at)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to touch keeper %d: %v", id, result.Error))
+		return result.Error
+	}
+	return nil
+}
+
+// CountWaiting 统计在before之前创建、仍未完成(待领取或已领取)的任务
+func (r *KeeperRepository) CountWaiting(before time.Time) (int64, error) {
+	var count int64
+	result := r.db.Model(&models.KeeperJob{}).
+		Where("status IN ? AND created_at < ?", []string{models.KeeperJobPending, models.KeeperJobClaimed}, before).
+		Count(&count)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to count waiting keeper jobs: %v", result.Error))
+		return 0, result.Error
+	}
+	return count, nil
+}
+
+// CreateJobs 批量创建任务，同一目标已有未完成任务时跳过，返回新建的数量
+func (r *KeeperRepository) CreateJobs(jobs []models.KeeperJob) (int64, error) {
+	if len(jobs) == 0 {
+		return 0, nil
+	}
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(jobs, batchSize)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to create keeper jobs: %v", result.Error))
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// CancelStale 取消不再需要执行的待领取任务：同类型中目标不在 keep 里的任务。
+// 已被领取且租约有效的任务不受影响，由持有者上报结果
+func (r *KeeperRepository) CancelStale(jobType string, keep []string, now time.Time, reason string) (int64, error) {
+	query := r.db.Model(&models.KeeperJob{}).
+		Where("type = ?", jobType).
+		Where("status = ? OR (status = ? AND lease_expires_at < ?)", models.KeeperJobPending, models.KeeperJobClaimed, now)
+	if len(keep) > 0 {
+		query = query.Where("target NOT IN ?", keep)
+	}
+	result := query.Updates(map[string]interface{}{
+		"status":       models.KeeperJobCancelled,
+		"error":        reason,
+		"completed_at": now,
+	})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to cancel stale %s jobs: %v", jobType, result.Error))
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// FinishedTargets 在since之后成功或最终失败的任务目标，生成任务时跳过这些目标
+func (r *KeeperRepository) FinishedTargets(jobType string, since time.Time) (map[string]bool, error) {
+	var targets []string
+	result := r.db.Model(&models.KeeperJob{}).
+		Where("type = ? AND status IN ? AND completed_at >= ?", jobType, []string{models.KeeperJobSucceeded, models.KeeperJobFailed}, since).
+		Distinct("target").
+		Pluck("target", &targets)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get finished %s jobs: %v", jobType, result.Error))
+		return nil, result.Error
+	}
+	finished := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		finished[target] = true
+	}
+	return finished, nil
+}
+
+// GetJob 根据ID获取任务
+func (r *KeeperRepository) GetJob(id uint) (*models.KeeperJob, error) {
+	var job models.KeeperJob
+	result := r.db.First(&job, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		logger.Error(fmt.Sprintf("Failed to get keeper job %d: %v", id, result.Error))
+		return nil, result.Error
+	}
+	return &job, nil
+}
+
+// AvailableJobs 列出可领取的任务(待领取或租约已过期)，按创建时间排序。
+// types 为空时返回全部类型，chainID 为0时不按链过滤
+func (r *KeeperRepository) AvailableJobs(types []string, chainID uint, now time.Time, limit int) ([]models.KeeperJob, error) {
+	var jobs []models.KeeperJob
+	query := r.db.Where("status = ? OR (status = ? AND lease_expires_at < ?)", models.KeeperJobPending, models.KeeperJobClaimed, now)
+	if len(types) > 0 {
+		query = query.Where("type IN ?", types)
+	}
+	if chainID != 0 {
+		query = query.Where("chain_id = ?", chainID)
+	}
+	result := query.Order("created_at ASC, id ASC").Limit(limit).Find(&jobs)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to list available keeper jobs: %v", result.Error))
+		return nil, result.Error
+	}
+	return jobs, nil
+}
+
+// ListJobs 按状态列出任务，最新的在前，status为空时返回全部
+func (r *KeeperRepository) ListJobs(status string, limit int) ([]models.KeeperJob, error) {
+	var jobs []models.KeeperJob
+	query := r.db.Order("created_at DESC, id DESC").Limit(limit)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Find(&jobs).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to list keeper jobs: %v", err))
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// Claim 以租约方式领取任务，只有待领取或租约已过期的任务可以被领取。
+// 返回false表示任务已被其他keeper持有或已结束
+func (r *KeeperRepository) Claim(id, keeperID uint, now, leaseUntil time.Time) (bool, error) {
+	result := r.db.Model(&models.KeeperJob{}).
+		Where("id = ?", id).
+		Where("status = ? OR (status = ? AND lease_expires_at < ?)", models.KeeperJobPending, models.KeeperJobClaimed, now).
+		Updates(map[string]interface{}{
+			"status":           models.KeeperJobClaimed,
+			"keeper_id":        keeperID,
+			"lease_expires_at": leaseUntil,
+			"attempts":         gorm.Expr("attempts + 1"),
+		})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to claim keeper job %d: %v", id, result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Finish 由租约持有者结束任务。status 为 pending 时释放租约等待重试。
+// 返回false表示调用方不再持有有效租约
+func (r *KeeperRepository) Finish(id, keeperID uint, now time.Time, status, txHash, errMsg string) (bool, error) {
+	updates := map[string]interface{}{
+		"status":  status,
+		"tx_hash": txHash,
+		"error":   errMsg,
+	}
+	if status == models.KeeperJobPending {
+		updates["keeper_id"] = nil
+		updates["lease_expires_at"] = nil
+	} else {
+		updates["completed_at"] = now
+	}
+
+	result := r.db.Model(&models.KeeperJob{}).
+		Where("id = ? AND status = ? AND keeper_id = ? AND lease_expires_at >= ?", id, models.KeeperJobClaimed, keeperID, now).
+		Updates(updates)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to finish keeper job %d: %v", id, result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
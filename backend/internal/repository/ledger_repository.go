@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrUnbalancedJournal 凭证借贷不平，整笔业务写入随之回滚
+var ErrUnbalancedJournal = errors.New("ledger journal is not balanced")
+
+// AccountBalance 一个账户的借贷发生额，Net 为借方减贷方
+type AccountBalance struct {
+	AccountType  string          `json:"account_type"`
+	AccountOwner string          `json:"account_owner,omitempty"`
+	VaultAddress string          `json:"vault_address"`
+	Debits       decimal.Decimal `json:"debits"`
+	Credits      decimal.Decimal `json:"credits"`
+	Net          decimal.Decimal `json:"net"`
+}
+
+// transferJournal 存款借记资金库资产、贷记用户；取款相反。记账时间取链上事件时间
+func transferJournal(transaction *models.Transaction) *models.LedgerJournal {
+	at := transaction.CreatedAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+	vaultSide, userSide := models.LedgerDebit, models.LedgerCredit
+	if transaction.Type == models.JournalWithdraw {
+		vaultSide, userSide = models.LedgerCredit, models.LedgerDebit
+	}
+	// 同一交易可能有多个存取款事件，凭证按事件区分；迁移前的凭证引用只有交易哈希
+	reference := transaction.TxHash
+	if transaction.LogIndex != nil {
+		reference = fmt.Sprintf("%s#%d", transaction.TxHash, *transaction.LogIndex)
+	}
+	return newJournal(transaction.Type, reference, transaction.VaultAddress, at, transaction.Amount,
+		models.LedgerEntry{AccountType: models.AccountVault, Side: vaultSide},
+		models.LedgerEntry{AccountType: models.AccountUser, AccountOwner: transaction.UserAddress, Side: userSide})
+}
+
+// harvestJournal 收获借记资金库资产、贷记资金库收益
+func harvestJournal(harvest *models.Harvest) *models.LedgerJournal {
+	reference := fmt.Sprintf("%s#%d", harvest.TxHash, harvest.LogIndex)
+	journal := newJournal(models.JournalHarvest, reference, harvest.VaultAddress, harvest.HarvestedAt, harvest.Amount,
+		models.LedgerEntry{AccountType: models.AccountVault, Side: models.LedgerDebit},
+		models.LedgerEntry{AccountType: models.AccountYield, Side: models.LedgerCredit})
+	journal.StrategyAddress = harvest.StrategyAddress
+	return journal
+}
+
+// feeJournal 费用计提从资金库收益转入平台费用
+func feeJournal(fee *models.FeeAccrual) *models.LedgerJournal {
+	return newJournal(models.JournalFee, strconv.FormatUint(uint64(fee.ID), 10), fee.VaultAddress, fee.PeriodEnd, fee.Amount,
+		models.LedgerEntry{AccountType: models.AccountYield, Side: models.LedgerDebit},
+		models.LedgerEntry{AccountType: models.AccountTreasury, Side: models.LedgerCredit})
+}
+
+// newJournal 按同一金额生成凭证，分录的资金库和时间取自凭证
+func newJournal(kind, reference, vault string, at time.Time, amount decimal.Decimal, entries ...models.LedgerEntry) *models.LedgerJournal {
+	for i := range entries {
+		entries[i].VaultAddress = vault
+		entries[i].Amount = amount
+		entries[i].PostedAt = at
+	}
+	return &models.LedgerJournal{Kind: kind, Reference: reference, VaultAddress: vault, PostedAt: at, Entries: entries}
+}
+
+// postJournal 在调用方的事务中写入凭证。金额不为正时不记账；
+// 同一 (kind, reference) 已记过时跳过，重复回放事件不会重复记账。返回false表示本次没有记账
+func postJournal(tx *gorm.DB, journal *models.LedgerJournal) (bool, error) {
+	if len(journal.Entries) > 0 && !journal.Entries[0].Amount.IsPositive() {
+		return false, nil
+	}
+	if !journal.Balanced() {
+		return false, fmt.Errorf("%w: %s %s", ErrUnbalancedJournal, journal.Kind, journal.Reference)
+	}
+
+	entries := journal.Entries
+	journal.Entries = nil
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(journal)
+	journal.Entries = entries
+	if result.Error != nil {
+		return false, fmt.Errorf("ledger journal: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	for i := range entries {
+		entries[i].JournalID = journal.ID
+	}
+	if err := tx.Create(&entries).Error; err != nil {
+		return false, fmt.Errorf("ledger entries: %w", err)
+	}
+	return true, nil
+}
+
+// ledgerSum 某类凭证金额合计的SQL表达式，column 为凭证上的汇总维度，owner 为其取值(通常是外层表的列)。
+// 每张凭证恰有一条借方分录，按借方合计即为凭证金额合计
+func ledgerSum(kind, column, owner string) string {
+	return fmt.Sprintf("COALESCE((SELECT SUM(e.amount) FROM ledger_entries e JOIN ledger_journals j ON j.id = e.journal_id "+
+		"WHERE e.side = 'debit' AND j.kind = '%s' AND j.%s = %s), 0)", kind, column, owner)
+}
+
+// 由账本汇总得出的资金库存取款总额和策略累计收益
+var (
+	derivedDeposits    = ledgerSum(models.JournalDeposit, "vault_address", "vaults.address")
+	derivedWithdrawals = ledgerSum(models.JournalWithdraw, "vault_address", "vaults.address")
+	derivedEarnings    = ledgerSum(models.JournalHarvest, "strategy_address", "strategies.address")
+)
+
+// deriveTotal 记入凭证后按账本重算该资金库或策略的汇总字段，字段始终等于账本合计而不是逐笔累加的结果，
+// derived 为上面由账本汇总的表达式
+func deriveTotal(tx *gorm.DB, model interface{}, address, column, derived string) error {
+	return tx.Model(model).Where("address = ?", address).Update(column, gorm.Expr(derived)).Error
+}
+
+// LedgerTotal 某资金库(收获凭证还按策略)某类凭证的金额合计
+type LedgerTotal struct {
+	Kind            string          `json:"kind"`
+	VaultAddress    string          `json:"vault_address"`
+	StrategyAddress string          `json:"strategy_address,omitempty"`
+	Amount          decimal.Decimal `json:"amount"`
+}
+
+// StoredTotals 资金库和策略上保存的汇总字段及按账本重算的值
+type StoredTotals struct {
+	Target             string          `json:"target"` // vault 或 strategy
+	Address            string          `json:"address"`
+	VaultAddress       string          `json:"vault_address"`
+	TotalDeposits      decimal.Decimal `json:"total_deposits"`
+	TotalWithdrawals   decimal.Decimal `json:"total_withdrawals"`
+	TotalEarnings      decimal.Decimal `json:"total_earnings"`
+	DerivedDeposits    decimal.Decimal `json:"derived_deposits"`
+	DerivedWithdrawals decimal.Decimal `json:"derived_withdrawals"`
+	DerivedEarnings    decimal.Decimal `json:"derived_earnings"`
+}
+
+type LedgerRepository struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository() *LedgerRepository {
+	return &LedgerRepository{
+		db: database.GetDB(),
+	}
+}
+
+// ListJournals 按记账时间倒序分页获取凭证及分录，vault、kind 为空时不过滤
+func (r *LedgerRepository) ListJournals(vaultAddress, kind string, cursor *Cursor, limit int) ([]models.LedgerJournal, *Page, error) {
+	query := r.db.Model(&models.LedgerJournal{})
+	if vaultAddress != "" {
+		query = query.Where("vault_address = ?", vaultAddress)
+	}
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	journals, page, err := paginate(query, "posted_at", cursor, limit, func(journal models.LedgerJournal) Cursor {
+		return Cursor{Time: journal.PostedAt, ID: journal.ID}
+	}, func(db *gorm.DB) *gorm.DB {
+		return db.Preload("Entries", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		})
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to list ledger journals: %v", err))
+		return nil, nil, err
+	}
+	return journals, page, nil
+}
+
+// Balances 汇总各账户的借贷发生额，vault 为空时汇总全部资金库
+func (r *LedgerRepository) Balances(vaultAddress string) ([]AccountBalance, error) {
+	var balances []AccountBalance
+	query := r.db.Model(&models.LedgerEntry{}).
+		Select("account_type, account_owner, vault_address, " +
+			"COALESCE(SUM(CASE WHEN side = 'debit' THEN amount ELSE 0 END), 0) AS debits, " +
+			"COALESCE(SUM(CASE WHEN side = 'credit' THEN amount ELSE 0 END), 0) AS credits")
+	if vaultAddress != "" {
+		query = query.Where("vault_address = ?", vaultAddress)
+	}
+	result := query.Group("account_type, account_owner, vault_address").
+		Order("vault_address ASC").Order("account_type ASC").Order("account_owner ASC").
+		Scan(&balances)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get ledger balances: %v", result.Error))
+		return nil, result.Error
+	}
+	for i := range balances {
+		balances[i].Net = balances[i].Debits.Sub(balances[i].Credits)
+	}
+	return balances, nil
+}
+
+// Totals 按凭证类型汇总各资金库截至 at(含)的金额，收获按策略拆分；vault 为空时汇总全部资金库
+func (r *LedgerRepository) Totals(vaultAddress string, at time.Time) ([]LedgerTotal, error) {
+	var totals []LedgerTotal
+	query := r.db.Table("ledger_journals j").
+		Select("j.kind, j.vault_address, j.strategy_address, SUM(e.amount) AS amount").
+		Joins("JOIN ledger_entries e ON e.journal_id = j.id AND e.side = ?", models.LedgerDebit).
+		Where("j.posted_at <= ?", at)
+	if vaultAddress != "" {
+		query = query.Where("j.vault_address = ?", vaultAddress)
+	}
+	result := query.Group("j.kind, j.vault_address, j.strategy_address").
+		Order("j.vault_address ASC").Order("j.kind ASC").Order("j.strategy_address ASC").
+		Scan(&totals)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get ledger totals: %v", result.Error))
+		return nil, result.Error
+	}
+	return totals, nil
+}
+
+// StoredTotals 读取全部资金库和策略上保存的汇总字段及按账本重算的值
+func (r *LedgerRepository) StoredTotals() ([]StoredTotals, error) {
+	var vaults, strategies []StoredTotals
+	if err := r.db.Model(&models.Vault{}).
+		Select("'vault' AS target, address, address AS vault_address, total_deposits, total_withdrawals, " +
+			derivedDeposits + " AS derived_deposits, " + derivedWithdrawals + " AS derived_withdrawals").
+		Order("address ASC").Scan(&vaults).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to get stored vault totals: %v", err))
+		return nil, err
+	}
+	if err := r.db.Model(&models.Strategy{}).
+		Select("'strategy' AS target, address, vault_address, total_earnings, " + derivedEarnings + " AS derived_earnings").
+		Order("address ASC").Scan(&strategies).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to get stored strategy totals: %v", err))
+		return nil, err
+	}
+	return append(vaults, strategies...), nil
+}
+
+// RecomputeTotals 按账本重写全部资金库的存取款总额和策略累计收益
+func (r *LedgerRepository) RecomputeTotals() error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Model(&models.Vault{}).Updates(map[string]interface{}{
+			"total_deposits":    gorm.Expr(derivedDeposits),
+			"total_withdrawals": gorm.Expr(derivedWithdrawals),
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Model(&models.Strategy{}).
+			Update("total_earnings", gorm.Expr(derivedEarnings)).Error
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to recompute ledger totals: %v", err))
+		return err
+	}
+	return nil
+}
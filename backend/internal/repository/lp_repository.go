@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+type LPRepository struct {
+	db *gorm.DB
+}
+
+func NewLPRepository() *LPRepository {
+	return &LPRepository{
+		db: database.GetDB(),
+	}
+}
+
+// SetPosition 在同一事务中设置策略的LP池类型和建仓基准，替换原有的代币列表
+func (r *LPRepository) SetPosition(strategyAddress, poolType string, entryAt time.Time, tokens []models.StrategyLPToken) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Strategy{}).Where("address = ?", strategyAddress).Updates(map[string]interface{}{
+			"lp_pool_type": poolType,
+			"lp_entry_at":  entryAt,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("strategy %s not found", strategyAddress)
+		}
+
+		if err := tx.Where("strategy_address = ?", strategyAddress).Delete(&models.StrategyLPToken{}).Error; err != nil {
+			return err
+		}
+		for i := range tokens {
+			tokens[i].StrategyAddress = strategyAddress
+		}
+		return tx.Create(&tokens).Error
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to set LP position of strategy %s: %v", strategyAddress, err))
+		return err
+	}
+	return nil
+}
+
+// Tokens 获取LP策略池中的代币，按加入顺序排列
+func (r *LPRepository) Tokens(strategyAddress string) ([]models.StrategyLPToken, error) {
+	var tokens []models.StrategyLPToken
+	result := r.db.Where("strategy_address = ?", strategyAddress).Order("id ASC").Find(&tokens)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get LP tokens of strategy %s: %v", strategyAddress, result.Error))
+		return nil, result.Error
+	}
+	return tokens, nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MetadataRepository struct {
+	db *gorm.DB
+}
+
+func NewMetadataRepository() *MetadataRepository {
+	return &MetadataRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Upsert 保存展示资料，已有记录时整体替换
+func (r *MetadataRepository) Upsert(metadata *models.DisplayMetadata) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "address"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"kind", "description", "logo_url", "links", "audits", "inception_date", "updated_by", "updated_at",
+		}),
+	}).Create(metadata).Error
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to save display metadata of %s: %v", metadata.Address, err))
+		return err
+	}
+	return nil
+}
+
+// Get 获取地址的展示资料，未设置时返回nil
+func (r *MetadataRepository) Get(address string) (*models.DisplayMetadata, error) {
+	var metadata []models.DisplayMetadata
+	result := r.db.Where("address = ?", address).Limit(1).Find(&metadata)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get display metadata of %s: %v", address, result.Error))
+		return nil, result.Error
+	}
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	return &metadata[0], nil
+}
+
+// ListByAddresses 批量获取展示资料，未设置的地址不返回
+func (r *MetadataRepository) ListByAddresses(addresses []string) ([]models.DisplayMetadata, error) {
+	var metadata []models.DisplayMetadata
+	if len(addresses) == 0 {
+		return metadata, nil
+	}
+	if result := r.db.Where("address IN ?", addresses).Find(&metadata); result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to list display metadata: %v", result.Error))
+		return nil, result.Error
+	}
+	return metadata, nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// morphoReadingColumns 每次同步覆盖的状态列
+var morphoReadingColumns = []string{
+	"supply_apy", "borrow_apy", "utilization", "total_supply_assets", "total_borrow_assets",
+	"oracle_price", "block_number", "synced_at", "updated_at",
+}
+
+type MorphoRepository struct {
+	db *gorm.DB
+}
+
+func NewMorphoRepository() *MorphoRepository {
+	return &MorphoRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Upsert 登记策略的市场，再次登记时整行替换
+func (r *MorphoRepository) Upsert(market *models.MorphoMarket) error {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "strategy_address"}},
+		DoUpdates: clause.AssignmentColumns(append([]string{
+			"morpho", "market_id", "loan_token", "collateral_token", "oracle", "irm", "lltv",
+			"loan_decimals", "collateral_decimals",
+		}, morphoReadingColumns...)),
+	}).Create(market)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to save Morpho market of strategy %s: %v", market.StrategyAddress, result.Error))
+		return result.Error
+	}
+	return nil
+}
+
+// UpdateReadings 写入一次同步读取的利率、利用率和预言机价格
+func (r *MorphoRepository) UpdateReadings(market *models.MorphoMarket) error {
+	result := r.db.Model(market).Select(morphoReadingColumns).Updates(market)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to update Morpho market of strategy %s: %v", market.StrategyAddress, result.Error))
+		return result.Error
+	}
+	return nil
+}
+
+// Get 获取策略登记的市场，未登记时返回nil
+func (r *MorphoRepository) Get(strategyAddress string) (*models.MorphoMarket, error) {
+	var markets []models.MorphoMarket
+	result := r.db.Where("strategy_address = ?", strategyAddress).Limit(1).Find(&markets)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get Morpho market of strategy %s: %v", strategyAddress, result.Error))
+		return nil, result.Error
+	}
+	if len(markets) == 0 {
+		return nil, nil
+	}
+	return &markets[0], nil
+}
+
+// List 获取全部登记的市场
+func (r *MorphoRepository) List() ([]models.MorphoMarket, error) {
+	var markets []models.MorphoMarket
+	if result := r.db.Order("strategy_address ASC").Find(&markets); result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to list Morpho markets: %v", result.Error))
+		return nil, result.Error
+	}
+	return markets, nil
+}
+
+// ListByStrategies 获取指定策略登记的市场，未登记的策略不返回
+func (r *MorphoRepository) ListByStrategies(strategyAddresses []string) ([]models.MorphoMarket, error) {
+	var markets []models.MorphoMarket
+	if len(strategyAddresses) == 0 {
+		return markets, nil
+	}
+	if result := r.db.Where("strategy_address IN ?", strategyAddresses).Find(&markets); result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to list Morpho markets of %d strategies: %v", len(strategyAddresses), result.Error))
+		return nil, result.Error
+	}
+	return markets, nil
+}
@@ -0,0 +1,326 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository() *NotificationRepository {
+	return &NotificationRepository{
+		db: database.GetDB(),
+	}
+}
+
+// UpsertChannel 创建或替换用户某类型的渠道，替换后需要重新验证
+func (r *NotificationRepository) UpsertChannel(channel *models.NotificationChannel) error {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_address"}, {Name: "type"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"target", "verified", "verification_code_hash", "verification_expires_at", "verification_attempts", "verified_at", "secret",
+		}),
+	}).Create(channel)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to save notification channel: %v", result.Error))
+		return result.Error
+	}
+	return nil
+}
+
+// GetChannel 获取用户某类型的渠道
+func (r *NotificationRepository) GetChannel(userAddress, channelType string) (*models.NotificationChannel, error) {
+	var channel models.NotificationChannel
+	result := r.db.Where("user_address = ? AND type = ?", userAddress, channelType).First(&channel)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		logger.Error(fmt.Sprintf("Failed to get notification channel: %v", result.Error))
+		return nil, result.Error
+	}
+	return &channel, nil
+}
+
+// GetChannelByID 按ID获取渠道，不存在时返回nil
+func (r *NotificationRepository) GetChannelByID(id uint) (*models.NotificationChannel, error) {
+	var channels []models.NotificationChannel
+	result := r.db.Where("id = ?", id).Limit(1).Find(&channels)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get notification channel %d: %v", id, result.Error))
+		return nil, result.Error
+	}
+	if len(channels) == 0 {
+		return nil, nil
+	}
+	return &channels[0], nil
+}
+
+// ClaimCodeAttempt 在比较验证码之前记一次尝试，已尝试 max 次的验证码不再接受。
+// 条件更新保证并发请求的总尝试次数不超过上限。返回false表示次数已用完
+func (r *NotificationRepository) ClaimCodeAttempt(id uint, max int) (bool, error) {
+	result := r.db.Model(&models.NotificationChannel{}).
+		Where("id = ? AND verification_attempts < ?", id, max).
+		Update("verification_attempts", gorm.Expr("verification_attempts + 1"))
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to record code attempt of notification channel %d: %v", id, result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// MarkVerified 标记渠道已验证并清除验证码
+func (r *NotificationRepository) MarkVerified(id uint) error {
+	now := time.Now()
+	result := r.db.Model(&models.NotificationChannel{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"verified":                true,
+		"verified_at":             now,
+		"verification_code_hash":  "",
+		"verification_expires_at": nil,
+	})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to verify notification channel %d: %v", id, result.Error))
+		return result.Error
+	}
+	return nil
+}
+
+// DeleteChannel 删除用户某类型的渠道
+func (r *NotificationRepository) DeleteChannel(userAddress, channelType string) (bool, error) {
+	result := r.db.Where("user_address = ? AND type = ?", userAddress, channelType).Delete(&models.NotificationChannel{})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to delete notification channel: %v", result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ListChannels 获取用户的所有渠道
+func (r *NotificationRepository) ListChannels(userAddress string) ([]models.NotificationChannel, error) {
+	var channels []models.NotificationChannel
+	if err := r.db.Where("user_address = ?", userAddress).Order("id ASC").Find(&channels).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to list notification channels: %v", err))
+		return nil, err
+	}
+	return channels, nil
+}
+
+// ListVerifiedChannels 获取用户已验证的渠道
+func (r *NotificationRepository) ListVerifiedChannels(userAddress string) ([]models.NotificationChannel, error) {
+	var channels []models.NotificationChannel
+	if err := r.db.Where("user_address = ? AND verified = ?", userAddress, true).Find(&channels).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to list verified channels: %v", err))
+		return nil, err
+	}
+	return channels, nil
+}
+
+// SetSecret 更换渠道的webhook签名密钥
+func (r *NotificationRepository) SetSecret(id uint, secret string) error {
+	result := r.db.Model(&models.NotificationChannel{}).Where("id = ?", id).Update("secret", secret)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to rotate secret of notification channel %d: %v", id, result.Error))
+		return result.Error
+	}
+	return nil
+}
+
+// RecordDelivery 记录一次webhook投递尝试
+func (r *NotificationRepository) RecordDelivery(delivery *models.WebhookDelivery) error {
+	if err := r.db.Create(delivery).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to record webhook delivery %s: %v", delivery.DeliveryID, err))
+		return err
+	}
+	return nil
+}
+
+// ListDeliveries 获取渠道最近的投递尝试，按时间倒序
+func (r *NotificationRepository) ListDeliveries(channelID uint, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	if err := r.db.Where("channel_id = ?", channelID).Order("attempted_at DESC, id DESC").Limit(limit).Find(&deliveries).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to list webhook deliveries of channel %d: %v", channelID, err))
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// PruneDeliveriesBefore 删除早于cutoff的投递记录，返回删除的行数
+func (r *NotificationRepository) PruneDeliveriesBefore(cutoff time.Time) (int64, error) {
+	result := r.db.Where("attempted_at < ?", cutoff).Delete(&models.WebhookDelivery{})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to prune webhook deliveries: %v", result.Error))
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// UpsertSubscription 创建订阅，同一事件和资金库已存在时更新阈值
+func (r *NotificationRepository) UpsertSubscription(subscription *models.NotificationSubscription) error {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_address"}, {Name: "event"}, {Name: "vault_address"}},
+		DoUpdates: clause.AssignmentColumns([]string{"threshold", "triggered_at"}),
+	}).Create(subscription)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to save notification subscription: %v", result.Error))
+		return result.Error
+	}
+	return nil
+}
+
+// DeleteSubscription 删除用户的订阅
+func (r *NotificationRepository) DeleteSubscription(userAddress string, id uint) (bool, error) {
+	result := r.db.Where("id = ? AND user_address = ?", id, userAddress).Delete(&models.NotificationSubscription{})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to delete notification subscription %d: %v", id, result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ListSubscriptions 获取用户的所有订阅
+func (r *NotificationRepository) ListSubscriptions(userAddress string) ([]models.NotificationSubscription, error) {
+	var subscriptions []models.NotificationSubscription
+	if err := r.db.Where("user_address = ?", userAddress).Order("id ASC").Find(&subscriptions).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to list notification subscriptions: %v", err))
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// FindSubscribers 查找订阅了某资金库事件的订阅(包括订阅所有资金库的)，userAddress为空时不限用户
+func (r *NotificationRepository) FindSubscribers(event, vaultAddress, userAddress string) ([]models.NotificationSubscription, error) {
+	var subscriptions []models.NotificationSubscription
+	query := r.db.Where("event = ? AND vault_address IN ?", event, []string{vaultAddress, ""})
+	if userAddress != "" {
+		query = query.Where("user_address = ?", userAddress)
+	}
+	if err := query.Find(&subscriptions).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to find %s subscribers: %v", event, err))
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// ListEventSubscriptions 获取所有用户对某事件的订阅，按用户排序
+func (r *NotificationRepository) ListEventSubscriptions(event string) ([]models.NotificationSubscription, error) {
+	var subscriptions []models.NotificationSubscription
+	if err := r.db.Where("event = ?", event).Order("user_address ASC, id ASC").Find(&subscriptions).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to list %s subscriptions: %v", event, err))
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// SetTriggered 记录或清除阈值类订阅的触发状态
+func (r *NotificationRepository) SetTriggered(id uint, at *time.Time) error {
+	result := r.db.Model(&models.NotificationSubscription{}).Where("id = ?", id).Update("triggered_at", at)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to update subscription %d trigger: %v", id, result.Error))
+		return result.Error
+	}
+	return nil
+}
+
+// RecordTrigger 记录一次告警触发
+func (r *NotificationRepository) RecordTrigger(trigger *models.AlertTrigger) error {
+	if err := r.db.Create(trigger).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to record %s trigger %d: %v", trigger.Source, trigger.SourceID, err))
+		return err
+	}
+	return nil
+}
+
+// ListTriggers 获取用户的全部告警触发记录，按时间升序，用于数据导出
+func (r *NotificationRepository) ListTriggers(userAddress string) ([]models.AlertTrigger, error) {
+	var triggers []models.AlertTrigger
+	if err := r.db.Where("user_address = ?", userAddress).Order("triggered_at ASC, id ASC").Find(&triggers).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to list alert triggers of %s: %v", userAddress, err))
+		return nil, err
+	}
+	return triggers, nil
+}
+
+// CountTriggered 统计当前处于触发状态的阈值订阅和告警规则
+func (r *NotificationRepository) CountTriggered() (subscriptions, rules int64, err error) {
+	if err = r.db.Model(&models.NotificationSubscription{}).Where("triggered_at IS NOT NULL").Count(&subscriptions).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to count triggered subscriptions: %v", err))
+		return 0, 0, err
+	}
+	if err = r.db.Model(&models.APYAlertRule{}).Where("triggered_at IS NOT NULL").Count(&rules).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to count triggered alert rules: %v", err))
+		return 0, 0, err
+	}
+	return subscriptions, rules, nil
+}
+
+// CreateRule 创建APY告警规则，用户在同一资金库上已有相同规则时返回false
+func (r *NotificationRepository) CreateRule(rule *models.APYAlertRule) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(rule)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to create APY alert rule: %v", result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CountRules 统计用户的告警规则数量
+func (r *NotificationRepository) CountRules(userAddress string) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.APYAlertRule{}).Where("user_address = ?", userAddress).Count(&count).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to count APY alert rules: %v", err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// ListRules 获取用户的所有告警规则
+func (r *NotificationRepository) ListRules(userAddress string) ([]models.APYAlertRule, error) {
+	var rules []models.APYAlertRule
+	if err := r.db.Where("user_address = ?", userAddress).Order("id ASC").Find(&rules).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to list APY alert rules: %v", err))
+		return nil, err
+	}
+	return rules, nil
+}
+
+// DeleteRule 删除用户的告警规则
+func (r *NotificationRepository) DeleteRule(userAddress string, id uint) (bool, error) {
+	result := r.db.Where("id = ? AND user_address = ?", id, userAddress).Delete(&models.APYAlertRule{})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to delete APY alert rule %d: %v", id, result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FindVaultRules 获取某资金库上的所有告警规则
+func (r *NotificationRepository) FindVaultRules(vaultAddress string) ([]models.APYAlertRule, error) {
+	var rules []models.APYAlertRule
+	if err := r.db.Where("vault_address = ?", vaultAddress).Find(&rules).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to find APY alert rules for %s: %v", vaultAddress, err))
+		return nil, err
+	}
+	return rules, nil
+}
+
+// UpdateRuleState 更新告警规则的触发与通知时间
+func (r *NotificationRepository) UpdateRuleState(id uint, triggeredAt, lastNotifiedAt *time.Time) error {
+	result := r.db.Model(&models.APYAlertRule{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"triggered_at":     triggeredAt,
+		"last_notified_at": lastNotifiedAt,
+	})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to update APY alert rule %d: %v", id, result.Error))
+		return result.Error
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PendleRepository struct {
+	db *gorm.DB
+}
+
+func NewPendleRepository() *PendleRepository {
+	return &PendleRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Upsert 在同一事务中登记策略的市场并把到期时间写入策略，再次登记时整行替换
+func (r *PendleRepository) Upsert(market *models.PendleMarket) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "strategy_address"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"market", "sy", "pt", "yt", "expiry", "implied_apy", "pt_price", "block_number", "synced_at", "updated_at",
+			}),
+		}).Create(market).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.Strategy{}).Where("address = ?", market.StrategyAddress).Update("maturity", market.Expiry).Error
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to save Pendle market of strategy %s: %v", market.StrategyAddress, err))
+		return err
+	}
+	return nil
+}
+
+// UpdateReadings 写入一次同步读取的隐含利率和PT价格
+func (r *PendleRepository) UpdateReadings(market *models.PendleMarket) error {
+	result := r.db.Model(market).Select("implied_apy", "pt_price", "block_number", "synced_at", "updated_at").Updates(market)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to update Pendle market of strategy %s: %v", market.StrategyAddress, result.Error))
+		return result.Error
+	}
+	return nil
+}
+
+// Get 获取策略登记的市场，未登记时返回nil
+func (r *PendleRepository) Get(strategyAddress string) (*models.PendleMarket, error) {
+	var markets []models.PendleMarket
+	result := r.db.Where("strategy_address = ?", strategyAddress).Limit(1).Find(&markets)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get Pendle market of strategy %s: %v", strategyAddress, result.Error))
+		return nil, result.Error
+	}
+	if len(markets) == 0 {
+		return nil, nil
+	}
+	return &markets[0], nil
+}
+
+// List 获取全部登记的市场
+func (r *PendleRepository) List() ([]models.PendleMarket, error) {
+	var markets []models.PendleMarket
+	if result := r.db.Order("strategy_address ASC").Find(&markets); result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to list Pendle markets: %v", result.Error))
+		return nil, result.Error
+	}
+	return markets, nil
+}
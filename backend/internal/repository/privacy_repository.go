@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PrivacyRepository struct {
+	db *gorm.DB
+}
+
+func NewPrivacyRepository() *PrivacyRepository {
+	return &PrivacyRepository{
+		db: database.GetDB(),
+	}
+}
+
+// CreateDeletionRequest 创建删除请求，用户已有待处理的请求时返回false
+func (r *PrivacyRepository) CreateDeletionRequest(request *models.DataDeletionRequest) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(request)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to create deletion request for %s: %v", request.UserAddress, result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ListDeletionRequests 获取用户的删除请求，最新的在前
+func (r *PrivacyRepository) ListDeletionRequests(userAddress string) ([]models.DataDeletionRequest, error) {
+	var requests []models.DataDeletionRequest
+	result := r.db.Where("user_address = ?", userAddress).Order("requested_at DESC, id DESC").Find(&requests)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to list deletion requests of %s: %v", userAddress, result.Error))
+		return nil, result.Error
+	}
+	return requests, nil
+}
+
+// PendingDeletionRequests 获取待处理的删除请求，按提交时间排序
+func (r *PrivacyRepository) PendingDeletionRequests(limit int) ([]models.DataDeletionRequest, error) {
+	var requests []models.DataDeletionRequest
+	result := r.db.Where("status = ?", models.DeletionPending).Order("requested_at ASC, id ASC").Limit(limit).Find(&requests)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to list pending deletion requests: %v", result.Error))
+		return nil, result.Error
+	}
+	return requests, nil
+}
+
+// Purge 在同一事务中清除用户资料、删除通知渠道及webhook投递记录、订阅、APY告警规则及其触发记录和收藏，并把请求标记为已完成。
+// 用户记录本身保留，TVL等由链上数据计算的字段不受影响
+func (r *PrivacyRepository) Purge(request *models.DataDeletionRequest, at time.Time) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("address = ?", request.UserAddress).Updates(map[string]interface{}{
+			"nickname":           "",
+			"avatar_url":         "",
+			"email":              "",
+			"profile_updated_at": nil,
+		}).Error; err != nil {
+			return err
+		}
+		for _, model := range []interface{}{
+			&models.WebhookDelivery{},
+			&models.NotificationChannel{},
+			&models.NotificationSubscription{},
+			&models.APYAlertRule{},
+			&models.AlertTrigger{},
+			&models.WatchlistEntry{},
+		} {
+			if err := tx.Where("user_address = ?", request.UserAddress).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.DataDeletionRequest{}).Where("id = ?", request.ID).Updates(map[string]interface{}{
+			"status":       models.DeletionCompleted,
+			"completed_at": at,
+		}).Error
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to purge data of %s: %v", request.UserAddress, err))
+		return err
+	}
+	return nil
+}
+
+// MarkDeletionFailed 记录删除失败的原因
+func (r *PrivacyRepository) MarkDeletionFailed(id uint, reason string, at time.Time) error {
+	result := r.db.Model(&models.DataDeletionRequest{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       models.DeletionFailed,
+		"error":        reason,
+		"completed_at": at,
+	})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to mark deletion request %d failed: %v", id, result.Error))
+		return result.Error
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+type ProtocolRateRepository struct {
+	db *gorm.DB
+}
+
+func NewProtocolRateRepository() *ProtocolRateRepository {
+	return &ProtocolRateRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 写入协议利率记录
+func (r *ProtocolRateRepository) Create(rate *models.ProtocolRate) error {
+	result := r.db.Create(rate)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to create protocol rate: %v", result.Error))
+		return result.Error
+	}
+	return nil
+}
+
+// GetRange 获取协议在某条链某资产上时间区间内的利率，按时间升序
+func (r *ProtocolRateRepository) GetRange(protocol string, chainID uint, assetAddress string, from, to time.Time) ([]models.ProtocolRate, error) {
+	var rates []models.ProtocolRate
+	result := r.db.Where("protocol = ? AND chain_id = ? AND asset_address = ? AND timestamp BETWEEN ? AND ?",
+		protocol, chainID, assetAddress, from, to).
+		Order("timestamp ASC").Find(&rates)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get protocol rates for %s: %v", protocol, result.Error))
+		return nil, result.Error
+	}
+	return rates, nil
+}
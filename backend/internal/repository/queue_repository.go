@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrQueueLeaseLost 任务的租约已过期，已被放回队列或由其他实例重新领取
+var ErrQueueLeaseLost = errors.New("queue job lease lost")
+
+// QueueStat 某类任务在某个状态下的数量
+type QueueStat struct {
+	Kind   string `json:"kind"`
+	Status string `json:"status"`
+	Count  int64  `json:"count"`
+}
+
+type QueueRepository struct {
+	db *gorm.DB
+}
+
+func NewQueueRepository() *QueueRepository {
+	return &QueueRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Enqueue 写入任务，同一 unique_key 已有未结束的任务时不写入并返回false。
+// Postgres 上由部分唯一索引保证并发入队时不重复，先查询是为了SQLite下也能去重
+func (r *QueueRepository) Enqueue(job *models.QueueJob) (bool, error) {
+	if job.UniqueKey != nil {
+		var active int64
+		err := r.db.Model(&models.QueueJob{}).
+			Where("unique_key = ? AND status IN ?", *job.UniqueKey, []string{models.QueueJobAvailable, models.QueueJobRunning}).
+			Count(&active).Error
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to check queued %s jobs: %v", job.Kind, err))
+			return false, err
+		}
+		if active > 0 {
+			return false, nil
+		}
+	}
+
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(job)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to enqueue %s job: %v", job.Kind, result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Claim 领取一个已到执行时间的任务并标记为执行中，leases 为可处理的任务类型及各自的租约时长。
+// Postgres 上用 SKIP LOCKED 让多个worker并发领取不同的任务；没有可执行的任务时返回nil
+func (r *QueueRepository) Claim(leases map[string]time.Duration) (*models.QueueJob, error) {
+	kinds := make([]string, 0, len(leases))
+	for kind := range leases {
+		kinds = append(kinds, kind)
+	}
+
+	var claimed *models.QueueJob
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var jobs []models.QueueJob
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND run_at <= ? AND kind IN ?", models.QueueJobAvailable, time.Now(), kinds).
+			Order("run_at ASC").Order("id ASC").
+			Limit(1).Find(&jobs).Error
+		if err != nil || len(jobs) == 0 {
+			return err
+		}
+
+		job := jobs[0]
+		leaseUntil := time.Now().Add(leases[job.Kind])
+		err = tx.Model(&models.QueueJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+			"status":      models.QueueJobRunning,
+			"attempts":    gorm.Expr("attempts + 1"),
+			"lease_until": leaseUntil,
+		}).Error
+		if err != nil {
+			return err
+		}
+		job.Status = models.QueueJobRunning
+		job.Attempts++
+		job.LeaseUntil = &leaseUntil
+		claimed = &job
+		return nil
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to claim queue job: %v", err))
+		return nil, err
+	}
+	return claimed, nil
+}
+
+// Complete 标记任务执行成功
+func (r *QueueRepository) Complete(job *models.QueueJob) error {
+	now := time.Now()
+	return r.finish(job, map[string]interface{}{
+		"status":      models.QueueJobCompleted,
+		"lease_until": nil,
+		"last_error":  "",
+		"finished_at": now,
+	})
+}
+
+// Retry 记录本次失败并在runAt重新执行
+func (r *QueueRepository) Retry(job *models.QueueJob, lastError string, runAt time.Time) error {
+	return r.finish(job, map[string]interface{}{
+		"status":      models.QueueJobAvailable,
+		"lease_until": nil,
+		"last_error":  lastError,
+		"run_at":      runAt,
+	})
+}
+
+// Fail 标记任务最终失败，不再自动重试
+func (r *QueueRepository) Fail(job *models.QueueJob, lastError string) error {
+	return r.finish(job, map[string]interface{}{
+		"status":      models.QueueJobFailed,
+		"lease_until": nil,
+		"last_error":  lastError,
+		"finished_at": time.Now(),
+	})
+}
+
+// Release 把被中断(worker退出)的任务放回队列，本次不计入尝试次数
+func (r *QueueRepository) Release(job *models.QueueJob) error {
+	return r.finish(job, map[string]interface{}{
+		"status":      models.QueueJobAvailable,
+		"lease_until": nil,
+		"attempts":    gorm.Expr("attempts - 1"),
+		"run_at":      time.Now(),
+	})
+}
+
+// finish 更新本次领取的执行。以领取时的尝试次数作为栅栏：租约过期后被放回或重新领取的任务不受影响，
+// 此时返回 ErrQueueLeaseLost
+func (r *QueueRepository) finish(job *models.QueueJob, updates map[string]interface{}) error {
+	result := r.db.Model(&models.QueueJob{}).
+		Where("id = ? AND status = ? AND attempts = ?", job.ID, models.QueueJobRunning, job.Attempts).
+		Updates(updates)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to update queue job %d: %v", job.ID, result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrQueueLeaseLost
+	}
+	return nil
+}
+
+// RescueExpired 处理租约已过期仍在执行中的任务(执行它的worker已退出)：
+// 还有重试次数的放回队列，否则标记为失败。返回处理的任务数
+func (r *QueueRepository) RescueExpired() (int64, error) {
+	now := time.Now()
+	result := r.db.Model(&models.QueueJob{}).
+		Where("status = ? AND lease_until < ?", models.QueueJobRunning, now).
+		Updates(map[string]interface{}{
+			"status":      gorm.Expr("CASE WHEN attempts >= max_attempts THEN ? ELSE ? END", models.QueueJobFailed, models.QueueJobAvailable),
+			"finished_at": gorm.Expr("CASE WHEN attempts >= max_attempts THEN ? ELSE NULL END", now),
+			"last_error":  "lease expired, worker stopped during execution",
+			"lease_until": nil,
+			"run_at":      now,
+		})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to rescue expired queue jobs: %v", result.Error))
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// GetByID 获取任务，不存在时返回nil
+func (r *QueueRepository) GetByID(id uint) (*models.QueueJob, error) {
+	var jobs []models.QueueJob
+	result := r.db.Where("id = ?", id).Limit(1).Find(&jobs)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get queue job %d: %v", id, result.Error))
+		return nil, result.Error
+	}
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+	return &jobs[0], nil
+}
+
+// List 按创建时间倒序分页获取任务，status 和 kind 为空时不过滤
+func (r *QueueRepository) List(status, kind string, cursor *Cursor, limit int) ([]models.QueueJob, *Page, error) {
+	query := r.db.Model(&models.QueueJob{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	jobs, page, err := paginate(query, "created_at", cursor, limit, func(job models.QueueJob) Cursor {
+		return Cursor{Time: job.CreatedAt, ID: job.ID}
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to list queue jobs: %v", err))
+		return nil, nil, err
+	}
+	return jobs, page, nil
+}
+
+// Stats 按类型和状态统计任务数
+func (r *QueueRepository) Stats() ([]QueueStat, error) {
+	var stats []QueueStat
+	result := r.db.Model(&models.QueueJob{}).
+		Select("kind, status, COUNT(*) AS count").
+		Group("kind, status").Order("kind ASC").Order("status ASC").
+		Scan(&stats)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to count queue jobs: %v", result.Error))
+		return nil, result.Error
+	}
+	return stats, nil
+}
+
+// Requeue 把最终失败的任务重新放回队列并重置尝试次数，返回任务是否处于失败状态
+func (r *QueueRepository) Requeue(id uint) (bool, error) {
+	result := r.db.Model(&models.QueueJob{}).
+		Where("id = ? AND status = ?", id, models.QueueJobFailed).
+		Updates(map[string]interface{}{
+			"status":      models.QueueJobAvailable,
+			"attempts":    0,
+			"run_at":      time.Now(),
+			"finished_at": nil,
+		})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to requeue queue job %d: %v", id, result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Cancel 取消尚未执行的任务，返回任务是否处于等待状态
+func (r *QueueRepository) Cancel(id uint, reason string) (bool, error) {
+	result := r.db.Model(&models.QueueJob{}).
+		Where("id = ? AND status = ?", id, models.QueueJobAvailable).
+		Updates(map[string]interface{}{
+			"status":      models.QueueJobFailed,
+			"last_error":  reason,
+			"finished_at": time.Now(),
+		})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to cancel queue job %d: %v", id, result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// PruneFinishedBefore 删除早于cutoff结束的已完成任务，失败任务保留供排查，返回删除的行数
+func (r *QueueRepository) PruneFinishedBefore(cutoff time.Time) (int64, error) {
+	result := r.db.Where("status = ? AND finished_at < ?", models.QueueJobCompleted, cutoff).Delete(&models.QueueJob{})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to prune queue jobs: %v", result.Error))
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+type QuoteRepository struct {
+	db *gorm.DB
+}
+
+func NewQuoteRepository() *QuoteRepository {
+	return &QuoteRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 保存报价
+func (r *QuoteRepository) Create(quote *models.Quote) error {
+	if err := r.db.Create(quote).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to create %s quote for %s: %v", quote.Kind, quote.UserAddress, err))
+		return err
+	}
+	return nil
+}
+
+// Get 获取用户的报价，不存在或不属于该用户时返回nil
+func (r *QuoteRepository) Get(userAddress, id string) (*models.Quote, error) {
+	var quotes []models.Quote
+	result := r.db.Where("id = ? AND user_address = ?", id, userAddress).Limit(1).Find(&quotes)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get quote %s: %v", id, result.Error))
+		return nil, result.Error
+	}
+	if len(quotes) == 0 {
+		return nil, nil
+	}
+	return &quotes[0], nil
+}
+
+// Refresh 写入重新报价的结果并顺延过期时间，已使用的报价不能刷新。返回false表示报价已被使用
+func (r *QuoteRepository) Refresh(id string, payload json.RawMessage, expiresAt time.Time) (bool, error) {
+	result := r.db.Model(&models.Quote{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"payload":    payload,
+			"expires_at": expiresAt,
+			"refreshes":  gorm.Expr("refreshes + 1"),
+		})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to refresh quote %s: %v", id, result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// markQuoteUsed 标记报价已被存款意向使用，只有未过期且未使用的报价可以标记。返回false表示报价已过期或已被使用
+func markQuoteUsed(tx *gorm.DB, id string, now time.Time) (bool, error) {
+	result := tx.Model(&models.Quote{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", id, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to mark quote %s used: %v", id, result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+type RebalanceRepository struct {
+	db *gorm.DB
+}
+
+func NewRebalanceRepository() *RebalanceRepository {
+	return &RebalanceRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 创建再平衡提案，同一资金库之前待审批的提案会被标记为已取代
+func (r *RebalanceRepository) Create(proposal *models.RebalanceProposal) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.RebalanceProposal{}).
+			Where("vault_address = ? AND status = ?", proposal.VaultAddress, models.RebalanceStatusPending).
+			Update("status", models.RebalanceStatusSuperseded).Error; err != nil {
+			return err
+		}
+		return tx.Create(proposal).Error
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create rebalance proposal: %v", err))
+		return err
+	}
+	return nil
+}
+
+// GetByID 根据ID获取提案及其调整明细
+func (r *RebalanceRepository) GetByID(id uint) (*models.RebalanceProposal, error) {
+	var proposal models.RebalanceProposal
+	result := r.db.Preload("Items").First(&proposal, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		logger.Error(fmt.Sprintf("Failed to get rebalance proposal %d: %v", id, result.Error))
+		return nil, result.Error
+	}
+	return &proposal, nil
+}
+
+// List 按状态列出提案，status为空时返回全部
+func (r *RebalanceRepository) List(status string, limit int) ([]models.RebalanceProposal, error) {
+	var proposals []models.RebalanceProposal
+	query := r.db.Preload("Items").Order("created_at DESC").Limit(limit)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Find(&proposals).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to list rebalance proposals: %v", err))
+		return nil, err
+	}
+	return proposals, nil
+}
+
+// UpdateReview 记录审批结果，只有待审批的提案可以被审批
+func (r *RebalanceRepository) UpdateReview(id uint, status, reviewer string) (bool, error) {
+	now := time.Now()
+	result := r.db.Model(&models.RebalanceProposal{}).
+		Where("id = ? AND status = ?", id, models.RebalanceStatusPending).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"reviewed_by": reviewer,
+			"reviewed_at": now,
+		})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to review rebalance proposal %d: %v", id, result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// MarkExecuted 在同一事务中写入执行交易、更新策略资产与目标分配并将提案标记为已执行
+func (r *RebalanceRepository) MarkExecuted(proposal *models.RebalanceProposal, txs []models.Transaction) error {
+	now := time.Now()
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.RebalanceProposal{}).
+			Where("id = ? AND status = ?", proposal.ID, models.RebalanceStatusApproved).
+			Updates(map[string]interface{}{
+				"status":      models.RebalanceStatusExecuted,
+				"executed_at": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("proposal %d is not approved", proposal.ID)
+		}
+
+		for _, item := range proposal.Items {
+			if err := tx.Model(&models.RebalanceItem{}).Where("id = ?", item.ID).
+				Update("tx_hash", item.TxHash).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Strategy{}).Where("address = ?", item.StrategyAddress).
+				Updates(map[string]interface{}{
+					"total_assets": item.TargetAssets,
+					"target_bps":   item.TargetBps,
+				}).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(txs) > 0 {
+			if err := tx.Create(&txs).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to execute rebalance proposal %d: %v", proposal.ID, err))
+		return err
+	}
+	proposal.Status = models.RebalanceStatusExecuted
+	proposal.ExecutedAt = &now
+	return nil
+}
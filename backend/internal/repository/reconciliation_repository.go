@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+type ReconciliationRepository struct {
+	db *gorm.DB
+}
+
+func NewReconciliationRepository() *ReconciliationRepository {
+	return &ReconciliationRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 保存对账报告
+func (r *ReconciliationRepository) Create(report *models.ReconciliationReport) error {
+	if err := r.db.Create(report).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to save reconciliation report: %v", err))
+		return err
+	}
+	return nil
+}
+
+// GetByID 获取对账报告，不存在时返回nil
+func (r *ReconciliationRepository) GetByID(id uint) (*models.ReconciliationReport, error) {
+	var report models.ReconciliationReport
+	result := r.db.First(&report, id)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		logger.Error(fmt.Sprintf("Failed to get reconciliation report %d: %v", id, result.Error))
+		return nil, result.Error
+	}
+	return &report, nil
+}
+
+// List 按开始时间倒序分页获取对账报告，列表不含差异明细
+func (r *ReconciliationRepository) List(cursor *Cursor, limit int) ([]models.ReconciliationReport, *Page, error) {
+	query := r.db.Model(&models.ReconciliationReport{}).Omit("discrepancies", "errors")
+	reports, page, err := paginate(query, "started_at", cursor, limit, func(report models.ReconciliationReport) Cursor {
+		return Cursor{Time: report.StartedAt, ID: report.ID}
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to list reconciliation reports: %v", err))
+		return nil, nil, err
+	}
+	return reports, page, nil
+}
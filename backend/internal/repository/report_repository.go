@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReportRepository struct {
+	db *gorm.DB
+}
+
+func NewReportRepository() *ReportRepository {
+	return &ReportRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 保存报告，同一天的报告已存在时返回false
+func (r *ReportRepository) Create(report *models.DailyReport) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(report)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to save report for %s: %v", report.ReportDate.Format(time.DateOnly), result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetByDate 获取某一天的报告，不存在时返回nil
+func (r *ReportRepository) GetByDate(day time.Time) (*models.DailyReport, error) {
+	var reports []models.DailyReport
+	result := r.db.Where("report_date = ?", day.Format(time.DateOnly)).Limit(1).Find(&reports)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get report for %s: %v", day.Format(time.DateOnly), result.Error))
+		return nil, result.Error
+	}
+	if len(reports) == 0 {
+		return nil, nil
+	}
+	return &reports[0], nil
+}
+
+// List 按日期倒序分页获取报告
+func (r *ReportRepository) List(cursor *Cursor, limit int) ([]models.DailyReport, *Page, error) {
+	reports, page, err := paginate(r.db.Model(&models.DailyReport{}), "report_date", cursor, limit, func(report models.DailyReport) Cursor {
+		return Cursor{Time: report.ReportDate, ID: report.ID}
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to list reports: %v", err))
+		return nil, nil, err
+	}
+	return reports, page, nil
+}
+
+// MarkDelivered 记录报告已推送
+func (r *ReportRepository) MarkDelivered(id uint, at time.Time) error {
+	result := r.db.Model(&models.DailyReport{}).Where("id = ?", id).Update("delivered_at", at)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to mark report %d delivered: %v", id, result.Error))
+		return result.Error
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/scheduler"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ScheduledJobRepository struct {
+	db *gorm.DB
+}
+
+func NewScheduledJobRepository() *ScheduledJobRepository {
+	return &ScheduledJobRepository{
+		db: database.GetDB(),
+	}
+}
+
+// SaveState 写入任务的调度配置和运行状态。暂停和手动触发由API控制，这里不覆盖
+func (r *ScheduledJobRepository) SaveState(status scheduler.Status) error {
+	job := models.ScheduledJob{
+		Name:          status.Name,
+		Schedule:      status.Schedule,
+		Timeout:       int64(status.Timeout / time.Second),
+		Running:       status.Running,
+		NextRunAt:     status.NextRunAt,
+		LastStartedAt: status.LastStartedAt,
+	}
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"schedule", "timeout", "running", "next_run_at", "last_started_at", "updated_at"}),
+	}).Create(&job)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to save state of job %s: %v", status.Name, result.Error))
+		return result.Error
+	}
+	return nil
+}
+
+// RecordRun 写入一次执行的结果并累加执行和失败次数，计数跨worker重启保留
+func (r *ScheduledJobRepository) RecordRun(status scheduler.Status) error {
+	failed := 0
+	if status.LastStatus != scheduler.StatusSuccess {
+		failed = 1
+	}
+	result := r.db.Model(&models.ScheduledJob{}).
+		Where("name = ?", status.Name).
+		Updates(map[string]interface{}{
+			"running":          false,
+			"last_finished_at": status.LastFinishedAt,
+			"last_status":      status.LastStatus,
+			"last_error":       status.LastError,
+			"last_duration_ms": status.LastDuration.Milliseconds(),
+			"runs":             gorm.Expr("runs + 1"),
+			"failures":         gorm.Expr("failures + ?", failed),
+			"updated_at":       time.Now(),
+		})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to record run of job %s: %v", status.Name, result.Error))
+		return result.Error
+	}
+	return nil
+}
+
+// ListAll 获取全部任务，按名称排序
+func (r *ScheduledJobRepository) ListAll() ([]models.ScheduledJob, error) {
+	var jobs []models.ScheduledJob
+	result := r.db.Order("name ASC").Find(&jobs)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to list scheduled jobs: %v", result.Error))
+		return nil, result.Error
+	}
+	return jobs, nil
+}
+
+// GetByName 获取任务，worker尚未登记时返回nil
+func (r *ScheduledJobRepository) GetByName(name string) (*models.ScheduledJob, error) {
+	var jobs []models.ScheduledJob
+	result := r.db.Where("name = ?", name).Limit(1).Find(&jobs)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get scheduled job %s: %v", name, result.Error))
+		return nil, result.Error
+	}
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+	return &jobs[0], nil
+}
+
+// SetPaused 暂停或恢复任务的定时执行，返回任务是否存在
+func (r *ScheduledJobRepository) SetPaused(name string, paused bool) (bool, error) {
+	result := r.db.Model(&models.ScheduledJob{}).
+		Where("name = ?", name).
+		Updates(map[string]interface{}{"paused": paused, "updated_at": time.Now()})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to set paused=%t on job %s: %v", paused, name, result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// RequestTrigger 请求立即执行一次任务，由worker下一次轮询时领取
+func (r *ScheduledJobRepository) RequestTrigger(name string) (bool, error) {
+	now := time.Now()
+	result := r.db.Model(&models.ScheduledJob{}).
+		Where("name = ?", name).
+		Updates(map[string]interface{}{"trigger_requested_at": now, "updated_at": now})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to request trigger of job %s: %v", name, result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ClaimTrigger 领取待执行的手动触发请求，多个worker实例中只有一个能领取成功
+func (r *ScheduledJobRepository) ClaimTrigger(name string) (bool, error) {
+	result := r.db.Model(&models.ScheduledJob{}).
+		Where("name = ? AND trigger_requested_at IS NOT NULL", name).
+		Update("trigger_requested_at", nil)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to claim trigger of job %s: %v", name, result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
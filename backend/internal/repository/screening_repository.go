@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+type ScreeningRepository struct {
+	db *gorm.DB
+}
+
+func NewScreeningRepository() *ScreeningRepository {
+	return &ScreeningRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 记录一次筛查结论
+func (r *ScreeningRepository) Create(decision *models.ScreeningDecision) error {
+	if err := r.db.Create(decision).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to record screening decision for %s: %v", decision.UserAddress, err))
+		return err
+	}
+	return nil
+}
+
+// List 按时间倒序获取筛查结论，decision、userAddress为空时不限
+func (r *ScreeningRepository) List(decision, userAddress string, limit int) ([]models.ScreeningDecision, error) {
+	var decisions []models.ScreeningDecision
+	query := r.db.Order("created_at DESC, id DESC").Limit(limit)
+	if decision != "" {
+		query = query.Where("decision = ?", decision)
+	}
+	if userAddress != "" {
+		query = query.Where("user_address = ?", userAddress)
+	}
+	if err := query.Find(&decisions).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to list screening decisions: %v", err))
+		return nil, err
+	}
+	return decisions, nil
+}
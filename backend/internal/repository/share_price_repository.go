@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+type SharePriceRepository struct {
+	db *gorm.DB
+}
+
+func NewSharePriceRepository() *SharePriceRepository {
+	return &SharePriceRepository{
+		db: database.GetDB(),
+	}
+}
+
+// CreateBatch 批量写入一轮同步得到的份额价格
+func (r *SharePriceRepository) CreateBatch(prices []models.SharePrice) error {
+	if len(prices) == 0 {
+		return nil
+	}
+	result := r.db.CreateInBatches(prices, batchSize)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to create share prices: %v", result.Error))
+		return result.Error
+	}
+	return nil
+}
+
+// GetLatest 获取资金库最新的份额价格，没有记录时返回nil
+func (r *SharePriceRepository) GetLatest(vaultAddress string) (*models.SharePrice, error) {
+	var price models.SharePrice
+	result := r.db.Where("vault_address = ?", vaultAddress).Order("timestamp DESC").Order("id DESC").First(&price)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		logger.Error(fmt.Sprintf("Failed to get latest share price for %s: %v", vaultAddress, result.Error))
+		return nil, result.Error
+	}
+	return &price, nil
+}
+
+// GetAt 获取at时刻或之前最近的份额价格，没有记录时返回nil
+func (r *SharePriceRepository) GetAt(vaultAddress string, at time.Time) (*models.SharePrice, error) {
+	var price models.SharePrice
+	result := r.db.Where("vault_address = ? AND timestamp <= ?", vaultAddress, at).
+		Order("timestamp DESC").Order("id DESC").First(&price)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		logger.Error(fmt.Sprintf("Failed to get share price for %s at %s: %v", vaultAddress, at, result.Error))
+		return nil, result.Error
+	}
+	return &price, nil
+}
+
+// GetVaultHistory 按时间倒序分页获取资金库的份额价格
+func (r *SharePriceRepository) GetVaultHistory(vaultAddress string, cursor *Cursor, limit int) ([]models.SharePrice, *Page, error) {
+	query := r.db.Model(&models.SharePrice{}).Where("vault_address = ?", vaultAddress)
+	prices, page, err := paginate(query, "timestamp", cursor, limit, func(price models.SharePrice) Cursor {
+		return Cursor{Time: price.Timestamp, ID: price.ID}
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get share price history for %s: %v", vaultAddress, err))
+		return nil, nil, err
+	}
+	return prices, page, nil
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// SnapshotBatch 快照任务一个周期内产生的全部记录
+type SnapshotBatch struct {
+	APY        []models.APYHistory
+	Strategies []models.StrategySnapshot
+	Rates      []models.ProtocolRate
+}
+
+type SnapshotBatchRepository struct {
+	db *gorm.DB
+}
+
+func NewSnapshotBatchRepository() *SnapshotBatchRepository {
+	return &SnapshotBatchRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Save 在一个事务内批量写入快照，任一表失败时整批回滚，下个周期重新生成
+func (r *SnapshotBatchRepository) Save(batch *SnapshotBatch) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if len(batch.APY) > 0 {
+			if err := tx.CreateInBatches(batch.APY, batchSize).Error; err != nil {
+				return fmt.Errorf("apy history: %w", err)
+			}
+		}
+		if len(batch.Strategies) > 0 {
+			if err := tx.CreateInBatches(batch.Strategies, batchSize).Error; err != nil {
+				return fmt.Errorf("strategy snapshots: %w", err)
+			}
+		}
+		if len(batch.Rates) > 0 {
+			if err := tx.CreateInBatches(batch.Rates, batchSize).Error; err != nil {
+				return fmt.Errorf("protocol rates: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to save snapshot batch: %v", err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+type StatusRepository struct {
+	db *gorm.DB
+}
+
+func NewStatusRepository() *StatusRepository {
+	return &StatusRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Record 记录一次检查结果：状态未变时只更新说明和检查时间，变化时结束当前记录并开始新记录。
+// 返回组件当前的状态记录和是否发生了变化
+func (r *StatusRepository) Record(component, status, message string, at time.Time) (*models.StatusPeriod, bool, error) {
+	var current *models.StatusPeriod
+	changed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var open []models.StatusPeriod
+		if err := tx.Where("component = ? AND ended_at IS NULL", component).Limit(1).Find(&open).Error; err != nil {
+			return err
+		}
+		if len(open) > 0 {
+			if open[0].Status == status {
+				current = &open[0]
+				return tx.Model(current).Updates(map[string]interface{}{"message": message, "checked_at": at}).Error
+			}
+			if err := tx.Model(&open[0]).Update("ended_at", at).Error; err != nil {
+				return err
+			}
+		}
+		changed = true
+		current = &models.StatusPeriod{
+			Component: component,
+			Status:    status,
+			Message:   message,
+			StartedAt: at,
+			CheckedAt: at,
+		}
+		return tx.Create(current).Error
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to record %s status: %v", component, err))
+		return nil, false, err
+	}
+	return current, changed, nil
+}
+
+// Current 各组件的当前状态
+func (r *StatusRepository) Current() ([]models.StatusPeriod, error) {
+	var periods []models.StatusPeriod
+	if err := r.db.Where("ended_at IS NULL").Order("component ASC").Find(&periods).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to get current component status: %v", err))
+		return nil, err
+	}
+	return periods, nil
+}
+
+// ListAbnormal 获取since之后仍在持续或结束的非正常状态，按开始时间倒序
+func (r *StatusRepository) ListAbnormal(since time.Time, limit int) ([]models.StatusPeriod, error) {
+	var periods []models.StatusPeriod
+	result := r.db.Where("status <> ? AND (ended_at IS NULL OR ended_at >= ?)", models.StatusOperational, since).
+		Order("started_at DESC").Limit(limit).Find(&periods)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to list abnormal component status: %v", result.Error))
+		return nil, result.Error
+	}
+	return periods, nil
+}
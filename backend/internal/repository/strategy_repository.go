@@ -1,13 +1,14 @@
 package repository
 
 import (
+	"context"
 	"fmt"
-	"time"
 
 	"github.com/chspring1/mya-platform/backend/internal/models"
 	"github.com/chspring1/mya-platform/backend/pkg/database"
 	"github.com/chspring1/mya-platform/backend/pkg/logger"
 
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
@@ -16,9 +17,17 @@ type StrategyRepository struct {
 }
 
 func NewStrategyRepository() *StrategyRepository {
-	return &StrategyRepository{
-		db: database.GetDB(),
-	}
+	return NewStrategyRepositoryWithDB(database.GetDB())
+}
+
+// NewStrategyRepositoryWithDB 基于指定连接构建，便于使用独立的数据库或事务
+func NewStrategyRepositoryWithDB(db *gorm.DB) *StrategyRepository {
+	return &StrategyRepository{db: db}
+}
+
+// WithContext 返回绑定请求上下文的副本，请求取消或超时时查询随之中止
+func (r *StrategyRepository) WithContext(ctx context.Context) StrategyRepo {
+	return &StrategyRepository{db: r.db.WithContext(ctx)}
 }
 
 // Create 创建策略
@@ -67,7 +76,7 @@ func (r *StrategyRepository) UpdateAPY(address string, apy float64) error {
 }
 
 // UpdateAssets 更新策略总资产
-func (r *StrategyRepository) UpdateAssets(address string, totalAssets float64) error {
+func (r *StrategyRepository) UpdateAssets(address string, totalAssets decimal.Decimal) error {
 	result := r.db.Model(&models.Strategy{}).Where("address = ?", address).Update("total_assets", totalAssets)
 	if result.Error != nil {
 		logger.Error(fmt.Sprintf("Failed to update strategy assets: %v", result.Error))
@@ -76,16 +85,70 @@ func (r *StrategyRepository) UpdateAssets(address string, totalAssets float64) e
 	return nil
 }
 
-// RecordHarvest 记录收获事件
-func (r *StrategyRepository) RecordHarvest(address string, earnings float64) error {
-	now := time.Now()
+// BulkUpdateAssets 用一条语句批量更新多个策略的总资产
+func (r *StrategyRepository) BulkUpdateAssets(assets map[string]decimal.Decimal) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		return bulkUpdateByAddress(tx, "strategies", "total_assets", assets)
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to bulk update strategy assets: %v", err))
+		return err
+	}
+	return nil
+}
+
+// ListActive 获取所有活跃策略
+func (r *StrategyRepository) ListActive() ([]models.Strategy, error) {
+	var strategies []models.Strategy
+	result := r.db.Where("is_active = ?", true).Find(&strategies)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to list active strategies: %v", result.Error))
+		return nil, result.Error
+	}
+	return strategies, nil
+}
+
+// UpdateTargetAllocations 在同一事务中更新资金库下各策略的目标分配(基点)
+func (r *StrategyRepository) UpdateTargetAllocations(vaultAddress string, targets map[string]uint16) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for address, bps := range targets {
+			result := tx.Model(&models.Strategy{}).
+				Where("address = ? AND vault_address = ?", address, vaultAddress).
+				Update("target_bps", bps)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return fmt.Errorf("strategy %s does not belong to vault %s", address, vaultAddress)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to update target allocations for vault %s: %v", vaultAddress, err))
+		return err
+	}
+	return nil
+}
+
+// SetActive 按链上 StrategyAdded/StrategyRemoved 事件启用或停用策略。
+// 未登记的策略在启用时以地址为名称创建，之后由管理员补充元数据
+func (r *StrategyRepository) SetActive(vaultAddress, address string, active bool) error {
 	result := r.db.Model(&models.Strategy{}).Where("address = ?", address).Updates(map[string]interface{}{
-		"total_earnings": gorm.Expr("total_earnings + ?", earnings),
-		"last_harvest":   now,
+		"vault_address": vaultAddress,
+		"is_active":     active,
 	})
 	if result.Error != nil {
-		logger.Error(fmt.Sprintf("Failed to record harvest: %v", result.Error))
+		logger.Error(fmt.Sprintf("Failed to set strategy %s active=%t: %v", address, active, result.Error))
 		return result.Error
 	}
-	return nil
+	if result.RowsAffected > 0 || !active {
+		return nil
+	}
+	return r.Create(&models.Strategy{
+		Address:      address,
+		Name:         address,
+		VaultAddress: vaultAddress,
+		IsActive:     true,
+	})
 }
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+type StrategySnapshotRepository struct {
+	db *gorm.DB
+}
+
+func NewStrategySnapshotRepository() *StrategySnapshotRepository {
+	return &StrategySnapshotRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 写入策略快照
+func (r *StrategySnapshotRepository) Create(snapshot *models.StrategySnapshot) error {
+	result := r.db.Create(snapshot)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to create strategy snapshot: %v", result.Error))
+		return result.Error
+	}
+	return nil
+}
+
+// GetHistory 获取策略在时间区间内的快照，按时间升序
+func (r *StrategySnapshotRepository) GetHistory(strategyAddress string, from, to time.Time, limit int) ([]models.StrategySnapshot, error) {
+	var snapshots []models.StrategySnapshot
+	result := r.db.Where("strategy_address = ? AND timestamp BETWEEN ? AND ?", strategyAddress, from, to).
+		Order("timestamp ASC").Limit(limit).Find(&snapshots)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get strategy history for %s: %v", strategyAddress, result.Error))
+		return nil, result.Error
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+type SuggestionRepository struct {
+	db *gorm.DB
+}
+
+func NewSuggestionRepository() *SuggestionRepository {
+	return &SuggestionRepository{
+		db: database.GetDB(),
+	}
+}
+
+// ListByUser 获取用户当前的调仓建议，按多出的年化收益降序
+func (r *SuggestionRepository) ListByUser(userAddress string) ([]models.RebalanceSuggestion, error) {
+	var suggestions []models.RebalanceSuggestion
+	if err := r.db.Where("user_address = ?", userAddress).Order("annual_gain_usd DESC").Find(&suggestions).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to list suggestions of %s: %v", userAddress, err))
+		return nil, err
+	}
+	return suggestions, nil
+}
+
+// Replace 在同一事务中用本次评估的结果替换用户的全部建议
+func (r *SuggestionRepository) Replace(userAddress string, suggestions []models.RebalanceSuggestion) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_address = ?", userAddress).Delete(&models.RebalanceSuggestion{}).Error; err != nil {
+			return err
+		}
+		if len(suggestions) == 0 {
+			return nil
+		}
+		return tx.Create(&suggestions).Error
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to replace suggestions of %s: %v", userAddress, err))
+		return err
+	}
+	return nil
+}
+
+// DeleteExcept 删除不在userAddresses中的用户的建议，用于清理已取消订阅的用户。返回删除的条数
+func (r *SuggestionRepository) DeleteExcept(userAddresses []string) (int64, error) {
+	query := r.db.Where("1 = 1")
+	if len(userAddresses) > 0 {
+		query = r.db.Where("user_address NOT IN ?", userAddresses)
+	}
+	result := query.Delete(&models.RebalanceSuggestion{})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to prune suggestions: %v", result.Error))
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
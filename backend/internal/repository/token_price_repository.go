@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+type TokenPriceRepository struct {
+	db *gorm.DB
+}
+
+func NewTokenPriceRepository() *TokenPriceRepository {
+	return &TokenPriceRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 写入历史价格
+func (r *TokenPriceRepository) Create(price *models.TokenPrice) error {
+	price.TokenAddress = strings.ToLower(price.TokenAddress)
+	result := r.db.Create(price)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to store token price: %v", result.Error))
+		return result.Error
+	}
+	return nil
+}
+
+// GetAt 获取某时刻之前最近的一条价格记录
+func (r *TokenPriceRepository) GetAt(chainID uint, tokenAddress string, at time.Time) (*models.TokenPrice, error) {
+	var price models.TokenPrice
+	result := r.db.Where("chain_id = ? AND token_address = ? AND timestamp <= ?", chainID, strings.ToLower(tokenAddress), at).
+		Order("timestamp DESC").First(&price)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		logger.Error(fmt.Sprintf("Failed to get token price for %s at %v: %v", tokenAddress, at, result.Error))
+		return nil, result.Error
+	}
+	return &price, nil
+}
+
+// GetRange 获取时间区间内的价格记录，按时间升序
+func (r *TokenPriceRepository) GetRange(chainID uint, tokenAddress string, from, to time.Time) ([]models.TokenPrice, error) {
+	var prices []models.TokenPrice
+	result := r.db.Where("chain_id = ? AND token_address = ? AND timestamp BETWEEN ? AND ?", chainID, strings.ToLower(tokenAddress), from, to).
+		Order("timestamp ASC").Find(&prices)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get token price range for %s: %v", tokenAddress, result.Error))
+		return nil, result.Error
+	}
+	return prices, nil
+}
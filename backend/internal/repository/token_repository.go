@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TokenRepository struct {
+	db *gorm.DB
+}
+
+func NewTokenRepository() *TokenRepository {
+	return &TokenRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Upsert 登记代币，已登记时更新元数据
+func (r *TokenRepository) Upsert(token *models.Token) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chain_id"}, {Name: "address"}},
+		DoUpdates: clause.AssignmentColumns([]string{"symbol", "name", "decimals", "logo_url", "coingecko_id", "updated_at"}),
+	}).Create(token).Error
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to save token %d:%s: %v", token.ChainID, token.Address, err))
+		return err
+	}
+	return nil
+}
+
+// Get 获取登记的代币，未登记时返回nil
+func (r *TokenRepository) Get(chainID uint, address string) (*models.Token, error) {
+	var tokens []models.Token
+	result := r.db.Where("chain_id = ? AND address = ?", chainID, strings.ToLower(address)).Limit(1).Find(&tokens)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get token %d:%s: %v", chainID, address, result.Error))
+		return nil, result.Error
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	return &tokens[0], nil
+}
+
+// List 按链和符号筛选登记的代币，chainID为0、symbol为空表示不限
+func (r *TokenRepository) List(chainID uint, symbol string) ([]models.Token, error) {
+	query := r.db.Model(&models.Token{})
+	if chainID != 0 {
+		query = query.Where("chain_id = ?", chainID)
+	}
+	if symbol != "" {
+		query = query.Where("UPPER(symbol) = ?", strings.ToUpper(symbol))
+	}
+
+	var tokens []models.Token
+	if result := query.Order("chain_id ASC, symbol ASC").Find(&tokens); result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to list tokens: %v", result.Error))
+		return nil, result.Error
+	}
+	return tokens, nil
+}
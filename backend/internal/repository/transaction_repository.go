@@ -1,13 +1,18 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/chspring1/mya-platform/backend/internal/models"
 	"github.com/chspring1/mya-platform/backend/pkg/database"
 	"github.com/chspring1/mya-platform/backend/pkg/logger"
 
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TransactionRepository struct {
@@ -15,9 +20,17 @@ type TransactionRepository struct {
 }
 
 func NewTransactionRepository() *TransactionRepository {
-	return &TransactionRepository{
-		db: database.GetDB(),
-	}
+	return NewTransactionRepositoryWithDB(database.GetDB())
+}
+
+// NewTransactionRepositoryWithDB 基于指定连接构建，便于使用独立的数据库或事务
+func NewTransactionRepositoryWithDB(db *gorm.DB) *TransactionRepository {
+	return &TransactionRepository{db: db}
+}
+
+// WithContext 返回绑定请求上下文的副本，请求取消或超时时查询随之中止
+func (r *TransactionRepository) WithContext(ctx context.Context) TxRepo {
+	return &TransactionRepository{db: r.db.WithContext(ctx)}
 }
 
 // Create 创建交易记录
@@ -44,28 +57,43 @@ func (r *TransactionRepository) GetByTxHash(txHash string) (*models.Transaction,
 	return &transaction, nil
 }
 
-// GetUserTransactions 获取用户的交易记录
-func (r *TransactionRepository) GetUserTransactions(userAddress string, limit int) ([]models.Transaction, error) {
-	var transactions []models.Transaction
-	result := r.db.Where("user_address = ?", userAddress).Order("created_at DESC").Limit(limit).Find(&transactions)
-	if result.Error != nil {
-		logger.Error(fmt.Sprintf("Failed to get user transactions: %v", result.Error))
-		return nil, result.Error
+// GetUserTransactions 按时间倒序分页获取用户的交易记录
+func (r *TransactionRepository) GetUserTransactions(userAddress string, cursor *Cursor, limit int) ([]models.Transaction, *Page, error) {
+	query := r.db.Model(&models.Transaction{}).Where("user_address = ?", userAddress)
+	transactions, page, err := paginate(query, "created_at", cursor, limit, transactionCursor)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get user transactions: %v", err))
+		return nil, nil, err
 	}
-	return transactions, nil
+	return transactions, page, nil
 }
 
-// GetVaultTransactions 获取资金库的交易记录
-func (r *TransactionRepository) GetVaultTransactions(vaultAddress string, limit int) ([]models.Transaction, error) {
+// ListByUser 获取用户的全部交易记录，按时间升序，用于数据导出
+func (r *TransactionRepository) ListByUser(userAddress string) ([]models.Transaction, error) {
 	var transactions []models.Transaction
-	result := r.db.Where("vault_address = ?", vaultAddress).Order("created_at DESC").Limit(limit).Find(&transactions)
+	result := r.db.Where("user_address = ?", userAddress).Order("created_at ASC, id ASC").Find(&transactions)
 	if result.Error != nil {
-		logger.Error(fmt.Sprintf("Failed to get vault transactions: %v", result.Error))
+		logger.Error(fmt.Sprintf("Failed to list transactions of %s: %v", userAddress, result.Error))
 		return nil, result.Error
 	}
 	return transactions, nil
 }
 
+// GetVaultTransactions 按时间倒序分页获取资金库的交易记录
+func (r *TransactionRepository) GetVaultTransactions(vaultAddress string, cursor *Cursor, limit int) ([]models.Transaction, *Page, error) {
+	query := r.db.Model(&models.Transaction{}).Where("vault_address = ?", vaultAddress)
+	transactions, page, err := paginate(query, "created_at", cursor, limit, transactionCursor)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get vault transactions: %v", err))
+		return nil, nil, err
+	}
+	return transactions, page, nil
+}
+
+func transactionCursor(tx models.Transaction) Cursor {
+	return Cursor{Time: tx.CreatedAt, ID: tx.ID}
+}
+
 // UpdateStatus 更新交易状态
 func (r *TransactionRepository) UpdateStatus(txHash string, status string) error {
 	result := r.db.Model(&models.Transaction{}).Where("tx_hash = ?", txHash).Update("status", status)
@@ -75,3 +103,210 @@ func (r *TransactionRepository) UpdateStatus(txHash string, status string) error
 	}
 	return nil
 }
+
+// GetPendingBridges 获取尚未完成的跨链转账，按创建时间升序
+func (r *TransactionRepository) GetPendingBridges(limit int) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	result := r.db.Where("type = ? AND status = ?", models.TransactionBridge, "pending").
+		Order("created_at ASC").Limit(limit).Find(&transactions)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get pending bridge transactions: %v", result.Error))
+		return nil, result.Error
+	}
+	return transactions, nil
+}
+
+// CompleteBridge 记录跨链转账的最终状态和目标链交易哈希
+func (r *TransactionRepository) CompleteBridge(id uint, status, destinationTxHash string) error {
+	result := r.db.Model(&models.Transaction{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":              status,
+		"destination_tx_hash": destinationTxHash,
+	})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to complete bridge transaction %d: %v", id, result.Error))
+		return result.Error
+	}
+	return nil
+}
+
+// PositionTotal 用户在单个资金库的净存入汇总
+type PositionTotal struct {
+	VaultAddress string
+	Shares       decimal.Decimal
+	Assets       decimal.Decimal
+}
+
+// GetUserPositionTotals 按资金库汇总用户已确认的存取款
+func (r *TransactionRepository) GetUserPositionTotals(userAddress string) ([]PositionTotal, error) {
+	var totals []PositionTotal
+	result := r.db.Model(&models.Transaction{}).
+		Select(`vault_address,
+			SUM(CASE WHEN type = 'deposit' THEN shares ELSE -shares END) AS shares,
+			SUM(CASE WHEN type = 'deposit' THEN amount ELSE -amount END) AS assets`).
+		Where("user_address = ? AND status = ? AND type IN ?", userAddress, "confirmed", []string{"deposit", "withdraw"}).
+		Group("vault_address").
+		Having("SUM(CASE WHEN type = 'deposit' THEN shares ELSE -shares END) > 0").
+		Scan(&totals)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to aggregate positions for %s: %v", userAddress, result.Error))
+		return nil, result.Error
+	}
+	return totals, nil
+}
+
+// UserShares 用户在资金库中由已确认存取款累计的份额
+type UserShares struct {
+	UserAddress string
+	Shares      decimal.Decimal
+}
+
+// GetVaultShareBalances 按用户汇总资金库已确认存取款的净份额，包括已全部取出(净份额为0)的用户
+func (r *TransactionRepository) GetVaultShareBalances(vaultAddress string) ([]UserShares, error) {
+	var balances []UserShares
+	result := r.db.Model(&models.Transaction{}).
+		Select("user_address, SUM(CASE WHEN type = 'deposit' THEN shares ELSE -shares END) AS shares").
+		Where("vault_address = ? AND status = ? AND type IN ?", vaultAddress, "confirmed", []string{"deposit", "withdraw"}).
+		Group("user_address").
+		Order("user_address").
+		Scan(&balances)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to aggregate share balances of %s: %v", vaultAddress, result.Error))
+		return nil, result.Error
+	}
+	return balances, nil
+}
+
+// GetUserVaultHistory 获取用户在资金库中已确认的存取款，按时间升序
+func (r *TransactionRepository) GetUserVaultHistory(userAddress, vaultAddress string) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	result := r.db.Where("user_address = ? AND vault_address = ? AND status = ? AND type IN ?",
+		userAddress, vaultAddress, "confirmed", []string{"deposit", "withdraw"}).
+		Order("created_at ASC").Find(&transactions)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get vault history for %s: %v", userAddress, result.Error))
+		return nil, result.Error
+	}
+	return transactions, nil
+}
+
+// ConfirmTransfer 将链上确认的存取款写入交易表并累加资金库存取款总额和TVL。
+// 按 (tx_hash, log_index) 去重，同一交易中的多个存取款事件分别记录；同一事件已确认过时不重复累加，返回false
+func (r *TransactionRepository) ConfirmTransfer(transaction *models.Transaction) (bool, error) {
+	applied := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		existing, err := findTransfer(tx, transaction)
+		switch {
+		case err == nil:
+			if existing.Status == "confirmed" {
+				// 迁移前没有 log_index 的记录由同一事件写入，补上位置后按已处理跳过
+				if existing.LogIndex == nil {
+					return tx.Model(existing).Update("log_index", transaction.LogIndex).Error
+				}
+				return nil
+			}
+			if err := tx.Model(existing).Updates(map[string]interface{}{
+				"status":       "confirmed",
+				"amount":       transaction.Amount,
+				"shares":       transaction.Shares,
+				"block_number": transaction.BlockNumber,
+				"log_index":    transaction.LogIndex,
+			}).Error; err != nil {
+				return err
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			transaction.Status = "confirmed"
+			if err := tx.Create(transaction).Error; err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		// 存取款总额按账本重算，TVL 是随链上同步校正的当前值，按增量调整
+		posted, err := postJournal(tx, transferJournal(transaction))
+		if err != nil {
+			return err
+		}
+		if posted {
+			column, derived := "total_deposits", derivedDeposits
+			if transaction.Type == "withdraw" {
+				column, derived = "total_withdrawals", derivedWithdrawals
+			}
+			if err := deriveTotal(tx, &models.Vault{}, transaction.VaultAddress, column, derived); err != nil {
+				return err
+			}
+		}
+		sign := "+"
+		if transaction.Type == "withdraw" {
+			sign = "-"
+		}
+		if err := tx.Model(&models.Vault{}).Where("address = ?", transaction.VaultAddress).
+			Update("tvl", gorm.Expr("tvl "+sign+" ?", transaction.Amount)).Error; err != nil {
+			return err
+		}
+
+		applied = true
+		return nil
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to confirm transaction %s: %v", transaction.TxHash, err))
+		return false, err
+	}
+	return applied, nil
+}
+
+// findTransfer 锁定并返回事件对应的记录：先按 (tx_hash, log_index) 查找，
+// 找不到时取同一交易中同一用户、资金库和类型且还没有 log_index 的记录(待确认或迁移前写入的)
+func findTransfer(tx *gorm.DB, transaction *models.Transaction) (*models.Transaction, error) {
+	var existing models.Transaction
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("tx_hash = ? AND log_index = ?", transaction.TxHash, transaction.LogIndex).First(&existing).Error
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return &existing, err
+	}
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("tx_hash = ? AND log_index IS NULL AND user_address = ? AND vault_address = ? AND type = ?",
+			transaction.TxHash, transaction.UserAddress, transaction.VaultAddress, transaction.Type).
+		First(&existing).Error
+	return &existing, err
+}
+
+// GetNetOutflow 统计资金库自since以来已确认的净流出(取款减存款)，净流入时为负
+func (r *TransactionRepository) GetNetOutflow(vaultAddress string, since time.Time) (decimal.Decimal, error) {
+	var outflow decimal.NullDecimal
+	result := r.db.Model(&models.Transaction{}).
+		Select("SUM(CASE WHEN type = 'withdraw' THEN amount ELSE -amount END)").
+		Where("vault_address = ? AND status = ? AND type IN ? AND created_at >= ?",
+			vaultAddress, "confirmed", []string{"deposit", "withdraw"}, since).
+		Scan(&outflow)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to compute outflow for %s: %v", vaultAddress, result.Error))
+		return decimal.Zero, result.Error
+	}
+	return outflow.Decimal, nil
+}
+
+// VaultFlow 资金库在一段时间内已确认的存款和取款合计
+type VaultFlow struct {
+	VaultAddress string
+	Deposits     decimal.Decimal
+	Withdrawals  decimal.Decimal
+}
+
+// GetFlows 按资金库统计 [from, to) 内已确认的存取款
+func (r *TransactionRepository) GetFlows(from, to time.Time) ([]VaultFlow, error) {
+	var flows []VaultFlow
+	result := r.db.Model(&models.Transaction{}).
+		Select(`vault_address,
+			COALESCE(SUM(CASE WHEN type = 'deposit' THEN amount END), 0) AS deposits,
+			COALESCE(SUM(CASE WHEN type = 'withdraw' THEN amount END), 0) AS withdrawals`).
+		Where("status = ? AND type IN ? AND created_at >= ? AND created_at < ?",
+			"confirmed", []string{"deposit", "withdraw"}, from, to).
+		Group("vault_address").
+		Scan(&flows)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to aggregate flows: %v", result.Error))
+		return nil, result.Error
+	}
+	return flows, nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+type UniswapV3Repository struct {
+	db *gorm.DB
+}
+
+func NewUniswapV3Repository() *UniswapV3Repository {
+	return &UniswapV3Repository{
+		db: database.GetDB(),
+	}
+}
+
+// SetPosition 在同一事务中登记策略的头寸并写入第一条快照，原有头寸和快照一并替换
+func (r *UniswapV3Repository) SetPosition(position *models.UniswapV3Position, snapshot *models.UniswapV3Snapshot) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("strategy_address = ?", position.StrategyAddress).Delete(&models.UniswapV3Snapshot{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("strategy_address = ?", position.StrategyAddress).Delete(&models.UniswapV3Position{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(position).Error; err != nil {
+			return err
+		}
+		return tx.Create(snapshot).Error
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to set Uniswap v3 position of strategy %s: %v", position.StrategyAddress, err))
+		return err
+	}
+	return nil
+}
+
+// GetPosition 获取策略登记的头寸，未登记时返回nil
+func (r *UniswapV3Repository) GetPosition(strategyAddress string) (*models.UniswapV3Position, error) {
+	var positions []models.UniswapV3Position
+	result := r.db.Where("strategy_address = ?", strategyAddress).Limit(1).Find(&positions)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get Uniswap v3 position of strategy %s: %v", strategyAddress, result.Error))
+		return nil, result.Error
+	}
+	if len(positions) == 0 {
+		return nil, nil
+	}
+	return &positions[0], nil
+}
+
+// ListPositions 获取全部登记的头寸
+func (r *UniswapV3Repository) ListPositions() ([]models.UniswapV3Position, error) {
+	var positions []models.UniswapV3Position
+	if result := r.db.Order("strategy_address ASC").Find(&positions); result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to list Uniswap v3 positions: %v", result.Error))
+		return nil, result.Error
+	}
+	return positions, nil
+}
+
+// CreateSnapshot 写入一条头寸快照
+func (r *UniswapV3Repository) CreateSnapshot(snapshot *models.UniswapV3Snapshot) error {
+	if result := r.db.Create(snapshot); result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to save Uniswap v3 snapshot of strategy %s: %v", snapshot.StrategyAddress, result.Error))
+		return result.Error
+	}
+	return nil
+}
+
+// LatestSnapshot 获取策略最近一次快照，没有快照时返回nil
+func (r *UniswapV3Repository) LatestSnapshot(strategyAddress string) (*models.UniswapV3Snapshot, error) {
+	return r.firstSnapshot(r.db.Where("strategy_address = ?", strategyAddress).Order("timestamp DESC, id DESC"))
+}
+
+// FirstSnapshotSince 获取策略在since及之后的第一次快照，用作手续费APR窗口的起点
+func (r *UniswapV3Repository) FirstSnapshotSince(strategyAddress string, since time.Time) (*models.UniswapV3Snapshot, error) {
+	return r.firstSnapshot(r.db.Where("strategy_address = ? AND timestamp >= ?", strategyAddress, since).Order("timestamp ASC, id ASC"))
+}
+
+func (r *UniswapV3Repository) firstSnapshot(query *gorm.DB) (*models.UniswapV3Snapshot, error) {
+	var snapshots []models.UniswapV3Snapshot
+	if result := query.Limit(1).Find(&snapshots); result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get Uniswap v3 snapshot: %v", result.Error))
+		return nil, result.Error
+	}
+	if len(snapshots) == 0 {
+		return nil, nil
+	}
+	return &snapshots[0], nil
+}
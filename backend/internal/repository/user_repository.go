@@ -1,12 +1,16 @@
 package repository
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/chspring1/mya-platform/backend/internal/models"
 	"github.com/chspring1/mya-platform/backend/pkg/database"
 	"github.com/chspring1/mya-platform/backend/pkg/logger"
 
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
@@ -15,9 +19,17 @@ type UserRepository struct {
 }
 
 func NewUserRepository() *UserRepository {
-	return &UserRepository{
-		db: database.GetDB(),
-	}
+	return NewUserRepositoryWithDB(database.GetDB())
+}
+
+// NewUserRepositoryWithDB 基于指定连接构建，便于使用独立的数据库或事务
+func NewUserRepositoryWithDB(db *gorm.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// WithContext 返回绑定请求上下文的副本，请求取消或超时时查询随之中止
+func (r *UserRepository) WithContext(ctx context.Context) UserRepo {
+	return &UserRepository{db: r.db.WithContext(ctx)}
 }
 
 // Create 创建用户
@@ -33,7 +45,7 @@ func (r *UserRepository) Create(user *models.User) error {
 // GetByAddress 根据地址获取用户
 func (r *UserRepository) GetByAddress(address string) (*models.User, error) {
 	var user models.User
-	result := r.db.Where("address = ?", address).First(&user)
+	result := r.db.Where("address = ?", strings.ToLower(address)).First(&user)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -46,6 +58,7 @@ func (r *UserRepository) GetByAddress(address string) (*models.User, error) {
 
 // GetOrCreate 获取或创建用户
 func (r *UserRepository) GetOrCreate(address string) (*models.User, error) {
+	address = strings.ToLower(address)
 	user, err := r.GetByAddress(address)
 	if err != nil {
 		return nil, err
@@ -54,7 +67,7 @@ func (r *UserRepository) GetOrCreate(address string) (*models.User, error) {
 	if user == nil {
 		user = &models.User{
 			Address:  address,
-			TotalTVL: 0,
+			TotalTVL: decimal.Zero,
 		}
 		if err := r.Create(user); err != nil {
 			return nil, err
@@ -65,7 +78,7 @@ func (r *UserRepository) GetOrCreate(address string) (*models.User, error) {
 }
 
 // UpdateTVL 更新用户总TVL
-func (r *UserRepository) UpdateTVL(address string, tvl float64) error {
+func (r *UserRepository) UpdateTVL(address string, tvl decimal.Decimal) error {
 	result := r.db.Model(&models.User{}).Where("address = ?", address).Update("total_tvl", tvl)
 	if result.Error != nil {
 		logger.Error(fmt.Sprintf("Failed to update user TVL: %v", result.Error))
@@ -84,3 +97,56 @@ func (r *UserRepository) ListAll() ([]models.User, error) {
 	}
 	return users, nil
 }
+
+// Count 统计用户数量
+func (r *UserRepository) Count() (int64, error) {
+	var count int64
+	result := r.db.Model(&models.User{}).Count(&count)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to count users: %v", result.Error))
+		return 0, result.Error
+	}
+	return count, nil
+}
+
+// GetByNickname 按昵称查找用户，不区分大小写
+func (r *UserRepository) GetByNickname(nickname string) (*models.User, error) {
+	var user models.User
+	result := r.db.Where("LOWER(nickname) = LOWER(?)", nickname).First(&user)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		logger.Error(fmt.Sprintf("Failed to get user by nickname %s: %v", nickname, result.Error))
+		return nil, result.Error
+	}
+	return &user, nil
+}
+
+// UpdateProfile 覆盖用户资料，空字符串表示清除该字段
+func (r *UserRepository) UpdateProfile(address, nickname, avatarURL, email string, signedAt time.Time) error {
+	result := r.db.Model(&models.User{}).Where("address = ?", address).Updates(map[string]interface{}{
+		"nickname":           nickname,
+		"avatar_url":         avatarURL,
+		"email":              email,
+		"profile_updated_at": signedAt,
+	})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to update profile of %s: %v", address, result.Error))
+		return result.Error
+	}
+	return nil
+}
+
+// consumeNonce 在nonce大于已使用的最大值时记录并返回true，否则返回false。
+// 条件更新保证并发请求中同一nonce只有一个成功
+func consumeNonce(tx *gorm.DB, address string, nonce uint64) (bool, error) {
+	result := tx.Model(&models.User{}).
+		Where("address = ? AND intent_nonce < ?", address, nonce).
+		Update("intent_nonce", nonce)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to consume nonce of %s: %v", address, result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
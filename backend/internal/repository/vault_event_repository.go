@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VaultEventRepository struct {
+	db *gorm.DB
+}
+
+func NewVaultEventRepository() *VaultEventRepository {
+	return &VaultEventRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Record 写入时间线事件，重复投递的链上事件忽略
+func (r *VaultEventRepository) Record(event *models.VaultEvent) error {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to record %s event for vault %s: %v", event.Type, event.VaultAddress, result.Error))
+		return result.Error
+	}
+	return nil
+}
+
+// GetVaultEvents 按时间倒序分页获取资金库的时间线，types为空时返回所有类型
+func (r *VaultEventRepository) GetVaultEvents(vaultAddress string, types []string, cursor *Cursor, limit int) ([]models.VaultEvent, *Page, error) {
+	query := r.db.Model(&models.VaultEvent{}).Where("vault_address = ?", vaultAddress)
+	if len(types) > 0 {
+		query = query.Where("type IN ?", types)
+	}
+
+	vaultEvents, page, err := paginate(query, "occurred_at", cursor, limit, func(e models.VaultEvent) Cursor {
+		return Cursor{Time: e.OccurredAt, ID: e.ID}
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to get events for vault %s: %v", vaultAddress, err))
+		return nil, nil, err
+	}
+	return vaultEvents, page, nil
+}
+
+// LastAt 资金库最近一次指定类型事件的时间，没有时返回nil
+func (r *VaultEventRepository) LastAt(vaultAddress, eventType string) (*time.Time, error) {
+	var events []models.VaultEvent
+	result := r.db.Where("vault_address = ? AND type = ?", vaultAddress, eventType).
+		Order("occurred_at DESC").Limit(1).Find(&events)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to get last %s event for vault %s: %v", eventType, vaultAddress, result.Error))
+		return nil, result.Error
+	}
+	if len(events) == 0 {
+		return nil, nil
+	}
+	return &events[0].OccurredAt, nil
+}
+
+// ListBetween 获取所有资金库在 [from, to) 内指定类型的事件，按时间升序
+func (r *VaultEventRepository) ListBetween(types []string, from, to time.Time) ([]models.VaultEvent, error) {
+	var events []models.VaultEvent
+	result := r.db.Where("type IN ? AND occurred_at >= ? AND occurred_at < ?", types, from, to).
+		Order("occurred_at ASC, id ASC").Find(&events)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to list vault events between %v and %v: %v", from, to, result.Error))
+		return nil, result.Error
+	}
+	return events, nil
+}
@@ -1,12 +1,15 @@
 package repository
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
 	"github.com/chspring1/mya-platform/backend/internal/models"
 	"github.com/chspring1/mya-platform/backend/pkg/database"
 	"github.com/chspring1/mya-platform/backend/pkg/logger"
 
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
@@ -15,13 +18,24 @@ type VaultRepository struct {
 }
 
 func NewVaultRepository() *VaultRepository {
-	return &VaultRepository{
-		db: database.GetDB(),
-	}
+	return NewVaultRepositoryWithDB(database.GetDB())
+}
+
+// NewVaultRepositoryWithDB 基于指定连接构建，便于使用独立的数据库或事务
+func NewVaultRepositoryWithDB(db *gorm.DB) *VaultRepository {
+	return &VaultRepository{db: db}
+}
+
+// WithContext 返回绑定请求上下文的副本，请求取消或超时时查询随之中止
+func (r *VaultRepository) WithContext(ctx context.Context) VaultRepo {
+	return &VaultRepository{db: r.db.WithContext(ctx)}
 }
 
 // Create 创建资金库
 func (r *VaultRepository) Create(vault *models.Vault) error {
+	vault.Address = strings.ToLower(vault.Address)
+	vault.AssetAddress = strings.ToLower(vault.AssetAddress)
+	vault.StrategyAddress = strings.ToLower(vault.StrategyAddress)
 	result := r.db.Create(vault)
 	if result.Error != nil {
 		logger.Error(fmt.Sprintf("Failed to create vault: %v", result.Error))
@@ -33,7 +47,7 @@ func (r *VaultRepository) Create(vault *models.Vault) error {
 // GetByAddress 根据地址获取资金库
 func (r *VaultRepository) GetByAddress(address string) (*models.Vault, error) {
 	var vault models.Vault
-	result := r.db.Preload("Strategies").Where("address = ?", address).First(&vault)
+	result := r.db.Preload("Strategies").Where("address = ?", strings.ToLower(address)).First(&vault)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -56,7 +70,7 @@ func (r *VaultRepository) ListAll() ([]models.Vault, error) {
 }
 
 // UpdateTVL 更新资金库TVL
-func (r *VaultRepository) UpdateTVL(address string, tvl float64) error {
+func (r *VaultRepository) UpdateTVL(address string, tvl decimal.Decimal) error {
 	result := r.db.Model(&models.Vault{}).Where("address = ?", address).Update("tvl", tvl)
 	if result.Error != nil {
 		logger.Error(fmt.Sprintf("Failed to update vault TVL: %v", result.Error))
@@ -65,6 +79,18 @@ func (r *VaultRepository) UpdateTVL(address string, tvl float64) error {
 	return nil
 }
 
+// BulkUpdateTVL 用一条语句批量更新多个资金库的TVL
+func (r *VaultRepository) BulkUpdateTVL(tvls map[string]decimal.Decimal) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		return bulkUpdateByAddress(tx, "vaults", "tvl", tvls)
+	})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to bulk update vault TVL: %v", err))
+		return err
+	}
+	return nil
+}
+
 // UpdateAPY 更新资金库APY
 func (r *VaultRepository) UpdateAPY(address string, apyCurrent, apyWeekly float64) error {
 	result := r.db.Model(&models.Vault{}).Where("address = ?", address).Updates(map[string]interface{}{
@@ -88,3 +114,60 @@ func (r *VaultRepository) GetActiveVaults() ([]models.Vault, error) {
 	}
 	return vaults, nil
 }
+
+// SetFees 修改资金库的管理费和业绩费，返回false表示资金库不存在
+func (r *VaultRepository) SetFees(address string, managementBps, performanceBps uint16) (bool, error) {
+	result := r.db.Model(&models.Vault{}).Where("address = ?", address).Updates(map[string]interface{}{
+		"management_fee_bps":  managementBps,
+		"performance_fee_bps": performanceBps,
+	})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to set vault %s fees: %v", address, result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// SetDepositCap 修改资金库存款上限，cap为nil表示取消上限，返回false表示资金库不存在
+func (r *VaultRepository) SetDepositCap(address string, cap *decimal.Decimal) (bool, error) {
+	result := r.db.Model(&models.Vault{}).Where("address = ?", address).Update("deposit_cap", cap)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to set vault %s deposit cap: %v", address, result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// SetAllowlistEnabled 开启或关闭资金库的存款白名单，返回false表示资金库不存在
+func (r *VaultRepository) SetAllowlistEnabled(address string, enabled bool) (bool, error) {
+	result := r.db.Model(&models.Vault{}).Where("address = ?", address).Update("allowlist_enabled", enabled)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to set vault %s allowlist: %v", address, result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// SetCategory 修改资金库分类和标签，返回false表示资金库不存在
+func (r *VaultRepository) SetCategory(address, category string, tags []string) (bool, error) {
+	result := r.db.Model(&models.Vault{}).Where("address = ?", address).Select("category", "tags").
+		Updates(&models.Vault{Category: category, Tags: tags})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to set vault %s category: %v", address, result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// SetMode 修改资金库运行模式，冻结时同时停用，返回false表示资金库不存在
+func (r *VaultRepository) SetMode(address, mode string) (bool, error) {
+	result := r.db.Model(&models.Vault{}).Where("address = ?", address).Updates(map[string]interface{}{
+		"mode":      mode,
+		"is_active": mode != models.VaultModeFrozen,
+	})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to set vault %s mode to %s: %v", address, mode, result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WatchlistRepository struct {
+	db *gorm.DB
+}
+
+func NewWatchlistRepository() *WatchlistRepository {
+	return &WatchlistRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Add 收藏资金库，已收藏时返回false
+func (r *WatchlistRepository) Add(entry *models.WatchlistEntry) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(entry)
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to add %s to watchlist of %s: %v", entry.VaultAddress, entry.UserAddress, result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Remove 取消收藏，未收藏时返回false
+func (r *WatchlistRepository) Remove(userAddress, vaultAddress string) (bool, error) {
+	result := r.db.Where("user_address = ? AND vault_address = ?", userAddress, vaultAddress).Delete(&models.WatchlistEntry{})
+	if result.Error != nil {
+		logger.Error(fmt.Sprintf("Failed to remove %s from watchlist of %s: %v", vaultAddress, userAddress, result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Count 统计用户收藏的资金库数量
+func (r *WatchlistRepository) Count(userAddress string) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.WatchlistEntry{}).Where("user_address = ?", userAddress).Count(&count).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to count watchlist of %s: %v", userAddress, err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// List 获取用户收藏的资金库，最近收藏的在前
+func (r *WatchlistRepository) List(userAddress string) ([]models.WatchlistEntry, error) {
+	var entries []models.WatchlistEntry
+	if err := r.db.Where("user_address = ?", userAddress).Order("created_at DESC, id DESC").Find(&entries).Error; err != nil {
+		logger.Error(fmt.Sprintf("Failed to list watchlist of %s: %v", userAddress, err))
+		return nil, err
+	}
+	return entries, nil
+}
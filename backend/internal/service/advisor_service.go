@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/prices"
+
+	"github.com/shopspring/decimal"
+)
+
+var ErrUnknownRiskTolerance = errors.New("risk_tolerance must be conservative, balanced or aggressive")
+
+// AdvisorPosition 组合中的一个资金库，AmountUSD 按当前资产价格换算为 Amount 个底层资产(截断到6位小数)
+type AdvisorPosition struct {
+	Vault     string          `json:"vault"`
+	Name      string          `json:"name"`
+	ChainID   uint            `json:"chain_id"`
+	Asset     string          `json:"asset"`
+	WeightBps uint16          `json:"weight_bps"` // 相对请求的总金额
+	AmountUSD decimal.Decimal `json:"amount_usd"`
+	Amount    decimal.Decimal `json:"amount"`
+	NetAPY    float64         `json:"net_apy"`
+	RiskScore float64         `json:"risk_score"`
+}
+
+// AdvisorMetrics 组合的风险指标，权重相对已分配的金额
+type AdvisorMetrics struct {
+	WeightedRiskScore  float64 `json:"weighted_risk_score"`
+	MaxRiskScore       float64 `json:"max_risk_score"`
+	LargestPositionBps uint16  `json:"largest_position_bps"`
+	Concentration      float64 `json:"concentration"` // 权重的赫芬达尔指数，1表示全部集中在一个资金库
+	VaultCount         int     `json:"vault_count"`
+	ChainCount         int     `json:"chain_count"`
+}
+
+// AdvisorExclusion 未被选入组合的资金库及原因
+type AdvisorExclusion struct {
+	Vault  string `json:"vault"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// Portfolio 按风险偏好建议的组合，ExpectedAPY 为已分配部分扣除费用后的加权年化收益
+type Portfolio struct {
+	RiskTolerance    string             `json:"risk_tolerance"`
+	AmountUSD        decimal.Decimal    `json:"amount_usd"`
+	Positions        []AdvisorPosition  `json:"positions"`
+	UnallocatedUSD   decimal.Decimal    `json:"unallocated_usd"`
+	ExpectedAPY      float64            `json:"expected_apy"`
+	ExpectedYieldUSD decimal.Decimal    `json:"expected_yield_usd"` // 已分配部分一年的预期收益
+	Metrics          AdvisorMetrics     `json:"metrics"`
+	Rationale        []string           `json:"rationale"`
+	Excluded         []AdvisorExclusion `json:"excluded"`
+}
+
+// advisorCandidate 参与排序的资金库，capacityUSD 为nil表示不限额度
+type advisorCandidate struct {
+	vault       *models.Vault
+	net         float64
+	risk        float64
+	score       float64
+	price       float64
+	capacityUSD *decimal.Decimal
+}
+
+// AdvisorService 按风险偏好把一笔美元金额分配到多个资金库。风险分与路由、再平衡使用同一套评分，
+// 偏好决定可接受的最高风险分、单个资金库的上限和排序时的风险扣减
+type AdvisorService struct {
+	vaultService *VaultService
+	tokenRepo    *repository.TokenRepository
+	prices       *prices.Service
+	morpho       *MorphoService
+}
+
+func NewAdvisorService() *AdvisorService {
+	return NewAdvisorServiceWith(repository.Default())
+}
+
+// NewAdvisorServiceWith 使用注入的核心仓储构建
+func NewAdvisorServiceWith(repos *repository.Repositories) *AdvisorService {
+	return &AdvisorService{
+		vaultService: NewVaultServiceWith(repos),
+		tokenRepo:    repository.NewTokenRepository(),
+		prices:       prices.Default(),
+		morpho:       NewMorphoServiceWith(repos),
+	}
+}
+
+// Advise 为amountUSD给出组合建议。assets 为偏好的资产符号或地址，为空时考虑所有资产；chainID 为0时不限链
+func (s *AdvisorService) Advise(ctx context.Context, tolerance string, amountUSD decimal.Decimal, assets []string, chainID uint) (*Portfolio, error) {
+	profile, ok := config.Load().Advisor.Profile(tolerance)
+	if !ok {
+		return nil, ErrUnknownRiskTolerance
+	}
+	var preferred map[string]bool
+	if len(assets) > 0 {
+		preferred = make(map[string]bool)
+		for _, asset := range assets {
+			keys, err := resolveAsset(s.tokenRepo, strings.TrimSpace(asset), chainID)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", asset, err)
+			}
+			for key := range keys {
+				preferred[key] = true
+			}
+		}
+	}
+
+	vaults, err := s.vaultService.GetVaults(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var matched []*models.Vault
+	for i := range vaults {
+		vault := &vaults[i]
+		if chainID != 0 && vault.ChainID != chainID {
+			continue
+		}
+		if preferred != nil && !preferred[assetKey(vault.ChainID, vault.AssetAddress)] {
+			continue
+		}
+		matched = append(matched, vault)
+	}
+	risks, err := vaultRiskScores(s.morpho, matched)
+	if err != nil {
+		return nil, err
+	}
+
+	portfolio := &Portfolio{RiskTolerance: tolerance, AmountUSD: amountUSD, Positions: []AdvisorPosition{}, Excluded: []AdvisorExclusion{}}
+	var candidates []advisorCandidate
+	for _, vault := range matched {
+		c, reason := s.candidate(ctx, vault, profile, risks[vault.Address])
+		if reason != "" {
+			portfolio.Excluded = append(portfolio.Excluded, AdvisorExclusion{Vault: vault.Address, Name: vault.Name, Reason: reason})
+			continue
+		}
+		candidates = append(candidates, c)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	s.allocate(portfolio, candidates, profile)
+	portfolio.Rationale = advisorRationale(portfolio, profile)
+	return portfolio, nil
+}
+
+// candidate 检查资金库是否符合偏好，返回非空原因表示排除
+func (s *AdvisorService) candidate(ctx context.Context, vault *models.Vault, profile config.AdvisorProfile, risk float64) (advisorCandidate, string) {
+	c := advisorCandidate{vault: vault, risk: risk}
+	c.net, _ = vaultNetAPY(vault)
+	c.score = c.net - profile.RiskPenalty*risk
+
+	remaining := vault.RemainingCapacity()
+	switch {
+	case !vault.AcceptsDeposits():
+		return c, "vault is not accepting deposits"
+	case vault.AllowlistEnabled:
+		return c, "vault only accepts allowlisted depositors"
+	case remaining != nil && !remaining.IsPositive():
+		return c, "vault is at its deposit cap"
+	case profile.MaxRiskScore > 0 && risk > profile.MaxRiskScore:
+		return c, fmt.Sprintf("risk score %.1f is above the profile limit of %.1f", risk, profile.MaxRiskScore)
+	case c.score <= 0:
+		return c, "risk-adjusted net APY is not positive"
+	}
+
+	price, err := s.prices.GetPrice(ctx, vault.AssetAddress, vault.ChainID)
+	if err != nil || price.USD <= 0 {
+		return c, "asset price is unavailable"
+	}
+	c.price = price.USD
+	if remaining != nil {
+		capacity := remaining.Mul(decimal.NewFromFloat(price.USD))
+		c.capacityUSD = &capacity
+	}
+	return c, ""
+}
+
+// allocate 按排序依次分配，单个资金库不超过偏好上限和剩余额度，分不完的部分留作未分配
+func (s *AdvisorService) allocate(portfolio *Portfolio, candidates []advisorCandidate, profile config.AdvisorProfile) {
+	maxBps := profile.MaxVaultBps
+	if maxBps == 0 || maxBps > 10000 {
+		maxBps = 10000
+	}
+	perVault := portfolio.AmountUSD.Mul(decimal.NewFromInt(int64(maxBps))).Div(bpsScale)
+	remaining := portfolio.AmountUSD
+	var yield, weightedRisk decimal.Decimal
+	chains := make(map[uint]bool)
+
+	for _, c := range candidates {
+		if !remaining.IsPositive() {
+			break
+		}
+		take := decimal.Min(remaining, perVault)
+		if c.capacityUSD != nil {
+			take = decimal.Min(take, *c.capacityUSD)
+		}
+		take = take.Truncate(2)
+		if !take.IsPositive() {
+			continue
+		}
+		portfolio.Positions = append(portfolio.Positions, AdvisorPosition{
+			Vault:     c.vault.Address,
+			Name:      c.vault.Name,
+			ChainID:   c.vault.ChainID,
+			Asset:     c.vault.AssetAddress,
+			WeightBps: uint16(take.Div(portfolio.AmountUSD).Mul(bpsScale).IntPart()),
+			AmountUSD: take,
+			Amount:    take.Div(decimal.NewFromFloat(c.price)).Truncate(6),
+			NetAPY:    roundRate(c.net),
+			RiskScore: c.risk,
+		})
+		remaining = remaining.Sub(take)
+		yield = yield.Add(take.Mul(decimal.NewFromFloat(c.net)))
+		weightedRisk = weightedRisk.Add(take.Mul(decimal.NewFromFloat(c.risk)))
+		chains[c.vault.ChainID] = true
+	}
+	portfolio.UnallocatedUSD = remaining
+
+	allocated := portfolio.AmountUSD.Sub(remaining)
+	if !allocated.IsPositive() {
+		return
+	}
+	portfolio.ExpectedAPY = roundRate(yield.Div(allocated).InexactFloat64())
+	portfolio.ExpectedYieldUSD = yield.Round(2)
+
+	metrics := &portfolio.Metrics
+	metrics.WeightedRiskScore = weightedRisk.Div(allocated).Round(2).InexactFloat64()
+	metrics.VaultCount = len(portfolio.Positions)
+	metrics.ChainCount = len(chains)
+	var hhi float64
+	for _, p := range portfolio.Positions {
+		weight := p.AmountUSD.Div(allocated)
+		hhi += weight.Mul(weight).InexactFloat64()
+		if bps := uint16(weight.Mul(bpsScale).IntPart()); bps > metrics.LargestPositionBps {
+			metrics.LargestPositionBps = bps
+		}
+		if p.RiskScore > metrics.MaxRiskScore {
+			metrics.MaxRiskScore = p.RiskScore
+		}
+	}
+	metrics.Concentration = roundRate(hhi)
+}
+
+// advisorRationale 说明偏好的约束、组合的构成，以及没有分配完的原因
+func advisorRationale(portfolio *Portfolio, profile config.AdvisorProfile) []string {
+	rationale := []string{fmt.Sprintf("%s profile: vaults with a risk score up to %.1f, at most %s in any one vault, ranked by net APY minus %s per risk point",
+		strings.ToUpper(portfolio.RiskTolerance[:1])+portfolio.RiskTolerance[1:], profile.MaxRiskScore,
+		percent(float64(profile.MaxVaultBps)/10000), percent(profile.RiskPenalty))}
+
+	if len(portfolio.Positions) == 0 {
+		rationale = append(rationale, "No vault fits this profile; see excluded for the reasons")
+		return rationale
+	}
+	rationale = append(rationale, fmt.Sprintf("Spread across %d vault(s) on %d chain(s) for an expected net APY of %s, about $%s a year",
+		portfolio.Metrics.VaultCount, portfolio.Metrics.ChainCount, percent(portfolio.ExpectedAPY), portfolio.ExpectedYieldUSD.StringFixed(2)))
+	if portfolio.UnallocatedUSD.IsPositive() {
+		rationale = append(rationale, fmt.Sprintf("$%s left unallocated: not enough eligible vaults within the per-vault limit and their remaining capacity; add assets or choose a higher risk tolerance",
+			portfolio.UnallocatedUSD.StringFixed(2)))
+	}
+	if len(portfolio.Excluded) > 0 {
+		rationale = append(rationale, fmt.Sprintf("%d vault(s) excluded; see excluded", len(portfolio.Excluded)))
+	}
+	return rationale
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+)
+
+// AllowlistService 管理机构资金库的存款白名单，所有修改写入审计日志
+type AllowlistService struct {
+	vaultRepo     repository.VaultRepo
+	allowlistRepo *repository.AllowlistRepository
+	auditRepo     *repository.AuditRepository
+}
+
+func NewAllowlistService() *AllowlistService {
+	return NewAllowlistServiceWith(repository.Default())
+}
+
+// NewAllowlistServiceWith 使用注入的核心仓储构建
+func NewAllowlistServiceWith(repos *repository.Repositories) *AllowlistService {
+	return &AllowlistService{
+		vaultRepo:     repos.Vaults,
+		allowlistRepo: repository.NewAllowlistRepository(),
+		auditRepo:     repository.NewAuditRepository(),
+	}
+}
+
+// IsAllowed 地址是否可以向资金库存款，未开启白名单的资金库对所有地址开放
+func (s *AllowlistService) IsAllowed(vault *models.Vault, userAddress string) (bool, error) {
+	if !vault.AllowlistEnabled {
+		return true, nil
+	}
+	return s.allowlistRepo.Contains(vault.Address, userAddress)
+}
+
+// List 获取资金库白名单
+func (s *AllowlistService) List(vault *models.Vault) ([]models.VaultAllowlistEntry, error) {
+	return s.allowlistRepo.List(vault.Address)
+}
+
+// SetEnabled 开启或关闭资金库的存款白名单。开启前无需先加入地址，此时所有存款都会被拒绝
+func (s *AllowlistService) SetEnabled(ctx context.Context, vault *models.Vault, actor string, enabled bool, reason string) (*models.Vault, error) {
+	if vault.AllowlistEnabled == enabled {
+		return vault, nil
+	}
+	if _, err := s.vaultRepo.SetAllowlistEnabled(vault.Address, enabled); err != nil {
+		return nil, err
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"reason":  reason,
+		"enabled": enabled,
+	})
+	if err := s.audit(actor, models.AuditSetAllowlist, vault.Address, details); err != nil {
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("Vault %s allowlist enabled=%v by %s: %s", vault.Address, enabled, actor, reason))
+	vault.AllowlistEnabled = enabled
+	InvalidateVault(ctx, vault.Address)
+	return vault, nil
+}
+
+// Add 把地址加入白名单，已在白名单中的地址忽略，返回新加入的数量
+func (s *AllowlistService) Add(vault *models.Vault, actor string, addresses []string, note string) (int64, error) {
+	entries := make([]models.VaultAllowlistEntry, 0, len(addresses))
+	seen := make(map[string]bool, len(addresses))
+	for _, address := range addresses {
+		if seen[address] {
+			continue
+		}
+		seen[address] = true
+		entries = append(entries, models.VaultAllowlistEntry{
+			VaultAddress: vault.Address,
+			UserAddress:  address,
+			AddedBy:      actor,
+			Note:         note,
+		})
+	}
+
+	added, err := s.allowlistRepo.Add(entries)
+	if err != nil {
+		return 0, err
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"addresses": addresses,
+		"added":     added,
+		"note":      note,
+	})
+	if err := s.audit(actor, models.AuditAllowlistAdd, vault.Address, details); err != nil {
+		return 0, err
+	}
+	logger.Info(fmt.Sprintf("Vault %s allowlist: %d addresses added by %s", vault.Address, added, actor))
+	return added, nil
+}
+
+// Remove 从白名单移除地址，返回false表示地址不在白名单中
+func (s *AllowlistService) Remove(vault *models.Vault, actor, userAddress string) (bool, error) {
+	removed, err := s.allowlistRepo.Remove(vault.Address, userAddress)
+	if err != nil || !removed {
+		return false, err
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"address": userAddress,
+	})
+	if err := s.audit(actor, models.AuditAllowlistDel, vault.Address, details); err != nil {
+		return false, err
+	}
+	logger.Info(fmt.Sprintf("Vault %s allowlist: %s removed by %s", vault.Address, userAddress, actor))
+	return true, nil
+}
+
+func (s *AllowlistService) audit(actor, action, target string, details []byte) error {
+	return s.auditRepo.Create(&models.AuditLog{
+		Actor:   actor,
+		Action:  action,
+		Target:  target,
+		Details: details,
+	})
+}
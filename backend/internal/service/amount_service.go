@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/units"
+
+	"github.com/shopspring/decimal"
+)
+
+var ErrInvalidAmount = errors.New("exactly one of the decimal amount and its base-unit (wei) form must be given, and it must be positive and fit in uint256")
+
+// AmountService 资金库存取金额的换算层。请求可以给出按精度换算的十进制数量，也可以给出最小单位(wei)的整数字符串，
+// 统一换算为 units.Amount；底层资产精度取自代币登记表，份额精度读取资金库合约的 decimals()
+type AmountService struct {
+	tokens *TokenService
+}
+
+func NewAmountService() *AmountService {
+	return NewAmountServiceWith(repository.Default())
+}
+
+// NewAmountServiceWith 使用注入的核心仓储构建
+func NewAmountServiceWith(repos *repository.Repositories) *AmountService {
+	return &AmountService{tokens: NewTokenServiceWith(repos)}
+}
+
+// Assets 解析底层资产数量，value 和 base 只能给出一个
+func (s *AmountService) Assets(ctx context.Context, vault *models.Vault, value *decimal.Decimal, base string) (*units.Amount, error) {
+	decimals, err := s.AssetDecimals(ctx, vault)
+	if err != nil {
+		return nil, err
+	}
+	return resolveAmount(value, base, decimals)
+}
+
+// Shares 解析份额数量，value 和 base 只能给出一个
+func (s *AmountService) Shares(ctx context.Context, vault *models.Vault, value *decimal.Decimal, base string) (*units.Amount, error) {
+	decimals, err := s.ShareDecimals(ctx, vault)
+	if err != nil {
+		return nil, err
+	}
+	return resolveAmount(value, base, decimals)
+}
+
+// AssetDecimals 资金库底层资产的精度
+func (s *AmountService) AssetDecimals(ctx context.Context, vault *models.Vault) (int32, error) {
+	decimals, err := s.tokens.Decimals(ctx, vault.ChainID, vault.AssetAddress)
+	if err != nil {
+		return 0, fmt.Errorf("decimals of %s: %w", vault.AssetAddress, err)
+	}
+	return decimals, nil
+}
+
+// ShareDecimals 资金库份额的精度
+func (s *AmountService) ShareDecimals(ctx context.Context, vault *models.Vault) (int32, error) {
+	decimals, err := blockchain.TokenDecimals(ctx, vault.ChainID, vault.Address)
+	if err != nil {
+		return 0, fmt.Errorf("decimals of %s: %w", vault.Address, err)
+	}
+	return decimals, nil
+}
+
+func resolveAmount(value *decimal.Decimal, base string, decimals int32) (*units.Amount, error) {
+	if (value == nil) == (base == "") {
+		return nil, ErrInvalidAmount
+	}
+	var amount *units.Amount
+	var err error
+	if value != nil {
+		amount, err = units.FromValue(*value, decimals)
+	} else {
+		amount, err = units.FromBaseString(base, decimals)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// 十进制数量换算后可能超出链上 uint256，最小单位字符串在解析时已经检查
+	if amount.Base.Sign() <= 0 || amount.Base.BitLen() > 256 {
+		return nil, ErrInvalidAmount
+	}
+	return amount, nil
+}
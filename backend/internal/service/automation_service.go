@@ -0,0 +1,397 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/notify"
+
+	"github.com/shopspring/decimal"
+)
+
+var gelatoTaskID = regexp.MustCompile(`^0x[0-9a-f]{64}$`)
+
+var (
+	ErrAutomationInvalidTask   = errors.New("chainlink task_id must be a decimal upkeep id and gelato task_id a 0x-prefixed bytes32")
+	ErrAutomationNotConfigured = errors.New("automation provider is not configured for the vault's chain")
+	ErrAutomationTaskExists    = errors.New("automation task already registered")
+	ErrAutomationTaskUnknown   = errors.New("automation task not found on chain")
+	ErrAutomationTaskNotFound  = errors.New("automation task not found")
+	ErrAutomationStrategy      = errors.New("harvest tasks require a strategy of the vault, rebalance tasks must not set one")
+	ErrAutomationVaultNotFound = errors.New("vault not found")
+)
+
+// RiskAlert 风险警报
+type RiskAlert struct {
+	ID        string    `json:"id"`
+	Level     string    `json:"level"` // high, medium, low
+	Type      string    `json:"type"`
+	Message   string    `json:"message"`
+	Vault     string    `json:"vault"`
+	Strategy  string    `json:"strategy,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// AutomationTaskRequest 登记定时任务的参数
+type AutomationTaskRequest struct {
+	Provider        string
+	TaskID          string
+	Action          string
+	VaultAddress    string
+	StrategyAddress string
+	Interval        uint // 预期执行间隔(秒)
+}
+
+type AutomationService struct {
+	automationRepo *repository.AutomationRepository
+	vaultRepo      repository.VaultRepo
+	strategyRepo   repository.StrategyRepo
+	timeline       *repository.VaultEventRepository
+	auditRepo      *repository.AuditRepository
+	alerts         *OperatorAlertService
+}
+
+func NewAutomationService() *AutomationService {
+	return NewAutomationServiceWith(repository.Default())
+}
+
+// NewAutomationServiceWith 使用注入的核心仓储构建
+func NewAutomationServiceWith(repos *repository.Repositories) *AutomationService {
+	return &AutomationService{
+		automationRepo: repository.NewAutomationRepository(),
+		vaultRepo:      repos.Vaults,
+		strategyRepo:   repos.Strategies,
+		timeline:       repository.NewVaultEventRepository(),
+		auditRepo:      repository.NewAuditRepository(),
+		alerts:         NewOperatorAlertServiceWith(repos),
+	}
+}
+
+// Register 登记在Chainlink Automation或Gelato上已创建的任务。登记前读取一次链上状态，
+// 链上不存在的任务拒绝登记
+func (s *AutomationService) Register(ctx context.Context, actor string, req AutomationTaskRequest) (*models.AutomationTask, error) {
+	taskID, ok := normalizeTaskID(req.Provider, req.TaskID)
+	if !ok {
+		return nil, ErrAutomationInvalidTask
+	}
+	switch {
+	case req.Action == models.AutomationHarvest && req.StrategyAddress == "",
+		req.Action == models.AutomationRebalance && req.StrategyAddress != "":
+		return nil, ErrAutomationStrategy
+	}
+
+	vault, err := s.vaultRepo.GetByAddress(req.VaultAddress)
+	if err != nil {
+		return nil, err
+	}
+	if vault == nil {
+		return nil, ErrAutomationVaultNotFound
+	}
+	if req.StrategyAddress != "" {
+		strategy, err := s.strategyRepo.GetByAddress(req.StrategyAddress)
+		if err != nil {
+			return nil, err
+		}
+		if strategy == nil || strategy.VaultAddress != vault.Address {
+			return nil, ErrAutomationStrategy
+		}
+	}
+	if _, ok := providerContract(req.Provider, vault.ChainID); !ok {
+		return nil, ErrAutomationNotConfigured
+	}
+
+	existing, err := s.automationRepo.Get(req.Provider, vault.ChainID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrAutomationTaskExists
+	}
+
+	task := &models.AutomationTask{
+		Provider:        req.Provider,
+		ChainID:         vault.ChainID,
+		TaskID:          taskID,
+		Action:          req.Action,
+		VaultAddress:    vault.Address,
+		StrategyAddress: req.StrategyAddress,
+		Interval:        req.Interval,
+		CreatedBy:       actor,
+		CreatedAt:       time.Now(),
+	}
+	missing, err := s.inspect(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+	if missing {
+		return nil, ErrAutomationTaskUnknown
+	}
+	if err := s.automationRepo.Create(task); err != nil {
+		return nil, err
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"provider": task.Provider,
+		"chain_id": task.ChainID,
+		"task_id":  task.TaskID,
+		"action":   task.Action,
+		"strategy": task.StrategyAddress,
+		"interval": task.Interval,
+	})
+	if err := s.auditRepo.Create(&models.AuditLog{
+		Actor:   actor,
+		Action:  models.AuditAutomationAdd,
+		Target:  task.VaultAddress,
+		Details: details,
+	}); err != nil {
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("%s %s task %s registered for vault %s by %s (%s)", task.Provider, task.Action, task.TaskID, task.VaultAddress, actor, task.Status))
+	return task, nil
+}
+
+// Remove 取消登记，不影响平台上的任务本身
+func (s *AutomationService) Remove(actor string, id uint) error {
+	task, err := s.automationRepo.Delete(id)
+	if err != nil {
+		return err
+	}
+	if task == nil {
+		return ErrAutomationTaskNotFound
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"provider": task.Provider,
+		"chain_id": task.ChainID,
+		"task_id":  task.TaskID,
+		"action":   task.Action,
+	})
+	if err := s.auditRepo.Create(&models.AuditLog{
+		Actor:   actor,
+		Action:  models.AuditAutomationDel,
+		Target:  task.VaultAddress,
+		Details: details,
+	}); err != nil {
+		return err
+	}
+
+	logger.Info(fmt.Sprintf("%s task %s unregistered by %s", task.Provider, task.TaskID, actor))
+	return nil
+}
+
+// List 列出登记的定时任务，vaultAddress为空时返回全部
+func (s *AutomationService) List(vaultAddress string) ([]models.AutomationTask, error) {
+	return s.automationRepo.List(vaultAddress)
+}
+
+// CheckAll 检查所有登记任务的状态，任务进入新的异常状态时通知运维。由worker定时调用
+func (s *AutomationService) CheckAll(ctx context.Context) error {
+	tasks, err := s.automationRepo.List("")
+	if err != nil {
+		return err
+	}
+
+	for i := range tasks {
+		task := &tasks[i]
+		previous := task.Status
+		if _, err := s.inspect(ctx, task); err != nil {
+			return err
+		}
+		if err := s.automationRepo.UpdateStatus(task); err != nil {
+			return err
+		}
+		if task.Failing() && task.Status != previous {
+			s.alerts.NotifyOperators(ctx, notify.Message{
+				Subject: fmt.Sprintf("%s %s task for %s is %s", task.Provider, task.Action, task.VaultAddress, task.Status),
+				Body: fmt.Sprintf("%s task %s on chain %d (%s %s) changed from %s to %s: %s",
+					task.Provider, task.TaskID, task.ChainID, task.Action, automationTarget(task),
+					previous, task.Status, task.StatusReason),
+			})
+		}
+	}
+	return nil
+}
+
+// Alerts 把异常的定时任务转为风险警报
+func (s *AutomationService) Alerts() ([]RiskAlert, error) {
+	tasks, err := s.automationRepo.ListFailing()
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := make([]RiskAlert, 0, len(tasks))
+	for _, task := range tasks {
+		level := "high"
+		switch task.Status {
+		case models.AutomationStatusPaused, models.AutomationStatusUnderfunded:
+			level = "medium"
+		case models.AutomationStatusUnreachable:
+			level = "low"
+		}
+		timestamp := task.UpdatedAt
+		if task.LastCheckedAt != nil {
+			timestamp = *task.LastCheckedAt
+		}
+		alerts = append(alerts, RiskAlert{
+			ID:        fmt.Sprintf("automation-%d", task.ID),
+			Level:     level,
+			Type:      "automation",
+			Message:   fmt.Sprintf("%s %s task %s is %s: %s", task.Provider, task.Action, task.TaskID, task.Status, task.StatusReason),
+			Vault:     task.VaultAddress,
+			Strategy:  task.StrategyAddress,
+			Timestamp: timestamp,
+		})
+	}
+	return alerts, nil
+}
+
+// inspect 读取任务在平台上的状态并检查对应操作是否按期执行，结果写入task。
+// 返回true表示平台上不存在该任务；RPC失败记为 unreachable 而不是返回错误
+func (s *AutomationService) inspect(ctx context.Context, task *models.AutomationTask) (bool, error) {
+	now := time.Now()
+	task.LastCheckedAt = &now
+	task.Status, task.StatusReason = models.AutomationStatusActive, ""
+
+	contract, ok := providerContract(task.Provider, task.ChainID)
+	if !ok {
+		task.Status, task.StatusReason = models.AutomationStatusUnreachable, ErrAutomationNotConfigured.Error()
+		return false, nil
+	}
+
+	switch task.Provider {
+	case models.AutomationChainlink:
+		id, _ := new(big.Int).SetString(task.TaskID, 10)
+		upkeep, err := blockchain.ChainlinkUpkeep(ctx, task.ChainID, contract, id)
+		if err != nil {
+			task.Status, task.StatusReason = models.AutomationStatusUnreachable, err.Error()
+			return false, nil
+		}
+		if upkeep == nil {
+			task.Status, task.StatusReason = models.AutomationStatusCancelled, "upkeep not found in registry"
+			return true, nil
+		}
+		balance := decimal.NewFromBigInt(upkeep.Balance, -18)
+		minBalance := decimal.NewFromBigInt(upkeep.MinBalance, -18)
+		task.Balance, task.MinBalance = &balance, &minBalance
+		if upkeep.LastPerformedBlockNumber > 0 {
+			task.LastPerformedBlock = &upkeep.LastPerformedBlockNumber
+		}
+		switch {
+		case upkeep.Cancelled:
+			task.Status, task.StatusReason = models.AutomationStatusCancelled, "upkeep was cancelled"
+			return false, nil
+		case upkeep.Paused:
+			task.Status, task.StatusReason = models.AutomationStatusPaused, "upkeep is paused"
+			return false, nil
+		case upkeep.Balance.Cmp(upkeep.MinBalance) < 0:
+			task.Status = models.AutomationStatusUnderfunded
+			task.StatusReason = fmt.Sprintf("balance %s LINK is below the minimum of %s LINK", balance.String(), minBalance.String())
+			return false, nil
+		}
+	case models.AutomationGelato:
+		network, _ := config.Load().Automation.Network(task.ChainID)
+		ids, err := blockchain.GelatoTaskIDs(ctx, task.ChainID, contract, network.GelatoOwner)
+		if err != nil {
+			task.Status, task.StatusReason = models.AutomationStatusUnreachable, err.Error()
+			return false, nil
+		}
+		found := false
+		for _, id := range ids {
+			if strings.EqualFold(id, task.TaskID) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			task.Status, task.StatusReason = models.AutomationStatusCancelled, "task not found for owner "+network.GelatoOwner
+			return true, nil
+		}
+	}
+
+	return false, s.checkStale(task, now)
+}
+
+// checkStale 以平台记录的收获时间或时间线上的再平衡事件为准，
+// 超过 interval × automation.stale_factor 仍未执行时标记为 stale
+func (s *AutomationService) checkStale(task *models.AutomationTask, now time.Time) error {
+	var last *time.Time
+	switch task.Action {
+	case models.AutomationHarvest:
+		strategy, err := s.strategyRepo.GetByAddress(task.StrategyAddress)
+		if err != nil {
+			return err
+		}
+		if strategy != nil {
+			last = strategy.LastHarvest
+		}
+	case models.AutomationRebalance:
+		var err error
+		if last, err = s.timeline.LastAt(task.VaultAddress, models.VaultEventRebalance); err != nil {
+			return err
+		}
+	}
+
+	// 从未执行过时从登记时间开始计算
+	since := task.CreatedAt
+	if last != nil && last.After(since) {
+		since = *last
+	}
+	limit := time.Duration(float64(task.Interval)*config.Load().Automation.StaleFactor) * time.Second
+	if limit > 0 && now.Sub(since) > limit {
+		task.Status = models.AutomationStatusStale
+		task.StatusReason = fmt.Sprintf("no %s since %s, expected every %s", task.Action,
+			since.UTC().Format(time.RFC3339), (time.Duration(task.Interval) * time.Second).String())
+	}
+	return nil
+}
+
+// providerContract 链上用于查询任务状态的合约地址
+func providerContract(provider string, chainID uint) (string, bool) {
+	network, ok := config.Load().Automation.Network(chainID)
+	if !ok {
+		return "", false
+	}
+	switch provider {
+	case models.AutomationChainlink:
+		return network.ChainlinkRegistry, network.ChainlinkRegistry != ""
+	case models.AutomationGelato:
+		return network.GelatoAutomate, network.GelatoAutomate != "" && network.GelatoOwner != ""
+	}
+	return "", false
+}
+
+// normalizeTaskID Chainlink upkeep ID统一为十进制，Gelato任务ID统一为小写bytes32
+func normalizeTaskID(provider, taskID string) (string, bool) {
+	taskID = strings.TrimSpace(taskID)
+	switch provider {
+	case models.AutomationChainlink:
+		id, ok := new(big.Int).SetString(taskID, 10)
+		if !ok || id.Sign() <= 0 || id.BitLen() > 256 {
+			return "", false
+		}
+		return id.String(), true
+	case models.AutomationGelato:
+		taskID = strings.ToLower(taskID)
+		return taskID, gelatoTaskID.MatchString(taskID)
+	}
+	return "", false
+}
+
+func automationTarget(task *models.AutomationTask) string {
+	if task.StrategyAddress != "" {
+		return task.StrategyAddress
+	}
+	return task.VaultAddress
+}
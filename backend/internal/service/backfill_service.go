@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/chspring1/mya-platform/backend/internal/backfill"
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/queue"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+)
+
+var (
+	ErrBackfillVaultNotFound = errors.New("vault not found")
+	ErrBackfillQueued        = errors.New("a backfill of this vault is already queued or running")
+	ErrInvalidBackfill       = errors.New("from_block is required unless the vault contract is registered with its deployment block, and to_block must not be before it")
+)
+
+// 未指定时与 cmd/backfill 的默认参数相同
+const (
+	defaultBackfillConfirmations = 12
+	defaultBackfillChunk         = 2000
+	defaultBackfillRPS           = 5
+)
+
+// BackfillJob 回放单个资金库历史事件的队列任务负载，字段含义与 cmd/backfill 的参数相同
+type BackfillJob struct {
+	Vault         string  `json:"vault"`
+	FromBlock     uint64  `json:"from_block"`
+	ToBlock       uint64  `json:"to_block,omitempty"`
+	Confirmations uint64  `json:"confirmations"`
+	ChunkSize     uint64  `json:"chunk_size"`
+	RPS           float64 `json:"rps"`
+	Restart       bool    `json:"restart,omitempty"`
+}
+
+// BackfillService 通过队列在worker中回放资金库历史事件。回放按区块段写断点，
+// 重试或worker重启后从断点继续
+type BackfillService struct {
+	vaultRepo    repository.VaultRepo
+	contractRepo *repository.ContractRepository
+	auditRepo    *repository.AuditRepository
+	events       *EventService
+}
+
+func NewBackfillService() *BackfillService {
+	return NewBackfillServiceWith(repository.Default())
+}
+
+// NewBackfillServiceWith 使用注入的核心仓储构建
+func NewBackfillServiceWith(repos *repository.Repositories) *BackfillService {
+	return &BackfillService{
+		vaultRepo:    repos.Vaults,
+		contractRepo: repository.NewContractRepository(),
+		auditRepo:    repository.NewAuditRepository(),
+		events:       NewEventServiceWith(repos),
+	}
+}
+
+// Enqueue 把回放加入队列，同一资金库同时只有一个回放任务。
+// 未指定 from_block 时从合约登记的部署区块开始
+func (s *BackfillService) Enqueue(actor string, job BackfillJob) (*models.QueueJob, error) {
+	if job.Confirmations == 0 {
+		job.Confirmations = defaultBackfillConfirmations
+	}
+	if job.ChunkSize == 0 {
+		job.ChunkSize = defaultBackfillChunk
+	}
+	if job.RPS <= 0 {
+		job.RPS = defaultBackfillRPS
+	}
+
+	vault, err := s.vaultRepo.GetByAddress(job.Vault)
+	if err != nil {
+		return nil, err
+	}
+	if vault == nil {
+		return nil, ErrBackfillVaultNotFound
+	}
+	if job.FromBlock == 0 {
+		contract, err := s.contractRepo.Get(vault.ChainID, vault.Address)
+		if err != nil {
+			return nil, err
+		}
+		if contract != nil {
+			job.FromBlock = contract.DeploymentBlock
+		}
+	}
+	if job.FromBlock == 0 || (job.ToBlock != 0 && job.ToBlock < job.FromBlock) {
+		return nil, ErrInvalidBackfill
+	}
+
+	job.Vault = vault.Address
+	queued, created, err := queue.Enqueue(QueueBackfill, job, queue.Options{UniqueKey: "backfill:" + vault.Address})
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrBackfillQueued
+	}
+
+	details, _ := json.Marshal(job)
+	if err := s.auditRepo.Create(&models.AuditLog{
+		Actor:   actor,
+		Action:  models.AuditQueueEnqueue,
+		Target:  vault.Address,
+		Details: details,
+	}); err != nil {
+		return nil, err
+	}
+	logger.Info(fmt.Sprintf("Backfill of %s from block %d queued by %s", vault.Address, job.FromBlock, actor))
+	return queued, nil
+}
+
+// RunQueued 执行队列中的回放任务。Restart 只在第一次尝试前有意义，
+// 之后的重试仍会从头开始，需要断点续跑时不要设置
+func (s *BackfillService) RunQueued(ctx context.Context, payload json.RawMessage) error {
+	var job BackfillJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return queue.Permanent(err)
+	}
+	vault, err := s.vaultRepo.GetByAddress(job.Vault)
+	if err != nil {
+		return err
+	}
+	if vault == nil {
+		return queue.Permanent(ErrBackfillVaultNotFound)
+	}
+
+	// 历史事件只物化数据，不给用户发送确认通知
+	backfiller, err := backfill.New(vault, backfill.Options{
+		FromBlock:     job.FromBlock,
+		ToBlock:       job.ToBlock,
+		Confirmations: job.Confirmations,
+		ChunkSize:     job.ChunkSize,
+		RPS:           job.RPS,
+		Restart:       job.Restart,
+	}, s.events.Replay().Apply)
+	if err != nil {
+		return err
+	}
+	_, err = backfiller.Run(ctx)
+	return err
+}
@@ -0,0 +1,376 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/units"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/shopspring/decimal"
+)
+
+const batchIDPrefix = "b_"
+
+// 批量存款的交易形式：multicall 由路由合约在一笔交易中完成全部存款，sequence 为按顺序发送的多笔交易
+const (
+	BatchMulticall = "multicall"
+	BatchSequence  = "sequence"
+)
+
+// 批量存款整体状态，由各笔意向的状态汇总
+const (
+	BatchPending           = "pending"
+	BatchPartiallyExecuted = "partially_executed"
+	BatchExecuted          = "executed"
+	BatchRejected          = "rejected"
+)
+
+var (
+	ErrBatchAllocation  = errors.New("allocations must name distinct vaults with weight_bps adding up to 10000")
+	ErrBatchMixedVaults = errors.New("all vaults in a batch must be on the same chain and share the same asset")
+	ErrBatchTooSmall    = errors.New("amount is too small to split across these allocations")
+	ErrBatchNotFound    = errors.New("batch deposit not found")
+)
+
+// BatchLeg 批量存款中存入一个资金库的部分
+type BatchLeg struct {
+	Vault             string          `json:"vault"`
+	WeightBps         uint16          `json:"weight_bps"`
+	IntentID          uint            `json:"intent_id,omitempty"`
+	Amount            decimal.Decimal `json:"amount"`
+	AmountWei         string          `json:"amount_wei"`
+	ExpectedShares    decimal.Decimal `json:"expected_shares"`
+	ExpectedSharesWei string          `json:"expected_shares_wei"`
+	MinShares         decimal.Decimal `json:"min_shares"`
+	MinSharesWei      string          `json:"min_shares_wei"`
+
+	base      *big.Int
+	minShares *big.Int
+}
+
+// BatchQuote 批量存款的合并报价，gas 为全部存款合计
+type BatchQuote struct {
+	Legs        []BatchLeg   `json:"legs"`
+	SlippageBps uint16       `json:"slippage_bps"`
+	Gas         *GasEstimate `json:"gas"`
+}
+
+// BatchTransaction 待用户发送的交易，发送前先按 approve 授权
+type BatchTransaction struct {
+	To        string       `json:"to"`
+	Data      string       `json:"data"`
+	Value     string       `json:"value"`
+	Approve   *ZapApproval `json:"approve,omitempty"`
+	IntentIDs []uint       `json:"intent_ids"`
+}
+
+// BatchDeposit 作为一次操作跟踪的批量存款，由共用 batch_id 的多笔存款意向组成
+type BatchDeposit struct {
+	ID           string             `json:"id"`
+	Status       string             `json:"status"`
+	Mode         string             `json:"mode"`
+	ChainID      uint               `json:"chain_id"`
+	AmountWei    string             `json:"amount_wei"`
+	Deadline     *time.Time         `json:"deadline,omitempty"`
+	Quote        *BatchQuote        `json:"quote,omitempty"`
+	Transactions []BatchTransaction `json:"transactions,omitempty"`
+	Intents      []models.Intent    `json:"intents"`
+}
+
+// BatchDepositService 把一笔存款按权重拆分到多个资金库。所在链配置了意向路由时生成一笔 multicall 交易，
+// 否则生成按顺序发送的多笔交易；各资金库的部分分别保存为存款意向，可以逐笔或整体上报执行结果
+type BatchDepositService struct {
+	repo     *repository.IntentRepository
+	intents  *IntentService
+	previews *PreviewService
+	gas      *GasService
+}
+
+func NewBatchDepositService() *BatchDepositService {
+	return NewBatchDepositServiceWith(repository.Default())
+}
+
+// NewBatchDepositServiceWith 使用注入的核心仓储构建
+func NewBatchDepositServiceWith(repos *repository.Repositories) *BatchDepositService {
+	return &BatchDepositService{
+		repo:     repository.NewIntentRepository(),
+		intents:  NewIntentServiceWith(repos),
+		previews: NewPreviewService(),
+		gas:      NewGasServiceWith(repos),
+	}
+}
+
+// CheckAllocations 校验权重：资金库不重复、每个权重为正且合计10000基点，签名校验前调用
+func CheckAllocations(allocations []IntentAllocation) error {
+	seen := make(map[string]bool, len(allocations))
+	total := 0
+	for _, allocation := range allocations {
+		vault := strings.ToLower(allocation.Vault)
+		if allocation.WeightBps == 0 || seen[vault] {
+			return ErrBatchAllocation
+		}
+		seen[vault] = true
+		total += int(allocation.WeightBps)
+	}
+	if total != 10000 {
+		return ErrBatchAllocation
+	}
+	return nil
+}
+
+// Plan 按权重拆分total并预览各资金库的份额，vaults 与 allocations 一一对应。
+// 各部分按资产最小单位向下取整，余数计入最后一个资金库，合计恰好等于total
+func (s *BatchDepositService) Plan(ctx context.Context, vaults []*models.Vault, allocations []IntentAllocation, total *units.Amount, slippageBps uint16) (*BatchQuote, error) {
+	cfg := config.Load()
+	if slippageBps == 0 {
+		slippageBps = cfg.Slippage.DefaultBps
+	}
+	if slippageBps > cfg.Slippage.MaxBps {
+		return nil, ErrInvalidSlippage
+	}
+	for _, vault := range vaults[1:] {
+		if vault.ChainID != vaults[0].ChainID || !strings.EqualFold(vault.AssetAddress, vaults[0].AssetAddress) {
+			return nil, ErrBatchMixedVaults
+		}
+	}
+
+	quote := &BatchQuote{SlippageBps: slippageBps}
+	remaining := new(big.Int).Set(total.Base)
+	for i, vault := range vaults {
+		base := new(big.Int).Mul(total.Base, big.NewInt(int64(allocations[i].WeightBps)))
+		base.Quo(base, big.NewInt(10000))
+		if i == len(vaults)-1 {
+			base = remaining
+		}
+		remaining = new(big.Int).Sub(remaining, base)
+		if base.Sign() == 0 {
+			return nil, ErrBatchTooSmall
+		}
+
+		amount := units.FromBase(base, total.Decimals)
+		preview, err := s.previews.PreviewDeposit(ctx, vault, amount)
+		if err != nil {
+			return nil, fmt.Errorf("preview %s: %w", vault.Address, err)
+		}
+		shareDecimals, err := blockchain.TokenDecimals(ctx, vault.ChainID, vault.Address)
+		if err != nil {
+			return nil, fmt.Errorf("decimals of %s: %w", vault.Address, err)
+		}
+		shares, _ := units.ParseBase(preview.SharesWei)
+		minShares := new(big.Int).Mul(shares, big.NewInt(int64(10000-int(slippageBps))))
+		minShares.Quo(minShares, big.NewInt(10000))
+
+		quote.Legs = append(quote.Legs, BatchLeg{
+			Vault:             vault.Address,
+			WeightBps:         allocations[i].WeightBps,
+			Amount:            amount,
+			AmountWei:         base.String(),
+			ExpectedShares:    preview.Shares,
+			ExpectedSharesWei: preview.SharesWei,
+			MinShares:         units.FromBase(minShares, shareDecimals),
+			MinSharesWei:      minShares.String(),
+			base:              base,
+			minShares:         minShares,
+		})
+	}
+	quote.Gas = s.gas.Estimate(ctx, vaults[0].ChainID, cfg.Gas.DepositGas*uint64(len(vaults)))
+	return quote, nil
+}
+
+// Prepare 为报价生成交易并保存各资金库的存款意向，意向的最少份额取报价按滑点扣除后的值。
+// 给出 deadline 而所在链未配置路由时返回 ErrDeadlineUnsupported。signed 的nonce与各笔意向在同一事务中消耗，已被使用时返回 ErrNonceUsed
+func (s *BatchDepositService) Prepare(vaults []*models.Vault, userAddress string, quote *BatchQuote, signed Intent) (*BatchDeposit, error) {
+	deadline := signed.Deadline
+	id, err := randomID(batchIDPrefix)
+	if err != nil {
+		return nil, err
+	}
+	chainID := vaults[0].ChainID
+	asset := common.HexToAddress(vaults[0].AssetAddress)
+	user := common.HexToAddress(userAddress)
+	router := intentRouter(config.Load().Intents, chainID)
+	if deadline != 0 && router == "" {
+		return nil, ErrDeadlineUnsupported
+	}
+
+	batch := &BatchDeposit{ID: id, Status: BatchPending, Mode: BatchSequence, ChainID: chainID, Quote: quote}
+	total := new(big.Int)
+	intents := make([]models.Intent, len(vaults))
+	for i, vault := range vaults {
+		leg := quote.Legs[i]
+		total.Add(total, leg.base)
+		intents[i] = models.Intent{
+			BatchID:      id,
+			UserAddress:  userAddress,
+			VaultAddress: vault.Address,
+			ChainID:      chainID,
+			Kind:         IntentDeposit,
+			AmountWei:    leg.AmountWei,
+			MinOutWei:    leg.MinSharesWei,
+			To:           vault.Address,
+			Status:       models.IntentPending,
+		}
+		if router != "" {
+			continue
+		}
+		call, err := encodeCall("deposit(uint256,address,uint256)", leg.base, user, leg.minShares)
+		if err != nil {
+			return nil, fmt.Errorf("encode deposit call: %w", err)
+		}
+		intents[i].Data = hexutil.Encode(call)
+	}
+	batch.AmountWei = total.String()
+
+	if router != "" {
+		batch.Mode = BatchMulticall
+		// 未给出 deadline 时 multicall 使用uint256最大值，即不限制截止时间
+		until := math.MaxBig256
+		if deadline != 0 {
+			at := time.Unix(deadline, 0)
+			batch.Deadline = &at
+			until = big.NewInt(deadline)
+		}
+		call, err := batchRouterCall(common.HexToAddress(router), asset, vaults, quote.Legs, user, total, until)
+		if err != nil {
+			return nil, fmt.Errorf("encode batch deposit call: %w", err)
+		}
+		for i := range intents {
+			intents[i].To = strings.ToLower(router)
+			intents[i].Data = hexutil.Encode(call)
+			intents[i].Deadline = batch.Deadline
+		}
+	}
+
+	if err := s.repo.CreateBatch(intents, signed.claim(userAddress)); err != nil {
+		return nil, claimError(err)
+	}
+	batch.Intents = intents
+	if batch.Mode == BatchMulticall {
+		tx := BatchTransaction{
+			To:      intents[0].To,
+			Data:    intents[0].Data,
+			Value:   "0",
+			Approve: &ZapApproval{Token: strings.ToLower(asset.Hex()), Spender: intents[0].To, Amount: batch.AmountWei},
+		}
+		for i := range intents {
+			quote.Legs[i].IntentID = intents[i].ID
+			tx.IntentIDs = append(tx.IntentIDs, intents[i].ID)
+		}
+		batch.Transactions = []BatchTransaction{tx}
+		return batch, nil
+	}
+	for i := range intents {
+		quote.Legs[i].IntentID = intents[i].ID
+		batch.Transactions = append(batch.Transactions, BatchTransaction{
+			To:        intents[i].To,
+			Data:      intents[i].Data,
+			Value:     "0",
+			Approve:   IntentApproval(&intents[i], vaults[i]),
+			IntentIDs: []uint{intents[i].ID},
+		})
+	}
+	return batch, nil
+}
+
+// Get 获取用户的批量存款和各笔意向的当前状态，不存在时返回 ErrBatchNotFound
+func (s *BatchDepositService) Get(userAddress, id string) (*BatchDeposit, error) {
+	intents, err := s.repo.ListBatch(userAddress, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(intents) == 0 {
+		return nil, ErrBatchNotFound
+	}
+
+	batch := &BatchDeposit{ID: id, Mode: BatchSequence, ChainID: intents[0].ChainID, Deadline: intents[0].Deadline, Intents: intents}
+	if len(intents) > 1 && intents[0].Data == intents[1].Data {
+		batch.Mode = BatchMulticall
+	}
+	total := new(big.Int)
+	executed, rejected := 0, 0
+	for _, intent := range intents {
+		if amount, _ := units.ParseBase(intent.AmountWei); amount != nil {
+			total.Add(total, amount)
+		}
+		switch intent.Status {
+		case models.IntentExecuted:
+			executed++
+		case models.IntentRejected:
+			rejected++
+		}
+	}
+	batch.AmountWei = total.String()
+	switch {
+	case rejected > 0:
+		batch.Status = BatchRejected
+	case executed == len(intents):
+		batch.Status = BatchExecuted
+	case executed > 0:
+		batch.Status = BatchPartiallyExecuted
+	default:
+		batch.Status = BatchPending
+	}
+	return batch, nil
+}
+
+// Report 上报批量存款的一笔执行交易，交易中包含的每笔待执行意向分别核对并记录结果：
+// multicall 的交易一次结束全部意向，sequence 的交易逐笔上报。交易不对应其中任何意向时返回 ErrTxMismatch
+func (s *BatchDepositService) Report(ctx context.Context, userAddress, id, txHash string) (*BatchDeposit, error) {
+	batch, err := s.Get(userAddress, id)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := false
+	for _, intent := range batch.Intents {
+		if intent.Status != models.IntentPending {
+			continue
+		}
+		_, err := s.intents.Report(ctx, userAddress, intent.ID, txHash)
+		switch {
+		case err == nil, errors.Is(err, ErrIntentViolation):
+			matched = true
+		case errors.Is(err, ErrTxMismatch), errors.Is(err, ErrIntentFinished):
+		default:
+			return nil, err
+		}
+	}
+	if !matched {
+		return nil, fmt.Errorf("%w: no pending intent of batch %s", ErrTxMismatch, id)
+	}
+	return s.Get(userAddress, id)
+}
+
+// batchRouterCall 编码经由路由合约的批量存款：一次把全部资产转入路由，再逐个授权并存入资金库，
+// 整体包在 multicall(deadline, data) 中，任何一个资金库的最少份额不满足时整笔回滚
+func batchRouterCall(router, asset common.Address, vaults []*models.Vault, legs []BatchLeg, user common.Address, total, deadline *big.Int) ([]byte, error) {
+	pull, err := encodeCall("pullToken(address,uint256,address)", asset, total, router)
+	if err != nil {
+		return nil, err
+	}
+	steps := [][]byte{pull}
+	for i, vault := range vaults {
+		target := common.HexToAddress(vault.Address)
+		approve, err := encodeCall("approve(address,address,uint256)", asset, target, legs[i].base)
+		if err != nil {
+			return nil, err
+		}
+		deposit, err := encodeCall("deposit(address,address,uint256,uint256)", target, user, legs[i].base, legs[i].minShares)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, approve, deposit)
+	}
+	return encodeCall("multicall(uint256,bytes[])", deadline, steps)
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/bridge"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/swap"
+	"github.com/chspring1/mya-platform/backend/pkg/units"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrBridgeSameChain   = errors.New("funds are already on the vault's chain, deposit directly")
+	ErrBridgeUnsupported = errors.New("source chain is not supported")
+	ErrBridgeMismatch    = errors.New("transaction hash is already tracked for a different user or vault")
+	ErrBridgeSource      = errors.New("source transaction was not sent by this user")
+)
+
+// 每轮最多查询的进行中跨链转账数
+const bridgeTrackBatch = 100
+
+// BridgeQuote 跨链+存款路线：先在源链执行 Transaction 把资产转到资金库所在链，到账后再按 Deposit 存入
+type BridgeQuote struct {
+	Vault          string          `json:"vault"`
+	FromChainID    uint            `json:"from_chain_id"`
+	ToChainID      uint            `json:"to_chain_id"`
+	FromToken      string          `json:"from_token"`
+	AmountIn       decimal.Decimal `json:"amount_in"`
+	Source         string          `json:"source"`
+	Bridge         string          `json:"bridge"`
+	ExpectedAssets decimal.Decimal `json:"expected_assets"`
+	MinAssets      decimal.Decimal `json:"min_assets"`
+	SlippageBps    uint16          `json:"slippage_bps"`
+	Fees           []bridge.Fee    `json:"fees"`
+	FeesUSD        float64         `json:"fees_usd"`
+	GasUSD         float64         `json:"gas_usd"`
+	ETASeconds     int64           `json:"eta_seconds"`
+	Approve        *ZapApproval    `json:"approve,omitempty"`
+	Transaction    ZapTransaction  `json:"transaction"`
+	Deposit        *Preview        `json:"deposit"` // 按到账的最少资产预览存款
+}
+
+type BridgeService struct {
+	source    bridge.Source
+	txRepo    repository.TxRepo
+	vaultRepo repository.VaultRepo
+	previews  *PreviewService
+}
+
+func NewBridgeService() *BridgeService {
+	return NewBridgeServiceWith(repository.Default())
+}
+
+// NewBridgeServiceWith 使用注入的核心仓储构建
+func NewBridgeServiceWith(repos *repository.Repositories) *BridgeService {
+	cfg := config.Load().Bridge
+	return &BridgeService{
+		source:    bridge.NewLiFiSource(cfg.LiFiURL, cfg.LiFiAPIKey, cfg.Integrator),
+		txRepo:    repos.Transactions,
+		vaultRepo: repos.Vaults,
+		previews:  NewPreviewService(),
+	}
+}
+
+// Quote 为其他链上的资金报价跨链到资金库所在链并存入的路线，资产到账地址为用户本人
+func (s *BridgeService) Quote(ctx context.Context, vault *models.Vault, user string, fromChainID uint, fromToken string, amountIn decimal.Decimal, slippageBps uint16) (*BridgeQuote, error) {
+	cfg := config.Load()
+	if fromChainID == vault.ChainID {
+		return nil, ErrBridgeSameChain
+	}
+	if cfg.Blockchain.RPCURL(fromChainID) == "" {
+		return nil, ErrBridgeUnsupported
+	}
+	if slippageBps == 0 {
+		slippageBps = cfg.Bridge.DefaultSlippageBps
+	}
+	if slippageBps > cfg.Bridge.MaxSlippageBps {
+		return nil, ErrInvalidSlippage
+	}
+
+	inDecimals := int32(18)
+	if !swap.IsNative(fromToken) {
+		var err error
+		if inDecimals, err = blockchain.TokenDecimals(ctx, fromChainID, fromToken); err != nil {
+			return nil, fmt.Errorf("decimals of %s: %w", fromToken, err)
+		}
+	}
+	rawIn, err := units.ToBase(amountIn, inDecimals)
+	if err != nil {
+		return nil, err
+	}
+	assetDecimals, err := blockchain.TokenDecimals(ctx, vault.ChainID, vault.AssetAddress)
+	if err != nil {
+		return nil, fmt.Errorf("decimals of %s: %w", vault.AssetAddress, err)
+	}
+
+	quote, err := s.source.Quote(ctx, bridge.Request{
+		FromChainID: fromChainID,
+		ToChainID:   vault.ChainID,
+		FromToken:   fromToken,
+		ToToken:     vault.AssetAddress,
+		FromAmount:  rawIn,
+		FromAddress: user,
+		ToAddress:   user,
+		SlippageBps: slippageBps,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BridgeQuote{
+		Vault:          vault.Address,
+		FromChainID:    fromChainID,
+		ToChainID:      vault.ChainID,
+		FromToken:      strings.ToLower(fromToken),
+		AmountIn:       amountIn,
+		Source:         quote.Source,
+		Bridge:         quote.Bridge,
+		ExpectedAssets: units.FromBase(quote.ToAmount, assetDecimals),
+		MinAssets:      units.FromBase(quote.ToAmountMin, assetDecimals),
+		SlippageBps:    slippageBps,
+		Fees:           quote.Fees,
+		GasUSD:         quote.GasUSD,
+		ETASeconds:     int64(quote.ETA.Seconds()),
+		Transaction: ZapTransaction{
+			ChainID: fromChainID,
+			To:      quote.To,
+			Data:    hexutil.Encode(quote.Data),
+			Value:   quote.Value.String(),
+			Gas:     quote.GasLimit,
+		},
+	}
+	for _, fee := range quote.Fees {
+		result.FeesUSD += fee.AmountUSD
+	}
+	if !swap.IsNative(fromToken) {
+		result.Approve = &ZapApproval{
+			Token:   strings.ToLower(fromToken),
+			Spender: quote.Spender,
+			Amount:  rawIn.String(),
+		}
+	}
+
+	// 存款预览失败不影响跨链报价
+	if result.MinAssets.IsPositive() {
+		preview, err := s.previews.PreviewDeposit(ctx, vault, result.MinAssets)
+		if err != nil {
+			logger.Info(fmt.Sprintf("Failed to preview deposit for bridge quote into %s: %v", vault.Address, err))
+		} else {
+			result.Deposit = preview
+		}
+	}
+	return result, nil
+}
+
+// Track 记录用户已在源链提交的跨链交易，之后由 TrackPending 跟踪到账。重复提交同一交易时返回已有记录。
+// 交易须已在源链成功上链且由用户本人发出，尚未上链时返回 ErrTxPending
+func (s *BridgeService) Track(ctx context.Context, vault *models.Vault, user string, fromChainID uint, txHash, bridgeName string, amount decimal.Decimal) (*models.Transaction, error) {
+	if fromChainID == vault.ChainID {
+		return nil, ErrBridgeSameChain
+	}
+	if config.Load().Blockchain.RPCURL(fromChainID) == "" {
+		return nil, ErrBridgeUnsupported
+	}
+	existing, err := s.txRepo.GetByTxHash(txHash)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if existing.UserAddress != user || existing.VaultAddress != vault.Address || existing.Type != models.TransactionBridge {
+			return nil, ErrBridgeMismatch
+		}
+		return existing, nil
+	}
+	if err := verifyBridgeSource(ctx, fromChainID, user, txHash); err != nil {
+		return nil, err
+	}
+
+	transaction := &models.Transaction{
+		UserAddress:   user,
+		VaultAddress:  vault.Address,
+		Type:          models.TransactionBridge,
+		Amount:        amount,
+		TxHash:        txHash,
+		Status:        "pending",
+		SourceChainID: &fromChainID,
+		Bridge:        bridgeName,
+	}
+	if err := s.txRepo.Create(transaction); err != nil {
+		return nil, err
+	}
+	logger.Info(fmt.Sprintf("Tracking bridge %s from chain %d to vault %s for %s", txHash, fromChainID, vault.Address, user))
+	return transaction, nil
+}
+
+// verifyBridgeSource 确认源链交易已成功上链且发送方为用户本人，防止他人抢先登记用户的交易哈希。
+// 模拟链上所有交易立即成功，不校验发送方
+func verifyBridgeSource(ctx context.Context, chainID uint, user, txHash string) error {
+	receipt, err := blockchain.TransactionReceipt(ctx, chainID, txHash)
+	if err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return ErrTxPending
+		}
+		return err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("%w: transaction reverted", ErrBridgeSource)
+	}
+	if blockchain.MockEnabled() {
+		return nil
+	}
+	from, err := blockchain.TransactionSender(ctx, chainID, txHash)
+	if err != nil {
+		return err
+	}
+	if from != user {
+		return fmt.Errorf("%w: sent by %s", ErrBridgeSource, from)
+	}
+	return nil
+}
+
+// TrackPending 查询进行中的跨链转账状态，到账后记录目标链交易，失败或超时标记为failed
+func (s *BridgeService) TrackPending(ctx context.Context) error {
+	pending, err := s.txRepo.GetPendingBridges(bridgeTrackBatch)
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	timeout := time.Duration(config.Load().Bridge.Timeout) * time.Hour
+	for _, tx := range pending {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		vault, err := s.vaultRepo.GetByAddress(tx.VaultAddress)
+		if err != nil || vault == nil || tx.SourceChainID == nil {
+			continue
+		}
+
+		status, err := s.source.Status(ctx, tx.Bridge, *tx.SourceChainID, vault.ChainID, tx.TxHash)
+		if err != nil {
+			logger.Info(fmt.Sprintf("Failed to query bridge status of %s: %v", tx.TxHash, err))
+			continue
+		}
+
+		switch {
+		case status.Status == bridge.StatusDone:
+			err = s.txRepo.CompleteBridge(tx.ID, "confirmed", status.DestinationTxHash)
+		case status.Status == bridge.StatusFailed:
+			logger.Info(fmt.Sprintf("Bridge %s failed: %s", tx.TxHash, status.Message))
+			err = s.txRepo.CompleteBridge(tx.ID, "failed", "")
+		case timeout > 0 && time.Since(tx.CreatedAt) > timeout:
+			logger.Info(fmt.Sprintf("Bridge %s still %s after %v, marking as failed", tx.TxHash, status.Status, timeout))
+			err = s.txRepo.CompleteBridge(tx.ID, "failed", "")
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/metrics"
+	"github.com/chspring1/mya-platform/backend/pkg/prices"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shopspring/decimal"
+)
+
+// 单次汇总的最长耗时，超时后沿用上一次的结果
+const businessMetricsTimeout = 10 * time.Second
+
+// BusinessCollector 在Prometheus抓取时从数据库汇总TVL、APY、用户数、24小时交易量和告警数。
+// 结果在进程内缓存 cache.metrics_ttl 秒，多个实例各自导出相同的值
+type BusinessCollector struct {
+	vaultRepo         repository.VaultRepo
+	userRepo          repository.UserRepo
+	txRepo            repository.TxRepo
+	notificationRepo  *repository.NotificationRepository
+	automationService *AutomationService
+	priceService      *prices.Service
+	ttl               time.Duration
+
+	mu        sync.Mutex
+	collected []prometheus.Metric
+	expiresAt time.Time
+}
+
+func NewBusinessCollector() *BusinessCollector {
+	return NewBusinessCollectorWith(repository.Default())
+}
+
+// NewBusinessCollectorWith 使用注入的核心仓储构建
+func NewBusinessCollectorWith(repos *repository.Repositories) *BusinessCollector {
+	return &BusinessCollector{
+		vaultRepo:         repos.Vaults,
+		userRepo:          repos.Users,
+		txRepo:            repos.Transactions,
+		notificationRepo:  repository.NewNotificationRepository(),
+		automationService: NewAutomationServiceWith(repos),
+		priceService:      prices.Default(),
+		ttl:               time.Duration(config.Load().Cache.MetricsTTL) * time.Second,
+	}
+}
+
+func (c *BusinessCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		metrics.TotalTVLUSD, metrics.VaultTVL, metrics.VaultTVLUSD, metrics.VaultAPY,
+		metrics.Users, metrics.Volume24hUSD, metrics.RiskAlertsOpen, metrics.UserAlertsTriggered,
+	} {
+		ch <- desc
+	}
+}
+
+func (c *BusinessCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Now().After(c.expiresAt) {
+		ctx, cancel := context.WithTimeout(context.Background(), businessMetricsTimeout)
+		collected, err := c.collect(ctx)
+		cancel()
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to collect business metrics: %v", err))
+		} else {
+			c.collected = collected
+			c.expiresAt = time.Now().Add(c.ttl)
+		}
+	}
+
+	for _, metric := range c.collected {
+		ch <- metric
+	}
+}
+
+func (c *BusinessCollector) collect(ctx context.Context) ([]prometheus.Metric, error) {
+	vaults, err := c.vaultRepo.GetActiveVaults()
+	if err != nil {
+		return nil, err
+	}
+	users, err := c.userRepo.Count()
+	if err != nil {
+		return nil, err
+	}
+	flows, err := c.txRepo.GetFlows(time.Now().Add(-24*time.Hour), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	alerts, err := c.automationService.Alerts()
+	if err != nil {
+		return nil, err
+	}
+	subscriptions, rules, err := c.notificationRepo.CountTriggered()
+	if err != nil {
+		return nil, err
+	}
+
+	var collected []prometheus.Metric
+	gauge := func(desc *prometheus.Desc, value float64, labels ...string) {
+		collected = append(collected, prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, labels...))
+	}
+
+	var totalTVL, deposits, withdrawals float64
+	usdPrices := make(map[string]decimal.Decimal, len(vaults))
+	for _, vault := range vaults {
+		labels := []string{vault.Address, vault.Name, strconv.FormatUint(uint64(vault.ChainID), 10)}
+		gauge(metrics.VaultTVL, vault.TVL.InexactFloat64(), labels...)
+		gauge(metrics.VaultAPY, vault.APYCurrent, labels...)
+
+		price, err := c.priceService.GetPrice(ctx, vault.AssetAddress, vault.ChainID)
+		if err != nil {
+			continue
+		}
+		usd := decimal.NewFromFloat(price.USD)
+		usdPrices[vault.Address] = usd
+		tvl := vault.TVL.Mul(usd).InexactFloat64()
+		totalTVL += tvl
+		gauge(metrics.VaultTVLUSD, tvl, labels...)
+	}
+	for _, flow := range flows {
+		usd, ok := usdPrices[flow.VaultAddress]
+		if !ok {
+			continue
+		}
+		deposits += flow.Deposits.Mul(usd).InexactFloat64()
+		withdrawals += flow.Withdrawals.Mul(usd).InexactFloat64()
+	}
+
+	gauge(metrics.TotalTVLUSD, totalTVL)
+	gauge(metrics.Users, float64(users))
+	gauge(metrics.Volume24hUSD, deposits, "deposit")
+	gauge(metrics.Volume24hUSD, withdrawals, "withdraw")
+
+	open := make(map[[2]string]int)
+	for _, alert := range alerts {
+		open[[2]string{alert.Type, alert.Level}]++
+	}
+	for key, count := range open {
+		gauge(metrics.RiskAlertsOpen, float64(count), key[0], key[1])
+	}
+	gauge(metrics.UserAlertsTriggered, float64(subscriptions), "subscription")
+	gauge(metrics.UserAlertsTriggered, float64(rules), "rule")
+
+	return collected, nil
+}
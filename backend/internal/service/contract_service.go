@@ -0,0 +1,121 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/contracts"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+)
+
+var ErrContractNotFound = errors.New("contract not found")
+
+// ContractUpload 上传的合约登记，ABI为合约编译产物中的abi数组
+type ContractUpload struct {
+	ChainID         uint
+	Address         string
+	Interface       string
+	Name            string
+	ABI             json.RawMessage
+	DeploymentBlock uint64
+}
+
+// ContractService 管理合约ABI登记。回放按登记的ABI解码资金库事件、按部署区块确定默认起点，
+// 未登记的合约使用内置ABI
+type ContractService struct {
+	repo      *repository.ContractRepository
+	auditRepo *repository.AuditRepository
+}
+
+func NewContractService() *ContractService {
+	return &ContractService{
+		repo:      repository.NewContractRepository(),
+		auditRepo: repository.NewAuditRepository(),
+	}
+}
+
+// Register 校验ABI包含接口类型要求的方法和事件后登记，已登记的合约整体覆盖
+func (s *ContractService) Register(actor string, upload ContractUpload) (*models.Contract, error) {
+	if _, err := contracts.Parse(upload.Interface, string(upload.ABI)); err != nil {
+		return nil, err
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, upload.ABI); err != nil {
+		return nil, fmt.Errorf("%w: %v", contracts.ErrInvalidABI, err)
+	}
+
+	contract := &models.Contract{
+		ChainID:         upload.ChainID,
+		Address:         upload.Address,
+		Interface:       upload.Interface,
+		Name:            upload.Name,
+		ABI:             compact.Bytes(),
+		DeploymentBlock: upload.DeploymentBlock,
+		UploadedBy:      actor,
+	}
+	if err := s.repo.Upsert(contract); err != nil {
+		return nil, err
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"interface":        upload.Interface,
+		"name":             upload.Name,
+		"deployment_block": upload.DeploymentBlock,
+	})
+	if err := s.auditRepo.Create(&models.AuditLog{
+		Actor:   actor,
+		Action:  models.AuditContractSave,
+		Target:  contractTarget(upload.ChainID, upload.Address),
+		Details: details,
+	}); err != nil {
+		return nil, err
+	}
+	logger.Info(fmt.Sprintf("Contract %s on chain %d registered as %s by %s", upload.Address, upload.ChainID, upload.Interface, actor))
+	return s.repo.Get(upload.ChainID, upload.Address)
+}
+
+// Get 获取登记的合约及其ABI
+func (s *ContractService) Get(chainID uint, address string) (*models.Contract, error) {
+	contract, err := s.repo.Get(chainID, address)
+	if err != nil {
+		return nil, err
+	}
+	if contract == nil {
+		return nil, ErrContractNotFound
+	}
+	return contract, nil
+}
+
+// List 列出登记的合约，可按链和接口类型过滤
+func (s *ContractService) List(chainID uint, iface string) ([]models.Contract, error) {
+	return s.repo.List(chainID, iface)
+}
+
+// Remove 删除登记，之后该合约按内置ABI解码
+func (s *ContractService) Remove(actor string, chainID uint, address string) error {
+	deleted, err := s.repo.Delete(chainID, address)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrContractNotFound
+	}
+
+	if err := s.auditRepo.Create(&models.AuditLog{
+		Actor:  actor,
+		Action: models.AuditContractDel,
+		Target: contractTarget(chainID, address),
+	}); err != nil {
+		return err
+	}
+	logger.Info(fmt.Sprintf("Contract %s on chain %d removed by %s", address, chainID, actor))
+	return nil
+}
+
+func contractTarget(chainID uint, address string) string {
+	return fmt.Sprintf("%d:%s", chainID, address)
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/maker"
+
+	"github.com/shopspring/decimal"
+)
+
+// ProtocolMakerDSR 存入Maker DSR(持有sDAI)的策略的 protocol
+const ProtocolMakerDSR = "maker-dsr"
+
+// SavingsRate DSR策略的储蓄利率。APY 为当前DSR年化，可与Aave、Compound等浮动利率直接比较；
+// RealizedAPY 按 dsr.realized_window 内sDAI兑换率(未配置sDAI时为chi)的实际增长年化，记录不足时为空
+type SavingsRate struct {
+	APY                float64          `json:"apy"`
+	SDAIRate           *decimal.Decimal `json:"sdai_rate"`
+	RealizedAPY        *float64         `json:"realized_apy"`
+	RealizedWindowDays float64          `json:"realized_window_days"`
+	BlockNumber        uint64           `json:"block_number"`
+	UpdatedAt          time.Time        `json:"updated_at"`
+}
+
+// DSRService 定期读取各链的DSR和sDAI兑换率，更新 protocol 为 maker-dsr 的策略APY，
+// 再平衡时这类策略按DSR与同一资金库内的其他DAI策略比较
+type DSRService struct {
+	vaultRepo    repository.VaultRepo
+	strategyRepo repository.StrategyRepo
+	repo         *repository.DSRRepository
+}
+
+func NewDSRService() *DSRService {
+	return NewDSRServiceWith(repository.Default())
+}
+
+// NewDSRServiceWith 使用注入的核心仓储构建
+func NewDSRServiceWith(repos *repository.Repositories) *DSRService {
+	return &DSRService{
+		vaultRepo:    repos.Vaults,
+		strategyRepo: repos.Strategies,
+		repo:         repository.NewDSRRepository(),
+	}
+}
+
+// SyncAll 读取 dsr.networks 中每条链的DSR并记录，再把APY写入该链上所有活跃的DSR策略。单条链失败不影响其他链
+func (s *DSRService) SyncAll(ctx context.Context) error {
+	networks := config.Load().DSR.Networks
+	if len(networks) == 0 {
+		return nil
+	}
+	vaults, err := s.vaultRepo.GetActiveVaults()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	updated := 0
+	for _, network := range networks {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		rate, err := s.record(ctx, network)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("chain %d: %w", network.ChainID, err))
+			continue
+		}
+		for _, vault := range vaults {
+			if vault.ChainID != network.ChainID {
+				continue
+			}
+			changed := false
+			for _, st := range vault.Strategies {
+				if st.Protocol != ProtocolMakerDSR {
+					continue
+				}
+				if err := s.strategyRepo.UpdateAPY(st.Address, rate.APY); err != nil {
+					return err
+				}
+				changed = true
+				updated++
+			}
+			if changed {
+				InvalidateVault(ctx, vault.Address)
+			}
+		}
+	}
+	logger.Info(fmt.Sprintf("Synced DSR on %d chain(s), updated %d strategy APY(s), %d failed", len(networks), updated, len(errs)))
+	return errors.Join(errs...)
+}
+
+func (s *DSRService) record(ctx context.Context, network config.DSRNetwork) (*models.DSRRate, error) {
+	savings, err := maker.Read(ctx, network.ChainID, network.Pot, network.SDAI)
+	if err != nil {
+		return nil, err
+	}
+	rate := &models.DSRRate{
+		ChainID:     network.ChainID,
+		APY:         savings.APY(),
+		Chi:         decimal.NewFromBigInt(savings.Chi, -27),
+		BlockNumber: savings.BlockNumber,
+		Timestamp:   time.Now(),
+	}
+	if savings.SDAIRate != nil {
+		sdaiRate := decimal.NewFromBigInt(savings.SDAIRate, -18)
+		rate.SDAIRate = &sdaiRate
+	}
+	if err := s.repo.Create(rate); err != nil {
+		return nil, err
+	}
+	return rate, nil
+}
+
+// Status DSR策略当前的储蓄利率和实际收益率，非DSR策略或所在链尚无读取记录时返回nil
+func (s *DSRService) Status(strategy *models.Strategy) (*SavingsRate, error) {
+	if strategy.Protocol != ProtocolMakerDSR {
+		return nil, nil
+	}
+	vault, err := s.vaultRepo.GetByAddress(strategy.VaultAddress)
+	if err != nil || vault == nil {
+		return nil, err
+	}
+	latest, err := s.repo.Latest(vault.ChainID)
+	if err != nil || latest == nil {
+		return nil, err
+	}
+	start, err := s.repo.FirstSince(vault.ChainID, latest.Timestamp.AddDate(0, 0, -config.Load().DSR.RealizedWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SavingsRate{
+		APY:         latest.APY,
+		SDAIRate:    latest.SDAIRate,
+		BlockNumber: latest.BlockNumber,
+		UpdatedAt:   latest.Timestamp,
+	}
+	if start == nil || start.ID == latest.ID {
+		return result, nil
+	}
+	elapsed := latest.Timestamp.Sub(start.Timestamp)
+	if elapsed < time.Hour {
+		return result, nil
+	}
+
+	// chi只在drip时更新，sDAI兑换率按区块时间计息，两端都有时优先用sDAI
+	before, after := start.Chi, latest.Chi
+	if start.SDAIRate != nil && latest.SDAIRate != nil {
+		before, after = *start.SDAIRate, *latest.SDAIRate
+	}
+	if !before.IsPositive() {
+		return result, nil
+	}
+	growth := after.Div(before).InexactFloat64()
+	realized := math.Pow(growth, 365*24*float64(time.Hour)/float64(elapsed)) - 1
+	result.RealizedAPY = &realized
+	result.RealizedWindowDays = elapsed.Hours() / 24
+	return result, nil
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/events"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/notify"
+)
+
+type EventService struct {
+	txRepo       repository.TxRepo
+	harvestRepo  *repository.HarvestRepository
+	strategyRepo repository.StrategyRepo
+	timeline     *repository.VaultEventRepository
+	feeService   *FeeService
+	userRepo     repository.UserRepo
+	vaultService *VaultService
+	priceHistory *PriceHistoryService
+	notifier     *NotificationService
+	alerts       *OperatorAlertService
+	replay       bool
+}
+
+func NewEventService() *EventService {
+	return NewEventServiceWith(repository.Default())
+}
+
+// NewEventServiceWith 使用注入的核心仓储构建
+func NewEventServiceWith(repos *repository.Repositories) *EventService {
+	return &EventService{
+		txRepo:       repos.Transactions,
+		harvestRepo:  repository.NewHarvestRepository(),
+		strategyRepo: repos.Strategies,
+		timeline:     repository.NewVaultEventRepository(),
+		feeService:   NewFeeServiceWith(repos),
+		userRepo:     repos.Users,
+		vaultService: NewVaultServiceWith(repos),
+		priceHistory: NewPriceHistoryService(),
+		notifier:     NewNotificationServiceWith(repos),
+		alerts:       NewOperatorAlertServiceWith(repos),
+	}
+}
+
+// Replay 返回用于历史数据回放的副本：只物化数据，不发送用户通知和运维告警，也不推进索引进度
+func (s *EventService) Replay() *EventService {
+	replay := *s
+	replay.replay = true
+	return &replay
+}
+
+// Apply 将链上事件物化为交易、持仓和资金库统计，重复投递的事件不会重复计入
+func (s *EventService) Apply(ctx context.Context, event *events.ChainEvent) error {
+	// 索引器和回放解码出的地址是EIP-55校验和格式，数据库统一存小写
+	event.Vault = strings.ToLower(event.Vault)
+	event.User = strings.ToLower(event.User)
+
+	var err error
+	switch event.Type {
+	case events.TypeDeposit, events.TypeWithdraw:
+		err = s.applyTransfer(ctx, event)
+	case events.TypeHarvest:
+		err = s.applyHarvest(ctx, event)
+	case events.TypeStrategyAdded, events.TypeStrategyRemoved:
+		err = s.applyStrategyChange(ctx, event)
+	case events.TypeVaultStats:
+		err = s.vaultService.UpdateVaultStats(event.Vault, event.TVL, event.APYCurrent, event.APYWeekly)
+	default:
+		return fmt.Errorf("%w: unknown type %q", events.ErrInvalidEvent, event.Type)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !s.replay {
+		recordIndexerProgress(ctx, event)
+	}
+	return nil
+}
+
+// applyHarvest 记录收获、累加到资金库当前策略的收益并计提费用
+func (s *EventService) applyHarvest(ctx context.Context, event *events.ChainEvent) error {
+	harvest := &models.Harvest{
+		VaultAddress: event.Vault,
+		ChainID:      event.ChainID,
+		Amount:       event.Assets,
+		TxHash:       event.TxHash,
+		LogIndex:     event.LogIndex,
+		BlockNumber:  event.BlockNumber,
+		HarvestedAt:  event.Timestamp,
+	}
+	if harvest.HarvestedAt.IsZero() {
+		harvest.HarvestedAt = time.Now()
+	}
+
+	vault, err := s.vaultService.GetVaultDetail(ctx, event.Vault)
+	if err != nil {
+		return err
+	}
+	var fees []models.FeeAccrual
+	if vault != nil {
+		harvest.StrategyAddress = vault.StrategyAddress
+		if fees, err = s.feeService.HarvestFees(vault, harvest); err != nil {
+			return err
+		}
+	}
+
+	if event.Strategy != "" {
+		harvest.StrategyAddress = strings.ToLower(event.Strategy)
+	}
+
+	applied, err := s.harvestRepo.Record(harvest, fees)
+	if err != nil {
+		return err
+	}
+	// 时间线按日志位置去重，收获已记录但时间线写入失败后重试时仍会补上
+	details := map[string]interface{}{
+		"amount":   harvest.Amount,
+		"strategy": harvest.StrategyAddress,
+	}
+	if err := s.recordChainEvent(event, models.VaultEventHarvest, details); err != nil {
+		return err
+	}
+	if !applied {
+		logger.Info(fmt.Sprintf("Harvest %s#%d already applied, skipping", event.TxHash, event.LogIndex))
+		return nil
+	}
+	InvalidateVault(ctx, event.Vault)
+	return nil
+}
+
+// applyStrategyChange 按链上事件启用或停用策略并记入时间线
+func (s *EventService) applyStrategyChange(ctx context.Context, event *events.ChainEvent) error {
+	strategy := strings.ToLower(event.Strategy)
+	added := event.Type == events.TypeStrategyAdded
+	if err := s.strategyRepo.SetActive(event.Vault, strategy, added); err != nil {
+		return err
+	}
+
+	eventType := models.VaultEventStrategyRemoved
+	if added {
+		eventType = models.VaultEventStrategyAdded
+	}
+	if err := s.recordChainEvent(event, eventType, map[string]interface{}{"strategy": strategy}); err != nil {
+		return err
+	}
+	InvalidateVault(ctx, event.Vault)
+	return nil
+}
+
+// recordChainEvent 把链上事件写入资金库时间线
+func (s *EventService) recordChainEvent(event *events.ChainEvent, eventType string, details map[string]interface{}) error {
+	raw, _ := json.Marshal(details)
+	return s.timeline.Record(&models.VaultEvent{
+		VaultAddress: event.Vault,
+		Type:         eventType,
+		TxHash:       event.TxHash,
+		LogIndex:     event.LogIndex,
+		Details:      raw,
+		OccurredAt:   event.Timestamp,
+	})
+}
+
+func (s *EventService) applyTransfer(ctx context.Context, event *events.ChainEvent) error {
+	if _, err := s.userRepo.GetOrCreate(event.User); err != nil {
+		return err
+	}
+
+	transaction := &models.Transaction{
+		UserAddress:  event.User,
+		VaultAddress: event.Vault,
+		Type:         event.Type,
+		Amount:       event.Assets,
+		Shares:       event.Shares,
+		TxHash:       event.TxHash,
+		LogIndex:     &event.LogIndex,
+		BlockNumber:  event.BlockNumber,
+	}
+	if !event.Timestamp.IsZero() {
+		transaction.CreatedAt = event.Timestamp
+	}
+
+	applied, err := s.txRepo.ConfirmTransfer(transaction)
+	if err != nil {
+		return err
+	}
+	if !applied {
+		logger.Info(fmt.Sprintf("Event %s#%d already applied, skipping", event.TxHash, event.LogIndex))
+		return nil
+	}
+
+	InvalidateVault(ctx, event.Vault)
+	if s.replay {
+		return nil
+	}
+	if vault, err := s.vaultService.GetVaultDetail(ctx, event.Vault); err == nil && vault != nil {
+		s.priceHistory.RecordForTransaction(ctx, vault.AssetAddress, vault.ChainID)
+		if event.Type == events.TypeWithdraw {
+			s.alerts.CheckWithdrawal(ctx, vault, transaction)
+		}
+	}
+
+	notifyEvent, verb := models.NotifyDepositConfirmed, "Deposit"
+	if event.Type == events.TypeWithdraw {
+		notifyEvent, verb = models.NotifyWithdrawConfirmed, "Withdrawal"
+	}
+	s.notifier.NotifyUser(ctx, event.User, notifyEvent, event.Vault, notify.Message{
+		Subject: verb + " confirmed",
+		Body: fmt.Sprintf("%s of %s in vault %s was confirmed in block %d (tx %s).",
+			verb, event.Assets.String(), event.Vault, event.BlockNumber, event.TxHash),
+	})
+	return nil
+}
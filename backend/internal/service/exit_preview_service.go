@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/prices"
+	"github.com/chspring1/mya-platform/backend/pkg/units"
+
+	"github.com/shopspring/decimal"
+)
+
+// WithdrawPreview 赎回份额的退出成本。Assets 为扣除退出费用后的预期资产，GrossAssets 为不含费用的价值，
+// 两者之差即退出费用；管理费和业绩费在收获时从份额价格中计提，赎回时不再扣除，只作展示
+type WithdrawPreview struct {
+	*Preview
+	GrossAssets       decimal.Decimal `json:"gross_assets"`
+	GrossAssetsWei    string          `json:"gross_assets_wei"`
+	ExitFee           decimal.Decimal `json:"exit_fee"`
+	ExitFeeWei        string          `json:"exit_fee_wei"`
+	ExitFeeBps        float64         `json:"exit_fee_bps"`
+	ManagementFeeBps  uint16          `json:"management_fee_bps"`
+	PerformanceFeeBps uint16          `json:"performance_fee_bps"`
+	SlippageBps       uint16          `json:"slippage_bps"`
+	MinAssets         decimal.Decimal `json:"min_assets"` // 按滑点扣除后最少可得的资产，执行时低于该值应回滚
+	MinAssetsWei      string          `json:"min_assets_wei"`
+	AssetsUSD         *float64        `json:"assets_usd"`
+	Gas               *GasEstimate    `json:"gas"`
+	NetUSD            *float64        `json:"net_usd"` // 预期资产扣除gas成本后的价值，任一价格不可用时为空
+}
+
+// ExitPreviewService 汇总赎回的预期资产、退出费用、gas成本和滑点下限
+type ExitPreviewService struct {
+	previews *PreviewService
+	gas      *GasService
+	prices   *prices.Service
+}
+
+func NewExitPreviewService() *ExitPreviewService {
+	return NewExitPreviewServiceWith(repository.Default())
+}
+
+// NewExitPreviewServiceWith 使用注入的核心仓储构建
+func NewExitPreviewServiceWith(repos *repository.Repositories) *ExitPreviewService {
+	return &ExitPreviewService{
+		previews: NewPreviewService(),
+		gas:      NewGasServiceWith(repos),
+		prices:   prices.Default(),
+	}
+}
+
+// Preview 预览赎回shares的退出成本，slippageBps 为0时使用 slippage.default_bps
+func (s *ExitPreviewService) Preview(ctx context.Context, vault *models.Vault, shares decimal.Decimal, slippageBps uint16) (*WithdrawPreview, error) {
+	cfg := config.Load()
+	if slippageBps == 0 {
+		slippageBps = cfg.Slippage.DefaultBps
+	}
+	if slippageBps > cfg.Slippage.MaxBps {
+		return nil, ErrInvalidSlippage
+	}
+
+	preview, gross, err := s.previews.RedeemWithFee(ctx, vault, shares)
+	if err != nil {
+		return nil, err
+	}
+	assetDecimals, err := blockchain.TokenDecimals(ctx, vault.ChainID, vault.AssetAddress)
+	if err != nil {
+		return nil, fmt.Errorf("decimals of %s: %w", vault.AssetAddress, err)
+	}
+	assets, _ := units.ParseBase(preview.AssetsWei)
+
+	// 舍入可能使 previewRedeem 略高于 convertToAssets，此时视为没有退出费用
+	fee := new(big.Int).Sub(gross, assets)
+	if fee.Sign() < 0 {
+		fee.SetInt64(0)
+	}
+	minAssets := new(big.Int).Mul(assets, big.NewInt(int64(10000-int(slippageBps))))
+	minAssets.Quo(minAssets, big.NewInt(10000))
+
+	result := &WithdrawPreview{
+		Preview:           preview,
+		GrossAssets:       units.FromBase(gross, assetDecimals),
+		GrossAssetsWei:    gross.String(),
+		ExitFee:           units.FromBase(fee, assetDecimals),
+		ExitFeeWei:        fee.String(),
+		ManagementFeeBps:  vault.ManagementFeeBps,
+		PerformanceFeeBps: vault.PerformanceFeeBps,
+		SlippageBps:       slippageBps,
+		MinAssets:         units.FromBase(minAssets, assetDecimals),
+		MinAssetsWei:      minAssets.String(),
+		Gas:               s.gas.Estimate(ctx, vault.ChainID, cfg.Gas.WithdrawGas),
+	}
+	if gross.Sign() > 0 {
+		result.ExitFeeBps, _ = new(big.Float).Quo(new(big.Float).SetInt(new(big.Int).Mul(fee, big.NewInt(10000))), new(big.Float).SetInt(gross)).Float64()
+	}
+
+	usd, err := s.prices.ToUSD(ctx, vault.AssetAddress, vault.ChainID, preview.Assets.InexactFloat64())
+	if err != nil {
+		logger.Info(fmt.Sprintf("Failed to price asset of vault %s: %v", vault.Address, err))
+		return result, nil
+	}
+	result.AssetsUSD = &usd
+	if result.Gas.CostUSD != nil {
+		net := usd - *result.Gas.CostUSD
+		result.NetUSD = &net
+	}
+	return result, nil
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/shopspring/decimal"
+)
+
+// 费率上限，防止误操作把收益全部计为费用
+const (
+	MaxManagementFeeBps  = 500  // 5%/年
+	MaxPerformanceFeeBps = 5000 // 收益的50%
+)
+
+var (
+	ErrInvalidFees   = fmt.Errorf("management fee must be at most %d bps and performance fee at most %d bps", MaxManagementFeeBps, MaxPerformanceFeeBps)
+	ErrInvalidPeriod = errors.New("period must be one of day, week, month")
+)
+
+// Revenue 协议费用收入报表
+type Revenue struct {
+	From     time.Time                  `json:"from"`
+	To       time.Time                  `json:"to"`
+	Period   string                     `json:"period"`
+	Totals   repository.FeeTotals       `json:"totals"`
+	ByVault  []repository.VaultRevenue  `json:"by_vault"`
+	ByPeriod []repository.PeriodRevenue `json:"by_period"`
+}
+
+type FeeService struct {
+	vaultRepo   repository.VaultRepo
+	harvestRepo *repository.HarvestRepository
+	shareRepo   *repository.SharePriceRepository
+	feeRepo     *repository.FeeRepository
+	auditRepo   *repository.AuditRepository
+	timeline    *repository.VaultEventRepository
+}
+
+func NewFeeService() *FeeService {
+	return NewFeeServiceWith(repository.Default())
+}
+
+// NewFeeServiceWith 使用注入的核心仓储构建
+func NewFeeServiceWith(repos *repository.Repositories) *FeeService {
+	return &FeeService{
+		vaultRepo:   repos.Vaults,
+		harvestRepo: repository.NewHarvestRepository(),
+		shareRepo:   repository.NewSharePriceRepository(),
+		feeRepo:     repository.NewFeeRepository(),
+		auditRepo:   repository.NewAuditRepository(),
+		timeline:    repository.NewVaultEventRepository(),
+	}
+}
+
+// HarvestFees 计算一次收获应计提的费用，由调用方随收获记录一起写入。
+// 管理费覆盖上次收获(没有时为资金库创建时间)到本次收获的区间，资产规模取收获时刻的份额价格记录，
+// 没有记录时使用当前TVL
+func (s *FeeService) HarvestFees(vault *models.Vault, harvest *models.Harvest) ([]models.FeeAccrual, error) {
+	var fees []models.FeeAccrual
+
+	if vault.PerformanceFeeBps > 0 && harvest.Amount.IsPositive() {
+		fees = append(fees, models.FeeAccrual{
+			VaultAddress: vault.Address,
+			Type:         models.FeePerformance,
+			FeeBps:       vault.PerformanceFeeBps,
+			Base:         harvest.Amount,
+			Amount:       bpsOf(harvest.Amount, vault.PerformanceFeeBps),
+			PeriodStart:  harvest.HarvestedAt,
+			PeriodEnd:    harvest.HarvestedAt,
+		})
+	}
+
+	if vault.ManagementFeeBps > 0 {
+		start := vault.CreatedAt
+		previous, err := s.harvestRepo.PreviousHarvestAt(vault.Address, harvest.HarvestedAt)
+		if err != nil {
+			return nil, err
+		}
+		if previous != nil {
+			start = *previous
+		}
+
+		assets := vault.TVL
+		price, err := s.shareRepo.GetAt(vault.Address, harvest.HarvestedAt)
+		if err != nil {
+			return nil, err
+		}
+		if price != nil {
+			assets = price.TotalAssets
+		}
+
+		if elapsed := harvest.HarvestedAt.Sub(start); elapsed > 0 && assets.IsPositive() {
+			fraction := decimal.NewFromFloat(elapsed.Seconds()).Div(decimal.NewFromInt(secondsPerYear))
+			fees = append(fees, models.FeeAccrual{
+				VaultAddress: vault.Address,
+				Type:         models.FeeManagement,
+				FeeBps:       vault.ManagementFeeBps,
+				Base:         assets,
+				Amount:       bpsOf(assets, vault.ManagementFeeBps).Mul(fraction).Round(18),
+				PeriodStart:  start,
+				PeriodEnd:    harvest.HarvestedAt,
+			})
+		}
+	}
+	return fees, nil
+}
+
+func bpsOf(amount decimal.Decimal, bps uint16) decimal.Decimal {
+	return amount.Mul(decimal.NewFromInt(int64(bps))).Div(decimal.NewFromInt(10000))
+}
+
+// SetFees 修改资金库费率并写入审计日志，只影响之后的收获
+func (s *FeeService) SetFees(ctx context.Context, vault *models.Vault, actor string, managementBps, performanceBps uint16, reason string) (*models.Vault, error) {
+	if managementBps > MaxManagementFeeBps || performanceBps > MaxPerformanceFeeBps {
+		return nil, ErrInvalidFees
+	}
+	if vault.ManagementFeeBps == managementBps && vault.PerformanceFeeBps == performanceBps {
+		return vault, nil
+	}
+
+	if _, err := s.vaultRepo.SetFees(vault.Address, managementBps, performanceBps); err != nil {
+		return nil, err
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"reason":                       reason,
+		"previous_management_fee_bps":  vault.ManagementFeeBps,
+		"previous_performance_fee_bps": vault.PerformanceFeeBps,
+		"management_fee_bps":           managementBps,
+		"performance_fee_bps":          performanceBps,
+	})
+	if err := s.auditRepo.Create(&models.AuditLog{
+		Actor:   actor,
+		Action:  models.AuditSetVaultFees,
+		Target:  vault.Address,
+		Details: details,
+	}); err != nil {
+		return nil, err
+	}
+	if err := s.timeline.Record(&models.VaultEvent{
+		VaultAddress: vault.Address,
+		Type:         models.VaultEventFeeChange,
+		Actor:        actor,
+		Details:      details,
+	}); err != nil {
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("Vault %s fees changed to %d/%d bps by %s: %s", vault.Address, managementBps, performanceBps, actor, reason))
+	vault.ManagementFeeBps = managementBps
+	vault.PerformanceFeeBps = performanceBps
+	InvalidateVault(ctx, vault.Address)
+	return vault, nil
+}
+
+// Revenue 统计 [from, to) 内的协议费用收入，按资金库和周期拆分
+func (s *FeeService) Revenue(from, to time.Time, period string) (*Revenue, error) {
+	switch period {
+	case "day", "week", "month":
+	default:
+		return nil, ErrInvalidPeriod
+	}
+
+	totals, err := s.feeRepo.Totals(from, to)
+	if err != nil {
+		return nil, err
+	}
+	byVault, err := s.feeRepo.ByVault(from, to)
+	if err != nil {
+		return nil, err
+	}
+	byPeriod, err := s.feeRepo.ByPeriod(from, to, period)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Revenue{
+		From:     from,
+		To:       to,
+		Period:   period,
+		Totals:   totals,
+		ByVault:  byVault,
+		ByPeriod: byPeriod,
+	}, nil
+}
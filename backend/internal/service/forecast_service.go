@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/forecast"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+)
+
+// 预测天数
+const forecastHorizon = 7
+
+// 最后一天的日汇总早于该天数时视为数据中断，不再外推
+const forecastMaxStaleDays = 2
+
+// ForecastDisclaimer 随预测一起返回的说明
+const ForecastDisclaimer = "Statistical estimate extrapolated from historical APY; not a guarantee of future returns."
+
+// ForecastPoint 某一天的预测APY及置信区间，APY不会为负，下界截断为0
+type ForecastPoint struct {
+	Date  string  `json:"date"`
+	APY   float64 `json:"apy"`
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+// APYForecastView 对外返回的预测，始终标注为估计
+type APYForecastView struct {
+	*models.APYForecast
+	Estimate   bool   `json:"estimate"`
+	Disclaimer string `json:"disclaimer"`
+}
+
+// ForecastSummary 对比接口中的第7天预测
+type ForecastSummary struct {
+	APY        float64 `json:"apy"`
+	Lower      float64 `json:"lower"`
+	Upper      float64 `json:"upper"`
+	Confidence float64 `json:"confidence"`
+	Estimate   bool    `json:"estimate"`
+}
+
+type ForecastService struct {
+	repo      *repository.ForecastRepository
+	apyRepo   *repository.APYHistoryRepository
+	vaultRepo repository.VaultRepo
+	cfg       config.ForecastConfig
+}
+
+func NewForecastService() *ForecastService {
+	return NewForecastServiceWith(repository.Default())
+}
+
+// NewForecastServiceWith 使用注入的核心仓储构建
+func NewForecastServiceWith(repos *repository.Repositories) *ForecastService {
+	return &ForecastService{
+		repo:      repository.NewForecastRepository(),
+		apyRepo:   repository.NewAPYHistoryRepository(),
+		vaultRepo: repos.Vaults,
+		cfg:       config.Load().Forecast,
+	}
+}
+
+// Run 按日汇总APY为每个活跃资金库预测之后7天的APY，数据不足或已中断的资金库删除旧的预测
+func (s *ForecastService) Run(ctx context.Context) error {
+	vaults, err := s.vaultRepo.GetActiveVaults()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	forecasted := 0
+	for _, vault := range vaults {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		days, err := s.apyRepo.GetDaily(vault.Address, today.AddDate(0, 0, -s.cfg.LookbackDays), today)
+		if err != nil {
+			return err
+		}
+		result, err := s.fit(vault.Address, days, today, now)
+		if errors.Is(err, forecast.ErrInsufficientData) {
+			if err := s.repo.Delete(vault.Address); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.repo.Save(result); err != nil {
+			return err
+		}
+		forecasted++
+	}
+
+	InvalidateAPYData(ctx)
+	logger.Info(fmt.Sprintf("📈 APY forecast updated for %d of %d vault(s)", forecasted, len(vaults)))
+	return nil
+}
+
+// fit 拟合一个资金库的日汇总序列，最后一天早于 forecastMaxStaleDays 时按数据不足处理
+func (s *ForecastService) fit(vault string, days []models.APYDaily, today, now time.Time) (*models.APYForecast, error) {
+	if len(days) == 0 || days[len(days)-1].Day.Before(today.AddDate(0, 0, -forecastMaxStaleDays)) {
+		return nil, forecast.ErrInsufficientData
+	}
+	series := make([]float64, len(days))
+	for i, day := range days {
+		series[i] = day.AvgAPY
+	}
+	fit, points, err := forecast.EWMA(series, s.cfg.Alpha, forecastHorizon, s.cfg.Confidence)
+	if err != nil {
+		return nil, err
+	}
+
+	last := days[len(days)-1].Day
+	out := make([]ForecastPoint, len(points))
+	for i, p := range points {
+		out[i] = ForecastPoint{
+			Date:  last.AddDate(0, 0, p.Step).Format(time.DateOnly),
+			APY:   math.Max(p.Mean, 0),
+			Lower: math.Max(p.Lower, 0),
+			Upper: math.Max(p.Upper, 0),
+		}
+	}
+	raw, err := json.Marshal(out)
+	if err != nil {
+		return nil, err
+	}
+	return &models.APYForecast{
+		VaultAddress: vault,
+		Method:       models.ForecastEWMA,
+		Alpha:        s.cfg.Alpha,
+		Confidence:   s.cfg.Confidence,
+		Samples:      fit.Samples,
+		Sigma:        fit.Sigma,
+		Points:       raw,
+		GeneratedAt:  now,
+	}, nil
+}
+
+// Get 获取资金库的预测，没有时返回nil
+func (s *ForecastService) Get(vaultAddress string) (*APYForecastView, error) {
+	result, err := s.repo.GetByVault(vaultAddress)
+	if err != nil || result == nil {
+		return nil, err
+	}
+	return &APYForecastView{APYForecast: result, Estimate: true, Disclaimer: ForecastDisclaimer}, nil
+}
+
+// Summaries 各资金库最后一天的预测，按资金库地址索引
+func (s *ForecastService) Summaries() (map[string]*ForecastSummary, error) {
+	forecasts, err := s.repo.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	summaries := make(map[string]*ForecastSummary, len(forecasts))
+	for _, f := range forecasts {
+		var points []ForecastPoint
+		if err := json.Unmarshal(f.Points, &points); err != nil || len(points) == 0 {
+			continue
+		}
+		last := points[len(points)-1]
+		summaries[f.VaultAddress] = &ForecastSummary{
+			APY:        last.APY,
+			Lower:      last.Lower,
+			Upper:      last.Upper,
+			Confidence: f.Confidence,
+			Estimate:   true,
+		}
+	}
+	return summaries, nil
+}
@@ -0,0 +1,40 @@
+package service
+
+// 以下方法将USD计价字段按汇率换算后写入Fiat，原USD字段保持不变
+
+func scaleUSD(rate float64, values map[string]*float64) map[string]float64 {
+	converted := make(map[string]float64, len(values))
+	for name, v := range values {
+		if v != nil {
+			converted[name] = *v * rate
+		}
+	}
+	return converted
+}
+
+// ApplyFX 换算资金库TVL与资产价格
+func (v *VaultView) ApplyFX(rate float64) {
+	v.Fiat = scaleUSD(rate, map[string]*float64{
+		"tvl":         v.TVLUSD,
+		"asset_price": v.AssetPriceUSD,
+	})
+}
+
+// ApplyFX 换算持仓价值、成本与盈亏
+func (p *Position) ApplyFX(rate float64) {
+	p.Fiat = scaleUSD(rate, map[string]*float64{
+		"value":      p.ValueUSD,
+		"cost_basis": p.CostBasisUSD,
+		"pnl":        p.PnLUSD,
+	})
+}
+
+// ApplyFX 换算平台汇总金额
+func (s *SystemStats) ApplyFX(rate float64) {
+	s.Fiat = scaleUSD(rate, map[string]*float64{
+		"total_tvl":         &s.TotalTVL,
+		"total_deposits":    &s.TotalDeposits,
+		"total_withdrawals": &s.TotalWithdrawals,
+		"total_yield":       &s.TotalYield,
+	})
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/cache"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/prices"
+)
+
+// ChainGasCost 同一资产在某条链上的资金库及其存取gas成本。
+// BreakEvenDeposit 为在给定天数内收益恰好覆盖gas成本的存款规模(USD)，成本或APY未知时为空
+type ChainGasCost struct {
+	Vault            string   `json:"vault"`
+	Name             string   `json:"name"`
+	ChainID          uint     `json:"chain_id"`
+	APY              float64  `json:"apy"`
+	GasPriceGwei     *float64 `json:"gas_price_gwei"`
+	RoundTripGas     uint64   `json:"round_trip_gas"`
+	RoundTripCostUSD *float64 `json:"round_trip_cost_usd"`
+	BreakEvenDeposit *float64 `json:"break_even_deposit_usd"`
+	Cheapest         bool     `json:"cheapest"`
+}
+
+// GasComparison 同一资产在各链上资金库的gas成本比较
+type GasComparison struct {
+	Asset   string         `json:"asset"` // 资产的CoinGecko ID，未配置价格的资产只比较自身
+	Days    int            `json:"days"`
+	Options []ChainGasCost `json:"options"`
+}
+
+// GasEstimate 按当前gas价格估算一笔交易的成本，价格或原生代币价格不可用时对应字段为空
+type GasEstimate struct {
+	Gas          uint64   `json:"gas"`
+	GasPriceGwei *float64 `json:"gas_price_gwei"`
+	CostNative   *float64 `json:"cost_native"`
+	CostUSD      *float64 `json:"cost_usd"`
+}
+
+type GasService struct {
+	vaultService *VaultService
+	prices       *prices.Service
+}
+
+func NewGasService() *GasService {
+	return NewGasServiceWith(repository.Default())
+}
+
+// NewGasServiceWith 使用注入的核心仓储构建
+func NewGasServiceWith(repos *repository.Repositories) *GasService {
+	return &GasService{
+		vaultService: NewVaultServiceWith(repos),
+		prices:       prices.Default(),
+	}
+}
+
+// Compare 找出与vault底层资产相同(按价格配置中的CoinGecko ID识别)的所有活跃资金库，
+// 估算各链一次完整存取的gas成本，以及持有days天后收益覆盖成本所需的存款规模，按成本升序排列
+func (s *GasService) Compare(ctx context.Context, vault *models.Vault, days int) (*GasComparison, error) {
+	vaults, err := s.vaultService.GetVaults(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	asset := ""
+	if token, ok := s.prices.Token(vault.ChainID, vault.AssetAddress); ok {
+		asset = token.CoinGeckoID
+	}
+	equivalent := []models.Vault{*vault}
+	if asset != "" {
+		for _, other := range vaults {
+			if other.Address == vault.Address || !other.IsActive {
+				continue
+			}
+			if token, ok := s.prices.Token(other.ChainID, other.AssetAddress); ok && token.CoinGeckoID == asset {
+				equivalent = append(equivalent, other)
+			}
+		}
+	}
+
+	cfg := config.Load().Gas
+	costs := make(map[uint]*float64)
+	gwei := make(map[uint]*float64)
+	result := &GasComparison{Asset: asset, Days: days}
+	for _, v := range equivalent {
+		if _, ok := costs[v.ChainID]; !ok {
+			gwei[v.ChainID], costs[v.ChainID] = s.roundTripCost(ctx, cfg, v.ChainID)
+		}
+		option := ChainGasCost{
+			Vault:            v.Address,
+			Name:             v.Name,
+			ChainID:          v.ChainID,
+			APY:              v.APYCurrent,
+			GasPriceGwei:     gwei[v.ChainID],
+			RoundTripGas:     cfg.RoundTripGas,
+			RoundTripCostUSD: costs[v.ChainID],
+		}
+		if cost := costs[v.ChainID]; cost != nil && v.APYCurrent > 0 && days > 0 {
+			breakEven := *cost / (v.APYCurrent * float64(days) / 365)
+			option.BreakEvenDeposit = &breakEven
+		}
+		result.Options = append(result.Options, option)
+	}
+
+	// 成本未知的排在最后
+	sort.SliceStable(result.Options, func(i, j int) bool {
+		a, b := result.Options[i].RoundTripCostUSD, result.Options[j].RoundTripCostUSD
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return *a < *b
+	})
+	if len(result.Options) > 0 && result.Options[0].RoundTripCostUSD != nil {
+		result.Options[0].Cheapest = true
+	}
+	return result, nil
+}
+
+// roundTripCost 按链上gas价格和原生代币价格估算一次完整存取的USD成本，任一数据不可用时返回nil
+func (s *GasService) roundTripCost(ctx context.Context, cfg config.GasConfig, chainID uint) (*float64, *float64) {
+	estimate := s.estimate(ctx, cfg, chainID, cfg.RoundTripGas)
+	return estimate.GasPriceGwei, estimate.CostUSD
+}
+
+// Estimate 估算在chainID上消耗gas的交易按当前gas价格的原生代币和USD成本
+func (s *GasService) Estimate(ctx context.Context, chainID uint, gas uint64) *GasEstimate {
+	return s.estimate(ctx, config.Load().Gas, chainID, gas)
+}
+
+func (s *GasService) estimate(ctx context.Context, cfg config.GasConfig, chainID uint, gas uint64) *GasEstimate {
+	estimate := &GasEstimate{Gas: gas}
+	price, err := s.gasPrice(ctx, cfg, chainID)
+	if err != nil {
+		logger.Info(fmt.Sprintf("Failed to get gas price on chain %d: %v", chainID, err))
+		return estimate
+	}
+	gwei, _ := new(big.Float).Quo(new(big.Float).SetInt(price), big.NewFloat(1e9)).Float64()
+	nativeCost := gwei * float64(gas) / 1e9
+	estimate.GasPriceGwei = &gwei
+	estimate.CostNative = &nativeCost
+
+	native := nativeToken(cfg, chainID)
+	if native == "" {
+		return estimate
+	}
+	usd, err := s.prices.ToUSD(ctx, native, chainID, nativeCost)
+	if err != nil {
+		logger.Info(fmt.Sprintf("Failed to price native token on chain %d: %v", chainID, err))
+		return estimate
+	}
+	estimate.CostUSD = &usd
+	return estimate
+}
+
+// gasPrice 读取并缓存链上的建议gas价格
+func (s *GasService) gasPrice(ctx context.Context, cfg config.GasConfig, chainID uint) (*big.Int, error) {
+	key := fmt.Sprintf("gas:price:%d", chainID)
+	var cached string
+	if cache.GetJSON(ctx, key, &cached) {
+		if price, ok := new(big.Int).SetString(cached, 10); ok {
+			return price, nil
+		}
+	}
+
+	price, err := blockchain.GasPrice(ctx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	cache.SetJSON(ctx, key, price.String(), time.Duration(cfg.CacheTTL)*time.Second)
+	return price, nil
+}
+
+// nativeToken 返回用于给链上原生代币计价的代币地址，未配置时返回空字符串
+func nativeToken(cfg config.GasConfig, chainID uint) string {
+	for _, chain := range cfg.Chains {
+		if chain.ChainID == chainID {
+			return chain.NativeToken
+		}
+	}
+	return ""
+}
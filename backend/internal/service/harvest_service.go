@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/prices"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/shopspring/decimal"
+)
+
+// 每轮补齐gas的收获数量上限
+const harvestGasBatch = 200
+
+// 取不到回执的次数上限，达到后不再补齐该收获的gas
+const harvestGasAttempts = 10
+
+// HarvestRecord 收获记录及其计提的费用，Net 为扣除费用后归属存款人的收益
+type HarvestRecord struct {
+	models.Harvest
+	ManagementFee  decimal.Decimal `json:"management_fee"`
+	PerformanceFee decimal.Decimal `json:"performance_fee"`
+	Net            decimal.Decimal `json:"net"`
+}
+
+// StrategyAttribution 单个策略对资金库收益的贡献，Share 为占资金库总收益的比例
+type StrategyAttribution struct {
+	repository.StrategyYield
+	Name  string          `json:"name,omitempty"`
+	Net   decimal.Decimal `json:"net"`
+	Share float64         `json:"share"`
+}
+
+// HarvestAttribution 资金库在一段时间内的收益来源。金额以底层资产计，gas以USD计
+type HarvestAttribution struct {
+	Vault          string                `json:"vault"`
+	From           time.Time             `json:"from"`
+	To             time.Time             `json:"to"`
+	Gross          decimal.Decimal       `json:"gross"`
+	ManagementFee  decimal.Decimal       `json:"management_fee"`
+	PerformanceFee decimal.Decimal       `json:"performance_fee"`
+	Net            decimal.Decimal       `json:"net"`
+	GasCostUSD     decimal.Decimal       `json:"gas_cost_usd"`
+	AssetPriceUSD  *float64              `json:"asset_price_usd"` // 底层资产当前价格，便于和gas成本比较
+	Strategies     []StrategyAttribution `json:"strategies"`
+}
+
+type HarvestService struct {
+	harvestRepo  *repository.HarvestRepository
+	feeRepo      *repository.FeeRepository
+	strategyRepo repository.StrategyRepo
+	priceHistory *PriceHistoryService
+	prices       *prices.Service
+}
+
+func NewHarvestService() *HarvestService {
+	return NewHarvestServiceWith(repository.Default())
+}
+
+// NewHarvestServiceWith 使用注入的核心仓储构建
+func NewHarvestServiceWith(repos *repository.Repositories) *HarvestService {
+	return &HarvestService{
+		harvestRepo:  repository.NewHarvestRepository(),
+		feeRepo:      repository.NewFeeRepository(),
+		strategyRepo: repos.Strategies,
+		priceHistory: NewPriceHistoryService(),
+		prices:       prices.Default(),
+	}
+}
+
+// List 分页获取资金库或策略的收获记录及每次收获计提的费用
+func (s *HarvestService) List(vaultAddress, strategyAddress string, cursor *repository.Cursor, limit int) ([]HarvestRecord, *repository.Page, error) {
+	harvests, page, err := s.harvestRepo.List(vaultAddress, strategyAddress, cursor, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids := make([]uint, len(harvests))
+	for i, h := range harvests {
+		ids[i] = h.ID
+	}
+	fees, err := s.feeRepo.ByHarvest(ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	records := make([]HarvestRecord, len(harvests))
+	for i, h := range harvests {
+		f := fees[h.ID]
+		records[i] = HarvestRecord{
+			Harvest:        h,
+			ManagementFee:  f.Management,
+			PerformanceFee: f.Performance,
+			Net:            h.Amount.Sub(f.Total),
+		}
+	}
+	return records, page, nil
+}
+
+// Attribution 按策略拆分资金库在 [from, to) 内的收获、费用和gas成本
+func (s *HarvestService) Attribution(ctx context.Context, vault *models.Vault, from, to time.Time) (*HarvestAttribution, error) {
+	yields, err := s.harvestRepo.YieldByStrategy(vault.Address, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &HarvestAttribution{
+		Vault:      vault.Address,
+		From:       from,
+		To:         to,
+		Strategies: make([]StrategyAttribution, 0, len(yields)),
+	}
+	for _, y := range yields {
+		result.Gross = result.Gross.Add(y.Gross)
+		result.ManagementFee = result.ManagementFee.Add(y.ManagementFee)
+		result.PerformanceFee = result.PerformanceFee.Add(y.PerformanceFee)
+		result.GasCostUSD = result.GasCostUSD.Add(y.GasCostUSD)
+	}
+	result.Net = result.Gross.Sub(result.ManagementFee).Sub(result.PerformanceFee)
+
+	for _, y := range yields {
+		attribution := StrategyAttribution{
+			StrategyYield: y,
+			Net:           y.Gross.Sub(y.ManagementFee).Sub(y.PerformanceFee),
+		}
+		// 旧的收获可能没有记录策略
+		if y.StrategyAddress != "" {
+			strategy, err := s.strategyRepo.GetByAddress(y.StrategyAddress)
+			if err != nil {
+				return nil, err
+			}
+			if strategy != nil {
+				attribution.Name = strategy.Name
+			}
+		}
+		if result.Gross.IsPositive() {
+			attribution.Share = y.Gross.Div(result.Gross).InexactFloat64()
+		}
+		result.Strategies = append(result.Strategies, attribution)
+	}
+
+	if price, err := s.prices.GetPrice(ctx, vault.AssetAddress, vault.ChainID); err == nil {
+		result.AssetPriceUSD = &price.USD
+	}
+	return result, nil
+}
+
+// FillGas 从交易回执补齐收获的实际gas成本，USD成本按收获时刻的原生代币价格换算。
+// 回执取不到的收获记录一次失败并排到后面，失败 harvestGasAttempts 次后不再尝试，避免占满每一轮
+func (s *HarvestService) FillGas(ctx context.Context) error {
+	pending, err := s.harvestRepo.PendingGas(harvestGasAttempts, harvestGasBatch)
+	if err != nil {
+		return err
+	}
+
+	cfg := config.Load().Gas
+	done := make(map[string]bool)
+	filled := 0
+	for _, harvest := range pending {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		key := fmt.Sprintf("%d:%s", harvest.ChainID, harvest.TxHash)
+		if done[key] {
+			continue
+		}
+		done[key] = true
+
+		receipt, err := blockchain.TransactionReceipt(ctx, harvest.ChainID, harvest.TxHash)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if !errors.Is(err, ethereum.NotFound) {
+				logger.Info(fmt.Sprintf("Failed to get receipt of harvest tx %s: %v", harvest.TxHash, err))
+			}
+			attempts, err := s.harvestRepo.RecordGasAttempt(harvest.ChainID, harvest.TxHash)
+			if err != nil {
+				return err
+			}
+			if attempts >= harvestGasAttempts {
+				logger.Error(fmt.Sprintf("Giving up gas cost of harvest tx %s on chain %d after %d attempts", harvest.TxHash, harvest.ChainID, attempts))
+			}
+			continue
+		}
+		gasPrice := receipt.EffectiveGasPrice
+		if gasPrice == nil {
+			gasPrice = new(big.Int)
+		}
+		wei := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(receipt.GasUsed))
+		cost := decimal.NewFromBigInt(wei, -18)
+
+		var costUSD *decimal.Decimal
+		if native := nativeToken(cfg, harvest.ChainID); native != "" {
+			if price, err := s.priceHistory.PriceAt(ctx, native, harvest.ChainID, harvest.HarvestedAt); err == nil {
+				usd := cost.Mul(decimal.NewFromFloat(price))
+				costUSD = &usd
+			}
+		}
+
+		if err := s.harvestRepo.SetGas(harvest.ChainID, harvest.TxHash, receipt.GasUsed, cost, costUSD); err != nil {
+			return err
+		}
+		filled++
+	}
+
+	if filled > 0 {
+		logger.Info(fmt.Sprintf("⛽ Filled gas cost for %d harvest transactions", filled))
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/migrations"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/cache"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+)
+
+// 依赖检查结果
+const (
+	CheckOK      = "ok"
+	CheckFail    = "fail"
+	CheckSkipped = "skipped"
+)
+
+// DependencyStatus 单项依赖的检查结果
+type DependencyStatus struct {
+	Status  string `json:"status"`
+	Latency string `json:"latency,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Readiness 就绪检查结果
+type Readiness struct {
+	Ready  bool                        `json:"ready"`
+	Checks map[string]DependencyStatus `json:"checks"`
+}
+
+type HealthService struct {
+	cfg config.HealthConfig
+
+	// 迁移只会在发布时变化，全部应用后不再重复检查
+	migrationsMu   sync.Mutex
+	migrationsDone bool
+}
+
+func NewHealthService() *HealthService {
+	return &HealthService{cfg: config.Load().Health}
+}
+
+// Readiness 并行检查数据库、Redis、迁移和索引延迟，任一失败即未就绪
+func (s *HealthService) Readiness(ctx context.Context) *Readiness {
+	checks := map[string]func(context.Context) error{
+		"database":   pingDatabase,
+		"redis":      pingRedis,
+		"migrations": s.checkMigrations,
+		"indexer":    s.checkIndexerLag,
+	}
+
+	result := &Readiness{Ready: true, Checks: make(map[string]DependencyStatus, len(checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(context.Context) error) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, time.Duration(s.cfg.CheckTimeout)*time.Second)
+			defer cancel()
+
+			start := time.Now()
+			err := check(checkCtx)
+			status := DependencyStatus{Status: CheckOK, Latency: time.Since(start).Round(time.Microsecond).String()}
+			switch {
+			case errors.Is(err, errCheckSkipped):
+				status = DependencyStatus{Status: CheckSkipped}
+			case err != nil:
+				status.Status, status.Error = CheckFail, err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			result.Checks[name] = status
+			if status.Status == CheckFail {
+				result.Ready = false
+			}
+		}(name, check)
+	}
+	wg.Wait()
+	return result
+}
+
+// errCheckSkipped 该项检查未启用或无数据，不影响就绪状态
+var errCheckSkipped = errors.New("check skipped")
+
+func pingDatabase(ctx context.Context) error {
+	db := database.GetDB()
+	if db == nil {
+		return errors.New("database is not initialized")
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+func pingRedis(ctx context.Context) error {
+	if cache.Client == nil {
+		return errors.New("redis is not initialized")
+	}
+	return cache.Client.Ping(ctx).Err()
+}
+
+func (s *HealthService) checkMigrations(ctx context.Context) error {
+	s.migrationsMu.Lock()
+	defer s.migrationsMu.Unlock()
+	if s.migrationsDone {
+		return nil
+	}
+	// SQLite内存库不使用迁移脚本
+	if database.IsSQLite() {
+		return errCheckSkipped
+	}
+
+	db := database.GetDB()
+	if db == nil {
+		return errors.New("database is not initialized")
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	migrator, err := migrations.New(sqlDB)
+	if err != nil {
+		return err
+	}
+	pending, err := migrator.Pending()
+	if err != nil {
+		return err
+	}
+	if pending > 0 {
+		return fmt.Errorf("%d pending migration(s)", pending)
+	}
+	s.migrationsDone = true
+	return nil
+}
+
+// checkIndexerLag 检查各链索引是否落后太多；RPC不可用时无法判断，不视为未就绪
+func (s *HealthService) checkIndexerLag(ctx context.Context) error {
+	if s.cfg.MaxIndexerLag == 0 {
+		return errCheckSkipped
+	}
+	progress, err := loadIndexerProgress(ctx)
+	if err != nil {
+		return err
+	}
+
+	checked := false
+	for _, p := range progress {
+		head, err := blockchain.BlockNumber(ctx, p.ChainID)
+		if err != nil {
+			continue
+		}
+		checked = true
+		if head > p.LastBlock && head-p.LastBlock > s.cfg.MaxIndexerLag {
+			return fmt.Errorf("chain %d is %d blocks behind (max %d)", p.ChainID, head-p.LastBlock, s.cfg.MaxIndexerLag)
+		}
+	}
+	if !checked {
+		return errCheckSkipped
+	}
+	return nil
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/notify"
+)
+
+var ErrIncidentNotFound = errors.New("incident not found")
+
+// IncidentInput 管理员创建事件，StartedAt 为空时取当前时间，Notify 为是否告警运维
+type IncidentInput struct {
+	Component string
+	Severity  string
+	Title     string
+	Message   string
+	StartedAt *time.Time
+	Notify    bool
+}
+
+// IncidentService 事件管理。管理员可以创建和记录进展；状态检查中组件降级或中断持续超过 status.incident_after
+// 分钟时自动创建事件，状态恶化时升级，恢复后自动结束，每一步都发送与事件关联的运维告警
+type IncidentService struct {
+	repo      *repository.IncidentRepository
+	auditRepo *repository.AuditRepository
+	alerts    *OperatorAlertService
+}
+
+func NewIncidentService() *IncidentService {
+	return NewIncidentServiceWith(repository.Default())
+}
+
+// NewIncidentServiceWith 使用注入的核心仓储构建
+func NewIncidentServiceWith(repos *repository.Repositories) *IncidentService {
+	return &IncidentService{
+		repo:      repository.NewIncidentRepository(),
+		auditRepo: repository.NewAuditRepository(),
+		alerts:    NewOperatorAlertServiceWith(repos),
+	}
+}
+
+// List 列出事件，status 为 open/resolved 或空
+func (s *IncidentService) List(status, component string, limit int) ([]models.Incident, error) {
+	return s.repo.List(status, component, limit)
+}
+
+// Get 获取事件及其进展和告警
+func (s *IncidentService) Get(id uint) (*models.Incident, error) {
+	incident, err := s.repo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if incident == nil {
+		return nil, ErrIncidentNotFound
+	}
+	return incident, nil
+}
+
+// Create 创建事件，状态为 investigating，Message 作为第一条进展
+func (s *IncidentService) Create(ctx context.Context, actor string, input IncidentInput) (*models.Incident, error) {
+	incident := &models.Incident{
+		Component: input.Component,
+		Severity:  input.Severity,
+		Status:    models.IncidentInvestigating,
+		Title:     input.Title,
+		CreatedBy: actor,
+		StartedAt: time.Now(),
+	}
+	if input.StartedAt != nil {
+		incident.StartedAt = *input.StartedAt
+	}
+	update := &models.IncidentUpdate{Status: incident.Status, Message: input.Message, Author: actor}
+	if err := s.repo.Create(incident, update); err != nil {
+		return nil, err
+	}
+	if err := s.audit(actor, models.AuditIncidentOpen, incident, map[string]interface{}{
+		"component": incident.Component,
+		"severity":  incident.Severity,
+		"title":     incident.Title,
+	}); err != nil {
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("Incident #%d (%s) opened by %s: %s", incident.ID, incident.Severity, actor, incident.Title))
+	if input.Notify {
+		s.notify(ctx, incident, update)
+	}
+	return s.Get(incident.ID)
+}
+
+// Update 修改事件的严重程度或标题，为空的字段不修改
+func (s *IncidentService) Update(actor string, id uint, severity, title string) (*models.Incident, error) {
+	incident, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{})
+	if severity != "" && severity != incident.Severity {
+		fields["severity"] = severity
+	}
+	if title != "" && title != incident.Title {
+		fields["title"] = title
+	}
+	if len(fields) == 0 {
+		return incident, nil
+	}
+	if err := s.repo.Update(id, fields); err != nil {
+		return nil, err
+	}
+	if err := s.audit(actor, models.AuditIncidentEdit, incident, fields); err != nil {
+		return nil, err
+	}
+	return s.Get(id)
+}
+
+// PostUpdate 记录一条进展并把事件改为该状态：resolved 时记录结束时间，其他状态重新打开已结束的事件。
+// notify 为是否告警运维
+func (s *IncidentService) PostUpdate(ctx context.Context, actor string, id uint, status, message string, notify bool) (*models.Incident, error) {
+	incident, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	update := &models.IncidentUpdate{IncidentID: id, Status: status, Message: message, Author: actor}
+	if err := s.repo.AddUpdate(update, incidentStatusFields(incident, status)); err != nil {
+		return nil, err
+	}
+	if err := s.audit(actor, models.AuditIncidentPost, incident, map[string]interface{}{
+		"status":  status,
+		"message": message,
+	}); err != nil {
+		return nil, err
+	}
+
+	if notify {
+		incident.Status = status
+		s.notify(ctx, incident, update)
+	}
+	return s.Get(id)
+}
+
+// Delete 删除事件，关联的告警保留
+func (s *IncidentService) Delete(actor string, id uint) error {
+	incident, err := s.repo.Delete(id)
+	if err != nil {
+		return err
+	}
+	if incident == nil {
+		return ErrIncidentNotFound
+	}
+	if err := s.audit(actor, models.AuditIncidentDel, incident, map[string]interface{}{
+		"component": incident.Component,
+		"title":     incident.Title,
+	}); err != nil {
+		return err
+	}
+
+	logger.Info(fmt.Sprintf("Incident #%d deleted by %s", id, actor))
+	return nil
+}
+
+// Observe 处理一个组件的检查结果，since 为进入当前状态的时间。组件最近的自动事件在本次异常期间被管理员结束时
+// 不再重新创建，直到组件恢复后再次出现异常
+func (s *IncidentService) Observe(ctx context.Context, component, status, message string, since time.Time) error {
+	after := config.Load().Status.IncidentAfter
+	if after <= 0 {
+		return nil
+	}
+	latest, err := s.repo.LatestAutomatic(component)
+	if err != nil {
+		return err
+	}
+	open := latest != nil && latest.ResolvedAt == nil
+
+	if status == models.StatusOperational {
+		if !open {
+			return nil
+		}
+		return s.resolve(ctx, latest, message)
+	}
+
+	severity := models.IncidentMinor
+	if status == models.StatusOutage {
+		severity = models.IncidentMajor
+	}
+	switch {
+	case open && incidentSeverityRank(severity) > incidentSeverityRank(latest.Severity):
+		return s.escalate(ctx, latest, status, severity, message)
+	case open:
+		return nil
+	case latest != nil && !latest.ResolvedAt.Before(since):
+		return nil
+	case time.Since(since) < time.Duration(after)*time.Minute:
+		return nil
+	}
+
+	incident := &models.Incident{
+		Component: component,
+		Severity:  severity,
+		Status:    models.IncidentInvestigating,
+		Title:     incidentTitle(component, status),
+		Automatic: true,
+		StartedAt: since,
+	}
+	update := &models.IncidentUpdate{
+		Status:  incident.Status,
+		Message: fmt.Sprintf("%s has been %s for %s: %s", componentName(component), status, time.Since(since).Round(time.Minute), incidentDetail(message)),
+	}
+	if err := s.repo.Create(incident, update); err != nil {
+		return err
+	}
+	logger.Info(fmt.Sprintf("Incident #%d opened automatically: %s", incident.ID, incident.Title))
+	s.notify(ctx, incident, update)
+	return nil
+}
+
+// escalate 组件状态恶化时提高自动事件的严重程度
+func (s *IncidentService) escalate(ctx context.Context, incident *models.Incident, status, severity, message string) error {
+	update := &models.IncidentUpdate{
+		IncidentID: incident.ID,
+		Status:     incident.Status,
+		Message:    fmt.Sprintf("Escalated to %s: %s is now %s: %s", severity, componentName(incident.Component), status, incidentDetail(message)),
+	}
+	if err := s.repo.AddUpdate(update, map[string]interface{}{
+		"severity": severity,
+		"title":    incidentTitle(incident.Component, status),
+	}); err != nil {
+		return err
+	}
+	incident.Severity, incident.Title = severity, incidentTitle(incident.Component, status)
+	logger.Info(fmt.Sprintf("Incident #%d escalated to %s", incident.ID, severity))
+	s.notify(ctx, incident, update)
+	return nil
+}
+
+// resolve 组件恢复后结束自动事件
+func (s *IncidentService) resolve(ctx context.Context, incident *models.Incident, message string) error {
+	now := time.Now()
+	text := fmt.Sprintf("%s is operational again after %s; resolved automatically", componentName(incident.Component), now.Sub(incident.StartedAt).Round(time.Minute))
+	if message != "" {
+		text += ": " + message
+	}
+	update := &models.IncidentUpdate{IncidentID: incident.ID, Status: models.IncidentResolved, Message: text}
+	if err := s.repo.AddUpdate(update, map[string]interface{}{
+		"status":      models.IncidentResolved,
+		"resolved_at": now,
+	}); err != nil {
+		return err
+	}
+	incident.Status, incident.ResolvedAt = models.IncidentResolved, &now
+	logger.Info(fmt.Sprintf("Incident #%d resolved automatically", incident.ID))
+	s.notify(ctx, incident, update)
+	return nil
+}
+
+// notify 发送与事件关联的运维告警，内容为最新的一条进展
+func (s *IncidentService) notify(ctx context.Context, incident *models.Incident, update *models.IncidentUpdate) {
+	s.alerts.NotifyIncident(ctx, incident.ID, notify.Message{
+		Subject: fmt.Sprintf("[Incident #%d] %s (%s, %s)", incident.ID, incident.Title, incident.Severity, update.Status),
+		Body: fmt.Sprintf("%s\n\nComponent: %s\nSeverity: %s\nStarted: %s",
+			update.Message, incident.Component, incident.Severity, incident.StartedAt.UTC().Format(time.RFC3339)),
+	})
+}
+
+func (s *IncidentService) audit(actor, action string, incident *models.Incident, details map[string]interface{}) error {
+	data, _ := json.Marshal(details)
+	return s.auditRepo.Create(&models.AuditLog{
+		Actor:   actor,
+		Action:  action,
+		Target:  fmt.Sprintf("incident:%d", incident.ID),
+		Details: data,
+	})
+}
+
+// incidentStatusFields 进展的状态对事件字段的修改：结束时记录结束时间，重新打开时清除
+func incidentStatusFields(incident *models.Incident, status string) map[string]interface{} {
+	fields := map[string]interface{}{"status": status}
+	switch {
+	case status == models.IncidentResolved && incident.ResolvedAt == nil:
+		fields["resolved_at"] = time.Now()
+	case status != models.IncidentResolved && incident.ResolvedAt != nil:
+		fields["resolved_at"] = nil
+	}
+	return fields
+}
+
+func incidentSeverityRank(severity string) int {
+	switch severity {
+	case models.IncidentMinor:
+		return 1
+	case models.IncidentMajor:
+		return 2
+	case models.IncidentCritical:
+		return 3
+	}
+	return 0
+}
+
+func incidentTitle(component, status string) string {
+	return componentName(component) + " " + status
+}
+
+func incidentDetail(message string) string {
+	if message == "" {
+		return "no details reported"
+	}
+	return message
+}
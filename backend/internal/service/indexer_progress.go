@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/pkg/cache"
+	"github.com/chspring1/mya-platform/backend/pkg/events"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 各链已物化的最高区块及物化时间，按链ID作为hash字段
+const (
+	indexerBlockKey  = "indexer:block"
+	indexerSeenAtKey = "indexer:seen_at"
+)
+
+// 分区间乱序消费时只前移，不回退
+var advanceScript = redis.NewScript(`
+local current = tonumber(redis.call("HGET", KEYS[1], ARGV[1]) or "0")
+if tonumber(ARGV[2]) > current then
+	redis.call("HSET", KEYS[1], ARGV[1], ARGV[2])
+end
+redis.call("HSET", KEYS[2], ARGV[1], ARGV[3])
+return 1`)
+
+// IndexerProgress 事件物化进度
+type IndexerProgress struct {
+	ChainID     uint       `json:"chain_id"`
+	LastBlock   uint64     `json:"last_block"`
+	HeadBlock   *uint64    `json:"head_block"` // RPC不可用时为空
+	LagBlocks   *uint64    `json:"lag_blocks"` // RPC不可用时为空
+	LastEventAt *time.Time `json:"last_event_at"`
+}
+
+// recordIndexerProgress 记录事件物化进度，失败只记录日志
+func recordIndexerProgress(ctx context.Context, event *events.ChainEvent) {
+	if cache.Client == nil || event.ChainID == 0 {
+		return
+	}
+	chain := strconv.FormatUint(uint64(event.ChainID), 10)
+	err := advanceScript.Run(ctx, cache.Client, []string{indexerBlockKey, indexerSeenAtKey},
+		chain, event.BlockNumber, time.Now().Unix()).Err()
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to record indexer progress for chain %s: %v", chain, err))
+	}
+}
+
+// loadIndexerProgress 读取各链的物化进度，Redis不可用时返回空
+func loadIndexerProgress(ctx context.Context) ([]IndexerProgress, error) {
+	if cache.Client == nil {
+		return nil, nil
+	}
+
+	blocks, err := cache.Client.HGetAll(ctx, indexerBlockKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	seenAt, err := cache.Client.HGetAll(ctx, indexerSeenAtKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	progress := make([]IndexerProgress, 0, len(blocks))
+	for chain, block := range blocks {
+		chainID, err := strconv.ParseUint(chain, 10, 32)
+		if err != nil {
+			continue
+		}
+		last, _ := strconv.ParseUint(block, 10, 64)
+		p := IndexerProgress{ChainID: uint(chainID), LastBlock: last}
+		if ts, err := strconv.ParseInt(seenAt[chain], 10, 64); err == nil {
+			t := time.Unix(ts, 0)
+			p.LastEventAt = &t
+		}
+		progress = append(progress, p)
+	}
+	return progress, nil
+}
@@ -0,0 +1,478 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/contracts"
+	"github.com/chspring1/mya-platform/backend/pkg/events"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/signature"
+	"github.com/chspring1/mya-platform/backend/pkg/units"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// 存取款意向类型
+const (
+	IntentDeposit      = "deposit"
+	IntentWithdraw     = "withdraw"
+	IntentBatchDeposit = "batch_deposit" // 仅用于签名，批量中的每笔意向仍为 deposit
+)
+
+var (
+	ErrIntentExpired       = errors.New("intent signature has expired")
+	ErrIntentExpiry        = errors.New("intent expiry is too far in the future")
+	ErrNonceUsed           = errors.New("nonce must be greater than the last used nonce")
+	ErrInvalidDeadline     = errors.New("deadline must be in the future and within intents.max_deadline")
+	ErrDeadlineUnsupported = errors.New("deadline is not supported on this chain: no intent router is configured")
+	ErrMinOutNotMet        = errors.New("current preview is below the minimum output, the transaction would revert")
+
+	ErrIntentNotFound  = errors.New("intent not found")
+	ErrIntentFinished  = errors.New("intent execution has already been reported")
+	ErrTxPending       = errors.New("transaction is not mined yet")
+	ErrTxMismatch      = errors.New("transaction did not execute this intent")
+	ErrIntentViolation = errors.New("execution violates the intent constraints")
+)
+
+// Intent 用户签名的存取款意向，Amount 存款时为资产数量、取款时为份额数量，为请求中的十进制原文。
+// 请求以最小单位给出数量时 AmountWei 为原始整数字符串，Amount 为空；MinOut / MinOutWei 同理，都为空表示不限制
+type Intent struct {
+	Kind         string
+	Vault        string
+	Amount       string
+	AmountWei    string
+	AllowPartial bool   // 仅存款
+	MinOut       string // 存款时为最少份额，取款时为最少资产
+	MinOutWei    string
+	Deadline     int64              // 交易截止时间(Unix秒)，0表示不限制
+	QuoteID      string             // 仅存款，使用的报价ID
+	Allocations  []IntentAllocation // 仅批量存款，Vault 为空
+	SlippageBps  uint16             // 仅批量存款，0表示使用默认滑点
+	Nonce        uint64
+	ExpiresAt    int64 // 签名过期时间(Unix秒)
+	Signature    string
+}
+
+// IntentAllocation 批量存款中分配给一个资金库的权重(基点)
+type IntentAllocation struct {
+	Vault     string
+	WeightBps uint16
+}
+
+// IntentMessage 存取款意向的签名原文，地址小写，金额与请求中的写法完全相同(不做规范化，"1.50" 不写作 "1.5")；
+// 以最小单位给出时金额行为 "Amount Wei:" / "Shares Wei:" 加原始整数字符串。
+// 最少输出、截止时间和报价ID只在给出时出现，不使用它们的旧客户端签名原文不变。
+// 批量存款没有 Vault 行，改为每个资金库一行 "Allocation: <地址> <权重基点>"
+func IntentMessage(address string, in Intent) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "MYA Platform %s\nAddress: %s\n", in.Kind, strings.ToLower(address))
+	if in.Vault != "" {
+		fmt.Fprintf(&b, "Vault: %s\n", strings.ToLower(in.Vault))
+	}
+	label, amount := "Amount", in.Amount
+	minLabel := "Min Shares Out"
+	if in.Kind == IntentWithdraw {
+		label, minLabel = "Shares", "Min Assets Out"
+	}
+	if in.AmountWei != "" {
+		label, amount = label+" Wei", in.AmountWei
+	}
+	fmt.Fprintf(&b, "%s: %s\n", label, amount)
+	if in.Kind == IntentDeposit {
+		fmt.Fprintf(&b, "Allow Partial: %t\n", in.AllowPartial)
+	}
+	for _, allocation := range in.Allocations {
+		fmt.Fprintf(&b, "Allocation: %s %d\n", strings.ToLower(allocation.Vault), allocation.WeightBps)
+	}
+	if in.SlippageBps != 0 {
+		fmt.Fprintf(&b, "Slippage Bps: %d\n", in.SlippageBps)
+	}
+	switch {
+	case in.MinOut != "":
+		fmt.Fprintf(&b, "%s: %s\n", minLabel, in.MinOut)
+	case in.MinOutWei != "":
+		fmt.Fprintf(&b, "%s Wei: %s\n", minLabel, in.MinOutWei)
+	}
+	if in.Deadline != 0 {
+		fmt.Fprintf(&b, "Deadline: %d\n", in.Deadline)
+	}
+	if in.QuoteID != "" {
+		fmt.Fprintf(&b, "Quote: %s\n", in.QuoteID)
+	}
+	fmt.Fprintf(&b, "Nonce: %d\nExpires At: %d", in.Nonce, in.ExpiresAt)
+	return b.String()
+}
+
+type IntentService struct {
+	userRepo     repository.UserRepo
+	repo         *repository.IntentRepository
+	contractRepo *repository.ContractRepository
+	previews     *PreviewService
+}
+
+func NewIntentService() *IntentService {
+	return NewIntentServiceWith(repository.Default())
+}
+
+// NewIntentServiceWith 使用注入的仓储构建
+func NewIntentServiceWith(repos *repository.Repositories) *IntentService {
+	return &IntentService{
+		userRepo:     repos.Users,
+		repo:         repository.NewIntentRepository(),
+		contractRepo: repository.NewContractRepository(),
+		previews:     NewPreviewService(),
+	}
+}
+
+// Verify 校验意向签名：未过期、过期时间不晚于 auth.signature_ttl 之后，且由地址本人签出。
+// 会话令牌被盗时，没有钱包签名仍无法以用户名义创建存取款意向。
+// nonce必须大于该用户已使用的最大值(允许跳号)。这里只检查不消耗，nonce在 Prepare 保存意向时才在同一事务中消耗，
+// 资金库、额度等后续检查失败时用户可以用同一签名重试，成功保存后同一签名请求不能重放
+func (s *IntentService) Verify(address string, in Intent) error {
+	expiresAt := time.Unix(in.ExpiresAt, 0)
+	if !time.Now().Before(expiresAt) {
+		return ErrIntentExpired
+	}
+	ttl := time.Duration(config.Load().Auth.SignatureTTL) * time.Second
+	if time.Until(expiresAt) > ttl {
+		return ErrIntentExpiry
+	}
+	if in.Deadline != 0 {
+		deadline := time.Unix(in.Deadline, 0)
+		maxDeadline := time.Duration(config.Load().Intents.MaxDeadline) * time.Second
+		if !time.Now().Before(deadline) || time.Until(deadline) > maxDeadline {
+			return ErrInvalidDeadline
+		}
+	}
+	if err := signature.VerifyPersonal(address, IntentMessage(address, in), in.Signature); err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetOrCreate(address)
+	if err != nil {
+		return err
+	}
+	if in.Nonce <= user.IntentNonce {
+		return ErrNonceUsed
+	}
+	return nil
+}
+
+// CheckMinOut 按当前链上预览检查最少输出是否还能满足，不满足时生成的交易必然回滚，直接返回 ErrMinOutNotMet
+func (s *IntentService) CheckMinOut(ctx context.Context, vault *models.Vault, kind string, amount, minOut *units.Amount) error {
+	if minOut == nil {
+		return nil
+	}
+	var preview *Preview
+	var err error
+	if kind == IntentDeposit {
+		preview, err = s.previews.PreviewDeposit(ctx, vault, amount.Value)
+	} else {
+		preview, err = s.previews.PreviewRedeem(ctx, vault, amount.Value)
+	}
+	if err != nil {
+		return err
+	}
+	expected := preview.SharesWei
+	if kind == IntentWithdraw {
+		expected = preview.AssetsWei
+	}
+	out, err := units.ParseBase(expected)
+	if err != nil {
+		return err
+	}
+	if out.Cmp(minOut.Base) < 0 {
+		return ErrMinOutNotMet
+	}
+	return nil
+}
+
+// Prepare 为签名通过的意向生成交易并保存。最少输出写入资金库带滑点保护的存取函数；
+// 给出 deadline 时交易改由路由合约的 multicall(deadline, data) 执行，所在链未配置路由时返回 ErrDeadlineUnsupported。
+// signed 为 Verify 通过的签名意向：保存意向时在同一事务中消耗其nonce并把使用的报价标记为已使用，
+// nonce已被使用时返回 ErrNonceUsed，报价已被使用或已过期时返回 ErrQuoteUsed
+func (s *IntentService) Prepare(vault *models.Vault, userAddress, kind string, amount, minOut *units.Amount, signed Intent) (*models.Intent, error) {
+	deadline := signed.Deadline
+	intent := &models.Intent{
+		UserAddress:  userAddress,
+		VaultAddress: vault.Address,
+		ChainID:      vault.ChainID,
+		Kind:         kind,
+		AmountWei:    amount.BaseString(),
+		Status:       models.IntentPending,
+	}
+	minOutBase := new(big.Int)
+	if minOut != nil {
+		intent.MinOutWei = minOut.BaseString()
+		minOutBase = minOut.Base
+	}
+
+	user := common.HexToAddress(userAddress)
+	var call []byte
+	var err error
+	if deadline == 0 {
+		intent.To = vault.Address
+		switch {
+		case kind == IntentDeposit && minOut == nil:
+			call, err = encodeCall("deposit(uint256,address)", amount.Base, user)
+		case kind == IntentDeposit:
+			call, err = encodeCall("deposit(uint256,address,uint256)", amount.Base, user, minOutBase)
+		case minOut == nil:
+			call, err = encodeCall("redeem(uint256,address,address)", amount.Base, user, user)
+		default:
+			call, err = encodeCall("redeem(uint256,address,address,uint256)", amount.Base, user, user, minOutBase)
+		}
+	} else {
+		router := intentRouter(config.Load().Intents, vault.ChainID)
+		if router == "" {
+			return nil, ErrDeadlineUnsupported
+		}
+		at := time.Unix(deadline, 0)
+		intent.To = strings.ToLower(router)
+		intent.Deadline = &at
+		call, err = routerCall(common.HexToAddress(router), vault, kind, user, amount.Base, minOutBase, deadline)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("encode %s call: %w", kind, err)
+	}
+	intent.Data = hexutil.Encode(call)
+
+	if err := s.repo.Create(intent, signed.claim(userAddress)); err != nil {
+		return nil, claimError(err)
+	}
+	return intent, nil
+}
+
+// claim 保存意向时消耗的nonce和报价
+func (in Intent) claim(userAddress string) repository.IntentClaim {
+	return repository.IntentClaim{UserAddress: userAddress, Nonce: in.Nonce, QuoteID: in.QuoteID}
+}
+
+// claimError 把保存意向时消耗nonce和报价的失败换成对应的服务错误
+func claimError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrNonceClaimed):
+		return ErrNonceUsed
+	case errors.Is(err, repository.ErrQuoteClaimed):
+		return ErrQuoteUsed
+	}
+	return err
+}
+
+// Get 获取用户的意向，不存在时返回 ErrIntentNotFound
+func (s *IntentService) Get(userAddress string, id uint) (*models.Intent, error) {
+	intent, err := s.repo.Get(userAddress, id)
+	if err != nil {
+		return nil, err
+	}
+	if intent == nil {
+		return nil, ErrIntentNotFound
+	}
+	return intent, nil
+}
+
+// Report 处理用户上报的执行交易：交易须已成功上链，且包含该资金库发给用户、数量与意向一致的存取款事件。
+// 实际输出低于最少输出或出块时间晚于 deadline 时意向记为 rejected 并返回 ErrIntentViolation；
+// 交易本身不对应该意向时意向保持 pending，可以重新上报
+func (s *IntentService) Report(ctx context.Context, userAddress string, id uint, txHash string) (*models.Intent, error) {
+	intent, err := s.Get(userAddress, id)
+	if err != nil {
+		return nil, err
+	}
+	if intent.Status != models.IntentPending {
+		return intent, ErrIntentFinished
+	}
+
+	receipt, err := blockchain.TransactionReceipt(ctx, intent.ChainID, txHash)
+	if err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return nil, ErrTxPending
+		}
+		return nil, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, fmt.Errorf("%w: transaction reverted", ErrTxMismatch)
+	}
+	txHash = strings.ToLower(txHash)
+	claimed, err := s.repo.ClaimedLogs(intent.ChainID, txHash, intent.ID)
+	if errors.Is(err, repository.ErrLogClaimed) {
+		return nil, fmt.Errorf("%w: %v", ErrTxMismatch, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	out, logIndex, err := s.executedOut(intent, receipt, claimed)
+	if err != nil {
+		return nil, err
+	}
+	executedAt, err := blockchain.BlockTime(ctx, intent.ChainID, receipt.BlockNumber.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	return s.settle(intent, txHash, logIndex, out, executedAt)
+}
+
+// Settle 记录已在模拟链上执行的意向
+func (s *IntentService) Settle(intent *models.Intent, txHash string, logIndex uint, out *big.Int) (*models.Intent, error) {
+	return s.settle(intent, txHash, logIndex, out, time.Now())
+}
+
+func (s *IntentService) settle(intent *models.Intent, txHash string, logIndex uint, out *big.Int, executedAt time.Time) (*models.Intent, error) {
+	status, reason := models.IntentExecuted, ""
+	if intent.MinOutWei != "" {
+		if minOut, _ := units.ParseBase(intent.MinOutWei); minOut != nil && out.Cmp(minOut) < 0 {
+			status, reason = models.IntentRejected, fmt.Sprintf("received %s, below min_out %s", out.String(), intent.MinOutWei)
+		}
+	}
+	if intent.Deadline != nil && executedAt.After(*intent.Deadline) {
+		status, reason = models.IntentRejected, fmt.Sprintf("executed at %s, after deadline %s",
+			executedAt.UTC().Format(time.RFC3339), intent.Deadline.UTC().Format(time.RFC3339))
+	}
+
+	finished, err := s.repo.Finish(intent, status, txHash, logIndex, out.String(), reason, executedAt)
+	if errors.Is(err, repository.ErrLogClaimed) {
+		return nil, fmt.Errorf("%w: %v", ErrTxMismatch, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !finished {
+		return intent, ErrIntentFinished
+	}
+	intent.Status, intent.TxHash, intent.LogIndex, intent.OutWei, intent.RejectReason, intent.ExecutedAt = status, txHash, &logIndex, out.String(), reason, &executedAt
+	if status == models.IntentRejected {
+		logger.Info(fmt.Sprintf("Rejected execution report of intent %d by %s: %s", intent.ID, intent.UserAddress, reason))
+		return intent, ErrIntentViolation
+	}
+	return intent, nil
+}
+
+// executedOut 在回执中找到资金库发给意向用户的存取款事件，返回得到的份额(存款)或资产(取款)和事件序号，
+// 事件中的输入数量须与意向一致。claimed 中的事件已结算其他意向，跳过
+func (s *IntentService) executedOut(intent *models.Intent, receipt *types.Receipt, claimed map[uint]bool) (*big.Int, uint, error) {
+	vaultABI := contracts.Default(contracts.InterfaceVault)
+	registered, err := s.contractRepo.Get(intent.ChainID, intent.VaultAddress)
+	if err != nil {
+		return nil, 0, err
+	}
+	if registered != nil && registered.Interface == contracts.InterfaceVault {
+		if vaultABI, err = contracts.Parse(registered.Interface, string(registered.ABI)); err != nil {
+			return nil, 0, fmt.Errorf("registered ABI of %s: %w", intent.VaultAddress, err)
+		}
+	}
+
+	kind := events.TypeDeposit
+	if intent.Kind == IntentWithdraw {
+		kind = events.TypeWithdraw
+	}
+	amount, _ := units.ParseBase(intent.AmountWei)
+	for _, log := range receipt.Logs {
+		if claimed[log.Index] || !strings.EqualFold(log.Address.Hex(), intent.VaultAddress) {
+			continue
+		}
+		decoded, err := contracts.DecodeVaultLog(vaultABI, *log)
+		if err != nil || decoded.Type != kind || !strings.EqualFold(decoded.User.Hex(), intent.UserAddress) {
+			continue
+		}
+		if kind == events.TypeDeposit && decoded.Assets.Cmp(amount) == 0 {
+			return decoded.Shares, log.Index, nil
+		}
+		if kind == events.TypeWithdraw && decoded.Shares.Cmp(amount) == 0 {
+			return decoded.Assets, log.Index, nil
+		}
+	}
+	return nil, 0, fmt.Errorf("%w: no matching %s event from %s", ErrTxMismatch, kind, intent.VaultAddress)
+}
+
+// IntentApproval 执行意向交易前需要的代币授权：存款授权资产给交易目标，经路由取款时授权份额给路由；
+// 直接从资金库赎回时不需要授权，返回nil
+func IntentApproval(intent *models.Intent, vault *models.Vault) *ZapApproval {
+	if intent.Kind == IntentWithdraw {
+		if intent.Deadline == nil {
+			return nil
+		}
+		return &ZapApproval{Token: vault.Address, Spender: intent.To, Amount: intent.AmountWei}
+	}
+	return &ZapApproval{Token: strings.ToLower(vault.AssetAddress), Spender: intent.To, Amount: intent.AmountWei}
+}
+
+// routerCall 编码经由路由合约执行的存取款：先把资产或份额从用户转入路由，存款时授权资金库，
+// 再调用路由的 deposit / redeem，整体包在 multicall(deadline, data) 中，超过deadline时整笔回滚
+func routerCall(router common.Address, vault *models.Vault, kind string, user common.Address, amount, minOut *big.Int, deadline int64) ([]byte, error) {
+	target := common.HexToAddress(vault.Address)
+	var steps [][]byte
+	if kind == IntentDeposit {
+		asset := common.HexToAddress(vault.AssetAddress)
+		pull, err := encodeCall("pullToken(address,uint256,address)", asset, amount, router)
+		if err != nil {
+			return nil, err
+		}
+		approve, err := encodeCall("approve(address,address,uint256)", asset, target, amount)
+		if err != nil {
+			return nil, err
+		}
+		deposit, err := encodeCall("deposit(address,address,uint256,uint256)", target, user, amount, minOut)
+		if err != nil {
+			return nil, err
+		}
+		steps = [][]byte{pull, approve, deposit}
+	} else {
+		pull, err := encodeCall("pullToken(address,uint256,address)", target, amount, router)
+		if err != nil {
+			return nil, err
+		}
+		redeem, err := encodeCall("redeem(address,address,uint256,uint256)", target, user, amount, minOut)
+		if err != nil {
+			return nil, err
+		}
+		steps = [][]byte{pull, redeem}
+	}
+	return encodeCall("multicall(uint256,bytes[])", big.NewInt(deadline), steps)
+}
+
+// encodeCall 按函数签名编码调用数据，参数类型取自签名
+func encodeCall(signature string, args ...interface{}) ([]byte, error) {
+	open := strings.IndexByte(signature, '(')
+	if open < 0 || !strings.HasSuffix(signature, ")") {
+		return nil, fmt.Errorf("invalid function signature %q", signature)
+	}
+	var arguments abi.Arguments
+	if params := signature[open+1 : len(signature)-1]; params != "" {
+		for _, name := range strings.Split(params, ",") {
+			typ, err := abi.NewType(name, "", nil)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", signature, err)
+			}
+			arguments = append(arguments, abi.Argument{Type: typ})
+		}
+	}
+	data, err := arguments.Pack(args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", signature, err)
+	}
+	return append(crypto.Keccak256([]byte(signature))[:4], data...), nil
+}
+
+// intentRouter 返回链上配置的意向路由合约地址，未配置时返回空字符串
+func intentRouter(cfg config.IntentsConfig, chainID uint) string {
+	for _, router := range cfg.Routers {
+		if router.ChainID == chainID && router.Address != "" {
+			return router.Address
+		}
+	}
+	return ""
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+)
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobRunning  = errors.New("job is already running")
+)
+
+// JobService 查看和控制worker中的定时任务。任务由worker启动时登记，
+// 暂停和手动触发写入数据库后由worker在几秒内生效
+type JobService struct {
+	repo      *repository.ScheduledJobRepository
+	auditRepo *repository.AuditRepository
+}
+
+func NewJobService() *JobService {
+	return &JobService{
+		repo:      repository.NewScheduledJobRepository(),
+		auditRepo: repository.NewAuditRepository(),
+	}
+}
+
+// List 获取全部任务的调度状态和最近一次执行结果
+func (s *JobService) List() ([]models.ScheduledJob, error) {
+	return s.repo.ListAll()
+}
+
+// Trigger 请求立即执行一次任务，不受暂停限制。任务正在执行时返回 ErrJobRunning
+func (s *JobService) Trigger(actor, name string) error {
+	job, err := s.repo.GetByName(name)
+	if err != nil {
+		return err
+	}
+	if job == nil {
+		return ErrJobNotFound
+	}
+	if job.Running {
+		return ErrJobRunning
+	}
+	if _, err := s.repo.RequestTrigger(name); err != nil {
+		return err
+	}
+
+	if err := s.audit(actor, models.AuditJobTrigger, name); err != nil {
+		return err
+	}
+	logger.Info(fmt.Sprintf("Job %s trigger requested by %s", name, actor))
+	return nil
+}
+
+// SetPaused 暂停或恢复任务的定时执行，正在执行的一次不受影响
+func (s *JobService) SetPaused(actor, name string, paused bool) (*models.ScheduledJob, error) {
+	found, err := s.repo.SetPaused(name, paused)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrJobNotFound
+	}
+
+	action := models.AuditJobResume
+	if paused {
+		action = models.AuditJobPause
+	}
+	if err := s.audit(actor, action, name); err != nil {
+		return nil, err
+	}
+	logger.Info(fmt.Sprintf("Job %s paused=%t by %s", name, paused, actor))
+	return s.repo.GetByName(name)
+}
+
+func (s *JobService) audit(actor, action, name string) error {
+	return s.auditRepo.Create(&models.AuditLog{
+		Actor:  actor,
+		Action: action,
+		Target: name,
+	})
+}
@@ -0,0 +1,454 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shopspring/decimal"
+)
+
+// keeper API Key的前缀，便于在日志和密钥扫描中识别
+const keeperKeyPrefix = "mk_"
+
+// 取消任务时记录的原因：策略已被收获、提案已执行或不再处于批准状态
+const staleJobReason = "job target no longer needs execution"
+
+var harvestCalldata = hexutil.Encode(crypto.Keccak256([]byte("harvest()"))[:4])
+
+var (
+	ErrKeeperExists      = errors.New("keeper already registered")
+	ErrKeeperNotFound    = errors.New("keeper not found")
+	ErrKeeperJobNotFound = errors.New("keeper job not found")
+	ErrKeeperJobClaimed  = errors.New("job is already claimed or finished")
+	ErrKeeperLeaseLost   = errors.New("job lease expired or held by another keeper")
+	ErrKeeperResult      = errors.New("successful results require tx_hash and failed results require error")
+	ErrKeeperJobType     = errors.New("unknown keeper job type")
+	ErrKeeperExecutions  = errors.New("rebalance results require transactions")
+	ErrKeeperTx          = errors.New("transaction did not execute this job")
+)
+
+// HarvestJobPayload 收获任务的执行参数：向 to 发送 data
+type HarvestJobPayload struct {
+	Strategy    string     `json:"strategy"`
+	To          string     `json:"to"`
+	Data        string     `json:"data"`
+	LastHarvest *time.Time `json:"last_harvest,omitempty"`
+}
+
+// RebalanceJobPayload 再平衡任务的执行参数，keeper按目标资产调仓后逐个策略上报交易
+type RebalanceJobPayload struct {
+	ProposalID uint                 `json:"proposal_id"`
+	Items      []RebalanceJobTarget `json:"items"`
+}
+
+type RebalanceJobTarget struct {
+	Strategy      string          `json:"strategy"`
+	CurrentAssets decimal.Decimal `json:"current_assets"`
+	TargetAssets  decimal.Decimal `json:"target_assets"`
+	TargetBps     uint16          `json:"target_bps"`
+}
+
+// KeeperResult keeper上报的执行结果
+type KeeperResult struct {
+	Success      bool
+	TxHash       string
+	Error        string
+	Transactions []RebalanceExecution // 再平衡任务成功时逐个策略的调仓交易
+}
+
+type KeeperService struct {
+	keeperRepo    *repository.KeeperRepository
+	vaultRepo     repository.VaultRepo
+	rebalanceRepo *repository.RebalanceRepository
+	auditRepo     *repository.AuditRepository
+	rebalance     *RebalanceService
+}
+
+func NewKeeperService() *KeeperService {
+	return NewKeeperServiceWith(repository.Default())
+}
+
+// NewKeeperServiceWith 使用注入的核心仓储构建
+func NewKeeperServiceWith(repos *repository.Repositories) *KeeperService {
+	return &KeeperService{
+		keeperRepo:    repository.NewKeeperRepository(),
+		vaultRepo:     repos.Vaults,
+		rebalanceRepo: repository.NewRebalanceRepository(),
+		auditRepo:     repository.NewAuditRepository(),
+		rebalance:     NewRebalanceServiceWith(repos),
+	}
+}
+
+// Register 登记keeper并生成API Key。Key只在此时返回一次，库中只保存哈希
+func (s *KeeperService) Register(actor, address, name string) (*models.Keeper, string, error) {
+	existing, err := s.keeperRepo.GetKeeperByAddress(address)
+	if err != nil {
+		return nil, "", err
+	}
+	if existing != nil {
+		return nil, "", ErrKeeperExists
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, "", err
+	}
+	key := keeperKeyPrefix + hex.EncodeToString(buf)
+
+	keeper := &models.Keeper{
+		Address:    address,
+		Name:       name,
+		APIKeyHash: hashCode(key),
+		Active:     true,
+		CreatedBy:  actor,
+	}
+	if err := s.keeperRepo.CreateKeeper(keeper); err != nil {
+		return nil, "", err
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{"name": name})
+	if err := s.auditRepo.Create(&models.AuditLog{
+		Actor:   actor,
+		Action:  models.AuditKeeperAdd,
+		Target:  address,
+		Details: details,
+	}); err != nil {
+		return nil, "", err
+	}
+
+	logger.Info(fmt.Sprintf("Keeper %s (%s) registered by %s", address, name, actor))
+	return keeper, key, nil
+}
+
+// Revoke 停用keeper，其API Key立即失效
+func (s *KeeperService) Revoke(actor, address, reason string) error {
+	ok, err := s.keeperRepo.DeactivateKeeper(address)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrKeeperNotFound
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{"reason": reason})
+	if err := s.auditRepo.Create(&models.AuditLog{
+		Actor:   actor,
+		Action:  models.AuditKeeperRevoke,
+		Target:  address,
+		Details: details,
+	}); err != nil {
+		return err
+	}
+
+	logger.Info(fmt.Sprintf("Keeper %s revoked by %s: %s", address, actor, reason))
+	return nil
+}
+
+// ListKeepers 列出全部keeper
+func (s *KeeperService) ListKeepers() ([]models.Keeper, error) {
+	return s.keeperRepo.ListKeepers()
+}
+
+// ListJobs 按状态列出任务，供管理员查看执行情况
+func (s *KeeperService) ListJobs(status string, limit int) ([]models.KeeperJob, error) {
+	return s.keeperRepo.ListJobs(status, limit)
+}
+
+// Authenticate 根据API Key查找启用中的keeper，Key无效时返回nil
+func (s *KeeperService) Authenticate(key string) (*models.Keeper, error) {
+	if key == "" {
+		return nil, nil
+	}
+	keeper, err := s.keeperRepo.GetActiveKeeperByKeyHash(hashCode(key))
+	if err != nil || keeper == nil {
+		return nil, err
+	}
+	// 访问时间只用于运维查看，写入失败不影响请求
+	_ = s.keeperRepo.TouchKeeper(keeper.ID, time.Now())
+	return keeper, nil
+}
+
+// AvailableJobs 列出当前可领取的任务
+func (s *KeeperService) AvailableJobs(types []string, chainID uint, limit int) ([]models.KeeperJob, error) {
+	for _, t := range types {
+		if !models.ValidKeeperJobType(t) {
+			return nil, ErrKeeperJobType
+		}
+	}
+	return s.keeperRepo.AvailableJobs(types, chainID, time.Now(), limit)
+}
+
+// Claim 领取任务，成功后在 keeper.lease_duration 内只有该keeper可以上报结果
+func (s *KeeperService) Claim(id uint, keeper *models.Keeper) (*models.KeeperJob, error) {
+	now := time.Now()
+	lease := time.Duration(config.Load().Keeper.LeaseDuration) * time.Second
+
+	ok, err := s.keeperRepo.Claim(id, keeper.ID, now, now.Add(lease))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		job, err := s.keeperRepo.GetJob(id)
+		if err != nil {
+			return nil, err
+		}
+		if job == nil {
+			return nil, ErrKeeperJobNotFound
+		}
+		return nil, ErrKeeperJobClaimed
+	}
+
+	logger.Info(fmt.Sprintf("Keeper job %d claimed by %s", id, keeper.Address))
+	return s.keeperRepo.GetJob(id)
+}
+
+// Report 上报任务结果。失败的任务在未达到 keeper.max_attempts 前重新开放；
+// 收获任务成功时先在链上校验交易，尚未上链时返回 ErrTxPending；再平衡任务成功时按上报的交易登记提案执行结果
+func (s *KeeperService) Report(ctx context.Context, id uint, keeper *models.Keeper, result KeeperResult) (*models.KeeperJob, error) {
+	if !result.Success && result.Error == "" {
+		return nil, ErrKeeperResult
+	}
+
+	job, err := s.keeperRepo.GetJob(id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrKeeperJobNotFound
+	}
+	now := time.Now()
+	if job.Status != models.KeeperJobClaimed || job.KeeperID == nil || *job.KeeperID != keeper.ID ||
+		job.LeaseExpiresAt == nil || job.LeaseExpiresAt.Before(now) {
+		return nil, ErrKeeperLeaseLost
+	}
+	if result.Success && job.Type == models.KeeperJobHarvest {
+		if result.TxHash == "" {
+			return nil, ErrKeeperResult
+		}
+		if err := verifyHarvestTx(ctx, job, keeper, result.TxHash); err != nil {
+			return nil, err
+		}
+	}
+
+	status := models.KeeperJobSucceeded
+	txHash := result.TxHash
+	if !result.Success {
+		status = models.KeeperJobPending
+		if job.Attempts >= config.Load().Keeper.MaxAttempts {
+			status = models.KeeperJobFailed
+		}
+	} else if job.Type == models.KeeperJobRebalance {
+		if txHash, err = s.executeRebalance(job, keeper, result.Transactions); err != nil {
+			return nil, err
+		}
+	}
+
+	ok, err := s.keeperRepo.Finish(id, keeper.ID, now, status, txHash, result.Error)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrKeeperLeaseLost
+	}
+
+	if result.Success {
+		logger.Info(fmt.Sprintf("Keeper job %d (%s %s) executed by %s: %s", id, job.Type, job.Target, keeper.Address, txHash))
+	} else {
+		logger.Info(fmt.Sprintf("Keeper job %d (%s %s) failed on attempt %d by %s: %s", id, job.Type, job.Target, job.Attempts, keeper.Address, result.Error))
+	}
+	return s.keeperRepo.GetJob(id)
+}
+
+// verifyHarvestTx 确认收获交易已成功上链、由keeper登记的地址发出并调用了任务策略的 harvest()。
+// 模拟链上所有交易立即成功，只检查回执
+func verifyHarvestTx(ctx context.Context, job *models.KeeperJob, keeper *models.Keeper, txHash string) error {
+	receipt, err := blockchain.TransactionReceipt(ctx, job.ChainID, txHash)
+	if err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return ErrTxPending
+		}
+		return err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("%w: transaction reverted", ErrKeeperTx)
+	}
+	if blockchain.MockEnabled() {
+		return nil
+	}
+
+	tx, from, err := blockchain.TransactionByHash(ctx, job.ChainID, txHash)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(from, keeper.Address) {
+		return fmt.Errorf("%w: sent by %s, not the keeper", ErrKeeperTx, from)
+	}
+	if tx.To() == nil || !strings.EqualFold(tx.To().Hex(), job.Target) {
+		return fmt.Errorf("%w: not sent to strategy %s", ErrKeeperTx, job.Target)
+	}
+	if !strings.HasPrefix(hexutil.Encode(tx.Data()), harvestCalldata) {
+		return fmt.Errorf("%w: did not call harvest()", ErrKeeperTx)
+	}
+	return nil
+}
+
+// executeRebalance 登记keeper提交的调仓交易，返回第一笔交易哈希作为任务的代表交易
+func (s *KeeperService) executeRebalance(job *models.KeeperJob, keeper *models.Keeper, executions []RebalanceExecution) (string, error) {
+	if len(executions) == 0 {
+		return "", ErrKeeperExecutions
+	}
+	proposalID, err := strconv.ParseUint(job.Target, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid rebalance job target %q: %w", job.Target, err)
+	}
+	if _, err := s.rebalance.Execute(uint(proposalID), keeper.Address, executions); err != nil {
+		return "", err
+	}
+	return executions[0].TxHash, nil
+}
+
+// GenerateJobs 为到期的策略生成收获任务、为已批准的再平衡提案生成执行任务，
+// 并取消目标已不需要执行的待领取任务。由worker定时调用。
+// 最近一个收获周期内已有成功或最终失败任务的策略不再生成，避免在索引器更新上次收获时间之前重复收获、
+// 或绕过 keeper.max_attempts 反复重试；最终失败的再平衡提案不再生成任务
+func (s *KeeperService) GenerateJobs(ctx context.Context) error {
+	now := time.Now()
+	due := now.Add(-time.Duration(config.Load().Keeper.HarvestInterval) * time.Hour)
+
+	harvests, err := s.harvestJobs(due)
+	if err != nil {
+		return err
+	}
+	rebalances, err := s.rebalanceJobs()
+	if err != nil {
+		return err
+	}
+
+	for _, group := range []struct {
+		jobType string
+		jobs    []models.KeeperJob
+		since   time.Time
+	}{
+		{models.KeeperJobHarvest, harvests, due},
+		{models.KeeperJobRebalance, rebalances, time.Time{}},
+	} {
+		jobType := group.jobType
+		targets := make([]string, 0, len(group.jobs))
+		for _, job := range group.jobs {
+			targets = append(targets, job.Target)
+		}
+		if _, err := s.keeperRepo.CancelStale(jobType, targets, now, staleJobReason); err != nil {
+			return err
+		}
+
+		finished, err := s.keeperRepo.FinishedTargets(jobType, group.since)
+		if err != nil {
+			return err
+		}
+		jobs := make([]models.KeeperJob, 0, len(group.jobs))
+		for _, job := range group.jobs {
+			if !finished[job.Target] {
+				jobs = append(jobs, job)
+			}
+		}
+		created, err := s.keeperRepo.CreateJobs(jobs)
+		if err != nil {
+			return err
+		}
+		if created > 0 {
+			logger.Info(fmt.Sprintf("Created %d %s keeper jobs", created, jobType))
+		}
+	}
+	return nil
+}
+
+// harvestJobs 活跃资金库中上次收获早于due(距今 keeper.harvest_interval)的策略
+func (s *KeeperService) harvestJobs(due time.Time) ([]models.KeeperJob, error) {
+	vaults, err := s.vaultRepo.GetActiveVaults()
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []models.KeeperJob
+	for _, vault := range vaults {
+		for _, st := range vault.Strategies {
+			if st.LastHarvest != nil && st.LastHarvest.After(due) {
+				continue
+			}
+			payload, _ := json.Marshal(HarvestJobPayload{
+				Strategy:    st.Address,
+				To:          st.Address,
+				Data:        harvestCalldata,
+				LastHarvest: st.LastHarvest,
+			})
+			jobs = append(jobs, models.KeeperJob{
+				Type:         models.KeeperJobHarvest,
+				ChainID:      vault.ChainID,
+				VaultAddress: vault.Address,
+				Target:       st.Address,
+				Payload:      payload,
+				Status:       models.KeeperJobPending,
+			})
+		}
+	}
+	return jobs, nil
+}
+
+// rebalanceJobs 已批准但尚未执行的再平衡提案
+func (s *KeeperService) rebalanceJobs() ([]models.KeeperJob, error) {
+	proposals, err := s.rebalanceRepo.List(models.RebalanceStatusApproved, 200)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]models.KeeperJob, 0, len(proposals))
+	for _, proposal := range proposals {
+		vault, err := s.vaultRepo.GetByAddress(proposal.VaultAddress)
+		if err != nil {
+			return nil, err
+		}
+		if vault == nil {
+			continue
+		}
+
+		payload := RebalanceJobPayload{ProposalID: proposal.ID}
+		for _, item := range proposal.Items {
+			if item.TargetAssets.Equal(item.CurrentAssets) {
+				continue
+			}
+			payload.Items = append(payload.Items, RebalanceJobTarget{
+				Strategy:      item.StrategyAddress,
+				CurrentAssets: item.CurrentAssets,
+				TargetAssets:  item.TargetAssets,
+				TargetBps:     item.TargetBps,
+			})
+		}
+		data, _ := json.Marshal(payload)
+		jobs = append(jobs, models.KeeperJob{
+			Type:         models.KeeperJobRebalance,
+			ChainID:      vault.ChainID,
+			VaultAddress: proposal.VaultAddress,
+			Target:       strconv.FormatUint(uint64(proposal.ID), 10),
+			Payload:      data,
+			Status:       models.KeeperJobPending,
+		})
+	}
+	return jobs, nil
+}
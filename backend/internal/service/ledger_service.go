@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/shopspring/decimal"
+)
+
+var ErrInvalidJournalKind = errors.New("journal kind must be one of deposit, withdraw, harvest, fee")
+
+// TrialBalance 试算平衡：各账户发生额及借贷合计，账本正确时两者相等
+type TrialBalance struct {
+	Accounts []repository.AccountBalance `json:"accounts"`
+	Debits   decimal.Decimal             `json:"debits"`
+	Credits  decimal.Decimal             `json:"credits"`
+	Balanced bool                        `json:"balanced"`
+}
+
+// VaultTotals 由账本汇总得出的资金库累计数，StrategyEarnings 按策略拆分收获
+type VaultTotals struct {
+	VaultAddress     string                     `json:"vault_address"`
+	Deposits         decimal.Decimal            `json:"deposits"`
+	Withdrawals      decimal.Decimal            `json:"withdrawals"`
+	Earnings         decimal.Decimal            `json:"earnings"`
+	Fees             decimal.Decimal            `json:"fees"`
+	StrategyEarnings map[string]decimal.Decimal `json:"strategy_earnings"`
+}
+
+// TotalDrift 资金库或策略上保存的汇总字段与账本不一致的一项
+type TotalDrift struct {
+	Target       string          `json:"target"`
+	Address      string          `json:"address"`
+	VaultAddress string          `json:"vault_address"`
+	Field        string          `json:"field"`
+	Stored       decimal.Decimal `json:"stored"`
+	Derived      decimal.Decimal `json:"derived"`
+}
+
+type LedgerService struct {
+	repo      *repository.LedgerRepository
+	auditRepo *repository.AuditRepository
+}
+
+func NewLedgerService() *LedgerService {
+	return &LedgerService{
+		repo:      repository.NewLedgerRepository(),
+		auditRepo: repository.NewAuditRepository(),
+	}
+}
+
+// ListJournals 分页获取凭证，kind 为空时返回全部类型
+func (s *LedgerService) ListJournals(vaultAddress, kind string, cursor *repository.Cursor, limit int) ([]models.LedgerJournal, *repository.Page, error) {
+	switch kind {
+	case "", models.JournalDeposit, models.JournalWithdraw, models.JournalHarvest, models.JournalFee:
+	default:
+		return nil, nil, ErrInvalidJournalKind
+	}
+	return s.repo.ListJournals(vaultAddress, kind, cursor, limit)
+}
+
+// TrialBalance 汇总账户余额并校验借贷合计
+func (s *LedgerService) TrialBalance(vaultAddress string) (*TrialBalance, error) {
+	accounts, err := s.repo.Balances(vaultAddress)
+	if err != nil {
+		return nil, err
+	}
+	trial := &TrialBalance{Accounts: accounts, Debits: decimal.Zero, Credits: decimal.Zero}
+	for _, account := range accounts {
+		trial.Debits = trial.Debits.Add(account.Debits)
+		trial.Credits = trial.Credits.Add(account.Credits)
+	}
+	trial.Balanced = trial.Debits.Equal(trial.Credits)
+	return trial, nil
+}
+
+// Totals 按账本重算截至 at 的资金库累计存取款、收获和费用，历史任一时点的数字都可由凭证复现
+func (s *LedgerService) Totals(vaultAddress string, at time.Time) ([]VaultTotals, error) {
+	rows, err := s.repo.Totals(vaultAddress, at)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := []VaultTotals{}
+	index := make(map[string]int)
+	for _, row := range rows {
+		i, ok := index[row.VaultAddress]
+		if !ok {
+			i = len(totals)
+			index[row.VaultAddress] = i
+			totals = append(totals, VaultTotals{VaultAddress: row.VaultAddress, StrategyEarnings: map[string]decimal.Decimal{}})
+		}
+		vault := &totals[i]
+		switch row.Kind {
+		case models.JournalDeposit:
+			vault.Deposits = vault.Deposits.Add(row.Amount)
+		case models.JournalWithdraw:
+			vault.Withdrawals = vault.Withdrawals.Add(row.Amount)
+		case models.JournalHarvest:
+			vault.Earnings = vault.Earnings.Add(row.Amount)
+			if row.StrategyAddress != "" {
+				vault.StrategyEarnings[row.StrategyAddress] = vault.StrategyEarnings[row.StrategyAddress].Add(row.Amount)
+			}
+		case models.JournalFee:
+			vault.Fees = vault.Fees.Add(row.Amount)
+		}
+	}
+	return totals, nil
+}
+
+// Drift 比较资金库和策略上保存的汇总字段与账本，返回不一致的项目
+func (s *LedgerService) Drift() ([]TotalDrift, error) {
+	stored, err := s.repo.StoredTotals()
+	if err != nil {
+		return nil, err
+	}
+
+	drift := []TotalDrift{}
+	check := func(row repository.StoredTotals, field string, value, derived decimal.Decimal) {
+		if !value.Equal(derived) {
+			drift = append(drift, TotalDrift{Target: row.Target, Address: row.Address, VaultAddress: row.VaultAddress, Field: field, Stored: value, Derived: derived})
+		}
+	}
+	for _, row := range stored {
+		if row.Target == "strategy" {
+			check(row, "total_earnings", row.TotalEarnings, row.DerivedEarnings)
+			continue
+		}
+		check(row, "total_deposits", row.TotalDeposits, row.DerivedDeposits)
+		check(row, "total_withdrawals", row.TotalWithdrawals, row.DerivedWithdrawals)
+	}
+	return drift, nil
+}
+
+// RecomputeTotals 按账本重写全部汇总字段，返回被更正的项目；存在更正时记录审计日志
+func (s *LedgerService) RecomputeTotals(ctx context.Context, actor string) ([]TotalDrift, error) {
+	drift, err := s.Drift()
+	if err != nil {
+		return nil, err
+	}
+	if len(drift) == 0 {
+		return drift, nil
+	}
+	if err := s.repo.RecomputeTotals(); err != nil {
+		return nil, err
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"corrections": drift,
+	})
+	if err := s.auditRepo.Create(&models.AuditLog{
+		Actor:   actor,
+		Action:  models.AuditLedgerRebuild,
+		Details: details,
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, item := range drift {
+		InvalidateVault(ctx, item.VaultAddress)
+	}
+	logger.Info(fmt.Sprintf("Ledger totals recomputed by %s, %d field(s) corrected", actor, len(drift)))
+	return drift, nil
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/prices"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrInvalidLPPosition  = errors.New("uniswap_v2 pools take exactly 2 tokens and curve pools 2 to 8 distinct tokens")
+	ErrLPPriceUnavailable = errors.New("lp token price unavailable")
+)
+
+// LPTokenPrice LP池中单个代币建仓时和当前的USD价格
+type LPTokenPrice struct {
+	TokenAddress    string          `json:"token_address"`
+	EntryPriceUSD   decimal.Decimal `json:"entry_price_usd"`
+	CurrentPriceUSD float64         `json:"current_price_usd"`
+	PriceRatio      float64         `json:"price_ratio"` // 当前价格/建仓价格
+}
+
+// ILEstimate LP策略相对建仓后持币不动的估算无常损失。ImpermanentLoss 为比例(0或负数)，
+// LossAmount 为按当前资产规模折算的损失(底层资产)，NetEarnings 为累计收益扣除该损失
+type ILEstimate struct {
+	PoolType        string          `json:"pool_type"`
+	EntryAt         *time.Time      `json:"entry_at"`
+	Tokens          []LPTokenPrice  `json:"tokens"`
+	ImpermanentLoss float64         `json:"impermanent_loss"`
+	LossAmount      decimal.Decimal `json:"loss_amount"`
+	NetEarnings     decimal.Decimal `json:"net_earnings"`
+}
+
+// LPService 为Curve/Uniswap等LP类策略记录建仓时的池内代币价格，并按价格偏离估算无常损失
+type LPService struct {
+	vaultRepo repository.VaultRepo
+	lpRepo    *repository.LPRepository
+	auditRepo *repository.AuditRepository
+	prices    *prices.Service
+}
+
+func NewLPService() *LPService {
+	return NewLPServiceWith(repository.Default())
+}
+
+// NewLPServiceWith 使用注入的核心仓储构建
+func NewLPServiceWith(repos *repository.Repositories) *LPService {
+	return &LPService{
+		vaultRepo: repos.Vaults,
+		lpRepo:    repository.NewLPRepository(),
+		auditRepo: repository.NewAuditRepository(),
+		prices:    prices.Default(),
+	}
+}
+
+// SetPosition 把策略标记为LP策略，以当前价格作为估算无常损失的基准。
+// 再次调用会替换代币列表并重置基准，用于策略调仓或换池之后
+func (s *LPService) SetPosition(ctx context.Context, strategy *models.Strategy, actor, poolType string, tokens []string, reason string) (*ILEstimate, error) {
+	if !validLPTokens(poolType, tokens) {
+		return nil, ErrInvalidLPPosition
+	}
+	chainID, err := s.chainOf(strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]models.StrategyLPToken, 0, len(tokens))
+	for _, token := range tokens {
+		price, err := s.prices.GetPrice(ctx, token, chainID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrLPPriceUnavailable, token, err)
+		}
+		entries = append(entries, models.StrategyLPToken{
+			TokenAddress:  token,
+			EntryPriceUSD: decimal.NewFromFloat(price.USD),
+		})
+	}
+
+	now := time.Now()
+	if err := s.lpRepo.SetPosition(strategy.Address, poolType, now, entries); err != nil {
+		return nil, err
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"reason":            reason,
+		"previous_pool":     strategy.LPPoolType,
+		"previous_entry_at": strategy.LPEntryAt,
+		"pool_type":         poolType,
+		"tokens":            entries,
+	})
+	if err := s.auditRepo.Create(&models.AuditLog{
+		Actor:   actor,
+		Action:  models.AuditSetStrategyLP,
+		Target:  strategy.Address,
+		Details: details,
+	}); err != nil {
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("Strategy %s LP position set to %s %v by %s: %s", strategy.Address, poolType, tokens, actor, reason))
+	strategy.LPPoolType = poolType
+	strategy.LPEntryAt = &now
+	InvalidateVault(ctx, strategy.VaultAddress)
+	return s.estimate(ctx, strategy, chainID)
+}
+
+// Estimate 估算LP策略当前的无常损失，非LP策略返回nil
+func (s *LPService) Estimate(ctx context.Context, strategy *models.Strategy) (*ILEstimate, error) {
+	if strategy.LPPoolType == "" {
+		return nil, nil
+	}
+	chainID, err := s.chainOf(strategy)
+	if err != nil {
+		return nil, err
+	}
+	return s.estimate(ctx, strategy, chainID)
+}
+
+func (s *LPService) estimate(ctx context.Context, strategy *models.Strategy, chainID uint) (*ILEstimate, error) {
+	if strategy.LPPoolType == "" {
+		return nil, nil
+	}
+	tokens, err := s.lpRepo.Tokens(strategy.Address)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) < 2 {
+		return nil, nil
+	}
+
+	result := &ILEstimate{
+		PoolType: strategy.LPPoolType,
+		EntryAt:  strategy.LPEntryAt,
+		Tokens:   make([]LPTokenPrice, 0, len(tokens)),
+	}
+	ratios := make([]float64, 0, len(tokens))
+	for _, token := range tokens {
+		price, err := s.prices.GetPrice(ctx, token.TokenAddress, chainID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrLPPriceUnavailable, token.TokenAddress, err)
+		}
+		entry := token.EntryPriceUSD.InexactFloat64()
+		if entry <= 0 {
+			return nil, fmt.Errorf("%w: %s has no entry price", ErrLPPriceUnavailable, token.TokenAddress)
+		}
+		ratio := price.USD / entry
+		ratios = append(ratios, ratio)
+		result.Tokens = append(result.Tokens, LPTokenPrice{
+			TokenAddress:    token.TokenAddress,
+			EntryPriceUSD:   token.EntryPriceUSD,
+			CurrentPriceUSD: price.USD,
+			PriceRatio:      ratio,
+		})
+	}
+
+	il := impermanentLoss(ratios)
+	result.ImpermanentLoss = il
+	// 当前LP价值 = 持币价值 * (1 + il)，损失 = 持币价值 - LP价值
+	if il < 0 && il > -1 {
+		result.LossAmount = strategy.TotalAssets.Mul(decimal.NewFromFloat(-il / (1 + il))).Round(18)
+	}
+	result.NetEarnings = strategy.TotalEarnings.Sub(result.LossAmount)
+	return result, nil
+}
+
+// impermanentLoss 等权重恒定乘积池相对持币不动的价值变化：各代币价格比的几何平均 / 算术平均 - 1。
+// 两种代币时即 2*sqrt(r)/(1+r) - 1
+func impermanentLoss(ratios []float64) float64 {
+	if len(ratios) == 0 {
+		return 0
+	}
+	logSum, sum := 0.0, 0.0
+	for _, r := range ratios {
+		if r <= 0 {
+			return -1
+		}
+		logSum += math.Log(r)
+		sum += r
+	}
+	n := float64(len(ratios))
+	return math.Exp(logSum/n)/(sum/n) - 1
+}
+
+func validLPTokens(poolType string, tokens []string) bool {
+	seen := make(map[string]bool, len(tokens))
+	for _, token := range tokens {
+		if seen[token] {
+			return false
+		}
+		seen[token] = true
+	}
+	switch poolType {
+	case models.LPPoolUniswapV2:
+		return len(tokens) == 2
+	case models.LPPoolCurve:
+		return len(tokens) >= 2 && len(tokens) <= 8
+	}
+	return false
+}
+
+func (s *LPService) chainOf(strategy *models.Strategy) (uint, error) {
+	vault, err := s.vaultRepo.GetByAddress(strategy.VaultAddress)
+	if err != nil {
+		return 0, err
+	}
+	if vault == nil {
+		return 0, fmt.Errorf("vault %s of strategy %s not found", strategy.VaultAddress, strategy.Address)
+	}
+	return vault.ChainID, nil
+}
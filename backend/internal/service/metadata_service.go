@@ -0,0 +1,111 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+)
+
+var ErrInvalidMetadataURL = errors.New("logo, link and audit URLs must be absolute https URLs")
+
+// MetadataUpdate 管理员提交的展示资料，整体替换原有内容
+type MetadataUpdate struct {
+	Description   string
+	LogoURL       string
+	Links         []models.MetadataLink
+	Audits        []models.MetadataAudit
+	InceptionDate *time.Time
+}
+
+// MetadataService 维护资金库和策略的展示资料(简介、logo、协议链接、审计报告、上线日期)
+type MetadataService struct {
+	repo      *repository.MetadataRepository
+	auditRepo *repository.AuditRepository
+}
+
+func NewMetadataService() *MetadataService {
+	return &MetadataService{
+		repo:      repository.NewMetadataRepository(),
+		auditRepo: repository.NewAuditRepository(),
+	}
+}
+
+// Set 保存资金库或策略的展示资料并写入审计日志。所有URL必须为https，避免前端加载不安全的资源
+func (s *MetadataService) Set(kind, address, actor string, update MetadataUpdate) (*models.DisplayMetadata, error) {
+	metadata := &models.DisplayMetadata{
+		Address:       address,
+		Kind:          kind,
+		Description:   strings.TrimSpace(update.Description),
+		LogoURL:       strings.TrimSpace(update.LogoURL),
+		Links:         make([]models.MetadataLink, 0, len(update.Links)),
+		Audits:        make([]models.MetadataAudit, 0, len(update.Audits)),
+		InceptionDate: update.InceptionDate,
+		UpdatedBy:     actor,
+	}
+	if metadata.LogoURL != "" && !httpsURL(metadata.LogoURL) {
+		return nil, ErrInvalidMetadataURL
+	}
+	for _, link := range update.Links {
+		link.Label, link.URL = strings.TrimSpace(link.Label), strings.TrimSpace(link.URL)
+		if !httpsURL(link.URL) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidMetadataURL, link.URL)
+		}
+		metadata.Links = append(metadata.Links, link)
+	}
+	for _, audit := range update.Audits {
+		audit.Auditor, audit.URL = strings.TrimSpace(audit.Auditor), strings.TrimSpace(audit.URL)
+		if !httpsURL(audit.URL) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidMetadataURL, audit.URL)
+		}
+		metadata.Audits = append(metadata.Audits, audit)
+	}
+
+	if err := s.repo.Upsert(metadata); err != nil {
+		return nil, err
+	}
+	details, _ := json.Marshal(metadata)
+	if err := s.auditRepo.Create(&models.AuditLog{
+		Actor:   actor,
+		Action:  models.AuditSetMetadata,
+		Target:  address,
+		Details: details,
+	}); err != nil {
+		return nil, err
+	}
+	logger.Info(fmt.Sprintf("Display metadata of %s %s updated by %s", kind, address, actor))
+	return metadata, nil
+}
+
+// Get 获取资金库或策略的展示资料，未设置时返回nil
+func (s *MetadataService) Get(address string) (*models.DisplayMetadata, error) {
+	return s.repo.Get(address)
+}
+
+// ForStrategies 资金库下各策略的展示资料，按策略地址索引，未设置的策略不返回
+func (s *MetadataService) ForStrategies(strategies []models.Strategy) (map[string]*models.DisplayMetadata, error) {
+	addresses := make([]string, 0, len(strategies))
+	for _, st := range strategies {
+		addresses = append(addresses, st.Address)
+	}
+	list, err := s.repo.ListByAddresses(addresses)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]*models.DisplayMetadata, len(list))
+	for i := range list {
+		result[list[i].Address] = &list[i]
+	}
+	return result, nil
+}
+
+func httpsURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && u.Scheme == "https" && u.Host != ""
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/events"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/shopspring/decimal"
+)
+
+// MockChainService 模拟链模式(blockchain.mock.enabled)下代替链上合约和索引器：
+// 存取款直接生成事件并立即确认，资金库和策略APY按脚本曲线变化
+type MockChainService struct {
+	eventService *EventService
+	vaultRepo    repository.VaultRepo
+	strategyRepo repository.StrategyRepo
+	vaultService *VaultService
+}
+
+func NewMockChainService() *MockChainService {
+	return NewMockChainServiceWith(repository.Default())
+}
+
+// NewMockChainServiceWith 使用注入的核心仓储构建
+func NewMockChainServiceWith(repos *repository.Repositories) *MockChainService {
+	return &MockChainService{
+		eventService: NewEventServiceWith(repos),
+		vaultRepo:    repos.Vaults,
+		strategyRepo: repos.Strategies,
+		vaultService: NewVaultServiceWith(repos),
+	}
+}
+
+// Deposit 模拟存款立即上链，份额与资产按1:1兑换
+func (s *MockChainService) Deposit(ctx context.Context, vault *models.Vault, user string, amount decimal.Decimal) (*events.ChainEvent, error) {
+	return s.transfer(ctx, events.TypeDeposit, vault, user, amount)
+}
+
+// Withdraw 模拟按份额取款立即上链
+func (s *MockChainService) Withdraw(ctx context.Context, vault *models.Vault, user string, shares decimal.Decimal) (*events.ChainEvent, error) {
+	return s.transfer(ctx, events.TypeWithdraw, vault, user, shares)
+}
+
+// transfer 生成与索引器格式相同的事件并走正常的物化流程，交易记录、TVL和用户通知与真实链上一致
+func (s *MockChainService) transfer(ctx context.Context, kind string, vault *models.Vault, user string, amount decimal.Decimal) (*events.ChainEvent, error) {
+	block, err := blockchain.BlockNumber(ctx, vault.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	event := &events.ChainEvent{
+		Type:        kind,
+		ChainID:     vault.ChainID,
+		BlockNumber: block,
+		TxHash:      blockchain.MockTxHash(kind, vault.Address, user, amount.String()),
+		Vault:       vault.Address,
+		User:        user,
+		Assets:      amount,
+		Shares:      amount,
+		Timestamp:   time.Now(),
+	}
+	if err := s.eventService.Apply(ctx, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// Tick 按脚本曲线更新所有活跃资金库和策略的APY，模拟链模式下代替 vault-sync 任务
+func (s *MockChainService) Tick(ctx context.Context) error {
+	vaults, err := s.vaultRepo.GetActiveVaults()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, vault := range vaults {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		for _, st := range vault.Strategies {
+			if err := s.strategyRepo.UpdateAPY(st.Address, blockchain.MockAPY(st.Address, now)); err != nil {
+				return err
+			}
+		}
+
+		// 周APY取过去7天每天同一时刻的平均值
+		var weekly float64
+		for day := 0; day < 7; day++ {
+			weekly += blockchain.MockAPY(vault.Address, now.AddDate(0, 0, -day))
+		}
+		if err := s.vaultService.UpdateVaultStats(vault.Address, vault.TVL, blockchain.MockAPY(vault.Address, now), weekly/7); err != nil {
+			return err
+		}
+	}
+
+	logger.Info(fmt.Sprintf("Mock chain updated APY of %d vault(s)", len(vaults)))
+	return nil
+}
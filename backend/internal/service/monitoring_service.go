@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/cache"
+	"github.com/chspring1/mya-platform/backend/pkg/database"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/metrics"
+)
+
+// 查询链上最新区块的超时，RPC慢时不拖慢监控接口
+const headBlockTimeout = 3 * time.Second
+
+// DBPoolStats 数据库连接池使用情况
+type DBPoolStats struct {
+	MaxOpen      int    `json:"max_open"`
+	Open         int    `json:"open"`
+	InUse        int    `json:"in_use"`
+	Idle         int    `json:"idle"`
+	WaitCount    int64  `json:"wait_count"`
+	WaitDuration string `json:"wait_duration"`
+}
+
+// CacheStats 进程启动以来的缓存命中情况
+type CacheStats struct {
+	Hits     uint64   `json:"hits"`
+	Misses   uint64   `json:"misses"`
+	HitRatio *float64 `json:"hit_ratio"` // 尚无读取时为空
+}
+
+// MonitoringData 管理后台监控数据
+type MonitoringData struct {
+	Requests    metrics.WindowStats `json:"requests"`
+	Database    *DBPoolStats        `json:"database"`
+	Cache       CacheStats          `json:"cache"`
+	Indexer     []IndexerProgress   `json:"indexer"`
+	Goroutines  int                 `json:"goroutines"`
+	LastUpdated time.Time           `json:"last_updated"`
+}
+
+type MonitoringService struct{}
+
+func NewMonitoringService() *MonitoringService {
+	return &MonitoringService{}
+}
+
+// GetMonitoringData 汇总请求速率、错误率、延迟分位数、连接池、缓存命中率和索引延迟。
+// 请求和缓存统计仅覆盖当前实例
+func (s *MonitoringService) GetMonitoringData(ctx context.Context) *MonitoringData {
+	data := &MonitoringData{
+		Requests:    metrics.Requests.Snapshot(),
+		Database:    dbPoolStats(),
+		Cache:       cacheStats(),
+		Goroutines:  runtime.NumGoroutine(),
+		LastUpdated: time.Now(),
+	}
+
+	progress, err := loadIndexerProgress(ctx)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to load indexer progress: %v", err))
+	}
+	for i := range progress {
+		p := &progress[i]
+		headCtx, cancel := context.WithTimeout(ctx, headBlockTimeout)
+		head, err := blockchain.BlockNumber(headCtx, p.ChainID)
+		cancel()
+		if err != nil {
+			continue
+		}
+		lag := uint64(0)
+		if head > p.LastBlock {
+			lag = head - p.LastBlock
+		}
+		p.HeadBlock, p.LagBlocks = &head, &lag
+	}
+	sort.Slice(progress, func(i, j int) bool { return progress[i].ChainID < progress[j].ChainID })
+	data.Indexer = progress
+
+	return data
+}
+
+func dbPoolStats() *DBPoolStats {
+	db := database.GetDB()
+	if db == nil {
+		return nil
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil
+	}
+	stats := sqlDB.Stats()
+	return &DBPoolStats{
+		MaxOpen:      stats.MaxOpenConnections,
+		Open:         stats.OpenConnections,
+		InUse:        stats.InUse,
+		Idle:         stats.Idle,
+		WaitCount:    stats.WaitCount,
+		WaitDuration: stats.WaitDuration.String(),
+	}
+}
+
+func cacheStats() CacheStats {
+	hits, misses := cache.Stats()
+	stats := CacheStats{Hits: hits, Misses: misses}
+	if total := hits + misses; total > 0 {
+		ratio := float64(hits) / float64(total)
+		stats.HitRatio = &ratio
+	}
+	return stats
+}
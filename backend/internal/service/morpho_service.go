@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/morpho"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/shopspring/decimal"
+)
+
+// Morpho市场的风险因素，每项在再平衡时使策略风险分加1
+const (
+	MorphoRiskHighUtilization   = "high_utilization"
+	MorphoRiskHighLLTV          = "high_lltv"
+	MorphoRiskOracleUnavailable = "oracle_unavailable"
+)
+
+var (
+	ErrInvalidMorphoMarket = errors.New("market_id must be an existing Morpho Blue market lending the vault asset")
+	ErrMorphoUnreadable    = errors.New("morpho market could not be read on chain")
+)
+
+// MorphoLending 借贷策略所在市场的利率和风险输入，RiskFactors 为空表示没有额外风险
+type MorphoLending struct {
+	Market         *models.MorphoMarket `json:"market"`
+	RiskFactors    []string             `json:"risk_factors"`
+	RiskAdjustment uint8                `json:"risk_adjustment"` // 再平衡时在策略风险分上增加的分数
+}
+
+// MorphoService 跟踪供应到Morpho Blue市场的策略：定期读取市场状态，以供应APY作为策略APY，
+// 利用率、LLTV和预言机状态作为再平衡的风险输入
+type MorphoService struct {
+	vaultRepo    repository.VaultRepo
+	strategyRepo repository.StrategyRepo
+	repo         *repository.MorphoRepository
+	auditRepo    *repository.AuditRepository
+}
+
+func NewMorphoService() *MorphoService {
+	return NewMorphoServiceWith(repository.Default())
+}
+
+// NewMorphoServiceWith 使用注入的核心仓储构建
+func NewMorphoServiceWith(repos *repository.Repositories) *MorphoService {
+	return &MorphoService{
+		vaultRepo:    repos.Vaults,
+		strategyRepo: repos.Strategies,
+		repo:         repository.NewMorphoRepository(),
+		auditRepo:    repository.NewAuditRepository(),
+	}
+}
+
+// SetMarket 登记策略供应资金的市场，morphoAddress 为空时使用 morpho.address。
+// 市场的借出资产必须是资金库的底层资产，登记后立即读取一次状态并更新策略APY
+func (s *MorphoService) SetMarket(ctx context.Context, strategy *models.Strategy, actor, morphoAddress, marketID string) (*MorphoLending, error) {
+	cfg := config.Load().Morpho
+	if morphoAddress == "" {
+		morphoAddress = cfg.Address
+	}
+	raw, err := hexutil.Decode(marketID)
+	if err != nil || len(raw) != common.HashLength {
+		return nil, ErrInvalidMorphoMarket
+	}
+	vault, err := s.vaultOf(strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := morpho.Read(ctx, vault.ChainID, morphoAddress, common.BytesToHash(raw))
+	if err != nil {
+		if errors.Is(err, morpho.ErrMarketNotFound) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidMorphoMarket, err)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrMorphoUnreadable, err)
+	}
+	if !strings.EqualFold(m.Params.LoanToken.Hex(), vault.AssetAddress) {
+		return nil, fmt.Errorf("%w: market lends %s, vault asset is %s", ErrInvalidMorphoMarket, m.Params.LoanToken.Hex(), vault.AssetAddress)
+	}
+
+	market := &models.MorphoMarket{
+		StrategyAddress: strategy.Address,
+		Morpho:          morphoAddress,
+		MarketID:        strings.ToLower(marketID),
+		LoanToken:       strings.ToLower(m.Params.LoanToken.Hex()),
+		CollateralToken: strings.ToLower(m.Params.CollateralToken.Hex()),
+		Oracle:          strings.ToLower(m.Params.Oracle.Hex()),
+		IRM:             strings.ToLower(m.Params.Irm.Hex()),
+		LLTV:            m.LLTV(),
+	}
+	if market.LoanDecimals, err = blockchain.TokenDecimals(ctx, vault.ChainID, market.LoanToken); err != nil {
+		return nil, err
+	}
+	// 闲置市场没有抵押品
+	if m.Params.CollateralToken != (common.Address{}) {
+		if market.CollateralDecimals, err = blockchain.TokenDecimals(ctx, vault.ChainID, market.CollateralToken); err != nil {
+			return nil, err
+		}
+	}
+	applyMorphoReadings(market, m)
+	if err := s.repo.Upsert(market); err != nil {
+		return nil, err
+	}
+	if err := s.strategyRepo.UpdateAPY(strategy.Address, market.SupplyAPY); err != nil {
+		return nil, err
+	}
+	strategy.APY = market.SupplyAPY
+
+	details, _ := json.Marshal(market)
+	if err := s.auditRepo.Create(&models.AuditLog{
+		Actor:   actor,
+		Action:  models.AuditSetMorpho,
+		Target:  strategy.Address,
+		Details: details,
+	}); err != nil {
+		return nil, err
+	}
+	logger.Info(fmt.Sprintf("Strategy %s Morpho market set to %s by %s", strategy.Address, market.MarketID, actor))
+	InvalidateVault(ctx, strategy.VaultAddress)
+	return morphoLending(market, cfg), nil
+}
+
+// SyncAll 读取全部登记市场的当前状态，更新市场记录和策略APY。单个市场失败不影响其他市场
+func (s *MorphoService) SyncAll(ctx context.Context) error {
+	markets, err := s.repo.List()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := range markets {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := s.sync(ctx, &markets[i]); err != nil {
+			errs = append(errs, fmt.Errorf("strategy %s: %w", markets[i].StrategyAddress, err))
+		}
+	}
+	if len(markets) > 0 {
+		logger.Info(fmt.Sprintf("Synced %d Morpho market(s), %d failed", len(markets), len(errs)))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *MorphoService) sync(ctx context.Context, market *models.MorphoMarket) error {
+	strategy, err := s.strategyRepo.GetByAddress(market.StrategyAddress)
+	if err != nil {
+		return err
+	}
+	if strategy == nil {
+		return fmt.Errorf("strategy %s not found", market.StrategyAddress)
+	}
+	vault, err := s.vaultOf(strategy)
+	if err != nil {
+		return err
+	}
+
+	m, err := morpho.Read(ctx, vault.ChainID, market.Morpho, common.HexToHash(market.MarketID))
+	if err != nil {
+		return err
+	}
+	applyMorphoReadings(market, m)
+	if err := s.repo.UpdateReadings(market); err != nil {
+		return err
+	}
+	if err := s.strategyRepo.UpdateAPY(strategy.Address, market.SupplyAPY); err != nil {
+		return err
+	}
+	InvalidateVault(ctx, strategy.VaultAddress)
+	return nil
+}
+
+// Status 借贷策略所在市场的最近状态和风险因素，未登记市场的策略返回nil
+func (s *MorphoService) Status(strategyAddress string) (*MorphoLending, error) {
+	market, err := s.repo.Get(strategyAddress)
+	if err != nil || market == nil {
+		return nil, err
+	}
+	return morphoLending(market, config.Load().Morpho), nil
+}
+
+// RiskAdjustments 各策略因所在Morpho市场的风险因素增加的风险分，未登记市场或没有风险因素的策略不返回
+func (s *MorphoService) RiskAdjustments(strategyAddresses []string) (map[string]uint8, error) {
+	markets, err := s.repo.ListByStrategies(strategyAddresses)
+	if err != nil {
+		return nil, err
+	}
+	cfg := config.Load().Morpho
+	adjustments := make(map[string]uint8, len(markets))
+	for i := range markets {
+		if lending := morphoLending(&markets[i], cfg); lending.RiskAdjustment > 0 {
+			adjustments[markets[i].StrategyAddress] = lending.RiskAdjustment
+		}
+	}
+	return adjustments, nil
+}
+
+func (s *MorphoService) vaultOf(strategy *models.Strategy) (*models.Vault, error) {
+	vault, err := s.vaultRepo.GetByAddress(strategy.VaultAddress)
+	if err != nil {
+		return nil, err
+	}
+	if vault == nil {
+		return nil, fmt.Errorf("vault %s of strategy %s not found", strategy.VaultAddress, strategy.Address)
+	}
+	return vault, nil
+}
+
+// applyMorphoReadings 把链上读取的市场状态写入记录，借贷总量按借出资产精度换算
+func applyMorphoReadings(market *models.MorphoMarket, m *morpho.Market) {
+	market.SupplyAPY = m.SupplyAPY()
+	market.BorrowAPY = m.BorrowAPY()
+	market.Utilization = m.Utilization()
+	market.TotalSupplyAssets = decimal.NewFromBigInt(m.TotalSupplyAssets, -market.LoanDecimals)
+	market.TotalBorrowAssets = decimal.NewFromBigInt(m.TotalBorrowAssets, -market.LoanDecimals)
+	market.OraclePrice = m.CollateralPrice(market.LoanDecimals, market.CollateralDecimals)
+	market.BlockNumber = m.BlockNumber
+	market.SyncedAt = time.Now()
+}
+
+// morphoLending 按配置的阈值判断市场的风险因素。没有抵押品的闲置市场不依赖预言机
+func morphoLending(market *models.MorphoMarket, cfg config.MorphoConfig) *MorphoLending {
+	lending := &MorphoLending{Market: market, RiskFactors: []string{}}
+	if market.Utilization >= cfg.HighUtilization {
+		lending.RiskFactors = append(lending.RiskFactors, MorphoRiskHighUtilization)
+	}
+	if market.LLTV >= cfg.HighLLTV {
+		lending.RiskFactors = append(lending.RiskFactors, MorphoRiskHighLLTV)
+	}
+	if market.CollateralToken != strings.ToLower(common.Address{}.Hex()) && (market.OraclePrice == nil || *market.OraclePrice <= 0) {
+		lending.RiskFactors = append(lending.RiskFactors, MorphoRiskOracleUnavailable)
+	}
+	lending.RiskAdjustment = uint8(len(lending.RiskFactors))
+	return lending
+}
@@ -0,0 +1,604 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/queue"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/notify"
+)
+
+var (
+	ErrInvalidChannel      = errors.New("channel type must be email, telegram or webhook with a valid target")
+	ErrChannelNotFound     = errors.New("notification channel not found")
+	ErrInvalidCode         = errors.New("verification code is invalid or expired")
+	ErrInvalidSubscription = errors.New("invalid notification subscription")
+	ErrInvalidAlertRule    = errors.New("invalid APY alert rule")
+	ErrTooManyAlertRules   = errors.New("too many APY alert rules")
+	ErrDuplicateAlertRule  = errors.New("an identical APY alert rule already exists")
+)
+
+// 渠道验证码通知的事件名，webhook负载中的 event 字段
+const eventChannelVerification = "channel_verification"
+
+// 每个验证码最多尝试的次数，6位数字验证码不能被穷举
+const maxCodeAttempts = 5
+
+// telegram chat id 为数字(群组为负数)或 @频道名
+var telegramChatID = regexp.MustCompile(`^(-?\d{1,20}|@[A-Za-z0-9_]{5,32})$`)
+
+// NotificationSettings 用户的通知渠道与订阅
+type NotificationSettings struct {
+	Channels      []models.NotificationChannel      `json:"channels"`
+	Subscriptions []models.NotificationSubscription `json:"subscriptions"`
+	Rules         []models.APYAlertRule             `json:"rules"`
+}
+
+type NotificationService struct {
+	repo       *repository.NotificationRepository
+	vaultRepo  repository.VaultRepo
+	apyRepo    *repository.APYHistoryRepository
+	dispatcher *notify.Dispatcher
+	webhook    *notify.WebhookSender
+	codeTTL    time.Duration
+	maxRules   int
+	cooldown   time.Duration
+}
+
+func NewNotificationService() *NotificationService {
+	return NewNotificationServiceWith(repository.Default())
+}
+
+// NewNotificationServiceWith 使用注入的核心仓储构建
+func NewNotificationServiceWith(repos *repository.Repositories) *NotificationService {
+	cfg := config.Load().Notifications
+	return &NotificationService{
+		repo:       repository.NewNotificationRepository(),
+		vaultRepo:  repos.Vaults,
+		apyRepo:    repository.NewAPYHistoryRepository(),
+		dispatcher: notify.Default(),
+		webhook:    notify.NewWebhookSender(),
+		codeTTL:    time.Duration(cfg.VerificationTTL) * time.Minute,
+		maxRules:   cfg.MaxAlertRules,
+		cooldown:   time.Duration(cfg.AlertCooldown) * time.Minute,
+	}
+}
+
+// GetSettings 获取用户的通知渠道与订阅
+func (s *NotificationService) GetSettings(userAddress string) (*NotificationSettings, error) {
+	channels, err := s.repo.ListChannels(userAddress)
+	if err != nil {
+		return nil, err
+	}
+	subscriptions, err := s.repo.ListSubscriptions(userAddress)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.repo.ListRules(userAddress)
+	if err != nil {
+		return nil, err
+	}
+	return &NotificationSettings{Channels: channels, Subscriptions: subscriptions, Rules: rules}, nil
+}
+
+// RegisterChannel 登记渠道并发送验证码，验证前不会向该渠道发送任何事件通知。
+// webhook渠道同时生成签名密钥，重新登记会更换密钥
+func (s *NotificationService) RegisterChannel(ctx context.Context, userAddress, channelType, target string) (*models.NotificationChannel, error) {
+	target = strings.TrimSpace(target)
+	if !validTarget(channelType, target) {
+		return nil, ErrInvalidChannel
+	}
+
+	code, err := verificationCode()
+	if err != nil {
+		return nil, err
+	}
+	expires := time.Now().Add(s.codeTTL)
+
+	channel := &models.NotificationChannel{
+		UserAddress:           userAddress,
+		Type:                  channelType,
+		Target:                target,
+		VerificationCodeHash:  hashCode(code),
+		VerificationExpiresAt: &expires,
+	}
+	if channelType == notify.ChannelWebhook {
+		if channel.Secret, err = webhookSecret(); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.UpsertChannel(channel); err != nil {
+		return nil, err
+	}
+
+	msg := notify.Message{
+		Subject: "Verify your MYA notification channel",
+		Body: fmt.Sprintf("Your verification code is %s. It expires in %d minutes.\n\nIf you did not request this, ignore this message.",
+			code, int(s.codeTTL.Minutes())),
+	}
+	if err := s.send(ctx, channel, eventChannelVerification, msg); err != nil {
+		return nil, fmt.Errorf("send verification code: %w", err)
+	}
+
+	logger.Info(fmt.Sprintf("Verification code sent to %s channel of %s", channelType, userAddress))
+	return channel, nil
+}
+
+// VerifyChannel 校验验证码，每个验证码最多尝试 maxCodeAttempts 次，用完后需重新登记获取新验证码
+func (s *NotificationService) VerifyChannel(userAddress, channelType, code string) error {
+	channel, err := s.repo.GetChannel(userAddress, channelType)
+	if err != nil {
+		return err
+	}
+	if channel == nil {
+		return ErrChannelNotFound
+	}
+	if channel.Verified {
+		return nil
+	}
+	if channel.VerificationExpiresAt == nil || time.Now().After(*channel.VerificationExpiresAt) {
+		return ErrInvalidCode
+	}
+	claimed, err := s.repo.ClaimCodeAttempt(channel.ID, maxCodeAttempts)
+	if err != nil {
+		return err
+	}
+	if !claimed || subtle.ConstantTimeCompare([]byte(channel.VerificationCodeHash), []byte(hashCode(strings.TrimSpace(code)))) != 1 {
+		return ErrInvalidCode
+	}
+	return s.repo.MarkVerified(channel.ID)
+}
+
+// DeleteChannel 删除渠道
+func (s *NotificationService) DeleteChannel(userAddress, channelType string) error {
+	ok, err := s.repo.DeleteChannel(userAddress, channelType)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrChannelNotFound
+	}
+	return nil
+}
+
+// RotateWebhookSecret 为用户的webhook渠道生成新的签名密钥，旧密钥立即失效
+func (s *NotificationService) RotateWebhookSecret(userAddress string) (string, error) {
+	channel, err := s.repo.GetChannel(userAddress, notify.ChannelWebhook)
+	if err != nil {
+		return "", err
+	}
+	if channel == nil {
+		return "", ErrChannelNotFound
+	}
+
+	secret, err := webhookSecret()
+	if err != nil {
+		return "", err
+	}
+	if err := s.repo.SetSecret(channel.ID, secret); err != nil {
+		return "", err
+	}
+	logger.Info(fmt.Sprintf("Webhook secret rotated for %s", userAddress))
+	return secret, nil
+}
+
+// ListWebhookDeliveries 获取用户webhook渠道最近的投递尝试
+func (s *NotificationService) ListWebhookDeliveries(userAddress string, limit int) ([]models.WebhookDelivery, error) {
+	channel, err := s.repo.GetChannel(userAddress, notify.ChannelWebhook)
+	if err != nil {
+		return nil, err
+	}
+	if channel == nil {
+		return nil, ErrChannelNotFound
+	}
+	return s.repo.ListDeliveries(channel.ID, limit)
+}
+
+// Subscribe 订阅事件，apy_below 必须指定资金库和阈值
+func (s *NotificationService) Subscribe(subscription *models.NotificationSubscription) error {
+	switch subscription.Event {
+	case models.NotifyAPYBelow:
+		if subscription.VaultAddress == "" || subscription.Threshold == nil || *subscription.Threshold < 0 {
+			return fmt.Errorf("%w: apy_below requires vault_address and a non-negative threshold", ErrInvalidSubscription)
+		}
+	case models.NotifyRebalanceSuggest:
+		// threshold 为净APY至少提高的年化收益率，不给出时使用 suggestions.min_apy_gain
+		if subscription.Threshold != nil && (*subscription.Threshold <= 0 || *subscription.Threshold > 1) {
+			return fmt.Errorf("%w: rebalance_suggestion threshold must be between 0 and 1", ErrInvalidSubscription)
+		}
+	case models.NotifyDepositConfirmed, models.NotifyWithdrawConfirmed, models.NotifyVaultPaused:
+		subscription.Threshold = nil
+	default:
+		return fmt.Errorf("%w: unknown event %q", ErrInvalidSubscription, subscription.Event)
+	}
+	subscription.TriggeredAt = nil
+	return s.repo.UpsertSubscription(subscription)
+}
+
+// Unsubscribe 取消订阅，返回false表示订阅不存在
+func (s *NotificationService) Unsubscribe(userAddress string, id uint) (bool, error) {
+	return s.repo.DeleteSubscription(userAddress, id)
+}
+
+// CreateRule 创建APY告警规则，资金库必须存在，同一资金库上不能有完全相同的规则
+func (s *NotificationService) CreateRule(rule *models.APYAlertRule) error {
+	if rule.Window != models.AlertWindowCurrent && rule.Window != models.AlertWindow7d {
+		return fmt.Errorf("%w: window must be current or 7d", ErrInvalidAlertRule)
+	}
+	if rule.Direction != models.AlertAbove && rule.Direction != models.AlertBelow {
+		return fmt.Errorf("%w: direction must be above or below", ErrInvalidAlertRule)
+	}
+	if rule.Threshold < 0 || rule.Threshold > 10 {
+		return fmt.Errorf("%w: threshold must be between 0 and 10", ErrInvalidAlertRule)
+	}
+
+	vault, err := s.vaultRepo.GetByAddress(rule.VaultAddress)
+	if err != nil {
+		return err
+	}
+	if vault == nil {
+		return fmt.Errorf("%w: unknown vault %s", ErrInvalidAlertRule, rule.VaultAddress)
+	}
+	rule.VaultAddress = vault.Address
+
+	count, err := s.repo.CountRules(rule.UserAddress)
+	if err != nil {
+		return err
+	}
+	if s.maxRules > 0 && count >= int64(s.maxRules) {
+		return fmt.Errorf("%w: limit is %d", ErrTooManyAlertRules, s.maxRules)
+	}
+
+	rule.TriggeredAt = nil
+	rule.LastNotifiedAt = nil
+	created, err := s.repo.CreateRule(rule)
+	if err != nil {
+		return err
+	}
+	if !created {
+		return ErrDuplicateAlertRule
+	}
+	return nil
+}
+
+// DeleteRule 删除告警规则，返回false表示规则不存在
+func (s *NotificationService) DeleteRule(userAddress string, id uint) (bool, error) {
+	return s.repo.DeleteRule(userAddress, id)
+}
+
+// NotifyUser 向订阅了该资金库事件的用户发送通知
+func (s *NotificationService) NotifyUser(ctx context.Context, userAddress, event, vaultAddress string, msg notify.Message) {
+	subscriptions, err := s.repo.FindSubscribers(event, vaultAddress, userAddress)
+	if err != nil || len(subscriptions) == 0 {
+		return
+	}
+	s.deliver(ctx, userAddress, event, msg)
+}
+
+// NotifyVault 向所有订阅了该资金库事件的用户发送通知
+func (s *NotificationService) NotifyVault(ctx context.Context, event, vaultAddress string, msg notify.Message) {
+	subscriptions, err := s.repo.FindSubscribers(event, vaultAddress, "")
+	if err != nil {
+		return
+	}
+
+	notified := make(map[string]bool, len(subscriptions))
+	for _, sub := range subscriptions {
+		if notified[sub.UserAddress] {
+			continue
+		}
+		notified[sub.UserAddress] = true
+		s.deliver(ctx, sub.UserAddress, event, msg)
+	}
+}
+
+// CheckAPY 检查资金库APY是否低于用户阈值，只在跌破时通知一次，回升到阈值以上后重新生效；
+// 随后评估该资金库上的告警规则，在每次APY快照后调用
+func (s *NotificationService) CheckAPY(ctx context.Context, vault *models.Vault) {
+	subscriptions, err := s.repo.FindSubscribers(models.NotifyAPYBelow, vault.Address, "")
+	if err != nil {
+		return
+	}
+
+	for _, sub := range subscriptions {
+		if sub.Threshold == nil {
+			continue
+		}
+		below := vault.APYCurrent < *sub.Threshold
+		switch {
+		case below && sub.TriggeredAt == nil:
+			now := time.Now()
+			if err := s.repo.SetTriggered(sub.ID, &now); err != nil {
+				continue
+			}
+			s.recordTrigger(models.AlertTrigger{
+				UserAddress:  sub.UserAddress,
+				VaultAddress: vault.Address,
+				Event:        models.NotifyAPYBelow,
+				Source:       models.AlertSourceSubscription,
+				SourceID:     sub.ID,
+				Notified:     true,
+				TriggeredAt:  now,
+			}, map[string]interface{}{"threshold": *sub.Threshold, "value": vault.APYCurrent})
+			s.deliver(ctx, sub.UserAddress, models.NotifyAPYBelow, notify.Message{
+				Subject: fmt.Sprintf("%s APY dropped below %.2f%%", vault.Name, *sub.Threshold*100),
+				Body: fmt.Sprintf("The APY of %s (%s) is now %.2f%%, below your alert threshold of %.2f%%.",
+					vault.Name, vault.Address, vault.APYCurrent*100, *sub.Threshold*100),
+			})
+		case !below && sub.TriggeredAt != nil:
+			s.repo.SetTriggered(sub.ID, nil)
+		}
+	}
+
+	s.evaluateRules(ctx, vault)
+}
+
+// evaluateRules 评估资金库上的告警规则。规则只在条件由不成立变为成立时通知，
+// 且距上次通知不足冷却时间时只记录触发不通知，避免APY在阈值附近波动时反复打扰用户
+func (s *NotificationService) evaluateRules(ctx context.Context, vault *models.Vault) {
+	rules, err := s.repo.FindVaultRules(vault.Address)
+	if err != nil || len(rules) == 0 {
+		return
+	}
+
+	now := time.Now()
+	values := map[string]*float64{models.AlertWindowCurrent: &vault.APYCurrent}
+	for _, rule := range rules {
+		value, ok := values[rule.Window]
+		if !ok {
+			// 7日均值按需计算，同一资金库只查询一次
+			value, err = s.apyRepo.GetAverageSince(vault.Address, now.AddDate(0, 0, -7))
+			if err != nil {
+				logger.Error(fmt.Sprintf("Failed to compute 7d APY of %s for alert rule %d: %v", vault.Address, rule.ID, err))
+				continue
+			}
+			values[rule.Window] = value
+		}
+		if value == nil {
+			continue
+		}
+
+		met := *value < rule.Threshold
+		if rule.Direction == models.AlertAbove {
+			met = *value > rule.Threshold
+		}
+
+		switch {
+		case met && rule.TriggeredAt == nil:
+			notified := rule.LastNotifiedAt
+			coolingDown := notified != nil && now.Sub(*notified) < s.cooldown
+			if !coolingDown {
+				notified = &now
+			}
+			if err := s.repo.UpdateRuleState(rule.ID, &now, notified); err != nil {
+				continue
+			}
+			s.recordTrigger(models.AlertTrigger{
+				UserAddress:  rule.UserAddress,
+				VaultAddress: vault.Address,
+				Event:        "apy_" + rule.Direction,
+				Source:       models.AlertSourceRule,
+				SourceID:     rule.ID,
+				Notified:     !coolingDown,
+				TriggeredAt:  now,
+			}, map[string]interface{}{
+				"window":    rule.Window,
+				"direction": rule.Direction,
+				"threshold": rule.Threshold,
+				"value":     *value,
+			})
+			if coolingDown {
+				continue
+			}
+			s.deliver(ctx, rule.UserAddress, "apy_"+rule.Direction, ruleMessage(vault, rule, *value))
+		case !met && rule.TriggeredAt != nil:
+			s.repo.UpdateRuleState(rule.ID, nil, rule.LastNotifiedAt)
+		}
+	}
+}
+
+func ruleMessage(vault *models.Vault, rule models.APYAlertRule, value float64) notify.Message {
+	label := "APY"
+	if rule.Window == models.AlertWindow7d {
+		label = "7-day average APY"
+	}
+	verb := "dropped below"
+	if rule.Direction == models.AlertAbove {
+		verb = "rose above"
+	}
+	return notify.Message{
+		Subject: fmt.Sprintf("%s %s %s %.2f%%", vault.Name, label, verb, rule.Threshold*100),
+		Body: fmt.Sprintf("The %s of %s (%s) is now %.2f%%, which %s your alert threshold of %.2f%%.",
+			label, vault.Name, vault.Address, value*100, verb, rule.Threshold*100),
+	}
+}
+
+// recordTrigger 记录触发用于用户动态，失败只记录日志，不影响通知
+func (s *NotificationService) recordTrigger(trigger models.AlertTrigger, details map[string]interface{}) {
+	raw, err := json.Marshal(details)
+	if err != nil {
+		return
+	}
+	trigger.Details = raw
+	s.repo.RecordTrigger(&trigger)
+}
+
+// WebhookJob webhook投递的队列任务负载。重试时沿用同一 delivery_id 和请求体，接收方可据此去重
+type WebhookJob struct {
+	ChannelID  uint            `json:"channel_id"`
+	DeliveryID string          `json:"delivery_id"`
+	Event      string          `json:"event"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// deliver 发送到用户所有已验证的渠道，单个渠道失败只记录日志。
+// webhook加入队列异步投递，失败时按队列的退避策略重试
+func (s *NotificationService) deliver(ctx context.Context, userAddress, event string, msg notify.Message) {
+	channels, err := s.repo.ListVerifiedChannels(userAddress)
+	if err != nil {
+		return
+	}
+	for i := range channels {
+		send := s.send
+		if channels[i].Type == notify.ChannelWebhook {
+			send = s.enqueueWebhook
+		}
+		if err := send(ctx, &channels[i], event, msg); err != nil {
+			logger.Error(fmt.Sprintf("Failed to notify %s via %s: %v", userAddress, channels[i].Type, err))
+		}
+	}
+}
+
+// send 通过单个渠道立即发送，webhook渠道签名后投递并记录结果。
+// 验证码只走这里：不能以明文写入队列，且登记时需要立即知道渠道是否可达
+func (s *NotificationService) send(ctx context.Context, channel *models.NotificationChannel, event string, msg notify.Message) error {
+	if channel.Type != notify.ChannelWebhook {
+		return s.dispatcher.Send(ctx, channel.Type, channel.Target, msg)
+	}
+	job, err := webhookJob(channel, event, msg)
+	if err != nil {
+		return err
+	}
+	return s.deliverWebhook(ctx, channel, job)
+}
+
+// enqueueWebhook 把webhook投递加入队列
+func (s *NotificationService) enqueueWebhook(ctx context.Context, channel *models.NotificationChannel, event string, msg notify.Message) error {
+	job, err := webhookJob(channel, event, msg)
+	if err != nil {
+		return err
+	}
+	_, _, err = queue.Enqueue(QueueWebhookDeliver, job, queue.Options{})
+	return err
+}
+
+// RunQueuedWebhook 执行队列中的webhook投递。渠道已删除或重新登记后尚未验证时不再投递
+func (s *NotificationService) RunQueuedWebhook(ctx context.Context, payload json.RawMessage) error {
+	var job WebhookJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return queue.Permanent(err)
+	}
+	channel, err := s.repo.GetChannelByID(job.ChannelID)
+	if err != nil {
+		return err
+	}
+	if channel == nil || !channel.Verified {
+		return queue.Permanent(ErrChannelNotFound)
+	}
+	err = s.deliverWebhook(ctx, channel, &job)
+	if errors.Is(err, notify.ErrPrivateAddress) {
+		return queue.Permanent(err)
+	}
+	return err
+}
+
+func webhookJob(channel *models.NotificationChannel, event string, msg notify.Message) (*WebhookJob, error) {
+	deliveryID, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"id":        deliveryID,
+		"event":     event,
+		"subject":   msg.Subject,
+		"body":      msg.Body,
+		"timestamp": time.Now().Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookJob{ChannelID: channel.ID, DeliveryID: deliveryID, Event: event, Body: body}, nil
+}
+
+// deliverWebhook 签名投递并记录本次尝试
+func (s *NotificationService) deliverWebhook(ctx context.Context, channel *models.NotificationChannel, job *WebhookJob) error {
+	now := time.Now()
+	result, err := s.webhook.Deliver(ctx, channel.Target, channel.Secret, job.DeliveryID, job.Body)
+	delivery := &models.WebhookDelivery{
+		ChannelID:   channel.ID,
+		UserAddress: channel.UserAddress,
+		DeliveryID:  job.DeliveryID,
+		Event:       job.Event,
+		StatusCode:  result.StatusCode,
+		DurationMs:  result.Duration.Milliseconds(),
+		AttemptedAt: now,
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+	s.repo.RecordDelivery(delivery)
+	return err
+}
+
+func validTarget(channelType, target string) bool {
+	switch channelType {
+	case notify.ChannelEmail:
+		addr, err := mail.ParseAddress(target)
+		return err == nil && addr.Address == target
+	case notify.ChannelTelegram:
+		return telegramChatID.MatchString(target)
+	case notify.ChannelWebhook:
+		u, err := url.Parse(target)
+		return err == nil && u.Scheme == "https" && u.Host != "" && u.User == nil && publicHost(u.Hostname())
+	}
+	return false
+}
+
+// publicHost 登记时拒绝 localhost 和非公网的IP字面量；域名在投递时解析后由 notify.WebhookSender 再检查
+func publicHost(host string) bool {
+	if host == "" || strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return notify.PublicIP(ip)
+	}
+	return true
+}
+
+// verificationCode 生成6位数字验证码
+func verificationCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// webhookSecret 生成webhook签名密钥
+func webhookSecret() (string, error) {
+	key, err := randomHex(32)
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + key, nil
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hashCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/cache"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/notify"
+)
+
+// OperatorAlertService 向运维人员发送告警，目标来自配置而不是用户订阅。每条告警都会记录下来，
+// 由事件产生的告警关联到该事件
+type OperatorAlertService struct {
+	txRepo       repository.TxRepo
+	incidentRepo *repository.IncidentRepository
+	dispatcher   *notify.Dispatcher
+	cfg          config.NotificationsConfig
+}
+
+func NewOperatorAlertService() *OperatorAlertService {
+	return NewOperatorAlertServiceWith(repository.Default())
+}
+
+// NewOperatorAlertServiceWith 使用注入的核心仓储构建
+func NewOperatorAlertServiceWith(repos *repository.Repositories) *OperatorAlertService {
+	return &OperatorAlertService{
+		txRepo:       repos.Transactions,
+		incidentRepo: repository.NewIncidentRepository(),
+		dispatcher:   notify.Default(),
+		cfg:          config.Load().Notifications,
+	}
+}
+
+// NotifyOperators 发送到所有配置的运维邮箱和Telegram chat，单个目标失败只记录日志
+func (s *OperatorAlertService) NotifyOperators(ctx context.Context, msg notify.Message) {
+	s.send(ctx, nil, msg)
+}
+
+// NotifyIncident 发送与事件关联的告警
+func (s *OperatorAlertService) NotifyIncident(ctx context.Context, incidentID uint, msg notify.Message) {
+	s.send(ctx, &incidentID, msg)
+}
+
+// send 先记录告警再发送，记录失败不影响发送
+func (s *OperatorAlertService) send(ctx context.Context, incidentID *uint, msg notify.Message) {
+	logger.Info(fmt.Sprintf("🚨 Operator alert: %s", msg.Subject))
+	_ = s.incidentRepo.CreateAlert(&models.OperatorAlert{IncidentID: incidentID, Subject: msg.Subject, Body: msg.Body})
+
+	for _, target := range s.cfg.OperatorEmails {
+		if err := s.dispatcher.Send(ctx, notify.ChannelEmail, target, msg); err != nil {
+			logger.Error(fmt.Sprintf("Failed to send operator alert to %s: %v", target, err))
+		}
+	}
+	for _, target := range s.cfg.OperatorTelegramChats {
+		if err := s.dispatcher.Send(ctx, notify.ChannelTelegram, target, msg); err != nil {
+			logger.Error(fmt.Sprintf("Failed to send operator alert to telegram %s: %v", target, err))
+		}
+	}
+}
+
+// CheckWithdrawal 在取款确认后检查是否为大额取款或短时间内的大量净流出，
+// vault 为取款已计入后的资金库状态
+func (s *OperatorAlertService) CheckWithdrawal(ctx context.Context, vault *models.Vault, withdrawal *models.Transaction) {
+	before := vault.TVL.Add(withdrawal.Amount)
+	if s.cfg.WhaleWithdrawPct > 0 && before.IsPositive() {
+		share := withdrawal.Amount.Div(before).InexactFloat64()
+		if share >= s.cfg.WhaleWithdrawPct {
+			s.NotifyOperators(ctx, notify.Message{
+				Subject: fmt.Sprintf("Large withdrawal from %s (%.1f%% of TVL)", vault.Name, share*100),
+				Body: fmt.Sprintf("%s withdrew %s from %s (%s), %.1f%% of the vault's TVL of %s.\nTVL is now %s.\nTx: %s",
+					withdrawal.UserAddress, withdrawal.Amount.String(), vault.Name, vault.Address,
+					share*100, before.String(), vault.TVL.String(), withdrawal.TxHash),
+			})
+		}
+	}
+
+	if s.cfg.OutflowPct > 0 && s.cfg.OutflowWindow > 0 {
+		s.checkOutflow(ctx, vault)
+	}
+}
+
+// checkOutflow 统计窗口内的累计净流出，同一资金库在一个窗口内只告警一次
+func (s *OperatorAlertService) checkOutflow(ctx context.Context, vault *models.Vault) {
+	window := time.Duration(s.cfg.OutflowWindow) * time.Minute
+	outflow, err := s.txRepo.GetNetOutflow(vault.Address, time.Now().Add(-window))
+	if err != nil || !outflow.IsPositive() {
+		return
+	}
+
+	// 以窗口开始时的TVL为基数
+	start := vault.TVL.Add(outflow)
+	share := outflow.Div(start).InexactFloat64()
+	if share < s.cfg.OutflowPct {
+		return
+	}
+
+	// Redis不可用时宁可重复告警也不漏报
+	if lock, err := cache.TryLock(ctx, "alert:outflow:"+vault.Address, window); err == nil && lock == nil {
+		return
+	}
+
+	s.NotifyOperators(ctx, notify.Message{
+		Subject: fmt.Sprintf("Rapid outflow from %s (%.1f%% in %d min)", vault.Name, share*100, s.cfg.OutflowWindow),
+		Body: fmt.Sprintf("Net outflow of %s from %s (%s) in the last %d minutes, %.1f%% of its TVL of %s at the start of the window.\nTVL is now %s.",
+			outflow.String(), vault.Name, vault.Address, s.cfg.OutflowWindow, share*100,
+			start.String(), vault.TVL.String()),
+	})
+}
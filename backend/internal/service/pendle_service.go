@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/pendle"
+)
+
+var (
+	ErrInvalidPendleMarket = errors.New("market must be an unexpired Pendle market on the vault asset")
+	ErrPendleUnreadable    = errors.New("pendle market could not be read on chain")
+)
+
+// FixedRate 固定利率策略持有的PT及到期情况。按当前PT价格买入并持有到期的收益即市场的 implied_apy
+type FixedRate struct {
+	Market         *models.PendleMarket `json:"market"`
+	DaysToMaturity float64              `json:"days_to_maturity"` // 已到期为0
+	Matured        bool                 `json:"matured"`
+}
+
+// PendleService 跟踪持有Pendle PT的固定利率策略：定期读取市场隐含利率作为策略APY，到期后APY归零，
+// 等待策略赎回后转入新的市场
+type PendleService struct {
+	vaultRepo    repository.VaultRepo
+	strategyRepo repository.StrategyRepo
+	repo         *repository.PendleRepository
+	auditRepo    *repository.AuditRepository
+}
+
+func NewPendleService() *PendleService {
+	return NewPendleServiceWith(repository.Default())
+}
+
+// NewPendleServiceWith 使用注入的核心仓储构建
+func NewPendleServiceWith(repos *repository.Repositories) *PendleService {
+	return &PendleService{
+		vaultRepo:    repos.Vaults,
+		strategyRepo: repos.Strategies,
+		repo:         repository.NewPendleRepository(),
+		auditRepo:    repository.NewAuditRepository(),
+	}
+}
+
+// SetMarket 登记策略持有PT的市场，市场的底层资产必须是资金库的底层资产，到期时间写入策略 maturity。
+// 策略转入新一期市场时重新登记
+func (s *PendleService) SetMarket(ctx context.Context, strategy *models.Strategy, actor, marketAddress string) (*FixedRate, error) {
+	vault, err := s.vaultOf(strategy)
+	if err != nil {
+		return nil, err
+	}
+	m, err := pendle.Read(ctx, vault.ChainID, marketAddress)
+	if err != nil {
+		if errors.Is(err, pendle.ErrNotMarket) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPendleMarket, err)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrPendleUnreadable, err)
+	}
+	if !strings.EqualFold(m.Asset.Hex(), vault.AssetAddress) {
+		return nil, fmt.Errorf("%w: market asset is %s, vault asset is %s", ErrInvalidPendleMarket, m.Asset.Hex(), vault.AssetAddress)
+	}
+	now := time.Now()
+	if m.Matured(now) {
+		return nil, fmt.Errorf("%w: expired at %s", ErrInvalidPendleMarket, m.Expiry.Format(time.RFC3339))
+	}
+
+	market := &models.PendleMarket{
+		StrategyAddress: strategy.Address,
+		Market:          marketAddress,
+		SY:              strings.ToLower(m.SY.Hex()),
+		PT:              strings.ToLower(m.PT.Hex()),
+		YT:              strings.ToLower(m.YT.Hex()),
+		Expiry:          m.Expiry,
+	}
+	applyPendleReadings(market, m, now)
+	if err := s.repo.Upsert(market); err != nil {
+		return nil, err
+	}
+	if err := s.strategyRepo.UpdateAPY(strategy.Address, market.ImpliedAPY); err != nil {
+		return nil, err
+	}
+	strategy.APY = market.ImpliedAPY
+	strategy.Maturity = &market.Expiry
+
+	details, _ := json.Marshal(market)
+	if err := s.auditRepo.Create(&models.AuditLog{
+		Actor:   actor,
+		Action:  models.AuditSetPendle,
+		Target:  strategy.Address,
+		Details: details,
+	}); err != nil {
+		return nil, err
+	}
+	logger.Info(fmt.Sprintf("Strategy %s Pendle market set to %s (maturity %s) by %s",
+		strategy.Address, marketAddress, market.Expiry.Format("2006-01-02"), actor))
+	InvalidateVault(ctx, strategy.VaultAddress)
+	return fixedRate(market, now), nil
+}
+
+// SyncAll 读取全部登记市场的隐含利率并更新策略APY，已到期的市场APY记为0。单个市场失败不影响其他市场
+func (s *PendleService) SyncAll(ctx context.Context) error {
+	markets, err := s.repo.List()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := range markets {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := s.sync(ctx, &markets[i]); err != nil {
+			errs = append(errs, fmt.Errorf("strategy %s: %w", markets[i].StrategyAddress, err))
+		}
+	}
+	if len(markets) > 0 {
+		logger.Info(fmt.Sprintf("Synced %d Pendle market(s), %d failed", len(markets), len(errs)))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *PendleService) sync(ctx context.Context, market *models.PendleMarket) error {
+	strategy, err := s.strategyRepo.GetByAddress(market.StrategyAddress)
+	if err != nil {
+		return err
+	}
+	if strategy == nil {
+		return fmt.Errorf("strategy %s not found", market.StrategyAddress)
+	}
+	vault, err := s.vaultOf(strategy)
+	if err != nil {
+		return err
+	}
+
+	m, err := pendle.Read(ctx, vault.ChainID, market.Market)
+	if err != nil {
+		return err
+	}
+	applyPendleReadings(market, m, time.Now())
+	if err := s.repo.UpdateReadings(market); err != nil {
+		return err
+	}
+	if err := s.strategyRepo.UpdateAPY(strategy.Address, market.ImpliedAPY); err != nil {
+		return err
+	}
+	InvalidateVault(ctx, strategy.VaultAddress)
+	return nil
+}
+
+// Status 固定利率策略的PT市场和到期情况，未登记市场的策略返回nil
+func (s *PendleService) Status(strategyAddress string) (*FixedRate, error) {
+	market, err := s.repo.Get(strategyAddress)
+	if err != nil || market == nil {
+		return nil, err
+	}
+	return fixedRate(market, time.Now()), nil
+}
+
+func (s *PendleService) vaultOf(strategy *models.Strategy) (*models.Vault, error) {
+	vault, err := s.vaultRepo.GetByAddress(strategy.VaultAddress)
+	if err != nil {
+		return nil, err
+	}
+	if vault == nil {
+		return nil, fmt.Errorf("vault %s of strategy %s not found", strategy.VaultAddress, strategy.Address)
+	}
+	return vault, nil
+}
+
+// applyPendleReadings 写入读取的隐含利率和PT价格。到期后PT按1:1赎回，不再产生收益
+func applyPendleReadings(market *models.PendleMarket, m *pendle.Market, now time.Time) {
+	market.ImpliedAPY = m.ImpliedAPY()
+	if m.Matured(now) {
+		market.ImpliedAPY = 0
+	}
+	market.PTPrice = m.PTPrice(now)
+	market.BlockNumber = m.BlockNumber
+	market.SyncedAt = now
+}
+
+func fixedRate(market *models.PendleMarket, now time.Time) *FixedRate {
+	result := &FixedRate{Market: market, Matured: !now.Before(market.Expiry)}
+	if !result.Matured {
+		result.DaysToMaturity = market.Expiry.Sub(now).Hours() / 24
+	}
+	return result
+}
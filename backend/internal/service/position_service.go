@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/prices"
+
+	"github.com/shopspring/decimal"
+)
+
+// Position 用户在单个资金库的持仓
+type Position struct {
+	UserAddress  string             `json:"user_address"`
+	VaultAddress string             `json:"vault_address"`
+	VaultName    string             `json:"vault_name"`
+	AssetAddress string             `json:"asset_address"`
+	Shares       decimal.Decimal    `json:"shares"`
+	Assets       decimal.Decimal    `json:"assets"`
+	APY          float64            `json:"apy"`
+	ValueUSD     *float64           `json:"value_usd"`
+	CostBasisUSD *float64           `json:"cost_basis_usd"` // 按每笔交易发生时价格计算
+	PnLUSD       *float64           `json:"pnl_usd"`
+	Fiat         map[string]float64 `json:"fiat,omitempty"`
+}
+
+type PositionService struct {
+	txRepo       repository.TxRepo
+	vaultRepo    repository.VaultRepo
+	priceService *prices.Service
+	priceHistory *PriceHistoryService
+}
+
+func NewPositionService() *PositionService {
+	return NewPositionServiceWith(repository.Default())
+}
+
+// NewPositionServiceWith 使用注入的仓储构建，main统一构建依赖时使用
+func NewPositionServiceWith(repos *repository.Repositories) *PositionService {
+	return &PositionService{
+		txRepo:       repos.Transactions,
+		vaultRepo:    repos.Vaults,
+		priceService: prices.Default(),
+		priceHistory: NewPriceHistoryService(),
+	}
+}
+
+// GetUserPositions 根据已确认交易计算用户持仓，并按当前价格估值
+func (s *PositionService) GetUserPositions(ctx context.Context, userAddress string) ([]Position, error) {
+	totals, err := s.txRepo.WithContext(ctx).GetUserPositionTotals(userAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make([]Position, 0, len(totals))
+	for _, t := range totals {
+		vault, err := s.vaultRepo.WithContext(ctx).GetByAddress(t.VaultAddress)
+		if err != nil {
+			return nil, err
+		}
+		if vault == nil {
+			logger.Error(fmt.Sprintf("Position references unknown vault %s", t.VaultAddress))
+			continue
+		}
+
+		position := Position{
+			UserAddress:  userAddress,
+			VaultAddress: vault.Address,
+			VaultName:    vault.Name,
+			AssetAddress: vault.AssetAddress,
+			Shares:       t.Shares,
+			Assets:       t.Assets,
+			APY:          vault.APYCurrent,
+			ValueUSD:     valueUSD(ctx, s.priceService, vault.AssetAddress, vault.ChainID, t.Assets),
+		}
+
+		costBasis, err := s.costBasisUSD(ctx, userAddress, vault.Address, vault.AssetAddress, vault.ChainID)
+		if err != nil {
+			logger.Info(fmt.Sprintf("Cost basis unavailable for %s in %s: %v", userAddress, vault.Address, err))
+		} else {
+			position.CostBasisUSD = &costBasis
+			if position.ValueUSD != nil {
+				pnl := *position.ValueUSD - costBasis
+				position.PnLUSD = &pnl
+			}
+		}
+
+		positions = append(positions, position)
+	}
+	return positions, nil
+}
+
+// costBasisUSD 按平均成本法计算持仓成本：存款按当时价格计入，取款按份额比例扣减
+func (s *PositionService) costBasisUSD(ctx context.Context, userAddress, vaultAddress, asset string, chainID uint) (float64, error) {
+	txs, err := s.txRepo.WithContext(ctx).GetUserVaultHistory(userAddress, vaultAddress)
+	if err != nil {
+		return 0, err
+	}
+
+	shares, cost := decimal.Zero, decimal.Zero
+	for _, tx := range txs {
+		switch tx.Type {
+		case "deposit":
+			price, err := s.priceHistory.PriceAt(ctx, asset, chainID, tx.CreatedAt)
+			if err != nil {
+				return 0, err
+			}
+			shares = shares.Add(tx.Shares)
+			cost = cost.Add(tx.Amount.Mul(decimal.NewFromFloat(price)))
+		case "withdraw":
+			if shares.IsPositive() {
+				cost = cost.Sub(cost.Mul(tx.Shares).Div(shares))
+			}
+			shares = shares.Sub(tx.Shares)
+		}
+	}
+	return cost.InexactFloat64(), nil
+}
+
+// valueUSD 按当前价格换算USD价值，价格不可用时返回nil而不是错误的0
+func valueUSD(ctx context.Context, priceService *prices.Service, token string, chainID uint, amount decimal.Decimal) *float64 {
+	value, err := priceService.ToUSD(ctx, token, chainID, amount.InexactFloat64())
+	if err != nil {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/cache"
+	"github.com/chspring1/mya-platform/backend/pkg/units"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	previewDepositSelector  = crypto.Keccak256([]byte("previewDeposit(uint256)"))[:4]
+	previewRedeemSelector   = crypto.Keccak256([]byte("previewRedeem(uint256)"))[:4]
+	convertToAssetsSelector = crypto.Keccak256([]byte("convertToAssets(uint256)"))[:4]
+
+	ErrPreviewPrecision = units.ErrTooPrecise
+)
+
+// 预览结果按区块缓存，key中带区块高度，过期时间只用于回收
+const previewCacheTTL = time.Minute
+
+// Preview ERC-4626 预览结果，Assets 为底层资产数量，Shares 为份额数量，*_wei 为对应的最小单位
+type Preview struct {
+	Vault     string          `json:"vault"`
+	Block     uint64          `json:"block"`
+	Assets    decimal.Decimal `json:"assets"`
+	Shares    decimal.Decimal `json:"shares"`
+	AssetsWei string          `json:"assets_wei"`
+	SharesWei string          `json:"shares_wei"`
+}
+
+type PreviewService struct{}
+
+func NewPreviewService() *PreviewService {
+	return &PreviewService{}
+}
+
+// PreviewDeposit 调用合约 previewDeposit(assets)，返回存入assets可获得的份额
+func (s *PreviewService) PreviewDeposit(ctx context.Context, vault *models.Vault, assets decimal.Decimal) (*Preview, error) {
+	assetDecimals, err := blockchain.TokenDecimals(ctx, vault.ChainID, vault.AssetAddress)
+	if err != nil {
+		return nil, fmt.Errorf("decimals of %s: %w", vault.AssetAddress, err)
+	}
+	shareDecimals, err := blockchain.TokenDecimals(ctx, vault.ChainID, vault.Address)
+	if err != nil {
+		return nil, fmt.Errorf("decimals of %s: %w", vault.Address, err)
+	}
+
+	in, shares, block, err := s.preview(ctx, vault, "deposit", previewDepositSelector, assets, assetDecimals)
+	if err != nil {
+		return nil, err
+	}
+	return &Preview{
+		Vault:     vault.Address,
+		Block:     block,
+		Assets:    assets,
+		Shares:    units.FromBase(shares, shareDecimals),
+		AssetsWei: in.String(),
+		SharesWei: shares.String(),
+	}, nil
+}
+
+// PreviewRedeem 调用合约 previewRedeem(shares)，返回赎回shares可取回的底层资产
+func (s *PreviewService) PreviewRedeem(ctx context.Context, vault *models.Vault, shares decimal.Decimal) (*Preview, error) {
+	shareDecimals, err := blockchain.TokenDecimals(ctx, vault.ChainID, vault.Address)
+	if err != nil {
+		return nil, fmt.Errorf("decimals of %s: %w", vault.Address, err)
+	}
+	assetDecimals, err := blockchain.TokenDecimals(ctx, vault.ChainID, vault.AssetAddress)
+	if err != nil {
+		return nil, fmt.Errorf("decimals of %s: %w", vault.AssetAddress, err)
+	}
+
+	in, assets, block, err := s.preview(ctx, vault, "redeem", previewRedeemSelector, shares, shareDecimals)
+	if err != nil {
+		return nil, err
+	}
+	return &Preview{
+		Vault:     vault.Address,
+		Block:     block,
+		Assets:    units.FromBase(assets, assetDecimals),
+		Shares:    shares,
+		AssetsWei: assets.String(),
+		SharesWei: in.String(),
+	}, nil
+}
+
+// RedeemWithFee 在同一区块上调用 previewRedeem 和 convertToAssets。按ERC-4626规范前者扣除了退出费用而后者不扣除，
+// 返回预览结果和不含费用的资产数量(最小单位)
+func (s *PreviewService) RedeemWithFee(ctx context.Context, vault *models.Vault, shares decimal.Decimal) (*Preview, *big.Int, error) {
+	preview, err := s.PreviewRedeem(ctx, vault, shares)
+	if err != nil {
+		return nil, nil, err
+	}
+	raw, _ := units.ParseBase(preview.SharesWei)
+	gross, err := s.callAt(ctx, vault, "convertToAssets", convertToAssetsSelector, raw, preview.Block)
+	if err != nil {
+		return nil, nil, err
+	}
+	return preview, gross, nil
+}
+
+// preview 在最新区块上调用预览函数，返回输入和结果的最小单位数量
+func (s *PreviewService) preview(ctx context.Context, vault *models.Vault, kind string, selector []byte, amount decimal.Decimal, inDecimals int32) (*big.Int, *big.Int, uint64, error) {
+	raw, err := units.ToBase(amount, inDecimals)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	// 合约参数为 uint256，超出的数量无法编码进调用
+	if raw.Sign() < 0 || raw.BitLen() > 256 {
+		return nil, nil, 0, ErrInvalidAmount
+	}
+
+	block, err := blockchain.BlockNumber(ctx, vault.ChainID)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("block number: %w", err)
+	}
+	result, err := s.callAt(ctx, vault, "preview"+kind, selector, raw, block)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return raw, result, block, nil
+}
+
+// callAt 在指定区块上调用资金库的单参数uint256视图函数。同一区块内相同输入直接返回缓存结果
+func (s *PreviewService) callAt(ctx context.Context, vault *models.Vault, method string, selector []byte, raw *big.Int, block uint64) (*big.Int, error) {
+	key := fmt.Sprintf("preview-wei:%s:%d:%s:%d:%s", method, vault.ChainID, vault.Address, block, raw.String())
+	var cached string
+	if cache.GetJSON(ctx, key, &cached) {
+		if result, err := units.ParseBase(cached); err == nil {
+			return result, nil
+		}
+	}
+
+	data := make([]byte, 0, len(selector)+32)
+	data = append(append(data, selector...), common.LeftPadBytes(raw.Bytes(), 32)...)
+	out, err := blockchain.CallAt(ctx, vault.ChainID, vault.Address, data, block)
+	if err != nil {
+		return nil, fmt.Errorf("%s on %s: %w", method, vault.Address, err)
+	}
+	if len(out) < 32 {
+		return nil, fmt.Errorf("unexpected %s result from %s", method, vault.Address)
+	}
+
+	result := new(big.Int).SetBytes(out[:32])
+	cache.SetJSON(ctx, key, result.String(), previewCacheTTL)
+	return result, nil
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/prices"
+)
+
+const (
+	priceKindDaily       = "daily"
+	priceKindTransaction = "transaction"
+
+	// 查询时刻在该窗口内直接使用实时价格
+	livePriceWindow = time.Hour
+	// 历史价格与查询时刻相差超过该值视为缺失
+	maxHistoricalGap = 48 * time.Hour
+)
+
+var ErrNoHistoricalPrice = errors.New("no historical price recorded near the requested time")
+
+type PriceHistoryService struct {
+	priceRepo    *repository.TokenPriceRepository
+	priceService *prices.Service
+}
+
+func NewPriceHistoryService() *PriceHistoryService {
+	return &PriceHistoryService{
+		priceRepo:    repository.NewTokenPriceRepository(),
+		priceService: prices.Default(),
+	}
+}
+
+// RecordDaily 记录所有已配置代币的每日价格
+func (s *PriceHistoryService) RecordDaily(ctx context.Context) error {
+	for _, token := range s.priceService.Tokens() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := s.record(ctx, token.Address, token.ChainID, priceKindDaily); err != nil {
+			logger.Error(fmt.Sprintf("Failed to record daily price for %s: %v", token.Address, err))
+		}
+	}
+	return nil
+}
+
+// RecordForTransaction 在交易发生时记录资产价格，供盈亏与税务计算使用
+func (s *PriceHistoryService) RecordForTransaction(ctx context.Context, token string, chainID uint) {
+	if err := s.record(ctx, token, chainID, priceKindTransaction); err != nil {
+		logger.Error(fmt.Sprintf("Failed to record transaction price for %s: %v", token, err))
+	}
+}
+
+func (s *PriceHistoryService) record(ctx context.Context, token string, chainID uint, kind string) error {
+	price, err := s.priceService.GetPrice(ctx, token, chainID)
+	if err != nil {
+		return err
+	}
+	return s.priceRepo.Create(&models.TokenPrice{
+		ChainID:      chainID,
+		TokenAddress: token,
+		PriceUSD:     price.USD,
+		Source:       price.Source,
+		Kind:         kind,
+		Timestamp:    time.Now(),
+	})
+}
+
+// PriceAt 获取代币在指定时刻的USD价格，近期使用实时价格，否则取该时刻之前最近的记录
+func (s *PriceHistoryService) PriceAt(ctx context.Context, token string, chainID uint, at time.Time) (float64, error) {
+	if time.Since(at) < livePriceWindow {
+		price, err := s.priceService.GetPrice(ctx, token, chainID)
+		if err == nil {
+			return price.USD, nil
+		}
+	}
+
+	record, err := s.priceRepo.GetAt(chainID, token, at)
+	if err != nil {
+		return 0, err
+	}
+	if record == nil || at.Sub(record.Timestamp) > maxHistoricalGap {
+		return 0, ErrNoHistoricalPrice
+	}
+	return record.PriceUSD, nil
+}
+
+// GetRange 获取代币在时间区间内记录的历史价格
+func (s *PriceHistoryService) GetRange(token string, chainID uint, from, to time.Time) ([]models.TokenPrice, error) {
+	return s.priceRepo.GetRange(chainID, token, from, to)
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+)
+
+// 每轮处理的删除请求数
+const purgeBatchSize = 100
+
+var ErrDeletionPending = errors.New("a deletion request is already pending")
+
+// UserDataExport 用户在平台上存储的全部数据
+type UserDataExport struct {
+	ExportedAt       time.Time                         `json:"exported_at"`
+	User             *models.User                      `json:"user"`
+	Transactions     []models.Transaction              `json:"transactions"`
+	Channels         []models.NotificationChannel      `json:"notification_channels"`
+	Subscriptions    []models.NotificationSubscription `json:"notification_subscriptions"`
+	AlertRules       []models.APYAlertRule             `json:"apy_alert_rules"`
+	AlertTriggers    []models.AlertTrigger             `json:"alert_triggers"`
+	Allowlists       []models.VaultAllowlistEntry      `json:"allowlists"`
+	Watchlist        []models.WatchlistEntry           `json:"watchlist"`
+	DeletionRequests []models.DataDeletionRequest      `json:"deletion_requests"`
+}
+
+type PrivacyService struct {
+	privacyRepo      *repository.PrivacyRepository
+	userRepo         repository.UserRepo
+	txRepo           repository.TxRepo
+	notificationRepo *repository.NotificationRepository
+	allowlistRepo    *repository.AllowlistRepository
+	watchlistRepo    *repository.WatchlistRepository
+}
+
+func NewPrivacyService() *PrivacyService {
+	return NewPrivacyServiceWith(repository.Default())
+}
+
+// NewPrivacyServiceWith 使用注入的核心仓储构建
+func NewPrivacyServiceWith(repos *repository.Repositories) *PrivacyService {
+	return &PrivacyService{
+		privacyRepo:      repository.NewPrivacyRepository(),
+		userRepo:         repos.Users,
+		txRepo:           repos.Transactions,
+		notificationRepo: repository.NewNotificationRepository(),
+		allowlistRepo:    repository.NewAllowlistRepository(),
+		watchlistRepo:    repository.NewWatchlistRepository(),
+	}
+}
+
+// DeletionMessage 申请删除个人数据时钱包签名的原文
+func DeletionMessage(address string, issuedAt int64) string {
+	return fmt.Sprintf("MYA Platform account deletion\nAddress: %s\nIssued At: %d", strings.ToLower(address), issuedAt)
+}
+
+// Export 导出用户的全部数据，包括资料、交易记录、通知设置、白名单记录和收藏
+func (s *PrivacyService) Export(address string) (*UserDataExport, error) {
+	user, err := s.userRepo.GetByAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := s.txRepo.ListByUser(address)
+	if err != nil {
+		return nil, err
+	}
+	channels, err := s.notificationRepo.ListChannels(address)
+	if err != nil {
+		return nil, err
+	}
+	subscriptions, err := s.notificationRepo.ListSubscriptions(address)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.notificationRepo.ListRules(address)
+	if err != nil {
+		return nil, err
+	}
+	triggers, err := s.notificationRepo.ListTriggers(address)
+	if err != nil {
+		return nil, err
+	}
+	allowlists, err := s.allowlistRepo.ListByUser(address)
+	if err != nil {
+		return nil, err
+	}
+	watchlist, err := s.watchlistRepo.List(address)
+	if err != nil {
+		return nil, err
+	}
+	requests, err := s.privacyRepo.ListDeletionRequests(address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserDataExport{
+		ExportedAt:       time.Now(),
+		User:             user,
+		Transactions:     transactions,
+		Channels:         channels,
+		Subscriptions:    subscriptions,
+		AlertRules:       rules,
+		AlertTriggers:    triggers,
+		Allowlists:       allowlists,
+		Watchlist:        watchlist,
+		DeletionRequests: requests,
+	}, nil
+}
+
+// RequestDeletion 校验签名后登记删除请求，由worker异步执行。
+// 签名时间必须晚于上一次请求，截获的旧签名不能用来重复提交
+func (s *PrivacyService) RequestDeletion(address string, issuedAt int64, sig string) (*models.DataDeletionRequest, error) {
+	signedAt, err := verifySigned(address, DeletionMessage(address, issuedAt), issuedAt, sig)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := s.privacyRepo.ListDeletionRequests(address)
+	if err != nil {
+		return nil, err
+	}
+	if len(previous) > 0 && !signedAt.After(previous[0].RequestedAt) {
+		return nil, ErrSignatureExpired
+	}
+
+	request := &models.DataDeletionRequest{
+		UserAddress: address,
+		Status:      models.DeletionPending,
+		RequestedAt: time.Now(),
+	}
+	created, err := s.privacyRepo.CreateDeletionRequest(request)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrDeletionPending
+	}
+
+	logger.Info(fmt.Sprintf("Data deletion requested by %s", address))
+	return request, nil
+}
+
+// DeletionRequests 获取用户的删除请求，最新的在前
+func (s *PrivacyService) DeletionRequests(address string) ([]models.DataDeletionRequest, error) {
+	return s.privacyRepo.ListDeletionRequests(address)
+}
+
+// PurgePending 执行待处理的删除请求，单个请求失败时记录原因，用户可重新申请。由worker定时调用
+func (s *PrivacyService) PurgePending(ctx context.Context) error {
+	requests, err := s.privacyRepo.PendingDeletionRequests(purgeBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for i := range requests {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		request := &requests[i]
+		if err := s.privacyRepo.Purge(request, time.Now()); err != nil {
+			if markErr := s.privacyRepo.MarkDeletionFailed(request.ID, err.Error(), time.Now()); markErr != nil {
+				return markErr
+			}
+			continue
+		}
+		logger.Info(fmt.Sprintf("Personal data of %s purged (request %d)", request.UserAddress, request.ID))
+	}
+	return nil
+}
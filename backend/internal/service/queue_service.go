@@ -0,0 +1,102 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+)
+
+// 队列任务类型，由 cmd/worker 注册对应的执行函数
+const (
+	QueueReportGenerate = "report.generate"
+	QueueWebhookDeliver = "webhook.deliver"
+	QueueBackfill       = "backfill"
+	QueueRelayFallback  = "relay.fallback"
+)
+
+var (
+	ErrQueueJobNotFound = errors.New("queue job not found")
+	ErrQueueJobState    = errors.New("queue job is not in a state that allows this operation")
+	ErrInvalidQueueJob  = errors.New("status must be one of available, running, completed, failed")
+)
+
+var queueStatuses = map[string]bool{
+	models.QueueJobAvailable: true,
+	models.QueueJobRunning:   true,
+	models.QueueJobCompleted: true,
+	models.QueueJobFailed:    true,
+}
+
+// QueueService 管理员查看和处理持久化队列中的任务
+type QueueService struct {
+	repo      *repository.QueueRepository
+	auditRepo *repository.AuditRepository
+}
+
+func NewQueueService() *QueueService {
+	return &QueueService{
+		repo:      repository.NewQueueRepository(),
+		auditRepo: repository.NewAuditRepository(),
+	}
+}
+
+// List 按创建时间倒序分页获取任务，status 和 kind 为空时不过滤
+func (s *QueueService) List(status, kind string, cursor *repository.Cursor, limit int) ([]models.QueueJob, *repository.Page, error) {
+	if status != "" && !queueStatuses[status] {
+		return nil, nil, ErrInvalidQueueJob
+	}
+	return s.repo.List(status, kind, cursor, limit)
+}
+
+// Stats 按类型和状态统计任务数
+func (s *QueueService) Stats() ([]repository.QueueStat, error) {
+	return s.repo.Stats()
+}
+
+// Retry 把最终失败的任务重新放回队列，尝试次数从零开始
+func (s *QueueService) Retry(actor string, id uint) (*models.QueueJob, error) {
+	return s.transition(actor, id, models.AuditQueueRetry, s.repo.Requeue)
+}
+
+// Cancel 取消尚未执行的任务，任务标记为失败，之后仍可重试
+func (s *QueueService) Cancel(actor string, id uint) (*models.QueueJob, error) {
+	return s.transition(actor, id, models.AuditQueueCancel, func(id uint) (bool, error) {
+		return s.repo.Cancel(id, "cancelled by "+actor)
+	})
+}
+
+func (s *QueueService) transition(actor string, id uint, action string, apply func(id uint) (bool, error)) (*models.QueueJob, error) {
+	job, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrQueueJobNotFound
+	}
+	changed, err := apply(id)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return nil, ErrQueueJobState
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"kind":   job.Kind,
+		"status": job.Status,
+	})
+	if err := s.auditRepo.Create(&models.AuditLog{
+		Actor:   actor,
+		Action:  action,
+		Target:  fmt.Sprintf("%d", id),
+		Details: details,
+	}); err != nil {
+		return nil, err
+	}
+	logger.Info(fmt.Sprintf("Queue job %d (%s) %s by %s", id, job.Kind, action, actor))
+	return s.repo.GetByID(id)
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/units"
+
+	"github.com/shopspring/decimal"
+)
+
+const quoteIDPrefix = "q_"
+
+var (
+	ErrQuoteNotFound = errors.New("quote not found")
+	ErrQuoteExpired  = errors.New("quote has expired, refresh it before depositing")
+	ErrQuoteUsed     = errors.New("quote has already been used by a deposit")
+	ErrQuoteMismatch = errors.New("quote was issued for a different vault or amount")
+)
+
+// DepositQuote 直接存入底层资产的报价。请求参数固定，预期份额、最少份额、区块和gas在重新报价时刷新
+type DepositQuote struct {
+	ID                string          `json:"id"`
+	Vault             string          `json:"vault"`
+	Block             uint64          `json:"block"`
+	Amount            decimal.Decimal `json:"amount"`
+	AmountWei         string          `json:"amount_wei"`
+	ExpectedShares    decimal.Decimal `json:"expected_shares"`
+	ExpectedSharesWei string          `json:"expected_shares_wei"`
+	MinShares         decimal.Decimal `json:"min_shares"`
+	MinSharesWei      string          `json:"min_shares_wei"`
+	SlippageBps       uint16          `json:"slippage_bps"`
+	Gas               *GasEstimate    `json:"gas"`
+	ExpiresAt         time.Time       `json:"expires_at"`
+}
+
+// QuoteService 保存带ID和有效期的存款报价。报价很快过时，过期后不能用于存款意向，
+// 重新报价只按原参数刷新易变的结果，不重复校验额度、白名单和数量换算。
+// Zap报价的交易由用户钱包直接提交，路由合约没有截止时间参数，无法拒绝过期的执行，因此不在这里保存
+type QuoteService struct {
+	repo      *repository.QuoteRepository
+	vaultRepo repository.VaultRepo
+	previews  *PreviewService
+	gas       *GasService
+}
+
+func NewQuoteService() *QuoteService {
+	return NewQuoteServiceWith(repository.Default())
+}
+
+// NewQuoteServiceWith 使用注入的核心仓储构建
+func NewQuoteServiceWith(repos *repository.Repositories) *QuoteService {
+	return &QuoteService{
+		repo:      repository.NewQuoteRepository(),
+		vaultRepo: repos.Vaults,
+		previews:  NewPreviewService(),
+		gas:       NewGasServiceWith(repos),
+	}
+}
+
+// Deposit 为直接存入amount报价，slippageBps 为0时使用 slippage.default_bps
+func (s *QuoteService) Deposit(ctx context.Context, vault *models.Vault, userAddress string, amount *units.Amount, slippageBps uint16) (*DepositQuote, error) {
+	cfg := config.Load().Slippage
+	if slippageBps == 0 {
+		slippageBps = cfg.DefaultBps
+	}
+	if slippageBps > cfg.MaxBps {
+		return nil, ErrInvalidSlippage
+	}
+	quote, err := s.depositQuote(ctx, vault, amount.Value, slippageBps)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.save(quote.ID, models.QuoteDeposit, vault, userAddress, vault.AssetAddress, amount.Value, slippageBps, quote.ExpiresAt, quote); err != nil {
+		return nil, err
+	}
+	return quote, nil
+}
+
+// Refresh 按报价的原参数重新计算预期结果，ID不变、有效期重新计算。已使用的报价返回 ErrQuoteUsed
+func (s *QuoteService) Refresh(ctx context.Context, userAddress, id string) (*DepositQuote, error) {
+	stored, err := s.get(userAddress, id)
+	if err != nil {
+		return nil, err
+	}
+	if stored.Kind != models.QuoteDeposit {
+		return nil, ErrQuoteNotFound
+	}
+	if stored.UsedAt != nil {
+		return nil, ErrQuoteUsed
+	}
+	vault, err := s.vaultRepo.GetByAddress(stored.VaultAddress)
+	if err != nil {
+		return nil, err
+	}
+	if vault == nil {
+		return nil, fmt.Errorf("vault %s of quote %s not found", stored.VaultAddress, id)
+	}
+
+	quote, err := s.depositQuote(ctx, vault, stored.Amount, stored.SlippageBps)
+	if err != nil {
+		return nil, err
+	}
+	quote.ID = stored.ID
+
+	payload, err := json.Marshal(quote)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := s.repo.Refresh(stored.ID, payload, quote.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrQuoteUsed
+	}
+	return quote, nil
+}
+
+// Check 存款意向校验报价：报价须属于该用户、针对同一资金库和存款数量、未过期且未被使用。
+// 只返回报价内容，不标记使用；意向保存时才在同一事务中标记，之后的检查失败不会消耗报价
+func (s *QuoteService) Check(userAddress, id, vaultAddress string, amount decimal.Decimal) (*DepositQuote, error) {
+	stored, err := s.get(userAddress, id)
+	if err != nil {
+		return nil, err
+	}
+	if stored.Kind != models.QuoteDeposit || !strings.EqualFold(stored.VaultAddress, vaultAddress) || !stored.Amount.Equal(amount) {
+		return nil, ErrQuoteMismatch
+	}
+	if stored.UsedAt != nil {
+		return nil, ErrQuoteUsed
+	}
+	if stored.Expired(time.Now()) {
+		return nil, ErrQuoteExpired
+	}
+
+	var quote DepositQuote
+	if err := json.Unmarshal(stored.Payload, &quote); err != nil {
+		return nil, fmt.Errorf("decode quote %s: %w", id, err)
+	}
+	return &quote, nil
+}
+
+func (s *QuoteService) get(userAddress, id string) (*models.Quote, error) {
+	stored, err := s.repo.Get(userAddress, id)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return nil, ErrQuoteNotFound
+	}
+	return stored, nil
+}
+
+// depositQuote 报价中易变的部分：当前区块的 previewDeposit、按滑点扣除的最少份额和存款交易的gas
+func (s *QuoteService) depositQuote(ctx context.Context, vault *models.Vault, amount decimal.Decimal, slippageBps uint16) (*DepositQuote, error) {
+	preview, err := s.previews.PreviewDeposit(ctx, vault, amount)
+	if err != nil {
+		return nil, err
+	}
+	shareDecimals, err := blockchain.TokenDecimals(ctx, vault.ChainID, vault.Address)
+	if err != nil {
+		return nil, fmt.Errorf("decimals of %s: %w", vault.Address, err)
+	}
+	shares, _ := units.ParseBase(preview.SharesWei)
+	minShares := new(big.Int).Mul(shares, big.NewInt(int64(10000-int(slippageBps))))
+	minShares.Quo(minShares, big.NewInt(10000))
+
+	id, err := randomID(quoteIDPrefix)
+	if err != nil {
+		return nil, err
+	}
+	return &DepositQuote{
+		ID:                id,
+		Vault:             vault.Address,
+		Block:             preview.Block,
+		Amount:            amount,
+		AmountWei:         preview.AssetsWei,
+		ExpectedShares:    preview.Shares,
+		ExpectedSharesWei: preview.SharesWei,
+		MinShares:         units.FromBase(minShares, shareDecimals),
+		MinSharesWei:      minShares.String(),
+		SlippageBps:       slippageBps,
+		Gas:               s.gas.Estimate(ctx, vault.ChainID, config.Load().Gas.RoundTripGas),
+		ExpiresAt:         quoteExpiry(),
+	}, nil
+}
+
+func (s *QuoteService) save(id, kind string, vault *models.Vault, userAddress, tokenIn string, amount decimal.Decimal, slippageBps uint16, expiresAt time.Time, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return s.repo.Create(&models.Quote{
+		ID:           id,
+		Kind:         kind,
+		UserAddress:  userAddress,
+		VaultAddress: vault.Address,
+		ChainID:      vault.ChainID,
+		TokenIn:      strings.ToLower(tokenIn),
+		Amount:       amount,
+		SlippageBps:  slippageBps,
+		Payload:      data,
+		ExpiresAt:    expiresAt,
+	})
+}
+
+func quoteExpiry() time.Time {
+	return time.Now().Add(time.Duration(config.Load().Intents.QuoteTTL) * time.Second)
+}
+
+// randomID 生成带前缀的随机ID，报价和批量存款使用，总长34
+func randomID(prefix string) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(buf), nil
+}
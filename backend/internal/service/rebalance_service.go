@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/shopspring/decimal"
+)
+
+const bpsDenominator = 10000
+
+var bpsScale = decimal.NewFromInt(bpsDenominator)
+
+var (
+	ErrProposalNotFound   = errors.New("rebalance proposal not found")
+	ErrProposalNotPending = errors.New("rebalance proposal is not pending")
+	ErrProposalNotReady   = errors.New("rebalance proposal is not approved")
+	ErrMissingTxHash      = errors.New("tx hash required for every strategy adjustment")
+)
+
+// RebalanceExecution 执行再平衡时链上交易的回执
+type RebalanceExecution struct {
+	StrategyAddress string `json:"strategy_address" binding:"required,eth_address"`
+	TxHash          string `json:"tx_hash" binding:"required"`
+	BlockNumber     uint64 `json:"block_number"`
+}
+
+type RebalanceService struct {
+	vaultRepo     repository.VaultRepo
+	rebalanceRepo *repository.RebalanceRepository
+	timeline      *repository.VaultEventRepository
+	priceHistory  *PriceHistoryService
+	morpho        *MorphoService
+	cfg           config.RebalanceConfig
+}
+
+func NewRebalanceService() *RebalanceService {
+	return NewRebalanceServiceWith(repository.Default())
+}
+
+// NewRebalanceServiceWith 使用注入的核心仓储构建
+func NewRebalanceServiceWith(repos *repository.Repositories) *RebalanceService {
+	return &RebalanceService{
+		vaultRepo:     repos.Vaults,
+		rebalanceRepo: repository.NewRebalanceRepository(),
+		timeline:      repository.NewVaultEventRepository(),
+		priceHistory:  NewPriceHistoryService(),
+		morpho:        NewMorphoServiceWith(repos),
+		cfg:           config.Load().Rebalance,
+	}
+}
+
+// ProposeAll 为所有活跃资金库计算最优分配，收益提升足够时生成提案
+func (s *RebalanceService) ProposeAll(ctx context.Context) error {
+	vaults, err := s.vaultRepo.GetActiveVaults()
+	if err != nil {
+		return err
+	}
+
+	for i := range vaults {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if _, err := s.Propose(&vaults[i]); err != nil {
+			logger.Error(fmt.Sprintf("Failed to compute rebalance for vault %s: %v", vaults[i].Address, err))
+		}
+	}
+	return nil
+}
+
+// Propose 计算单个资金库的目标分配，提升不足阈值时返回nil
+func (s *RebalanceService) Propose(vault *models.Vault) (*models.RebalanceProposal, error) {
+	strategies, err := s.withMarketRisk(vault.Strategies)
+	if err != nil {
+		return nil, err
+	}
+	proposal := s.computeAllocation(vault.Address, strategies)
+	if proposal == nil {
+		return nil, nil
+	}
+
+	improvementBps := (proposal.ProposedAPY - proposal.CurrentAPY) * bpsDenominator
+	if improvementBps < float64(s.cfg.MinImprovementBps) {
+		return nil, nil
+	}
+
+	if err := s.rebalanceRepo.Create(proposal); err != nil {
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("📐 Rebalance proposal %d created for vault %s (APY %.4f -> %.4f)",
+		proposal.ID, vault.Address, proposal.CurrentAPY, proposal.ProposedAPY))
+	return proposal, nil
+}
+
+// computeAllocation 按风险调整后的APY从高到低依次填满各策略上限
+func (s *RebalanceService) computeAllocation(vaultAddress string, strategies []models.Strategy) *models.RebalanceProposal {
+	total := decimal.Zero
+	for _, st := range strategies {
+		total = total.Add(st.TotalAssets)
+	}
+	if !total.IsPositive() || len(strategies) == 0 {
+		return nil
+	}
+
+	ranked := make([]models.Strategy, len(strategies))
+	copy(ranked, strategies)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return s.adjustedAPY(ranked[i]) > s.adjustedAPY(ranked[j])
+	})
+
+	targets := make(map[string]decimal.Decimal, len(ranked))
+	remaining := total
+	for _, st := range ranked {
+		if !remaining.IsPositive() {
+			break
+		}
+		if !st.IsActive || st.RiskScore > s.cfg.MaxRiskScore {
+			continue
+		}
+		allocation := decimal.Min(total.Mul(decimal.NewFromInt(int64(st.MaxAllocBps))).Div(bpsScale), remaining)
+		targets[st.Address] = allocation
+		remaining = remaining.Sub(allocation)
+	}
+
+	proposal := &models.RebalanceProposal{
+		VaultAddress: vaultAddress,
+		Status:       models.RebalanceStatusPending,
+		TotalAssets:  total,
+	}
+	changed := false
+	for _, st := range strategies {
+		target := targets[st.Address]
+		currentShare := st.TotalAssets.Div(total)
+		targetShare := target.Div(total)
+		proposal.CurrentAPY += st.APY * currentShare.InexactFloat64()
+		proposal.ProposedAPY += st.APY * targetShare.InexactFloat64()
+
+		item := models.RebalanceItem{
+			StrategyAddress: st.Address,
+			StrategyAPY:     st.APY,
+			RiskScore:       st.RiskScore,
+			CurrentAssets:   st.TotalAssets,
+			TargetAssets:    target,
+			CurrentBps:      uint16(currentShare.Mul(bpsScale).IntPart()),
+			TargetBps:       uint16(targetShare.Mul(bpsScale).IntPart()),
+		}
+		if item.CurrentBps != item.TargetBps {
+			changed = true
+		}
+		proposal.Items = append(proposal.Items, item)
+	}
+	if !changed {
+		return nil
+	}
+
+	if remaining.IsPositive() {
+		proposal.Reason = fmt.Sprintf("risk-adjusted reallocation; %s left idle due to strategy caps", remaining.StringFixed(2))
+	} else {
+		proposal.Reason = "risk-adjusted reallocation"
+	}
+	return proposal
+}
+
+// withMarketRisk 复制策略列表并叠加底层市场的风险输入(如Morpho市场的利用率、LLTV和预言机状态)，
+// 提案中记录的风险分为叠加后的值
+func (s *RebalanceService) withMarketRisk(strategies []models.Strategy) ([]models.Strategy, error) {
+	addresses := make([]string, len(strategies))
+	for i, st := range strategies {
+		addresses[i] = st.Address
+	}
+	adjustments, err := s.morpho.RiskAdjustments(addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	adjusted := make([]models.Strategy, len(strategies))
+	copy(adjusted, strategies)
+	for i := range adjusted {
+		adjusted[i].RiskScore += adjustments[adjusted[i].Address]
+	}
+	return adjusted, nil
+}
+
+func (s *RebalanceService) adjustedAPY(st models.Strategy) float64 {
+	return st.APY - s.cfg.RiskPenalty*float64(st.RiskScore)
+}
+
+// ListProposals 列出再平衡提案
+func (s *RebalanceService) ListProposals(status string, limit int) ([]models.RebalanceProposal, error) {
+	return s.rebalanceRepo.List(status, limit)
+}
+
+// GetProposal 获取提案详情
+func (s *RebalanceService) GetProposal(id uint) (*models.RebalanceProposal, error) {
+	return s.rebalanceRepo.GetByID(id)
+}
+
+// Review 审批提案，approve为false时拒绝
+func (s *RebalanceService) Review(id uint, approve bool, reviewer string) (*models.RebalanceProposal, error) {
+	status := models.RebalanceStatusRejected
+	if approve {
+		status = models.RebalanceStatusApproved
+	}
+
+	ok, err := s.rebalanceRepo.UpdateReview(id, status, reviewer)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		proposal, err := s.rebalanceRepo.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if proposal == nil {
+			return nil, ErrProposalNotFound
+		}
+		return nil, ErrProposalNotPending
+	}
+
+	logger.Info(fmt.Sprintf("Rebalance proposal %d %s by %s", id, status, reviewer))
+	return s.rebalanceRepo.GetByID(id)
+}
+
+// Execute 记录已批准提案的链上执行结果，并将调仓交易写入交易表
+func (s *RebalanceService) Execute(id uint, operator string, executions []RebalanceExecution) (*models.RebalanceProposal, error) {
+	proposal, err := s.rebalanceRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if proposal == nil {
+		return nil, ErrProposalNotFound
+	}
+	if proposal.Status != models.RebalanceStatusApproved {
+		return nil, ErrProposalNotReady
+	}
+
+	receipts := make(map[string]RebalanceExecution, len(executions))
+	for _, e := range executions {
+		receipts[e.StrategyAddress] = e
+	}
+
+	// 一笔链上交易可能同时调整多个策略，按交易哈希合并
+	var txs []models.Transaction
+	txIndex := make(map[string]int)
+	for i := range proposal.Items {
+		item := &proposal.Items[i]
+		if item.TargetAssets.Equal(item.CurrentAssets) {
+			continue
+		}
+		receipt, ok := receipts[item.StrategyAddress]
+		if !ok || receipt.TxHash == "" {
+			return nil, ErrMissingTxHash
+		}
+		item.TxHash = receipt.TxHash
+
+		amount := item.TargetAssets.Sub(item.CurrentAssets).Abs()
+		if idx, seen := txIndex[receipt.TxHash]; seen {
+			txs[idx].Amount = txs[idx].Amount.Add(amount)
+			continue
+		}
+		txIndex[receipt.TxHash] = len(txs)
+		txs = append(txs, models.Transaction{
+			UserAddress:  operator,
+			VaultAddress: proposal.VaultAddress,
+			Type:         "rebalance",
+			Amount:       amount,
+			TxHash:       receipt.TxHash,
+			BlockNumber:  receipt.BlockNumber,
+			Status:       "pending",
+		})
+	}
+
+	if err := s.rebalanceRepo.MarkExecuted(proposal, txs); err != nil {
+		return nil, err
+	}
+	InvalidateVault(context.Background(), proposal.VaultAddress)
+
+	// 提案已标记执行，时间线写入失败只记录日志(Record内部已记录)，不影响执行结果
+	hashes := make([]string, 0, len(txs))
+	for _, tx := range txs {
+		hashes = append(hashes, tx.TxHash)
+	}
+	details, _ := json.Marshal(map[string]interface{}{
+		"proposal_id":  proposal.ID,
+		"tx_hashes":    hashes,
+		"current_apy":  proposal.CurrentAPY,
+		"proposed_apy": proposal.ProposedAPY,
+	})
+	_ = s.timeline.Record(&models.VaultEvent{
+		VaultAddress: proposal.VaultAddress,
+		Type:         models.VaultEventRebalance,
+		Actor:        operator,
+		Details:      details,
+	})
+
+	if vault, err := s.vaultRepo.GetByAddress(proposal.VaultAddress); err == nil && vault != nil {
+		s.priceHistory.RecordForTransaction(context.Background(), vault.AssetAddress, vault.ChainID)
+	}
+
+	logger.Info(fmt.Sprintf("✅ Rebalance proposal %d executed by %s (%d transactions)", id, operator, len(txs)))
+	return proposal, nil
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/notify"
+	"github.com/chspring1/mya-platform/backend/pkg/workerpool"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shopspring/decimal"
+)
+
+var balanceOfSelector = crypto.Keccak256([]byte("balanceOf(address)"))[:4]
+
+// 对账项目
+const (
+	ReconcileTVL        = "tvl"         // 资金库 TVL 与 totalAssets()
+	ReconcileSupply     = "supply"      // 已确认存取款累计的份额与 totalSupply()
+	ReconcileUserShares = "user_shares" // 用户累计份额与 balanceOf(user)
+)
+
+// 告警中最多列出的差异数
+const reconciliationAlertItems = 10
+
+// Discrepancy 一项超过容差的差异，Deviation 为相对链上数值的偏差(链上为0而数据库不为0时记为1)
+type Discrepancy struct {
+	VaultAddress string          `json:"vault_address"`
+	Kind         string          `json:"kind"`
+	UserAddress  string          `json:"user_address,omitempty"`
+	Database     decimal.Decimal `json:"database"`
+	Chain        decimal.Decimal `json:"chain"`
+	Deviation    float64         `json:"deviation"`
+}
+
+// reconcileFailure 一次读取失败，对应的项目未核对
+type reconcileFailure struct {
+	VaultAddress string `json:"vault_address"`
+	UserAddress  string `json:"user_address,omitempty"`
+	Error        string `json:"error"`
+}
+
+type ReconciliationService struct {
+	repo      *repository.ReconciliationRepository
+	vaultRepo repository.VaultRepo
+	txRepo    repository.TxRepo
+	operators *OperatorAlertService
+	cfg       config.ReconciliationConfig
+}
+
+func NewReconciliationService() *ReconciliationService {
+	return NewReconciliationServiceWith(repository.Default())
+}
+
+// NewReconciliationServiceWith 使用注入的核心仓储构建
+func NewReconciliationServiceWith(repos *repository.Repositories) *ReconciliationService {
+	return &ReconciliationService{
+		repo:      repository.NewReconciliationRepository(),
+		vaultRepo: repos.Vaults,
+		txRepo:    repos.Transactions,
+		operators: NewOperatorAlertServiceWith(repos),
+		cfg:       config.Load().Reconciliation,
+	}
+}
+
+// Run 对所有活跃资金库逐一核对 TVL、份额总量和每个用户的份额，保存报告，存在超过容差的差异时通知运维。
+// 只能发现数据库中出现过的用户的偏差；从未经过本平台存入的链上持有人只体现在份额总量的差异中
+func (s *ReconciliationService) Run(ctx context.Context) error {
+	// 模拟链上没有真实的合约状态可供核对
+	if blockchain.MockEnabled() {
+		return nil
+	}
+
+	vaults, err := s.vaultRepo.GetActiveVaults()
+	if err != nil {
+		return err
+	}
+
+	report := &models.ReconciliationReport{
+		Tolerance: s.cfg.Tolerance,
+		StartedAt: time.Now(),
+	}
+	var (
+		discrepancies []Discrepancy
+		failures      []reconcileFailure
+	)
+	for i := range vaults {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		found, failed, users := s.reconcileVault(ctx, &vaults[i])
+		discrepancies = append(discrepancies, found...)
+		failures = append(failures, failed...)
+		report.VaultsChecked++
+		report.UsersChecked += users
+	}
+	report.FinishedAt = time.Now()
+
+	sort.SliceStable(discrepancies, func(i, j int) bool {
+		return discrepancies[i].Deviation > discrepancies[j].Deviation
+	})
+	report.DiscrepancyCount = len(discrepancies)
+	switch {
+	case len(discrepancies) > 0:
+		report.Status = models.ReconciliationDiscrepancies
+	case len(failures) > 0:
+		report.Status = models.ReconciliationIncomplete
+	default:
+		report.Status = models.ReconciliationOK
+	}
+	if len(discrepancies) > 0 {
+		if report.Discrepancies, err = json.Marshal(discrepancies); err != nil {
+			return err
+		}
+	}
+	if len(failures) > 0 {
+		if report.Errors, err = json.Marshal(failures); err != nil {
+			return err
+		}
+	}
+	if err := s.repo.Create(report); err != nil {
+		return err
+	}
+
+	logger.Info(fmt.Sprintf("🔎 Reconciliation #%d: %d vault(s), %d user position(s), %d discrepancy(ies), %d read failure(s)",
+		report.ID, report.VaultsChecked, report.UsersChecked, len(discrepancies), len(failures)))
+	if len(discrepancies) > 0 {
+		s.operators.NotifyOperators(ctx, reconciliationMessage(report, discrepancies))
+	}
+	return nil
+}
+
+// Get 获取对账报告
+func (s *ReconciliationService) Get(id uint) (*models.ReconciliationReport, error) {
+	return s.repo.GetByID(id)
+}
+
+// List 按时间倒序分页获取对账报告
+func (s *ReconciliationService) List(cursor *repository.Cursor, limit int) ([]models.ReconciliationReport, *repository.Page, error) {
+	return s.repo.List(cursor, limit)
+}
+
+// reconcileVault 核对单个资金库，返回差异、读取失败和核对的用户数
+func (s *ReconciliationService) reconcileVault(ctx context.Context, vault *models.Vault) ([]Discrepancy, []reconcileFailure, int) {
+	var (
+		discrepancies []Discrepancy
+		failures      []reconcileFailure
+	)
+	fail := func(user string, err error) {
+		failures = append(failures, reconcileFailure{VaultAddress: vault.Address, UserAddress: user, Error: err.Error()})
+	}
+
+	read := assetRead{vault: vault.Address, chainID: vault.ChainID, asset: vault.AssetAddress}
+	if assets, err := readAssets(ctx, read); err != nil {
+		fail("", err)
+	} else if d, ok := s.compare(vault.Address, ReconcileTVL, "", vault.TVL, assets); !ok {
+		discrepancies = append(discrepancies, d)
+	}
+
+	balances, err := s.txRepo.GetVaultShareBalances(vault.Address)
+	if err != nil {
+		fail("", err)
+		return discrepancies, failures, 0
+	}
+	dbSupply := decimal.Zero
+	for _, balance := range balances {
+		dbSupply = dbSupply.Add(balance.Shares)
+	}
+	if supply, err := readSupply(ctx, read); err != nil {
+		fail("", err)
+	} else if d, ok := s.compare(vault.Address, ReconcileSupply, "", dbSupply, supply); !ok {
+		discrepancies = append(discrepancies, d)
+	}
+
+	decimals, err := blockchain.TokenDecimals(ctx, vault.ChainID, vault.Address)
+	if err != nil {
+		fail("", fmt.Errorf("decimals of %s: %w", vault.Address, err))
+		return discrepancies, failures, 0
+	}
+
+	var mutex sync.Mutex
+	workerpool.ForEach(ctx, config.Load().Blockchain.ReadConcurrency, balances, func(ctx context.Context, balance repository.UserShares) error {
+		shares, err := readShareBalance(ctx, vault.ChainID, vault.Address, balance.UserAddress, decimals)
+		mutex.Lock()
+		defer mutex.Unlock()
+		if err != nil {
+			fail(balance.UserAddress, err)
+			return nil
+		}
+		if d, ok := s.compare(vault.Address, ReconcileUserShares, balance.UserAddress, balance.Shares, shares); !ok {
+			discrepancies = append(discrepancies, d)
+		}
+		return nil
+	})
+	return discrepancies, failures, len(balances)
+}
+
+// compare 比较数据库与链上数值，偏差在容差内时返回true
+func (s *ReconciliationService) compare(vault, kind, user string, db, chain decimal.Decimal) (Discrepancy, bool) {
+	deviation := 0.0
+	switch {
+	case chain.IsZero() && db.IsZero():
+	case chain.IsZero():
+		deviation = 1
+	default:
+		deviation = db.Sub(chain).Abs().Div(chain.Abs()).InexactFloat64()
+	}
+	if deviation <= s.cfg.Tolerance {
+		return Discrepancy{}, true
+	}
+	return Discrepancy{
+		VaultAddress: vault,
+		Kind:         kind,
+		UserAddress:  user,
+		Database:     db,
+		Chain:        chain,
+		Deviation:    deviation,
+	}, false
+}
+
+// readShareBalance 读取用户持有的资金库份额，按份额代币精度换算
+func readShareBalance(ctx context.Context, chainID uint, vault, user string, decimals int32) (decimal.Decimal, error) {
+	data := append(append([]byte{}, balanceOfSelector...), common.LeftPadBytes(common.HexToAddress(user).Bytes(), 32)...)
+	out, err := blockchain.Call(ctx, chainID, vault, data)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("read shares of %s in %s: %w", user, vault, err)
+	}
+	if len(out) < 32 {
+		return decimal.Zero, fmt.Errorf("unexpected balanceOf result from %s", vault)
+	}
+	return decimal.NewFromBigInt(new(big.Int).SetBytes(out[:32]), -decimals), nil
+}
+
+func reconciliationMessage(report *models.ReconciliationReport, discrepancies []Discrepancy) notify.Message {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Reconciliation #%d found %d discrepancy(ies) above the %.4g%% tolerance across %d vault(s).\n\n",
+		report.ID, len(discrepancies), report.Tolerance*100, report.VaultsChecked)
+	for i, d := range discrepancies {
+		if i == reconciliationAlertItems {
+			fmt.Fprintf(&b, "... and %d more, see /api/v1/admin/reconciliation/%d\n", len(discrepancies)-i, report.ID)
+			break
+		}
+		subject := d.VaultAddress
+		if d.UserAddress != "" {
+			subject += " / " + d.UserAddress
+		}
+		fmt.Fprintf(&b, "- %s %s: database %s, chain %s (%.2f%%)\n", subject, d.Kind, d.Database.String(), d.Chain.String(), d.Deviation*100)
+	}
+	return notify.Message{
+		Subject: fmt.Sprintf("Reconciliation found %d discrepancy(ies)", len(discrepancies)),
+		Body:    b.String(),
+	}
+}
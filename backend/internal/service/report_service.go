@@ -0,0 +1,342 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/queue"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/notify"
+
+	"github.com/shopspring/decimal"
+)
+
+// 计入报告事故的时间线事件
+var incidentEventTypes = []string{models.VaultEventPause, models.VaultEventEmergencyStop}
+
+// VaultDailySummary 单个资金库一天内的变化，金额为资产数量，USD估值按当天结束时的价格
+type VaultDailySummary struct {
+	VaultAddress string          `json:"vault_address"`
+	Name         string          `json:"name"`
+	TVLStart     decimal.Decimal `json:"tvl_start"`
+	TVLEnd       decimal.Decimal `json:"tvl_end"`
+	TVLEndUSD    *float64        `json:"tvl_end_usd"`
+	Deposits     decimal.Decimal `json:"deposits"`
+	Withdrawals  decimal.Decimal `json:"withdrawals"`
+	NetFlow      decimal.Decimal `json:"net_flow"`
+	APYStart     *float64        `json:"apy_start"`
+	APYEnd       *float64        `json:"apy_end"`
+	APYChange    *float64        `json:"apy_change"`
+}
+
+// DailySummary 平台一天(UTC)的汇总，USD合计不含无法定价的资金库
+type DailySummary struct {
+	Date           string              `json:"date"`
+	TVLStartUSD    float64             `json:"tvl_start_usd"`
+	TVLEndUSD      float64             `json:"tvl_end_usd"`
+	TVLChangeUSD   float64             `json:"tvl_change_usd"`
+	TVLChangePct   *float64            `json:"tvl_change_pct"`
+	DepositsUSD    float64             `json:"deposits_usd"`
+	WithdrawalsUSD float64             `json:"withdrawals_usd"`
+	NetFlowUSD     float64             `json:"net_flow_usd"`
+	VaultCount     int                 `json:"vault_count"`
+	TopVaults      []VaultDailySummary `json:"top_vaults"`
+	APYMoves       []VaultDailySummary `json:"apy_moves"`
+	Incidents      []models.VaultEvent `json:"incidents"`
+	UnpricedVaults []string            `json:"unpriced_vaults,omitempty"`
+}
+
+type ReportService struct {
+	reportRepo   *repository.ReportRepository
+	vaultRepo    repository.VaultRepo
+	apyRepo      *repository.APYHistoryRepository
+	txRepo       repository.TxRepo
+	timeline     *repository.VaultEventRepository
+	priceHistory *PriceHistoryService
+	dispatcher   *notify.Dispatcher
+	cfg          config.ReportsConfig
+}
+
+func NewReportService() *ReportService {
+	return NewReportServiceWith(repository.Default())
+}
+
+// NewReportServiceWith 使用注入的核心仓储构建
+func NewReportServiceWith(repos *repository.Repositories) *ReportService {
+	return &ReportService{
+		reportRepo:   repository.NewReportRepository(),
+		vaultRepo:    repos.Vaults,
+		apyRepo:      repository.NewAPYHistoryRepository(),
+		txRepo:       repos.Transactions,
+		timeline:     repository.NewVaultEventRepository(),
+		priceHistory: NewPriceHistoryService(),
+		dispatcher:   notify.Default(),
+		cfg:          config.Load().Reports,
+	}
+}
+
+// ReportJob 生成某天报告的队列任务负载
+type ReportJob struct {
+	Date string `json:"date"` // 2006-01-02
+}
+
+// EnqueueDaily 把前一天(UTC)的报告加入队列，由worker定时调用。生成失败时由队列重试
+func (s *ReportService) EnqueueDaily(ctx context.Context) error {
+	day := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+	_, err := s.Enqueue(day)
+	return err
+}
+
+// Enqueue 把某天的报告加入队列，同一天的任务未结束时不重复入队，返回是否入队
+func (s *ReportService) Enqueue(day time.Time) (bool, error) {
+	date := day.UTC().Format("2006-01-02")
+	_, created, err := queue.Enqueue(QueueReportGenerate, ReportJob{Date: date}, queue.Options{UniqueKey: "report:" + date})
+	return created, err
+}
+
+// RunQueued 执行队列中的报告任务
+func (s *ReportService) RunQueued(ctx context.Context, payload json.RawMessage) error {
+	var job ReportJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return queue.Permanent(err)
+	}
+	day, err := time.Parse("2006-01-02", job.Date)
+	if err != nil {
+		return queue.Permanent(err)
+	}
+	return s.Generate(ctx, day)
+}
+
+// Generate 生成某一天(UTC)的报告并推送，已生成时跳过
+func (s *ReportService) Generate(ctx context.Context, day time.Time) error {
+	day = day.UTC().Truncate(24 * time.Hour)
+	existing, err := s.reportRepo.GetByDate(day)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return nil
+	}
+
+	summary, err := s.Build(ctx, day)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+
+	report := &models.DailyReport{ReportDate: day, Summary: raw}
+	created, err := s.reportRepo.Create(report)
+	if err != nil || !created {
+		return err
+	}
+	logger.Info(fmt.Sprintf("📊 Daily report for %s generated", summary.Date))
+
+	if s.deliver(ctx, summary) {
+		return s.reportRepo.MarkDelivered(report.ID, time.Now())
+	}
+	return nil
+}
+
+// Build 统计 day 当天 [00:00, 24:00) UTC 的数据。资金库的TVL和APY取当天开始、结束前的最后一次快照
+func (s *ReportService) Build(ctx context.Context, day time.Time) (*DailySummary, error) {
+	start := day.UTC().Truncate(24 * time.Hour)
+	end := start.AddDate(0, 0, 1)
+
+	vaults, err := s.vaultRepo.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	startRecords, err := s.apyRepo.GetLatestBefore(start)
+	if err != nil {
+		return nil, err
+	}
+	endRecords, err := s.apyRepo.GetLatestBefore(end)
+	if err != nil {
+		return nil, err
+	}
+	flows, err := s.txRepo.GetFlows(start, end)
+	if err != nil {
+		return nil, err
+	}
+	incidents, err := s.timeline.ListBetween(incidentEventTypes, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	before := make(map[string]models.APYHistory, len(startRecords))
+	for _, record := range startRecords {
+		before[record.VaultAddress] = record
+	}
+	after := make(map[string]models.APYHistory, len(endRecords))
+	for _, record := range endRecords {
+		after[record.VaultAddress] = record
+	}
+	flowByVault := make(map[string]repository.VaultFlow, len(flows))
+	for _, flow := range flows {
+		flowByVault[flow.VaultAddress] = flow
+	}
+
+	summary := &DailySummary{
+		Date:      start.Format(time.DateOnly),
+		Incidents: incidents,
+	}
+	var rows []VaultDailySummary
+	for _, vault := range vaults {
+		last, ok := after[vault.Address]
+		if !ok {
+			// 当天结束前还没有快照，资金库尚未上线
+			continue
+		}
+		flow := flowByVault[vault.Address]
+		row := VaultDailySummary{
+			VaultAddress: vault.Address,
+			Name:         vault.Name,
+			TVLEnd:       last.TVL,
+			Deposits:     flow.Deposits,
+			Withdrawals:  flow.Withdrawals,
+			NetFlow:      flow.Deposits.Sub(flow.Withdrawals),
+			APYEnd:       &last.APYValue,
+		}
+		if first, ok := before[vault.Address]; ok {
+			change := last.APYValue - first.APYValue
+			row.TVLStart = first.TVL
+			row.APYStart = &first.APYValue
+			row.APYChange = &change
+		}
+
+		if price, err := s.priceHistory.PriceAt(ctx, vault.AssetAddress, vault.ChainID, end); err == nil {
+			usd := decimal.NewFromFloat(price)
+			tvlEnd := row.TVLEnd.Mul(usd).InexactFloat64()
+			row.TVLEndUSD = &tvlEnd
+			summary.TVLStartUSD += row.TVLStart.Mul(usd).InexactFloat64()
+			summary.TVLEndUSD += tvlEnd
+			summary.DepositsUSD += row.Deposits.Mul(usd).InexactFloat64()
+			summary.WithdrawalsUSD += row.Withdrawals.Mul(usd).InexactFloat64()
+		} else {
+			summary.UnpricedVaults = append(summary.UnpricedVaults, vault.Address)
+		}
+		rows = append(rows, row)
+	}
+
+	summary.VaultCount = len(rows)
+	summary.TVLChangeUSD = summary.TVLEndUSD - summary.TVLStartUSD
+	summary.NetFlowUSD = summary.DepositsUSD - summary.WithdrawalsUSD
+	if summary.TVLStartUSD > 0 {
+		pct := summary.TVLChangeUSD / summary.TVLStartUSD
+		summary.TVLChangePct = &pct
+	}
+	summary.TopVaults = topVaults(rows, s.cfg.TopVaults)
+	summary.APYMoves = apyMoves(rows, s.cfg.TopVaults)
+	return summary, nil
+}
+
+// topVaults 按结束时USD TVL取前n个，无法定价的排在最后
+func topVaults(rows []VaultDailySummary, n int) []VaultDailySummary {
+	sorted := append(make([]VaultDailySummary, 0, len(rows)), rows...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].TVLEndUSD, sorted[j].TVLEndUSD
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return *a > *b
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// apyMoves 按APY变动幅度取前n个，当天开始前没有快照的资金库不参与
+func apyMoves(rows []VaultDailySummary, n int) []VaultDailySummary {
+	moves := make([]VaultDailySummary, 0, len(rows))
+	for _, row := range rows {
+		if row.APYChange != nil && *row.APYChange != 0 {
+			moves = append(moves, row)
+		}
+	}
+	sort.SliceStable(moves, func(i, j int) bool {
+		return math.Abs(*moves[i].APYChange) > math.Abs(*moves[j].APYChange)
+	})
+	if len(moves) > n {
+		moves = moves[:n]
+	}
+	return moves
+}
+
+// deliver 推送到配置的Slack和Telegram，至少一个目标成功时返回true
+func (s *ReportService) deliver(ctx context.Context, summary *DailySummary) bool {
+	msg := reportMessage(summary)
+	delivered := false
+	send := func(channel string, targets []string) {
+		for _, target := range targets {
+			if err := s.dispatcher.Send(ctx, channel, target, msg); err != nil {
+				logger.Error(fmt.Sprintf("Failed to deliver daily report via %s: %v", channel, err))
+				continue
+			}
+			delivered = true
+		}
+	}
+	send(notify.ChannelSlack, s.cfg.SlackWebhooks)
+	send(notify.ChannelTelegram, s.cfg.TelegramChats)
+	return delivered
+}
+
+func reportMessage(summary *DailySummary) notify.Message {
+	var b strings.Builder
+	fmt.Fprintf(&b, "TVL: $%.0f (%+.0f", summary.TVLEndUSD, summary.TVLChangeUSD)
+	if summary.TVLChangePct != nil {
+		fmt.Fprintf(&b, ", %+.2f%%", *summary.TVLChangePct*100)
+	}
+	fmt.Fprintf(&b, ")\nNet flow: $%+.0f (deposits $%.0f, withdrawals $%.0f)\n",
+		summary.NetFlowUSD, summary.DepositsUSD, summary.WithdrawalsUSD)
+
+	if len(summary.TopVaults) > 0 {
+		b.WriteString("\nTop vaults:\n")
+		for i, row := range summary.TopVaults {
+			tvl := "unpriced"
+			if row.TVLEndUSD != nil {
+				tvl = fmt.Sprintf("$%.0f", *row.TVLEndUSD)
+			}
+			fmt.Fprintf(&b, "%d. %s %s, APY %.2f%%\n", i+1, row.Name, tvl, *row.APYEnd*100)
+		}
+	}
+	if len(summary.APYMoves) > 0 {
+		b.WriteString("\nAPY moves:\n")
+		for _, row := range summary.APYMoves {
+			fmt.Fprintf(&b, "%s %.2f%% -> %.2f%%\n", row.Name, *row.APYStart*100, *row.APYEnd*100)
+		}
+	}
+	fmt.Fprintf(&b, "\nIncidents: %d\n", len(summary.Incidents))
+	for _, event := range summary.Incidents {
+		fmt.Fprintf(&b, "%s %s %s\n", event.OccurredAt.UTC().Format("15:04"), event.Type, event.VaultAddress)
+	}
+	if len(summary.UnpricedVaults) > 0 {
+		fmt.Fprintf(&b, "\nUnpriced vaults excluded from totals: %d\n", len(summary.UnpricedVaults))
+	}
+
+	return notify.Message{
+		Subject: fmt.Sprintf("MYA daily report %s", summary.Date),
+		Body:    b.String(),
+	}
+}
+
+// List 按日期倒序分页获取报告
+func (s *ReportService) List(cursor *repository.Cursor, limit int) ([]models.DailyReport, *repository.Page, error) {
+	return s.reportRepo.List(cursor, limit)
+}
+
+// Get 获取某一天的报告，不存在时返回nil
+func (s *ReportService) Get(day time.Time) (*models.DailyReport, error) {
+	return s.reportRepo.GetByDate(day)
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+)
+
+type RetentionService struct {
+	apyRepo          *repository.APYHistoryRepository
+	notificationRepo *repository.NotificationRepository
+	queueRepo        *repository.QueueRepository
+	cfg              config.RetentionConfig
+}
+
+func NewRetentionService() *RetentionService {
+	return &RetentionService{
+		apyRepo:          repository.NewAPYHistoryRepository(),
+		notificationRepo: repository.NewNotificationRepository(),
+		queueRepo:        repository.NewQueueRepository(),
+		cfg:              config.Load().Retention,
+	}
+}
+
+// Run 汇总已结束日期的APY历史，再删除超过保留期的原始记录；汇总失败时不删除。
+// 过期的webhook投递记录和已完成的队列任务与APY历史无关，在汇总之后单独清理
+func (s *RetentionService) Run(ctx context.Context) error {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	rolled, err := s.apyRepo.RollupDaily(today)
+	if err != nil {
+		return err
+	}
+	if rolled > 0 {
+		InvalidateAPYData(ctx)
+	}
+
+	if s.cfg.WebhookDeliveryDays > 0 {
+		cutoff := today.AddDate(0, 0, -s.cfg.WebhookDeliveryDays)
+		pruned, err := s.notificationRepo.PruneDeliveriesBefore(cutoff)
+		if err != nil {
+			return err
+		}
+		if pruned > 0 {
+			logger.Info(fmt.Sprintf("🧹 Pruned %d webhook deliveries older than %s", pruned, cutoff.Format("2006-01-02")))
+		}
+	}
+
+	if s.cfg.QueueJobDays > 0 {
+		cutoff := today.AddDate(0, 0, -s.cfg.QueueJobDays)
+		pruned, err := s.queueRepo.PruneFinishedBefore(cutoff)
+		if err != nil {
+			return err
+		}
+		if pruned > 0 {
+			logger.Info(fmt.Sprintf("🧹 Pruned %d completed queue jobs older than %s", pruned, cutoff.Format("2006-01-02")))
+		}
+	}
+
+	if s.cfg.APYRawDays <= 0 || ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	cutoff := today.AddDate(0, 0, -s.cfg.APYRawDays)
+	pruned, err := s.apyRepo.PruneBefore(cutoff)
+	if err != nil {
+		return err
+	}
+
+	logger.Info(fmt.Sprintf("🧹 APY history retention: %d daily rows updated, %d raw rows older than %s pruned",
+		rolled, pruned, cutoff.Format("2006-01-02")))
+	return nil
+}
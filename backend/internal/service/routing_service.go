@@ -0,0 +1,355 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/prices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrRouteAssetUnknown = errors.New("asset is not in the token registry")
+	ErrRouteNoVault      = errors.New("no active vault holds this asset")
+)
+
+// RouteCandidate 一个候选资金库的评分明细，收益率均为年化小数。
+// Score = NetAPY - RiskPenalty - GasDrag，Excluded 非空表示不参与推荐
+type RouteCandidate struct {
+	Vault             string           `json:"vault"`
+	Name              string           `json:"name"`
+	ChainID           uint             `json:"chain_id"`
+	APY               float64          `json:"apy"`
+	FeeDrag           float64          `json:"fee_drag"` // 管理费和业绩费折算的年化收益损失
+	NetAPY            float64          `json:"net_apy"`
+	RiskScore         float64          `json:"risk_score"` // 叠加市场风险因素后按策略资产加权
+	RiskPenalty       float64          `json:"risk_penalty"`
+	GasCostUSD        *float64         `json:"gas_cost_usd"` // 一次完整存取，gas或资产价格不可用时为空
+	GasDrag           *float64         `json:"gas_drag"`     // gas成本按 routing.horizon_days 折算的年化
+	Score             float64          `json:"score"`
+	RemainingCapacity *decimal.Decimal `json:"remaining_capacity"`
+	Excluded          string           `json:"excluded,omitempty"`
+}
+
+// RouteAllocation 推荐存入一个资金库的数量，WeightBps 相对已分配的总额。拆分都在同一条链上，可直接用于批量存款
+type RouteAllocation struct {
+	Vault     string          `json:"vault"`
+	ChainID   uint            `json:"chain_id"`
+	Amount    decimal.Decimal `json:"amount"`
+	WeightBps uint16          `json:"weight_bps"`
+	NetAPY    float64         `json:"net_apy"`
+	Score     float64         `json:"score"`
+}
+
+// Route 资产存入推荐：最优资金库，或在额度不足时按评分依次拆分，附带推荐理由和全部候选的评分
+type Route struct {
+	Asset         string            `json:"asset"`
+	ChainID       uint              `json:"chain_id,omitempty"`
+	Amount        decimal.Decimal   `json:"amount"`
+	AmountUSD     *float64          `json:"amount_usd"` // 按分配所在链的资产价格
+	Allocations   []RouteAllocation `json:"allocations"`
+	Unallocated   decimal.Decimal   `json:"unallocated"` // 候选资金库剩余额度合计不足时未能分配的部分
+	BlendedNetAPY float64           `json:"blended_net_apy"`
+	Rationale     []string          `json:"rationale"`
+	Candidates    []RouteCandidate  `json:"candidates"`
+}
+
+// RoutingService 为一笔资产推荐存入的资金库，综合扣除费用后的APY、风险分、剩余额度和gas成本
+type RoutingService struct {
+	vaultService *VaultService
+	tokenRepo    *repository.TokenRepository
+	gas          *GasService
+	prices       *prices.Service
+	morpho       *MorphoService
+}
+
+func NewRoutingService() *RoutingService {
+	return NewRoutingServiceWith(repository.Default())
+}
+
+// NewRoutingServiceWith 使用注入的核心仓储构建
+func NewRoutingServiceWith(repos *repository.Repositories) *RoutingService {
+	return &RoutingService{
+		vaultService: NewVaultServiceWith(repos),
+		tokenRepo:    repository.NewTokenRepository(),
+		gas:          NewGasServiceWith(repos),
+		prices:       prices.Default(),
+		morpho:       NewMorphoServiceWith(repos),
+	}
+}
+
+// Route 推荐存入amount个asset的资金库。asset 为代币登记表中的符号或代币地址，chainID 为0时比较所有链上的同名资产
+func (s *RoutingService) Route(ctx context.Context, asset string, chainID uint, amount decimal.Decimal) (*Route, error) {
+	assets, err := resolveAsset(s.tokenRepo, asset, chainID)
+	if err != nil {
+		return nil, err
+	}
+	vaults, err := s.vaultService.GetVaults(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := config.Load()
+	horizon := cfg.Routing.HorizonDays
+	if horizon <= 0 {
+		horizon = 30
+	}
+	var matched []*models.Vault
+	for i := range vaults {
+		if assets[assetKey(vaults[i].ChainID, vaults[i].AssetAddress)] {
+			matched = append(matched, &vaults[i])
+		}
+	}
+	if len(matched) == 0 {
+		return nil, ErrRouteNoVault
+	}
+	risks, err := vaultRiskScores(s.morpho, matched)
+	if err != nil {
+		return nil, err
+	}
+
+	route := &Route{Asset: asset, ChainID: chainID, Amount: amount, Unallocated: amount}
+	amountUSD := make(map[uint]*float64)
+	gasUSD := make(map[uint]*float64)
+	for _, vault := range matched {
+		if _, ok := amountUSD[vault.ChainID]; !ok {
+			amountUSD[vault.ChainID] = s.usdValue(ctx, vault, amount)
+			gasUSD[vault.ChainID] = s.gas.Estimate(ctx, vault.ChainID, cfg.Gas.RoundTripGas).CostUSD
+		}
+		if route.AmountUSD == nil {
+			route.AmountUSD = amountUSD[vault.ChainID]
+		}
+		route.Candidates = append(route.Candidates, s.candidate(vault, cfg.Routing, risks[vault.Address], amount, amountUSD[vault.ChainID], gasUSD[vault.ChainID], horizon))
+	}
+
+	// 参与推荐的排在前面，各自按评分降序
+	sort.SliceStable(route.Candidates, func(i, j int) bool {
+		a, b := route.Candidates[i], route.Candidates[j]
+		if (a.Excluded == "") != (b.Excluded == "") {
+			return a.Excluded == ""
+		}
+		return a.Score > b.Score
+	})
+	s.allocate(route, cfg.Routing, horizon, amountUSD)
+	return route, nil
+}
+
+// candidate 计算资金库在存入amount时的评分，不接受存款、需要白名单、风险过高或已满的资金库标记为排除
+func (s *RoutingService) candidate(vault *models.Vault, cfg config.RoutingConfig, risk float64, amount decimal.Decimal, amountUSD, gasUSD *float64, horizon int) RouteCandidate {
+	net, feeDrag := vaultNetAPY(vault)
+	c := RouteCandidate{
+		Vault:             vault.Address,
+		Name:              vault.Name,
+		ChainID:           vault.ChainID,
+		APY:               vault.APYCurrent,
+		FeeDrag:           feeDrag,
+		NetAPY:            net,
+		RiskScore:         risk,
+		RiskPenalty:       cfg.RiskPenalty * risk,
+		GasCostUSD:        gasUSD,
+		RemainingCapacity: vault.RemainingCapacity(),
+	}
+	legAmount := amount
+	if c.RemainingCapacity != nil && c.RemainingCapacity.LessThan(amount) {
+		legAmount = *c.RemainingCapacity
+	}
+	c.GasDrag = gasDrag(gasUSD, amountUSD, legAmount.Div(amount), horizon)
+	c.Score = c.NetAPY - c.RiskPenalty
+	if c.GasDrag != nil {
+		c.Score -= *c.GasDrag
+		*c.GasDrag = roundRate(*c.GasDrag)
+	}
+	c.FeeDrag, c.NetAPY, c.RiskPenalty, c.Score = roundRate(c.FeeDrag), roundRate(c.NetAPY), roundRate(c.RiskPenalty), roundRate(c.Score)
+
+	switch {
+	case !vault.AcceptsDeposits():
+		c.Excluded = "vault is not accepting deposits"
+	case vault.AllowlistEnabled:
+		c.Excluded = "vault only accepts allowlisted depositors"
+	case cfg.MaxRiskScore > 0 && risk > cfg.MaxRiskScore:
+		c.Excluded = fmt.Sprintf("risk score %.1f is above routing.max_risk_score %.1f", risk, cfg.MaxRiskScore)
+	case c.RemainingCapacity != nil && !c.RemainingCapacity.IsPositive():
+		c.Excluded = "vault is at its deposit cap"
+	}
+	return c
+}
+
+// allocate 按评分依次把金额分配给参与推荐的资金库，单个资金库只受剩余额度限制，最多拆分 routing.max_splits 个。
+// 拆分只在首选资金库所在的链上进行，以便整体作为一次批量存款提交；拆分出的部分按该链上的资产价格和自身金额重新摊销gas，
+// 评分不再为正时不再拆分。amountUSD 为各链上存入金额的美元价值
+func (s *RoutingService) allocate(route *Route, cfg config.RoutingConfig, horizon int, amountUSD map[uint]*float64) {
+	maxSplits := cfg.MaxSplits
+	if maxSplits <= 0 {
+		maxSplits = 1
+	}
+	remaining := route.Amount
+	var skipped []string
+	for _, c := range route.Candidates {
+		if c.Excluded != "" || !remaining.IsPositive() || len(route.Allocations) >= maxSplits {
+			break
+		}
+		if len(route.Allocations) > 0 && c.ChainID != route.Allocations[0].ChainID {
+			continue
+		}
+		take := remaining
+		if c.RemainingCapacity != nil && c.RemainingCapacity.LessThan(take) {
+			take = *c.RemainingCapacity
+		}
+		score := c.NetAPY - c.RiskPenalty
+		if drag := gasDrag(c.GasCostUSD, amountUSD[c.ChainID], take.Div(route.Amount), horizon); drag != nil {
+			score -= *drag
+		}
+		if len(route.Allocations) > 0 && score <= 0 {
+			skipped = append(skipped, c.Vault)
+			break
+		}
+		route.Allocations = append(route.Allocations, RouteAllocation{
+			Vault:   c.Vault,
+			ChainID: c.ChainID,
+			Amount:  take,
+			NetAPY:  c.NetAPY,
+			Score:   roundRate(score),
+		})
+		remaining = remaining.Sub(take)
+	}
+	route.Unallocated = remaining
+	if len(route.Allocations) > 0 {
+		route.AmountUSD = amountUSD[route.Allocations[0].ChainID]
+	}
+
+	allocated := route.Amount.Sub(remaining)
+	if allocated.IsPositive() {
+		var weighted decimal.Decimal
+		bps := 0
+		for i := range route.Allocations {
+			a := &route.Allocations[i]
+			weighted = weighted.Add(a.Amount.Mul(decimal.NewFromFloat(a.NetAPY)))
+			if i == len(route.Allocations)-1 {
+				a.WeightBps = uint16(10000 - bps)
+				break
+			}
+			a.WeightBps = uint16(a.Amount.Div(allocated).Mul(bpsScale).IntPart())
+			bps += int(a.WeightBps)
+		}
+		route.BlendedNetAPY = roundRate(weighted.Div(allocated).InexactFloat64())
+	}
+	route.Rationale = routeRationale(route, skipped, horizon)
+}
+
+// routeRationale 用文字说明推荐的依据：首选资金库的评分构成、额度不足时的拆分和被排除的候选
+func routeRationale(route *Route, skipped []string, horizon int) []string {
+	var rationale []string
+	if len(route.Allocations) == 0 {
+		rationale = append(rationale, "No vault can take this deposit; see candidates[].excluded for the reasons")
+		return rationale
+	}
+
+	byVault := make(map[string]RouteCandidate, len(route.Candidates))
+	for _, c := range route.Candidates {
+		byVault[c.Vault] = c
+	}
+	best := byVault[route.Allocations[0].Vault]
+	line := fmt.Sprintf("%s on chain %d has the highest risk-adjusted net APY of %s: APY %s, fees -%s, risk score %.1f -%s",
+		best.Name, best.ChainID, percent(best.Score), percent(best.APY), percent(best.FeeDrag), best.RiskScore, percent(best.RiskPenalty))
+	if best.GasDrag != nil {
+		line += fmt.Sprintf(", gas -%s amortized over %d days", percent(*best.GasDrag), horizon)
+	} else {
+		line += ", gas cost unknown and not included"
+	}
+	rationale = append(rationale, line)
+
+	for i := 1; i < len(route.Allocations); i++ {
+		prev, next := route.Allocations[i-1], byVault[route.Allocations[i].Vault]
+		rationale = append(rationale, fmt.Sprintf("%s only has %s of capacity left; the next %s goes to %s (score %s)",
+			byVault[prev.Vault].Name, prev.Amount.String(), route.Allocations[i].Amount.String(), next.Name, percent(route.Allocations[i].Score)))
+	}
+	for _, vault := range skipped {
+		rationale = append(rationale, fmt.Sprintf("Not splitting into %s: gas on the remaining amount outweighs its yield", byVault[vault].Name))
+	}
+	if route.Unallocated.IsPositive() {
+		rationale = append(rationale, fmt.Sprintf("%s could not be placed: eligible vaults on chain %d are short of capacity", route.Unallocated.String(), best.ChainID))
+	}
+	excluded := 0
+	for _, c := range route.Candidates {
+		if c.Excluded != "" {
+			excluded++
+		}
+	}
+	if excluded > 0 {
+		rationale = append(rationale, fmt.Sprintf("%d vault(s) excluded; see candidates[].excluded", excluded))
+	}
+	return rationale
+}
+
+// resolveAsset 把资产符号或地址解析为 "链ID:地址" 集合
+func resolveAsset(tokenRepo *repository.TokenRepository, asset string, chainID uint) (map[string]bool, error) {
+	assets := make(map[string]bool)
+	if common.IsHexAddress(asset) {
+		if chainID != 0 {
+			assets[assetKey(chainID, asset)] = true
+			return assets, nil
+		}
+		// 未指定链时按地址匹配所有链
+		tokens, err := tokenRepo.List(0, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, token := range tokens {
+			if strings.EqualFold(token.Address, asset) {
+				assets[assetKey(token.ChainID, token.Address)] = true
+			}
+		}
+	} else {
+		tokens, err := tokenRepo.List(chainID, asset)
+		if err != nil {
+			return nil, err
+		}
+		for _, token := range tokens {
+			assets[assetKey(token.ChainID, token.Address)] = true
+		}
+	}
+	if len(assets) == 0 {
+		return nil, ErrRouteAssetUnknown
+	}
+	return assets, nil
+}
+
+func (s *RoutingService) usdValue(ctx context.Context, vault *models.Vault, amount decimal.Decimal) *float64 {
+	price, err := s.prices.GetPrice(ctx, vault.AssetAddress, vault.ChainID)
+	if err != nil {
+		return nil
+	}
+	value := amount.InexactFloat64() * price.USD
+	return &value
+}
+
+// gasDrag 一次完整存取的gas成本占存入金额的比例，按持有期折算为年化；share 为存入部分占amountUSD的比例
+func gasDrag(gasUSD, amountUSD *float64, share decimal.Decimal, horizon int) *float64 {
+	if gasUSD == nil || amountUSD == nil || *amountUSD <= 0 || !share.IsPositive() {
+		return nil
+	}
+	drag := *gasUSD / (*amountUSD * share.InexactFloat64()) * 365 / float64(horizon)
+	return &drag
+}
+
+func assetKey(chainID uint, address string) string {
+	return fmt.Sprintf("%d:%s", chainID, strings.ToLower(address))
+}
+
+// roundRate 收益率保留6位小数(0.0001%)，去掉浮点运算的尾差
+func roundRate(rate float64) float64 {
+	return math.Round(rate*1e6) / 1e6
+}
+
+func percent(rate float64) string {
+	return fmt.Sprintf("%.2f%%", rate*100)
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/cache"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/screening"
+)
+
+var ErrScreeningUnavailable = errors.New("sanctions screening is temporarily unavailable")
+
+const screeningCachePrefix = "screening:"
+
+// ScreeningService 存款意向的制裁地址筛查。本地名单优先，其次查询提供方(结果按 screening.cache_ttl 缓存)，
+// 每次结论都写入 screening_decisions
+type ScreeningService struct {
+	provider    screening.Provider
+	providerErr error
+	blocklist   map[string]bool
+	repo        *repository.ScreeningRepository
+	cacheTTL    time.Duration
+	failOpen    bool
+}
+
+func NewScreeningService() *ScreeningService {
+	cfg := config.Load().Screening
+	provider, err := screening.New(cfg)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Invalid screening provider %q: %v", cfg.Provider, err))
+	}
+
+	blocklist := make(map[string]bool, len(cfg.Blocklist))
+	for _, address := range cfg.Blocklist {
+		blocklist[strings.ToLower(address)] = true
+	}
+	return &ScreeningService{
+		provider:    provider,
+		providerErr: err,
+		blocklist:   blocklist,
+		repo:        repository.NewScreeningRepository(),
+		cacheTTL:    time.Duration(cfg.CacheTTL) * time.Second,
+		failOpen:    cfg.FailOpen,
+	}
+}
+
+// Enabled 是否配置了提供方或本地名单
+func (s *ScreeningService) Enabled() bool {
+	return s.provider != nil || s.providerErr != nil || len(s.blocklist) > 0
+}
+
+// ScreenDeposit 筛查存款地址并记录结论。未启用筛查时返回nil；
+// 提供方不可用且未开启 fail_open 时记录 unavailable 并返回 ErrScreeningUnavailable
+func (s *ScreeningService) ScreenDeposit(ctx context.Context, userAddress, vaultAddress string) (*models.ScreeningDecision, error) {
+	if !s.Enabled() {
+		return nil, nil
+	}
+	userAddress = strings.ToLower(userAddress)
+
+	decision := &models.ScreeningDecision{
+		UserAddress:  userAddress,
+		VaultAddress: strings.ToLower(vaultAddress),
+	}
+
+	result, cached, err := s.screen(ctx, userAddress)
+	switch {
+	case err != nil:
+		decision.Provider = s.providerName()
+		decision.Error = err.Error()
+		decision.Decision = models.ScreeningUnavailable
+		if s.failOpen {
+			decision.Decision = models.ScreeningAllowed
+		}
+	default:
+		decision.Provider = result.Provider
+		decision.Cached = cached
+		decision.Decision = models.ScreeningAllowed
+		if result.Flagged {
+			decision.Decision = models.ScreeningBlocked
+		}
+		if len(result.Identifications) > 0 {
+			if raw, err := json.Marshal(result.Identifications); err == nil {
+				decision.Identifications = raw
+			}
+		}
+	}
+
+	if err := s.repo.Create(decision); err != nil {
+		// 结论无法留档时不放行，合规复核需要完整记录
+		return nil, err
+	}
+	if decision.Decision == models.ScreeningBlocked {
+		logger.Info(fmt.Sprintf("🚫 Deposit intent from %s to %s blocked by %s screening", userAddress, decision.VaultAddress, decision.Provider))
+	}
+	if decision.Decision == models.ScreeningUnavailable {
+		return decision, ErrScreeningUnavailable
+	}
+	return decision, nil
+}
+
+// ListDecisions 按时间倒序获取筛查结论
+func (s *ScreeningService) ListDecisions(decision, userAddress string, limit int) ([]models.ScreeningDecision, error) {
+	return s.repo.List(decision, strings.ToLower(userAddress), limit)
+}
+
+// screen 依次查本地名单、缓存和提供方，返回结果及是否来自缓存
+func (s *ScreeningService) screen(ctx context.Context, address string) (*screening.Result, bool, error) {
+	if s.blocklist[address] {
+		return &screening.Result{
+			Provider:        "blocklist",
+			Flagged:         true,
+			Identifications: []screening.Identification{{Category: "sanctions", Name: "Configured blocklist"}},
+		}, false, nil
+	}
+	if s.providerErr != nil {
+		return nil, false, s.providerErr
+	}
+	if s.provider == nil {
+		return &screening.Result{Provider: "blocklist"}, false, nil
+	}
+
+	key := screeningCachePrefix + address
+	var result screening.Result
+	if cache.GetJSON(ctx, key, &result) {
+		return &result, true, nil
+	}
+
+	fresh, err := s.provider.Screen(ctx, address)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Screening %s via %s failed: %v", address, s.provider.Name(), err))
+		return nil, false, err
+	}
+	if s.cacheTTL > 0 {
+		cache.SetJSON(ctx, key, fresh, s.cacheTTL)
+	}
+	return fresh, false, nil
+}
+
+func (s *ScreeningService) providerName() string {
+	if s.provider != nil {
+		return s.provider.Name()
+	}
+	return config.Load().Screening.Provider
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/prices"
+
+	"github.com/shopspring/decimal"
+)
+
+// 搜索结果类型
+const (
+	SearchVault    = "vault"
+	SearchStrategy = "strategy"
+)
+
+// 查询词的最小长度，地址前缀至少需要 0x 加两位
+const minSearchQuery = 2
+
+var ErrSearchQueryTooShort = errors.New("search query must be at least 2 characters")
+
+// 匹配得分，完全匹配优先于前缀匹配，前缀匹配优先于包含
+const (
+	scoreExact     = 100
+	scorePrefix    = 80
+	scoreAddress   = 70
+	scoreWordStart = 60
+	scoreContains  = 40
+)
+
+// SearchResult 一条搜索结果，MatchedOn 为得分最高的匹配字段
+type SearchResult struct {
+	Type         string          `json:"type"`
+	Address      string          `json:"address"`
+	Name         string          `json:"name"`
+	Symbol       string          `json:"symbol,omitempty"`
+	AssetSymbol  string          `json:"asset_symbol,omitempty"`
+	Protocol     string          `json:"protocol,omitempty"`
+	VaultAddress string          `json:"vault_address,omitempty"` // 策略所属的资金库
+	ChainID      uint            `json:"chain_id"`
+	APY          float64         `json:"apy"`
+	TVL          decimal.Decimal `json:"tvl"`
+	MatchedOn    string          `json:"matched_on"`
+	Score        int             `json:"score"`
+}
+
+type SearchService struct {
+	vaultService *VaultService
+	priceService *prices.Service
+}
+
+func NewSearchService() *SearchService {
+	return NewSearchServiceWith(repository.Default())
+}
+
+// NewSearchServiceWith 使用注入的核心仓储构建
+func NewSearchServiceWith(repos *repository.Repositories) *SearchService {
+	return &SearchService{
+		vaultService: NewVaultServiceWith(repos),
+		priceService: prices.Default(),
+	}
+}
+
+// Search 在活跃资金库和策略中按名称、符号、底层资产符号和地址前缀不区分大小写匹配，
+// 按得分、TVL降序返回前limit条。kind为空时同时搜索两种类型
+func (s *SearchService) Search(ctx context.Context, query, kind string, limit int) ([]SearchResult, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if len(query) < minSearchQuery {
+		return nil, ErrSearchQueryTooShort
+	}
+
+	vaults, err := s.vaultService.GetVaults(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []SearchResult{}
+	for _, vault := range vaults {
+		assetSymbol := ""
+		if token, ok := s.priceService.Token(vault.ChainID, vault.AssetAddress); ok {
+			assetSymbol = token.Symbol
+		}
+
+		if kind == "" || kind == SearchVault {
+			field, score := bestMatch(query, vault.Address, map[string]string{
+				"name":         vault.Name,
+				"symbol":       vault.Symbol,
+				"asset_symbol": assetSymbol,
+			})
+			if score > 0 {
+				results = append(results, SearchResult{
+					Type:        SearchVault,
+					Address:     vault.Address,
+					Name:        vault.Name,
+					Symbol:      vault.Symbol,
+					AssetSymbol: assetSymbol,
+					ChainID:     vault.ChainID,
+					APY:         vault.APYCurrent,
+					TVL:         vault.TVL,
+					MatchedOn:   field,
+					Score:       score,
+				})
+			}
+		}
+
+		if kind == "" || kind == SearchStrategy {
+			for _, st := range vault.Strategies {
+				if !st.IsActive {
+					continue
+				}
+				field, score := bestMatch(query, st.Address, map[string]string{
+					"name":         st.Name,
+					"protocol":     st.Protocol,
+					"asset_symbol": assetSymbol,
+				})
+				if score == 0 {
+					continue
+				}
+				results = append(results, SearchResult{
+					Type:         SearchStrategy,
+					Address:      st.Address,
+					Name:         st.Name,
+					AssetSymbol:  assetSymbol,
+					Protocol:     st.Protocol,
+					VaultAddress: vault.Address,
+					ChainID:      vault.ChainID,
+					APY:          st.APY,
+					TVL:          st.TotalAssets,
+					MatchedOn:    field,
+					Score:        score,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].TVL.GreaterThan(results[j].TVL)
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// bestMatch 返回得分最高的字段，地址只按前缀匹配，都不匹配时得分为0
+func bestMatch(query, address string, fields map[string]string) (string, int) {
+	best, bestScore := "", 0
+	if strings.HasPrefix(query, "0x") && strings.HasPrefix(address, query) {
+		best, bestScore = "address", scoreAddress
+		if address == query {
+			bestScore = scoreExact
+		}
+	}
+	// 按固定顺序比较，得分相同时优先名称
+	for _, name := range []string{"name", "symbol", "asset_symbol", "protocol"} {
+		value, ok := fields[name]
+		if !ok || value == "" {
+			continue
+		}
+		if score := matchScore(query, strings.ToLower(value)); score > bestScore {
+			best, bestScore = name, score
+		}
+	}
+	return best, bestScore
+}
+
+func matchScore(query, value string) int {
+	switch {
+	case value == query:
+		return scoreExact
+	case strings.HasPrefix(value, query):
+		return scorePrefix
+	case strings.Contains(value, " "+query), strings.Contains(value, "-"+query):
+		return scoreWordStart
+	case strings.Contains(value, query):
+		return scoreContains
+	}
+	return 0
+}
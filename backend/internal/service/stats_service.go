@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/cache"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/prices"
+
+	"github.com/shopspring/decimal"
+)
+
+// SystemStats 平台汇总统计，金额均为按当前价格换算的USD
+type SystemStats struct {
+	TotalTVL         float64            `json:"total_tvl"`
+	TotalUsers       int64              `json:"total_users"`
+	TotalVaults      int                `json:"total_vaults"`
+	TotalStrategies  int                `json:"total_strategies"`
+	TotalDeposits    float64            `json:"total_deposits"`
+	TotalWithdrawals float64            `json:"total_withdrawals"`
+	TotalYield       float64            `json:"total_yield"`
+	AvgAPY           float64            `json:"avg_apy"`
+	UnpricedVaults   []string           `json:"unpriced_vaults,omitempty"`
+	Fiat             map[string]float64 `json:"fiat,omitempty"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+}
+
+const statsCacheKey = "stats:system"
+
+type StatsService struct {
+	vaultRepo    repository.VaultRepo
+	userRepo     repository.UserRepo
+	priceService *prices.Service
+	ttl          time.Duration
+}
+
+func NewStatsService() *StatsService {
+	return NewStatsServiceWith(repository.Default())
+}
+
+// NewStatsServiceWith 使用注入的仓储构建，main统一构建依赖时使用
+func NewStatsServiceWith(repos *repository.Repositories) *StatsService {
+	return &StatsService{
+		vaultRepo:    repos.Vaults,
+		userRepo:     repos.Users,
+		priceService: prices.Default(),
+		ttl:          time.Duration(config.Load().Cache.StatsTTL) * time.Second,
+	}
+}
+
+// GetSystemStats 汇总所有活跃资金库，平均APY按USD TVL加权，优先读取缓存
+func (s *StatsService) GetSystemStats(ctx context.Context) (*SystemStats, error) {
+	var cached SystemStats
+	if cache.GetJSON(ctx, statsCacheKey, &cached) {
+		return &cached, nil
+	}
+
+	vaults, err := s.vaultRepo.WithContext(ctx).GetActiveVaults()
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.userRepo.WithContext(ctx).Count()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &SystemStats{
+		TotalUsers:  users,
+		TotalVaults: len(vaults),
+		UpdatedAt:   time.Now(),
+	}
+
+	var weightedAPY float64
+	for _, vault := range vaults {
+		stats.TotalStrategies += len(vault.Strategies)
+
+		price, err := s.priceService.GetPrice(ctx, vault.AssetAddress, vault.ChainID)
+		if err != nil {
+			stats.UnpricedVaults = append(stats.UnpricedVaults, vault.Address)
+			continue
+		}
+
+		usd := decimal.NewFromFloat(price.USD)
+		tvl := vault.TVL.Mul(usd).InexactFloat64()
+		stats.TotalTVL += tvl
+		stats.TotalDeposits += vault.TotalDeposits.Mul(usd).InexactFloat64()
+		stats.TotalWithdrawals += vault.TotalWithdrawals.Mul(usd).InexactFloat64()
+		for _, st := range vault.Strategies {
+			stats.TotalYield += st.TotalEarnings.Mul(usd).InexactFloat64()
+		}
+		weightedAPY += vault.APYCurrent * tvl
+	}
+
+	if stats.TotalTVL > 0 {
+		stats.AvgAPY = weightedAPY / stats.TotalTVL
+	}
+
+	cache.SetJSON(ctx, statsCacheKey, stats, s.ttl)
+	return stats, nil
+}
@@ -0,0 +1,346 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/metrics"
+	"github.com/chspring1/mya-platform/backend/pkg/prices"
+)
+
+// StatusUnknown 组件超过3个检查周期没有检查结果(worker未运行)
+const StatusUnknown = "unknown"
+
+// 状态页组件，索引按链区分为 indexer:<链ID>
+const (
+	ComponentAPI      = "api"
+	ComponentDatabase = "database"
+	ComponentPrices   = "prices"
+	ComponentKeeper   = "keeper"
+	componentIndexer  = "indexer:"
+)
+
+// 状态页最多展示的异常记录数
+const maxStatusIncidents = 50
+
+// ComponentStatus 状态页上的一个组件，Since 为进入当前状态的时间
+type ComponentStatus struct {
+	Component string     `json:"component"`
+	Name      string     `json:"name"`
+	Status    string     `json:"status"`
+	Message   string     `json:"message,omitempty"`
+	Since     *time.Time `json:"since,omitempty"`
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+}
+
+// IncidentSummary 组件的一段非正常状态，Ongoing 表示尚未恢复
+type IncidentSummary struct {
+	Component string     `json:"component"`
+	Name      string     `json:"name"`
+	Status    string     `json:"status"`
+	Message   string     `json:"message,omitempty"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	Duration  string     `json:"duration"`
+	Ongoing   bool       `json:"ongoing"`
+}
+
+// PlatformStatus 公开状态页，Status 为各组件中最严重的状态
+type PlatformStatus struct {
+	Status     string            `json:"status"`
+	Components []ComponentStatus `json:"components"`
+	Incidents  []IncidentSummary `json:"incidents"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+// componentCheck 一次组件检查的结果
+type componentCheck struct {
+	component string
+	status    string
+	message   string
+}
+
+// StatusService 公开状态页。worker按 status.interval 检查数据库、各链索引、价格源和keeper并记录状态变化；
+// API组件按当前实例最近5分钟的5xx比例实时判断，不记录历史
+type StatusService struct {
+	repo       *repository.StatusRepository
+	keeperRepo *repository.KeeperRepository
+	prices     *prices.Service
+	incidents  *IncidentService
+}
+
+func NewStatusService() *StatusService {
+	return NewStatusServiceWith(repository.Default())
+}
+
+// NewStatusServiceWith 使用注入的核心仓储构建
+func NewStatusServiceWith(repos *repository.Repositories) *StatusService {
+	return &StatusService{
+		repo:       repository.NewStatusRepository(),
+		keeperRepo: repository.NewKeeperRepository(),
+		prices:     prices.Default(),
+		incidents:  NewIncidentServiceWith(repos),
+	}
+}
+
+// Check 检查各组件并记录结果，再交给事件管理判断是否需要创建或结束事件。
+// 数据库不可达时无法记录，状态页据检查结果过期判断
+func (s *StatusService) Check(ctx context.Context) error {
+	checkCtx, cancel := context.WithTimeout(ctx, time.Duration(config.Load().Health.CheckTimeout)*time.Second)
+	err := pingDatabase(checkCtx)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("database unreachable: %w", err)
+	}
+
+	checks := []componentCheck{{component: ComponentDatabase, status: models.StatusOperational}}
+	checks = append(checks, s.checkIndexers(ctx)...)
+	if check, ok := s.checkPrices(ctx); ok {
+		checks = append(checks, check)
+	}
+	if check, ok := s.checkKeeper(); ok {
+		checks = append(checks, check)
+	}
+
+	now := time.Now()
+	for _, check := range checks {
+		period, changed, err := s.repo.Record(check.component, check.status, check.message, now)
+		if err != nil {
+			return err
+		}
+		if changed {
+			logger.Info(fmt.Sprintf("Component %s is now %s %s", check.component, check.status, check.message))
+		}
+		if err := s.incidents.Observe(ctx, check.component, check.status, check.message, period.StartedAt); err != nil {
+			logger.Error(fmt.Sprintf("Failed to update incident for %s: %v", check.component, err))
+		}
+	}
+	return nil
+}
+
+// Get 当前各组件状态和最近 status.history_days 天的异常。数据库不可达时数据库报告为 outage，其余组件为 unknown
+func (s *StatusService) Get() *PlatformStatus {
+	cfg := config.Load().Status
+	now := time.Now()
+	status := &PlatformStatus{
+		Components: []ComponentStatus{apiStatus(cfg)},
+		Incidents:  []IncidentSummary{},
+		UpdatedAt:  now,
+	}
+
+	current, err := s.repo.Current()
+	if err != nil {
+		status.Components = append(status.Components, ComponentStatus{
+			Component: ComponentDatabase,
+			Name:      componentName(ComponentDatabase),
+			Status:    models.StatusOutage,
+			Message:   "Database is unreachable",
+		})
+		status.Status = overallStatus(status.Components)
+		return status
+	}
+
+	stale := 3 * time.Duration(cfg.Interval) * time.Minute
+	if stale < 5*time.Minute {
+		stale = 5 * time.Minute
+	}
+	for _, period := range current {
+		component := ComponentStatus{
+			Component: period.Component,
+			Name:      componentName(period.Component),
+			Status:    period.Status,
+			Message:   period.Message,
+			Since:     &period.StartedAt,
+			CheckedAt: &period.CheckedAt,
+		}
+		if now.Sub(period.CheckedAt) > stale {
+			component.Status, component.Since = StatusUnknown, nil
+			component.Message = fmt.Sprintf("Not checked since %s", period.CheckedAt.UTC().Format(time.RFC3339))
+		}
+		status.Components = append(status.Components, component)
+	}
+	sort.SliceStable(status.Components[1:], func(i, j int) bool {
+		return componentOrder(status.Components[1+i].Component) < componentOrder(status.Components[1+j].Component)
+	})
+	status.Status = overallStatus(status.Components)
+
+	days := cfg.HistoryDays
+	if days <= 0 {
+		days = 7
+	}
+	periods, err := s.repo.ListAbnormal(now.AddDate(0, 0, -days), maxStatusIncidents)
+	if err != nil {
+		return status
+	}
+	for _, period := range periods {
+		end := now
+		if period.EndedAt != nil {
+			end = *period.EndedAt
+		}
+		status.Incidents = append(status.Incidents, IncidentSummary{
+			Component: period.Component,
+			Name:      componentName(period.Component),
+			Status:    period.Status,
+			Message:   period.Message,
+			StartedAt: period.StartedAt,
+			EndedAt:   period.EndedAt,
+			Duration:  end.Sub(period.StartedAt).Round(time.Minute).String(),
+			Ongoing:   period.EndedAt == nil,
+		})
+	}
+	return status
+}
+
+// checkIndexers 各链索引：落后超过 health.max_indexer_lag 为降级，超过10倍为中断；RPC不可用时无法判断，报告为降级
+func (s *StatusService) checkIndexers(ctx context.Context) []componentCheck {
+	progress, err := loadIndexerProgress(ctx)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to load indexer progress: %v", err))
+		return nil
+	}
+	maxLag := config.Load().Health.MaxIndexerLag
+
+	checks := make([]componentCheck, 0, len(progress))
+	for _, p := range progress {
+		check := componentCheck{component: componentIndexer + strconv.FormatUint(uint64(p.ChainID), 10), status: models.StatusOperational}
+		headCtx, cancel := context.WithTimeout(ctx, headBlockTimeout)
+		head, err := blockchain.BlockNumber(headCtx, p.ChainID)
+		cancel()
+		switch {
+		case err != nil:
+			check.status, check.message = models.StatusDegraded, "Chain RPC is unavailable; indexing progress is unknown"
+		case maxLag > 0 && head > p.LastBlock && head-p.LastBlock > maxLag:
+			check.status, check.message = models.StatusDegraded, fmt.Sprintf("Indexing is %d blocks behind", head-p.LastBlock)
+			if head-p.LastBlock > 10*maxLag {
+				check.status = models.StatusOutage
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// checkPrices 价格源：全部代币取价失败为中断，部分失败为降级。没有配置代币时不检查
+func (s *StatusService) checkPrices(ctx context.Context) (componentCheck, bool) {
+	tokens := config.Load().Prices.Tokens
+	if len(tokens) == 0 {
+		return componentCheck{}, false
+	}
+	var failed []string
+	for _, token := range tokens {
+		if _, err := s.prices.GetPrice(ctx, token.Address, token.ChainID); err != nil {
+			failed = append(failed, token.Symbol)
+		}
+	}
+
+	check := componentCheck{component: ComponentPrices, status: models.StatusOperational}
+	switch {
+	case len(failed) == len(tokens):
+		check.status, check.message = models.StatusOutage, "No price feed is available"
+	case len(failed) > 0:
+		check.status, check.message = models.StatusDegraded, "Prices unavailable for "+strings.Join(failed, ", ")
+	}
+	return check, true
+}
+
+// checkKeeper keeper：有任务等待超过 status.keeper_max_wait 分钟为降级，同时没有启用的keeper为中断。
+// 未开启任务生成时不检查
+func (s *StatusService) checkKeeper() (componentCheck, bool) {
+	cfg := config.Load()
+	if cfg.Keeper.JobInterval <= 0 {
+		return componentCheck{}, false
+	}
+	check := componentCheck{component: ComponentKeeper, status: models.StatusOperational}
+
+	waiting, err := s.keeperRepo.CountWaiting(time.Now().Add(-time.Duration(cfg.Status.KeeperMaxWait) * time.Minute))
+	if err != nil || waiting == 0 {
+		return check, err == nil
+	}
+	keepers, err := s.keeperRepo.ListKeepers()
+	if err != nil {
+		return check, false
+	}
+	active := 0
+	for _, keeper := range keepers {
+		if keeper.Active {
+			active++
+		}
+	}
+	check.status, check.message = models.StatusDegraded, fmt.Sprintf("%d job(s) waiting over %d minutes", waiting, cfg.Status.KeeperMaxWait)
+	if active == 0 {
+		check.status, check.message = models.StatusOutage, fmt.Sprintf("No active keeper; %d job(s) waiting", waiting)
+	}
+	return check, true
+}
+
+// apiStatus 按当前实例最近5分钟的请求判断，请求太少时不判断错误率
+func apiStatus(cfg config.StatusConfig) ComponentStatus {
+	now := time.Now()
+	status := ComponentStatus{
+		Component: ComponentAPI,
+		Name:      componentName(ComponentAPI),
+		Status:    models.StatusOperational,
+		CheckedAt: &now,
+	}
+	stats := metrics.Requests.Snapshot()
+	if cfg.APIErrorRate > 0 && stats.Requests >= 20 && stats.ErrorRate > cfg.APIErrorRate {
+		status.Status = models.StatusDegraded
+		status.Message = fmt.Sprintf("Elevated error rate: %.1f%% of requests failed in the last %s", stats.ErrorRate*100, stats.Window)
+	}
+	return status
+}
+
+func componentName(component string) string {
+	switch {
+	case component == ComponentAPI:
+		return "API"
+	case component == ComponentDatabase:
+		return "Database"
+	case component == ComponentPrices:
+		return "Price feeds"
+	case component == ComponentKeeper:
+		return "Keeper"
+	case strings.HasPrefix(component, componentIndexer):
+		return "Indexer (chain " + strings.TrimPrefix(component, componentIndexer) + ")"
+	}
+	return component
+}
+
+// componentOrder 状态页上的组件顺序：数据库、各链索引、价格源、keeper
+func componentOrder(component string) string {
+	switch {
+	case component == ComponentDatabase:
+		return "0"
+	case strings.HasPrefix(component, componentIndexer):
+		chainID, _ := strconv.ParseUint(strings.TrimPrefix(component, componentIndexer), 10, 64)
+		return fmt.Sprintf("1%020d", chainID)
+	case component == ComponentPrices:
+		return "2"
+	case component == ComponentKeeper:
+		return "3"
+	}
+	return "4" + component
+}
+
+// overallStatus 最严重的组件状态，无法判断的组件按降级计
+func overallStatus(components []ComponentStatus) string {
+	overall := models.StatusOperational
+	for _, c := range components {
+		switch c.Status {
+		case models.StatusOutage:
+			return models.StatusOutage
+		case models.StatusDegraded, StatusUnknown:
+			overall = models.StatusDegraded
+		}
+	}
+	return overall
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/prices"
+	"github.com/chspring1/mya-platform/backend/pkg/rates"
+
+	"github.com/shopspring/decimal"
+)
+
+// StrategyHistory 策略历史表现及区间变化
+type StrategyHistory struct {
+	Strategy              *models.Strategy          `json:"strategy"`
+	Snapshots             []models.StrategySnapshot `json:"snapshots"`
+	APYChange             float64                   `json:"apy_change"`
+	EarningsChange        decimal.Decimal           `json:"earnings_change"`
+	LP                    *ILEstimate               `json:"impermanent_loss,omitempty"`       // 仅LP策略，价格不可用时为空
+	ConcentratedLiquidity *ConcentratedLiquidity    `json:"concentrated_liquidity,omitempty"` // 仅登记了Uniswap v3头寸的策略
+	Morpho                *MorphoLending            `json:"morpho,omitempty"`                 // 仅登记了Morpho市场的策略
+	SavingsRate           *SavingsRate              `json:"savings_rate,omitempty"`           // 仅 maker-dsr 策略
+	FixedRate             *FixedRate                `json:"fixed_rate,omitempty"`             // 仅登记了Pendle市场的固定利率策略
+}
+
+type StrategyService struct {
+	strategyRepo repository.StrategyRepo
+	snapshotRepo *repository.StrategySnapshotRepository
+	vaultRepo    repository.VaultRepo
+	batchRepo    *repository.SnapshotBatchRepository
+	notifier     *NotificationService
+	lpService    *LPService
+	uniswapV3    *UniswapV3Service
+	morpho       *MorphoService
+	dsr          *DSRService
+	pendle       *PendleService
+	priceService *prices.Service
+}
+
+func NewStrategyService() *StrategyService {
+	return NewStrategyServiceWith(repository.Default())
+}
+
+// NewStrategyServiceWith 使用注入的仓储构建，main统一构建依赖时使用
+func NewStrategyServiceWith(repos *repository.Repositories) *StrategyService {
+	return &StrategyService{
+		strategyRepo: repos.Strategies,
+		snapshotRepo: repository.NewStrategySnapshotRepository(),
+		vaultRepo:    repos.Vaults,
+		batchRepo:    repository.NewSnapshotBatchRepository(),
+		notifier:     NewNotificationServiceWith(repos),
+		lpService:    NewLPServiceWith(repos),
+		uniswapV3:    NewUniswapV3ServiceWith(repos),
+		morpho:       NewMorphoServiceWith(repos),
+		dsr:          NewDSRServiceWith(repos),
+		pendle:       NewPendleServiceWith(repos),
+		priceService: prices.Default(),
+	}
+}
+
+// SnapshotAll 为所有活跃策略记录一次APY、资产和收益快照，同时记录资金库APY历史和底层协议利率。
+// 一个周期的记录在同一个事务内批量写入
+func (s *StrategyService) SnapshotAll(ctx context.Context) error {
+	vaults, err := s.vaultRepo.GetActiveVaults()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	batch := &repository.SnapshotBatch{}
+	for _, vault := range vaults {
+		batch.APY = append(batch.APY, models.APYHistory{
+			VaultAddress: vault.Address,
+			APYValue:     vault.APYCurrent,
+			TVL:          vault.TVL,
+			Timestamp:    now,
+		})
+		s.notifier.CheckAPY(ctx, &vault)
+
+		var priceUSD *float64
+		if price, err := s.priceService.GetPrice(ctx, vault.AssetAddress, vault.ChainID); err == nil {
+			priceUSD = &price.USD
+		}
+
+		for _, st := range vault.Strategies {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			snapshot := models.StrategySnapshot{
+				StrategyAddress: st.Address,
+				APY:             st.APY,
+				TotalAssets:     st.TotalAssets,
+				TotalEarnings:   st.TotalEarnings,
+				Timestamp:       now,
+			}
+			if priceUSD != nil {
+				assetsUSD := st.TotalAssets.Mul(decimal.NewFromFloat(*priceUSD)).InexactFloat64()
+				snapshot.TotalAssetsUSD = &assetsUSD
+			}
+			if st.LPPoolType != "" {
+				estimate, err := s.lpService.estimate(ctx, &st, vault.ChainID)
+				if err != nil {
+					logger.Info(fmt.Sprintf("Failed to estimate impermanent loss of strategy %s: %v", st.Address, err))
+				} else if estimate != nil {
+					snapshot.ImpermanentLoss = &estimate.ImpermanentLoss
+				}
+			}
+			batch.Strategies = append(batch.Strategies, snapshot)
+
+			if st.Protocol == "" {
+				continue
+			}
+			batch.Rates = append(batch.Rates, models.ProtocolRate{
+				Protocol:     st.Protocol,
+				ChainID:      vault.ChainID,
+				AssetAddress: vault.AssetAddress,
+				APY:          st.APY,
+				Timestamp:    now,
+			})
+		}
+	}
+
+	if err := s.batchRepo.Save(batch); err != nil {
+		return err
+	}
+	logger.Info(fmt.Sprintf("Saved %d APY record(s), %d strategy snapshot(s), %d protocol rate(s)",
+		len(batch.APY), len(batch.Strategies), len(batch.Rates)))
+	return nil
+}
+
+// GetStrategy 根据地址获取策略，不存在时返回nil
+func (s *StrategyService) GetStrategy(address string) (*models.Strategy, error) {
+	return s.strategyRepo.GetByAddress(address)
+}
+
+// GetHistory 获取策略在时间区间内的历史表现，快照的 rate 字段按kind口径换算；策略不存在时返回nil
+func (s *StrategyService) GetHistory(ctx context.Context, address, kind string, from, to time.Time, limit int) (*StrategyHistory, error) {
+	strategy, err := s.strategyRepo.GetByAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	if strategy == nil {
+		return nil, nil
+	}
+
+	snapshots, err := s.snapshotRepo.GetHistory(address, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	strategy.APR = rates.ToAPR(strategy.APY)
+	for i := range snapshots {
+		snapshots[i].Rate = rates.FromAPY(snapshots[i].APY, kind)
+	}
+
+	history := &StrategyHistory{
+		Strategy:  strategy,
+		Snapshots: snapshots,
+	}
+	if len(snapshots) > 1 {
+		first, last := snapshots[0], snapshots[len(snapshots)-1]
+		history.APYChange = last.APY - first.APY
+		history.EarningsChange = last.TotalEarnings.Sub(first.TotalEarnings)
+	}
+	if estimate, err := s.lpService.Estimate(ctx, strategy); err != nil {
+		logger.Info(fmt.Sprintf("Failed to estimate impermanent loss of strategy %s: %v", address, err))
+	} else {
+		history.LP = estimate
+	}
+	if history.ConcentratedLiquidity, err = s.uniswapV3.Status(address); err != nil {
+		return nil, err
+	}
+	if history.Morpho, err = s.morpho.Status(address); err != nil {
+		return nil, err
+	}
+	if history.SavingsRate, err = s.dsr.Status(strategy); err != nil {
+		return nil, err
+	}
+	if history.FixedRate, err = s.pendle.Status(address); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
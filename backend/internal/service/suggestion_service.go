@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/notify"
+	"github.com/chspring1/mya-platform/backend/pkg/prices"
+)
+
+// suggestionMarket 一次评估共用的资金库、风险分和各链gas成本
+type suggestionMarket struct {
+	vaults  []*models.Vault
+	byAddr  map[string]*models.Vault
+	risks   map[string]float64
+	gasUSD  map[uint]*float64
+	horizon int
+}
+
+// SuggestionService 为订阅了 rebalance_suggestion 的用户评估持仓：同链同资产、风险分不更高的资金库净APY
+// 高出足够多，且多出的收益能在 suggestions.horizon_days 内覆盖转移的gas时给出建议，新建议通过订阅的渠道通知一次
+type SuggestionService struct {
+	repo       *repository.SuggestionRepository
+	notifyRepo *repository.NotificationRepository
+	txRepo     repository.TxRepo
+	vaults     *VaultService
+	morpho     *MorphoService
+	gas        *GasService
+	prices     *prices.Service
+	notifier   *NotificationService
+}
+
+func NewSuggestionService() *SuggestionService {
+	return NewSuggestionServiceWith(repository.Default())
+}
+
+// NewSuggestionServiceWith 使用注入的核心仓储构建
+func NewSuggestionServiceWith(repos *repository.Repositories) *SuggestionService {
+	return &SuggestionService{
+		repo:       repository.NewSuggestionRepository(),
+		notifyRepo: repository.NewNotificationRepository(),
+		txRepo:     repos.Transactions,
+		vaults:     NewVaultServiceWith(repos),
+		morpho:     NewMorphoServiceWith(repos),
+		gas:        NewGasServiceWith(repos),
+		prices:     prices.Default(),
+		notifier:   NewNotificationServiceWith(repos),
+	}
+}
+
+// EvaluateAll 评估所有订阅用户的持仓并替换其建议，已取消订阅用户的建议一并删除。单个用户失败只记录日志
+func (s *SuggestionService) EvaluateAll(ctx context.Context) error {
+	subscriptions, err := s.notifyRepo.ListEventSubscriptions(models.NotifyRebalanceSuggest)
+	if err != nil {
+		return err
+	}
+	// 每个用户的订阅范围：资金库地址(空为全部持仓)到 threshold
+	scopes := make(map[string]map[string]*float64)
+	var users []string
+	for _, sub := range subscriptions {
+		if scopes[sub.UserAddress] == nil {
+			scopes[sub.UserAddress] = make(map[string]*float64)
+			users = append(users, sub.UserAddress)
+		}
+		scopes[sub.UserAddress][sub.VaultAddress] = sub.Threshold
+	}
+	if _, err := s.repo.DeleteExcept(users); err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return nil
+	}
+
+	market, err := s.market(ctx)
+	if err != nil {
+		return err
+	}
+	total, notified := 0, 0
+	for _, user := range users {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		count, sent, err := s.evaluateUser(ctx, user, scopes[user], market)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to evaluate rebalance suggestions for %s: %v", user, err))
+			continue
+		}
+		total += count
+		notified += sent
+	}
+	logger.Info(fmt.Sprintf("Rebalance suggestions evaluated for %d users: %d suggestions, %d new", len(users), total, notified))
+	return nil
+}
+
+// List 获取用户当前的建议，optedIn 表示用户是否订阅了 rebalance_suggestion
+func (s *SuggestionService) List(userAddress string) (suggestions []models.RebalanceSuggestion, optedIn bool, err error) {
+	subscriptions, err := s.notifyRepo.ListSubscriptions(userAddress)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, sub := range subscriptions {
+		if sub.Event == models.NotifyRebalanceSuggest {
+			optedIn = true
+			break
+		}
+	}
+	suggestions, err = s.repo.ListByUser(userAddress)
+	return suggestions, optedIn, err
+}
+
+func (s *SuggestionService) market(ctx context.Context) (*suggestionMarket, error) {
+	vaults, err := s.vaults.GetVaults(ctx)
+	if err != nil {
+		return nil, err
+	}
+	market := &suggestionMarket{
+		byAddr:  make(map[string]*models.Vault, len(vaults)),
+		gasUSD:  make(map[uint]*float64),
+		horizon: config.Load().Suggestions.HorizonDays,
+	}
+	if market.horizon <= 0 {
+		market.horizon = 90
+	}
+	for i := range vaults {
+		market.vaults = append(market.vaults, &vaults[i])
+		market.byAddr[vaults[i].Address] = &vaults[i]
+	}
+	if market.risks, err = vaultRiskScores(s.morpho, market.vaults); err != nil {
+		return nil, err
+	}
+	return market, nil
+}
+
+// evaluateUser 评估一个用户的持仓并替换其建议。去向与上次相同的建议保留创建和通知时间，
+// 新出现或去向改变的建议在保存后通知。返回建议数和新通知数
+func (s *SuggestionService) evaluateUser(ctx context.Context, userAddress string, scope map[string]*float64, market *suggestionMarket) (int, int, error) {
+	totals, err := s.txRepo.WithContext(ctx).GetUserPositionTotals(userAddress)
+	if err != nil {
+		return 0, 0, err
+	}
+	previous, err := s.repo.ListByUser(userAddress)
+	if err != nil {
+		return 0, 0, err
+	}
+	byFrom := make(map[string]models.RebalanceSuggestion, len(previous))
+	for _, p := range previous {
+		byFrom[p.FromVault] = p
+	}
+
+	cfg := config.Load().Suggestions
+	now := time.Now()
+	var suggestions []models.RebalanceSuggestion
+	var fresh []int
+	for _, total := range totals {
+		vault := market.byAddr[total.VaultAddress]
+		if vault == nil || !total.Assets.IsPositive() {
+			continue
+		}
+		threshold, ok := scope[vault.Address]
+		if !ok {
+			if threshold, ok = scope[""]; !ok {
+				continue
+			}
+		}
+		minGain := cfg.MinAPYGain
+		if threshold != nil {
+			minGain = *threshold
+		}
+
+		suggestion := s.suggest(ctx, vault, total, minGain, cfg.MinPositionUSD, market)
+		if suggestion == nil {
+			continue
+		}
+		suggestion.UserAddress = userAddress
+		if prev, ok := byFrom[vault.Address]; ok && prev.ToVault == suggestion.ToVault {
+			suggestion.CreatedAt, suggestion.NotifiedAt = prev.CreatedAt, prev.NotifiedAt
+		}
+		if suggestion.NotifiedAt == nil {
+			suggestion.NotifiedAt = &now
+			fresh = append(fresh, len(suggestions))
+		}
+		suggestions = append(suggestions, *suggestion)
+	}
+
+	if err := s.repo.Replace(userAddress, suggestions); err != nil {
+		return 0, 0, err
+	}
+	for _, i := range fresh {
+		suggestion := suggestions[i]
+		s.notifier.NotifyUser(ctx, userAddress, models.NotifyRebalanceSuggest, suggestion.FromVault,
+			suggestionMessage(&suggestion, market.byAddr[suggestion.FromVault], market.byAddr[suggestion.ToVault]))
+	}
+	return len(suggestions), len(fresh), nil
+}
+
+// suggest 为一个持仓选出多出年化收益最多的去向，没有满足条件的资金库时返回nil。
+// 资产价格或gas价格不可用时无法确认收益能覆盖gas，不给出建议
+func (s *SuggestionService) suggest(ctx context.Context, vault *models.Vault, total repository.PositionTotal, minGain, minValueUSD float64, market *suggestionMarket) *models.RebalanceSuggestion {
+	if !vault.AcceptsWithdrawals() {
+		return nil
+	}
+	price, err := s.prices.GetPrice(ctx, vault.AssetAddress, vault.ChainID)
+	if err != nil {
+		return nil
+	}
+	valueUSD := total.Assets.InexactFloat64() * price.USD
+	if valueUSD < minValueUSD || valueUSD <= 0 {
+		return nil
+	}
+	gasUSD, ok := market.gasUSD[vault.ChainID]
+	if !ok {
+		gasUSD = s.gas.Estimate(ctx, vault.ChainID, config.Load().Gas.RoundTripGas).CostUSD
+		market.gasUSD[vault.ChainID] = gasUSD
+	}
+	if gasUSD == nil {
+		return nil
+	}
+
+	currentNet, _ := vaultNetAPY(vault)
+	currentRisk := market.risks[vault.Address]
+	var best *models.RebalanceSuggestion
+	for _, target := range market.vaults {
+		if target.Address == vault.Address || target.ChainID != vault.ChainID || !strings.EqualFold(target.AssetAddress, vault.AssetAddress) {
+			continue
+		}
+		if !target.AcceptsDeposits() || target.AllowlistEnabled {
+			continue
+		}
+		if remaining := target.RemainingCapacity(); remaining != nil && remaining.LessThan(total.Assets) {
+			continue
+		}
+		risk := market.risks[target.Address]
+		net, _ := vaultNetAPY(target)
+		if risk > currentRisk || net-currentNet < minGain {
+			continue
+		}
+		annualGain := valueUSD * (net - currentNet)
+		if annualGain*float64(market.horizon)/365 <= *gasUSD {
+			continue
+		}
+		if best != nil && annualGain <= best.AnnualGainUSD {
+			continue
+		}
+		best = &models.RebalanceSuggestion{
+			FromVault:        vault.Address,
+			ToVault:          target.Address,
+			ChainID:          vault.ChainID,
+			Assets:           total.Assets,
+			ValueUSD:         math.Round(valueUSD*100) / 100,
+			CurrentNetAPY:    roundRate(currentNet),
+			TargetNetAPY:     roundRate(net),
+			CurrentRiskScore: currentRisk,
+			TargetRiskScore:  risk,
+			GasCostUSD:       math.Round(*gasUSD*100) / 100,
+			AnnualGainUSD:    math.Round(annualGain*100) / 100,
+			BreakEvenDays:    math.Ceil(*gasUSD / annualGain * 365),
+		}
+	}
+	return best
+}
+
+func suggestionMessage(suggestion *models.RebalanceSuggestion, from, to *models.Vault) notify.Message {
+	return notify.Message{
+		Subject: fmt.Sprintf("Higher yield available for your %s position", from.Name),
+		Body: fmt.Sprintf("Moving your %s position (%s) to %s (%s) would raise its net APY from %.2f%% to %.2f%% "+
+			"with a risk score of %.1f instead of %.1f. That is about $%.2f more a year; the gas to move (about $%.2f) is earned back in %.0f days.\n\n"+
+			"This is only a suggestion; nothing is moved automatically.",
+			from.Name, from.Address, to.Name, to.Address, suggestion.CurrentNetAPY*100, suggestion.TargetNetAPY*100,
+			suggestion.TargetRiskScore, suggestion.CurrentRiskScore, suggestion.AnnualGainUSD, suggestion.GasCostUSD, suggestion.BreakEvenDays),
+	}
+}
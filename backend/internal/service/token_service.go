@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/prices"
+)
+
+// TokenService 维护代币登记表：资金库底层资产和价格配置中的代币读取链上符号、名称和精度，
+// 配置了 coingecko_id 的代币同时记录CoinGecko上的logo
+type TokenService struct {
+	vaultRepo    repository.VaultRepo
+	repo         *repository.TokenRepository
+	priceService *prices.Service
+	coingecko    *prices.CoinGeckoSource
+}
+
+func NewTokenService() *TokenService {
+	return NewTokenServiceWith(repository.Default())
+}
+
+// NewTokenServiceWith 使用注入的核心仓储构建
+func NewTokenServiceWith(repos *repository.Repositories) *TokenService {
+	cfg := config.Load().Prices
+	return &TokenService{
+		vaultRepo:    repos.Vaults,
+		repo:         repository.NewTokenRepository(),
+		priceService: prices.Default(),
+		coingecko:    prices.NewCoinGeckoSource(cfg.CoinGeckoURL, cfg.CoinGeckoAPIKey),
+	}
+}
+
+// SyncAll 登记尚未登记的代币，已登记的代币只补全缺失的logo和 coingecko_id。单个代币失败不影响其他代币
+func (s *TokenService) SyncAll(ctx context.Context) error {
+	vaults, err := s.vaultRepo.ListAll()
+	if err != nil {
+		return err
+	}
+
+	type key struct {
+		chainID uint
+		address string
+	}
+	seen := make(map[key]bool)
+	var candidates []key
+	add := func(chainID uint, address string) {
+		k := key{chainID, strings.ToLower(address)}
+		if address != "" && !seen[k] {
+			seen[k] = true
+			candidates = append(candidates, k)
+		}
+	}
+	for _, vault := range vaults {
+		add(vault.ChainID, vault.AssetAddress)
+	}
+	for _, token := range s.priceService.Tokens() {
+		add(token.ChainID, token.Address)
+	}
+
+	var errs []error
+	registered := 0
+	for _, k := range candidates {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		changed, err := s.register(ctx, k.chainID, k.address)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("token %d:%s: %w", k.chainID, k.address, err))
+			continue
+		}
+		if changed {
+			registered++
+		}
+	}
+	if registered > 0 || len(errs) > 0 {
+		logger.Info(fmt.Sprintf("Registered or updated %d token(s), %d failed", registered, len(errs)))
+	}
+	return errors.Join(errs...)
+}
+
+// register 登记单个代币，返回是否写入了记录
+func (s *TokenService) register(ctx context.Context, chainID uint, address string) (bool, error) {
+	token, err := s.repo.Get(chainID, address)
+	if err != nil {
+		return false, err
+	}
+	configured, _ := s.priceService.Token(chainID, address)
+
+	if token == nil {
+		info, err := blockchain.TokenMetadata(ctx, chainID, address)
+		if err != nil {
+			return false, err
+		}
+		token = &models.Token{
+			ChainID:  chainID,
+			Address:  address,
+			Symbol:   info.Symbol,
+			Name:     info.Name,
+			Decimals: info.Decimals,
+		}
+		// 链上符号不可读时使用价格配置中的符号
+		if token.Symbol == "" {
+			token.Symbol = configured.Symbol
+		}
+	} else if token.CoinGeckoID != configured.CoinGeckoID {
+		// coingecko_id 改变后按新的币种重新读取logo
+		token.LogoURL = ""
+	} else if token.LogoURL != "" || token.CoinGeckoID == "" {
+		return false, nil
+	}
+
+	token.CoinGeckoID = configured.CoinGeckoID
+	if token.CoinGeckoID != "" && token.LogoURL == "" {
+		// logo只是展示信息，读取失败时下个周期再试
+		logo, err := s.coingecko.Logo(ctx, token.CoinGeckoID)
+		if err != nil {
+			logger.Info(fmt.Sprintf("Failed to fetch logo of %s from CoinGecko: %v", token.CoinGeckoID, err))
+		}
+		token.LogoURL = logo
+	}
+	if err := s.repo.Upsert(token); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Get 获取登记的代币，未登记时返回nil
+func (s *TokenService) Get(chainID uint, address string) (*models.Token, error) {
+	return s.repo.Get(chainID, address)
+}
+
+// List 按链和符号筛选登记的代币
+func (s *TokenService) List(chainID uint, symbol string) ([]models.Token, error) {
+	return s.repo.List(chainID, symbol)
+}
+
+// Decimals 代币精度，优先使用登记表，未登记时读取链上 decimals()
+func (s *TokenService) Decimals(ctx context.Context, chainID uint, address string) (int32, error) {
+	token, err := s.repo.Get(chainID, address)
+	if err != nil {
+		return 0, err
+	}
+	if token != nil {
+		return token.Decimals, nil
+	}
+	return blockchain.TokenDecimals(ctx, chainID, address)
+}
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/prices"
+	"github.com/chspring1/mya-platform/backend/pkg/uniswapv3"
+
+	"github.com/shopspring/decimal"
+)
+
+// 集中流动性头寸的区间状态
+const (
+	RangeHealthy    = "healthy"
+	RangeNearEdge   = "near_edge"
+	RangeOutOfRange = "out_of_range"
+)
+
+var (
+	ErrInvalidUniswapV3Position = errors.New("token_id must be a positive integer and the position must belong to the pool")
+	ErrUniswapV3Unreadable      = errors.New("uniswap v3 position could not be read on chain")
+)
+
+// ConcentratedLiquidity 集中流动性策略的区间和手续费情况，价格均为以token1计价的1个token0。
+// DistanceToLower/DistanceToUpper 为当前价格距区间下沿/上沿的相对距离，超出区间时为负数。
+// FeeAPR 按 uniswap_v3.apr_window 内赚取的手续费相对头寸平均价值年化，快照不足或价格不可用时为空
+type ConcentratedLiquidity struct {
+	Position        *models.UniswapV3Position `json:"position"`
+	Price           float64                   `json:"price"`
+	PriceLower      float64                   `json:"price_lower"`
+	PriceUpper      float64                   `json:"price_upper"`
+	InRange         bool                      `json:"in_range"`
+	RangeHealth     string                    `json:"range_health"`
+	DistanceToLower float64                   `json:"distance_to_lower"`
+	DistanceToUpper float64                   `json:"distance_to_upper"`
+	Amount0         decimal.Decimal           `json:"amount0"`
+	Amount1         decimal.Decimal           `json:"amount1"`
+	ValueUSD        *float64                  `json:"value_usd"`
+	FeesEarned0     decimal.Decimal           `json:"fees_earned0"` // 登记以来累计，含已领取的
+	FeesEarned1     decimal.Decimal           `json:"fees_earned1"`
+	FeeAPR          *float64                  `json:"fee_apr"`
+	FeeAPRWindow    float64                   `json:"fee_apr_window_days"` // 实际参与计算的天数
+	BlockNumber     uint64                    `json:"block_number"`
+	UpdatedAt       time.Time                 `json:"updated_at"`
+}
+
+// UniswapV3Service 跟踪Uniswap v3集中流动性策略的头寸：定期读取区间、流动性和区间内手续费增长，
+// 累计赚取的手续费并计算手续费APR和区间状态。详情接口只读快照，不请求链上
+type UniswapV3Service struct {
+	vaultRepo    repository.VaultRepo
+	strategyRepo repository.StrategyRepo
+	repo         *repository.UniswapV3Repository
+	auditRepo    *repository.AuditRepository
+	prices       *prices.Service
+}
+
+func NewUniswapV3Service() *UniswapV3Service {
+	return NewUniswapV3ServiceWith(repository.Default())
+}
+
+// NewUniswapV3ServiceWith 使用注入的核心仓储构建
+func NewUniswapV3ServiceWith(repos *repository.Repositories) *UniswapV3Service {
+	return &UniswapV3Service{
+		vaultRepo:    repos.Vaults,
+		strategyRepo: repos.Strategies,
+		repo:         repository.NewUniswapV3Repository(),
+		auditRepo:    repository.NewAuditRepository(),
+		prices:       prices.Default(),
+	}
+}
+
+// SetPosition 登记策略持有的头寸，校验头寸属于该池后写入第一条快照。
+// 策略换到新的区间(新的NFT)后需要重新登记，累计手续费从零开始
+func (s *UniswapV3Service) SetPosition(ctx context.Context, strategy *models.Strategy, actor, manager, tokenID, pool string) (*ConcentratedLiquidity, error) {
+	id, ok := new(big.Int).SetString(tokenID, 10)
+	if !ok || id.Sign() <= 0 {
+		return nil, ErrInvalidUniswapV3Position
+	}
+	chainID, err := s.chainOf(strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	pos, err := uniswapv3.Read(ctx, chainID, manager, id, pool)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUniswapV3Unreadable, err)
+	}
+	if err := uniswapv3.Verify(ctx, chainID, pool, pos); err != nil {
+		if errors.Is(err, uniswapv3.ErrPoolMismatch) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidUniswapV3Position, err)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrUniswapV3Unreadable, err)
+	}
+
+	position := &models.UniswapV3Position{
+		StrategyAddress: strategy.Address,
+		PositionManager: manager,
+		TokenID:         id.String(),
+		Pool:            pool,
+		Token0:          strings.ToLower(pos.Token0.Hex()),
+		Token1:          strings.ToLower(pos.Token1.Hex()),
+		Fee:             pos.Fee,
+		TickLower:       pos.TickLower,
+		TickUpper:       pos.TickUpper,
+	}
+	if position.Decimals0, err = blockchain.TokenDecimals(ctx, chainID, position.Token0); err != nil {
+		return nil, err
+	}
+	if position.Decimals1, err = blockchain.TokenDecimals(ctx, chainID, position.Token1); err != nil {
+		return nil, err
+	}
+
+	snapshot := s.snapshot(ctx, chainID, position, pos, nil)
+	if err := s.repo.SetPosition(position, snapshot); err != nil {
+		return nil, err
+	}
+
+	details, _ := json.Marshal(position)
+	if err := s.auditRepo.Create(&models.AuditLog{
+		Actor:   actor,
+		Action:  models.AuditSetUniswapV3,
+		Target:  strategy.Address,
+		Details: details,
+	}); err != nil {
+		return nil, err
+	}
+	logger.Info(fmt.Sprintf("Strategy %s Uniswap v3 position set to #%s in pool %s by %s", strategy.Address, position.TokenID, pool, actor))
+	InvalidateVault(ctx, strategy.VaultAddress)
+	return s.status(position, snapshot, snapshot, config.Load().UniswapV3), nil
+}
+
+// SnapshotAll 为全部登记的头寸记录一次快照。单个头寸失败不影响其他头寸
+func (s *UniswapV3Service) SnapshotAll(ctx context.Context) error {
+	positions, err := s.repo.ListPositions()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := range positions {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := s.record(ctx, &positions[i]); err != nil {
+			errs = append(errs, fmt.Errorf("strategy %s: %w", positions[i].StrategyAddress, err))
+		}
+	}
+	if len(positions) > 0 {
+		logger.Info(fmt.Sprintf("Recorded Uniswap v3 snapshots of %d position(s), %d failed", len(positions), len(errs)))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *UniswapV3Service) record(ctx context.Context, position *models.UniswapV3Position) error {
+	strategy, err := s.strategyRepo.GetByAddress(position.StrategyAddress)
+	if err != nil {
+		return err
+	}
+	if strategy == nil {
+		return fmt.Errorf("strategy %s not found", position.StrategyAddress)
+	}
+	chainID, err := s.chainOf(strategy)
+	if err != nil {
+		return err
+	}
+
+	id, _ := new(big.Int).SetString(position.TokenID, 10)
+	pos, err := uniswapv3.Read(ctx, chainID, position.PositionManager, id, position.Pool)
+	if err != nil {
+		return err
+	}
+	prev, err := s.repo.LatestSnapshot(position.StrategyAddress)
+	if err != nil {
+		return err
+	}
+	return s.repo.CreateSnapshot(s.snapshot(ctx, chainID, position, pos, prev))
+}
+
+// snapshot 由链上状态生成快照，手续费在上一次快照的累计值上加上期间的区间内手续费增长乘以上一次的流动性
+func (s *UniswapV3Service) snapshot(ctx context.Context, chainID uint, position *models.UniswapV3Position, pos *uniswapv3.Position, prev *models.UniswapV3Snapshot) *models.UniswapV3Snapshot {
+	amount0, amount1 := uniswapv3.Amounts(pos)
+	snapshot := &models.UniswapV3Snapshot{
+		StrategyAddress:  position.StrategyAddress,
+		BlockNumber:      pos.BlockNumber,
+		Tick:             pos.Tick,
+		InRange:          pos.InRange(),
+		Liquidity:        decimal.NewFromBigInt(pos.Liquidity, 0),
+		Amount0:          decimal.NewFromFloat(amount0).Shift(-position.Decimals0).Round(18),
+		Amount1:          decimal.NewFromFloat(amount1).Shift(-position.Decimals1).Round(18),
+		FeeGrowthInside0: decimal.NewFromBigInt(pos.FeeGrowthInside0X128, 0),
+		FeeGrowthInside1: decimal.NewFromBigInt(pos.FeeGrowthInside1X128, 0),
+		Fees0:            decimal.Zero,
+		Fees1:            decimal.Zero,
+		Timestamp:        time.Now(),
+	}
+	if prev != nil {
+		liquidity := prev.Liquidity.BigInt()
+		earned0 := uniswapv3.FeesEarned(liquidity, prev.FeeGrowthInside0.BigInt(), pos.FeeGrowthInside0X128)
+		earned1 := uniswapv3.FeesEarned(liquidity, prev.FeeGrowthInside1.BigInt(), pos.FeeGrowthInside1X128)
+		snapshot.Fees0 = prev.Fees0.Add(decimal.NewFromBigInt(earned0, -position.Decimals0))
+		snapshot.Fees1 = prev.Fees1.Add(decimal.NewFromBigInt(earned1, -position.Decimals1))
+	}
+	if price, err := s.prices.GetPrice(ctx, position.Token0, chainID); err == nil {
+		snapshot.Price0USD = &price.USD
+	}
+	if price, err := s.prices.GetPrice(ctx, position.Token1, chainID); err == nil {
+		snapshot.Price1USD = &price.USD
+	}
+	return snapshot
+}
+
+// Status 集中流动性策略当前的区间状态和手续费APR，未登记头寸的策略返回nil
+func (s *UniswapV3Service) Status(strategyAddress string) (*ConcentratedLiquidity, error) {
+	position, err := s.repo.GetPosition(strategyAddress)
+	if err != nil || position == nil {
+		return nil, err
+	}
+	latest, err := s.repo.LatestSnapshot(strategyAddress)
+	if err != nil || latest == nil {
+		return nil, err
+	}
+	cfg := config.Load().UniswapV3
+	start, err := s.repo.FirstSnapshotSince(strategyAddress, latest.Timestamp.AddDate(0, 0, -cfg.APRWindow))
+	if err != nil {
+		return nil, err
+	}
+	return s.status(position, start, latest, cfg), nil
+}
+
+func (s *UniswapV3Service) status(position *models.UniswapV3Position, start, latest *models.UniswapV3Snapshot, cfg config.UniswapV3Config) *ConcentratedLiquidity {
+	price := uniswapv3.TickPrice(latest.Tick, position.Decimals0, position.Decimals1)
+	result := &ConcentratedLiquidity{
+		Position:    position,
+		Price:       price,
+		PriceLower:  uniswapv3.TickPrice(position.TickLower, position.Decimals0, position.Decimals1),
+		PriceUpper:  uniswapv3.TickPrice(position.TickUpper, position.Decimals0, position.Decimals1),
+		InRange:     latest.InRange,
+		Amount0:     latest.Amount0,
+		Amount1:     latest.Amount1,
+		FeesEarned0: latest.Fees0,
+		FeesEarned1: latest.Fees1,
+		BlockNumber: latest.BlockNumber,
+		UpdatedAt:   latest.Timestamp,
+	}
+	result.DistanceToLower = (price - result.PriceLower) / price
+	result.DistanceToUpper = (result.PriceUpper - price) / price
+	switch {
+	case !latest.InRange:
+		result.RangeHealth = RangeOutOfRange
+	case math.Min(result.DistanceToLower, result.DistanceToUpper) < cfg.EdgeWarning:
+		result.RangeHealth = RangeNearEdge
+	default:
+		result.RangeHealth = RangeHealthy
+	}
+
+	value := positionValue(latest, latest.Amount0, latest.Amount1)
+	result.ValueUSD = value
+	if value == nil || start == nil || start.ID == latest.ID {
+		return result
+	}
+	elapsed := latest.Timestamp.Sub(start.Timestamp)
+	if elapsed < time.Hour {
+		return result
+	}
+	result.FeeAPRWindow = elapsed.Hours() / 24
+
+	// 窗口内赚取的手续费和头寸价值都按最新价格计价，价值取窗口首尾的平均
+	fees := positionValue(latest, latest.Fees0.Sub(start.Fees0), latest.Fees1.Sub(start.Fees1))
+	startValue := positionValue(latest, start.Amount0, start.Amount1)
+	average := *value
+	if startValue != nil {
+		average = (*value + *startValue) / 2
+	}
+	if fees != nil && average > 0 {
+		apr := *fees / average * (365 * 24 * float64(time.Hour) / float64(elapsed))
+		result.FeeAPR = &apr
+	}
+	return result
+}
+
+// positionValue 按快照中的代币价格计算两种代币数量的USD价值，价格缺失时返回nil
+func positionValue(prices *models.UniswapV3Snapshot, amount0, amount1 decimal.Decimal) *float64 {
+	if prices.Price0USD == nil || prices.Price1USD == nil {
+		return nil
+	}
+	value := amount0.InexactFloat64()**prices.Price0USD + amount1.InexactFloat64()**prices.Price1USD
+	return &value
+}
+
+func (s *UniswapV3Service) chainOf(strategy *models.Strategy) (uint, error) {
+	vault, err := s.vaultRepo.GetByAddress(strategy.VaultAddress)
+	if err != nil {
+		return 0, err
+	}
+	if vault == nil {
+		return 0, fmt.Errorf("vault %s of strategy %s not found", strategy.VaultAddress, strategy.Address)
+	}
+	return vault.ChainID, nil
+}
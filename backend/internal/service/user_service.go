@@ -1,38 +1,157 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/chspring1/mya-platform/backend/internal/models"
 	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
 	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/signature"
+
+	"github.com/shopspring/decimal"
 )
 
 type UserService struct {
-	userRepo *repository.UserRepository
+	userRepo     repository.UserRepo
+	txRepo       repository.TxRepo
+	activityRepo *repository.ActivityRepository
 }
 
 func NewUserService() *UserService {
+	return NewUserServiceWith(repository.Default())
+}
+
+// NewUserServiceWith 使用注入的仓储构建，main统一构建依赖时使用
+func NewUserServiceWith(repos *repository.Repositories) *UserService {
 	return &UserService{
-		userRepo: repository.NewUserRepository(),
+		userRepo:     repos.Users,
+		txRepo:       repos.Transactions,
+		activityRepo: repository.NewActivityRepository(),
 	}
 }
 
-// GetUserInfo 获取用户信息
-func (s *UserService) GetUserInfo(address string) (*models.User, error) {
-	user, err := s.userRepo.GetOrCreate(address)
+// GetUserInfo 获取用户信息，并附带反向解析的ENS名称
+func (s *UserService) GetUserInfo(ctx context.Context, address string) (*models.User, error) {
+	user, err := s.userRepo.WithContext(ctx).GetOrCreate(address)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to get user info for %s: %v", address, err))
 		return nil, err
 	}
+
+	// ENS只用于展示，RPC不可用时不影响返回用户信息
+	if user.ENSName, err = blockchain.LookupENS(ctx, user.Address); err != nil {
+		logger.Error(fmt.Sprintf("Failed to reverse resolve ENS for %s: %v", address, err))
+	}
 	return user, nil
 }
 
+// GetUserTransactions 分页获取用户的交易记录
+func (s *UserService) GetUserTransactions(address string, cursor *repository.Cursor, limit int) ([]models.Transaction, *repository.Page, error) {
+	return s.txRepo.GetUserTransactions(address, cursor, limit)
+}
+
+// GetUserActivity 分页获取用户动态：存取款等交易、APY告警触发和持仓资金库的时间线事件
+func (s *UserService) GetUserActivity(address string, cursor *repository.Cursor, limit int) ([]models.ActivityItem, *repository.Page, error) {
+	return s.activityRepo.GetUserActivity(address, cursor, limit)
+}
+
 // UpdateUserTVL 更新用户总TVL
-func (s *UserService) UpdateUserTVL(address string, tvl float64) error {
+func (s *UserService) UpdateUserTVL(address string, tvl decimal.Decimal) error {
 	if err := s.userRepo.UpdateTVL(address, tvl); err != nil {
 		logger.Error(fmt.Sprintf("Failed to update user TVL: %v", err))
 		return err
 	}
 	return nil
 }
+
+var (
+	ErrInvalidNickname  = errors.New("nickname must be 2-32 letters, digits, spaces, '.', '_' or '-'")
+	ErrInvalidAvatarURL = errors.New("avatar_url must be an https URL")
+	ErrNicknameTaken    = errors.New("nickname is already taken")
+	ErrSignatureExpired = errors.New("issued_at is outside the accepted window or not newer than the last signed request")
+)
+
+var nicknamePattern = regexp.MustCompile(`^[\p{L}\p{N}_.\- ]{2,32}$`)
+
+// ProfileUpdate 用户资料修改，需要地址本人对 ProfileMessage 的签名
+type ProfileUpdate struct {
+	Nickname  string
+	AvatarURL string
+	Email     string
+	IssuedAt  int64 // 签名时间(Unix秒)
+	Signature string
+}
+
+// ProfileMessage 修改资料时钱包签名的原文，字段与请求体一一对应，未填写的字段在原文中留空
+func ProfileMessage(address string, p ProfileUpdate) string {
+	return fmt.Sprintf("MYA Platform profile update\nAddress: %s\nNickname: %s\nAvatar: %s\nEmail: %s\nIssued At: %d",
+		strings.ToLower(address), p.Nickname, p.AvatarURL, p.Email, p.IssuedAt)
+}
+
+// UpdateProfile 校验签名后覆盖用户资料。issued_at 必须在 auth.signature_ttl 内且晚于上次修改，
+// 截获的旧签名不能用来回滚资料
+func (s *UserService) UpdateProfile(address string, p ProfileUpdate) (*models.User, error) {
+	p.Nickname = strings.TrimSpace(p.Nickname)
+	p.AvatarURL = strings.TrimSpace(p.AvatarURL)
+	p.Email = strings.ToLower(strings.TrimSpace(p.Email))
+	if p.Nickname != "" && !nicknamePattern.MatchString(p.Nickname) {
+		return nil, ErrInvalidNickname
+	}
+	if p.AvatarURL != "" {
+		if u, err := url.Parse(p.AvatarURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			return nil, ErrInvalidAvatarURL
+		}
+	}
+
+	issuedAt, err := verifySigned(address, ProfileMessage(address, p), p.IssuedAt, p.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetOrCreate(address)
+	if err != nil {
+		return nil, err
+	}
+	if user.ProfileUpdatedAt != nil && !issuedAt.After(*user.ProfileUpdatedAt) {
+		return nil, ErrSignatureExpired
+	}
+	if p.Nickname != "" && !strings.EqualFold(p.Nickname, user.Nickname) {
+		owner, err := s.userRepo.GetByNickname(p.Nickname)
+		if err != nil {
+			return nil, err
+		}
+		if owner != nil && owner.Address != user.Address {
+			return nil, ErrNicknameTaken
+		}
+	}
+
+	if err := s.userRepo.UpdateProfile(user.Address, p.Nickname, p.AvatarURL, p.Email, issuedAt); err != nil {
+		return nil, err
+	}
+	user.Nickname, user.AvatarURL, user.Email = p.Nickname, p.AvatarURL, p.Email
+	user.ProfileUpdatedAt = &issuedAt
+
+	logger.Info(fmt.Sprintf("Profile of %s updated", user.Address))
+	return user, nil
+}
+
+// verifySigned 校验钱包签名请求：issued_at 与服务器时间相差不超过 auth.signature_ttl，且签名由地址本人签出
+func verifySigned(address, message string, issuedAt int64, sig string) (time.Time, error) {
+	at := time.Unix(issuedAt, 0)
+	ttl := time.Duration(config.Load().Auth.SignatureTTL) * time.Second
+	if d := time.Since(at); d > ttl || d < -ttl {
+		return time.Time{}, ErrSignatureExpired
+	}
+	if err := signature.VerifyPersonal(address, message, sig); err != nil {
+		return time.Time{}, err
+	}
+	return at, nil
+}
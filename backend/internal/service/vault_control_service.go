@@ -0,0 +1,327 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/queue"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/notify"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shopspring/decimal"
+)
+
+// pauseSelector 资金库合约 pause() 的函数选择器
+var pauseSelector = crypto.Keccak256([]byte("pause()"))[:4]
+
+// EmergencyStopResult 紧急停止的结果。链下停用总会生效，链上暂停失败时记录在OnChainError
+type EmergencyStopResult struct {
+	Vault        *models.Vault `json:"vault"`
+	TxHash       string        `json:"tx_hash,omitempty"`
+	OnChainError string        `json:"on_chain_error,omitempty"`
+	AuditID      uint          `json:"audit_id"`
+}
+
+type VaultControlService struct {
+	vaultRepo repository.VaultRepo
+	auditRepo *repository.AuditRepository
+	timeline  *repository.VaultEventRepository
+	notifier  *NotificationService
+	alerts    *OperatorAlertService
+}
+
+func NewVaultControlService() *VaultControlService {
+	return NewVaultControlServiceWith(repository.Default())
+}
+
+// NewVaultControlServiceWith 使用注入的核心仓储构建
+func NewVaultControlServiceWith(repos *repository.Repositories) *VaultControlService {
+	return &VaultControlService{
+		vaultRepo: repos.Vaults,
+		auditRepo: repository.NewAuditRepository(),
+		timeline:  repository.NewVaultEventRepository(),
+		notifier:  NewNotificationServiceWith(repos),
+		alerts:    NewOperatorAlertServiceWith(repos),
+	}
+}
+
+var (
+	ErrInvalidVaultMode     = errors.New("invalid vault mode")
+	ErrInvalidVaultCategory = errors.New("invalid vault category")
+	ErrInvalidVaultTags     = errors.New("tags must be at most 10 entries of 1-32 lowercase letters, digits or '-'")
+)
+
+const maxVaultTags = 10
+
+// EmergencyStop 冻结资金库并拒绝新的存取款意向；onChain为true时用运维账户提交合约 pause()。
+// 操作写入审计日志，并通知订阅了 vault_paused 的用户和运维人员
+func (s *VaultControlService) EmergencyStop(ctx context.Context, vault *models.Vault, actor, reason string, onChain bool) (*EmergencyStopResult, error) {
+	wasActive := vault.IsActive
+	if _, err := s.vaultRepo.SetMode(vault.Address, models.VaultModeFrozen); err != nil {
+		return nil, err
+	}
+	vault.Mode = models.VaultModeFrozen
+	vault.IsActive = false
+	InvalidateVault(ctx, vault.Address)
+
+	result := &EmergencyStopResult{Vault: vault}
+	if onChain {
+		txHash, fallback, err := blockchain.SendTransaction(ctx, vault.ChainID, vault.Address, pauseSelector)
+		if fallback != nil {
+			scheduleRelayFallback(fallback)
+		}
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to submit pause() for vault %s: %v", vault.Address, err))
+			result.OnChainError = err.Error()
+			if errors.Is(err, blockchain.ErrNoSigner) {
+				result.OnChainError = "operator signer is not configured, vault was stopped off-chain only"
+			}
+		}
+		result.TxHash = txHash
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"reason":         reason,
+		"was_active":     wasActive,
+		"on_chain":       onChain,
+		"tx_hash":        result.TxHash,
+		"on_chain_error": result.OnChainError,
+	})
+	entry := &models.AuditLog{
+		Actor:   actor,
+		Action:  models.AuditEmergencyStop,
+		Target:  vault.Address,
+		Details: details,
+	}
+	if err := s.auditRepo.Create(entry); err != nil {
+		return nil, err
+	}
+	result.AuditID = entry.ID
+	if err := s.timeline.Record(&models.VaultEvent{
+		VaultAddress: vault.Address,
+		Type:         models.VaultEventEmergencyStop,
+		Actor:        actor,
+		Details:      details,
+	}); err != nil {
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("🛑 Vault %s emergency stopped by %s: %s", vault.Address, actor, reason))
+
+	body := fmt.Sprintf("%s (%s) has been paused by the operators. Deposits and withdrawals are disabled.", vault.Name, vault.Address)
+	if reason != "" {
+		body += "\n\nReason: " + reason
+	}
+	s.notifier.NotifyVault(ctx, models.NotifyVaultPaused, vault.Address, notify.Message{
+		Subject: vault.Name + " has been paused",
+		Body:    body,
+	})
+	s.alerts.NotifyOperators(ctx, notify.Message{
+		Subject: fmt.Sprintf("Emergency stop: %s", vault.Name),
+		Body: fmt.Sprintf("%s (%s) was emergency stopped by %s.\nReason: %s\nOn-chain pause: %v, tx: %s %s",
+			vault.Name, vault.Address, actor, reason, onChain, result.TxHash, result.OnChainError),
+	})
+	return result, nil
+}
+
+// scheduleRelayFallback 把私有通道交易的公共内存池兜底放入持久化队列，进程重启也会执行。入队失败只记录日志
+func scheduleRelayFallback(fallback *blockchain.RelayFallback) {
+	if _, _, err := queue.Enqueue(QueueRelayFallback, fallback, queue.Options{
+		RunAt:     fallback.At,
+		UniqueKey: "relay:" + fallback.TxHash,
+	}); err != nil {
+		logger.Error(fmt.Sprintf("Failed to schedule public fallback for %s: %v", fallback.TxHash, err))
+	}
+}
+
+// RunQueuedRelayFallback 执行队列中的私有通道兜底，交易已上链或nonce已被占用时直接结束
+func RunQueuedRelayFallback(ctx context.Context, payload json.RawMessage) error {
+	var fallback blockchain.RelayFallback
+	if err := json.Unmarshal(payload, &fallback); err != nil {
+		return queue.Permanent(err)
+	}
+	return blockchain.PublicFallback(ctx, fallback)
+}
+
+// SetMode 切换资金库运行模式，用于比紧急停止更细的控制：只暂停存款、只暂停取款、完全冻结或恢复。
+// 模式未变化时不做任何操作；暂停类切换会通知订阅了 vault_paused 的用户
+func (s *VaultControlService) SetMode(ctx context.Context, vault *models.Vault, actor, mode, reason string) (*models.Vault, error) {
+	if !models.ValidVaultMode(mode) {
+		return nil, ErrInvalidVaultMode
+	}
+	previous := vault.Mode
+	if previous == "" {
+		previous = models.VaultModeActive
+	}
+	if previous == mode && vault.IsActive == (mode != models.VaultModeFrozen) {
+		return vault, nil
+	}
+
+	if _, err := s.vaultRepo.SetMode(vault.Address, mode); err != nil {
+		return nil, err
+	}
+	vault.Mode = mode
+	vault.IsActive = mode != models.VaultModeFrozen
+	InvalidateVault(ctx, vault.Address)
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"reason":   reason,
+		"previous": previous,
+		"mode":     mode,
+	})
+	if err := s.auditRepo.Create(&models.AuditLog{
+		Actor:   actor,
+		Action:  models.AuditSetVaultMode,
+		Target:  vault.Address,
+		Details: details,
+	}); err != nil {
+		return nil, err
+	}
+	eventType := models.VaultEventPause
+	if mode == models.VaultModeActive {
+		eventType = models.VaultEventUnpause
+	}
+	if err := s.timeline.Record(&models.VaultEvent{
+		VaultAddress: vault.Address,
+		Type:         eventType,
+		Actor:        actor,
+		Details:      details,
+	}); err != nil {
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("Vault %s mode changed %s -> %s by %s: %s", vault.Address, previous, mode, actor, reason))
+
+	if mode != models.VaultModeActive {
+		body := fmt.Sprintf("%s (%s) is now %s.", vault.Name, vault.Address, describeVaultMode(mode))
+		if reason != "" {
+			body += "\n\nReason: " + reason
+		}
+		s.notifier.NotifyVault(ctx, models.NotifyVaultPaused, vault.Address, notify.Message{
+			Subject: vault.Name + " has been paused",
+			Body:    body,
+		})
+	}
+	return vault, nil
+}
+
+func describeVaultMode(mode string) string {
+	switch mode {
+	case models.VaultModeWithdrawOnly:
+		return "withdraw-only: new deposits are paused, withdrawals remain available"
+	case models.VaultModeDepositOnly:
+		return "deposit-only: withdrawals are paused, deposits remain available"
+	case models.VaultModeFrozen:
+		return "frozen: deposits and withdrawals are paused"
+	}
+	return "active"
+}
+
+// GetAuditLog 获取审计日志，vaultAddress为空时返回所有对象
+func (s *VaultControlService) GetAuditLog(vaultAddress string, limit int) ([]models.AuditLog, error) {
+	return s.auditRepo.List(vaultAddress, limit)
+}
+
+// SetDepositCap 修改资金库存款上限并写入审计日志，cap为nil表示取消上限。
+// 上限低于当前TVL时不影响已有资金，只是不再接受新的存款
+func (s *VaultControlService) SetDepositCap(ctx context.Context, vault *models.Vault, actor string, cap *decimal.Decimal, reason string) (*models.Vault, error) {
+	if _, err := s.vaultRepo.SetDepositCap(vault.Address, cap); err != nil {
+		return nil, err
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"reason":      reason,
+		"previous":    vault.DepositCap,
+		"deposit_cap": cap,
+	})
+	if err := s.auditRepo.Create(&models.AuditLog{
+		Actor:   actor,
+		Action:  models.AuditSetDepositCap,
+		Target:  vault.Address,
+		Details: details,
+	}); err != nil {
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("Vault %s deposit cap changed to %v by %s: %s", vault.Address, cap, actor, reason))
+	vault.DepositCap = cap
+	InvalidateVault(ctx, vault.Address)
+	return vault, nil
+}
+
+// SetCategory 修改资金库分类和标签并写入审计日志，category为空表示取消分类。
+// 标签统一转为小写、去重并排序，便于列表筛选时精确匹配
+func (s *VaultControlService) SetCategory(ctx context.Context, vault *models.Vault, actor, category string, tags []string) (*models.Vault, error) {
+	if category != "" && !models.ValidVaultCategory(category) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidVaultCategory, category)
+	}
+	tags, err := NormalizeVaultTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.vaultRepo.SetCategory(vault.Address, category, tags); err != nil {
+		return nil, err
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"previous_category": vault.Category,
+		"previous_tags":     vault.Tags,
+		"category":          category,
+		"tags":              tags,
+	})
+	if err := s.auditRepo.Create(&models.AuditLog{
+		Actor:   actor,
+		Action:  models.AuditSetCategory,
+		Target:  vault.Address,
+		Details: details,
+	}); err != nil {
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("Vault %s category changed to %q %v by %s", vault.Address, category, tags, actor))
+	vault.Category = category
+	vault.Tags = tags
+	InvalidateVault(ctx, vault.Address)
+	return vault, nil
+}
+
+// NormalizeVaultTags 把标签转为小写并去重排序，也用于解析列表接口的 ?tags= 参数
+func NormalizeVaultTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !validVaultTag(tag) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidVaultTags, tag)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	if len(normalized) > maxVaultTags {
+		return nil, ErrInvalidVaultTags
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+func validVaultTag(tag string) bool {
+	if tag == "" || len(tag) > 32 {
+		return false
+	}
+	for _, r := range tag {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return false
+		}
+	}
+	return true
+}
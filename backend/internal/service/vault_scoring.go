@@ -0,0 +1,57 @@
+package service
+
+import (
+	"github.com/chspring1/mya-platform/backend/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// vaultRiskScores 各资金库的风险分：策略风险分叠加所在市场的风险因素(与再平衡引擎相同)，再按策略资产加权
+func vaultRiskScores(morpho *MorphoService, vaults []*models.Vault) (map[string]float64, error) {
+	var strategies []string
+	for _, vault := range vaults {
+		for _, st := range vault.Strategies {
+			strategies = append(strategies, st.Address)
+		}
+	}
+	adjustments, err := morpho.RiskAdjustments(strategies)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make(map[string]float64, len(vaults))
+	for _, vault := range vaults {
+		scores[vault.Address] = vaultRiskScore(vault.Strategies, adjustments)
+	}
+	return scores, nil
+}
+
+// vaultRiskScore 按活跃策略的资产加权的风险分，策略都还没有资产时取简单平均，没有活跃策略时按1计
+func vaultRiskScore(strategies []models.Strategy, adjustments map[string]uint8) float64 {
+	var weighted, total decimal.Decimal
+	var sum float64
+	count := 0
+	for _, st := range strategies {
+		if !st.IsActive {
+			continue
+		}
+		risk := int64(st.RiskScore) + int64(adjustments[st.Address])
+		weighted = weighted.Add(st.TotalAssets.Mul(decimal.NewFromInt(risk)))
+		total = total.Add(st.TotalAssets)
+		sum += float64(risk)
+		count++
+	}
+	switch {
+	case total.IsPositive():
+		return weighted.Div(total).Round(2).InexactFloat64()
+	case count > 0:
+		return sum / float64(count)
+	}
+	return 1
+}
+
+// vaultNetAPY 扣除业绩费(按收益比例)和管理费后的年化收益，feeDrag 为费用折算的年化损失
+func vaultNetAPY(vault *models.Vault) (net, feeDrag float64) {
+	feeDrag = vault.APYCurrent*float64(vault.PerformanceFeeBps)/10000 + float64(vault.ManagementFeeBps)/10000
+	return vault.APYCurrent - feeDrag, feeDrag
+}
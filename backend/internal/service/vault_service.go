@@ -1,36 +1,180 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/chspring1/mya-platform/backend/internal/models"
 	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/cache"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
 	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/prices"
+	"github.com/chspring1/mya-platform/backend/pkg/rates"
+
+	"github.com/shopspring/decimal"
+)
+
+// StrategyAllocation 资金库在单个策略上的目标与实际分配
+type StrategyAllocation struct {
+	StrategyAddress string          `json:"strategy_address"`
+	Name            string          `json:"name"`
+	Protocol        string          `json:"protocol"`
+	TotalAssets     decimal.Decimal `json:"total_assets"`
+	TargetBps       uint16          `json:"target_bps"`
+	ActualBps       uint16          `json:"actual_bps"`
+	DriftBps        int             `json:"drift_bps"`
+}
+
+var ErrInvalidAllocation = errors.New("target allocations must reference the vault's strategies and sum to at most 10000 bps")
+
+// VaultView 带实时USD估值的资金库，同时给出由APY换算的APR
+type VaultView struct {
+	models.Vault
+	APRCurrent    float64            `json:"apr_current"`
+	APRWeekly     float64            `json:"apr_weekly"`
+	AssetPriceUSD *float64           `json:"asset_price_usd"`
+	TVLUSD        *float64           `json:"tvl_usd"`
+	Fiat          map[string]float64 `json:"fiat,omitempty"` // 按?currency=换算后的金额
+
+	RemainingCapacity *decimal.Decimal `json:"remaining_capacity"` // 未设置存款上限时为null
+	AtCapacity        bool             `json:"at_capacity"`        // 已达存款上限，前端据此置灰存款入口
+
+	RateType string     `json:"rate_type"`          // 见 RateType*
+	Maturity *time.Time `json:"maturity,omitempty"` // 固定利率策略中最早的到期时间
+
+	AssetToken *models.Token `json:"asset_token"` // 底层资产的登记信息，尚未登记时为null
+}
+
+// 资金库收益率类型，由活跃策略是否有到期时间(如持有Pendle PT)决定
+const (
+	RateTypeVariable = "variable"
+	RateTypeFixed    = "fixed"
+	RateTypeMixed    = "mixed" // 同时有固定和浮动利率策略
+)
+
+// ValidRateType 是否为支持的收益率类型
+func ValidRateType(kind string) bool {
+	return kind == RateTypeVariable || kind == RateTypeFixed || kind == RateTypeMixed
+}
+
+// VaultAPY 资金库当前及历史平均收益率，同时给出APY和换算后的APR；
+// 按 ?rate= 只返回一种口径时另一组字段为nil，不出现在响应中
+type VaultAPY struct {
+	VaultAddress string `json:"vault"`
+	Name         string `json:"name"`
+	*APYFigures
+	*APRFigures
+	Forecast *ForecastSummary `json:"apy_forecast_7d,omitempty"` // 第7天的统计预测，仅为估计，以APY口径表示
+}
+
+type APYFigures struct {
+	APYCurrent float64  `json:"apy_current"`
+	APY7d      *float64 `json:"apy_7d"`
+	APY30d     *float64 `json:"apy_30d"`
+	APY90d     *float64 `json:"apy_90d"`
+}
+
+type APRFigures struct {
+	APRCurrent float64  `json:"apr_current"`
+	APR7d      *float64 `json:"apr_7d"`
+	APR30d     *float64 `json:"apr_30d"`
+	APR90d     *float64 `json:"apr_90d"`
+}
+
+// Only 只保留指定口径的字段，kind为空时两种都保留
+func (v VaultAPY) Only(kind string) VaultAPY {
+	switch kind {
+	case rates.APY:
+		v.APRFigures = nil
+	case rates.APR:
+		v.APYFigures = nil
+	}
+	return v
+}
+
+func toAPR(apy *float64) *float64 {
+	if apy == nil {
+		return nil
+	}
+	apr := rates.ToAPR(*apy)
+	return &apr
+}
+
+// 响应缓存键，资金库数据变更时通过invalidateVault失效
+const (
+	vaultListCacheKey = "vaults:all"
+	apyDataCacheKey   = "vaults:apy"
 )
 
+func vaultCacheKey(address string) string {
+	return "vaults:" + strings.ToLower(address)
+}
+
 type VaultService struct {
-	vaultRepo *repository.VaultRepository
+	vaultRepo    repository.VaultRepo
+	strategyRepo repository.StrategyRepo
+	apyRepo      *repository.APYHistoryRepository
+	txRepo       repository.TxRepo
+	shareRepo    *repository.SharePriceRepository
+	timeline     *repository.VaultEventRepository
+	tokenRepo    *repository.TokenRepository
+	priceService *prices.Service
+	forecasts    *ForecastService
+	vaultTTL     time.Duration
+	apyTTL       time.Duration
 }
 
 func NewVaultService() *VaultService {
+	return NewVaultServiceWith(repository.Default())
+}
+
+// NewVaultServiceWith 使用注入的仓储构建，main统一构建依赖时使用
+func NewVaultServiceWith(repos *repository.Repositories) *VaultService {
+	cfg := config.Load().Cache
 	return &VaultService{
-		vaultRepo: repository.NewVaultRepository(),
+		vaultRepo:    repos.Vaults,
+		strategyRepo: repos.Strategies,
+		apyRepo:      repository.NewAPYHistoryRepository(),
+		txRepo:       repos.Transactions,
+		shareRepo:    repository.NewSharePriceRepository(),
+		timeline:     repository.NewVaultEventRepository(),
+		tokenRepo:    repository.NewTokenRepository(),
+		priceService: prices.Default(),
+		forecasts:    NewForecastServiceWith(repos),
+		vaultTTL:     time.Duration(cfg.VaultTTL) * time.Second,
+		apyTTL:       time.Duration(cfg.APYTTL) * time.Second,
 	}
 }
 
-// GetVaults 获取所有资金库
-func (s *VaultService) GetVaults() ([]models.Vault, error) {
-	vaults, err := s.vaultRepo.ListAll()
+// GetVaults 获取所有资金库，优先读取缓存
+func (s *VaultService) GetVaults(ctx context.Context) ([]models.Vault, error) {
+	var vaults []models.Vault
+	if cache.GetJSON(ctx, vaultListCacheKey, &vaults) {
+		return vaults, nil
+	}
+
+	vaults, err := s.vaultRepo.WithContext(ctx).ListAll()
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to get vaults: %v", err))
 		return nil, err
 	}
+
+	cache.SetJSON(ctx, vaultListCacheKey, vaults, s.vaultTTL)
 	return vaults, nil
 }
 
-// GetVaultDetail 获取资金库详情
-func (s *VaultService) GetVaultDetail(address string) (*models.Vault, error) {
-	vault, err := s.vaultRepo.GetByAddress(address)
+// GetVaultDetail 获取资金库详情，优先读取缓存
+func (s *VaultService) GetVaultDetail(ctx context.Context, address string) (*models.Vault, error) {
+	var cached models.Vault
+	if cache.GetJSON(ctx, vaultCacheKey(address), &cached) {
+		return &cached, nil
+	}
+
+	vault, err := s.vaultRepo.WithContext(ctx).GetByAddress(address)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to get vault detail for %s: %v", address, err))
 		return nil, err
@@ -40,9 +184,186 @@ func (s *VaultService) GetVaultDetail(address string) (*models.Vault, error) {
 		return nil, nil
 	}
 
+	cache.SetJSON(ctx, vaultCacheKey(address), vault, s.vaultTTL)
 	return vault, nil
 }
 
+// GetVaultTransactions 分页获取资金库的交易记录
+func (s *VaultService) GetVaultTransactions(address string, cursor *repository.Cursor, limit int) ([]models.Transaction, *repository.Page, error) {
+	return s.txRepo.GetVaultTransactions(address, cursor, limit)
+}
+
+// GetAPYHistory 分页获取资金库的原始APY记录，rate 字段按kind口径换算；超过保留期的数据只有按天汇总
+func (s *VaultService) GetAPYHistory(address string, kind string, cursor *repository.Cursor, limit int) ([]models.APYHistory, *repository.Page, error) {
+	history, page, err := s.apyRepo.GetVaultHistory(address, cursor, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range history {
+		history[i].Rate = rates.FromAPY(history[i].APYValue, kind)
+	}
+	return history, page, nil
+}
+
+// GetVaultEvents 分页获取资金库的运行时间线，types为空时返回所有类型
+func (s *VaultService) GetVaultEvents(address string, types []string, cursor *repository.Cursor, limit int) ([]models.VaultEvent, *repository.Page, error) {
+	return s.timeline.GetVaultEvents(address, types, cursor, limit)
+}
+
+// GetSharePrice 获取资金库的份额价格，at为nil时返回最新值，否则返回at时刻或之前最近的一次记录
+func (s *VaultService) GetSharePrice(address string, at *time.Time) (*models.SharePrice, error) {
+	if at == nil {
+		return s.shareRepo.GetLatest(address)
+	}
+	return s.shareRepo.GetAt(address, *at)
+}
+
+// GetSharePriceHistory 分页获取资金库的份额价格历史
+func (s *VaultService) GetSharePriceHistory(address string, cursor *repository.Cursor, limit int) ([]models.SharePrice, *repository.Page, error) {
+	return s.shareRepo.GetVaultHistory(address, cursor, limit)
+}
+
+// GetAPYData 获取活跃资金库的当前APY、7/30/90天平均APY及7天预测，优先读取缓存
+func (s *VaultService) GetAPYData(ctx context.Context) ([]VaultAPY, error) {
+	var data []VaultAPY
+	if cache.GetJSON(ctx, apyDataCacheKey, &data) {
+		return data, nil
+	}
+
+	vaults, err := s.vaultRepo.WithContext(ctx).GetActiveVaults()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	averages, err := s.apyRepo.GetTrailingAverages(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		return nil, err
+	}
+	byVault := make(map[string]repository.TrailingAPY, len(averages))
+	for _, a := range averages {
+		byVault[a.VaultAddress] = a
+	}
+	forecasts, err := s.forecasts.Summaries()
+	if err != nil {
+		return nil, err
+	}
+
+	data = make([]VaultAPY, 0, len(vaults))
+	for _, vault := range vaults {
+		avg := byVault[vault.Address]
+		data = append(data, VaultAPY{
+			VaultAddress: vault.Address,
+			Name:         vault.Name,
+			APYFigures: &APYFigures{
+				APYCurrent: vault.APYCurrent,
+				APY7d:      avg.APY7d,
+				APY30d:     avg.APY30d,
+				APY90d:     avg.APY90d,
+			},
+			APRFigures: &APRFigures{
+				APRCurrent: rates.ToAPR(vault.APYCurrent),
+				APR7d:      toAPR(avg.APY7d),
+				APR30d:     toAPR(avg.APY30d),
+				APR90d:     toAPR(avg.APY90d),
+			},
+			Forecast: forecasts[vault.Address],
+		})
+	}
+
+	cache.SetJSON(ctx, apyDataCacheKey, data, s.apyTTL)
+	return data, nil
+}
+
+// InvalidateVault 资金库或其策略数据变更后清除相关缓存
+func InvalidateVault(ctx context.Context, address string) {
+	cache.Delete(ctx, vaultListCacheKey, apyDataCacheKey, statsCacheKey, vaultCacheKey(address))
+}
+
+// InvalidateAPYData APY历史汇总更新后清除APY数据缓存
+func InvalidateAPYData(ctx context.Context) {
+	cache.Delete(ctx, apyDataCacheKey)
+}
+
+// WithUSD 按资产当前价格计算资金库的USD TVL，ETH等非稳定币资金库随行情变化
+func (s *VaultService) WithUSD(ctx context.Context, vault *models.Vault) VaultView {
+	// 代币信息只是附加展示，读取失败时返回null
+	token, _ := s.tokenRepo.Get(vault.ChainID, vault.AssetAddress)
+	return s.withUSD(ctx, vault, token)
+}
+
+func (s *VaultService) withUSD(ctx context.Context, vault *models.Vault, token *models.Token) VaultView {
+	view := VaultView{
+		Vault:      *vault,
+		APRCurrent: rates.ToAPR(vault.APYCurrent),
+		APRWeekly:  rates.ToAPR(vault.APYWeekly),
+		AssetToken: token,
+	}
+	// 复制策略切片，避免改写调用方持有的资金库
+	view.Strategies = make([]models.Strategy, len(vault.Strategies))
+	for i, st := range vault.Strategies {
+		st.APR = rates.ToAPR(st.APY)
+		view.Strategies[i] = st
+	}
+	if remaining := vault.RemainingCapacity(); remaining != nil {
+		view.RemainingCapacity = remaining
+		view.AtCapacity = !remaining.IsPositive()
+	}
+	view.RateType, view.Maturity = rateType(vault.Strategies)
+
+	price, err := s.priceService.GetPrice(ctx, vault.AssetAddress, vault.ChainID)
+	if err != nil {
+		return view
+	}
+	tvlUSD := vault.TVL.Mul(decimal.NewFromFloat(price.USD)).InexactFloat64()
+	view.AssetPriceUSD = &price.USD
+	view.TVLUSD = &tvlUSD
+	return view
+}
+
+// rateType 按活跃策略判断资金库的收益率类型，返回固定利率策略中最早的到期时间
+func rateType(strategies []models.Strategy) (string, *time.Time) {
+	var fixed, variable int
+	var maturity *time.Time
+	for _, st := range strategies {
+		if !st.IsActive {
+			continue
+		}
+		if st.Maturity == nil {
+			variable++
+			continue
+		}
+		fixed++
+		if maturity == nil || st.Maturity.Before(*maturity) {
+			maturity = st.Maturity
+		}
+	}
+	switch {
+	case fixed == 0:
+		return RateTypeVariable, nil
+	case variable == 0:
+		return RateTypeFixed, maturity
+	}
+	return RateTypeMixed, maturity
+}
+
+// WithUSDList 批量计算资金库USD估值
+func (s *VaultService) WithUSDList(ctx context.Context, vaults []models.Vault) []VaultView {
+	// 一次读取全部登记的代币，避免逐个资金库查询
+	tokens, _ := s.tokenRepo.List(0, "")
+	byKey := make(map[string]*models.Token, len(tokens))
+	for i := range tokens {
+		byKey[fmt.Sprintf("%d:%s", tokens[i].ChainID, tokens[i].Address)] = &tokens[i]
+	}
+
+	views := make([]VaultView, 0, len(vaults))
+	for i := range vaults {
+		token := byKey[fmt.Sprintf("%d:%s", vaults[i].ChainID, strings.ToLower(vaults[i].AssetAddress))]
+		views = append(views, s.withUSD(ctx, &vaults[i], token))
+	}
+	return views
+}
+
 // GetActiveVaults 获取活跃的资金库
 func (s *VaultService) GetActiveVaults() ([]models.Vault, error) {
 	vaults, err := s.vaultRepo.GetActiveVaults()
@@ -54,7 +375,7 @@ func (s *VaultService) GetActiveVaults() ([]models.Vault, error) {
 }
 
 // UpdateVaultStats 更新资金库统计信息
-func (s *VaultService) UpdateVaultStats(address string, tvl, apyCurrent, apyWeekly float64) error {
+func (s *VaultService) UpdateVaultStats(address string, tvl decimal.Decimal, apyCurrent, apyWeekly float64) error {
 	if err := s.vaultRepo.UpdateTVL(address, tvl); err != nil {
 		return err
 	}
@@ -63,5 +384,61 @@ func (s *VaultService) UpdateVaultStats(address string, tvl, apyCurrent, apyWeek
 		return err
 	}
 
+	InvalidateVault(context.Background(), address)
+	return nil
+}
+
+// GetAllocations 根据各策略资产计算资金库的实际分配，并与目标分配比较
+func (s *VaultService) GetAllocations(vault *models.Vault) []StrategyAllocation {
+	total := decimal.Zero
+	for _, st := range vault.Strategies {
+		total = total.Add(st.TotalAssets)
+	}
+
+	allocations := make([]StrategyAllocation, 0, len(vault.Strategies))
+	for _, st := range vault.Strategies {
+		var actual uint16
+		if total.IsPositive() {
+			actual = uint16(st.TotalAssets.Div(total).Mul(bpsScale).Round(0).IntPart())
+		}
+		allocations = append(allocations, StrategyAllocation{
+			StrategyAddress: st.Address,
+			Name:            st.Name,
+			Protocol:        st.Protocol,
+			TotalAssets:     st.TotalAssets,
+			TargetBps:       st.TargetBps,
+			ActualBps:       actual,
+			DriftBps:        int(actual) - int(st.TargetBps),
+		})
+	}
+	return allocations
+}
+
+// SetTargetAllocations 设置资金库各策略的目标分配，未列出的策略保持不变
+func (s *VaultService) SetTargetAllocations(vault *models.Vault, targets map[string]uint16) error {
+	owned := make(map[string]uint16, len(vault.Strategies))
+	for _, st := range vault.Strategies {
+		owned[st.Address] = st.TargetBps
+	}
+
+	var sum int
+	for address, bps := range targets {
+		if _, ok := owned[address]; !ok {
+			return ErrInvalidAllocation
+		}
+		owned[address] = bps
+	}
+	for _, bps := range owned {
+		sum += int(bps)
+	}
+	if sum > bpsDenominator {
+		return ErrInvalidAllocation
+	}
+
+	if err := s.strategyRepo.UpdateTargetAllocations(vault.Address, targets); err != nil {
+		return err
+	}
+
+	InvalidateVault(context.Background(), vault.Address)
 	return nil
 }
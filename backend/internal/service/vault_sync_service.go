@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/workerpool"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	totalAssetsSelector          = crypto.Keccak256([]byte("totalAssets()"))[:4]
+	estimatedTotalAssetsSelector = crypto.Keccak256([]byte("estimatedTotalAssets()"))[:4]
+	totalSupplySelector          = crypto.Keccak256([]byte("totalSupply()"))[:4]
+)
+
+// assetRead 一次合约资产读取，资金库读 totalAssets()，策略读 estimatedTotalAssets()
+type assetRead struct {
+	vault    string
+	strategy string // 为空表示读取资金库本身
+	chainID  uint
+	asset    string
+}
+
+type VaultSyncService struct {
+	vaultRepo      repository.VaultRepo
+	strategyRepo   repository.StrategyRepo
+	sharePriceRepo *repository.SharePriceRepository
+}
+
+func NewVaultSyncService() *VaultSyncService {
+	return NewVaultSyncServiceWith(repository.Default())
+}
+
+// NewVaultSyncServiceWith 使用注入的核心仓储构建
+func NewVaultSyncServiceWith(repos *repository.Repositories) *VaultSyncService {
+	return &VaultSyncService{
+		vaultRepo:      repos.Vaults,
+		strategyRepo:   repos.Strategies,
+		sharePriceRepo: repository.NewSharePriceRepository(),
+	}
+}
+
+// SyncAll 并发读取所有活跃资金库及其策略的链上资产，校正数据库中由事件累计的TVL，并记录资金库份额价格。
+// 并发数由 blockchain.read_concurrency 限制，请求速率由RPC限流控制；单个合约读取失败不影响其他合约，
+// 所有失败合并后返回
+func (s *VaultSyncService) SyncAll(ctx context.Context) error {
+	vaults, err := s.vaultRepo.GetActiveVaults()
+	if err != nil {
+		return err
+	}
+
+	var reads []assetRead
+	for _, vault := range vaults {
+		reads = append(reads, assetRead{vault: vault.Address, chainID: vault.ChainID, asset: vault.AssetAddress})
+		for _, st := range vault.Strategies {
+			reads = append(reads, assetRead{vault: vault.Address, strategy: st.Address, chainID: vault.ChainID, asset: vault.AssetAddress})
+		}
+	}
+
+	// 读取并发进行，结果汇总后各用一条语句写回
+	var (
+		mutex          sync.Mutex
+		vaultTVL       = make(map[string]decimal.Decimal)
+		strategyAssets = make(map[string]decimal.Decimal)
+		sharePrices    []models.SharePrice
+		now            = time.Now()
+	)
+	readErr := workerpool.ForEach(ctx, config.Load().Blockchain.ReadConcurrency, reads, func(ctx context.Context, read assetRead) error {
+		amount, err := readAssets(ctx, read)
+		if err != nil {
+			return err
+		}
+		if read.strategy != "" {
+			mutex.Lock()
+			strategyAssets[read.strategy] = amount
+			mutex.Unlock()
+			return nil
+		}
+
+		supply, err := readSupply(ctx, read)
+		if err != nil {
+			return err
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		vaultTVL[read.vault] = amount
+		sharePrices = append(sharePrices, models.SharePrice{
+			VaultAddress:  read.vault,
+			TotalAssets:   amount,
+			TotalSupply:   supply,
+			PricePerShare: pricePerShare(amount, supply),
+			Timestamp:     now,
+		})
+		return nil
+	})
+
+	if err := s.vaultRepo.BulkUpdateTVL(vaultTVL); err != nil {
+		return err
+	}
+	if err := s.strategyRepo.BulkUpdateAssets(strategyAssets); err != nil {
+		return err
+	}
+	if err := s.sharePriceRepo.CreateBatch(sharePrices); err != nil {
+		return err
+	}
+	for _, vault := range vaults {
+		InvalidateVault(ctx, vault.Address)
+	}
+	if readErr != nil {
+		return readErr
+	}
+	logger.Info(fmt.Sprintf("Synced %d vault(s), %d contract read(s)", len(vaults), len(reads)))
+	return nil
+}
+
+// readAssets 读取合约当前资产，按底层资产精度换算
+func readAssets(ctx context.Context, read assetRead) (decimal.Decimal, error) {
+	decimals, err := blockchain.TokenDecimals(ctx, read.chainID, read.asset)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("decimals of %s: %w", read.asset, err)
+	}
+
+	target, selector := read.vault, totalAssetsSelector
+	if read.strategy != "" {
+		target, selector = read.strategy, estimatedTotalAssetsSelector
+	}
+	out, err := blockchain.Call(ctx, read.chainID, target, selector)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("read assets of %s: %w", target, err)
+	}
+	if len(out) < 32 {
+		return decimal.Zero, fmt.Errorf("unexpected assets result from %s", target)
+	}
+	return decimal.NewFromBigInt(new(big.Int).SetBytes(out[:32]), -decimals), nil
+}
+
+// readSupply 读取资金库份额总量，按份额代币精度换算
+func readSupply(ctx context.Context, read assetRead) (decimal.Decimal, error) {
+	decimals, err := blockchain.TokenDecimals(ctx, read.chainID, read.vault)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("decimals of %s: %w", read.vault, err)
+	}
+	out, err := blockchain.Call(ctx, read.chainID, read.vault, totalSupplySelector)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("read supply of %s: %w", read.vault, err)
+	}
+	if len(out) < 32 {
+		return decimal.Zero, fmt.Errorf("unexpected supply result from %s", read.vault)
+	}
+	return decimal.NewFromBigInt(new(big.Int).SetBytes(out[:32]), -decimals), nil
+}
+
+// pricePerShare 每份额对应的底层资产数量，尚无份额时按1计
+func pricePerShare(assets, supply decimal.Decimal) decimal.Decimal {
+	if supply.IsZero() {
+		return decimal.NewFromInt(1)
+	}
+	return assets.DivRound(supply, 18)
+}
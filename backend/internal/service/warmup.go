@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+)
+
+// WarmCache 预热热点接口缓存，避免部署后的首批请求同时回源数据库。
+// 单项失败只记录日志，不阻止服务启动
+func WarmCache(ctx context.Context, repos *repository.Repositories) {
+	start := time.Now()
+	vaultService := NewVaultServiceWith(repos)
+
+	vaults, err := vaultService.GetVaults(ctx)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Cache warm-up: failed to load vaults: %v", err))
+	}
+	for _, vault := range vaults {
+		if ctx.Err() != nil {
+			break
+		}
+		if _, err := vaultService.GetVaultDetail(ctx, vault.Address); err != nil {
+			logger.Error(fmt.Sprintf("Cache warm-up: failed to load vault %s: %v", vault.Address, err))
+		}
+	}
+
+	if _, err := vaultService.GetAPYData(ctx); err != nil {
+		logger.Error(fmt.Sprintf("Cache warm-up: failed to load APY data: %v", err))
+	}
+
+	if _, err := NewStatsServiceWith(repos).GetSystemStats(ctx); err != nil {
+		logger.Error(fmt.Sprintf("Cache warm-up: failed to load system stats: %v", err))
+	}
+
+	logger.Info(fmt.Sprintf("🔥 Cache warmed for %d vault(s) in %v", len(vaults), time.Since(start)))
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/internal/repository"
+)
+
+// 单个用户最多收藏的资金库数
+const maxWatchlistSize = 100
+
+var (
+	ErrWatchlistVaultNotFound = errors.New("vault not found")
+	ErrWatchlistFull          = errors.New("too many vaults in watchlist")
+)
+
+// WatchlistItem 收藏的资金库及其实时APY、TVL
+type WatchlistItem struct {
+	VaultView
+	AddedAt time.Time `json:"added_at"`
+}
+
+type WatchlistService struct {
+	repo         *repository.WatchlistRepository
+	vaultService *VaultService
+}
+
+func NewWatchlistService() *WatchlistService {
+	return NewWatchlistServiceWith(repository.Default())
+}
+
+// NewWatchlistServiceWith 使用注入的核心仓储构建
+func NewWatchlistServiceWith(repos *repository.Repositories) *WatchlistService {
+	return &WatchlistService{
+		repo:         repository.NewWatchlistRepository(),
+		vaultService: NewVaultServiceWith(repos),
+	}
+}
+
+// Add 收藏资金库，重复收藏不报错，返回是否新增
+func (s *WatchlistService) Add(ctx context.Context, userAddress, vaultAddress string) (bool, error) {
+	vault, err := s.vaultService.GetVaultDetail(ctx, vaultAddress)
+	if err != nil {
+		return false, err
+	}
+	if vault == nil {
+		return false, ErrWatchlistVaultNotFound
+	}
+
+	count, err := s.repo.Count(userAddress)
+	if err != nil {
+		return false, err
+	}
+	if count >= maxWatchlistSize {
+		return false, fmt.Errorf("%w: limit is %d", ErrWatchlistFull, maxWatchlistSize)
+	}
+
+	return s.repo.Add(&models.WatchlistEntry{
+		UserAddress:  userAddress,
+		VaultAddress: vault.Address,
+	})
+}
+
+// Remove 取消收藏，未收藏时返回false
+func (s *WatchlistService) Remove(userAddress, vaultAddress string) (bool, error) {
+	return s.repo.Remove(userAddress, vaultAddress)
+}
+
+// List 获取收藏的资金库，APY、TVL取自资金库缓存并按资产当前价格计算USD估值；
+// 已下线的资金库不再返回
+func (s *WatchlistService) List(ctx context.Context, userAddress string) ([]WatchlistItem, error) {
+	entries, err := s.repo.List(userAddress)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return []WatchlistItem{}, nil
+	}
+
+	vaults, err := s.vaultService.GetVaults(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byAddress := make(map[string]*models.Vault, len(vaults))
+	for i := range vaults {
+		byAddress[vaults[i].Address] = &vaults[i]
+	}
+
+	items := make([]WatchlistItem, 0, len(entries))
+	for _, entry := range entries {
+		vault, ok := byAddress[entry.VaultAddress]
+		if !ok {
+			continue
+		}
+		items = append(items, WatchlistItem{
+			VaultView: s.vaultService.WithUSD(ctx, vault),
+			AddedAt:   entry.CreatedAt,
+		})
+	}
+	return items, nil
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/internal/models"
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/prices"
+	"github.com/chspring1/mya-platform/backend/pkg/swap"
+	"github.com/chspring1/mya-platform/backend/pkg/units"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrZapUnsupported  = errors.New("zap is not available for this vault's chain")
+	ErrZapUnavailable  = errors.New("no swap aggregator is configured")
+	ErrZapSameToken    = errors.New("token is already the vault asset, deposit directly")
+	ErrInvalidSlippage = errors.New("slippage exceeds the allowed maximum")
+
+	// zapIn(address tokenIn, uint256 amountIn, address swapTarget, address spender, bytes swapData, address vault, uint256 minShares, address receiver)
+	zapInSelector = crypto.Keccak256([]byte("zapIn(address,uint256,address,address,bytes,address,uint256,address)"))[:4]
+	zapInArgs     = zapArguments()
+)
+
+func zapArguments() abi.Arguments {
+	address, _ := abi.NewType("address", "", nil)
+	uint256, _ := abi.NewType("uint256", "", nil)
+	bytes, _ := abi.NewType("bytes", "", nil)
+	return abi.Arguments{
+		{Type: address}, {Type: uint256}, {Type: address}, {Type: address},
+		{Type: bytes}, {Type: address}, {Type: uint256}, {Type: address},
+	}
+}
+
+// ZapQuote 兑换+存入的组合报价，Transaction 发往路由合约，卖出ERC20时需先授权 Approve.Spender
+type ZapQuote struct {
+	Vault          string          `json:"vault"`
+	TokenIn        string          `json:"token_in"`
+	AmountIn       decimal.Decimal `json:"amount_in"`
+	Source         string          `json:"source"`
+	Route          []swap.Fill     `json:"route"`
+	ExpectedAssets decimal.Decimal `json:"expected_assets"`
+	MinAssets      decimal.Decimal `json:"min_assets"`
+	ExpectedShares decimal.Decimal `json:"expected_shares"`
+	MinShares      decimal.Decimal `json:"min_shares"`
+	SlippageBps    uint16          `json:"slippage_bps"`
+	PriceImpact    *float64        `json:"price_impact"` // 按USD价格计算的损耗比例，缺少价格时为空
+	Approve        *ZapApproval    `json:"approve,omitempty"`
+	Transaction    ZapTransaction  `json:"transaction"`
+}
+
+type ZapApproval struct {
+	Token   string `json:"token"`
+	Spender string `json:"spender"`
+	Amount  string `json:"amount"`
+}
+
+type ZapTransaction struct {
+	ChainID uint   `json:"chain_id"`
+	To      string `json:"to"`
+	Data    string `json:"data"`
+	Value   string `json:"value"`
+	Gas     uint64 `json:"gas,omitempty"` // 仅为兑换部分的估算
+}
+
+type ZapService struct {
+	sources  []swap.Source
+	previews *PreviewService
+	prices   *prices.Service
+}
+
+func NewZapService() *ZapService {
+	cfg := config.Load().Zap
+	var sources []swap.Source
+	for _, name := range cfg.Sources {
+		switch {
+		case name == "0x" && cfg.ZeroExAPIKey != "":
+			sources = append(sources, swap.NewZeroExSource(cfg.ZeroExURL, cfg.ZeroExAPIKey))
+		case name == "1inch" && cfg.OneInchAPIKey != "":
+			sources = append(sources, swap.NewOneInchSource(cfg.OneInchURL, cfg.OneInchAPIKey))
+		}
+	}
+	return &ZapService{
+		sources:  sources,
+		previews: NewPreviewService(),
+		prices:   prices.Default(),
+	}
+}
+
+// Quote 为用户持有的tokenIn报价：聚合器兑换为底层资产后由路由合约存入资金库，份额直接发给receiver。
+// 最少份额按滑点从预期份额中扣除，链上不足时整笔交易回滚
+func (s *ZapService) Quote(ctx context.Context, vault *models.Vault, receiver, tokenIn string, amountIn decimal.Decimal, slippageBps uint16) (*ZapQuote, error) {
+	cfg := config.Load().Zap
+	if slippageBps == 0 {
+		slippageBps = cfg.DefaultSlippageBps
+	}
+	if slippageBps > cfg.MaxSlippageBps {
+		return nil, ErrInvalidSlippage
+	}
+	router := zapRouter(cfg, vault.ChainID)
+	if router == nil {
+		return nil, ErrZapUnsupported
+	}
+	if len(s.sources) == 0 {
+		return nil, ErrZapUnavailable
+	}
+	if strings.EqualFold(tokenIn, vault.AssetAddress) {
+		return nil, ErrZapSameToken
+	}
+
+	inDecimals := int32(18)
+	if !swap.IsNative(tokenIn) {
+		var err error
+		if inDecimals, err = blockchain.TokenDecimals(ctx, vault.ChainID, tokenIn); err != nil {
+			return nil, fmt.Errorf("decimals of %s: %w", tokenIn, err)
+		}
+	}
+	rawIn, err := units.ToBase(amountIn, inDecimals)
+	if err != nil {
+		return nil, err
+	}
+	assetDecimals, err := blockchain.TokenDecimals(ctx, vault.ChainID, vault.AssetAddress)
+	if err != nil {
+		return nil, fmt.Errorf("decimals of %s: %w", vault.AssetAddress, err)
+	}
+
+	quote, err := s.swapQuote(ctx, swap.Request{
+		ChainID:     vault.ChainID,
+		SellToken:   tokenIn,
+		BuyToken:    vault.AssetAddress,
+		SellAmount:  rawIn,
+		Taker:       router.Address,
+		SlippageBps: slippageBps,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	expectedAssets := units.FromBase(quote.BuyAmount, assetDecimals)
+	minAssets := units.FromBase(quote.MinBuyAmount, assetDecimals)
+	preview, err := s.previews.PreviewDeposit(ctx, vault, expectedAssets)
+	if err != nil {
+		return nil, err
+	}
+	shareDecimals, err := blockchain.TokenDecimals(ctx, vault.ChainID, vault.Address)
+	if err != nil {
+		return nil, fmt.Errorf("decimals of %s: %w", vault.Address, err)
+	}
+	rawShares, err := units.ToBase(preview.Shares, shareDecimals)
+	if err != nil {
+		return nil, err
+	}
+	rawMinShares := new(big.Int).Mul(rawShares, big.NewInt(int64(10000-int(slippageBps))))
+	rawMinShares.Quo(rawMinShares, big.NewInt(10000))
+
+	data, err := zapInArgs.Pack(
+		common.HexToAddress(tokenIn), rawIn,
+		common.HexToAddress(quote.To), common.HexToAddress(quote.Spender), quote.Data,
+		common.HexToAddress(vault.Address), rawMinShares, common.HexToAddress(receiver),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("encode zapIn: %w", err)
+	}
+	calldata := make([]byte, 0, len(zapInSelector)+len(data))
+	calldata = append(append(calldata, zapInSelector...), data...)
+
+	result := &ZapQuote{
+		Vault:          vault.Address,
+		TokenIn:        strings.ToLower(tokenIn),
+		AmountIn:       amountIn,
+		Source:         quote.Source,
+		Route:          quote.Route,
+		ExpectedAssets: expectedAssets,
+		MinAssets:      minAssets,
+		ExpectedShares: preview.Shares,
+		MinShares:      units.FromBase(rawMinShares, shareDecimals),
+		SlippageBps:    slippageBps,
+		PriceImpact:    s.priceImpact(ctx, vault, router, tokenIn, amountIn, expectedAssets),
+		Transaction: ZapTransaction{
+			ChainID: vault.ChainID,
+			To:      strings.ToLower(router.Address),
+			Data:    hexutil.Encode(calldata),
+			Value:   "0",
+			Gas:     quote.Gas,
+		},
+	}
+	if swap.IsNative(tokenIn) {
+		result.Transaction.Value = rawIn.String()
+	} else {
+		result.Approve = &ZapApproval{
+			Token:   strings.ToLower(tokenIn),
+			Spender: strings.ToLower(router.Address),
+			Amount:  rawIn.String(),
+		}
+	}
+	return result, nil
+}
+
+// swapQuote 按优先级依次询价，返回第一个成功的报价
+func (s *ZapService) swapQuote(ctx context.Context, req swap.Request) (*swap.Quote, error) {
+	var lastErr error
+	for _, source := range s.sources {
+		quote, err := source.Quote(ctx, req)
+		if err != nil {
+			logger.Info(fmt.Sprintf("Swap source %s failed for %s -> %s: %v", source.Name(), req.SellToken, req.BuyToken, err))
+			lastErr = err
+			continue
+		}
+		return quote, nil
+	}
+	return nil, lastErr
+}
+
+// priceImpact 兑换前后USD价值的损耗比例，任一代币没有价格时返回nil
+func (s *ZapService) priceImpact(ctx context.Context, vault *models.Vault, router *config.ZapRouter, tokenIn string, amountIn, assetsOut decimal.Decimal) *float64 {
+	priced := tokenIn
+	if swap.IsNative(tokenIn) {
+		priced = router.WrappedNative
+	}
+	valueIn, err := s.prices.ToUSD(ctx, priced, vault.ChainID, amountIn.InexactFloat64())
+	if err != nil || valueIn <= 0 {
+		return nil
+	}
+	valueOut, err := s.prices.ToUSD(ctx, vault.AssetAddress, vault.ChainID, assetsOut.InexactFloat64())
+	if err != nil {
+		return nil
+	}
+	impact := (valueIn - valueOut) / valueIn
+	return &impact
+}
+
+func zapRouter(cfg config.ZapConfig, chainID uint) *config.ZapRouter {
+	for i, router := range cfg.Routers {
+		if router.ChainID == chainID && router.Address != "" {
+			return &cfg.Routers[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/events"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// 处理失败时的重试次数与初始间隔
+const (
+	maxAttempts  = 5
+	retryBackoff = 500 * time.Millisecond
+)
+
+// Handler 处理单个链上事件
+type Handler func(ctx context.Context, event *events.ChainEvent) error
+
+// Consumer 以消费组方式读取链上事件，处理完成或写入死信后才提交位点
+type Consumer struct {
+	reader *kafka.Reader
+	dlq    *DeadLetterWriter
+	handle Handler
+}
+
+func NewConsumer(cfg config.KafkaConfig, handle Handler) *Consumer {
+	return &Consumer{
+		dlq: NewDeadLetterWriter(cfg),
+		reader: kafka.NewReader(kafka.ReaderConfig{
+			Brokers: cfg.Brokers,
+			GroupID: cfg.GroupID,
+			Topic:   cfg.EventsTopic,
+			Dialer: &kafka.Dialer{
+				ClientID: cfg.ClientID,
+				Timeout:  10 * time.Second,
+			},
+		}),
+		handle: handle,
+	}
+}
+
+// Run 持续消费直到ctx取消
+func (c *Consumer) Run(ctx context.Context) error {
+	logger.Info(fmt.Sprintf("📥 Consuming %s as %s", c.reader.Config().Topic, c.reader.Config().GroupID))
+
+	for {
+		msg, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("fetch message: %w", err)
+		}
+
+		attempts, err := c.process(ctx, msg)
+		if ctx.Err() != nil {
+			// 关闭过程中未处理完的消息不提交，重启后重新投递
+			return nil
+		}
+		if err != nil {
+			// 死信写入失败时不提交，避免消息丢失
+			if dlqErr := c.dlq.Send(ctx, msg, err, attempts); dlqErr != nil {
+				return fmt.Errorf("send offset %d to dead-letter topic: %w", msg.Offset, dlqErr)
+			}
+			logger.Error(fmt.Sprintf("Event at %s/%d@%d moved to dead-letter topic after %d attempt(s): %v",
+				msg.Topic, msg.Partition, msg.Offset, attempts, err))
+		}
+
+		if err := c.reader.CommitMessages(ctx, msg); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("commit offset %d: %w", msg.Offset, err)
+		}
+	}
+}
+
+// process 解析并处理消息，临时错误按指数退避重试；格式错误的消息不重试。
+// 返回最终的尝试次数和错误，错误非空时消息应进入死信主题
+func (c *Consumer) process(ctx context.Context, msg kafka.Message) (int, error) {
+	version, err := events.ParseVersion(header(msg, events.HeaderSchemaVersion))
+	if err != nil {
+		return 1, err
+	}
+	event, err := events.DecodeVersion(version, msg.Value)
+	if err != nil {
+		return 1, err
+	}
+
+	backoff := retryBackoff
+	for attempt := 1; ; attempt++ {
+		err = c.handle(ctx, event)
+		if err == nil || errors.Is(err, events.ErrInvalidEvent) || attempt == maxAttempts || ctx.Err() != nil {
+			return attempt, err
+		}
+		logger.Error(fmt.Sprintf("Event %s %s failed (attempt %d), retrying: %v", event.Type, event.TxHash, attempt, err))
+
+		select {
+		case <-ctx.Done():
+			return attempt, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// Close 关闭消费者并离开消费组
+func (c *Consumer) Close() error {
+	c.dlq.Close()
+	return c.reader.Close()
+}
@@ -0,0 +1,230 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/events"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// 死信消息头，记录失败原因与原始位置
+const (
+	headerError           = "x-error"
+	headerAttempts        = "x-attempts"
+	headerSourceTopic     = "x-source-topic"
+	headerSourcePartition = "x-source-partition"
+	headerSourceOffset    = "x-source-offset"
+	headerFailedAt        = "x-failed-at"
+	headerReplayedFrom    = "x-replayed-from"
+)
+
+// DeadLetter 死信队列中的一条消息
+type DeadLetter struct {
+	Partition       int       `json:"partition"`
+	Offset          int64     `json:"offset"`
+	Key             string    `json:"key"`
+	SchemaVersion   string    `json:"schema_version,omitempty"`
+	Payload         []byte    `json:"payload"` // Avro负载，JSON中以base64输出
+	Error           string    `json:"error"`
+	Attempts        int       `json:"attempts"`
+	SourceTopic     string    `json:"source_topic"`
+	SourcePartition int       `json:"source_partition"`
+	SourceOffset    int64     `json:"source_offset"`
+	FailedAt        time.Time `json:"failed_at"`
+}
+
+// DeadLetterWriter 将无法处理的消息连同错误信息写入死信主题
+type DeadLetterWriter struct {
+	writer *kafka.Writer
+}
+
+func NewDeadLetterWriter(cfg config.KafkaConfig) *DeadLetterWriter {
+	return &DeadLetterWriter{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(cfg.Brokers...),
+			Topic:                  cfg.DLQTopic,
+			RequiredAcks:           kafka.RequireAll,
+			AllowAutoTopicCreation: true,
+		},
+	}
+}
+
+// Send 写入死信，保留原消息头(包括schema版本)，成功后原消息才可以提交位点
+func (w *DeadLetterWriter) Send(ctx context.Context, msg kafka.Message, cause error, attempts int) error {
+	headers := append(originalHeaders(msg),
+		kafka.Header{Key: headerError, Value: []byte(cause.Error())},
+		kafka.Header{Key: headerAttempts, Value: []byte(strconv.Itoa(attempts))},
+		kafka.Header{Key: headerSourceTopic, Value: []byte(msg.Topic)},
+		kafka.Header{Key: headerSourcePartition, Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: headerSourceOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		kafka.Header{Key: headerFailedAt, Value: []byte(time.Now().UTC().Format(time.RFC3339))},
+	)
+	return w.writer.WriteMessages(ctx, kafka.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	})
+}
+
+// originalHeaders 去掉死信与重放相关的消息头，只保留生产者写入的部分
+func originalHeaders(msg kafka.Message) []kafka.Header {
+	headers := make([]kafka.Header, 0, len(msg.Headers))
+	for _, h := range msg.Headers {
+		switch h.Key {
+		case headerError, headerAttempts, headerSourceTopic, headerSourcePartition,
+			headerSourceOffset, headerFailedAt, headerReplayedFrom:
+			continue
+		}
+		headers = append(headers, h)
+	}
+	return headers
+}
+
+// Close 刷新并关闭写入器
+func (w *DeadLetterWriter) Close() error {
+	return w.writer.Close()
+}
+
+// ListDeadLetters 读取死信主题各分区中最早的limit条消息，不影响任何消费组位点
+func ListDeadLetters(ctx context.Context, cfg config.KafkaConfig, limit int) ([]DeadLetter, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, errors.New("no kafka brokers configured")
+	}
+
+	conn, err := kafka.DialContext(ctx, "tcp", cfg.Brokers[0])
+	if err != nil {
+		return nil, err
+	}
+	partitions, err := conn.ReadPartitions(cfg.DLQTopic)
+	conn.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var letters []DeadLetter
+	for _, p := range partitions {
+		if len(letters) >= limit {
+			break
+		}
+		read, err := readPartition(ctx, p, limit-len(letters))
+		if err != nil {
+			return letters, err
+		}
+		letters = append(letters, read...)
+	}
+	return letters, nil
+}
+
+func readPartition(ctx context.Context, p kafka.Partition, limit int) ([]DeadLetter, error) {
+	leader := net.JoinHostPort(p.Leader.Host, strconv.Itoa(p.Leader.Port))
+	conn, err := kafka.DialLeader(ctx, "tcp", leader, p.Topic, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	first, last, err := conn.ReadOffsets()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.Seek(first, kafka.SeekAbsolute); err != nil {
+		return nil, err
+	}
+
+	var letters []DeadLetter
+	for offset := first; offset < last && len(letters) < limit; offset++ {
+		if err := conn.SetReadDeadline(time.Now().Add(10 * time.Second)); err != nil {
+			return letters, err
+		}
+		msg, err := conn.ReadMessage(10 << 20)
+		if err != nil {
+			return letters, err
+		}
+		letters = append(letters, toDeadLetter(msg))
+	}
+	return letters, nil
+}
+
+func toDeadLetter(msg kafka.Message) DeadLetter {
+	letter := DeadLetter{
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Key:       string(msg.Key),
+		Payload:   msg.Value,
+	}
+	for _, h := range msg.Headers {
+		value := string(h.Value)
+		switch h.Key {
+		case headerError:
+			letter.Error = value
+		case headerAttempts:
+			letter.Attempts, _ = strconv.Atoi(value)
+		case headerSourceTopic:
+			letter.SourceTopic = value
+		case headerSourcePartition:
+			letter.SourcePartition, _ = strconv.Atoi(value)
+		case headerSourceOffset:
+			letter.SourceOffset, _ = strconv.ParseInt(value, 10, 64)
+		case events.HeaderSchemaVersion:
+			letter.SchemaVersion = value
+		case headerFailedAt:
+			letter.FailedAt, _ = time.Parse(time.RFC3339, value)
+		}
+	}
+	return letter
+}
+
+// Replay 通过独立消费组把死信重新发布到事件主题，idle时间内没有新消息即结束。
+// 每条死信只会被重放一次；重放后仍失败的消息会再次进入死信主题
+func Replay(ctx context.Context, cfg config.KafkaConfig, idle time.Duration) (int, error) {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     cfg.Brokers,
+		GroupID:     cfg.GroupID + "-dlq-replay",
+		Topic:       cfg.DLQTopic,
+		StartOffset: kafka.FirstOffset,
+	})
+	defer reader.Close()
+
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(cfg.Brokers...),
+		Topic:        cfg.EventsTopic,
+		RequiredAcks: kafka.RequireAll,
+	}
+	defer writer.Close()
+
+	replayed := 0
+	for {
+		fetchCtx, cancel := context.WithTimeout(ctx, idle)
+		msg, err := reader.FetchMessage(fetchCtx)
+		cancel()
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				return replayed, nil
+			}
+			return replayed, err
+		}
+
+		headers := append(originalHeaders(msg), kafka.Header{
+			Key:   headerReplayedFrom,
+			Value: []byte(fmt.Sprintf("%s/%d@%d", msg.Topic, msg.Partition, msg.Offset)),
+		})
+		if err := writer.WriteMessages(ctx, kafka.Message{
+			Key:     msg.Key,
+			Value:   msg.Value,
+			Headers: headers,
+		}); err != nil {
+			return replayed, err
+		}
+		if err := reader.CommitMessages(ctx, msg); err != nil {
+			return replayed, err
+		}
+		replayed++
+	}
+}
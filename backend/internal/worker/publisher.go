@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/events"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Publisher 发布链上事件，发布前按当前schema校验并编码，保证消费者不会收到不兼容的负载
+type Publisher struct {
+	writer *kafka.Writer
+}
+
+func NewPublisher(cfg config.KafkaConfig) *Publisher {
+	return &Publisher{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(cfg.Brokers...),
+			Topic:        cfg.EventsTopic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+		},
+	}
+}
+
+// Publish 校验并发布事件，同一资金库的事件写入同一分区以保证顺序
+func (p *Publisher) Publish(ctx context.Context, event *events.ChainEvent) error {
+	data, version, err := events.Encode(event)
+	if err != nil {
+		return err
+	}
+
+	return p.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(event.Vault),
+		Value: data,
+		Headers: []kafka.Header{
+			{Key: events.HeaderSchema, Value: []byte(events.SchemaName)},
+			{Key: events.HeaderSchemaVersion, Value: []byte(strconv.Itoa(version))},
+		},
+	})
+}
+
+// Close 刷新并关闭写入器
+func (p *Publisher) Close() error {
+	return p.writer.Close()
+}
+
+// header 读取消息头，不存在时返回空字符串
+func header(msg kafka.Message, key string) string {
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
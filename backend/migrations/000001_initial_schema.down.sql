@@ -0,0 +1,13 @@
+-- 回滚初始表结构
+
+DROP TABLE IF EXISTS token_prices;
+DROP TABLE IF EXISTS protocol_rates;
+DROP TABLE IF EXISTS strategy_snapshots;
+DROP TABLE IF EXISTS rebalance_items;
+DROP TABLE IF EXISTS rebalance_proposals;
+DROP TABLE IF EXISTS apy_history;
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS strategies;
+DROP TABLE IF EXISTS vaults;
+DROP TABLE IF EXISTS users;
+DROP FUNCTION IF EXISTS update_updated_at_column();
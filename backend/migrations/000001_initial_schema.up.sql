@@ -0,0 +1,189 @@
+-- 初始表结构
+
+-- 创建用户表
+CREATE TABLE IF NOT EXISTS users (
+    id SERIAL PRIMARY KEY,
+    address VARCHAR(42) UNIQUE NOT NULL,
+    total_tvl DECIMAL(36,18) DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+-- 创建金库表
+CREATE TABLE IF NOT EXISTS vaults (
+    id SERIAL PRIMARY KEY,
+    address VARCHAR(42) UNIQUE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    symbol VARCHAR(10) NOT NULL,
+    chain_id INTEGER NOT NULL,
+    asset_address VARCHAR(42) NOT NULL,
+    strategy_address VARCHAR(42),
+    tvl DECIMAL(36,18) DEFAULT 0,
+    apy_current DECIMAL(10,8) DEFAULT 0,
+    apy_weekly DECIMAL(10,8) DEFAULT 0,
+    total_deposits DECIMAL(36,18) DEFAULT 0,
+    total_withdrawals DECIMAL(36,18) DEFAULT 0,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+-- 创建策略表
+CREATE TABLE IF NOT EXISTS strategies (
+    id SERIAL PRIMARY KEY,
+    address VARCHAR(42) UNIQUE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    vault_address VARCHAR(42) NOT NULL,
+    protocol VARCHAR(50),
+    apy DECIMAL(10,8) DEFAULT 0,
+    risk_score SMALLINT DEFAULT 0,
+    max_alloc_bps INTEGER DEFAULT 10000,
+    target_bps INTEGER DEFAULT 0,
+    total_assets DECIMAL(36,18) DEFAULT 0,
+    total_earnings DECIMAL(36,18) DEFAULT 0,
+    is_active BOOLEAN DEFAULT true,
+    last_harvest TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+-- 创建交易表
+CREATE TABLE IF NOT EXISTS transactions (
+    id SERIAL PRIMARY KEY,
+    user_address VARCHAR(42) NOT NULL,
+    vault_address VARCHAR(42) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('deposit', 'withdraw', 'rebalance')),
+    amount DECIMAL(36,18) NOT NULL,
+    shares DECIMAL(36,18) DEFAULT 0,
+    tx_hash VARCHAR(66) UNIQUE NOT NULL,
+    block_number BIGINT,
+    status VARCHAR(20) DEFAULT 'pending' CHECK (status IN ('pending', 'confirmed', 'failed')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+-- 创建APY历史表
+CREATE TABLE IF NOT EXISTS apy_history (
+    id SERIAL PRIMARY KEY,
+    vault_address VARCHAR(42) NOT NULL,
+    apy_value DECIMAL(10,8) NOT NULL,
+    tvl DECIMAL(36,18) NOT NULL,
+    timestamp TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 创建再平衡提案表
+CREATE TABLE IF NOT EXISTS rebalance_proposals (
+    id SERIAL PRIMARY KEY,
+    vault_address VARCHAR(42) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected', 'executed', 'superseded')),
+    total_assets DECIMAL(36,18) NOT NULL,
+    current_apy DECIMAL(10,8) NOT NULL,
+    proposed_apy DECIMAL(10,8) NOT NULL,
+    reason VARCHAR(255),
+    reviewed_by VARCHAR(42),
+    reviewed_at TIMESTAMP,
+    executed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 创建再平衡明细表
+CREATE TABLE IF NOT EXISTS rebalance_items (
+    id SERIAL PRIMARY KEY,
+    proposal_id INTEGER NOT NULL REFERENCES rebalance_proposals(id) ON DELETE CASCADE,
+    strategy_address VARCHAR(42) NOT NULL,
+    strategy_apy DECIMAL(10,8) NOT NULL,
+    risk_score SMALLINT NOT NULL,
+    current_assets DECIMAL(36,18) NOT NULL,
+    target_assets DECIMAL(36,18) NOT NULL,
+    current_bps INTEGER NOT NULL,
+    target_bps INTEGER NOT NULL,
+    tx_hash VARCHAR(66)
+);
+
+-- 创建策略快照表
+CREATE TABLE IF NOT EXISTS strategy_snapshots (
+    id SERIAL PRIMARY KEY,
+    strategy_address VARCHAR(42) NOT NULL,
+    apy DECIMAL(10,8) NOT NULL,
+    total_assets DECIMAL(36,18) NOT NULL,
+    total_earnings DECIMAL(36,18) NOT NULL,
+    total_assets_usd DECIMAL(36,8),
+    timestamp TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 创建协议利率表
+CREATE TABLE IF NOT EXISTS protocol_rates (
+    id SERIAL PRIMARY KEY,
+    protocol VARCHAR(50) NOT NULL,
+    chain_id INTEGER NOT NULL,
+    asset_address VARCHAR(42) NOT NULL,
+    apy DECIMAL(10,8) NOT NULL,
+    timestamp TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 创建代币历史价格表
+CREATE TABLE IF NOT EXISTS token_prices (
+    id SERIAL PRIMARY KEY,
+    chain_id INTEGER NOT NULL,
+    token_address VARCHAR(42) NOT NULL,
+    price_usd DECIMAL(36,18) NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('daily', 'transaction')),
+    timestamp TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 创建索引以提高查询性能
+CREATE INDEX IF NOT EXISTS idx_users_address ON users(address);
+CREATE INDEX IF NOT EXISTS idx_vaults_address ON vaults(address);
+CREATE INDEX IF NOT EXISTS idx_vaults_is_active ON vaults(is_active);
+CREATE INDEX IF NOT EXISTS idx_strategies_address ON strategies(address);
+CREATE INDEX IF NOT EXISTS idx_strategies_vault_address ON strategies(vault_address);
+CREATE INDEX IF NOT EXISTS idx_transactions_user_address ON transactions(user_address);
+CREATE INDEX IF NOT EXISTS idx_transactions_vault_address ON transactions(vault_address);
+CREATE INDEX IF NOT EXISTS idx_transactions_tx_hash ON transactions(tx_hash);
+CREATE INDEX IF NOT EXISTS idx_transactions_status ON transactions(status);
+CREATE INDEX IF NOT EXISTS idx_apy_history_vault_ts ON apy_history(vault_address, timestamp);
+CREATE INDEX IF NOT EXISTS idx_rebalance_proposals_vault_address ON rebalance_proposals(vault_address);
+CREATE INDEX IF NOT EXISTS idx_rebalance_proposals_status ON rebalance_proposals(status);
+CREATE INDEX IF NOT EXISTS idx_rebalance_items_proposal_id ON rebalance_items(proposal_id);
+CREATE INDEX IF NOT EXISTS idx_strategy_snapshots_address_ts ON strategy_snapshots(strategy_address, timestamp);
+CREATE INDEX IF NOT EXISTS idx_protocol_rates_lookup ON protocol_rates(protocol, chain_id, asset_address, timestamp);
+CREATE INDEX IF NOT EXISTS idx_token_prices_lookup ON token_prices(chain_id, token_address, timestamp);
+
+-- 创建触发器函数来自动更新 updated_at 字段
+CREATE OR REPLACE FUNCTION update_updated_at_column()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = CURRENT_TIMESTAMP;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+-- 为需要的表创建触发器
+DROP TRIGGER IF EXISTS update_users_updated_at ON users;
+CREATE TRIGGER update_users_updated_at
+    BEFORE UPDATE ON users
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_vaults_updated_at ON vaults;
+CREATE TRIGGER update_vaults_updated_at
+    BEFORE UPDATE ON vaults
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_strategies_updated_at ON strategies;
+CREATE TRIGGER update_strategies_updated_at
+    BEFORE UPDATE ON strategies
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_rebalance_proposals_updated_at ON rebalance_proposals;
+CREATE TRIGGER update_rebalance_proposals_updated_at
+    BEFORE UPDATE ON rebalance_proposals
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_apy_history_timestamp;
+DROP TABLE IF EXISTS apy_history_daily;
@@ -0,0 +1,14 @@
+-- APY历史按天汇总，原始数据超过保留期后删除，日汇总永久保留
+CREATE TABLE IF NOT EXISTS apy_history_daily (
+    id SERIAL PRIMARY KEY,
+    vault_address VARCHAR(42) NOT NULL,
+    day DATE NOT NULL,
+    avg_apy DECIMAL(10,8) NOT NULL,
+    min_apy DECIMAL(10,8) NOT NULL,
+    max_apy DECIMAL(10,8) NOT NULL,
+    avg_tvl DECIMAL(36,18) NOT NULL,
+    samples INTEGER NOT NULL,
+    UNIQUE (vault_address, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_apy_history_timestamp ON apy_history(timestamp);
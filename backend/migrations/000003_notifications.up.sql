@@ -0,0 +1,34 @@
+-- 用户通知渠道，验证通过后才会发送事件通知
+CREATE TABLE IF NOT EXISTS notification_channels (
+    id SERIAL PRIMARY KEY,
+    user_address VARCHAR(42) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('email', 'telegram')),
+    target VARCHAR(255) NOT NULL,
+    verified BOOLEAN NOT NULL DEFAULT false,
+    verification_code_hash VARCHAR(64),
+    verification_expires_at TIMESTAMP,
+    verified_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_address, type)
+);
+
+-- 用户订阅的事件，vault_address为空表示所有资金库
+CREATE TABLE IF NOT EXISTS notification_subscriptions (
+    id SERIAL PRIMARY KEY,
+    user_address VARCHAR(42) NOT NULL,
+    event VARCHAR(30) NOT NULL CHECK (event IN ('apy_below', 'deposit_confirmed', 'withdraw_confirmed', 'vault_paused')),
+    vault_address VARCHAR(42) NOT NULL DEFAULT '',
+    threshold DECIMAL(10,8),
+    triggered_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_address, event, vault_address)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_subscriptions_event ON notification_subscriptions(event, vault_address);
+
+DROP TRIGGER IF EXISTS update_notification_channels_updated_at ON notification_channels;
+CREATE TRIGGER update_notification_channels_updated_at
+    BEFORE UPDATE ON notification_channels
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
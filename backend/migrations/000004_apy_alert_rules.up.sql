@@ -0,0 +1,15 @@
+-- 用户自定义的APY告警规则，例如"7日APY低于4%"或"当前APY高于8%"
+CREATE TABLE IF NOT EXISTS apy_alert_rules (
+    id SERIAL PRIMARY KEY,
+    user_address VARCHAR(42) NOT NULL,
+    vault_address VARCHAR(42) NOT NULL,
+    apy_window VARCHAR(10) NOT NULL CHECK (apy_window IN ('current', '7d')),
+    direction VARCHAR(10) NOT NULL CHECK (direction IN ('above', 'below')),
+    threshold DECIMAL(10,8) NOT NULL,
+    triggered_at TIMESTAMP,
+    last_notified_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_address, vault_address, apy_window, direction, threshold)
+);
+
+CREATE INDEX IF NOT EXISTS idx_apy_alert_rules_vault ON apy_alert_rules(vault_address);
@@ -0,0 +1,12 @@
+-- 管理操作审计日志，只追加不修改
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id SERIAL PRIMARY KEY,
+    actor VARCHAR(42) NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    target VARCHAR(66) NOT NULL DEFAULT '',
+    details JSONB,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_target ON audit_logs(target, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs(created_at DESC);
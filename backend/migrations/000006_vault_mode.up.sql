@@ -0,0 +1,5 @@
+-- 资金库运行模式：正常、只允许取款、只允许存款、完全冻结。is_active 与 mode != 'frozen' 保持一致
+ALTER TABLE vaults ADD COLUMN IF NOT EXISTS mode VARCHAR(20) NOT NULL DEFAULT 'active'
+    CHECK (mode IN ('active', 'withdraw_only', 'deposit_only', 'frozen'));
+
+UPDATE vaults SET mode = 'frozen' WHERE is_active = false;
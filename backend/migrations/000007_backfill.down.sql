@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS backfill_checkpoints;
+DROP TABLE IF EXISTS harvests;
@@ -0,0 +1,27 @@
+-- 资金库收获记录，按交易和日志序号去重，重复回放不会重复计入收益
+CREATE TABLE IF NOT EXISTS harvests (
+    id SERIAL PRIMARY KEY,
+    vault_address VARCHAR(42) NOT NULL,
+    strategy_address VARCHAR(42) NOT NULL DEFAULT '',
+    chain_id INTEGER NOT NULL,
+    amount DECIMAL(36,18) NOT NULL,
+    tx_hash VARCHAR(66) NOT NULL,
+    log_index INTEGER NOT NULL,
+    block_number BIGINT NOT NULL,
+    harvested_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tx_hash, log_index)
+);
+
+CREATE INDEX IF NOT EXISTS idx_harvests_vault ON harvests(vault_address, harvested_at DESC);
+
+-- cmd/backfill 的断点，记录每个资金库已完整回放到的区块
+CREATE TABLE IF NOT EXISTS backfill_checkpoints (
+    vault_address VARCHAR(42) PRIMARY KEY,
+    chain_id INTEGER NOT NULL,
+    from_block BIGINT NOT NULL,
+    to_block BIGINT NOT NULL,
+    last_block BIGINT NOT NULL,
+    events INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,7 @@
+CREATE INDEX IF NOT EXISTS idx_transactions_user_address ON transactions(user_address);
+CREATE INDEX IF NOT EXISTS idx_transactions_vault_address ON transactions(vault_address);
+CREATE INDEX IF NOT EXISTS idx_apy_history_vault_ts ON apy_history(vault_address, timestamp);
+
+DROP INDEX IF EXISTS idx_apy_history_vault_ts_id;
+DROP INDEX IF EXISTS idx_transactions_vault_created;
+DROP INDEX IF EXISTS idx_transactions_user_created;
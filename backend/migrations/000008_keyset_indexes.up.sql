@@ -0,0 +1,9 @@
+-- 游标分页按 (时间, id) 倒序扫描，覆盖过滤列后不再需要额外排序
+CREATE INDEX IF NOT EXISTS idx_transactions_user_created ON transactions(user_address, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_transactions_vault_created ON transactions(vault_address, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_apy_history_vault_ts_id ON apy_history(vault_address, timestamp DESC, id DESC);
+
+-- 被上面的索引覆盖
+DROP INDEX IF EXISTS idx_transactions_user_address;
+DROP INDEX IF EXISTS idx_transactions_vault_address;
+DROP INDEX IF EXISTS idx_apy_history_vault_ts;
@@ -0,0 +1 @@
+-- 原始大小写无法恢复，小写地址对旧版本同样有效
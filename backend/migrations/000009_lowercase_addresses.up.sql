@@ -0,0 +1,48 @@
+-- 地址统一以小写存储，先删除仅大小写不同的重复行，保留最早写入的一条
+
+DELETE FROM users u USING users d
+WHERE lower(u.address) = lower(d.address) AND u.id > d.id;
+
+DELETE FROM vaults v USING vaults d
+WHERE lower(v.address) = lower(d.address) AND v.id > d.id;
+
+DELETE FROM strategies s USING strategies d
+WHERE lower(s.address) = lower(d.address) AND s.id > d.id;
+
+DELETE FROM apy_history_daily a USING apy_history_daily d
+WHERE lower(a.vault_address) = lower(d.vault_address) AND a.day = d.day AND a.id > d.id;
+
+DELETE FROM notification_channels n USING notification_channels d
+WHERE lower(n.user_address) = lower(d.user_address) AND n.type = d.type AND n.id > d.id;
+
+DELETE FROM notification_subscriptions n USING notification_subscriptions d
+WHERE lower(n.user_address) = lower(d.user_address) AND n.event = d.event
+  AND lower(n.vault_address) = lower(d.vault_address) AND n.id > d.id;
+
+DELETE FROM apy_alert_rules a USING apy_alert_rules d
+WHERE lower(a.user_address) = lower(d.user_address) AND lower(a.vault_address) = lower(d.vault_address)
+  AND a.apy_window = d.apy_window AND a.direction = d.direction AND a.threshold = d.threshold AND a.id > d.id;
+
+-- 断点以资金库地址为主键，保留进度最远的一条
+DELETE FROM backfill_checkpoints b USING backfill_checkpoints d
+WHERE lower(b.vault_address) = lower(d.vault_address) AND b.vault_address <> d.vault_address
+  AND (b.last_block, b.vault_address) < (d.last_block, d.vault_address);
+
+UPDATE users SET address = lower(address);
+UPDATE vaults SET address = lower(address), asset_address = lower(asset_address), strategy_address = lower(strategy_address);
+UPDATE strategies SET address = lower(address), vault_address = lower(vault_address);
+UPDATE transactions SET user_address = lower(user_address), vault_address = lower(vault_address);
+UPDATE apy_history SET vault_address = lower(vault_address);
+UPDATE apy_history_daily SET vault_address = lower(vault_address);
+UPDATE rebalance_proposals SET vault_address = lower(vault_address);
+UPDATE rebalance_items SET strategy_address = lower(strategy_address);
+UPDATE strategy_snapshots SET strategy_address = lower(strategy_address);
+UPDATE protocol_rates SET asset_address = lower(asset_address);
+UPDATE token_prices SET token_address = lower(token_address);
+UPDATE notification_channels SET user_address = lower(user_address);
+UPDATE notification_subscriptions SET user_address = lower(user_address), vault_address = lower(vault_address);
+UPDATE apy_alert_rules SET user_address = lower(user_address), vault_address = lower(vault_address);
+UPDATE audit_logs SET actor = lower(actor);
+UPDATE audit_logs SET target = lower(target) WHERE target ~ '^0x[0-9a-fA-F]{40}$';
+UPDATE harvests SET vault_address = lower(vault_address), strategy_address = lower(strategy_address);
+UPDATE backfill_checkpoints SET vault_address = lower(vault_address);
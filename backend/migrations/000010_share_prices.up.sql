@@ -0,0 +1,11 @@
+-- 资金库份额价格，由链上 totalAssets()/totalSupply() 计算，是计算用户实际收益的依据
+CREATE TABLE IF NOT EXISTS share_prices (
+    id SERIAL PRIMARY KEY,
+    vault_address VARCHAR(42) NOT NULL,
+    total_assets DECIMAL(36,18) NOT NULL,
+    total_supply DECIMAL(36,18) NOT NULL,
+    price_per_share DECIMAL(36,18) NOT NULL,
+    timestamp TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_share_prices_vault_ts_id ON share_prices(vault_address, timestamp DESC, id DESC);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS fee_accruals;
+ALTER TABLE vaults DROP COLUMN IF EXISTS performance_fee_bps;
+ALTER TABLE vaults DROP COLUMN IF EXISTS management_fee_bps;
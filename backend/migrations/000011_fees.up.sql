@@ -0,0 +1,23 @@
+-- 资金库费率(万分比)：管理费按年化计提，业绩费按每次收获的收益计提
+ALTER TABLE vaults ADD COLUMN IF NOT EXISTS management_fee_bps INTEGER NOT NULL DEFAULT 0
+    CHECK (management_fee_bps BETWEEN 0 AND 10000);
+ALTER TABLE vaults ADD COLUMN IF NOT EXISTS performance_fee_bps INTEGER NOT NULL DEFAULT 0
+    CHECK (performance_fee_bps BETWEEN 0 AND 10000);
+
+-- 每次收获计提的费用，随收获记录一起写入，重复回放不会重复计提
+CREATE TABLE IF NOT EXISTS fee_accruals (
+    id SERIAL PRIMARY KEY,
+    vault_address VARCHAR(42) NOT NULL,
+    harvest_id INTEGER NOT NULL REFERENCES harvests(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('management', 'performance')),
+    fee_bps INTEGER NOT NULL,
+    base DECIMAL(36,18) NOT NULL,
+    amount DECIMAL(36,18) NOT NULL,
+    period_start TIMESTAMP NOT NULL,
+    period_end TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (harvest_id, type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_fee_accruals_period_end ON fee_accruals(period_end);
+CREATE INDEX IF NOT EXISTS idx_fee_accruals_vault ON fee_accruals(vault_address, period_end);
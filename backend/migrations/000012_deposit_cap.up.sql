@@ -0,0 +1,2 @@
+-- 资金库存款上限(底层资产数量)，为空表示不限制
+ALTER TABLE vaults ADD COLUMN IF NOT EXISTS deposit_cap DECIMAL(36,18) CHECK (deposit_cap >= 0);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS vault_allowlist;
+ALTER TABLE vaults DROP COLUMN IF EXISTS allowlist_enabled;
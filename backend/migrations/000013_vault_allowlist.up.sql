@@ -0,0 +1,12 @@
+-- 机构资金库只接受白名单地址存款，allowlist_enabled 为false时不检查
+ALTER TABLE vaults ADD COLUMN IF NOT EXISTS allowlist_enabled BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS vault_allowlist (
+    id SERIAL PRIMARY KEY,
+    vault_address VARCHAR(42) NOT NULL,
+    user_address VARCHAR(42) NOT NULL,
+    added_by VARCHAR(42) NOT NULL,
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (vault_address, user_address)
+);
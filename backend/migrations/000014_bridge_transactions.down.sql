@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_transactions_pending_bridge;
+ALTER TABLE transactions DROP COLUMN IF EXISTS destination_tx_hash;
+ALTER TABLE transactions DROP COLUMN IF EXISTS bridge;
+ALTER TABLE transactions DROP COLUMN IF EXISTS source_chain_id;
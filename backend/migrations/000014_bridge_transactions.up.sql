@@ -0,0 +1,7 @@
+-- 跨链存款：源链上的跨链交易以 type='bridge' 记录，完成后写入目标链交易哈希
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS source_chain_id INTEGER;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS bridge VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS destination_tx_hash VARCHAR(66) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_transactions_pending_bridge ON transactions (created_at)
+    WHERE type = 'bridge' AND status = 'pending';
@@ -0,0 +1,33 @@
+-- 资金库运行时间线：策略增减、收获、再平衡、费率变更、暂停/恢复、紧急停止
+CREATE TABLE IF NOT EXISTS vault_events (
+    id BIGSERIAL PRIMARY KEY,
+    vault_address VARCHAR(42) NOT NULL,
+    type VARCHAR(30) NOT NULL,
+    actor VARCHAR(42) NOT NULL DEFAULT '',
+    tx_hash VARCHAR(66) NOT NULL DEFAULT '',
+    log_index INTEGER NOT NULL DEFAULT 0,
+    details JSONB,
+    occurred_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_vault_events_vault_time ON vault_events (vault_address, occurred_at DESC, id DESC);
+-- 链上事件可能重复投递，按日志位置去重
+CREATE UNIQUE INDEX IF NOT EXISTS idx_vault_events_log ON vault_events (tx_hash, log_index, type) WHERE tx_hash <> '';
+
+-- 用已有的收获记录和审计日志补齐历史时间线
+INSERT INTO vault_events (vault_address, type, tx_hash, log_index, details, occurred_at)
+SELECT vault_address, 'harvest', tx_hash, log_index,
+       jsonb_build_object('amount', amount::text, 'strategy', strategy_address), harvested_at
+FROM harvests
+ON CONFLICT DO NOTHING;
+
+INSERT INTO vault_events (vault_address, type, actor, details, occurred_at)
+SELECT target,
+       CASE action
+           WHEN 'vault.emergency_stop' THEN 'emergency_stop'
+           WHEN 'vault.set_fees' THEN 'fee_change'
+           WHEN 'vault.set_mode' THEN CASE WHEN details->>'mode' = 'active' THEN 'unpause' ELSE 'pause' END
+       END,
+       actor, details, created_at
+FROM audit_logs
+WHERE action IN ('vault.emergency_stop', 'vault.set_fees', 'vault.set_mode');
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_harvests_gas_pending;
+DROP INDEX IF EXISTS idx_harvests_strategy;
+
+ALTER TABLE harvests DROP COLUMN IF EXISTS gas_cost_usd;
+ALTER TABLE harvests DROP COLUMN IF EXISTS gas_cost;
+ALTER TABLE harvests DROP COLUMN IF EXISTS gas_used;
@@ -0,0 +1,7 @@
+-- 收获交易实际支付的gas，由 harvest-gas 任务从交易回执补齐，为空表示尚未补齐
+ALTER TABLE harvests ADD COLUMN IF NOT EXISTS gas_used BIGINT;
+ALTER TABLE harvests ADD COLUMN IF NOT EXISTS gas_cost DECIMAL(36,18);
+ALTER TABLE harvests ADD COLUMN IF NOT EXISTS gas_cost_usd DECIMAL(36,18);
+
+CREATE INDEX IF NOT EXISTS idx_harvests_strategy ON harvests(strategy_address, harvested_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_harvests_gas_pending ON harvests(id) WHERE gas_used IS NULL;
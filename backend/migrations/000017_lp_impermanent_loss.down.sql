@@ -0,0 +1,6 @@
+ALTER TABLE strategy_snapshots DROP COLUMN IF EXISTS impermanent_loss;
+
+DROP TABLE IF EXISTS strategy_lp_tokens;
+
+ALTER TABLE strategies DROP COLUMN IF EXISTS lp_entry_at;
+ALTER TABLE strategies DROP COLUMN IF EXISTS lp_pool_type;
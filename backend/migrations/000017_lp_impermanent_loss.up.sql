@@ -0,0 +1,14 @@
+-- LP类策略的池类型和建仓时间，空字符串表示不是LP策略
+ALTER TABLE strategies ADD COLUMN IF NOT EXISTS lp_pool_type VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE strategies ADD COLUMN IF NOT EXISTS lp_entry_at TIMESTAMP;
+
+-- LP池中的代币及建仓时的USD价格，用于估算无常损失
+CREATE TABLE IF NOT EXISTS strategy_lp_tokens (
+    id SERIAL PRIMARY KEY,
+    strategy_address VARCHAR(42) NOT NULL,
+    token_address VARCHAR(42) NOT NULL,
+    entry_price_usd DECIMAL(36,18) NOT NULL,
+    UNIQUE (strategy_address, token_address)
+);
+
+ALTER TABLE strategy_snapshots ADD COLUMN IF NOT EXISTS impermanent_loss DECIMAL(10,8);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS keeper_jobs;
+DROP TABLE IF EXISTS keepers;
@@ -0,0 +1,46 @@
+-- 外部keeper：登记的执行机器人凭API Key领取收获、再平衡等链上任务
+CREATE TABLE IF NOT EXISTS keepers (
+    id SERIAL PRIMARY KEY,
+    address VARCHAR(42) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    api_key_hash VARCHAR(64) NOT NULL UNIQUE,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_by VARCHAR(42) NOT NULL,
+    last_seen_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS keeper_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('harvest', 'rebalance')),
+    chain_id INTEGER NOT NULL,
+    vault_address VARCHAR(42) NOT NULL,
+    target VARCHAR(66) NOT NULL,
+    payload JSONB,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'claimed', 'succeeded', 'failed', 'cancelled')),
+    keeper_id INTEGER REFERENCES keepers(id),
+    lease_expires_at TIMESTAMP,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    tx_hash VARCHAR(66) NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 同一目标同时只有一个未完成的任务，生成任务时依赖该索引去重
+CREATE UNIQUE INDEX IF NOT EXISTS idx_keeper_jobs_open ON keeper_jobs (type, target) WHERE status IN ('pending', 'claimed');
+CREATE INDEX IF NOT EXISTS idx_keeper_jobs_available ON keeper_jobs (created_at, id) WHERE status IN ('pending', 'claimed');
+
+DROP TRIGGER IF EXISTS update_keepers_updated_at ON keepers;
+CREATE TRIGGER update_keepers_updated_at
+    BEFORE UPDATE ON keepers
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_keeper_jobs_updated_at ON keeper_jobs;
+CREATE TRIGGER update_keeper_jobs_updated_at
+    BEFORE UPDATE ON keeper_jobs
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
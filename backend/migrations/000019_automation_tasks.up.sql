@@ -0,0 +1,29 @@
+-- 在Chainlink Automation或Gelato上创建的定时任务，worker定期读取链上状态，异常时作为风险警报展示
+CREATE TABLE IF NOT EXISTS automation_tasks (
+    id SERIAL PRIMARY KEY,
+    provider VARCHAR(20) NOT NULL CHECK (provider IN ('chainlink', 'gelato')),
+    chain_id INTEGER NOT NULL,
+    task_id VARCHAR(78) NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('harvest', 'rebalance')),
+    vault_address VARCHAR(42) NOT NULL,
+    strategy_address VARCHAR(42) NOT NULL DEFAULT '',
+    interval INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'unknown',
+    status_reason TEXT NOT NULL DEFAULT '',
+    balance DECIMAL(36,18),
+    min_balance DECIMAL(36,18),
+    last_performed_block BIGINT,
+    last_checked_at TIMESTAMP,
+    created_by VARCHAR(42) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider, chain_id, task_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_automation_tasks_vault ON automation_tasks (vault_address);
+
+DROP TRIGGER IF EXISTS update_automation_tasks_updated_at ON automation_tasks;
+CREATE TRIGGER update_automation_tasks_updated_at
+    BEFORE UPDATE ON automation_tasks
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
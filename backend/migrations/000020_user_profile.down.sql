@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_users_nickname;
+ALTER TABLE users DROP COLUMN IF EXISTS profile_updated_at;
+ALTER TABLE users DROP COLUMN IF EXISTS email;
+ALTER TABLE users DROP COLUMN IF EXISTS avatar_url;
+ALTER TABLE users DROP COLUMN IF EXISTS nickname;
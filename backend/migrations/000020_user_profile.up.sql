@@ -0,0 +1,8 @@
+-- 用户资料：排行榜和通知中代替原始地址展示，email 仅作联系方式，通知邮箱仍需在通知渠道中验证
+ALTER TABLE users ADD COLUMN IF NOT EXISTS nickname VARCHAR(32) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_url VARCHAR(512) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS profile_updated_at TIMESTAMP;
+
+-- 昵称不区分大小写唯一
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_nickname ON users (LOWER(nickname)) WHERE nickname <> '';
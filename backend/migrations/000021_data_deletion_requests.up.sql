@@ -0,0 +1,13 @@
+-- 用户发起的链下个人数据删除请求，由worker异步清除资料和通知联系方式；链上交易记录是公开数据，不在删除范围内
+CREATE TABLE IF NOT EXISTS data_deletion_requests (
+    id SERIAL PRIMARY KEY,
+    user_address VARCHAR(42) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed', 'failed')),
+    error TEXT NOT NULL DEFAULT '',
+    requested_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP
+);
+
+-- 每个用户同时只有一个待处理的请求
+CREATE UNIQUE INDEX IF NOT EXISTS idx_data_deletion_requests_pending ON data_deletion_requests (user_address) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_data_deletion_requests_user ON data_deletion_requests (user_address, requested_at DESC);
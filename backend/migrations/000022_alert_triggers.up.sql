@@ -0,0 +1,27 @@
+-- APY订阅和告警规则每次触发的记录，用于用户动态；冷却期内只记录不通知的触发 notified 为false
+CREATE TABLE IF NOT EXISTS alert_triggers (
+    id BIGSERIAL PRIMARY KEY,
+    user_address VARCHAR(42) NOT NULL,
+    vault_address VARCHAR(42) NOT NULL,
+    event VARCHAR(30) NOT NULL,
+    source VARCHAR(20) NOT NULL CHECK (source IN ('subscription', 'rule')),
+    source_id INTEGER NOT NULL,
+    details JSONB,
+    notified BOOLEAN NOT NULL DEFAULT TRUE,
+    triggered_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_triggers_user_time ON alert_triggers (user_address, triggered_at DESC, id DESC);
+
+-- 补齐当前仍处于触发状态的订阅和规则
+INSERT INTO alert_triggers (user_address, vault_address, event, source, source_id, details, triggered_at)
+SELECT user_address, vault_address, event, 'subscription', id,
+       jsonb_build_object('threshold', threshold), triggered_at
+FROM notification_subscriptions
+WHERE triggered_at IS NOT NULL;
+
+INSERT INTO alert_triggers (user_address, vault_address, event, source, source_id, details, triggered_at)
+SELECT user_address, vault_address, 'apy_' || direction, 'rule', id,
+       jsonb_build_object('window', apy_window, 'direction', direction, 'threshold', threshold), triggered_at
+FROM apy_alert_rules
+WHERE triggered_at IS NOT NULL;
@@ -0,0 +1,8 @@
+-- 用户收藏的资金库，前端"收藏"标签页的数据来源
+CREATE TABLE IF NOT EXISTS vault_watchlist (
+    id SERIAL PRIMARY KEY,
+    user_address VARCHAR(42) NOT NULL,
+    vault_address VARCHAR(42) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_address, vault_address)
+);
@@ -0,0 +1,8 @@
+-- 每日平台汇总报告：TVL变化、资金净流入、APY变动、头部资金库和当日事故
+CREATE TABLE IF NOT EXISTS daily_reports (
+    id SERIAL PRIMARY KEY,
+    report_date DATE NOT NULL UNIQUE,
+    summary JSONB NOT NULL,
+    delivered_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
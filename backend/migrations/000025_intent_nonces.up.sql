@@ -0,0 +1,2 @@
+-- 存取款意向签名中已使用的最大nonce，新意向的nonce必须更大，截获的签名请求无法重放
+ALTER TABLE users ADD COLUMN IF NOT EXISTS intent_nonce BIGINT NOT NULL DEFAULT 0;
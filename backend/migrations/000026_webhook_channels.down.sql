@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS webhook_deliveries;
+
+DELETE FROM notification_channels WHERE type = 'webhook';
+ALTER TABLE notification_channels DROP COLUMN IF EXISTS secret;
+ALTER TABLE notification_channels DROP CONSTRAINT IF EXISTS notification_channels_type_check;
+ALTER TABLE notification_channels ADD CONSTRAINT notification_channels_type_check CHECK (type IN ('email', 'telegram'));
@@ -0,0 +1,20 @@
+-- webhook通知渠道：投递时以渠道密钥对请求体做HMAC-SHA256签名
+ALTER TABLE notification_channels DROP CONSTRAINT IF EXISTS notification_channels_type_check;
+ALTER TABLE notification_channels ADD CONSTRAINT notification_channels_type_check CHECK (type IN ('email', 'telegram', 'webhook'));
+ALTER TABLE notification_channels ADD COLUMN IF NOT EXISTS secret VARCHAR(80) NOT NULL DEFAULT '';
+
+-- webhook每次投递尝试的结果，status_code为0表示未收到响应
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    channel_id INTEGER NOT NULL REFERENCES notification_channels(id) ON DELETE CASCADE,
+    user_address VARCHAR(42) NOT NULL,
+    delivery_id VARCHAR(32) NOT NULL,
+    event VARCHAR(30) NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    attempted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_channel_time ON webhook_deliveries (channel_id, attempted_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_attempted_at ON webhook_deliveries (attempted_at);
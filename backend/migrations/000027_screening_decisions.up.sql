@@ -0,0 +1,16 @@
+-- 存款意向的制裁筛查结论，供合规复核；不随用户数据删除请求清除
+CREATE TABLE IF NOT EXISTS screening_decisions (
+    id BIGSERIAL PRIMARY KEY,
+    user_address VARCHAR(42) NOT NULL,
+    vault_address VARCHAR(42) NOT NULL,
+    decision VARCHAR(20) NOT NULL CHECK (decision IN ('allowed', 'blocked', 'unavailable')),
+    provider VARCHAR(30) NOT NULL,
+    identifications JSONB,
+    cached BOOLEAN NOT NULL DEFAULT FALSE,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_screening_decisions_user_address ON screening_decisions (user_address);
+CREATE INDEX IF NOT EXISTS idx_screening_decisions_created_at ON screening_decisions (created_at);
+CREATE INDEX IF NOT EXISTS idx_screening_decisions_decision ON screening_decisions (decision, created_at DESC) WHERE decision <> 'allowed';
@@ -0,0 +1,15 @@
+-- 链上状态(资金库资产、份额总量、用户份额)与数据库的对账报告
+CREATE TABLE IF NOT EXISTS reconciliation_reports (
+    id BIGSERIAL PRIMARY KEY,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('ok', 'discrepancies', 'incomplete')),
+    tolerance DOUBLE PRECISION NOT NULL,
+    vaults_checked INTEGER NOT NULL DEFAULT 0,
+    users_checked INTEGER NOT NULL DEFAULT 0,
+    discrepancy_count INTEGER NOT NULL DEFAULT 0,
+    discrepancies JSONB,
+    errors JSONB,
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_reconciliation_reports_started_at ON reconciliation_reports (started_at DESC, id DESC);
@@ -0,0 +1,76 @@
+-- 复式记账凭证：每笔存款、取款、收获和费用计提各记一张，借贷合计相等，只追加不修改
+CREATE TABLE IF NOT EXISTS ledger_journals (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('deposit', 'withdraw', 'harvest', 'fee')),
+    reference VARCHAR(100) NOT NULL,
+    vault_address VARCHAR(42) NOT NULL,
+    posted_at TIMESTAMP NOT NULL,
+    UNIQUE (kind, reference)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_journals_vault ON ledger_journals (vault_address, posted_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_ledger_journals_posted_at ON ledger_journals (posted_at DESC, id DESC);
+
+-- 凭证分录，金额为资金库底层资产数量
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id BIGSERIAL PRIMARY KEY,
+    journal_id BIGINT NOT NULL REFERENCES ledger_journals(id) ON DELETE RESTRICT,
+    account_type VARCHAR(20) NOT NULL CHECK (account_type IN ('vault', 'user', 'yield', 'treasury')),
+    account_owner VARCHAR(42) NOT NULL DEFAULT '',
+    vault_address VARCHAR(42) NOT NULL,
+    side VARCHAR(10) NOT NULL CHECK (side IN ('debit', 'credit')),
+    amount DECIMAL(36,18) NOT NULL CHECK (amount > 0),
+    posted_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_journal ON ledger_entries (journal_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_account ON ledger_entries (account_type, vault_address, account_owner);
+
+-- 按已有记录补记历史凭证
+-- 存款：借 资金库资产，贷 用户；取款相反
+WITH journals AS (
+    INSERT INTO ledger_journals (kind, reference, vault_address, posted_at)
+    SELECT type, tx_hash, vault_address, created_at
+    FROM transactions
+    WHERE status = 'confirmed' AND type IN ('deposit', 'withdraw') AND amount > 0
+    ON CONFLICT (kind, reference) DO NOTHING
+    RETURNING id, kind, reference, vault_address, posted_at
+)
+INSERT INTO ledger_entries (journal_id, account_type, account_owner, vault_address, side, amount, posted_at)
+SELECT j.id, e.account_type, e.account_owner, j.vault_address, e.side, t.amount, j.posted_at
+FROM journals j
+JOIN transactions t ON t.tx_hash = j.reference
+CROSS JOIN LATERAL (VALUES
+    ('vault', '', CASE WHEN j.kind = 'deposit' THEN 'debit' ELSE 'credit' END),
+    ('user', t.user_address, CASE WHEN j.kind = 'deposit' THEN 'credit' ELSE 'debit' END)
+) AS e(account_type, account_owner, side);
+
+-- 收获：借 资金库资产，贷 资金库收益
+WITH journals AS (
+    INSERT INTO ledger_journals (kind, reference, vault_address, posted_at)
+    SELECT 'harvest', tx_hash || '#' || log_index, vault_address, harvested_at
+    FROM harvests
+    WHERE amount > 0
+    ON CONFLICT (kind, reference) DO NOTHING
+    RETURNING id, reference, vault_address, posted_at
+)
+INSERT INTO ledger_entries (journal_id, account_type, account_owner, vault_address, side, amount, posted_at)
+SELECT j.id, e.account_type, '', j.vault_address, e.side, h.amount, j.posted_at
+FROM journals j
+JOIN harvests h ON h.tx_hash || '#' || h.log_index = j.reference
+CROSS JOIN (VALUES ('vault', 'debit'), ('yield', 'credit')) AS e(account_type, side);
+
+-- 费用计提：借 资金库收益，贷 平台费用
+WITH journals AS (
+    INSERT INTO ledger_journals (kind, reference, vault_address, posted_at)
+    SELECT 'fee', id::text, vault_address, period_end
+    FROM fee_accruals
+    WHERE amount > 0
+    ON CONFLICT (kind, reference) DO NOTHING
+    RETURNING id, reference, vault_address, posted_at
+)
+INSERT INTO ledger_entries (journal_id, account_type, account_owner, vault_address, side, amount, posted_at)
+SELECT j.id, e.account_type, '', j.vault_address, e.side, f.amount, j.posted_at
+FROM journals j
+JOIN fee_accruals f ON f.id::text = j.reference
+CROSS JOIN (VALUES ('yield', 'debit'), ('treasury', 'credit')) AS e(account_type, side);
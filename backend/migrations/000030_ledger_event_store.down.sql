@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS ledger_entries_append_only ON ledger_entries;
+DROP TRIGGER IF EXISTS ledger_journals_append_only ON ledger_journals;
+DROP FUNCTION IF EXISTS ledger_append_only();
+DROP INDEX IF EXISTS idx_ledger_journals_strategy;
+ALTER TABLE ledger_journals DROP COLUMN IF EXISTS strategy_address;
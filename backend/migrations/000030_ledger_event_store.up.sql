@@ -0,0 +1,35 @@
+-- 收获凭证记录策略，策略累计收益由凭证汇总
+ALTER TABLE ledger_journals ADD COLUMN IF NOT EXISTS strategy_address VARCHAR(42) NOT NULL DEFAULT '';
+
+UPDATE ledger_journals j
+SET strategy_address = h.strategy_address
+FROM harvests h
+WHERE j.kind = 'harvest' AND j.reference = h.tx_hash || '#' || h.log_index;
+
+CREATE INDEX IF NOT EXISTS idx_ledger_journals_strategy ON ledger_journals (strategy_address) WHERE kind = 'harvest';
+
+-- 账本只追加：拒绝修改或删除已记录的凭证和分录，更正须另记一张凭证
+CREATE OR REPLACE FUNCTION ledger_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION '% is append-only', TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS ledger_journals_append_only ON ledger_journals;
+CREATE TRIGGER ledger_journals_append_only BEFORE UPDATE OR DELETE ON ledger_journals
+    FOR EACH ROW EXECUTE FUNCTION ledger_append_only();
+
+DROP TRIGGER IF EXISTS ledger_entries_append_only ON ledger_entries;
+CREATE TRIGGER ledger_entries_append_only BEFORE UPDATE OR DELETE ON ledger_entries
+    FOR EACH ROW EXECUTE FUNCTION ledger_append_only();
+
+-- 按账本重算已有的汇总字段
+UPDATE vaults v SET
+    total_deposits = COALESCE((SELECT SUM(e.amount) FROM ledger_entries e JOIN ledger_journals j ON j.id = e.journal_id
+        WHERE e.side = 'debit' AND j.kind = 'deposit' AND j.vault_address = v.address), 0),
+    total_withdrawals = COALESCE((SELECT SUM(e.amount) FROM ledger_entries e JOIN ledger_journals j ON j.id = e.journal_id
+        WHERE e.side = 'debit' AND j.kind = 'withdraw' AND j.vault_address = v.address), 0);
+
+UPDATE strategies s SET
+    total_earnings = COALESCE((SELECT SUM(e.amount) FROM ledger_entries e JOIN ledger_journals j ON j.id = e.journal_id
+        WHERE e.side = 'debit' AND j.kind = 'harvest' AND j.strategy_address = s.address), 0);
@@ -0,0 +1,12 @@
+-- 资金库未来几天APY的统计预测，每个资金库只保留最近一次结果
+CREATE TABLE IF NOT EXISTS apy_forecasts (
+    id SERIAL PRIMARY KEY,
+    vault_address VARCHAR(42) NOT NULL UNIQUE,
+    method VARCHAR(20) NOT NULL,
+    alpha DOUBLE PRECISION NOT NULL,
+    confidence DOUBLE PRECISION NOT NULL,
+    samples INTEGER NOT NULL,
+    sigma DOUBLE PRECISION NOT NULL,
+    points JSONB NOT NULL,
+    generated_at TIMESTAMP NOT NULL
+);
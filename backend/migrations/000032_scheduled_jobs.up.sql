@@ -0,0 +1,18 @@
+-- worker定时任务的调度状态，API进程通过 paused 和 trigger_requested_at 控制任务
+CREATE TABLE IF NOT EXISTS scheduled_jobs (
+    name VARCHAR(50) PRIMARY KEY,
+    schedule VARCHAR(100) NOT NULL,
+    timeout BIGINT NOT NULL DEFAULT 0,
+    paused BOOLEAN NOT NULL DEFAULT FALSE,
+    running BOOLEAN NOT NULL DEFAULT FALSE,
+    trigger_requested_at TIMESTAMP,
+    next_run_at TIMESTAMP,
+    last_started_at TIMESTAMP,
+    last_finished_at TIMESTAMP,
+    last_status VARCHAR(20) NOT NULL DEFAULT '',
+    last_error TEXT NOT NULL DEFAULT '',
+    last_duration_ms BIGINT NOT NULL DEFAULT 0,
+    runs BIGINT NOT NULL DEFAULT 0,
+    failures BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,22 @@
+-- 持久化的异步任务队列，worker重启后未完成的任务不会丢失
+CREATE TABLE IF NOT EXISTS queue_jobs (
+    id SERIAL PRIMARY KEY,
+    kind VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    unique_key VARCHAR(100),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL,
+    run_at TIMESTAMP NOT NULL,
+    lease_until TIMESTAMP,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_queue_jobs_kind_status ON queue_jobs(kind, status);
+CREATE INDEX IF NOT EXISTS idx_queue_jobs_status_run_at ON queue_jobs(status, run_at);
+
+-- 同一 unique_key 只允许一个未结束的任务，重复入队时 ON CONFLICT DO NOTHING
+CREATE UNIQUE INDEX IF NOT EXISTS idx_queue_jobs_unique_active ON queue_jobs(unique_key)
+    WHERE unique_key IS NOT NULL AND status IN ('available', 'running');
@@ -0,0 +1,16 @@
+-- 合约ABI登记，回放和适配器按 链ID+地址 查找解码事件所需的ABI
+CREATE TABLE IF NOT EXISTS contracts (
+    id SERIAL PRIMARY KEY,
+    chain_id INTEGER NOT NULL,
+    address VARCHAR(42) NOT NULL,
+    interface VARCHAR(20) NOT NULL,
+    name VARCHAR(100) NOT NULL DEFAULT '',
+    abi JSONB NOT NULL,
+    deployment_block BIGINT NOT NULL DEFAULT 0,
+    uploaded_by VARCHAR(42) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contracts_chain_address ON contracts(chain_id, address);
+CREATE INDEX IF NOT EXISTS idx_contracts_interface ON contracts(interface);
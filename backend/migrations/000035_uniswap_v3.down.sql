@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS uniswap_v3_snapshots;
+DROP TABLE IF EXISTS uniswap_v3_positions;
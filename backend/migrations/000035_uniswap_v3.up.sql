@@ -0,0 +1,36 @@
+-- Uniswap v3集中流动性策略的头寸登记和定期快照，用于手续费APR和区间状态
+CREATE TABLE IF NOT EXISTS uniswap_v3_positions (
+    strategy_address VARCHAR(42) PRIMARY KEY,
+    position_manager VARCHAR(42) NOT NULL,
+    token_id VARCHAR(78) NOT NULL,
+    pool VARCHAR(42) NOT NULL,
+    token0 VARCHAR(42) NOT NULL,
+    token1 VARCHAR(42) NOT NULL,
+    decimals0 INTEGER NOT NULL,
+    decimals1 INTEGER NOT NULL,
+    fee INTEGER NOT NULL,
+    tick_lower INTEGER NOT NULL,
+    tick_upper INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS uniswap_v3_snapshots (
+    id SERIAL PRIMARY KEY,
+    strategy_address VARCHAR(42) NOT NULL,
+    block_number BIGINT NOT NULL,
+    tick INTEGER NOT NULL,
+    in_range BOOLEAN NOT NULL,
+    liquidity DECIMAL(39,0) NOT NULL,
+    amount0 DECIMAL(36,18) NOT NULL,
+    amount1 DECIMAL(36,18) NOT NULL,
+    fee_growth_inside0 DECIMAL(78,0) NOT NULL,
+    fee_growth_inside1 DECIMAL(78,0) NOT NULL,
+    fees0 DECIMAL(36,18) NOT NULL,
+    fees1 DECIMAL(36,18) NOT NULL,
+    price0_usd DOUBLE PRECISION,
+    price1_usd DOUBLE PRECISION,
+    timestamp TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_uniswap_v3_snapshots_strategy_time ON uniswap_v3_snapshots(strategy_address, timestamp);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS morpho_markets;
@@ -0,0 +1,23 @@
+-- Morpho Blue借贷策略登记的市场及最近一次读取的利率、利用率和预言机价格
+CREATE TABLE IF NOT EXISTS morpho_markets (
+    strategy_address VARCHAR(42) PRIMARY KEY,
+    morpho VARCHAR(42) NOT NULL,
+    market_id VARCHAR(66) NOT NULL,
+    loan_token VARCHAR(42) NOT NULL,
+    collateral_token VARCHAR(42) NOT NULL,
+    oracle VARCHAR(42) NOT NULL,
+    irm VARCHAR(42) NOT NULL,
+    lltv DECIMAL(10,8) NOT NULL,
+    loan_decimals INTEGER NOT NULL,
+    collateral_decimals INTEGER NOT NULL,
+    supply_apy DECIMAL(10,8) NOT NULL DEFAULT 0,
+    borrow_apy DECIMAL(10,8) NOT NULL DEFAULT 0,
+    utilization DECIMAL(10,8) NOT NULL DEFAULT 0,
+    total_supply_assets DECIMAL(36,18) NOT NULL DEFAULT 0,
+    total_borrow_assets DECIMAL(36,18) NOT NULL DEFAULT 0,
+    oracle_price DOUBLE PRECISION,
+    block_number BIGINT NOT NULL DEFAULT 0,
+    synced_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
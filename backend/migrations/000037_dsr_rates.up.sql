@@ -0,0 +1,12 @@
+-- Maker DSR和sDAI兑换率的定期读取，用于DSR策略APY和实际收益率
+CREATE TABLE IF NOT EXISTS dsr_rates (
+    id SERIAL PRIMARY KEY,
+    chain_id INTEGER NOT NULL,
+    apy DECIMAL(10,8) NOT NULL,
+    chi DECIMAL(36,27) NOT NULL,
+    sdai_rate DECIMAL(36,18),
+    block_number BIGINT NOT NULL,
+    timestamp TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_dsr_rates_chain_time ON dsr_rates(chain_id, timestamp);
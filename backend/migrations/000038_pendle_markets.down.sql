@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS pendle_markets;
+ALTER TABLE strategies DROP COLUMN IF EXISTS maturity;
@@ -0,0 +1,17 @@
+-- 固定利率策略：策略到期时间及持有PT的Pendle市场
+ALTER TABLE strategies ADD COLUMN IF NOT EXISTS maturity TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS pendle_markets (
+    strategy_address VARCHAR(42) PRIMARY KEY,
+    market VARCHAR(42) NOT NULL,
+    sy VARCHAR(42) NOT NULL,
+    pt VARCHAR(42) NOT NULL,
+    yt VARCHAR(42) NOT NULL,
+    expiry TIMESTAMP NOT NULL,
+    implied_apy DECIMAL(10,8) NOT NULL DEFAULT 0,
+    pt_price DECIMAL(20,18) NOT NULL DEFAULT 0,
+    block_number BIGINT NOT NULL DEFAULT 0,
+    synced_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
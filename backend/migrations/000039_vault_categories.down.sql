@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_vaults_category;
+ALTER TABLE vaults DROP COLUMN IF EXISTS tags;
+ALTER TABLE vaults DROP COLUMN IF EXISTS category;
@@ -0,0 +1,5 @@
+-- 资金库分类和标签，列表接口按 ?category= / ?tags= 筛选
+ALTER TABLE vaults ADD COLUMN IF NOT EXISTS category VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE vaults ADD COLUMN IF NOT EXISTS tags JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_vaults_category ON vaults(category);
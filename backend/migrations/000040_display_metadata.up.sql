@@ -0,0 +1,13 @@
+-- 资金库和策略的展示资料：简介、logo、协议链接、审计报告和上线日期
+CREATE TABLE IF NOT EXISTS display_metadata (
+    address VARCHAR(42) PRIMARY KEY,
+    kind VARCHAR(10) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    logo_url VARCHAR(512) NOT NULL DEFAULT '',
+    links JSONB,
+    audits JSONB,
+    inception_date DATE,
+    updated_by VARCHAR(42) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
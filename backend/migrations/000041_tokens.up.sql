@@ -0,0 +1,15 @@
+-- 代币登记表：资金库底层资产和价格配置中代币的链上元数据
+CREATE TABLE IF NOT EXISTS tokens (
+    chain_id BIGINT NOT NULL,
+    address VARCHAR(42) NOT NULL,
+    symbol VARCHAR(32) NOT NULL DEFAULT '',
+    name VARCHAR(100) NOT NULL DEFAULT '',
+    decimals INTEGER NOT NULL,
+    logo_url VARCHAR(512) NOT NULL DEFAULT '',
+    coingecko_id VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (chain_id, address)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tokens_symbol ON tokens(symbol);
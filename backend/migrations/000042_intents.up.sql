@@ -0,0 +1,22 @@
+-- 存取款意向：生成的交易、滑点和截止时间约束，以及用户上报的执行结果
+CREATE TABLE IF NOT EXISTS intents (
+    id BIGSERIAL PRIMARY KEY,
+    user_address VARCHAR(42) NOT NULL,
+    vault_address VARCHAR(42) NOT NULL,
+    chain_id BIGINT NOT NULL,
+    kind VARCHAR(10) NOT NULL,
+    amount_wei VARCHAR(78) NOT NULL,
+    min_out_wei VARCHAR(78) NOT NULL DEFAULT '',
+    deadline TIMESTAMP,
+    to_address VARCHAR(42) NOT NULL,
+    data TEXT NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending',
+    tx_hash VARCHAR(66) NOT NULL DEFAULT '',
+    out_wei VARCHAR(78) NOT NULL DEFAULT '',
+    reject_reason VARCHAR(200) NOT NULL DEFAULT '',
+    executed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_intents_user_address ON intents(user_address);
@@ -0,0 +1,19 @@
+-- 存款报价：请求参数固定，预期结果可刷新，过期或已使用的报价不能再用于存款
+CREATE TABLE IF NOT EXISTS quotes (
+    id VARCHAR(34) PRIMARY KEY,
+    kind VARCHAR(10) NOT NULL,
+    user_address VARCHAR(42) NOT NULL,
+    vault_address VARCHAR(42) NOT NULL,
+    chain_id BIGINT NOT NULL,
+    token_in VARCHAR(42) NOT NULL,
+    amount DECIMAL(36,18) NOT NULL,
+    slippage_bps INTEGER NOT NULL,
+    payload JSONB NOT NULL,
+    refreshes INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_quotes_user_address ON quotes(user_address);
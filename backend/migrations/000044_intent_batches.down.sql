@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_intents_batch_id;
+ALTER TABLE intents DROP COLUMN IF EXISTS batch_id;
@@ -0,0 +1,4 @@
+-- 批量存款：同一次签名拆分到多个资金库的存款意向共用一个 batch_id，为空表示单笔意向
+ALTER TABLE intents ADD COLUMN IF NOT EXISTS batch_id VARCHAR(34) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_intents_batch_id ON intents(batch_id);
@@ -0,0 +1,22 @@
+-- 用户持仓的调仓建议，每个持仓最多一条，每次评估整体替换
+CREATE TABLE IF NOT EXISTS rebalance_suggestions (
+    id BIGSERIAL PRIMARY KEY,
+    user_address VARCHAR(42) NOT NULL,
+    from_vault VARCHAR(42) NOT NULL,
+    to_vault VARCHAR(42) NOT NULL,
+    chain_id BIGINT NOT NULL,
+    assets DECIMAL(36,18) NOT NULL,
+    value_usd DOUBLE PRECISION NOT NULL,
+    current_net_apy DECIMAL(10,8) NOT NULL,
+    target_net_apy DECIMAL(10,8) NOT NULL,
+    current_risk_score DOUBLE PRECISION NOT NULL,
+    target_risk_score DOUBLE PRECISION NOT NULL,
+    gas_cost_usd DOUBLE PRECISION NOT NULL,
+    annual_gain_usd DOUBLE PRECISION NOT NULL,
+    break_even_days DOUBLE PRECISION NOT NULL,
+    notified_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_rebalance_suggestions_user_vault ON rebalance_suggestions(user_address, from_vault);
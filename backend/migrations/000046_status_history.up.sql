@@ -0,0 +1,13 @@
+-- 公开状态页的组件状态历史，每行为组件处于某一状态的一段时间，ended_at 为空表示当前状态
+CREATE TABLE IF NOT EXISTS status_history (
+    id BIGSERIAL PRIMARY KEY,
+    component VARCHAR(40) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    message VARCHAR(255) NOT NULL DEFAULT '',
+    started_at TIMESTAMP NOT NULL,
+    checked_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_status_history_component_started ON status_history(component, started_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_status_history_current ON status_history(component) WHERE ended_at IS NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS operator_alerts;
+DROP TABLE IF EXISTS incident_updates;
+DROP TABLE IF EXISTS incidents;
@@ -0,0 +1,39 @@
+-- 事件记录：状态检查持续失败时自动创建，也可由管理员创建，用于复盘
+CREATE TABLE IF NOT EXISTS incidents (
+    id BIGSERIAL PRIMARY KEY,
+    component VARCHAR(40) NOT NULL,
+    severity VARCHAR(10) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    title VARCHAR(200) NOT NULL,
+    automatic BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by VARCHAR(42) NOT NULL DEFAULT '',
+    started_at TIMESTAMP NOT NULL,
+    resolved_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_incidents_component ON incidents(component);
+CREATE INDEX IF NOT EXISTS idx_incidents_open ON incidents(component) WHERE resolved_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS incident_updates (
+    id BIGSERIAL PRIMARY KEY,
+    incident_id BIGINT NOT NULL REFERENCES incidents(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL,
+    message TEXT NOT NULL,
+    author VARCHAR(42) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_incident_updates_incident_id ON incident_updates(incident_id);
+
+-- 运维告警记录，由事件产生的告警关联到该事件
+CREATE TABLE IF NOT EXISTS operator_alerts (
+    id BIGSERIAL PRIMARY KEY,
+    incident_id BIGINT REFERENCES incidents(id) ON DELETE SET NULL,
+    subject VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_operator_alerts_incident_id ON operator_alerts(incident_id);
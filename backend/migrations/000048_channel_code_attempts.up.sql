@@ -0,0 +1,2 @@
+-- 通知渠道验证码的已尝试次数，达到上限后验证码失效，需重新登记获取新验证码
+ALTER TABLE notification_channels ADD COLUMN IF NOT EXISTS verification_attempts INTEGER NOT NULL DEFAULT 0;
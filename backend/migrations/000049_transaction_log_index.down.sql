@@ -0,0 +1,6 @@
+-- 回滚后不恢复 tx_hash 唯一约束：迁移后同一交易可能已有多条事件记录，去重会删除已记账的存取款。
+-- 只补回 tx_hash 上的普通索引，旧版本按 tx_hash 查重仍然有效，但不再由数据库保证唯一
+DROP INDEX IF EXISTS idx_transactions_tx_unindexed;
+DROP INDEX IF EXISTS idx_transactions_tx_log;
+ALTER TABLE transactions DROP COLUMN IF EXISTS log_index;
+CREATE INDEX IF NOT EXISTS idx_transactions_tx_hash ON transactions (tx_hash);
@@ -0,0 +1,8 @@
+-- 一笔链上交易可以包含多个存取款事件(如批量存款的 multicall)，事件按 (tx_hash, log_index) 去重。
+-- 不来自事件的记录(调仓、跨链源链交易)和迁移前的记录 log_index 为空，仍按 tx_hash 唯一
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_tx_hash_key;
+DROP INDEX IF EXISTS idx_transactions_tx_hash;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS log_index INTEGER;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_tx_log ON transactions (tx_hash, log_index);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_tx_unindexed ON transactions (tx_hash) WHERE log_index IS NULL;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_apy_alert_rules_rule;
@@ -0,0 +1,8 @@
+-- 用户在同一资金库上不能有完全相同的告警规则，创建规则时按该索引 ON CONFLICT DO NOTHING 识别重复。
+-- 先删除重复的规则，保留最早创建的一条
+DELETE FROM apy_alert_rules a USING apy_alert_rules d
+WHERE a.user_address = d.user_address AND a.vault_address = d.vault_address
+  AND a.apy_window = d.apy_window AND a.direction = d.direction AND a.threshold = d.threshold AND a.id > d.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_apy_alert_rules_rule
+    ON apy_alert_rules (user_address, vault_address, apy_window, direction, threshold);
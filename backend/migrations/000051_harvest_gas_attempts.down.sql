@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_harvests_gas_pending;
+CREATE INDEX IF NOT EXISTS idx_harvests_gas_pending ON harvests(id) WHERE gas_used IS NULL;
+ALTER TABLE harvests DROP COLUMN IF EXISTS gas_attempts;
@@ -0,0 +1,5 @@
+-- 补齐gas时取不到回执的次数(节点已裁剪、交易被重组或链配置错误)，按次数排序让新的收获优先，达到上限后不再尝试
+ALTER TABLE harvests ADD COLUMN IF NOT EXISTS gas_attempts INTEGER NOT NULL DEFAULT 0;
+
+DROP INDEX IF EXISTS idx_harvests_gas_pending;
+CREATE INDEX IF NOT EXISTS idx_harvests_gas_pending ON harvests(gas_attempts, id) WHERE gas_used IS NULL;
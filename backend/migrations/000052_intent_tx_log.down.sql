@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_intents_tx_log;
+ALTER TABLE intents DROP COLUMN IF EXISTS log_index;
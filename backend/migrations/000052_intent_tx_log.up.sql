@@ -0,0 +1,5 @@
+-- 意向执行结果对应的存取款事件：同一笔交易的同一个事件只能结算一个意向，multicall批量存款的各个事件分别结算。
+-- 之前结算的意向不知道对应哪个事件，log_index 留空，它们的交易不能再结算其他意向
+ALTER TABLE intents ADD COLUMN IF NOT EXISTS log_index INTEGER;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_intents_tx_log ON intents(chain_id, tx_hash, log_index) WHERE tx_hash <> '';
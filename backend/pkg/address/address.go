@@ -0,0 +1,37 @@
+package address
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	ErrInvalid         = errors.New("invalid Ethereum address")
+	ErrInvalidChecksum = errors.New("invalid EIP-55 address checksum")
+)
+
+// Normalize 校验地址并转为小写，数据库存储和查询统一使用小写地址。
+// 全小写或全大写视为不带校验和；大小写混合时必须符合EIP-55，避免手误输入的地址被当作新地址
+func Normalize(value string) (string, error) {
+	if len(value) != 42 || !strings.HasPrefix(value, "0x") || !common.IsHexAddress(value) {
+		return "", ErrInvalid
+	}
+
+	hex := value[2:]
+	if hex != strings.ToLower(hex) && hex != strings.ToUpper(hex) && Checksum(value) != value {
+		return "", ErrInvalidChecksum
+	}
+	return strings.ToLower(value), nil
+}
+
+// Checksum 返回EIP-55校验和格式的地址，用于展示
+func Checksum(value string) string {
+	return common.HexToAddress(value).Hex()
+}
+
+// Equal 按地址而不是字符串比较
+func Equal(a, b string) bool {
+	return strings.EqualFold(a, b)
+}
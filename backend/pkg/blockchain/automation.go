@@ -0,0 +1,107 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	getUpkeepSelector     = crypto.Keccak256([]byte("getUpkeep(uint256)"))[:4]
+	getMinBalanceSelector = crypto.Keccak256([]byte("getMinBalanceForUpkeep(uint256)"))[:4]
+	getTaskIdsSelector    = crypto.Keccak256([]byte("getTaskIdsByUser(address)"))[:4]
+)
+
+// Upkeep Chainlink Automation 注册表(v2.1)中的upkeep状态，余额单位为LINK的最小单位
+type Upkeep struct {
+	Target                   string
+	Balance                  *big.Int
+	MinBalance               *big.Int
+	Paused                   bool
+	Cancelled                bool   // 取消后 maxValidBlocknumber 被设为取消区块，未取消时为 uint32 最大值
+	LastPerformedBlockNumber uint64 // 从未执行时为0
+}
+
+// ChainlinkUpkeep 读取upkeep的状态，不存在的ID返回nil
+func ChainlinkUpkeep(ctx context.Context, chainID uint, registry string, id *big.Int) (*Upkeep, error) {
+	out, err := Call(ctx, chainID, registry, encodeUint(getUpkeepSelector, id))
+	if err != nil {
+		return nil, fmt.Errorf("getUpkeep(%s): %w", id, err)
+	}
+	// 返回值为包含动态字段的结构体：第一个字为结构体偏移，之后依次为
+	// target, performGas, checkData(偏移), balance, admin, maxValidBlocknumber,
+	// lastPerformedBlockNumber, amountSpent, paused, offchainConfig(偏移)
+	if len(out) < 32 {
+		return nil, fmt.Errorf("unexpected getUpkeep(%s) result", id)
+	}
+	base := new(big.Int).SetBytes(out[:32])
+	if !base.IsUint64() || base.Uint64()+10*32 > uint64(len(out)) {
+		return nil, fmt.Errorf("unexpected getUpkeep(%s) result", id)
+	}
+	word := func(i uint64) []byte {
+		start := base.Uint64() + i*32
+		return out[start : start+32]
+	}
+
+	target := common.BytesToAddress(word(0))
+	if target == (common.Address{}) {
+		return nil, nil
+	}
+	upkeep := &Upkeep{
+		Target:                   strings.ToLower(target.Hex()),
+		Balance:                  new(big.Int).SetBytes(word(3)),
+		Cancelled:                new(big.Int).SetBytes(word(5)).Uint64() != math.MaxUint32,
+		LastPerformedBlockNumber: new(big.Int).SetBytes(word(6)).Uint64(),
+		Paused:                   new(big.Int).SetBytes(word(8)).Sign() != 0,
+	}
+
+	out, err = Call(ctx, chainID, registry, encodeUint(getMinBalanceSelector, id))
+	if err != nil {
+		return nil, fmt.Errorf("getMinBalanceForUpkeep(%s): %w", id, err)
+	}
+	if len(out) < 32 {
+		return nil, fmt.Errorf("unexpected getMinBalanceForUpkeep(%s) result", id)
+	}
+	upkeep.MinBalance = new(big.Int).SetBytes(out[:32])
+	return upkeep, nil
+}
+
+// GelatoTaskIDs 读取 Gelato Automate 合约中 owner 创建且未取消的任务ID(小写bytes32)
+func GelatoTaskIDs(ctx context.Context, chainID uint, automate, owner string) ([]string, error) {
+	data := make([]byte, 0, 36)
+	data = append(data, getTaskIdsSelector...)
+	data = append(data, common.LeftPadBytes(common.HexToAddress(owner).Bytes(), 32)...)
+
+	out, err := Call(ctx, chainID, automate, data)
+	if err != nil {
+		return nil, fmt.Errorf("getTaskIdsByUser(%s): %w", owner, err)
+	}
+	if len(out) < 64 {
+		return nil, fmt.Errorf("unexpected getTaskIdsByUser(%s) result", owner)
+	}
+	offset := new(big.Int).SetBytes(out[:32])
+	if !offset.IsUint64() || offset.Uint64()+32 > uint64(len(out)) {
+		return nil, fmt.Errorf("unexpected getTaskIdsByUser(%s) result", owner)
+	}
+	start := offset.Uint64() + 32
+	count := new(big.Int).SetBytes(out[start-32 : start])
+	if !count.IsUint64() || start+count.Uint64()*32 > uint64(len(out)) {
+		return nil, fmt.Errorf("unexpected getTaskIdsByUser(%s) result", owner)
+	}
+
+	ids := make([]string, 0, count.Uint64())
+	for i := uint64(0); i < count.Uint64(); i++ {
+		ids = append(ids, common.BytesToHash(out[start+i*32:start+(i+1)*32]).Hex())
+	}
+	return ids, nil
+}
+
+func encodeUint(selector []byte, value *big.Int) []byte {
+	data := make([]byte, 0, len(selector)+32)
+	return append(append(data, selector...), common.LeftPadBytes(value.Bytes(), 32)...)
+}
@@ -0,0 +1,226 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/resilience"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	clients = make(map[uint]*ethclient.Client)
+	mutex   sync.Mutex
+)
+
+// RPC地址变化时关闭旧连接，下次调用按新地址重连
+func init() {
+	config.Subscribe(func(prev, next *config.Config) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		for chainID, client := range clients {
+			if prev.Blockchain.RPCURL(chainID) != next.Blockchain.RPCURL(chainID) {
+				client.Close()
+				delete(clients, chainID)
+				logger.Info(fmt.Sprintf("🔗 RPC for chain %d changed, reconnecting on next call", chainID))
+			}
+		}
+	})
+}
+
+// GetClient 获取指定链的RPC客户端，首次调用时建立连接。模拟链模式下返回 ErrMockClient
+func GetClient(chainID uint) (*ethclient.Client, error) {
+	if MockEnabled() {
+		return nil, ErrMockClient
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if client, ok := clients[chainID]; ok {
+		return client, nil
+	}
+
+	url := config.Load().Blockchain.RPCURL(chainID)
+	if url == "" {
+		return nil, fmt.Errorf("no RPC configured for chain %d", chainID)
+	}
+
+	client, err := ethclient.Dial(url)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to chain %d RPC: %v", chainID, err))
+		return nil, err
+	}
+
+	logger.Info(fmt.Sprintf("🔗 Connected to chain %d RPC", chainID))
+	clients[chainID] = client
+	return client, nil
+}
+
+// Call 对合约发起只读调用，受RPC限流约束
+func Call(ctx context.Context, chainID uint, to string, data []byte) ([]byte, error) {
+	if MockEnabled() {
+		return mockCall(to, data)
+	}
+	contract := common.HexToAddress(to)
+	var result []byte
+	err := do(ctx, chainID, func(ctx context.Context, client *ethclient.Client) (err error) {
+		result, err = client.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, nil)
+		return err
+	})
+	return result, err
+}
+
+// CallAt 在指定区块高度上发起只读调用，同一区块的结果不会变化，便于调用方按区块缓存
+func CallAt(ctx context.Context, chainID uint, to string, data []byte, block uint64) ([]byte, error) {
+	if MockEnabled() {
+		return mockCall(to, data)
+	}
+	contract := common.HexToAddress(to)
+	var result []byte
+	err := do(ctx, chainID, func(ctx context.Context, client *ethclient.Client) (err error) {
+		result, err = client.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, new(big.Int).SetUint64(block))
+		return err
+	})
+	return result, err
+}
+
+// BlockNumber 获取链上最新区块高度
+func BlockNumber(ctx context.Context, chainID uint) (uint64, error) {
+	if MockEnabled() {
+		return mockBlockNumber(), nil
+	}
+	var block uint64
+	err := do(ctx, chainID, func(ctx context.Context, client *ethclient.Client) (err error) {
+		block, err = client.BlockNumber(ctx)
+		return err
+	})
+	return block, err
+}
+
+// GasPrice 获取链上建议的gas价格(wei)
+func GasPrice(ctx context.Context, chainID uint) (*big.Int, error) {
+	if MockEnabled() {
+		return big.NewInt(mockGasPrice), nil
+	}
+	var price *big.Int
+	err := do(ctx, chainID, func(ctx context.Context, client *ethclient.Client) (err error) {
+		price, err = client.SuggestGasPrice(ctx)
+		return err
+	})
+	return price, err
+}
+
+// TransactionReceipt 获取交易回执，交易未上链时返回 ethereum.NotFound。模拟链上所有交易立即确认
+func TransactionReceipt(ctx context.Context, chainID uint, txHash string) (*types.Receipt, error) {
+	if MockEnabled() {
+		return mockReceipt(txHash), nil
+	}
+	var receipt *types.Receipt
+	err := do(ctx, chainID, func(ctx context.Context, client *ethclient.Client) (err error) {
+		receipt, err = client.TransactionReceipt(ctx, common.HexToHash(txHash))
+		return err
+	})
+	return receipt, err
+}
+
+// TransactionSender 获取交易的发送地址(小写)，交易不存在时返回 ethereum.NotFound
+func TransactionSender(ctx context.Context, chainID uint, txHash string) (string, error) {
+	_, from, err := TransactionByHash(ctx, chainID, txHash)
+	return from, err
+}
+
+// TransactionByHash 获取交易及其发送地址(小写)，交易不存在时返回 ethereum.NotFound
+func TransactionByHash(ctx context.Context, chainID uint, txHash string) (*types.Transaction, string, error) {
+	var tx *types.Transaction
+	err := do(ctx, chainID, func(ctx context.Context, client *ethclient.Client) (err error) {
+		tx, _, err = client.TransactionByHash(ctx, common.HexToHash(txHash))
+		return err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return nil, "", err
+	}
+	return tx, strings.ToLower(from.Hex()), nil
+}
+
+// BlockTime 获取区块的出块时间。模拟链按出块间隔从区块高度反推
+func BlockTime(ctx context.Context, chainID uint, block uint64) (time.Time, error) {
+	if MockEnabled() {
+		return mockBlockTime(block), nil
+	}
+	var header *types.Header
+	err := do(ctx, chainID, func(ctx context.Context, client *ethclient.Client) (err error) {
+		header, err = client.HeaderByNumber(ctx, new(big.Int).SetUint64(block))
+		return err
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(int64(header.Time), 0), nil
+}
+
+// do 经过限流、重试和熔断执行一次RPC读取，依赖名为 rpc:<chainID>
+func do(ctx context.Context, chainID uint, fn func(ctx context.Context, client *ethclient.Client) error) error {
+	return resilience.Do(ctx, fmt.Sprintf("rpc:%d", chainID), func(ctx context.Context) error {
+		if err := Wait(ctx, chainID); err != nil {
+			return resilience.Permanent(err)
+		}
+		client, err := GetClient(chainID)
+		if err != nil {
+			return err
+		}
+		return classify(fn(ctx, client))
+	})
+}
+
+// 节点服务商的限流错误码，需要退避重试
+const rpcLimitExceeded = -32005
+
+// classify 区分节点故障和节点正常给出的错误：交易未上链、合约revert等JSON-RPC错误以及除429外的4xx不重试
+func classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, ethereum.NotFound) {
+		return resilience.Permanent(err)
+	}
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		if httpErr.StatusCode >= 400 && httpErr.StatusCode < 500 && httpErr.StatusCode != 429 {
+			return resilience.Permanent(err)
+		}
+		return err
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() != rpcLimitExceeded {
+		return resilience.Permanent(err)
+	}
+	return err
+}
+
+// Close 关闭所有RPC连接
+func Close() {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	for chainID, client := range clients {
+		client.Close()
+		delete(clients, chainID)
+	}
+}
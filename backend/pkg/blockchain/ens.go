@@ -0,0 +1,186 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/pkg/cache"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ENS 只部署在以太坊主网
+const ensChainID = 1
+
+var (
+	resolverSelector = crypto.Keccak256([]byte("resolver(bytes32)"))[:4]
+	addrSelector     = crypto.Keccak256([]byte("addr(bytes32)"))[:4]
+	nameSelector     = crypto.Keccak256([]byte("name(bytes32)"))[:4]
+
+	ErrENSDisabled = errors.New("ens resolution is disabled")
+	ErrENSNotFound = errors.New("ens name not found")
+)
+
+// IsENSName 判断路径参数是否为ENS名称而不是十六进制地址
+func IsENSName(value string) bool {
+	if strings.HasPrefix(value, "0x") || !strings.Contains(value, ".") {
+		return false
+	}
+	for _, label := range strings.Split(value, ".") {
+		if label == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// ResolveENS 将ENS名称解析为小写地址，结果(包括未注册)按 blockchain.ens_cache_ttl 缓存
+func ResolveENS(ctx context.Context, name string) (string, error) {
+	cfg := config.Load().Blockchain
+	if cfg.ENSRegistry == "" || cfg.Mock.Enabled {
+		return "", ErrENSDisabled
+	}
+	name = strings.ToLower(strings.TrimSpace(name))
+	key := "ens:resolve:" + name
+
+	var address string
+	if !cache.GetJSON(ctx, key, &address) {
+		var err error
+		address, err = resolveENS(ctx, cfg.ENSRegistry, name)
+		if err != nil {
+			return "", err
+		}
+		cache.SetJSON(ctx, key, address, time.Duration(cfg.ENSCacheTTL)*time.Second)
+	}
+
+	if address == "" {
+		return "", ErrENSNotFound
+	}
+	return address, nil
+}
+
+// LookupENS 反向解析地址的主ENS名称，未设置或正向解析不一致时返回空字符串
+func LookupENS(ctx context.Context, address string) (string, error) {
+	cfg := config.Load().Blockchain
+	if cfg.ENSRegistry == "" || cfg.Mock.Enabled {
+		return "", nil
+	}
+	address = strings.ToLower(address)
+	key := "ens:reverse:" + address
+
+	var name string
+	if cache.GetJSON(ctx, key, &name) {
+		return name, nil
+	}
+
+	name, err := lookupENS(ctx, cfg.ENSRegistry, address)
+	if err != nil {
+		return "", err
+	}
+	// 反向记录可由任何人设置，必须能正向解析回同一地址才可信
+	if name != "" {
+		resolved, err := ResolveENS(ctx, name)
+		if err != nil && !errors.Is(err, ErrENSNotFound) {
+			return "", err
+		}
+		if resolved != address {
+			name = ""
+		}
+	}
+
+	cache.SetJSON(ctx, key, name, time.Duration(cfg.ENSCacheTTL)*time.Second)
+	return name, nil
+}
+
+func resolveENS(ctx context.Context, registry, name string) (string, error) {
+	node := namehash(name)
+	resolver, err := ensResolver(ctx, registry, node)
+	if err != nil || resolver == "" {
+		return "", err
+	}
+
+	out, err := Call(ctx, ensChainID, resolver, encodeNode(addrSelector, node))
+	if err != nil {
+		return "", fmt.Errorf("addr(%s): %w", name, err)
+	}
+	if len(out) < 32 {
+		return "", nil
+	}
+	address := common.BytesToAddress(out[:32])
+	if address == (common.Address{}) {
+		return "", nil
+	}
+	return strings.ToLower(address.Hex()), nil
+}
+
+func lookupENS(ctx context.Context, registry, address string) (string, error) {
+	node := namehash(strings.TrimPrefix(address, "0x") + ".addr.reverse")
+	resolver, err := ensResolver(ctx, registry, node)
+	if err != nil || resolver == "" {
+		return "", err
+	}
+
+	out, err := Call(ctx, ensChainID, resolver, encodeNode(nameSelector, node))
+	if err != nil {
+		return "", fmt.Errorf("name(%s): %w", address, err)
+	}
+	return decodeString(out), nil
+}
+
+// ensResolver 查询注册表中节点的解析器合约，未设置时返回空字符串
+func ensResolver(ctx context.Context, registry string, node []byte) (string, error) {
+	out, err := Call(ctx, ensChainID, registry, encodeNode(resolverSelector, node))
+	if err != nil {
+		return "", fmt.Errorf("resolver lookup: %w", err)
+	}
+	if len(out) < 32 {
+		return "", nil
+	}
+	resolver := common.BytesToAddress(out[:32])
+	if resolver == (common.Address{}) {
+		return "", nil
+	}
+	return resolver.Hex(), nil
+}
+
+// encodeNode 拼接函数选择器和bytes32参数。选择器切片容量为32，直接append会改写共享的底层数组
+func encodeNode(selector, node []byte) []byte {
+	data := make([]byte, 0, len(selector)+len(node))
+	return append(append(data, selector...), node...)
+}
+
+// namehash 按EIP-137计算名称节点
+func namehash(name string) []byte {
+	node := make([]byte, 32)
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		node = crypto.Keccak256(node, crypto.Keccak256([]byte(labels[i])))
+	}
+	return node
+}
+
+// decodeString 解码ABI编码的string返回值，格式错误时返回空字符串
+func decodeString(out []byte) string {
+	if len(out) < 64 {
+		return ""
+	}
+	offset := new(big.Int).SetBytes(out[:32])
+	if !offset.IsUint64() || offset.Uint64()+32 > uint64(len(out)) {
+		return ""
+	}
+	start := offset.Uint64() + 32
+	length := new(big.Int).SetBytes(out[start-32 : start])
+	if !length.IsUint64() || start+length.Uint64() > uint64(len(out)) {
+		return ""
+	}
+	return string(out[start : start+length.Uint64()])
+}
@@ -0,0 +1,83 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	decimalsSelector    = crypto.Keccak256([]byte("decimals()"))[:4]
+	tokenSymbolSelector = crypto.Keccak256([]byte("symbol()"))[:4]
+	tokenNameSelector   = crypto.Keccak256([]byte("name()"))[:4]
+
+	// 代币精度不会变化，按 链ID:地址 缓存
+	tokenDecimals     = make(map[string]int32)
+	tokenDecimalsLock sync.Mutex
+)
+
+// TokenDecimals 读取并缓存ERC20代币精度
+func TokenDecimals(ctx context.Context, chainID uint, token string) (int32, error) {
+	key := fmt.Sprintf("%d:%s", chainID, strings.ToLower(token))
+	tokenDecimalsLock.Lock()
+	decimals, ok := tokenDecimals[key]
+	tokenDecimalsLock.Unlock()
+	if ok {
+		return decimals, nil
+	}
+
+	out, err := Call(ctx, chainID, token, decimalsSelector)
+	if err != nil {
+		return 0, err
+	}
+	if len(out) < 32 {
+		return 0, fmt.Errorf("unexpected decimals() result from %s", token)
+	}
+	decimals = int32(new(big.Int).SetBytes(out[:32]).Int64())
+
+	tokenDecimalsLock.Lock()
+	tokenDecimals[key] = decimals
+	tokenDecimalsLock.Unlock()
+	return decimals, nil
+}
+
+// TokenInfo ERC20代币的链上元数据
+type TokenInfo struct {
+	Symbol   string
+	Name     string
+	Decimals int32
+}
+
+// TokenMetadata 读取代币的符号、名称和精度。精度必须可读；符号和名称读取失败时留空，
+// 兼容返回bytes32的早期代币(如MKR)
+func TokenMetadata(ctx context.Context, chainID uint, token string) (*TokenInfo, error) {
+	decimals, err := TokenDecimals(ctx, chainID, token)
+	if err != nil {
+		return nil, err
+	}
+	info := &TokenInfo{Decimals: decimals}
+	if out, err := Call(ctx, chainID, token, tokenSymbolSelector); err == nil {
+		info.Symbol = decodeTokenString(out)
+	}
+	if out, err := Call(ctx, chainID, token, tokenNameSelector); err == nil {
+		info.Name = decodeTokenString(out)
+	}
+	return info, nil
+}
+
+// decodeTokenString 解码 symbol()/name() 返回值，先按ABI string解码，不符合时按右侧补零的bytes32解码
+func decodeTokenString(out []byte) string {
+	value := decodeString(out)
+	if value == "" && len(out) == 32 {
+		value = strings.TrimRight(string(out), "\x00")
+	}
+	if !utf8.ValidString(value) {
+		return ""
+	}
+	return strings.TrimSpace(value)
+}
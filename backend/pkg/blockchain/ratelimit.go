@@ -0,0 +1,65 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+
+	"golang.org/x/time/rate"
+)
+
+// 按RPC地址限流，多条链共用同一个节点服务商地址时共享额度
+var (
+	limiters     = make(map[string]*rate.Limiter)
+	limitersLock sync.Mutex
+)
+
+// 限流参数变化时调整已有的限流器
+func init() {
+	config.Subscribe(func(prev, next *config.Config) {
+		if prev.Blockchain.RPCRateLimit == next.Blockchain.RPCRateLimit && prev.Blockchain.RPCBurst == next.Blockchain.RPCBurst {
+			return
+		}
+		limitersLock.Lock()
+		defer limitersLock.Unlock()
+		for _, limiter := range limiters {
+			limiter.SetLimit(limitFor(next.Blockchain))
+			limiter.SetBurst(burstFor(next.Blockchain))
+		}
+	})
+}
+
+// Wait 等待指定链的RPC请求额度，所有链上读取在发起请求前调用
+func Wait(ctx context.Context, chainID uint) error {
+	cfg := config.Load().Blockchain
+	url := cfg.RPCURL(chainID)
+	if url == "" {
+		return fmt.Errorf("no RPC configured for chain %d", chainID)
+	}
+
+	limitersLock.Lock()
+	limiter, ok := limiters[url]
+	if !ok {
+		limiter = rate.NewLimiter(limitFor(cfg), burstFor(cfg))
+		limiters[url] = limiter
+	}
+	limitersLock.Unlock()
+
+	return limiter.Wait(ctx)
+}
+
+func limitFor(cfg config.BlockchainConfig) rate.Limit {
+	if cfg.RPCRateLimit <= 0 {
+		return rate.Inf
+	}
+	return rate.Limit(cfg.RPCRateLimit)
+}
+
+func burstFor(cfg config.BlockchainConfig) int {
+	if cfg.RPCBurst <= 0 {
+		return 1
+	}
+	return cfg.RPCBurst
+}
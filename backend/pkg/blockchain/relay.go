@@ -0,0 +1,100 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// 私有通道未配置超时时，等待上链的默认时长
+const defaultRelayTimeout = 2 * time.Minute
+
+// sendPrivate 通过私有RPC提交已签名交易，交易不进入公共内存池
+func sendPrivate(ctx context.Context, relay config.PrivateRelay, signed *types.Transaction) error {
+	client, err := ethclient.DialContext(ctx, relay.URL)
+	if err != nil {
+		return fmt.Errorf("dial private relay: %w", err)
+	}
+	defer client.Close()
+	return client.SendTransaction(ctx, signed)
+}
+
+// RelayFallback 经私有通道提交、尚未确认上链的交易。调用方需在 At 之后调用 PublicFallback(通常放入持久化队列)，
+// 进程退出也不会丢失
+type RelayFallback struct {
+	ChainID uint      `json:"chain_id"`
+	TxHash  string    `json:"tx_hash"`
+	RawTx   string    `json:"raw_tx"` // 已签名交易的二进制编码(hex)
+	At      time.Time `json:"at"`
+}
+
+// newRelayFallback 私有通道提交成功后，按通道的超时时间生成公共内存池兜底
+func newRelayFallback(chainID uint, relay config.PrivateRelay, signed *types.Transaction) (*RelayFallback, error) {
+	timeout := time.Duration(relay.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultRelayTimeout
+	}
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("encode transaction: %w", err)
+	}
+	return &RelayFallback{
+		ChainID: chainID,
+		TxHash:  signed.Hash().Hex(),
+		RawTx:   hexutil.Encode(raw),
+		At:      time.Now().Add(timeout),
+	}, nil
+}
+
+// PublicFallback 私有通道提交的交易超时仍未上链且nonce未被占用时广播到公共内存池。
+// 广播的是同一笔已签名交易，即使私有通道之后仍打包了它也不会重复执行。查询或广播失败时返回错误以便重试
+func PublicFallback(ctx context.Context, fallback RelayFallback) error {
+	raw, err := hexutil.Decode(fallback.RawTx)
+	if err != nil {
+		return fmt.Errorf("decode transaction: %w", err)
+	}
+	signed := new(types.Transaction)
+	if err := signed.UnmarshalBinary(raw); err != nil {
+		return fmt.Errorf("decode transaction: %w", err)
+	}
+
+	client, err := GetClient(fallback.ChainID)
+	if err != nil {
+		return err
+	}
+	if _, err := client.TransactionReceipt(ctx, signed.Hash()); err == nil {
+		return nil
+	} else if !errors.Is(err, ethereum.NotFound) {
+		return fmt.Errorf("get receipt: %w", err)
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(signed.ChainId()), signed)
+	if err != nil {
+		return fmt.Errorf("recover sender: %w", err)
+	}
+	nonce, err := client.NonceAt(ctx, from, nil)
+	if err != nil {
+		return fmt.Errorf("get nonce: %w", err)
+	}
+	if nonce > signed.Nonce() {
+		// 该nonce已被其他交易使用，本交易不可能再上链
+		logger.Info(fmt.Sprintf("Private relay transaction %s was superseded, skipping public fallback", signed.Hash().Hex()))
+		return nil
+	}
+
+	if err := client.SendTransaction(ctx, signed); err != nil {
+		return fmt.Errorf("broadcast to public mempool: %w", err)
+	}
+	logger.Info(fmt.Sprintf("📤 Transaction %s not included via private relay in time, broadcast to public mempool on chain %d",
+		signed.Hash().Hex(), fallback.ChainID))
+	return nil
+}
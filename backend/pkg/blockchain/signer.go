@@ -0,0 +1,175 @@
+package blockchain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// ErrNoSigner 未配置运维签名账户
+var ErrNoSigner = errors.New("operator signer is not configured")
+
+// operatorNonce 一条链上运维账户的nonce分配。私有通道提交的交易不进入公共内存池，PendingNonceAt 看不到，
+// 因此记住本进程最近使用的nonce，取两者中较大的一个；发送被拒绝时清除，下次重新以链上为准
+type operatorNonce struct {
+	mu   sync.Mutex
+	last *uint64
+}
+
+var (
+	operatorNonces   = make(map[uint]*operatorNonce)
+	operatorNoncesMu sync.Mutex
+)
+
+func nonceFor(chainID uint) *operatorNonce {
+	operatorNoncesMu.Lock()
+	defer operatorNoncesMu.Unlock()
+	n, ok := operatorNonces[chainID]
+	if !ok {
+		n = &operatorNonce{}
+		operatorNonces[chainID] = n
+	}
+	return n
+}
+
+// next 调用方需持有 mu
+func (n *operatorNonce) next(pending uint64) uint64 {
+	if n.last != nil && *n.last+1 > pending {
+		return *n.last + 1
+	}
+	return pending
+}
+
+// SendTransaction 使用运维账户签名并发送一笔EIP-1559交易，返回交易哈希，不等待上链。同一条链上的发送串行执行。
+// 链上配置了 blockchain.private_relays 时先通过私有通道提交，返回的 RelayFallback 需由调用方在超时后执行，
+// 未上链时再广播到公共内存池。模拟链模式下不需要运维私钥，直接返回模拟交易哈希
+func SendTransaction(ctx context.Context, chainID uint, to string, data []byte) (string, *RelayFallback, error) {
+	if MockEnabled() {
+		txHash := MockTxHash(fmt.Sprint(chainID), to, common.Bytes2Hex(data))
+		logger.Info(fmt.Sprintf("📤 Mock chain accepted operator transaction %s to %s on chain %d", txHash, to, chainID))
+		return txHash, nil, nil
+	}
+	hexKey := strings.TrimPrefix(config.Load().Blockchain.OperatorKey, "0x")
+	if hexKey == "" {
+		return "", nil, ErrNoSigner
+	}
+	key, err := crypto.HexToECDSA(hexKey)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid operator key: %w", err)
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	client, err := GetClient(chainID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	nonces := nonceFor(chainID)
+	nonces.mu.Lock()
+	defer nonces.mu.Unlock()
+
+	pending, err := client.PendingNonceAt(ctx, from)
+	if err != nil {
+		return "", nil, fmt.Errorf("get nonce: %w", err)
+	}
+	nonce := nonces.next(pending)
+	signed, err := signTransaction(ctx, client, chainID, key, from, nonce, to, data)
+	if err != nil {
+		return "", nil, err
+	}
+	fallback, err := submit(ctx, client, chainID, to, signed)
+	if err != nil {
+		nonces.last = nil
+		return "", nil, err
+	}
+	nonces.last = &nonce
+	return signed.Hash().Hex(), fallback, nil
+}
+
+// signTransaction 以给定nonce构造并签名交易，链上区块没有基础费用(未启用EIP-1559)时改为传统交易
+func signTransaction(ctx context.Context, client *ethclient.Client, chainID uint, key *ecdsa.PrivateKey, from common.Address, nonce uint64, to string, data []byte) (*types.Transaction, error) {
+	contract := common.HexToAddress(to)
+	gas, err := client.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &contract, Data: data})
+	if err != nil {
+		return nil, fmt.Errorf("estimate gas: %w", err)
+	}
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get latest header: %w", err)
+	}
+
+	var txData types.TxData
+	if head.BaseFee == nil {
+		// 未启用EIP-1559的链没有基础费用，按节点建议的gas价格发送传统交易
+		price, err := client.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("suggest gas price: %w", err)
+		}
+		txData = &types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: price,
+			Gas:      gas * 12 / 10,
+			To:       &contract,
+			Data:     data,
+		}
+	} else {
+		tip, err := client.SuggestGasTipCap(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("suggest gas tip: %w", err)
+		}
+		// 最大费用为当前基础费用的2倍加小费，足以覆盖接下来几个区块的基础费用上涨
+		feeCap := new(big.Int).Add(tip, new(big.Int).Mul(head.BaseFee, big.NewInt(2)))
+		txData = &types.DynamicFeeTx{
+			ChainID:   new(big.Int).SetUint64(uint64(chainID)),
+			Nonce:     nonce,
+			GasTipCap: tip,
+			GasFeeCap: feeCap,
+			Gas:       gas * 12 / 10,
+			To:        &contract,
+			Data:      data,
+		}
+	}
+
+	signed, err := types.SignTx(types.NewTx(txData), types.LatestSignerForChainID(new(big.Int).SetUint64(uint64(chainID))), key)
+	if err != nil {
+		return nil, fmt.Errorf("sign transaction: %w", err)
+	}
+	return signed, nil
+}
+
+// submit 发送已签名交易。配置了私有通道的链优先私有提交并返回公共内存池兜底，通道拒绝时直接走公共内存池
+func submit(ctx context.Context, client *ethclient.Client, chainID uint, to string, signed *types.Transaction) (*RelayFallback, error) {
+	if relay, ok := config.Load().Blockchain.PrivateRelay(chainID); ok {
+		err := sendPrivate(ctx, relay, signed)
+		if err == nil {
+			logger.Info(fmt.Sprintf("🔒 Sent operator transaction %s to %s on chain %d via private relay", signed.Hash().Hex(), to, chainID))
+			fallback, err := newRelayFallback(chainID, relay, signed)
+			if err != nil {
+				// 交易已提交，只是无法安排兜底
+				logger.Error(fmt.Sprintf("No public fallback for %s: %v", signed.Hash().Hex(), err))
+			}
+			return fallback, nil
+		}
+		logger.Error(fmt.Sprintf("Private relay rejected %s on chain %d, using public mempool: %v", signed.Hash().Hex(), chainID, err))
+	}
+
+	if err := client.SendTransaction(ctx, signed); err != nil {
+		return nil, fmt.Errorf("send transaction: %w", err)
+	}
+
+	logger.Info(fmt.Sprintf("📤 Sent operator transaction %s to %s on chain %d", signed.Hash().Hex(), to, chainID))
+	return nil, nil
+}
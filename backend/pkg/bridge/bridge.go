@@ -0,0 +1,71 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"time"
+)
+
+var ErrNoRoute = errors.New("no bridge route available")
+
+// 跨链转账状态
+const (
+	StatusPending  = "pending"
+	StatusDone     = "done"
+	StatusFailed   = "failed"
+	StatusNotFound = "not_found" // 聚合器尚未索引到源链交易
+)
+
+// Request 跨链报价请求，数量为代币最小单位
+type Request struct {
+	FromChainID uint
+	ToChainID   uint
+	FromToken   string
+	ToToken     string
+	FromAmount  *big.Int
+	FromAddress string
+	ToAddress   string
+	SlippageBps uint16
+}
+
+// Fee 单项费用，AmountUSD 为聚合器给出的USD估值
+type Fee struct {
+	Name      string  `json:"name"`
+	Token     string  `json:"token"`
+	Amount    string  `json:"amount"`
+	AmountUSD float64 `json:"amount_usd"`
+}
+
+// Quote 跨链报价及源链上可直接执行的交易
+type Quote struct {
+	Source      string
+	Bridge      string // 实际使用的跨链桥
+	ToAmount    *big.Int
+	ToAmountMin *big.Int
+	Spender     string // 卖出ERC20时需要授权的地址
+	To          string
+	Data        []byte
+	Value       *big.Int
+	GasLimit    uint64
+	Fees        []Fee
+	GasUSD      float64
+	ETA         time.Duration
+}
+
+// Status 跨链转账的执行状态
+type Status struct {
+	Status            string
+	DestinationTxHash string
+	Message           string
+}
+
+// Source 跨链聚合器
+type Source interface {
+	Name() string
+	Quote(ctx context.Context, req Request) (*Quote, error)
+	Status(ctx context.Context, bridge string, fromChainID, toChainID uint, txHash string) (*Status, error)
+}
+
+var httpClient = &http.Client{Timeout: 15 * time.Second}
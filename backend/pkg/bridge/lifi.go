@@ -0,0 +1,204 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// LiFiSource 通过LI.FI接口获取跨链报价和转账状态
+type LiFiSource struct {
+	baseURL    string
+	apiKey     string
+	integrator string
+}
+
+func NewLiFiSource(baseURL, apiKey, integrator string) *LiFiSource {
+	return &LiFiSource{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		integrator: integrator,
+	}
+}
+
+func (s *LiFiSource) Name() string {
+	return "lifi"
+}
+
+func (s *LiFiSource) Quote(ctx context.Context, req Request) (*Quote, error) {
+	query := url.Values{}
+	query.Set("fromChain", strconv.FormatUint(uint64(req.FromChainID), 10))
+	query.Set("toChain", strconv.FormatUint(uint64(req.ToChainID), 10))
+	query.Set("fromToken", req.FromToken)
+	query.Set("toToken", req.ToToken)
+	query.Set("fromAmount", req.FromAmount.String())
+	query.Set("fromAddress", req.FromAddress)
+	query.Set("toAddress", req.ToAddress)
+	query.Set("slippage", strconv.FormatFloat(float64(req.SlippageBps)/10000, 'f', -1, 64))
+	if s.integrator != "" {
+		query.Set("integrator", s.integrator)
+	}
+
+	var body struct {
+		Tool     string `json:"tool"`
+		Estimate struct {
+			ToAmount          string `json:"toAmount"`
+			ToAmountMin       string `json:"toAmountMin"`
+			ApprovalAddress   string `json:"approvalAddress"`
+			ExecutionDuration int64  `json:"executionDuration"`
+			FeeCosts          []struct {
+				Name      string `json:"name"`
+				Amount    string `json:"amount"`
+				AmountUSD string `json:"amountUSD"`
+				Token     struct {
+					Address string `json:"address"`
+				} `json:"token"`
+			} `json:"feeCosts"`
+			GasCosts []struct {
+				AmountUSD string `json:"amountUSD"`
+			} `json:"gasCosts"`
+		} `json:"estimate"`
+		TransactionRequest struct {
+			To       string `json:"to"`
+			Data     string `json:"data"`
+			Value    string `json:"value"`
+			GasLimit string `json:"gasLimit"`
+		} `json:"transactionRequest"`
+	}
+	status, err := s.get(ctx, "/v1/quote?"+query.Encode(), &body)
+	if err != nil {
+		return nil, err
+	}
+	// 找不到路由时返回404
+	if status == http.StatusNotFound {
+		return nil, ErrNoRoute
+	}
+
+	toAmount, ok := new(big.Int).SetString(body.Estimate.ToAmount, 10)
+	if !ok {
+		return nil, fmt.Errorf("unexpected lifi toAmount %q", body.Estimate.ToAmount)
+	}
+	toAmountMin, ok := new(big.Int).SetString(body.Estimate.ToAmountMin, 10)
+	if !ok {
+		toAmountMin = toAmount
+	}
+	data, err := hexutil.Decode(body.TransactionRequest.Data)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected lifi calldata: %w", err)
+	}
+	value, err := hexutil.DecodeBig(body.TransactionRequest.Value)
+	if err != nil {
+		value = new(big.Int)
+	}
+	gasLimit, _ := hexutil.DecodeUint64(body.TransactionRequest.GasLimit)
+
+	fees := make([]Fee, 0, len(body.Estimate.FeeCosts))
+	for _, fee := range body.Estimate.FeeCosts {
+		usd, _ := strconv.ParseFloat(fee.AmountUSD, 64)
+		fees = append(fees, Fee{
+			Name:      fee.Name,
+			Token:     strings.ToLower(fee.Token.Address),
+			Amount:    fee.Amount,
+			AmountUSD: usd,
+		})
+	}
+	var gasUSD float64
+	for _, gas := range body.Estimate.GasCosts {
+		usd, _ := strconv.ParseFloat(gas.AmountUSD, 64)
+		gasUSD += usd
+	}
+
+	return &Quote{
+		Source:      s.Name(),
+		Bridge:      body.Tool,
+		ToAmount:    toAmount,
+		ToAmountMin: toAmountMin,
+		Spender:     strings.ToLower(common.HexToAddress(body.Estimate.ApprovalAddress).Hex()),
+		To:          strings.ToLower(common.HexToAddress(body.TransactionRequest.To).Hex()),
+		Data:        data,
+		Value:       value,
+		GasLimit:    gasLimit,
+		Fees:        fees,
+		GasUSD:      gasUSD,
+		ETA:         time.Duration(body.Estimate.ExecutionDuration) * time.Second,
+	}, nil
+}
+
+func (s *LiFiSource) Status(ctx context.Context, bridge string, fromChainID, toChainID uint, txHash string) (*Status, error) {
+	query := url.Values{}
+	query.Set("txHash", txHash)
+	query.Set("fromChain", strconv.FormatUint(uint64(fromChainID), 10))
+	query.Set("toChain", strconv.FormatUint(uint64(toChainID), 10))
+	if bridge != "" {
+		query.Set("bridge", bridge)
+	}
+
+	var body struct {
+		Status    string `json:"status"` // NOT_FOUND, INVALID, PENDING, DONE, FAILED
+		Substatus string `json:"substatus"`
+		Message   string `json:"substatusMessage"`
+		Receiving struct {
+			TxHash string `json:"txHash"`
+		} `json:"receiving"`
+	}
+	status, err := s.get(ctx, "/v1/status?"+query.Encode(), &body)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return &Status{Status: StatusNotFound}, nil
+	}
+
+	result := &Status{Message: body.Message}
+	switch body.Status {
+	case "DONE":
+		// PARTIAL/REFUNDED 表示用户在目标链收到的不是资金库底层资产或资金被退回
+		if body.Substatus == "COMPLETED" || body.Substatus == "" {
+			result.Status = StatusDone
+		} else {
+			result.Status = StatusFailed
+		}
+		result.DestinationTxHash = strings.ToLower(body.Receiving.TxHash)
+	case "FAILED", "INVALID":
+		result.Status = StatusFailed
+	case "NOT_FOUND":
+		result.Status = StatusNotFound
+	default:
+		result.Status = StatusPending
+	}
+	return result, nil
+}
+
+// get 发起GET请求，200时解码到dest，404时只返回状态码
+func (s *LiFiSource) get(ctx context.Context, path string, dest interface{}) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+path, nil)
+	if err != nil {
+		return 0, err
+	}
+	if s.apiKey != "" {
+		req.Header.Set("x-lifi-api-key", s.apiKey)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.StatusCode, json.NewDecoder(resp.Body).Decode(dest)
+	case http.StatusNotFound:
+		return resp.StatusCode, nil
+	}
+	return resp.StatusCode, fmt.Errorf("lifi returned status %d", resp.StatusCode)
+}
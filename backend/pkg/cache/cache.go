@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/metrics"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var Client *redis.Client
+
+// 进程启动以来的缓存命中统计
+var hits, misses atomic.Uint64
+
+// Init 初始化Redis连接，连接失败时仅记录日志，缓存读写会直接回源
+func Init() {
+	cfg := config.Load()
+
+	Client = redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("%s:%s", cfg.Redis.Host, cfg.Redis.Port),
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if err := Client.Ping(ctx).Err(); err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to Redis: %v", err))
+		return
+	}
+
+	logger.Info("✅ Redis connection established")
+}
+
+// GetClient 获取Redis客户端
+func GetClient() *redis.Client {
+	return Client
+}
+
+// GetJSON 读取缓存并反序列化，未命中或Redis不可用时返回false
+func GetJSON(ctx context.Context, key string, dest interface{}) bool {
+	if Client == nil {
+		return false
+	}
+
+	data, err := Client.Get(ctx, key).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			logger.Error(fmt.Sprintf("Cache get %s failed: %v", key, err))
+		}
+		recordLookup(false)
+		return false
+	}
+
+	if err := json.Unmarshal(data, dest); err != nil {
+		logger.Error(fmt.Sprintf("Cache decode %s failed: %v", key, err))
+		recordLookup(false)
+		return false
+	}
+	recordLookup(true)
+	return true
+}
+
+func recordLookup(hit bool) {
+	if hit {
+		hits.Add(1)
+		metrics.CacheRequests.WithLabelValues("hit").Inc()
+		return
+	}
+	misses.Add(1)
+	metrics.CacheRequests.WithLabelValues("miss").Inc()
+}
+
+// Stats 返回进程启动以来的缓存命中和未命中次数
+func Stats() (uint64, uint64) {
+	return hits.Load(), misses.Load()
+}
+
+// SetJSON 序列化后写入缓存，失败只记录日志
+func SetJSON(ctx context.Context, key string, value interface{}, ttl time.Duration) {
+	if Client == nil {
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Cache encode %s failed: %v", key, err))
+		return
+	}
+
+	if err := Client.Set(ctx, key, data, ttl).Err(); err != nil {
+		logger.Error(fmt.Sprintf("Cache set %s failed: %v", key, err))
+	}
+}
+
+// Delete 删除缓存键，用于数据变更后的主动失效
+func Delete(ctx context.Context, keys ...string) {
+	if Client == nil || len(keys) == 0 {
+		return
+	}
+
+	if err := Client.Del(ctx, keys...).Err(); err != nil {
+		logger.Error(fmt.Sprintf("Cache delete %v failed: %v", keys, err))
+	}
+}
+
+// Close 关闭Redis连接
+func Close() error {
+	if Client == nil {
+		return nil
+	}
+	return Client.Close()
+}
@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrLockUnavailable Redis未初始化，无法获取分布式锁
+var ErrLockUnavailable = errors.New("redis client is not initialized")
+
+// 只有持有者(token一致)才能续期或释放锁
+var (
+	refreshScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+	releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+// Lock 基于Redis SET NX的分布式锁
+type Lock struct {
+	key   string
+	token string
+	ttl   time.Duration
+}
+
+// TryLock 尝试获取锁，锁已被其他实例持有时返回nil和nil
+func TryLock(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	if Client == nil {
+		return nil, ErrLockUnavailable
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(buf)
+
+	ok, err := Client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, nil
+	}
+	return &Lock{key: key, token: token, ttl: ttl}, nil
+}
+
+// Refresh 将锁的过期时间重置为ttl，返回false表示锁已丢失
+func (l *Lock) Refresh(ctx context.Context) (bool, error) {
+	n, err := refreshScript.Run(ctx, Client, []string{l.key}, l.token, l.ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// Release 释放锁，锁已过期或被他人持有时不做任何操作
+func (l *Lock) Release(ctx context.Context) error {
+	return releaseScript.Run(ctx, Client, []string{l.key}, l.token).Err()
+}
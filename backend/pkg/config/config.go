@@ -1,42 +1,652 @@
 package config
 
 import (
+	"log"
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Redis    RedisConfig    `mapstructure:"redis"`
+	Server     ServerConfig     `mapstructure:"server"`
+	Database   DatabaseConfig   `mapstructure:"database"`
+	Redis      RedisConfig      `mapstructure:"redis"`
+	Cache      CacheConfig      `mapstructure:"cache"`
+	Kafka      KafkaConfig      `mapstructure:"kafka"`
+	Rebalance  RebalanceConfig  `mapstructure:"rebalance"`
+	Snapshot   SnapshotConfig   `mapstructure:"snapshot"`
+	Retention  RetentionConfig  `mapstructure:"retention"`
+	Jobs       JobsConfig       `mapstructure:"jobs"`
+	Worker     WorkerConfig     `mapstructure:"worker"`
+	Blockchain BlockchainConfig `mapstructure:"blockchain"`
+	Auth       AuthConfig       `mapstructure:"auth"`
+	Log        LogConfig        `mapstructure:"log"`
+	Prices     PricesConfig     `mapstructure:"prices"`
+	Zap        ZapConfig        `mapstructure:"zap"`
+	Bridge     BridgeConfig     `mapstructure:"bridge"`
+	Gas        GasConfig        `mapstructure:"gas"`
+	Rates      RatesConfig      `mapstructure:"rates"`
+	Keeper     KeeperConfig     `mapstructure:"keeper"`
+	Automation AutomationConfig `mapstructure:"automation"`
+	Privacy    PrivacyConfig    `mapstructure:"privacy"`
+	Reports    ReportsConfig    `mapstructure:"reports"`
+	Screening  ScreeningConfig  `mapstructure:"screening"`
+
+	Notifications  NotificationsConfig  `mapstructure:"notifications"`
+	Health         HealthConfig         `mapstructure:"health"`
+	Reconciliation ReconciliationConfig `mapstructure:"reconciliation"`
+	Forecast       ForecastConfig       `mapstructure:"forecast"`
+	Queue          QueueConfig          `mapstructure:"queue"`
+	Resilience     ResilienceConfig     `mapstructure:"resilience"`
+	UniswapV3      UniswapV3Config      `mapstructure:"uniswap_v3"`
+	Morpho         MorphoConfig         `mapstructure:"morpho"`
+	DSR            DSRConfig            `mapstructure:"dsr"`
+	Pendle         PendleConfig         `mapstructure:"pendle"`
+	Tokens         TokensConfig         `mapstructure:"tokens"`
+	Slippage       SlippageConfig       `mapstructure:"slippage"`
+	Intents        IntentsConfig        `mapstructure:"intents"`
+	Routing        RoutingConfig        `mapstructure:"routing"`
+	Advisor        AdvisorConfig        `mapstructure:"advisor"`
+	Suggestions    SuggestionsConfig    `mapstructure:"suggestions"`
+	Status         StatusConfig         `mapstructure:"status"`
 }
 
 type ServerConfig struct {
-	Port string `mapstructure:"port"`
-	Mode string `mapstructure:"mode"`
+	Port  string `mapstructure:"port"`
+	Mode  string `mapstructure:"mode"`
+	Pprof bool   `mapstructure:"pprof"` // 在 /api/v1/admin/debug/pprof 下挂载pprof，仍需管理员权限
+
+	RateLimit  int               `mapstructure:"rate_limit"`  // 未归入其他策略的接口每个客户端每分钟允许的请求数，支持热加载
+	RateLimits RateLimitPolicies `mapstructure:"rate_limits"` // 按路由组的限流策略
+
+	ShutdownTimeout int `mapstructure:"shutdown_timeout"` // 收到退出信号后等待进行中请求结束的时长(秒)
+
+	ReadTimeout  int   `mapstructure:"read_timeout"`  // 读取整个请求(含body)的超时(秒)
+	WriteTimeout int   `mapstructure:"write_timeout"` // 从读完请求头到写完响应的超时(秒)
+	IdleTimeout  int   `mapstructure:"idle_timeout"`  // keep-alive空闲连接的保留时长(秒)
+	MaxBodySize  int64 `mapstructure:"max_body_size"` // 请求body的最大字节数
+
+	TLS         TLSConfig         `mapstructure:"tls"`
+	Compression CompressionConfig `mapstructure:"compression"`
+	CORS        CORSConfig        `mapstructure:"cors"`
+}
+
+// RateLimitPolicies 按路由组的限流策略，每个客户端(按IP，已认证的同时按地址)每分钟允许的请求数，
+// 0表示使用 server.rate_limit，支持热加载
+type RateLimitPolicies struct {
+	Global int `mapstructure:"global"` // 所有请求(含认证失败和404)按IP计数，在认证之前生效
+	Public int `mapstructure:"public"` // 公开的只读接口
+	Write  int `mapstructure:"write"`  // 需要认证的写接口
+	Admin  int `mapstructure:"admin"`  // 管理员接口
+	Export int `mapstructure:"export"` // 用户数据导出
+}
+
+// CORSConfig 跨域配置。allowed_origins 支持 "*" 和 "https://*.example.com" 形式的子域名通配
+type CORSConfig struct {
+	AllowedOrigins   []string `mapstructure:"allowed_origins"`
+	AllowedMethods   []string `mapstructure:"allowed_methods"`
+	AllowedHeaders   []string `mapstructure:"allowed_headers"`
+	ExposedHeaders   []string `mapstructure:"exposed_headers"`   // 允许前端脚本读取的响应头
+	AllowCredentials bool     `mapstructure:"allow_credentials"` // 是否允许携带Cookie等凭证
+	MaxAge           int      `mapstructure:"max_age"`           // 预检结果缓存时间(秒)
+}
+
+// CompressionConfig 响应压缩配置，按Accept-Encoding协商gzip或deflate
+type CompressionConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	Level   int  `mapstructure:"level"`    // 压缩级别1-9，-1为默认级别
+	MinSize int  `mapstructure:"min_size"` // 小于该字节数的响应不压缩
+}
+
+// TLSConfig HTTPS配置，前面没有负载均衡终止TLS时使用。
+// 指定证书文件或开启autocert(Let's Encrypt)，两者都未配置时使用HTTP
+type TLSConfig struct {
+	CertFile string   `mapstructure:"cert_file"`
+	KeyFile  string   `mapstructure:"key_file"`
+	Autocert bool     `mapstructure:"autocert"`
+	Domains  []string `mapstructure:"domains"`   // autocert只为这些域名申请证书
+	CacheDir string   `mapstructure:"cache_dir"` // autocert证书缓存目录，多次重启复用避免触发签发频率限制
+	Email    string   `mapstructure:"email"`     // ACME账户联系邮箱，可选
+	HTTPPort string   `mapstructure:"http_port"` // 启用TLS时监听HTTP并跳转到HTTPS(同时处理autocert的HTTP-01挑战)，为空或未启用TLS时不监听
+}
+
+// Enabled 是否启用HTTPS
+func (t TLSConfig) Enabled() bool {
+	return t.Autocert || t.CertFile != ""
 }
 
 type DatabaseConfig struct {
+	Driver      string `mapstructure:"driver"` // postgres 或 sqlite，sqlite为进程内内存库，仅用于本地开发
+	Host        string `mapstructure:"host"`
+	Port        string `mapstructure:"port"`
+	User        string `mapstructure:"user"`
+	Password    string `mapstructure:"password"`
+	DBName      string `mapstructure:"dbname"`
+	SSLMode     string `mapstructure:"sslmode"`
+	AutoMigrate bool   `mapstructure:"auto_migrate"` // 启动时自动执行未应用的迁移
+
+	MaxOpenConns    int `mapstructure:"max_open_conns"`     // 最大打开连接数，0表示不限制
+	MaxIdleConns    int `mapstructure:"max_idle_conns"`     // 最大空闲连接数
+	ConnMaxLifetime int `mapstructure:"conn_max_lifetime"`  // 连接最长复用时间(秒)，0表示不限制
+	ConnMaxIdleTime int `mapstructure:"conn_max_idle_time"` // 空闲连接最长保留时间(秒)，0表示不限制
+
+	Replicas []string `mapstructure:"replicas"` // 只读副本DSN，为空时所有查询走主库
+
+	ConnectTimeout int `mapstructure:"connect_timeout"` // 启动时等待数据库可用的总时长(秒)，超时后退出
+	RetryInterval  int `mapstructure:"retry_interval"`  // 首次重试间隔(秒)，之后逐次翻倍
+
+	SlowQueryThreshold int `mapstructure:"slow_query_threshold"` // 超过该耗时(毫秒)的查询输出日志，0表示不输出
+}
+
+type RedisConfig struct {
 	Host     string `mapstructure:"host"`
 	Port     string `mapstructure:"port"`
-	User     string `mapstructure:"user"`
 	Password string `mapstructure:"password"`
-	DBName   string `mapstructure:"dbname"`
-	SSLMode  string `mapstructure:"sslmode"`
+	DB       int    `mapstructure:"db"`
 }
 
-type RedisConfig struct {
-	Host string `mapstructure:"host"`
-	Port string `mapstructure:"port"`
-	DB   int    `mapstructure:"db"`
+// KafkaConfig 事件消费配置
+type KafkaConfig struct {
+	Brokers     []string `mapstructure:"brokers"`
+	GroupID     string   `mapstructure:"group_id"`
+	ClientID    string   `mapstructure:"client_id"`
+	EventsTopic string   `mapstructure:"events_topic"` // 索引器发布的原始链上事件
+	DLQTopic    string   `mapstructure:"dlq_topic"`    // 处理失败的事件及错误信息
+}
+
+// CacheConfig 接口响应缓存配置
+type CacheConfig struct {
+	VaultTTL   int `mapstructure:"vault_ttl"`   // 资金库列表与详情缓存时间(秒)
+	APYTTL     int `mapstructure:"apy_ttl"`     // APY数据缓存时间(秒)
+	StatsTTL   int `mapstructure:"stats_ttl"`   // 系统统计缓存时间(秒)
+	MetricsTTL int `mapstructure:"metrics_ttl"` // /metrics 业务指标在进程内的缓存时间(秒)
+
+	WarmUp        bool `mapstructure:"warm_up"`         // 启动时在接收请求前预热缓存
+	WarmUpTimeout int  `mapstructure:"warm_up_timeout"` // 预热最长耗时(秒)，超时后直接启动
+}
+
+// RebalanceConfig 再平衡引擎配置
+type RebalanceConfig struct {
+	Interval          int     `mapstructure:"interval"`            // 计算间隔(分钟)，0表示关闭
+	MinImprovementBps int     `mapstructure:"min_improvement_bps"` // 预期APY提升低于该值(基点)时不生成提案
+	RiskPenalty       float64 `mapstructure:"risk_penalty"`        // 每个风险分对应扣减的APY
+	MaxRiskScore      uint8   `mapstructure:"max_risk_score"`      // 超过该风险分的策略不分配资金
+}
+
+// SnapshotConfig 历史快照任务配置
+type SnapshotConfig struct {
+	Interval     int `mapstructure:"interval"`      // 快照间隔(分钟)，0表示关闭
+	SyncInterval int `mapstructure:"sync_interval"` // 从链上同步资金库和策略资产的间隔(分钟)，0表示关闭
+}
+
+// RetentionConfig 历史数据保留配置
+type RetentionConfig struct {
+	Interval   int `mapstructure:"interval"`     // 汇总与清理间隔(分钟)，0表示关闭
+	APYRawDays int `mapstructure:"apy_raw_days"` // APY原始记录保留天数，0表示不删除；日汇总永久保留
+
+	WebhookDeliveryDays int `mapstructure:"webhook_delivery_days"` // webhook投递记录保留天数，0表示不删除
+	QueueJobDays        int `mapstructure:"queue_job_days"`        // 已完成的队列任务保留天数，0表示不删除；失败任务不清理
+}
+
+// JobsConfig 后台任务配置
+type JobsConfig struct {
+	DistributedLock bool              `mapstructure:"distributed_lock"` // 通过Redis锁保证多实例时每个任务的每次触发只有一个实例执行，需要Redis
+	Timeout         int               `mapstructure:"timeout"`          // 单次执行超时(秒)，0表示不限制
+	Schedules       map[string]string `mapstructure:"schedules"`        // 按任务名覆盖调度表达式(cron或@every)，空字符串表示停用
+}
+
+// WorkerConfig cmd/worker 的运行内容，事件消费和定时任务可拆成不同实例分别扩容
+type WorkerConfig struct {
+	Consumer        bool `mapstructure:"consumer"`         // 消费Kafka链上事件
+	Jobs            bool `mapstructure:"jobs"`             // 运行定时任务(快照、再平衡、数据清理等)
+	Queue           bool `mapstructure:"queue"`            // 执行持久化队列中的异步任务(报告、webhook投递、回填)
+	ShutdownTimeout int  `mapstructure:"shutdown_timeout"` // 收到退出信号后等待正在执行的任务结束的时长(秒)
+}
+
+// AuthConfig 认证配置
+type AuthConfig struct {
+	JWTSecret   string `mapstructure:"jwt_secret"`
+	JWTDuration int    `mapstructure:"jwt_duration"` // 小时
+
+	SignatureTTL int `mapstructure:"signature_ttl"` // 签名请求中 issued_at 与服务器时间允许的最大偏差(秒)
+}
+
+// LogConfig 日志配置
+type LogConfig struct {
+	Level string `mapstructure:"level"` // debug, info, warn, error，支持热加载
+}
+
+// HealthConfig 就绪探针配置
+type HealthConfig struct {
+	CheckTimeout  int    `mapstructure:"check_timeout"`   // 单项依赖检查的超时(秒)
+	MaxIndexerLag uint64 `mapstructure:"max_indexer_lag"` // 索引落后链上最新区块超过该值时视为未就绪，0表示不检查
+}
+
+// StatusConfig 公开状态页：worker定期检查各组件并记录状态变化
+type StatusConfig struct {
+	Interval      int     `mapstructure:"interval"`        // 检查间隔(分钟)，0表示关闭
+	HistoryDays   int     `mapstructure:"history_days"`    // 状态页展示最近多少天的异常
+	KeeperMaxWait int     `mapstructure:"keeper_max_wait"` // keeper任务等待执行超过该分钟数时视为降级
+	APIErrorRate  float64 `mapstructure:"api_error_rate"`  // 最近5分钟5xx比例超过该值时API视为降级
+	IncidentAfter int     `mapstructure:"incident_after"`  // 非正常状态持续超过该分钟数时自动创建事件，0表示不自动创建
+}
+
+// BlockchainConfig 各链RPC配置
+type BlockchainConfig struct {
+	EthereumRPC string `mapstructure:"ethereum_rpc"`
+	PolygonRPC  string `mapstructure:"polygon_rpc"`
+	ArbitrumRPC string `mapstructure:"arbitrum_rpc"`
+	ChainID     int64  `mapstructure:"chain_id"`
+	OperatorKey string `mapstructure:"operator_key"` // 运维签名账户私钥(hex)，用于紧急暂停等链上操作，为空时只做链下处理
+
+	ReadConcurrency int     `mapstructure:"read_concurrency"` // 批量读取合约时的最大并发数
+	RPCRateLimit    float64 `mapstructure:"rpc_rate_limit"`   // 每个RPC地址每秒最多请求数，0表示不限制，支持热加载
+	RPCBurst        int     `mapstructure:"rpc_burst"`        // 允许的瞬时突发请求数
+
+	ENSRegistry string `mapstructure:"ens_registry"`  // 主网ENS注册表地址，为空时关闭ENS解析
+	ENSCacheTTL int    `mapstructure:"ens_cache_ttl"` // ENS正向和反向解析结果缓存时间(秒)
+
+	PrivateRelays []PrivateRelay `mapstructure:"private_relays"` // 运维交易的私有提交通道，未配置的链直接进入公共内存池
+
+	Mock MockChainConfig `mapstructure:"mock"`
+}
+
+// MockChainConfig 开发用模拟链。开启后所有链上读写由进程内的确定性实现应答，不连接RPC，
+// 存取款立即确认，资金库和策略APY按脚本曲线变化
+type MockChainConfig struct {
+	Enabled   bool        `mapstructure:"enabled"`
+	BlockTime int         `mapstructure:"block_time"` // 模拟出块间隔(秒)，区块高度由当前时间推算
+	APYCurves []APYCurve  `mapstructure:"apy_curves"` // 未列出的资金库和策略使用默认曲线
+	Prices    []MockPrice `mapstructure:"prices"`     // Chainlink喂价的模拟价格，未列出的喂价返回1美元
+}
+
+// APYCurve 正弦APY曲线：APY = Base + Amplitude * sin(2π * t / Period)，Period 为小时
+type APYCurve struct {
+	Address   string  `mapstructure:"address"`
+	Base      float64 `mapstructure:"base"`
+	Amplitude float64 `mapstructure:"amplitude"`
+	Period    int     `mapstructure:"period"`
+}
+
+// MockPrice 单个Chainlink喂价合约的模拟美元价格
+type MockPrice struct {
+	Feed string  `mapstructure:"feed"`
+	USD  float64 `mapstructure:"usd"`
+}
+
+// PrivateRelay 单条链的私有交易提交RPC(Flashbots Protect、MEV Blocker等)，
+// 超过 Timeout 秒仍未上链时把同一笔已签名交易广播到公共内存池
+type PrivateRelay struct {
+	ChainID uint   `mapstructure:"chain_id"`
+	URL     string `mapstructure:"url"`
+	Timeout int    `mapstructure:"timeout"`
+}
+
+// PrivateRelay 返回链上配置的私有提交通道
+func (b BlockchainConfig) PrivateRelay(chainID uint) (PrivateRelay, bool) {
+	for _, relay := range b.PrivateRelays {
+		if relay.ChainID == chainID && relay.URL != "" {
+			return relay, true
+		}
+	}
+	return PrivateRelay{}, false
+}
+
+// RPCURL 根据链ID返回对应的RPC地址，未配置时返回空字符串
+func (b BlockchainConfig) RPCURL(chainID uint) string {
+	switch chainID {
+	case 1:
+		return b.EthereumRPC
+	case 137:
+		return b.PolygonRPC
+	case 42161:
+		return b.ArbitrumRPC
+	}
+	return ""
+}
+
+// PricesConfig 价格服务配置
+type PricesConfig struct {
+	CacheTTL        int          `mapstructure:"cache_ttl"`        // 价格缓存时间(秒)
+	MaxStaleness    int          `mapstructure:"max_staleness"`    // 价格最大允许延迟(秒)
+	HistoryInterval int          `mapstructure:"history_interval"` // 历史价格记录间隔(分钟)，0表示关闭
+	CoinGeckoURL    string       `mapstructure:"coingecko_url"`
+	CoinGeckoAPIKey string       `mapstructure:"coingecko_api_key"`
+	FXURL           string       `mapstructure:"fx_url"`       // 每日汇率接口(Frankfurter兼容)
+	FXCacheTTL      int          `mapstructure:"fx_cache_ttl"` // 汇率缓存时间(秒)
+	Tokens          []PriceToken `mapstructure:"tokens"`
+}
+
+// PriceToken 单个代币的价格来源配置
+type PriceToken struct {
+	ChainID       uint   `mapstructure:"chain_id"`
+	Address       string `mapstructure:"address"`
+	Symbol        string `mapstructure:"symbol"`         // 代币符号，资金库搜索按底层资产符号匹配
+	ChainlinkFeed string `mapstructure:"chainlink_feed"` // 对USD的Chainlink喂价合约
+	CoinGeckoID   string `mapstructure:"coingecko_id"`
+}
+
+// ZapConfig 任意代币一键存入配置：通过聚合器兑换为资金库底层资产，再由路由合约在同一笔交易中存入
+type ZapConfig struct {
+	Sources            []string    `mapstructure:"sources"` // 按优先级排列的聚合器: 0x, 1inch
+	ZeroExURL          string      `mapstructure:"zeroex_url"`
+	ZeroExAPIKey       string      `mapstructure:"zeroex_api_key"`
+	OneInchURL         string      `mapstructure:"oneinch_url"`
+	OneInchAPIKey      string      `mapstructure:"oneinch_api_key"`
+	DefaultSlippageBps uint16      `mapstructure:"default_slippage_bps"` // 请求未指定时的滑点(基点)
+	MaxSlippageBps     uint16      `mapstructure:"max_slippage_bps"`     // 允许的最大滑点(基点)
+	Routers            []ZapRouter `mapstructure:"routers"`
+}
+
+// ZapRouter 单条链上的zap路由合约
+type ZapRouter struct {
+	ChainID       uint   `mapstructure:"chain_id"`
+	Address       string `mapstructure:"address"`
+	WrappedNative string `mapstructure:"wrapped_native"` // 原生代币的包装代币，用于计算价格影响
+}
+
+// BridgeConfig 跨链存款配置，资金在其他链上的用户先通过跨链聚合器把资产转到资金库所在链
+type BridgeConfig struct {
+	LiFiURL            string `mapstructure:"lifi_url"`
+	LiFiAPIKey         string `mapstructure:"lifi_api_key"` // 可选，提高请求频率上限
+	Integrator         string `mapstructure:"integrator"`   // 报价请求中的集成方标识
+	DefaultSlippageBps uint16 `mapstructure:"default_slippage_bps"`
+	MaxSlippageBps     uint16 `mapstructure:"max_slippage_bps"`
+	TrackInterval      int    `mapstructure:"track_interval"` // 查询进行中跨链转账状态的间隔(分钟)，0表示关闭
+	Timeout            int    `mapstructure:"timeout"`        // 超过该时长(小时)仍未完成的跨链转账标记为失败
+}
+
+// RatesConfig 收益率口径配置，APR与APY的换算见 pkg/rates
+type RatesConfig struct {
+	CompoundingPeriods int `mapstructure:"compounding_periods"` // 每年复利次数
+}
+
+// KeeperConfig 外部keeper任务配置
+type KeeperConfig struct {
+	JobInterval     int `mapstructure:"job_interval"`     // 生成收获、再平衡任务的间隔(分钟)，0表示不生成
+	HarvestInterval int `mapstructure:"harvest_interval"` // 策略距上次收获超过该时长(小时)时生成收获任务
+	LeaseDuration   int `mapstructure:"lease_duration"`   // 领取任务后的租约时长(秒)，过期后任务可被其他keeper领取
+	MaxAttempts     int `mapstructure:"max_attempts"`     // 上报失败后重新开放的次数上限，达到后任务标记为失败
+}
+
+// PrivacyConfig 用户数据导出与删除配置
+type PrivacyConfig struct {
+	PurgeInterval int `mapstructure:"purge_interval"` // 处理个人数据删除请求的间隔(分钟)，0表示不处理
+}
+
+// ScreeningConfig 存款地址的制裁筛查配置
+type ScreeningConfig struct {
+	Provider  string   `mapstructure:"provider"`  // chainalysis；为空时只按 blocklist 拦截
+	APIURL    string   `mapstructure:"api_url"`   // 筛查接口地址
+	APIKey    string   `mapstructure:"api_key"`   // 筛查接口密钥
+	Blocklist []string `mapstructure:"blocklist"` // 始终拦截的地址(如OFAC SDN名单中的地址)，无需请求提供方
+	CacheTTL  int      `mapstructure:"cache_ttl"` // 筛查结果缓存时长(秒)
+	FailOpen  bool     `mapstructure:"fail_open"` // 提供方不可用时放行存款，默认拒绝
+}
+
+// ReconciliationConfig 链上状态与数据库的对账配置
+type ReconciliationConfig struct {
+	Interval  int     `mapstructure:"interval"`  // 对账间隔(分钟)，0表示关闭
+	Tolerance float64 `mapstructure:"tolerance"` // 数据库与链上数值的相对偏差超过该值时记为差异并告警
+}
+
+// ForecastConfig 资金库APY统计预测配置，按日汇总APY做指数加权移动平均(EWMA)
+type ForecastConfig struct {
+	Interval     int     `mapstructure:"interval"`      // 重新预测的间隔(分钟)，0表示关闭
+	LookbackDays int     `mapstructure:"lookback_days"` // 参与拟合的日汇总天数
+	Alpha        float64 `mapstructure:"alpha"`         // 平滑系数(0,1]，越大越偏重近期
+	Confidence   float64 `mapstructure:"confidence"`    // 置信区间的置信水平
+}
+
+// UniswapV3Config Uniswap v3集中流动性策略的头寸跟踪配置
+type UniswapV3Config struct {
+	Interval    int     `mapstructure:"interval"`     // 读取头寸快照的间隔(分钟)，0表示关闭
+	APRWindow   int     `mapstructure:"apr_window"`   // 手续费APR的统计窗口(天)
+	EdgeWarning float64 `mapstructure:"edge_warning"` // 当前价格距区间边界小于该比例时标记为 near_edge
+}
+
+// MorphoConfig Morpho Blue借贷市场的利率同步和风险输入配置
+type MorphoConfig struct {
+	Interval        int     `mapstructure:"interval"`         // 读取市场状态并更新策略APY的间隔(分钟)，0表示关闭
+	Address         string  `mapstructure:"address"`          // 登记时未指定时使用的Morpho Blue合约地址
+	HighUtilization float64 `mapstructure:"high_utilization"` // 利用率达到该值时供应方可能无法及时取回资金，风险分加1
+	HighLLTV        float64 `mapstructure:"high_lltv"`        // 清算LTV达到该值时清算缓冲较薄，风险分加1
+}
+
+// DSRConfig Maker DSR / sDAI 储蓄利率同步配置
+type DSRConfig struct {
+	Interval       int          `mapstructure:"interval"`        // 读取DSR和sDAI兑换率的间隔(分钟)，0表示关闭
+	RealizedWindow int          `mapstructure:"realized_window"` // 按sDAI兑换率增长计算实际收益率的窗口(天)
+	Networks       []DSRNetwork `mapstructure:"networks"`
+}
+
+// DSRNetwork 单条链上的Pot和sDAI合约，未配置sDAI的链只读取DSR
+type DSRNetwork struct {
+	ChainID uint   `mapstructure:"chain_id"`
+	Pot     string `mapstructure:"pot"`
+	SDAI    string `mapstructure:"sdai"`
+}
+
+// Network 返回链上配置的DSR合约
+func (d DSRConfig) Network(chainID uint) (DSRNetwork, bool) {
+	for _, network := range d.Networks {
+		if network.ChainID == chainID {
+			return network, true
+		}
+	}
+	return DSRNetwork{}, false
+}
+
+// PendleConfig Pendle固定利率策略的隐含利率同步配置
+type PendleConfig struct {
+	Interval int `mapstructure:"interval"` // 读取市场隐含利率并更新策略APY的间隔(分钟)，0表示关闭
+}
+
+// TokensConfig 代币登记表同步配置
+type TokensConfig struct {
+	Interval int `mapstructure:"interval"` // 登记新代币、补全logo的间隔(分钟)，0表示关闭
+}
+
+// SlippageConfig 资金库存取的滑点保护，预览按 DefaultBps 给出最少可得数量
+type SlippageConfig struct {
+	DefaultBps uint16 `mapstructure:"default_bps"`
+	MaxBps     uint16 `mapstructure:"max_bps"`
+}
+
+// IntentsConfig 存取款意向生成交易的配置
+type IntentsConfig struct {
+	MaxDeadline int            `mapstructure:"max_deadline"` // 意向 deadline 距当前时间的上限(秒)
+	QuoteTTL    int            `mapstructure:"quote_ttl"`    // 存款和zap报价的有效期(秒)，过期的报价不能用于存款
+	Routers     []IntentRouter `mapstructure:"routers"`
+}
+
+// IntentRouter 单条链上的ERC-4626路由合约，带 deadline 的意向经由它的 multicall(deadline, data) 执行
+type IntentRouter struct {
+	ChainID uint   `mapstructure:"chain_id"`
+	Address string `mapstructure:"address"`
+}
+
+// RoutingConfig 按资产推荐存入资金库的评分参数
+type RoutingConfig struct {
+	HorizonDays  int     `mapstructure:"horizon_days"`   // 摊销存取gas成本的预期持有天数
+	RiskPenalty  float64 `mapstructure:"risk_penalty"`   // 每个风险分扣减的年化收益率
+	MaxRiskScore float64 `mapstructure:"max_risk_score"` // 风险分超过该值的资金库不推荐
+	MaxSplits    int     `mapstructure:"max_splits"`     // 单个资金库额度不足时最多拆分到的资金库数量
+}
+
+// AdvisorConfig 按风险偏好给出组合建议的参数，每种偏好一组
+type AdvisorConfig struct {
+	Conservative AdvisorProfile `mapstructure:"conservative"`
+	Balanced     AdvisorProfile `mapstructure:"balanced"`
+	Aggressive   AdvisorProfile `mapstructure:"aggressive"`
+}
+
+// AdvisorProfile 一种风险偏好的约束
+type AdvisorProfile struct {
+	MaxRiskScore float64 `mapstructure:"max_risk_score"` // 只选择风险分不超过该值的资金库
+	MaxVaultBps  uint16  `mapstructure:"max_vault_bps"`  // 单个资金库占组合的上限(基点)
+	RiskPenalty  float64 `mapstructure:"risk_penalty"`   // 排序时每个风险分扣减的年化收益率
+}
+
+// Profile 返回风险偏好对应的参数，名称未知时返回false
+func (c AdvisorConfig) Profile(tolerance string) (AdvisorProfile, bool) {
+	switch tolerance {
+	case "conservative":
+		return c.Conservative, true
+	case "balanced":
+		return c.Balanced, true
+	case "aggressive":
+		return c.Aggressive, true
+	}
+	return AdvisorProfile{}, false
+}
+
+// SuggestionsConfig 用户持仓调仓建议，只评估订阅了 rebalance_suggestion 的用户
+type SuggestionsConfig struct {
+	Interval       int     `mapstructure:"interval"`         // 评估间隔(分钟)，0表示关闭
+	MinAPYGain     float64 `mapstructure:"min_apy_gain"`     // 净APY至少提高的年化收益率，订阅的 threshold 可覆盖
+	MinPositionUSD float64 `mapstructure:"min_position_usd"` // 低于该价值的持仓不评估
+	HorizonDays    int     `mapstructure:"horizon_days"`     // 转移的gas成本须在该天数内由多出的收益覆盖
+}
+
+// QueueConfig 持久化异步任务队列配置
+type QueueConfig struct {
+	Concurrency  int `mapstructure:"concurrency"`   // 每个worker实例同时执行的任务数
+	PollInterval int `mapstructure:"poll_interval"` // 队列为空时轮询新任务的间隔(毫秒)
+	MaxAttempts  int `mapstructure:"max_attempts"`  // 入队时未指定时的最大尝试次数
+	RetryBase    int `mapstructure:"retry_base"`    // 第一次重试的基础等待(秒)，之后每次翻倍
+	RetryMax     int `mapstructure:"retry_max"`     // 重试等待的上限(秒)
+	Timeout      int `mapstructure:"timeout"`       // 任务类型未指定时的单次执行超时(秒)
+}
+
+// ResilienceConfig 外部依赖(RPC、价格接口、webhook)的重试和熔断策略。
+// Dependencies 按依赖名(如 rpc:1)或分组名(冒号前缀，如 rpc、webhook)覆盖默认策略中的非零字段
+type ResilienceConfig struct {
+	Default      ResiliencePolicy            `mapstructure:"default"`
+	Dependencies map[string]ResiliencePolicy `mapstructure:"dependencies"`
+}
+
+// ResiliencePolicy 单个依赖的重试和熔断参数
+type ResiliencePolicy struct {
+	MaxAttempts      int     `mapstructure:"max_attempts"`      // 含第一次在内的最大尝试次数，1表示不重试
+	BaseDelay        int     `mapstructure:"base_delay"`        // 第一次重试前的最大等待(毫秒)，之后每次翻倍，实际等待在0到该值之间随机
+	MaxDelay         int     `mapstructure:"max_delay"`         // 重试等待的上限(毫秒)
+	RetryBudget      float64 `mapstructure:"retry_budget"`      // 重试次数占请求数的比例上限，依赖整体故障时避免重试放大流量
+	FailureThreshold int     `mapstructure:"failure_threshold"` // 连续失败多少次后熔断
+	OpenTimeout      int     `mapstructure:"open_timeout"`      // 熔断后多久放行一次试探请求(秒)
+}
+
+// Policy 返回依赖的有效策略：依次以分组、依赖名的配置覆盖默认值
+func (c ResilienceConfig) Policy(dependency string) ResiliencePolicy {
+	policy := c.Default
+	if group, _, ok := strings.Cut(dependency, ":"); ok {
+		policy = policy.merge(c.Dependencies[group])
+	}
+	return policy.merge(c.Dependencies[dependency])
+}
+
+func (p ResiliencePolicy) merge(override ResiliencePolicy) ResiliencePolicy {
+	if override.MaxAttempts > 0 {
+		p.MaxAttempts = override.MaxAttempts
+	}
+	if override.BaseDelay > 0 {
+		p.BaseDelay = override.BaseDelay
+	}
+	if override.MaxDelay > 0 {
+		p.MaxDelay = override.MaxDelay
+	}
+	if override.RetryBudget > 0 {
+		p.RetryBudget = override.RetryBudget
+	}
+	if override.FailureThreshold > 0 {
+		p.FailureThreshold = override.FailureThreshold
+	}
+	if override.OpenTimeout > 0 {
+		p.OpenTimeout = override.OpenTimeout
+	}
+	return p
+}
+
+// ReportsConfig 每日平台汇总报告配置
+type ReportsConfig struct {
+	Interval      int      `mapstructure:"interval"`       // 检查前一天报告是否已生成的间隔(分钟)，0表示不生成
+	TopVaults     int      `mapstructure:"top_vaults"`     // 报告中列出的头部资金库和APY变动数
+	SlackWebhooks []string `mapstructure:"slack_webhooks"` // 生成后推送到这些Slack incoming webhook
+	TelegramChats []string `mapstructure:"telegram_chats"` // 生成后推送到这些Telegram chat
+}
+
+// AutomationConfig Chainlink Automation / Gelato 定时任务监控配置
+type AutomationConfig struct {
+	CheckInterval int                 `mapstructure:"check_interval"` // 检查任务状态的间隔(分钟)，0表示不检查
+	StaleFactor   float64             `mapstructure:"stale_factor"`   // 超过预期间隔的多少倍仍未执行时视为停止执行
+	Networks      []AutomationNetwork `mapstructure:"networks"`
+}
+
+// AutomationNetwork 单条链上的自动化平台合约，未配置的平台不能登记任务
+type AutomationNetwork struct {
+	ChainID           uint   `mapstructure:"chain_id"`
+	ChainlinkRegistry string `mapstructure:"chainlink_registry"` // Automation 注册表(v2.1)
+	GelatoAutomate    string `mapstructure:"gelato_automate"`    // Gelato Automate 合约
+	GelatoOwner       string `mapstructure:"gelato_owner"`       // 创建Gelato任务的地址
+}
+
+// Network 返回链上配置的自动化平台合约
+func (a AutomationConfig) Network(chainID uint) (AutomationNetwork, bool) {
+	for _, network := range a.Networks {
+		if network.ChainID == chainID {
+			return network, true
+		}
+	}
+	return AutomationNetwork{}, false
+}
+
+// GasConfig 各链存取款gas成本估算配置
+type GasConfig struct {
+	RoundTripGas uint64     `mapstructure:"round_trip_gas"` // 一次完整存取(授权+存款+赎回)消耗的gas
+	WithdrawGas  uint64     `mapstructure:"withdraw_gas"`   // 单次赎回消耗的gas，用于取款预览
+	DepositGas   uint64     `mapstructure:"deposit_gas"`    // 单个资金库授权+存款消耗的gas，用于批量存款报价
+	CacheTTL     int        `mapstructure:"cache_ttl"`      // gas价格缓存时间(秒)
+	Chains       []GasChain `mapstructure:"chains"`
+
+	HarvestInterval int `mapstructure:"harvest_interval"` // 从回执补齐收获交易gas成本的间隔(分钟)，0表示不运行
+}
+
+// GasChain 单条链的原生代币，用于把gas费换算为USD，地址需在 prices.tokens 中配置
+type GasChain struct {
+	ChainID     uint   `mapstructure:"chain_id"`
+	NativeToken string `mapstructure:"native_token"`
+}
+
+// NotificationsConfig 用户通知渠道配置
+type NotificationsConfig struct {
+	SMTPHost         string `mapstructure:"smtp_host"`
+	SMTPPort         string `mapstructure:"smtp_port"`
+	SMTPUsername     string `mapstructure:"smtp_username"`
+	SMTPPassword     string `mapstructure:"smtp_password"`
+	EmailFrom        string `mapstructure:"email_from"`
+	TelegramBotToken string `mapstructure:"telegram_bot_token"`
+	TelegramAPIURL   string `mapstructure:"telegram_api_url"`
+	VerificationTTL  int    `mapstructure:"verification_ttl"` // 验证码有效期(分钟)
+	MaxAlertRules    int    `mapstructure:"max_alert_rules"`  // 每个用户最多的APY告警规则数
+	AlertCooldown    int    `mapstructure:"alert_cooldown"`   // 同一规则两次通知的最短间隔(分钟)
+
+	// 运维告警，发送到下列邮箱和Telegram chat，无需验证
+	OperatorEmails        []string `mapstructure:"operator_emails"`
+	OperatorTelegramChats []string `mapstructure:"operator_telegram_chats"`
+	WhaleWithdrawPct      float64  `mapstructure:"whale_withdraw_pct"` // 单笔取款占取款前TVL的比例达到该值时告警
+	OutflowWindow         int      `mapstructure:"outflow_window"`     // 累计净流出统计窗口(分钟)
+	OutflowPct            float64  `mapstructure:"outflow_pct"`        // 窗口内净流出占TVL的比例达到该值时告警
 }
 
 var (
-	config *Config
-	once   sync.Once
+	current atomic.Pointer[Config]
+	once    sync.Once
 )
 
+// Load 返回当前配置。开启热加载后配置文件变化会替换为新的实例，
+// 需要感知变化的模块应通过 Subscribe 注册而不是缓存旧实例
 func Load() *Config {
 	once.Do(func() {
 		// 设置配置文件路径和名称
@@ -46,6 +656,9 @@ func Load() *Config {
 		viper.AddConfigPath("../configs")
 		viper.AddConfigPath("../../configs")
 
+		// 后续新增模块的默认值，无论配置文件是否存在都生效
+		setModuleDefaults()
+
 		// 读取配置文件
 		if err := viper.ReadInConfig(); err != nil {
 			// 如果读取失败，使用默认值
@@ -64,27 +677,589 @@ func Load() *Config {
 
 		// 从环境变量读取（会覆盖配置文件中的值）
 		viper.AutomaticEnv()
+		bindEnvVars()
+
+		current.Store(build())
+	})
+
+	return current.Load()
+}
+
+// build 根据viper当前的值构建配置，热加载时重新调用
+func build() *Config {
+	cfg := &Config{
+		Server: ServerConfig{
+			Port:  viper.GetString("server.port"),
+			Mode:  viper.GetString("server.mode"),
+			Pprof: viper.GetBool("server.pprof"),
 
-		config = &Config{
-			Server: ServerConfig{
-				Port: viper.GetString("server.port"),
-				Mode: viper.GetString("server.mode"),
+			ShutdownTimeout: viper.GetInt("server.shutdown_timeout"),
+
+			RateLimit: viper.GetInt("server.rate_limit"),
+			RateLimits: RateLimitPolicies{
+				Global: viper.GetInt("server.rate_limits.global"),
+				Public: viper.GetInt("server.rate_limits.public"),
+				Write:  viper.GetInt("server.rate_limits.write"),
+				Admin:  viper.GetInt("server.rate_limits.admin"),
+				Export: viper.GetInt("server.rate_limits.export"),
 			},
-			Database: DatabaseConfig{
-				Host:     viper.GetString("database.host"),
-				Port:     viper.GetString("database.port"),
-				User:     viper.GetString("database.user"),
-				Password: viper.GetString("database.password"),
-				DBName:   viper.GetString("database.dbname"),
-				SSLMode:  viper.GetString("database.sslmode"),
+
+			ReadTimeout:  viper.GetInt("server.read_timeout"),
+			WriteTimeout: viper.GetInt("server.write_timeout"),
+			IdleTimeout:  viper.GetInt("server.idle_timeout"),
+			MaxBodySize:  viper.GetInt64("server.max_body_size"),
+
+			TLS: TLSConfig{
+				CertFile: viper.GetString("server.tls.cert_file"),
+				KeyFile:  viper.GetString("server.tls.key_file"),
+				Autocert: viper.GetBool("server.tls.autocert"),
+				Domains:  viper.GetStringSlice("server.tls.domains"),
+				CacheDir: viper.GetString("server.tls.cache_dir"),
+				Email:    viper.GetString("server.tls.email"),
+				HTTPPort: viper.GetString("server.tls.http_port"),
 			},
-			Redis: RedisConfig{
-				Host: viper.GetString("redis.host"),
-				Port: viper.GetString("redis.port"),
-				DB:   viper.GetInt("redis.db"),
+			Compression: CompressionConfig{
+				Enabled: viper.GetBool("server.compression.enabled"),
+				Level:   viper.GetInt("server.compression.level"),
+				MinSize: viper.GetInt("server.compression.min_size"),
 			},
-		}
-	})
+			CORS: CORSConfig{
+				AllowedOrigins:   viper.GetStringSlice("server.cors.allowed_origins"),
+				AllowedMethods:   viper.GetStringSlice("server.cors.allowed_methods"),
+				AllowedHeaders:   viper.GetStringSlice("server.cors.allowed_headers"),
+				ExposedHeaders:   viper.GetStringSlice("server.cors.exposed_headers"),
+				AllowCredentials: viper.GetBool("server.cors.allow_credentials"),
+				MaxAge:           viper.GetInt("server.cors.max_age"),
+			},
+		},
+		Database: DatabaseConfig{
+			Driver:      viper.GetString("database.driver"),
+			Host:        viper.GetString("database.host"),
+			Port:        viper.GetString("database.port"),
+			User:        viper.GetString("database.user"),
+			Password:    viper.GetString("database.password"),
+			DBName:      viper.GetString("database.dbname"),
+			SSLMode:     viper.GetString("database.sslmode"),
+			AutoMigrate: viper.GetBool("database.auto_migrate"),
+
+			MaxOpenConns:    viper.GetInt("database.max_open_conns"),
+			MaxIdleConns:    viper.GetInt("database.max_idle_conns"),
+			ConnMaxLifetime: viper.GetInt("database.conn_max_lifetime"),
+			ConnMaxIdleTime: viper.GetInt("database.conn_max_idle_time"),
+
+			Replicas: viper.GetStringSlice("database.replicas"),
+
+			ConnectTimeout: viper.GetInt("database.connect_timeout"),
+			RetryInterval:  viper.GetInt("database.retry_interval"),
+
+			SlowQueryThreshold: viper.GetInt("database.slow_query_threshold"),
+		},
+		Redis: RedisConfig{
+			Host:     viper.GetString("redis.host"),
+			Port:     viper.GetString("redis.port"),
+			Password: viper.GetString("redis.password"),
+			DB:       viper.GetInt("redis.db"),
+		},
+		Kafka: KafkaConfig{
+			Brokers:     viper.GetStringSlice("kafka.brokers"),
+			GroupID:     viper.GetString("kafka.group_id"),
+			ClientID:    viper.GetString("kafka.client_id"),
+			EventsTopic: viper.GetString("kafka.events_topic"),
+			DLQTopic:    viper.GetString("kafka.dlq_topic"),
+		},
+		Cache: CacheConfig{
+			VaultTTL:   viper.GetInt("cache.vault_ttl"),
+			APYTTL:     viper.GetInt("cache.apy_ttl"),
+			StatsTTL:   viper.GetInt("cache.stats_ttl"),
+			MetricsTTL: viper.GetInt("cache.metrics_ttl"),
+
+			WarmUp:        viper.GetBool("cache.warm_up"),
+			WarmUpTimeout: viper.GetInt("cache.warm_up_timeout"),
+		},
+		Rebalance: RebalanceConfig{
+			Interval:          viper.GetInt("rebalance.interval"),
+			MinImprovementBps: viper.GetInt("rebalance.min_improvement_bps"),
+			RiskPenalty:       viper.GetFloat64("rebalance.risk_penalty"),
+			MaxRiskScore:      uint8(viper.GetUint("rebalance.max_risk_score")),
+		},
+		Snapshot: SnapshotConfig{
+			Interval:     viper.GetInt("snapshot.interval"),
+			SyncInterval: viper.GetInt("snapshot.sync_interval"),
+		},
+		Retention: RetentionConfig{
+			Interval:   viper.GetInt("retention.interval"),
+			APYRawDays: viper.GetInt("retention.apy_raw_days"),
+
+			WebhookDeliveryDays: viper.GetInt("retention.webhook_delivery_days"),
+			QueueJobDays:        viper.GetInt("retention.queue_job_days"),
+		},
+		Jobs: JobsConfig{
+			DistributedLock: viper.GetBool("jobs.distributed_lock"),
+			Timeout:         viper.GetInt("jobs.timeout"),
+			Schedules:       viper.GetStringMapString("jobs.schedules"),
+		},
+		Worker: WorkerConfig{
+			Consumer:        viper.GetBool("worker.consumer"),
+			Jobs:            viper.GetBool("worker.jobs"),
+			Queue:           viper.GetBool("worker.queue"),
+			ShutdownTimeout: viper.GetInt("worker.shutdown_timeout"),
+		},
+		Auth: AuthConfig{
+			JWTSecret:   viper.GetString("auth.jwt_secret"),
+			JWTDuration: viper.GetInt("auth.jwt_duration"),
+
+			SignatureTTL: viper.GetInt("auth.signature_ttl"),
+		},
+		Log: LogConfig{
+			Level: viper.GetString("log.level"),
+		},
+		Health: HealthConfig{
+			CheckTimeout:  viper.GetInt("health.check_timeout"),
+			MaxIndexerLag: viper.GetUint64("health.max_indexer_lag"),
+		},
+		Blockchain: BlockchainConfig{
+			EthereumRPC: viper.GetString("blockchain.ethereum_rpc"),
+			PolygonRPC:  viper.GetString("blockchain.polygon_rpc"),
+			ArbitrumRPC: viper.GetString("blockchain.arbitrum_rpc"),
+			ChainID:     viper.GetInt64("blockchain.chain_id"),
+			OperatorKey: viper.GetString("blockchain.operator_key"),
+
+			ReadConcurrency: viper.GetInt("blockchain.read_concurrency"),
+			RPCRateLimit:    viper.GetFloat64("blockchain.rpc_rate_limit"),
+			RPCBurst:        viper.GetInt("blockchain.rpc_burst"),
+
+			ENSRegistry: viper.GetString("blockchain.ens_registry"),
+			ENSCacheTTL: viper.GetInt("blockchain.ens_cache_ttl"),
+
+			Mock: MockChainConfig{
+				Enabled:   viper.GetBool("blockchain.mock.enabled"),
+				BlockTime: viper.GetInt("blockchain.mock.block_time"),
+			},
+		},
+		Prices: PricesConfig{
+			CacheTTL:        viper.GetInt("prices.cache_ttl"),
+			MaxStaleness:    viper.GetInt("prices.max_staleness"),
+			HistoryInterval: viper.GetInt("prices.history_interval"),
+			CoinGeckoURL:    viper.GetString("prices.coingecko_url"),
+			CoinGeckoAPIKey: viper.GetString("prices.coingecko_api_key"),
+			FXURL:           viper.GetString("prices.fx_url"),
+			FXCacheTTL:      viper.GetInt("prices.fx_cache_ttl"),
+		},
+		Zap: ZapConfig{
+			Sources:            viper.GetStringSlice("zap.sources"),
+			ZeroExURL:          viper.GetString("zap.zeroex_url"),
+			ZeroExAPIKey:       viper.GetString("zap.zeroex_api_key"),
+			OneInchURL:         viper.GetString("zap.oneinch_url"),
+			OneInchAPIKey:      viper.GetString("zap.oneinch_api_key"),
+			DefaultSlippageBps: uint16(viper.GetUint("zap.default_slippage_bps")),
+			MaxSlippageBps:     uint16(viper.GetUint("zap.max_slippage_bps")),
+		},
+		Bridge: BridgeConfig{
+			LiFiURL:            viper.GetString("bridge.lifi_url"),
+			LiFiAPIKey:         viper.GetString("bridge.lifi_api_key"),
+			Integrator:         viper.GetString("bridge.integrator"),
+			DefaultSlippageBps: uint16(viper.GetUint("bridge.default_slippage_bps")),
+			MaxSlippageBps:     uint16(viper.GetUint("bridge.max_slippage_bps")),
+			TrackInterval:      viper.GetInt("bridge.track_interval"),
+			Timeout:            viper.GetInt("bridge.timeout"),
+		},
+		Gas: GasConfig{
+			RoundTripGas: viper.GetUint64("gas.round_trip_gas"),
+			WithdrawGas:  viper.GetUint64("gas.withdraw_gas"),
+			DepositGas:   viper.GetUint64("gas.deposit_gas"),
+			CacheTTL:     viper.GetInt("gas.cache_ttl"),
+
+			HarvestInterval: viper.GetInt("gas.harvest_interval"),
+		},
+		Rates: RatesConfig{
+			CompoundingPeriods: viper.GetInt("rates.compounding_periods"),
+		},
+		Keeper: KeeperConfig{
+			JobInterval:     viper.GetInt("keeper.job_interval"),
+			HarvestInterval: viper.GetInt("keeper.harvest_interval"),
+			LeaseDuration:   viper.GetInt("keeper.lease_duration"),
+			MaxAttempts:     viper.GetInt("keeper.max_attempts"),
+		},
+		Privacy: PrivacyConfig{
+			PurgeInterval: viper.GetInt("privacy.purge_interval"),
+		},
+		Reconciliation: ReconciliationConfig{
+			Interval:  viper.GetInt("reconciliation.interval"),
+			Tolerance: viper.GetFloat64("reconciliation.tolerance"),
+		},
+		Forecast: ForecastConfig{
+			Interval:     viper.GetInt("forecast.interval"),
+			LookbackDays: viper.GetInt("forecast.lookback_days"),
+			Alpha:        viper.GetFloat64("forecast.alpha"),
+			Confidence:   viper.GetFloat64("forecast.confidence"),
+		},
+		UniswapV3: UniswapV3Config{
+			Interval:    viper.GetInt("uniswap_v3.interval"),
+			APRWindow:   viper.GetInt("uniswap_v3.apr_window"),
+			EdgeWarning: viper.GetFloat64("uniswap_v3.edge_warning"),
+		},
+		Morpho: MorphoConfig{
+			Interval:        viper.GetInt("morpho.interval"),
+			Address:         strings.ToLower(viper.GetString("morpho.address")),
+			HighUtilization: viper.GetFloat64("morpho.high_utilization"),
+			HighLLTV:        viper.GetFloat64("morpho.high_lltv"),
+		},
+		DSR: DSRConfig{
+			Interval:       viper.GetInt("dsr.interval"),
+			RealizedWindow: viper.GetInt("dsr.realized_window"),
+		},
+		Pendle: PendleConfig{
+			Interval: viper.GetInt("pendle.interval"),
+		},
+		Tokens: TokensConfig{
+			Interval: viper.GetInt("tokens.interval"),
+		},
+		Slippage: SlippageConfig{
+			DefaultBps: uint16(viper.GetUint("slippage.default_bps")),
+			MaxBps:     uint16(viper.GetUint("slippage.max_bps")),
+		},
+		Intents: IntentsConfig{
+			MaxDeadline: viper.GetInt("intents.max_deadline"),
+			QuoteTTL:    viper.GetInt("intents.quote_ttl"),
+		},
+		Routing: RoutingConfig{
+			HorizonDays:  viper.GetInt("routing.horizon_days"),
+			RiskPenalty:  viper.GetFloat64("routing.risk_penalty"),
+			MaxRiskScore: viper.GetFloat64("routing.max_risk_score"),
+			MaxSplits:    viper.GetInt("routing.max_splits"),
+		},
+		Advisor: AdvisorConfig{
+			Conservative: advisorProfile("advisor.conservative"),
+			Balanced:     advisorProfile("advisor.balanced"),
+			Aggressive:   advisorProfile("advisor.aggressive"),
+		},
+		Suggestions: SuggestionsConfig{
+			Interval:       viper.GetInt("suggestions.interval"),
+			MinAPYGain:     viper.GetFloat64("suggestions.min_apy_gain"),
+			MinPositionUSD: viper.GetFloat64("suggestions.min_position_usd"),
+			HorizonDays:    viper.GetInt("suggestions.horizon_days"),
+		},
+		Status: StatusConfig{
+			Interval:      viper.GetInt("status.interval"),
+			HistoryDays:   viper.GetInt("status.history_days"),
+			KeeperMaxWait: viper.GetInt("status.keeper_max_wait"),
+			APIErrorRate:  viper.GetFloat64("status.api_error_rate"),
+			IncidentAfter: viper.GetInt("status.incident_after"),
+		},
+		Resilience: ResilienceConfig{
+			Default: ResiliencePolicy{
+				MaxAttempts:      viper.GetInt("resilience.default.max_attempts"),
+				BaseDelay:        viper.GetInt("resilience.default.base_delay"),
+				MaxDelay:         viper.GetInt("resilience.default.max_delay"),
+				RetryBudget:      viper.GetFloat64("resilience.default.retry_budget"),
+				FailureThreshold: viper.GetInt("resilience.default.failure_threshold"),
+				OpenTimeout:      viper.GetInt("resilience.default.open_timeout"),
+			},
+		},
+		Queue: QueueConfig{
+			Concurrency:  viper.GetInt("queue.concurrency"),
+			PollInterval: viper.GetInt("queue.poll_interval"),
+			MaxAttempts:  viper.GetInt("queue.max_attempts"),
+			RetryBase:    viper.GetInt("queue.retry_base"),
+			RetryMax:     viper.GetInt("queue.retry_max"),
+			Timeout:      viper.GetInt("queue.timeout"),
+		},
+		Screening: ScreeningConfig{
+			Provider:  viper.GetString("screening.provider"),
+			APIURL:    viper.GetString("screening.api_url"),
+			APIKey:    viper.GetString("screening.api_key"),
+			Blocklist: viper.GetStringSlice("screening.blocklist"),
+			CacheTTL:  viper.GetInt("screening.cache_ttl"),
+			FailOpen:  viper.GetBool("screening.fail_open"),
+		},
+		Reports: ReportsConfig{
+			Interval:      viper.GetInt("reports.interval"),
+			TopVaults:     viper.GetInt("reports.top_vaults"),
+			SlackWebhooks: viper.GetStringSlice("reports.slack_webhooks"),
+			TelegramChats: viper.GetStringSlice("reports.telegram_chats"),
+		},
+		Automation: AutomationConfig{
+			CheckInterval: viper.GetInt("automation.check_interval"),
+			StaleFactor:   viper.GetFloat64("automation.stale_factor"),
+		},
+		Notifications: NotificationsConfig{
+			SMTPHost:         viper.GetString("notifications.smtp_host"),
+			SMTPPort:         viper.GetString("notifications.smtp_port"),
+			SMTPUsername:     viper.GetString("notifications.smtp_username"),
+			SMTPPassword:     viper.GetString("notifications.smtp_password"),
+			EmailFrom:        viper.GetString("notifications.email_from"),
+			TelegramBotToken: viper.GetString("notifications.telegram_bot_token"),
+			TelegramAPIURL:   viper.GetString("notifications.telegram_api_url"),
+			VerificationTTL:  viper.GetInt("notifications.verification_ttl"),
+			MaxAlertRules:    viper.GetInt("notifications.max_alert_rules"),
+			AlertCooldown:    viper.GetInt("notifications.alert_cooldown"),
+
+			OperatorEmails:        viper.GetStringSlice("notifications.operator_emails"),
+			OperatorTelegramChats: viper.GetStringSlice("notifications.operator_telegram_chats"),
+			WhaleWithdrawPct:      viper.GetFloat64("notifications.whale_withdraw_pct"),
+			OutflowWindow:         viper.GetInt("notifications.outflow_window"),
+			OutflowPct:            viper.GetFloat64("notifications.outflow_pct"),
+		},
+	}
+
+	// 列表类配置需要整体反序列化
+	if err := viper.UnmarshalKey("prices.tokens", &cfg.Prices.Tokens); err != nil {
+		log.Printf("Warning: Could not decode prices.tokens: %v", err)
+	}
+	if err := viper.UnmarshalKey("zap.routers", &cfg.Zap.Routers); err != nil {
+		log.Printf("Warning: Could not decode zap.routers: %v", err)
+	}
+	if err := viper.UnmarshalKey("intents.routers", &cfg.Intents.Routers); err != nil {
+		log.Printf("Warning: Could not decode intents.routers: %v", err)
+	}
+	if err := viper.UnmarshalKey("gas.chains", &cfg.Gas.Chains); err != nil {
+		log.Printf("Warning: Could not decode gas.chains: %v", err)
+	}
+	if err := viper.UnmarshalKey("blockchain.private_relays", &cfg.Blockchain.PrivateRelays); err != nil {
+		log.Printf("Warning: Could not decode blockchain.private_relays: %v", err)
+	}
+	if err := viper.UnmarshalKey("blockchain.mock.apy_curves", &cfg.Blockchain.Mock.APYCurves); err != nil {
+		log.Printf("Warning: Could not decode blockchain.mock.apy_curves: %v", err)
+	}
+	if err := viper.UnmarshalKey("blockchain.mock.prices", &cfg.Blockchain.Mock.Prices); err != nil {
+		log.Printf("Warning: Could not decode blockchain.mock.prices: %v", err)
+	}
+	if err := viper.UnmarshalKey("automation.networks", &cfg.Automation.Networks); err != nil {
+		log.Printf("Warning: Could not decode automation.networks: %v", err)
+	}
+	if err := viper.UnmarshalKey("dsr.networks", &cfg.DSR.Networks); err != nil {
+		log.Printf("Warning: Could not decode dsr.networks: %v", err)
+	}
+	if err := viper.UnmarshalKey("resilience.dependencies", &cfg.Resilience.Dependencies); err != nil {
+		log.Printf("Warning: Could not decode resilience.dependencies: %v", err)
+	}
+	return cfg
+}
+
+// advisorProfile 读取一种风险偏好的参数，key 为 advisor.<偏好>
+func advisorProfile(key string) AdvisorProfile {
+	return AdvisorProfile{
+		MaxRiskScore: viper.GetFloat64(key + ".max_risk_score"),
+		MaxVaultBps:  uint16(viper.GetUint(key + ".max_vault_bps")),
+		RiskPenalty:  viper.GetFloat64(key + ".risk_penalty"),
+	}
+}
+
+func setModuleDefaults() {
+	viper.SetDefault("server.rate_limit", 60)
+	viper.SetDefault("server.rate_limits.global", 1200)
+	viper.SetDefault("server.rate_limits.public", 300)
+	viper.SetDefault("server.rate_limits.write", 30)
+	viper.SetDefault("server.rate_limits.admin", 600)
+	viper.SetDefault("server.rate_limits.export", 5)
+	viper.SetDefault("log.level", "info")
+	viper.SetDefault("auth.jwt_duration", 24)
+	viper.SetDefault("auth.signature_ttl", 300)
+	viper.SetDefault("server.shutdown_timeout", 30)
+	viper.SetDefault("server.read_timeout", 30)
+	viper.SetDefault("server.write_timeout", 30)
+	viper.SetDefault("server.idle_timeout", 120)
+	viper.SetDefault("server.max_body_size", 1<<20)
+	viper.SetDefault("server.tls.cache_dir", "./certs")
+	viper.SetDefault("server.compression.enabled", true)
+	viper.SetDefault("server.compression.level", -1)
+	viper.SetDefault("server.compression.min_size", 1024)
+	viper.SetDefault("server.cors.allowed_origins", []string{"http://localhost:3000"})
+	viper.SetDefault("server.cors.allowed_methods", []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"})
+	viper.SetDefault("server.cors.allowed_headers", []string{"Content-Type", "Authorization", "Accept", "Cache-Control", "X-Requested-With", "X-User-Address"})
+	viper.SetDefault("server.cors.exposed_headers", []string{"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"})
+	viper.SetDefault("server.cors.max_age", 600)
+	viper.SetDefault("health.check_timeout", 2)
+	viper.SetDefault("health.max_indexer_lag", 100)
+	viper.SetDefault("status.interval", 1)
+	viper.SetDefault("status.history_days", 7)
+	viper.SetDefault("status.keeper_max_wait", 60)
+	viper.SetDefault("status.api_error_rate", 0.05)
+	viper.SetDefault("status.incident_after", 5)
+	viper.SetDefault("database.driver", "postgres")
+	viper.SetDefault("database.max_open_conns", 25)
+	viper.SetDefault("database.max_idle_conns", 10)
+	viper.SetDefault("database.conn_max_lifetime", 1800)
+	viper.SetDefault("database.conn_max_idle_time", 300)
+	viper.SetDefault("database.connect_timeout", 60)
+	viper.SetDefault("database.retry_interval", 1)
+	viper.SetDefault("database.slow_query_threshold", 200)
+
+	viper.SetDefault("kafka.brokers", []string{"localhost:9092"})
+	viper.SetDefault("kafka.group_id", "mya-worker-group")
+	viper.SetDefault("kafka.client_id", "mya-worker")
+	viper.SetDefault("kafka.events_topic", "chain-events")
+	viper.SetDefault("kafka.dlq_topic", "chain-events-dlq")
+
+	viper.SetDefault("cache.vault_ttl", 30)
+	viper.SetDefault("cache.apy_ttl", 300)
+	viper.SetDefault("cache.stats_ttl", 60)
+	viper.SetDefault("cache.metrics_ttl", 30)
+	viper.SetDefault("cache.warm_up", true)
+	viper.SetDefault("cache.warm_up_timeout", 30)
+
+	viper.SetDefault("rebalance.interval", 60)
+	viper.SetDefault("rebalance.min_improvement_bps", 10)
+	viper.SetDefault("rebalance.risk_penalty", 0.002)
+	viper.SetDefault("rebalance.max_risk_score", 4)
+
+	viper.SetDefault("snapshot.interval", 60)
+	viper.SetDefault("snapshot.sync_interval", 5)
+
+	viper.SetDefault("retention.interval", 1440)
+	viper.SetDefault("retention.apy_raw_days", 90)
+	viper.SetDefault("retention.webhook_delivery_days", 30)
+	viper.SetDefault("retention.queue_job_days", 7)
+
+	viper.SetDefault("jobs.distributed_lock", true)
+	viper.SetDefault("jobs.timeout", 3600)
+
+	viper.SetDefault("worker.consumer", true)
+	viper.SetDefault("worker.jobs", true)
+	viper.SetDefault("worker.queue", true)
+	viper.SetDefault("worker.shutdown_timeout", 30)
+
+	viper.SetDefault("blockchain.ethereum_rpc", "https://eth.llamarpc.com")
+	viper.SetDefault("blockchain.polygon_rpc", "https://polygon-rpc.com")
+	viper.SetDefault("blockchain.arbitrum_rpc", "https://arb1.arbitrum.io/rpc")
+	viper.SetDefault("blockchain.chain_id", 1)
+	viper.SetDefault("blockchain.read_concurrency", 8)
+	viper.SetDefault("blockchain.rpc_rate_limit", 10)
+	viper.SetDefault("blockchain.rpc_burst", 20)
+	viper.SetDefault("blockchain.ens_registry", "0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e")
+	viper.SetDefault("blockchain.ens_cache_ttl", 3600)
+	viper.SetDefault("blockchain.mock.block_time", 12)
+
+	viper.SetDefault("prices.cache_ttl", 60)
+	viper.SetDefault("prices.max_staleness", 3600)
+	viper.SetDefault("prices.history_interval", 1440)
+	viper.SetDefault("prices.coingecko_url", "https://api.coingecko.com/api/v3")
+	viper.SetDefault("prices.fx_url", "https://api.frankfurter.app/latest")
+	viper.SetDefault("prices.fx_cache_ttl", 86400)
+
+	viper.SetDefault("zap.sources", []string{"0x", "1inch"})
+	viper.SetDefault("zap.zeroex_url", "https://api.0x.org")
+	viper.SetDefault("zap.oneinch_url", "https://api.1inch.dev")
+	viper.SetDefault("zap.default_slippage_bps", 50)
+	viper.SetDefault("zap.max_slippage_bps", 300)
+
+	viper.SetDefault("bridge.lifi_url", "https://li.quest")
+	viper.SetDefault("bridge.integrator", "mya-platform")
+	viper.SetDefault("bridge.default_slippage_bps", 50)
+	viper.SetDefault("bridge.max_slippage_bps", 300)
+	viper.SetDefault("bridge.track_interval", 1)
+	viper.SetDefault("bridge.timeout", 24)
+
+	viper.SetDefault("gas.round_trip_gas", 320000)
+	viper.SetDefault("gas.withdraw_gas", 150000)
+	viper.SetDefault("gas.deposit_gas", 170000)
+	viper.SetDefault("gas.cache_ttl", 30)
+	viper.SetDefault("gas.harvest_interval", 10)
+	viper.SetDefault("rates.compounding_periods", 365)
+
+	viper.SetDefault("keeper.job_interval", 5)
+	viper.SetDefault("keeper.harvest_interval", 24)
+	viper.SetDefault("keeper.lease_duration", 300)
+	viper.SetDefault("keeper.max_attempts", 3)
+
+	viper.SetDefault("privacy.purge_interval", 5)
+	viper.SetDefault("screening.api_url", "https://public.chainalysis.com/api/v1")
+	viper.SetDefault("screening.cache_ttl", 86400)
+	viper.SetDefault("reconciliation.interval", 1440)
+	viper.SetDefault("reconciliation.tolerance", 0.001)
+	viper.SetDefault("forecast.interval", 1440)
+	viper.SetDefault("forecast.lookback_days", 60)
+	viper.SetDefault("forecast.alpha", 0.3)
+	viper.SetDefault("forecast.confidence", 0.9)
+
+	viper.SetDefault("uniswap_v3.interval", 60)
+	viper.SetDefault("uniswap_v3.apr_window", 7)
+	viper.SetDefault("uniswap_v3.edge_warning", 0.05)
+
+	viper.SetDefault("morpho.interval", 10)
+	viper.SetDefault("morpho.address", "0xBBBBBbbBBb9cC5e90e3b3Af64bdAF62C37EEFFCb")
+	viper.SetDefault("morpho.high_utilization", 0.95)
+	viper.SetDefault("morpho.high_lltv", 0.915)
+
+	viper.SetDefault("dsr.interval", 60)
+	viper.SetDefault("dsr.realized_window", 7)
+
+	viper.SetDefault("pendle.interval", 30)
+
+	viper.SetDefault("tokens.interval", 60)
+	viper.SetDefault("slippage.default_bps", 50)
+	viper.SetDefault("slippage.max_bps", 500)
+	viper.SetDefault("intents.max_deadline", 86400)
+	viper.SetDefault("intents.quote_ttl", 60)
+	viper.SetDefault("routing.horizon_days", 30)
+	viper.SetDefault("routing.risk_penalty", 0.005)
+	viper.SetDefault("routing.max_risk_score", 4)
+	viper.SetDefault("routing.max_splits", 3)
+	viper.SetDefault("advisor.conservative.max_risk_score", 2)
+	viper.SetDefault("advisor.conservative.max_vault_bps", 4000)
+	viper.SetDefault("advisor.conservative.risk_penalty", 0.02)
+	viper.SetDefault("advisor.balanced.max_risk_score", 3)
+	viper.SetDefault("advisor.balanced.max_vault_bps", 5000)
+	viper.SetDefault("advisor.balanced.risk_penalty", 0.01)
+	viper.SetDefault("advisor.aggressive.max_risk_score", 5)
+	viper.SetDefault("advisor.aggressive.max_vault_bps", 8000)
+	viper.SetDefault("advisor.aggressive.risk_penalty", 0)
+	viper.SetDefault("suggestions.interval", 360)
+	viper.SetDefault("suggestions.min_apy_gain", 0.01)
+	viper.SetDefault("suggestions.min_position_usd", 100)
+	viper.SetDefault("suggestions.horizon_days", 90)
+
+	viper.SetDefault("resilience.default.max_attempts", 3)
+	viper.SetDefault("resilience.default.base_delay", 200)
+	viper.SetDefault("resilience.default.max_delay", 2000)
+	viper.SetDefault("resilience.default.retry_budget", 0.2)
+	viper.SetDefault("resilience.default.failure_threshold", 5)
+	viper.SetDefault("resilience.default.open_timeout", 30)
+
+	viper.SetDefault("queue.concurrency", 4)
+	viper.SetDefault("queue.poll_interval", 1000)
+	viper.SetDefault("queue.max_attempts", 8)
+	viper.SetDefault("queue.retry_base", 15)
+	viper.SetDefault("queue.retry_max", 3600)
+	viper.SetDefault("queue.timeout", 300)
+
+	viper.SetDefault("reports.interval", 60)
+	viper.SetDefault("reports.top_vaults", 5)
+
+	viper.SetDefault("automation.check_interval", 15)
+	viper.SetDefault("automation.stale_factor", 2)
+
+	viper.SetDefault("notifications.smtp_port", "587")
+	viper.SetDefault("notifications.telegram_api_url", "https://api.telegram.org")
+	viper.SetDefault("notifications.verification_ttl", 30)
+	viper.SetDefault("notifications.max_alert_rules", 20)
+	viper.SetDefault("notifications.alert_cooldown", 360)
+	viper.SetDefault("notifications.whale_withdraw_pct", 0.05)
+	viper.SetDefault("notifications.outflow_window", 60)
+	viper.SetDefault("notifications.outflow_pct", 0.2)
+}
 
-	return config
+// bindEnvVars 绑定部署环境中使用的环境变量名(见 .env.example)
+func bindEnvVars() {
+	viper.BindEnv("server.port", "API_PORT")
+	viper.BindEnv("database.driver", "DB_DRIVER")
+	viper.BindEnv("database.host", "DB_HOST")
+	viper.BindEnv("database.port", "DB_PORT")
+	viper.BindEnv("database.user", "DB_USER")
+	viper.BindEnv("database.password", "DB_PASSWORD")
+	viper.BindEnv("database.dbname", "DB_NAME")
+	viper.BindEnv("redis.host", "REDIS_HOST")
+	viper.BindEnv("redis.port", "REDIS_PORT")
+	viper.BindEnv("redis.password", "REDIS_PASSWORD")
+	viper.BindEnv("redis.db", "REDIS_DB")
+	viper.BindEnv("auth.jwt_secret", "JWT_SECRET")
+	viper.BindEnv("blockchain.ethereum_rpc", "ETHEREUM_RPC")
+	viper.BindEnv("blockchain.polygon_rpc", "POLYGON_RPC")
+	viper.BindEnv("blockchain.arbitrum_rpc", "ARBITRUM_RPC")
+	viper.BindEnv("blockchain.operator_key", "OPERATOR_PRIVATE_KEY")
+	viper.BindEnv("blockchain.mock.enabled", "MOCK_CHAIN")
+	viper.BindEnv("zap.zeroex_api_key", "ZEROEX_API_KEY")
+	viper.BindEnv("zap.oneinch_api_key", "ONEINCH_API_KEY")
+	viper.BindEnv("bridge.lifi_api_key", "LIFI_API_KEY")
+	viper.BindEnv("screening.api_key", "SCREENING_API_KEY")
 }
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"log"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+var (
+	subscribers []func(prev, next *Config)
+	subMu       sync.Mutex
+	watchOnce   sync.Once
+)
+
+// Subscribe 注册配置变化回调，回调在配置文件变化并重新加载后按注册顺序同步执行
+func Subscribe(fn func(prev, next *Config)) {
+	subMu.Lock()
+	defer subMu.Unlock()
+	subscribers = append(subscribers, fn)
+}
+
+// Watch 监听配置文件，变化时重新加载并通知订阅者。
+// 连接类配置(数据库、Redis、Kafka、端口)仍需重启才会生效
+func Watch() {
+	Load()
+	if viper.ConfigFileUsed() == "" {
+		return
+	}
+
+	watchOnce.Do(func() {
+		viper.OnConfigChange(func(e fsnotify.Event) {
+			next := build()
+			prev := current.Swap(next)
+			log.Printf("Config reloaded from %s", e.Name)
+
+			subMu.Lock()
+			fns := append([]func(prev, next *Config){}, subscribers...)
+			subMu.Unlock()
+			for _, fn := range fns {
+				fn(prev, next)
+			}
+		})
+		viper.WatchConfig()
+	})
+}
@@ -0,0 +1,104 @@
+package contracts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// 合约接口类型，决定ABI中必须包含的方法和事件
+const (
+	InterfaceVault    = "erc4626"  // 资金库，索引器和回放按其事件物化存取款
+	InterfaceStrategy = "strategy" // 策略，同步任务读取 estimatedTotalAssets
+	InterfaceOracle   = "oracle"   // Chainlink风格的价格预言机
+)
+
+var ErrInvalidABI = errors.New("invalid ABI")
+
+// ValidInterface 是否为支持的接口类型
+func ValidInterface(iface string) bool {
+	_, ok := required[iface]
+	return ok
+}
+
+// 各接口类型的ABI必须包含的方法和事件
+var required = map[string]struct{ methods, events []string }{
+	InterfaceVault:    {methods: []string{"totalAssets"}, events: []string{"Deposit", "Withdraw"}},
+	InterfaceStrategy: {methods: []string{"estimatedTotalAssets"}},
+	InterfaceOracle:   {methods: []string{"latestRoundData"}},
+}
+
+// Parse 解析并校验上传的ABI，缺少接口类型要求的方法或事件时返回 ErrInvalidABI
+func Parse(iface, abiJSON string) (*abi.ABI, error) {
+	req, ok := required[iface]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown interface %q", ErrInvalidABI, iface)
+	}
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidABI, err)
+	}
+	for _, name := range req.methods {
+		if _, ok := parsed.Methods[name]; !ok {
+			return nil, fmt.Errorf("%w: %s contract must have method %s", ErrInvalidABI, iface, name)
+		}
+	}
+	for _, name := range req.events {
+		if _, ok := parsed.Events[name]; !ok {
+			return nil, fmt.Errorf("%w: %s contract must have event %s", ErrInvalidABI, iface, name)
+		}
+	}
+	return &parsed, nil
+}
+
+// Default 未登记ABI的合约使用的内置ABI，与本项目部署的合约一致
+func Default(iface string) *abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(defaults[iface]))
+	if err != nil {
+		panic(fmt.Sprintf("built-in %s ABI: %v", iface, err))
+	}
+	return &parsed
+}
+
+var defaults = map[string]string{
+	InterfaceVault: `[
+		{"type":"event","name":"Deposit","inputs":[
+			{"name":"sender","type":"address","indexed":true},
+			{"name":"owner","type":"address","indexed":true},
+			{"name":"assets","type":"uint256"},
+			{"name":"shares","type":"uint256"}]},
+		{"type":"event","name":"Withdraw","inputs":[
+			{"name":"sender","type":"address","indexed":true},
+			{"name":"receiver","type":"address","indexed":true},
+			{"name":"owner","type":"address","indexed":true},
+			{"name":"assets","type":"uint256"},
+			{"name":"shares","type":"uint256"}]},
+		{"type":"event","name":"Harvest","inputs":[
+			{"name":"harvestedAmount","type":"uint256"},
+			{"name":"timestamp","type":"uint256"}]},
+		{"type":"event","name":"StrategyAdded","inputs":[
+			{"name":"strategy","type":"address","indexed":true}]},
+		{"type":"event","name":"StrategyRemoved","inputs":[
+			{"name":"strategy","type":"address","indexed":true}]},
+		{"type":"function","name":"totalAssets","stateMutability":"view","inputs":[],"outputs":[{"type":"uint256"}]},
+		{"type":"function","name":"totalSupply","stateMutability":"view","inputs":[],"outputs":[{"type":"uint256"}]},
+		{"type":"function","name":"decimals","stateMutability":"view","inputs":[],"outputs":[{"type":"uint8"}]}
+	]`,
+	InterfaceStrategy: `[
+		{"type":"event","name":"Harvested","inputs":[
+			{"name":"profit","type":"uint256"},
+			{"name":"timestamp","type":"uint256"}]},
+		{"type":"function","name":"estimatedTotalAssets","stateMutability":"view","inputs":[],"outputs":[{"type":"uint256"}]}
+	]`,
+	InterfaceOracle: `[
+		{"type":"function","name":"latestRoundData","stateMutability":"view","inputs":[],"outputs":[
+			{"name":"roundId","type":"uint80"},
+			{"name":"answer","type":"int256"},
+			{"name":"startedAt","type":"uint256"},
+			{"name":"updatedAt","type":"uint256"},
+			{"name":"answeredInRound","type":"uint80"}]},
+		{"type":"function","name":"decimals","stateMutability":"view","inputs":[],"outputs":[{"type":"uint8"}]}
+	]`,
+}
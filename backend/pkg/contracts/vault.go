@@ -0,0 +1,138 @@
+package contracts
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/chspring1/mya-platform/backend/pkg/events"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// 资金库ABI中按事件名识别的事件
+var vaultEvents = map[string]string{
+	"Deposit":         events.TypeDeposit,
+	"Withdraw":        events.TypeWithdraw,
+	"Harvest":         events.TypeHarvest,
+	"StrategyAdded":   events.TypeStrategyAdded,
+	"StrategyRemoved": events.TypeStrategyRemoved,
+}
+
+// VaultLog 按ABI解码后的资金库事件，金额为未按精度换算的原始值
+type VaultLog struct {
+	Type      string
+	User      common.Address // 存取款的份额所有者
+	Strategy  common.Address // 策略增删
+	Assets    *big.Int       // 存取款资产数量或收获收益
+	Shares    *big.Int
+	Timestamp *big.Int // 收获事件自带的时间戳
+}
+
+// VaultTopics 资金库ABI中需要索引的事件签名，用作 eth_getLogs 的topic过滤
+func VaultTopics(contract *abi.ABI) []common.Hash {
+	var topics []common.Hash
+	for name := range vaultEvents {
+		if event, ok := contract.Events[name]; ok {
+			topics = append(topics, event.ID)
+		}
+	}
+	return topics
+}
+
+// DecodeVaultLog 按ABI解码资金库日志。参数优先按ERC-4626的名称(owner、assets、shares)取值，
+// 名称不同的合约(如只有 user 一个地址参数)按类型和位置取：最后一个indexed地址、依次的非indexed整数
+func DecodeVaultLog(contract *abi.ABI, log types.Log) (*VaultLog, error) {
+	if len(log.Topics) == 0 {
+		return nil, fmt.Errorf("log has no topics")
+	}
+	event, err := contract.EventByID(log.Topics[0])
+	if err != nil {
+		return nil, fmt.Errorf("unexpected topic %s", log.Topics[0].Hex())
+	}
+	kind, ok := vaultEvents[event.Name]
+	if !ok {
+		return nil, fmt.Errorf("event %s is not a vault event", event.Name)
+	}
+
+	values := make(map[string]interface{})
+	if err := event.Inputs.UnpackIntoMap(values, log.Data); err != nil {
+		return nil, fmt.Errorf("unpack %s data: %w", event.Name, err)
+	}
+	var indexed abi.Arguments
+	for _, arg := range event.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	if len(log.Topics)-1 < len(indexed) {
+		return nil, fmt.Errorf("%s has %d indexed topic(s), ABI expects %d", event.Name, len(log.Topics)-1, len(indexed))
+	}
+	if err := abi.ParseTopicsIntoMap(values, indexed, log.Topics[1:len(indexed)+1]); err != nil {
+		return nil, fmt.Errorf("parse %s topics: %w", event.Name, err)
+	}
+
+	decoded := &VaultLog{Type: kind}
+	switch kind {
+	case events.TypeStrategyAdded, events.TypeStrategyRemoved:
+		if decoded.Strategy, ok = address(event, values, "strategy"); !ok {
+			return nil, fmt.Errorf("missing strategy address in %s", event.Name)
+		}
+	case events.TypeDeposit, events.TypeWithdraw:
+		if decoded.User, ok = address(event, values, "owner", "user"); !ok {
+			return nil, fmt.Errorf("missing owner address in %s", event.Name)
+		}
+		if decoded.Assets, ok = uint256(event, values, 0, "assets"); !ok {
+			return nil, fmt.Errorf("missing assets in %s", event.Name)
+		}
+		if decoded.Shares, ok = uint256(event, values, 1, "shares"); !ok {
+			return nil, fmt.Errorf("missing shares in %s", event.Name)
+		}
+	case events.TypeHarvest:
+		if decoded.Assets, ok = uint256(event, values, 0); !ok {
+			return nil, fmt.Errorf("missing amount in %s", event.Name)
+		}
+		if decoded.Timestamp, ok = uint256(event, values, 1, "timestamp"); !ok {
+			return nil, fmt.Errorf("missing timestamp in %s", event.Name)
+		}
+	}
+	return decoded, nil
+}
+
+// address 取名为names之一的地址参数，没有时取最后一个indexed地址参数
+func address(event *abi.Event, values map[string]interface{}, names ...string) (common.Address, bool) {
+	for _, name := range names {
+		if value, ok := values[name].(common.Address); ok {
+			return value, true
+		}
+	}
+	for i := len(event.Inputs) - 1; i >= 0; i-- {
+		arg := event.Inputs[i]
+		if arg.Indexed && arg.Type.T == abi.AddressTy {
+			value, ok := values[arg.Name].(common.Address)
+			return value, ok
+		}
+	}
+	return common.Address{}, false
+}
+
+// uint256 取名为names之一的整数参数，没有时取第position个非indexed整数参数
+func uint256(event *abi.Event, values map[string]interface{}, position int, names ...string) (*big.Int, bool) {
+	for _, name := range names {
+		if value, ok := values[name].(*big.Int); ok {
+			return value, true
+		}
+	}
+	for _, arg := range event.Inputs.NonIndexed() {
+		if arg.Type.T != abi.UintTy {
+			continue
+		}
+		if position == 0 {
+			value, ok := values[arg.Name].(*big.Int)
+			return value, ok
+		}
+		position--
+	}
+	return nil, false
+}
@@ -2,19 +2,51 @@ package database
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/chspring1/mya-platform/backend/migrations"
 	"github.com/chspring1/mya-platform/backend/pkg/config"
 	"github.com/chspring1/mya-platform/backend/pkg/logger"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
 )
 
-var DB *gorm.DB
+// readHeavyTables 读多写少的表，配置了副本时这些表的查询走副本
+var readHeavyTables = []interface{}{
+	"vaults",
+	"apy_history",
+	"apy_history_daily",
+	"strategy_snapshots",
+	"protocol_rates",
+	"token_prices",
+}
+
+const maxRetryBackoff = 30 * time.Second
+
+var (
+	DB       *gorm.DB
+	resolver *dbresolver.DBResolver
+)
 
-// Init 初始化数据库连接
-func Init() {
+// 支持的数据库驱动
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Init 初始化数据库连接，在connect_timeout窗口内按指数退避重试，最终失败时返回错误。
+// models 只用于 database.driver=sqlite 时自动建表，Postgres的表结构始终由 migrations 管理
+func Init(models ...interface{}) error {
 	cfg := config.Load()
+	switch cfg.Database.Driver {
+	case DriverSQLite:
+		return initSQLite(cfg.Database, models)
+	case "", DriverPostgres:
+	default:
+		return fmt.Errorf("unsupported database driver %q", cfg.Database.Driver)
+	}
 
 	// 构建数据库连接字符串
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
@@ -28,37 +60,151 @@ func Init() {
 	logger.Info(fmt.Sprintf("Connecting to database: %s@%s:%s/%s",
 		cfg.Database.User, cfg.Database.Host, cfg.Database.Port, cfg.Database.DBName))
 
-	var err error
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := connect(dsn, cfg.Database)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Failed to connect to database: %v", err))
-		return
+		return fmt.Errorf("connect to database: %w", err)
 	}
+	DB = db
 
 	logger.Info("✅ Database connection established")
 
+	// 查询耗时指标与慢查询日志，先于读写分离注册以覆盖副本上的查询
+	if err := DB.Use(&queryMetrics{slowThreshold: time.Duration(cfg.Database.SlowQueryThreshold) * time.Millisecond}); err != nil {
+		return fmt.Errorf("register query metrics: %w", err)
+	}
+
+	// 配置读写分离，需在连接池配置之前注册以便副本共享同样的连接池设置
+	if err := registerReplicas(cfg.Database); err != nil {
+		return fmt.Errorf("register read replicas: %w", err)
+	}
+
+	// 配置连接池
+	if err := configurePool(cfg.Database); err != nil {
+		return fmt.Errorf("configure connection pool: %w", err)
+	}
+
 	// 测试连接
 	var version string
-	DB.Raw("SELECT version()").Scan(&version)
+	if err := DB.Raw("SELECT version()").Scan(&version).Error; err != nil {
+		return fmt.Errorf("query server version: %w", err)
+	}
 	logger.Info("📊 Connected to PostgreSQL")
 
-	// 检查表是否存在
-	checkTables()
+	// 检查或执行数据库迁移
+	return migrate(cfg.Database.AutoMigrate)
 }
 
-// checkTables 检查必要的表是否存在
-func checkTables() {
-	tables := []string{"users", "vaults", "strategies", "transactions"}
-
-	for _, table := range tables {
-		var exists bool
-		DB.Raw("SELECT EXISTS (SELECT FROM information_schema.tables WHERE table_schema = 'public' AND table_name = ?)", table).Scan(&exists)
-		if exists {
-			logger.Info(fmt.Sprintf("✅ Table %s exists", table))
-		} else {
-			logger.Info(fmt.Sprintf("❌ Table %s does not exist", table))
+// connect 打开连接，失败后等待retry_interval并逐次翻倍(最多30秒)，直到超过connect_timeout
+func connect(dsn string, cfg config.DatabaseConfig) (*gorm.DB, error) {
+	deadline := time.Now().Add(time.Duration(cfg.ConnectTimeout) * time.Second)
+	backoff := time.Duration(cfg.RetryInterval) * time.Second
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	for attempt := 1; ; attempt++ {
+		db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+		if err == nil {
+			return db, nil
+		}
+
+		if time.Now().Add(backoff).After(deadline) {
+			return nil, fmt.Errorf("giving up after %d attempt(s): %w", attempt, err)
 		}
+		logger.Error(fmt.Sprintf("Failed to connect to database (attempt %d), retrying in %s: %v", attempt, backoff, err))
+		time.Sleep(backoff)
+
+		backoff *= 2
+		if backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+}
+
+// registerReplicas 注册只读副本，资金库、历史与分析表的查询走副本，写入和其他表仍走主库
+func registerReplicas(cfg config.DatabaseConfig) error {
+	if len(cfg.Replicas) == 0 {
+		return nil
+	}
+
+	replicas := make([]gorm.Dialector, 0, len(cfg.Replicas))
+	for _, dsn := range cfg.Replicas {
+		replicas = append(replicas, postgres.Open(dsn))
 	}
+
+	resolver = dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   dbresolver.RandomPolicy{},
+	}, readHeavyTables...)
+	if err := DB.Use(resolver); err != nil {
+		resolver = nil
+		return err
+	}
+
+	logger.Info(fmt.Sprintf("📚 %d read replica(s) registered", len(cfg.Replicas)))
+	return nil
+}
+
+// configurePool 应用连接池配置，避免高并发时耗尽Postgres连接
+func configurePool(cfg config.DatabaseConfig) error {
+	maxLifetime := time.Duration(cfg.ConnMaxLifetime) * time.Second
+	maxIdleTime := time.Duration(cfg.ConnMaxIdleTime) * time.Second
+
+	// 副本连接池由dbresolver管理
+	if resolver != nil {
+		resolver.SetMaxOpenConns(cfg.MaxOpenConns).
+			SetMaxIdleConns(cfg.MaxIdleConns).
+			SetConnMaxLifetime(maxLifetime).
+			SetConnMaxIdleTime(maxIdleTime)
+	}
+
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(maxLifetime)
+	sqlDB.SetConnMaxIdleTime(maxIdleTime)
+
+	logger.Info(fmt.Sprintf("Database pool: max_open=%d max_idle=%d max_lifetime=%ds",
+		cfg.MaxOpenConns, cfg.MaxIdleConns, cfg.ConnMaxLifetime))
+	return nil
+}
+
+// migrate 开启auto_migrate时执行未应用的迁移，否则只提示待执行的迁移数量
+func migrate(auto bool) error {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return fmt.Errorf("get sql.DB for migrations: %w", err)
+	}
+
+	migrator, err := migrations.New(sqlDB)
+	if err != nil {
+		return fmt.Errorf("load migrations: %w", err)
+	}
+
+	if !auto {
+		pending, err := migrator.Pending()
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to check migration status: %v", err))
+			return nil
+		}
+		if pending > 0 {
+			logger.Info(fmt.Sprintf("⚠️ %d pending migration(s), run `go run ./cmd/migrate up`", pending))
+		}
+		return nil
+	}
+
+	applied, err := migrator.Up()
+	for _, m := range applied {
+		logger.Info(fmt.Sprintf("✅ Applied migration %06d_%s", m.Version, m.Name))
+	}
+	if err != nil {
+		return fmt.Errorf("run migrations: %w", err)
+	}
+	return nil
 }
 
 // GetDB 获取数据库连接
@@ -68,6 +214,9 @@ func GetDB() *gorm.DB {
 
 // Close 关闭数据库连接
 func Close() error {
+	if DB == nil {
+		return nil
+	}
 	sqlDB, err := DB.DB()
 	if err != nil {
 		return err
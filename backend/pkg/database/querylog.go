@@ -0,0 +1,109 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/metrics"
+
+	"gorm.io/gorm"
+)
+
+const queryStartKey = "query_metrics:start"
+
+var (
+	sqlStringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+	sqlPlaceholder   = regexp.MustCompile(`\$\d+`)
+	sqlNumber        = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	sqlValueList     = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)+\s*\)`)
+	sqlWhitespace    = regexp.MustCompile(`\s+`)
+)
+
+// queryMetrics GORM插件，记录每条查询的耗时到Prometheus，并输出超过阈值的慢查询
+type queryMetrics struct {
+	slowThreshold time.Duration
+}
+
+func (p *queryMetrics) Name() string {
+	return "query_metrics"
+}
+
+// Initialize 在每类操作的GORM回调前后挂上计时
+func (p *queryMetrics) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	hooks := []struct {
+		operation string
+		before    error
+		after     error
+	}{
+		{"create",
+			cb.Create().Before("gorm:create").Register("query_metrics:before_create", p.before),
+			cb.Create().After("gorm:create").Register("query_metrics:after_create", p.after("create"))},
+		{"query",
+			cb.Query().Before("gorm:query").Register("query_metrics:before_query", p.before),
+			cb.Query().After("gorm:query").Register("query_metrics:after_query", p.after("query"))},
+		{"update",
+			cb.Update().Before("gorm:update").Register("query_metrics:before_update", p.before),
+			cb.Update().After("gorm:update").Register("query_metrics:after_update", p.after("update"))},
+		{"delete",
+			cb.Delete().Before("gorm:delete").Register("query_metrics:before_delete", p.before),
+			cb.Delete().After("gorm:delete").Register("query_metrics:after_delete", p.after("delete"))},
+		{"row",
+			cb.Row().Before("gorm:row").Register("query_metrics:before_row", p.before),
+			cb.Row().After("gorm:row").Register("query_metrics:after_row", p.after("row"))},
+		{"raw",
+			cb.Raw().Before("gorm:raw").Register("query_metrics:before_raw", p.before),
+			cb.Raw().After("gorm:raw").Register("query_metrics:after_raw", p.after("raw"))},
+	}
+	for _, h := range hooks {
+		if h.before != nil {
+			return fmt.Errorf("register %s hook: %w", h.operation, h.before)
+		}
+		if h.after != nil {
+			return fmt.Errorf("register %s hook: %w", h.operation, h.after)
+		}
+	}
+	return nil
+}
+
+func (p *queryMetrics) before(db *gorm.DB) {
+	db.InstanceSet(queryStartKey, time.Now())
+}
+
+func (p *queryMetrics) after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(queryStartKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+		elapsed := time.Since(start)
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "raw"
+		}
+		metrics.DBQueryDuration.WithLabelValues(table, operation).Observe(elapsed.Seconds())
+
+		if p.slowThreshold > 0 && elapsed >= p.slowThreshold {
+			logger.Info(fmt.Sprintf("🐢 Slow query (%s, %s on %s, %d rows): %s",
+				elapsed.Round(time.Millisecond), operation, table, db.RowsAffected,
+				normalizeSQL(db.Statement.SQL.String())))
+		}
+	}
+}
+
+// normalizeSQL 去掉字面量和参数，合并IN列表与空白，使同类查询的日志可以聚合
+func normalizeSQL(sql string) string {
+	sql = sqlStringLiteral.ReplaceAllString(sql, "?")
+	sql = sqlPlaceholder.ReplaceAllString(sql, "?")
+	sql = sqlNumber.ReplaceAllString(sql, "?")
+	sql = sqlValueList.ReplaceAllString(sql, "(?)")
+	return strings.TrimSpace(sqlWhitespace.ReplaceAllString(sql, " "))
+}
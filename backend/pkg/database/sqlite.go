@@ -0,0 +1,51 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// 进程内共享缓存的内存库，连接关闭后数据即丢失
+const sqliteDSN = "file::memory:?cache=shared"
+
+// initSQLite 打开内存库并按模型自动建表。内存库只保留一个连接：
+// 共享缓存下多个连接并发写会直接返回 database table is locked，且最后一个连接关闭时库会被释放
+func initSQLite(cfg config.DatabaseConfig, models []interface{}) error {
+	db, err := gorm.Open(sqlite.Open(sqliteDSN), &gorm.Config{})
+	if err != nil {
+		return fmt.Errorf("open sqlite: %w", err)
+	}
+	DB = db
+
+	if err := DB.Use(&queryMetrics{slowThreshold: time.Duration(cfg.SlowQueryThreshold) * time.Millisecond}); err != nil {
+		return fmt.Errorf("register query metrics: %w", err)
+	}
+
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	sqlDB.SetMaxOpenConns(1)
+	sqlDB.SetMaxIdleConns(1)
+	sqlDB.SetConnMaxLifetime(0)
+	sqlDB.SetConnMaxIdleTime(0)
+
+	if err := DB.AutoMigrate(models...); err != nil {
+		return fmt.Errorf("automigrate sqlite schema: %w", err)
+	}
+
+	logger.Info(fmt.Sprintf("🧪 Using in-memory SQLite database, %d table(s) automigrated; data is lost on exit", len(models)))
+	return nil
+}
+
+// IsSQLite 当前是否运行在SQLite内存库上。迁移脚本在该模式下不执行，
+// 使用Postgres专有语法(date_trunc、DISTINCT ON、GREATEST、UPDATE ... FROM (VALUES ...))的仓储查询按此分支改用SQLite的写法
+func IsSQLite() bool {
+	return DB != nil && DB.Dialector.Name() == sqlite.DriverName
+}
@@ -0,0 +1,85 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// 链上事件类型
+const (
+	TypeDeposit    = "deposit"
+	TypeWithdraw   = "withdraw"
+	TypeVaultStats = "vault_stats"
+	TypeHarvest    = "harvest"
+
+	TypeStrategyAdded   = "strategy_added"
+	TypeStrategyRemoved = "strategy_removed"
+)
+
+// ChainEvent 索引器发布的原始链上事件
+type ChainEvent struct {
+	Type        string          `json:"type"`
+	ChainID     uint            `json:"chain_id"`
+	BlockNumber uint64          `json:"block_number"`
+	TxHash      string          `json:"tx_hash"`
+	LogIndex    uint            `json:"log_index"`
+	Vault       string          `json:"vault"`
+	User        string          `json:"user,omitempty"`     // deposit/withdraw
+	Assets      decimal.Decimal `json:"assets"`             // deposit/withdraw 的资产数量，harvest 的收益数量
+	Shares      decimal.Decimal `json:"shares"`             // deposit/withdraw 的份额数量
+	TVL         decimal.Decimal `json:"tvl"`                // vault_stats
+	APYCurrent  float64         `json:"apy_current"`        // vault_stats
+	APYWeekly   float64         `json:"apy_weekly"`         // vault_stats
+	Strategy    string          `json:"strategy,omitempty"` // strategy_added/strategy_removed，harvest 可选
+	Timestamp   time.Time       `json:"timestamp"`
+}
+
+var ErrInvalidEvent = errors.New("invalid chain event")
+
+// Decode 解析并校验旧版JSON格式的事件，格式错误的消息无法通过重试修复
+func Decode(data []byte) (*ChainEvent, error) {
+	var event ChainEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	if err := event.Validate(); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// Validate 校验事件必填字段
+func (e *ChainEvent) Validate() error {
+	if e.Vault == "" {
+		return fmt.Errorf("%w: missing vault", ErrInvalidEvent)
+	}
+
+	switch e.Type {
+	case TypeDeposit, TypeWithdraw:
+		if e.User == "" || e.TxHash == "" {
+			return fmt.Errorf("%w: %s requires user and tx_hash", ErrInvalidEvent, e.Type)
+		}
+		if !e.Assets.IsPositive() || e.Shares.IsNegative() {
+			return fmt.Errorf("%w: %s requires positive assets", ErrInvalidEvent, e.Type)
+		}
+	case TypeHarvest:
+		if e.TxHash == "" || !e.Assets.IsPositive() {
+			return fmt.Errorf("%w: harvest requires tx_hash and positive assets", ErrInvalidEvent)
+		}
+	case TypeStrategyAdded, TypeStrategyRemoved:
+		if e.Strategy == "" || e.TxHash == "" {
+			return fmt.Errorf("%w: %s requires strategy and tx_hash", ErrInvalidEvent, e.Type)
+		}
+	case TypeVaultStats:
+		if e.TVL.IsNegative() {
+			return fmt.Errorf("%w: negative tvl", ErrInvalidEvent)
+		}
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidEvent, e.Type)
+	}
+	return nil
+}
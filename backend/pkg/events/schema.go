@@ -0,0 +1,178 @@
+package events
+
+import (
+	"embed"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hamba/avro/v2"
+	"github.com/shopspring/decimal"
+)
+
+//go:embed schemas/*.avsc
+var schemaFiles embed.FS
+
+// 消息头：事件名与Avro schema版本，未携带版本头的消息按旧版JSON格式解析
+const (
+	HeaderSchema        = "x-schema"
+	HeaderSchemaVersion = "x-schema-version"
+
+	SchemaName = "chain_event"
+)
+
+// CurrentVersion 发布事件时使用的schema版本，新增版本时必须与之前所有版本兼容
+const CurrentVersion = 2
+
+// LegacyJSONVersion 引入schema之前的JSON格式
+const LegacyJSONVersion = 0
+
+var schemas = loadSchemas()
+
+func loadSchemas() map[int]avro.Schema {
+	loaded := make(map[int]avro.Schema, CurrentVersion)
+	for v := 1; v <= CurrentVersion; v++ {
+		data, err := schemaFiles.ReadFile(fmt.Sprintf("schemas/%s.v%d.avsc", SchemaName, v))
+		if err != nil {
+			panic(fmt.Sprintf("events: missing schema version %d: %v", v, err))
+		}
+		loaded[v] = avro.MustParse(string(data))
+	}
+	return loaded
+}
+
+// CheckCompatibility 校验当前版本可以读取所有旧版本写入的消息，
+// 保证升级消费者前已发布的事件仍能被处理
+func CheckCompatibility() error {
+	current := schemas[CurrentVersion]
+	compat := avro.NewSchemaCompatibility()
+	for v := 1; v < CurrentVersion; v++ {
+		if err := compat.Compatible(current, schemas[v]); err != nil {
+			return fmt.Errorf("%s v%d cannot read v%d: %w", SchemaName, CurrentVersion, v, err)
+		}
+	}
+	return nil
+}
+
+// chainEventRecord ChainEvent在Avro中的表示
+type chainEventRecord struct {
+	Type        string    `avro:"type"`
+	ChainID     int64     `avro:"chain_id"`
+	BlockNumber int64     `avro:"block_number"`
+	TxHash      string    `avro:"tx_hash"`
+	LogIndex    int64     `avro:"log_index"`
+	Vault       string    `avro:"vault"`
+	User        string    `avro:"user"`
+	Assets      string    `avro:"assets"`
+	Shares      string    `avro:"shares"`
+	TVL         string    `avro:"tvl"`
+	APYCurrent  float64   `avro:"apy_current"`
+	APYWeekly   float64   `avro:"apy_weekly"`
+	Timestamp   time.Time `avro:"timestamp"`
+	Strategy    string    `avro:"strategy"`
+}
+
+// Encode 校验事件并按当前schema编码，返回负载和应写入的版本号
+func Encode(event *ChainEvent) ([]byte, int, error) {
+	if err := event.Validate(); err != nil {
+		return nil, 0, err
+	}
+	if event.Timestamp.IsZero() {
+		return nil, 0, fmt.Errorf("%w: missing timestamp", ErrInvalidEvent)
+	}
+
+	data, err := avro.Marshal(schemas[CurrentVersion], chainEventRecord{
+		Type:        event.Type,
+		ChainID:     int64(event.ChainID),
+		BlockNumber: int64(event.BlockNumber),
+		TxHash:      event.TxHash,
+		LogIndex:    int64(event.LogIndex),
+		Vault:       event.Vault,
+		User:        event.User,
+		Assets:      event.Assets.String(),
+		Shares:      event.Shares.String(),
+		TVL:         event.TVL.String(),
+		APYCurrent:  event.APYCurrent,
+		APYWeekly:   event.APYWeekly,
+		Timestamp:   event.Timestamp,
+		Strategy:    event.Strategy,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	return data, CurrentVersion, nil
+}
+
+// ParseVersion 解析消息头中的schema版本，为空表示旧版JSON
+func ParseVersion(header string) (int, error) {
+	if header == "" {
+		return LegacyJSONVersion, nil
+	}
+	version, err := strconv.Atoi(header)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid schema version %q", ErrInvalidEvent, header)
+	}
+	return version, nil
+}
+
+// DecodeVersion 按写入时的schema版本解码并用当前版本解析，未知版本视为格式错误
+func DecodeVersion(version int, data []byte) (*ChainEvent, error) {
+	if version == LegacyJSONVersion {
+		return Decode(data)
+	}
+
+	writer, ok := schemas[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported schema version %d", ErrInvalidEvent, version)
+	}
+	schema := writer
+	if version != CurrentVersion {
+		resolved, err := avro.NewSchemaCompatibility().Resolve(schemas[CurrentVersion], writer)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+		}
+		schema = resolved
+	}
+
+	var record chainEventRecord
+	if err := avro.Unmarshal(schema, data, &record); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+
+	event := &ChainEvent{
+		Type:        record.Type,
+		ChainID:     uint(record.ChainID),
+		BlockNumber: uint64(record.BlockNumber),
+		TxHash:      record.TxHash,
+		LogIndex:    uint(record.LogIndex),
+		Vault:       record.Vault,
+		User:        record.User,
+		APYCurrent:  record.APYCurrent,
+		APYWeekly:   record.APYWeekly,
+		Timestamp:   record.Timestamp,
+		Strategy:    record.Strategy,
+	}
+	var err error
+	if event.Assets, err = parseAmount("assets", record.Assets); err != nil {
+		return nil, err
+	}
+	if event.Shares, err = parseAmount("shares", record.Shares); err != nil {
+		return nil, err
+	}
+	if event.TVL, err = parseAmount("tvl", record.TVL); err != nil {
+		return nil, err
+	}
+
+	if err := event.Validate(); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func parseAmount(field, value string) (decimal.Decimal, error) {
+	amount, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("%w: invalid %s %q", ErrInvalidEvent, field, value)
+	}
+	return amount, nil
+}
@@ -0,0 +1,61 @@
+// Package forecast 时间序列的统计预测。
+//
+// EWMA 即简单指数平滑：水平值 L_t = α·y_t + (1-α)·L_{t-1}，各期的点预测都等于最后的水平值。
+// 误差按一步预测残差的均方根 σ 估计，h 步预测的方差为 σ²·(1 + (h-1)·α²)，
+// 置信区间随预测期数变宽。该模型不含趋势和季节项，适合围绕均值波动的APY序列
+package forecast
+
+import (
+	"errors"
+	"math"
+)
+
+// MinSamples 拟合所需的最少观测数
+const MinSamples = 7
+
+var (
+	ErrInsufficientData = errors.New("not enough observations to forecast")
+	ErrInvalidParams    = errors.New("alpha must be in (0, 1] and confidence in (0, 1)")
+)
+
+// Point 第 Step 期的点预测及置信区间
+type Point struct {
+	Step  int
+	Mean  float64
+	Lower float64
+	Upper float64
+}
+
+// Fit 拟合结果：最后的水平值和一步预测残差的均方根
+type Fit struct {
+	Level   float64
+	Sigma   float64
+	Samples int
+}
+
+// EWMA 按时间升序的观测值预测之后 horizon 期，confidence 为置信区间的置信水平(如0.9)
+func EWMA(series []float64, alpha float64, horizon int, confidence float64) (*Fit, []Point, error) {
+	if alpha <= 0 || alpha > 1 || confidence <= 0 || confidence >= 1 {
+		return nil, nil, ErrInvalidParams
+	}
+	if len(series) < MinSamples {
+		return nil, nil, ErrInsufficientData
+	}
+
+	level := series[0]
+	var squared float64
+	for _, y := range series[1:] {
+		residual := y - level
+		squared += residual * residual
+		level = alpha*y + (1-alpha)*level
+	}
+	fit := &Fit{Level: level, Sigma: math.Sqrt(squared / float64(len(series)-1)), Samples: len(series)}
+
+	z := math.Sqrt2 * math.Erfinv(confidence)
+	points := make([]Point, horizon)
+	for h := 1; h <= horizon; h++ {
+		spread := z * fit.Sigma * math.Sqrt(1+float64(h-1)*alpha*alpha)
+		points[h-1] = Point{Step: h, Mean: level, Lower: level - spread, Upper: level + spread}
+	}
+	return fit, points, nil
+}
@@ -1,23 +1,48 @@
 package logger
 
 import (
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
-var Log *zap.Logger
+var (
+	Log   *zap.Logger
+	level = zap.NewAtomicLevel()
+)
 
 func Init() {
-	config := zap.NewDevelopmentConfig()
-	config.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
-	config.EncoderConfig.TimeKey = "timestamp"
-	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	SetLevel(config.Load().Log.Level)
+
+	zapConfig := zap.NewDevelopmentConfig()
+	zapConfig.Level = level
+	zapConfig.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	zapConfig.EncoderConfig.TimeKey = "timestamp"
+	zapConfig.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 
 	var err error
-	Log, err = config.Build()
+	Log, err = zapConfig.Build()
 	if err != nil {
 		panic(err)
 	}
+
+	// 日志级别支持热加载
+	config.Subscribe(func(prev, next *config.Config) {
+		if prev.Log.Level != next.Log.Level {
+			SetLevel(next.Log.Level)
+			Info("Log level changed to " + level.String())
+		}
+	})
+}
+
+// SetLevel 修改日志级别，无法识别的级别保持不变
+func SetLevel(name string) {
+	var l zapcore.Level
+	if err := l.UnmarshalText([]byte(name)); err != nil {
+		return
+	}
+	level.SetLevel(l)
 }
 
 func Info(message string) {
@@ -0,0 +1,30 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// 业务指标由 service.BusinessCollector 在抓取时汇总，这里只定义名称和标签
+var (
+	TotalTVLUSD = prometheus.NewDesc("mya_tvl_usd",
+		"Total TVL of active vaults in USD, excluding vaults without a price.", nil, nil)
+
+	VaultTVL = prometheus.NewDesc("mya_vault_tvl",
+		"Vault TVL in units of the vault asset.", []string{"vault", "name", "chain_id"}, nil)
+
+	VaultTVLUSD = prometheus.NewDesc("mya_vault_tvl_usd",
+		"Vault TVL in USD at the current asset price.", []string{"vault", "name", "chain_id"}, nil)
+
+	VaultAPY = prometheus.NewDesc("mya_vault_apy",
+		"Current vault APY as a fraction.", []string{"vault", "name", "chain_id"}, nil)
+
+	Users = prometheus.NewDesc("mya_users",
+		"Registered users.", nil, nil)
+
+	Volume24hUSD = prometheus.NewDesc("mya_volume_24h_usd",
+		"Confirmed deposit or withdraw volume over the last 24 hours in USD.", []string{"type"}, nil)
+
+	RiskAlertsOpen = prometheus.NewDesc("mya_risk_alerts_open",
+		"Open risk alerts by type and level.", []string{"type", "level"}, nil)
+
+	UserAlertsTriggered = prometheus.NewDesc("mya_user_alerts_triggered",
+		"User APY subscriptions and alert rules currently in triggered state.", []string{"source"}, nil)
+)
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry 进程内所有指标的注册表，通过 /metrics 暴露给Prometheus
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests 按路由模板和状态码统计的请求数
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "mya",
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPDuration 按路由模板统计的请求耗时
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "mya",
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by method and route.",
+		Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}, []string{"method", "route"})
+
+	// DBQueryDuration 按表和操作类型统计的数据库查询耗时
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "mya",
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Database query latency by table and operation.",
+		Buckets:   []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+	}, []string{"table", "operation"})
+
+	// CacheRequests 缓存读取结果，result 为 hit 或 miss
+	CacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "mya",
+		Subsystem: "cache",
+		Name:      "requests_total",
+		Help:      "Cache lookups by result (hit or miss).",
+	}, []string{"result"})
+
+	// DependencyCalls 外部依赖调用结果，result 为 success、failure 或 rejected(熔断中直接拒绝)
+	DependencyCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "mya",
+		Subsystem: "dependency",
+		Name:      "calls_total",
+		Help:      "External dependency calls by dependency and result (success, failure, rejected).",
+	}, []string{"dependency", "result"})
+
+	// DependencyRetries 外部依赖调用的重试次数
+	DependencyRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "mya",
+		Subsystem: "dependency",
+		Name:      "retries_total",
+		Help:      "Retries of external dependency calls.",
+	}, []string{"dependency"})
+
+	// DependencyBreakerState 外部依赖的熔断器状态：0 关闭，1 半开，2 打开
+	DependencyBreakerState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "mya",
+		Subsystem: "dependency",
+		Name:      "breaker_state",
+		Help:      "Circuit breaker state per external dependency (0 closed, 1 half-open, 2 open).",
+	}, []string{"dependency"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPDuration,
+		DBQueryDuration,
+		CacheRequests,
+		DependencyCalls,
+		DependencyRetries,
+		DependencyBreakerState,
+	)
+}
+
+// Handler 返回Prometheus抓取接口
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
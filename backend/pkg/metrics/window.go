@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	windowBuckets = 5    // 滚动窗口的分钟数
+	bucketSamples = 2048 // 每分钟最多保留的耗时样本，超出后按蓄水池抽样替换
+)
+
+// WindowStats 最近几分钟的请求统计
+type WindowStats struct {
+	Window            string  `json:"window"`
+	Requests          int64   `json:"requests"`
+	Errors            int64   `json:"errors"`
+	RequestsPerMinute float64 `json:"requests_per_minute"`
+	ErrorRate         float64 `json:"error_rate"`
+	P50               string  `json:"p50"`
+	P95               string  `json:"p95"`
+	P99               string  `json:"p99"`
+}
+
+type bucket struct {
+	minute    int64
+	count     int64
+	errors    int64
+	latencies []time.Duration
+}
+
+// Window 按分钟分桶的滚动请求统计，用于管理后台展示实时速率和分位数。
+// Prometheus直方图适合长期趋势，但无法直接给出"最近5分钟的p95"
+type Window struct {
+	mu      sync.Mutex
+	buckets [windowBuckets]bucket
+	seed    uint64
+}
+
+// Requests 全局HTTP请求窗口
+var Requests = &Window{}
+
+// Observe 记录一次请求，isError 表示5xx
+func (w *Window) Observe(latency time.Duration, isError bool) {
+	now := time.Now().Unix() / 60
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	b := &w.buckets[now%windowBuckets]
+	if b.minute != now {
+		*b = bucket{minute: now, latencies: b.latencies[:0]}
+	}
+	b.count++
+	if isError {
+		b.errors++
+	}
+	if len(b.latencies) < bucketSamples {
+		b.latencies = append(b.latencies, latency)
+		return
+	}
+	// 蓄水池抽样，保证高流量时样本仍代表整分钟的分布
+	w.seed = w.seed*6364136223846793005 + 1442695040888963407
+	if i := w.seed % uint64(b.count); i < bucketSamples {
+		b.latencies[i] = latency
+	}
+}
+
+// Snapshot 汇总窗口内的请求数、错误率和耗时分位数
+func (w *Window) Snapshot() WindowStats {
+	now := time.Now().Unix() / 60
+
+	w.mu.Lock()
+	var stats WindowStats
+	var samples []time.Duration
+	oldest := now
+	for i := range w.buckets {
+		b := &w.buckets[i]
+		if b.count == 0 || now-b.minute >= windowBuckets {
+			continue
+		}
+		if b.minute < oldest {
+			oldest = b.minute
+		}
+		stats.Requests += b.count
+		stats.Errors += b.errors
+		samples = append(samples, b.latencies...)
+	}
+	w.mu.Unlock()
+
+	// 当前分钟只过了一部分，按实际经过的时间计算速率
+	elapsed := time.Since(time.Unix(oldest*60, 0)).Minutes()
+	if elapsed < 1 {
+		elapsed = 1
+	}
+	stats.Window = (windowBuckets * time.Minute).String()
+	stats.RequestsPerMinute = float64(stats.Requests) / elapsed
+	if stats.Requests > 0 {
+		stats.ErrorRate = float64(stats.Errors) / float64(stats.Requests)
+	}
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	stats.P50 = percentile(samples, 0.50).String()
+	stats.P95 = percentile(samples, 0.95).String()
+	stats.P99 = percentile(samples, 0.99).String()
+	return stats
+}
+
+// percentile 在已排序的样本上取分位数(最近秩法)
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
@@ -0,0 +1,207 @@
+// Package morpho 读取Morpho Blue借贷市场的状态：供应和借款总量、利率模型给出的借款利率、清算LTV和预言机价格。
+//
+// 利率按Morpho的口径计算：借款APY = e^(每秒借款利率 × 一年秒数) - 1，
+// 供应APY = 借款APY × 利用率 × (1 - 协议费率)
+package morpho
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var ErrMarketNotFound = errors.New("morpho market not found")
+
+const secondsPerYear = 365 * 24 * 3600
+
+// 市场读取用到的Morpho Blue、利率模型(IRM)和预言机方法
+var (
+	morphoABI = mustParse(`[
+		{"type":"function","name":"market","stateMutability":"view","inputs":[{"name":"id","type":"bytes32"}],"outputs":[
+			{"name":"totalSupplyAssets","type":"uint128"},{"name":"totalSupplyShares","type":"uint128"},
+			{"name":"totalBorrowAssets","type":"uint128"},{"name":"totalBorrowShares","type":"uint128"},
+			{"name":"lastUpdate","type":"uint128"},{"name":"fee","type":"uint128"}]},
+		{"type":"function","name":"idToMarketParams","stateMutability":"view","inputs":[{"name":"id","type":"bytes32"}],"outputs":[
+			{"name":"loanToken","type":"address"},{"name":"collateralToken","type":"address"},
+			{"name":"oracle","type":"address"},{"name":"irm","type":"address"},{"name":"lltv","type":"uint256"}]}
+	]`)
+	irmABI = mustParse(`[
+		{"type":"function","name":"borrowRateView","stateMutability":"view","inputs":[
+			{"name":"marketParams","type":"tuple","components":[
+				{"name":"loanToken","type":"address"},{"name":"collateralToken","type":"address"},
+				{"name":"oracle","type":"address"},{"name":"irm","type":"address"},{"name":"lltv","type":"uint256"}]},
+			{"name":"market","type":"tuple","components":[
+				{"name":"totalSupplyAssets","type":"uint128"},{"name":"totalSupplyShares","type":"uint128"},
+				{"name":"totalBorrowAssets","type":"uint128"},{"name":"totalBorrowShares","type":"uint128"},
+				{"name":"lastUpdate","type":"uint128"},{"name":"fee","type":"uint128"}]}],
+		 "outputs":[{"type":"uint256"}]}
+	]`)
+	oracleABI = mustParse(`[
+		{"type":"function","name":"price","stateMutability":"view","inputs":[],"outputs":[{"type":"uint256"}]}
+	]`)
+)
+
+var wad = new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))
+
+// Params 市场的不可变参数，市场ID即其ABI编码的keccak256
+type Params struct {
+	LoanToken       common.Address
+	CollateralToken common.Address
+	Oracle          common.Address
+	Irm             common.Address
+	Lltv            *big.Int
+}
+
+// state 与合约中 Market 结构体一致，调用 borrowRateView 时按字段名编码
+type state struct {
+	TotalSupplyAssets *big.Int
+	TotalSupplyShares *big.Int
+	TotalBorrowAssets *big.Int
+	TotalBorrowShares *big.Int
+	LastUpdate        *big.Int
+	Fee               *big.Int
+}
+
+// Market 市场在同一区块上的参数和状态。借贷总量截至上次计息(lastUpdate)，
+// BorrowRate 为每秒借款利率(WAD)，OraclePrice 为预言机原始价格(1e36精度)，不可读时为nil
+type Market struct {
+	BlockNumber       uint64
+	Params            Params
+	TotalSupplyAssets *big.Int
+	TotalBorrowAssets *big.Int
+	Fee               *big.Int
+	BorrowRate        *big.Int
+	OraclePrice       *big.Int
+}
+
+// Read 在最新区块上读取 morpho 合约中市场id的参数、借贷总量、借款利率和预言机价格。
+// 预言机调用失败不影响其余读取，结果中 OraclePrice 为nil
+func Read(ctx context.Context, chainID uint, morpho string, id common.Hash) (*Market, error) {
+	block, err := blockchain.BlockNumber(ctx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	r := reader{ctx: ctx, chainID: chainID, block: block}
+
+	out, err := r.call(morpho, morphoABI, "idToMarketParams", id)
+	if err != nil {
+		return nil, fmt.Errorf("read market params: %w", err)
+	}
+	params := Params{
+		LoanToken:       out[0].(common.Address),
+		CollateralToken: out[1].(common.Address),
+		Oracle:          out[2].(common.Address),
+		Irm:             out[3].(common.Address),
+		Lltv:            out[4].(*big.Int),
+	}
+	if params.LoanToken == (common.Address{}) {
+		return nil, fmt.Errorf("%w: %s", ErrMarketNotFound, id.Hex())
+	}
+
+	if out, err = r.call(morpho, morphoABI, "market", id); err != nil {
+		return nil, fmt.Errorf("read market: %w", err)
+	}
+	st := state{
+		TotalSupplyAssets: out[0].(*big.Int),
+		TotalSupplyShares: out[1].(*big.Int),
+		TotalBorrowAssets: out[2].(*big.Int),
+		TotalBorrowShares: out[3].(*big.Int),
+		LastUpdate:        out[4].(*big.Int),
+		Fee:               out[5].(*big.Int),
+	}
+	market := &Market{
+		BlockNumber:       block,
+		Params:            params,
+		TotalSupplyAssets: st.TotalSupplyAssets,
+		TotalBorrowAssets: st.TotalBorrowAssets,
+		Fee:               st.Fee,
+		BorrowRate:        new(big.Int),
+	}
+
+	// 没有利率模型的市场不计息
+	if params.Irm != (common.Address{}) {
+		if out, err = r.call(params.Irm.Hex(), irmABI, "borrowRateView", params, st); err != nil {
+			return nil, fmt.Errorf("read borrow rate: %w", err)
+		}
+		market.BorrowRate = out[0].(*big.Int)
+	}
+	if params.Oracle != (common.Address{}) {
+		if out, err = r.call(params.Oracle.Hex(), oracleABI, "price"); err == nil {
+			market.OraclePrice = out[0].(*big.Int)
+		}
+	}
+	return market, nil
+}
+
+// Utilization 借款总量占供应总量的比例
+func (m *Market) Utilization() float64 {
+	if m.TotalSupplyAssets.Sign() == 0 {
+		return 0
+	}
+	ratio, _ := new(big.Float).Quo(new(big.Float).SetInt(m.TotalBorrowAssets), new(big.Float).SetInt(m.TotalSupplyAssets)).Float64()
+	return ratio
+}
+
+// BorrowAPY 按当前每秒借款利率连续复利一年的借款APY
+func (m *Market) BorrowAPY() float64 {
+	return math.Expm1(fromWAD(m.BorrowRate) * secondsPerYear)
+}
+
+// SupplyAPY 供应方APY，借款利息按利用率分给供应方并扣除协议费
+func (m *Market) SupplyAPY() float64 {
+	return m.BorrowAPY() * m.Utilization() * (1 - fromWAD(m.Fee))
+}
+
+// LLTV 清算LTV，小数表示
+func (m *Market) LLTV() float64 {
+	return fromWAD(m.Params.Lltv)
+}
+
+// CollateralPrice 1个抵押品折合的借出资产数量，已按两种代币的精度换算；预言机不可读时返回nil
+func (m *Market) CollateralPrice(loanDecimals, collateralDecimals int32) *float64 {
+	if m.OraclePrice == nil {
+		return nil
+	}
+	price, _ := new(big.Float).SetInt(m.OraclePrice).Float64()
+	price /= math.Pow10(int(36 + loanDecimals - collateralDecimals))
+	return &price
+}
+
+func fromWAD(value *big.Int) float64 {
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(value), wad).Float64()
+	return f
+}
+
+type reader struct {
+	ctx     context.Context
+	chainID uint
+	block   uint64
+}
+
+func (r reader) call(to string, contract abi.ABI, method string, args ...interface{}) ([]interface{}, error) {
+	data, err := contract.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	out, err := blockchain.CallAt(r.ctx, r.chainID, to, data, r.block)
+	if err != nil {
+		return nil, err
+	}
+	return contract.Unpack(method, out)
+}
+
+func mustParse(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(fmt.Sprintf("morpho ABI: %v", err))
+	}
+	return parsed
+}
@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+)
+
+// EmailSender 通过SMTP发送邮件
+type EmailSender struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+func NewEmailSender(cfg config.NotificationsConfig) *EmailSender {
+	return &EmailSender{
+		host:     cfg.SMTPHost,
+		port:     cfg.SMTPPort,
+		username: cfg.SMTPUsername,
+		password: cfg.SMTPPassword,
+		from:     cfg.EmailFrom,
+	}
+}
+
+func (s *EmailSender) Send(ctx context.Context, target string, msg Message) error {
+	if s.host == "" || s.from == "" {
+		return ErrNotConfigured
+	}
+
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+
+	body := strings.Join([]string{
+		"From: " + s.from,
+		"To: " + target,
+		"Subject: " + msg.Subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		msg.Body,
+	}, "\r\n")
+
+	// net/smtp不支持context，在独立goroutine中发送以便调用方超时返回
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(net.JoinHostPort(s.host, s.port), auth, s.from, []string{target}, []byte(body))
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("send email: %w", err)
+		}
+		return nil
+	}
+}
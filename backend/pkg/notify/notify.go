@@ -0,0 +1,69 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+)
+
+// 通知渠道类型
+const (
+	ChannelEmail    = "email"
+	ChannelTelegram = "telegram"
+	ChannelSlack    = "slack"
+	ChannelWebhook  = "webhook"
+)
+
+var (
+	ErrUnsupportedChannel = errors.New("unsupported notification channel")
+	ErrNotConfigured      = errors.New("notification channel is not configured")
+)
+
+// Message 一条通知
+type Message struct {
+	Subject string
+	Body    string
+}
+
+// Sender 通过某个渠道发送通知，target为邮箱地址、Telegram chat id或Slack webhook地址
+type Sender interface {
+	Send(ctx context.Context, target string, msg Message) error
+}
+
+// Dispatcher 按渠道类型分发通知
+type Dispatcher struct {
+	senders map[string]Sender
+}
+
+func NewDispatcher(senders map[string]Sender) *Dispatcher {
+	return &Dispatcher{senders: senders}
+}
+
+// Send 通过指定渠道发送通知
+func (d *Dispatcher) Send(ctx context.Context, channel, target string, msg Message) error {
+	sender, ok := d.senders[channel]
+	if !ok {
+		return ErrUnsupportedChannel
+	}
+	return sender.Send(ctx, target, msg)
+}
+
+var (
+	defaultDispatcher *Dispatcher
+	defaultOnce       sync.Once
+)
+
+// Default 返回基于配置的邮件、Telegram与Slack分发器
+func Default() *Dispatcher {
+	defaultOnce.Do(func() {
+		cfg := config.Load().Notifications
+		defaultDispatcher = NewDispatcher(map[string]Sender{
+			ChannelEmail:    NewEmailSender(cfg),
+			ChannelTelegram: NewTelegramSender(cfg),
+			ChannelSlack:    NewSlackSender(),
+		})
+	})
+	return defaultDispatcher
+}
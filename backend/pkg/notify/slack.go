@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SlackSender 通过Slack incoming webhook发送消息，target为webhook地址
+type SlackSender struct {
+	client *http.Client
+}
+
+func NewSlackSender() *SlackSender {
+	return &SlackSender{
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *SlackSender) Send(ctx context.Context, target string, msg Message) error {
+	if !strings.HasPrefix(target, "https://") {
+		return ErrNotConfigured
+	}
+
+	text := msg.Body
+	if msg.Subject != "" {
+		text = "*" + msg.Subject + "*\n\n" + msg.Body
+	}
+	payload, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send slack message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+)
+
+// TelegramSender 通过Telegram Bot API发送消息
+type TelegramSender struct {
+	apiURL string
+	token  string
+	client *http.Client
+}
+
+func NewTelegramSender(cfg config.NotificationsConfig) *TelegramSender {
+	return &TelegramSender{
+		apiURL: strings.TrimRight(cfg.TelegramAPIURL, "/"),
+		token:  cfg.TelegramBotToken,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *TelegramSender) Send(ctx context.Context, target string, msg Message) error {
+	if s.token == "" {
+		return ErrNotConfigured
+	}
+
+	text := msg.Body
+	if msg.Subject != "" {
+		text = msg.Subject + "\n\n" + msg.Body
+	}
+	payload, err := json.Marshal(map[string]string{
+		"chat_id": target,
+		"text":    text,
+	})
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/bot%s/sendMessage", s.apiURL, s.token)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send telegram message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("telegram returned status %d", resp.StatusCode)
+	}
+	return nil
+}
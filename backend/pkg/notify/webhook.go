@@ -0,0 +1,136 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/pkg/resilience"
+)
+
+// webhook请求头
+const (
+	SignatureHeader = "X-Signature"
+	DeliveryHeader  = "X-Webhook-ID"
+)
+
+// ErrPrivateAddress webhook目标是或解析到非公网地址
+var ErrPrivateAddress = errors.New("webhook target resolves to a non-public address")
+
+// sharedAddressSpace 运营商级NAT地址段(100.64.0.0/10)，不属于 net.IP.IsPrivate 但同样不可从公网访问
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// PublicIP 是否为公网单播地址。回环、私有网段、链路本地(包括云元数据地址 169.254.169.254)、
+// 组播和未指定地址都不是，webhook不投递到这些地址
+func PublicIP(ip net.IP) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !sharedAddressSpace.Contains(ip)
+}
+
+// Sign 以订阅方密钥对请求体计算HMAC-SHA256，格式为 sha256=<十六进制>
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookResult 一次投递的结果，StatusCode 为0表示未收到响应
+type WebhookResult struct {
+	StatusCode int
+	Duration   time.Duration
+}
+
+// WebhookSender 向订阅方的HTTPS地址POST JSON并附带签名。
+// 签名需要每个渠道各自的密钥，因此不注册到 Dispatcher，由通知服务直接调用
+type WebhookSender struct {
+	client *http.Client
+}
+
+func NewWebhookSender() *WebhookSender {
+	// 在DNS解析之后、建立连接之前检查地址，登记时是公网地址的域名之后改为解析到内网同样会被拒绝
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !PublicIP(ip) {
+				return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil // 经代理时检查的是代理地址而不是目标地址
+	transport.DialContext = dialer.DialContext
+	return &WebhookSender{
+		client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: transport,
+			// 不跟随重定向，避免签名请求被转发到登记地址以外的主机
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Deliver 投递请求体，非2xx响应视为失败，结果中仍带有状态码。
+// 按目标主机熔断(依赖名 webhook，指标不区分主机)，一个订阅方持续故障时后续投递直接失败，不占用队列worker等待超时
+func (s *WebhookSender) Deliver(ctx context.Context, target, secret, deliveryID string, body []byte) (WebhookResult, error) {
+	if !strings.HasPrefix(target, "https://") || secret == "" {
+		return WebhookResult{}, ErrNotConfigured
+	}
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return WebhookResult{}, err
+	}
+
+	var result WebhookResult
+	err = resilience.DoKeyed(ctx, "webhook", parsed.Host, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+		if err != nil {
+			return resilience.Permanent(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "MYA-Webhook/1.0")
+		req.Header.Set(DeliveryHeader, deliveryID)
+		req.Header.Set(SignatureHeader, Sign(secret, body))
+
+		start := time.Now()
+		resp, err := s.client.Do(req)
+		result = WebhookResult{Duration: time.Since(start)}
+		if err != nil {
+			err = fmt.Errorf("deliver webhook: %w", err)
+			if errors.Is(err, ErrPrivateAddress) {
+				return resilience.Permanent(err)
+			}
+			return err
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+		result.StatusCode = resp.StatusCode
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			err := fmt.Errorf("webhook returned status %d", resp.StatusCode)
+			// 订阅方明确拒绝(4xx，429除外)说明服务在线，不计入熔断
+			if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+				return resilience.Permanent(err)
+			}
+			return err
+		}
+		return nil
+	})
+	return result, err
+}
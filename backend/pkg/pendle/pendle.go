@@ -0,0 +1,135 @@
+// Package pendle 读取Pendle市场的到期时间和隐含利率，为本金代币(PT)定价。
+//
+// 市场记录的 lastLnImpliedRate 为 ln(1 + 隐含APY)(1e18精度)。PT到期时按1:1兑换底层资产，
+// 到期前的价格(以底层资产计)为 e^(-lnImpliedRate × 剩余年数)，持有到期即锁定隐含APY。
+// 底层资产取自市场SY的 assetInfo，即PT到期兑换所得的资产
+package pendle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var ErrNotMarket = errors.New("address is not a Pendle market")
+
+const year = 365 * 24 * time.Hour
+
+var marketABI = mustParse(`[
+	{"type":"function","name":"readTokens","stateMutability":"view","inputs":[],"outputs":[
+		{"name":"_SY","type":"address"},{"name":"_PT","type":"address"},{"name":"_YT","type":"address"}]},
+	{"type":"function","name":"expiry","stateMutability":"view","inputs":[],"outputs":[{"type":"uint256"}]},
+	{"type":"function","name":"_storage","stateMutability":"view","inputs":[],"outputs":[
+		{"name":"totalPt","type":"int128"},{"name":"totalSy","type":"int128"},
+		{"name":"lastLnImpliedRate","type":"uint96"},{"name":"observationIndex","type":"uint16"},
+		{"name":"observationCardinality","type":"uint16"},{"name":"observationCardinalityNext","type":"uint16"}]}
+]`)
+
+var syABI = mustParse(`[
+	{"type":"function","name":"assetInfo","stateMutability":"view","inputs":[],"outputs":[
+		{"name":"assetType","type":"uint8"},{"name":"assetAddress","type":"address"},{"name":"assetDecimals","type":"uint8"}]}
+]`)
+
+var wad = new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))
+
+// Market 市场在同一区块上的代币、底层资产、到期时间和隐含利率
+type Market struct {
+	BlockNumber   uint64
+	SY            common.Address
+	PT            common.Address
+	YT            common.Address
+	Asset         common.Address
+	Expiry        time.Time
+	LnImpliedRate float64
+}
+
+// Read 在最新区块上读取市场的SY/PT/YT地址、底层资产、到期时间和最近一次交易后的隐含利率
+func Read(ctx context.Context, chainID uint, market string) (*Market, error) {
+	block, err := blockchain.BlockNumber(ctx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	r := reader{ctx: ctx, chainID: chainID, block: block}
+
+	out, err := r.call(market, marketABI, "readTokens")
+	if err != nil {
+		return nil, fmt.Errorf("read tokens: %w", err)
+	}
+	m := &Market{
+		BlockNumber: block,
+		SY:          out[0].(common.Address),
+		PT:          out[1].(common.Address),
+		YT:          out[2].(common.Address),
+	}
+	if out, err = r.call(m.SY.Hex(), syABI, "assetInfo"); err != nil {
+		return nil, fmt.Errorf("read SY asset: %w", err)
+	}
+	m.Asset = out[1].(common.Address)
+	if out, err = r.call(market, marketABI, "expiry"); err != nil {
+		return nil, fmt.Errorf("read expiry: %w", err)
+	}
+	m.Expiry = time.Unix(out[0].(*big.Int).Int64(), 0).UTC()
+	if out, err = r.call(market, marketABI, "_storage"); err != nil {
+		return nil, fmt.Errorf("read market storage: %w", err)
+	}
+	m.LnImpliedRate, _ = new(big.Float).Quo(new(big.Float).SetInt(out[2].(*big.Int)), wad).Float64()
+	return m, nil
+}
+
+// ImpliedAPY 持有PT到期锁定的年化收益率
+func (m *Market) ImpliedAPY() float64 {
+	return math.Expm1(m.LnImpliedRate)
+}
+
+// Matured 在at时刻是否已到期
+func (m *Market) Matured(at time.Time) bool {
+	return !at.Before(m.Expiry)
+}
+
+// PTPrice at时刻1个PT以底层资产计的价格，到期后为1
+func (m *Market) PTPrice(at time.Time) float64 {
+	if m.Matured(at) {
+		return 1
+	}
+	return math.Exp(-m.LnImpliedRate * float64(m.Expiry.Sub(at)) / float64(year))
+}
+
+type reader struct {
+	ctx     context.Context
+	chainID uint
+	block   uint64
+}
+
+func (r reader) call(to string, contract abi.ABI, method string) ([]interface{}, error) {
+	data, err := contract.Pack(method)
+	if err != nil {
+		return nil, err
+	}
+	out, err := blockchain.CallAt(r.ctx, r.chainID, to, data, r.block)
+	if err != nil {
+		return nil, err
+	}
+	values, err := contract.Unpack(method, out)
+	if err != nil {
+		// 非合约地址返回空结果，其他合约返回的数据与市场方法不符
+		return nil, fmt.Errorf("%w: %s: %v", ErrNotMarket, method, err)
+	}
+	return values, nil
+}
+
+func mustParse(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(fmt.Sprintf("pendle ABI: %v", err))
+	}
+	return parsed
+}
@@ -0,0 +1,98 @@
+package prices
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+)
+
+var (
+	// latestRoundData() 和 decimals() 的函数选择器
+	selectorLatestRoundData = []byte{0xfe, 0xaf, 0x96, 0x8c}
+	selectorDecimals        = []byte{0x31, 0x3c, 0xe5, 0x67}
+)
+
+// ChainlinkSource 通过Chainlink聚合器合约读取价格
+type ChainlinkSource struct {
+	decimals map[string]uint8
+	mutex    sync.Mutex
+}
+
+func NewChainlinkSource() *ChainlinkSource {
+	return &ChainlinkSource{
+		decimals: make(map[string]uint8),
+	}
+}
+
+func (s *ChainlinkSource) Name() string {
+	return "chainlink"
+}
+
+func (s *ChainlinkSource) GetPrice(ctx context.Context, token config.PriceToken) (*Price, error) {
+	if token.ChainlinkFeed == "" {
+		return nil, ErrUnknownToken
+	}
+
+	decimals, err := s.feedDecimals(ctx, token.ChainID, token.ChainlinkFeed)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := blockchain.Call(ctx, token.ChainID, token.ChainlinkFeed, selectorLatestRoundData)
+	if err != nil {
+		return nil, err
+	}
+	// 返回值: roundId, answer, startedAt, updatedAt, answeredInRound
+	if len(out) < 5*32 {
+		return nil, errors.New("unexpected latestRoundData response")
+	}
+
+	answer := new(big.Int).SetBytes(out[32:64])
+	if out[32]&0x80 != 0 {
+		return nil, errors.New("negative price answer")
+	}
+	updatedAt := new(big.Int).SetBytes(out[96:128]).Int64()
+
+	usd, _ := new(big.Float).Quo(new(big.Float).SetInt(answer), big.NewFloat(math.Pow10(int(decimals)))).Float64()
+	if usd <= 0 {
+		return nil, errors.New("non-positive price answer")
+	}
+
+	return &Price{
+		Token:     token.Address,
+		ChainID:   token.ChainID,
+		USD:       usd,
+		Source:    s.Name(),
+		UpdatedAt: time.Unix(updatedAt, 0),
+	}, nil
+}
+
+// feedDecimals 读取并缓存喂价合约的精度
+func (s *ChainlinkSource) feedDecimals(ctx context.Context, chainID uint, feed string) (uint8, error) {
+	s.mutex.Lock()
+	decimals, ok := s.decimals[feed]
+	s.mutex.Unlock()
+	if ok {
+		return decimals, nil
+	}
+
+	out, err := blockchain.Call(ctx, chainID, feed, selectorDecimals)
+	if err != nil {
+		return 0, err
+	}
+	if len(out) < 32 {
+		return 0, errors.New("unexpected decimals response")
+	}
+	decimals = out[31]
+
+	s.mutex.Lock()
+	s.decimals[feed] = decimals
+	s.mutex.Unlock()
+	return decimals, nil
+}
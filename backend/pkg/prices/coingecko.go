@@ -0,0 +1,123 @@
+package prices
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/resilience"
+)
+
+// CoinGeckoSource 通过CoinGecko HTTP接口读取价格，作为Chainlink的备用来源
+type CoinGeckoSource struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+func NewCoinGeckoSource(baseURL, apiKey string) *CoinGeckoSource {
+	return &CoinGeckoSource{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *CoinGeckoSource) Name() string {
+	return "coingecko"
+}
+
+func (s *CoinGeckoSource) GetPrice(ctx context.Context, token config.PriceToken) (*Price, error) {
+	if token.CoinGeckoID == "" {
+		return nil, ErrUnknownToken
+	}
+
+	query := url.Values{}
+	query.Set("ids", token.CoinGeckoID)
+	query.Set("vs_currencies", "usd")
+	query.Set("include_last_updated_at", "true")
+
+	var body map[string]struct {
+		USD           float64 `json:"usd"`
+		LastUpdatedAt int64   `json:"last_updated_at"`
+	}
+	err := resilience.Do(ctx, s.Name(), func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"/simple/price?"+query.Encode(), nil)
+		if err != nil {
+			return resilience.Permanent(err)
+		}
+		if s.apiKey != "" {
+			req.Header.Set("x-cg-pro-api-key", s.apiKey)
+		}
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return statusError("coingecko", resp.StatusCode)
+		}
+		return json.NewDecoder(resp.Body).Decode(&body)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	quote, ok := body[token.CoinGeckoID]
+	if !ok || quote.USD <= 0 {
+		return nil, fmt.Errorf("coingecko has no price for %s", token.CoinGeckoID)
+	}
+
+	return &Price{
+		Token:     token.Address,
+		ChainID:   token.ChainID,
+		USD:       quote.USD,
+		Source:    s.Name(),
+		UpdatedAt: time.Unix(quote.LastUpdatedAt, 0),
+	}, nil
+}
+
+// Logo 读取CoinGecko上该币种的logo地址(image.large)，用于代币登记
+func (s *CoinGeckoSource) Logo(ctx context.Context, coinGeckoID string) (string, error) {
+	query := url.Values{}
+	for _, key := range []string{"localization", "tickers", "market_data", "community_data", "developer_data"} {
+		query.Set(key, "false")
+	}
+
+	var body struct {
+		Image struct {
+			Large string `json:"large"`
+		} `json:"image"`
+	}
+	err := resilience.Do(ctx, s.Name(), func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"/coins/"+url.PathEscape(coinGeckoID)+"?"+query.Encode(), nil)
+		if err != nil {
+			return resilience.Permanent(err)
+		}
+		if s.apiKey != "" {
+			req.Header.Set("x-cg-pro-api-key", s.apiKey)
+		}
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return statusError("coingecko", resp.StatusCode)
+		}
+		return json.NewDecoder(resp.Body).Decode(&body)
+	})
+	if err != nil {
+		return "", err
+	}
+	return body.Image.Large, nil
+}
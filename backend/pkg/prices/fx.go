@@ -0,0 +1,156 @@
+package prices
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/pkg/cache"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/resilience"
+)
+
+// SupportedCurrencies 支持换算的法币
+var SupportedCurrencies = []string{"USD", "EUR", "GBP", "JPY", "CNY"}
+
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+const fxCacheKey = "fx:USD"
+
+// FXRates 以USD为基准的汇率
+type FXRates struct {
+	Date      string             `json:"date"`
+	Rates     map[string]float64 `json:"rates"`
+	FetchedAt time.Time          `json:"fetched_at"`
+}
+
+// FXService 从每日汇率接口获取USD兑其他法币汇率
+type FXService struct {
+	url    string
+	ttl    time.Duration
+	client *http.Client
+
+	last  *FXRates
+	mutex sync.Mutex
+}
+
+var (
+	defaultFX *FXService
+	fxOnce    sync.Once
+)
+
+// DefaultFX 返回全局汇率服务
+func DefaultFX() *FXService {
+	fxOnce.Do(func() {
+		cfg := config.Load().Prices
+		defaultFX = NewFXService(cfg.FXURL, time.Duration(cfg.FXCacheTTL)*time.Second)
+	})
+	return defaultFX
+}
+
+func NewFXService(url string, ttl time.Duration) *FXService {
+	return &FXService{
+		url:    url,
+		ttl:    ttl,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// IsSupported 判断是否支持该法币
+func IsSupported(currency string) bool {
+	for _, c := range SupportedCurrencies {
+		if c == currency {
+			return true
+		}
+	}
+	return false
+}
+
+// Rate 返回1 USD可兑换的目标法币数量
+func (s *FXService) Rate(ctx context.Context, currency string) (float64, string, error) {
+	currency = strings.ToUpper(currency)
+	if !IsSupported(currency) {
+		return 0, "", ErrUnsupportedCurrency
+	}
+	if currency == "USD" {
+		return 1, time.Now().Format("2006-01-02"), nil
+	}
+
+	rates, err := s.rates(ctx)
+	if err != nil {
+		return 0, "", err
+	}
+
+	rate, ok := rates.Rates[currency]
+	if !ok || rate <= 0 {
+		return 0, "", fmt.Errorf("no FX rate for %s", currency)
+	}
+	return rate, rates.Date, nil
+}
+
+// rates 依次从Redis、汇率接口获取汇率，接口失败时退回上一次成功的结果
+func (s *FXService) rates(ctx context.Context) (*FXRates, error) {
+	var cached FXRates
+	if cache.GetJSON(ctx, fxCacheKey, &cached) {
+		return &cached, nil
+	}
+
+	fresh, err := s.fetch(ctx)
+	if err != nil {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		if s.last != nil {
+			logger.Error(fmt.Sprintf("FX fetch failed, using rates from %s: %v", s.last.Date, err))
+			return s.last, nil
+		}
+		return nil, err
+	}
+
+	cache.SetJSON(ctx, fxCacheKey, fresh, s.ttl)
+	s.mutex.Lock()
+	s.last = fresh
+	s.mutex.Unlock()
+	return fresh, nil
+}
+
+func (s *FXService) fetch(ctx context.Context) (*FXRates, error) {
+	symbols := strings.Join(SupportedCurrencies[1:], ",")
+	var rates FXRates
+	err := resilience.Do(ctx, "fx", func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url+"?from=USD&to="+symbols, nil)
+		if err != nil {
+			return resilience.Permanent(err)
+		}
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return statusError("FX source", resp.StatusCode)
+		}
+		return json.NewDecoder(resp.Body).Decode(&rates)
+	})
+	if err != nil {
+		return nil, err
+	}
+	rates.FetchedAt = time.Now()
+	return &rates, nil
+}
+
+// statusError 非200响应的错误，除429外的4xx是请求本身的问题，重试无意义
+func statusError(source string, status int) error {
+	err := fmt.Errorf("%s returned status %d", source, status)
+	if status >= 400 && status < 500 && status != http.StatusTooManyRequests {
+		return resilience.Permanent(err)
+	}
+	return err
+}
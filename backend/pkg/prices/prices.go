@@ -0,0 +1,139 @@
+package prices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/pkg/cache"
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+)
+
+var (
+	ErrPriceUnavailable = errors.New("price unavailable from all sources")
+	ErrStalePrice       = errors.New("price is stale")
+	ErrUnknownToken     = errors.New("token has no configured price source")
+)
+
+// Price 代币的USD价格
+type Price struct {
+	Token     string    `json:"token"`
+	ChainID   uint      `json:"chain_id"`
+	USD       float64   `json:"usd"`
+	Source    string    `json:"source"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Source 价格来源
+type Source interface {
+	Name() string
+	GetPrice(ctx context.Context, token config.PriceToken) (*Price, error)
+}
+
+// Service 按顺序查询价格来源，结果写入Redis缓存
+type Service struct {
+	sources      []Source
+	tokens       map[string]config.PriceToken
+	cacheTTL     time.Duration
+	maxStaleness time.Duration
+}
+
+var (
+	defaultService *Service
+	once           sync.Once
+)
+
+// Default 返回以Chainlink为主、CoinGecko为备的全局价格服务
+func Default() *Service {
+	once.Do(func() {
+		cfg := config.Load().Prices
+		defaultService = NewService(cfg,
+			NewChainlinkSource(),
+			NewCoinGeckoSource(cfg.CoinGeckoURL, cfg.CoinGeckoAPIKey),
+		)
+	})
+	return defaultService
+}
+
+// NewService 创建价格服务，sources按优先级排列
+func NewService(cfg config.PricesConfig, sources ...Source) *Service {
+	tokens := make(map[string]config.PriceToken, len(cfg.Tokens))
+	for _, t := range cfg.Tokens {
+		tokens[tokenKey(t.ChainID, t.Address)] = t
+	}
+
+	return &Service{
+		sources:      sources,
+		tokens:       tokens,
+		cacheTTL:     time.Duration(cfg.CacheTTL) * time.Second,
+		maxStaleness: time.Duration(cfg.MaxStaleness) * time.Second,
+	}
+}
+
+// GetPrice 获取代币在指定链上的USD价格
+func (s *Service) GetPrice(ctx context.Context, token string, chainID uint) (*Price, error) {
+	key := tokenKey(chainID, token)
+	tokenCfg, ok := s.tokens[key]
+	if !ok {
+		return nil, ErrUnknownToken
+	}
+
+	cacheKey := "price:" + key
+	var cached Price
+	if cache.GetJSON(ctx, cacheKey, &cached) {
+		return &cached, nil
+	}
+
+	for _, source := range s.sources {
+		price, err := source.GetPrice(ctx, tokenCfg)
+		if err == nil && s.isStale(price) {
+			err = ErrStalePrice
+		}
+		if err != nil {
+			logger.Info(fmt.Sprintf("Price source %s failed for %s: %v", source.Name(), key, err))
+			continue
+		}
+
+		cache.SetJSON(ctx, cacheKey, price, s.cacheTTL)
+		return price, nil
+	}
+
+	logger.Error(fmt.Sprintf("No price available for %s", key))
+	return nil, ErrPriceUnavailable
+}
+
+// ToUSD 将代币数量换算为USD价值
+func (s *Service) ToUSD(ctx context.Context, token string, chainID uint, amount float64) (float64, error) {
+	price, err := s.GetPrice(ctx, token, chainID)
+	if err != nil {
+		return 0, err
+	}
+	return amount * price.USD, nil
+}
+
+// Token 返回代币的价格配置，未配置时返回false
+func (s *Service) Token(chainID uint, token string) (config.PriceToken, bool) {
+	t, ok := s.tokens[tokenKey(chainID, token)]
+	return t, ok
+}
+
+// Tokens 返回所有配置了价格来源的代币
+func (s *Service) Tokens() []config.PriceToken {
+	tokens := make([]config.PriceToken, 0, len(s.tokens))
+	for _, t := range s.tokens {
+		tokens = append(tokens, t)
+	}
+	return tokens
+}
+
+func (s *Service) isStale(price *Price) bool {
+	return s.maxStaleness > 0 && time.Since(price.UpdatedAt) > s.maxStaleness
+}
+
+func tokenKey(chainID uint, token string) string {
+	return fmt.Sprintf("%d:%s", chainID, strings.ToLower(token))
+}
@@ -0,0 +1,70 @@
+// Package rates 年化收益率的APR/APY换算。
+//
+// 数据库、链上事件和缓存中的收益率统一为APY(含复利)，小数表示(0.05即5%)。
+// APR按每年复利 rates.compounding_periods 次与APY互相换算：APY = (1 + APR/n)^n - 1，
+// 默认n=365，对应策略大致每天收获并复投
+package rates
+
+import (
+	"math"
+
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+)
+
+// 收益率口径
+const (
+	APR = "apr" // 单利年化，不含复投收益
+	APY = "apy" // 复利年化
+)
+
+const defaultPeriods = 365
+
+// Basis 响应中收益率的口径说明
+type Basis struct {
+	Type               string `json:"type"`
+	CompoundingPeriods int    `json:"compounding_periods"`
+}
+
+// Valid 是否为支持的收益率口径
+func Valid(kind string) bool {
+	return kind == APR || kind == APY
+}
+
+// Periods 每年的复利次数
+func Periods() int {
+	if n := config.Load().Rates.CompoundingPeriods; n > 0 {
+		return n
+	}
+	return defaultPeriods
+}
+
+// NewBasis 返回指定口径及当前复利假设
+func NewBasis(kind string) Basis {
+	return Basis{Type: kind, CompoundingPeriods: Periods()}
+}
+
+// ToAPY 将APR换算为APY
+func ToAPY(apr float64) float64 {
+	n := float64(Periods())
+	if apr <= -n {
+		return -1
+	}
+	return math.Pow(1+apr/n, n) - 1
+}
+
+// ToAPR 将APY换算为APR，低于-100%的异常值原样返回
+func ToAPR(apy float64) float64 {
+	if apy <= -1 {
+		return apy
+	}
+	n := float64(Periods())
+	return n * (math.Pow(1+apy, 1/n) - 1)
+}
+
+// FromAPY 按口径返回以APY存储的收益率
+func FromAPY(apy float64, kind string) float64 {
+	if kind == APR {
+		return ToAPR(apy)
+	}
+	return apy
+}
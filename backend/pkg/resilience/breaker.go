@@ -0,0 +1,172 @@
+package resilience
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/logger"
+	"github.com/chspring1/mya-platform/backend/pkg/metrics"
+)
+
+// 熔断器状态，数值与 mya_dependency_breaker_state 指标一致
+const (
+	stateClosed = iota
+	stateHalfOpen
+	stateOpen
+)
+
+// 重试预算的令牌上限，依赖长时间正常后最多允许连续重试这么多次
+const budgetCap = 10
+
+// breaker 单个依赖的熔断器和重试预算。
+// 连续失败达到阈值后打开，打开期间直接拒绝；超过 open_timeout 后半开，只放行一个试探请求，
+// 试探成功则关闭，失败则重新打开
+type breaker struct {
+	name  string
+	keyed bool // 按调用方给出的key分别熔断，状态不写入指标
+	used  time.Time
+
+	mutex    sync.Mutex
+	state    int
+	failures int
+	openedAt time.Time
+	probing  bool
+	tokens   float64
+}
+
+// 按key熔断时最多保留的熔断器数量，key来自用户输入(如webhook主机)，超出时淘汰最久未使用的
+const maxKeyed = 1024
+
+var (
+	breakers      = make(map[string]*breaker)
+	keyed         = make(map[string]*breaker)
+	breakersMutex sync.Mutex
+)
+
+func get(name string) *breaker {
+	breakersMutex.Lock()
+	defer breakersMutex.Unlock()
+	b, ok := breakers[name]
+	if !ok {
+		b = &breaker{name: name, tokens: budgetCap}
+		breakers[name] = b
+		metrics.DependencyBreakerState.WithLabelValues(name).Set(stateClosed)
+	}
+	return b
+}
+
+// getKeyed 获取依赖下某个key的熔断器，数量达到 maxKeyed 时先淘汰最久未使用的一个
+func getKeyed(dependency, key string) *breaker {
+	name := dependency + ":" + key
+	now := time.Now()
+	breakersMutex.Lock()
+	defer breakersMutex.Unlock()
+	b, ok := keyed[name]
+	if !ok {
+		if len(keyed) >= maxKeyed {
+			evictKeyed()
+		}
+		b = &breaker{name: name, keyed: true, tokens: budgetCap}
+		keyed[name] = b
+	}
+	b.used = now
+	return b
+}
+
+// evictKeyed 淘汰最久未使用的按key熔断器，调用方持有 breakersMutex
+func evictKeyed() {
+	var oldest *breaker
+	for _, b := range keyed {
+		if oldest == nil || b.used.Before(oldest.used) {
+			oldest = b
+		}
+	}
+	if oldest != nil {
+		delete(keyed, oldest.name)
+	}
+}
+
+// allow 判断本次尝试能否发出，半开状态下同一时间只有一个试探请求
+func (b *breaker) allow(policy config.ResiliencePolicy, now time.Time) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	switch b.state {
+	case stateOpen:
+		if now.Sub(b.openedAt) < time.Duration(policy.OpenTimeout)*time.Second {
+			return false
+		}
+		b.setState(stateHalfOpen)
+		b.probing = true
+		return true
+	case stateHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	}
+	return true
+}
+
+// success 依赖正常应答，清零连续失败并关闭熔断器
+func (b *breaker) success() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.failures = 0
+	b.probing = false
+	if b.state != stateClosed {
+		b.setState(stateClosed)
+	}
+}
+
+// failure 记一次失败，半开时的试探失败或连续失败达到阈值时打开熔断器
+func (b *breaker) failure(policy config.ResiliencePolicy, now time.Time) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.failures++
+	b.probing = false
+	if b.state == stateHalfOpen || (b.state == stateClosed && b.failures >= policy.FailureThreshold) {
+		b.openedAt = now
+		b.setState(stateOpen)
+	}
+}
+
+// release 调用方取消时放弃本次结果，不计成功也不计失败
+func (b *breaker) release() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.probing = false
+}
+
+// deposit 每次调用按 retry_budget 补充令牌
+func (b *breaker) deposit(budget float64) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.tokens = min(b.tokens+budget, budgetCap)
+}
+
+// spend 每次重试消耗一个令牌，令牌不足时不再重试
+func (b *breaker) spend() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (b *breaker) setState(state int) {
+	switch {
+	case state == stateOpen && b.state != stateOpen:
+		logger.Error(fmt.Sprintf("Circuit breaker for %s opened after %d consecutive failure(s)", b.name, b.failures))
+	case state == stateClosed && b.state != stateClosed:
+		logger.Info(fmt.Sprintf("Circuit breaker for %s closed", b.name))
+	}
+	b.state = state
+	if !b.keyed {
+		metrics.DependencyBreakerState.WithLabelValues(b.name).Set(float64(state))
+	}
+}
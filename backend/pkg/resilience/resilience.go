@@ -0,0 +1,105 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+	"github.com/chspring1/mya-platform/backend/pkg/metrics"
+)
+
+// ErrOpen 依赖的熔断器处于打开状态，调用未发出
+var ErrOpen = errors.New("circuit breaker open")
+
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent 标记依赖已正常应答但结果是错误(如合约revert、4xx)，不重试也不计入熔断
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Do 以依赖名对应的策略执行外部调用：失败按指数退避加随机抖动重试，受重试预算限制；
+// 连续失败达到阈值后熔断，熔断期间直接返回 ErrOpen，避免一个故障依赖拖住请求处理。
+// 依赖名形如 rpc:1、coingecko，冒号前为策略分组
+func Do(ctx context.Context, dependency string, fn func(ctx context.Context) error) error {
+	return run(ctx, dependency, get(dependency), fn)
+}
+
+// DoKeyed 与 Do 相同，但同一依赖下按key分别熔断，如webhook按订阅方主机，一个订阅方故障不影响其他订阅方。
+// key来自外部输入，只用于区分熔断器：策略和指标标签都按依赖名，熔断器数量有上限
+func DoKeyed(ctx context.Context, dependency, key string, fn func(ctx context.Context) error) error {
+	return run(ctx, dependency, getKeyed(dependency, key), fn)
+}
+
+func run(ctx context.Context, dependency string, b *breaker, fn func(ctx context.Context) error) error {
+	policy := config.Load().Resilience.Policy(dependency)
+	b.deposit(policy.RetryBudget)
+
+	var last error
+	for attempt := 1; ; attempt++ {
+		if !b.allow(policy, time.Now()) {
+			metrics.DependencyCalls.WithLabelValues(dependency, "rejected").Inc()
+			if last != nil {
+				return last
+			}
+			return fmt.Errorf("%s: %w", dependency, ErrOpen)
+		}
+
+		err := fn(ctx)
+		var permanent *permanentError
+		switch {
+		case err == nil:
+			b.success()
+			metrics.DependencyCalls.WithLabelValues(dependency, "success").Inc()
+			return nil
+		case errors.As(err, &permanent):
+			b.success()
+			metrics.DependencyCalls.WithLabelValues(dependency, "success").Inc()
+			return permanent.err
+		case ctx.Err() != nil:
+			b.release()
+			return err
+		}
+
+		b.failure(policy, time.Now())
+		metrics.DependencyCalls.WithLabelValues(dependency, "failure").Inc()
+		last = err
+		if attempt >= policy.MaxAttempts || !b.spend() {
+			return err
+		}
+
+		metrics.DependencyRetries.WithLabelValues(dependency).Inc()
+		timer := time.NewTimer(backoff(attempt, policy))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff 第attempt次失败后的等待时间：base_delay*2^(attempt-1)，不超过 max_delay，在0到该值之间随机
+func backoff(attempt int, policy config.ResiliencePolicy) time.Duration {
+	delay := time.Duration(policy.BaseDelay) * time.Millisecond
+	limit := time.Duration(policy.MaxDelay) * time.Millisecond
+	for i := 1; i < attempt && delay < limit; i++ {
+		delay *= 2
+	}
+	if delay > limit {
+		delay = limit
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return rand.N(delay + 1)
+}
@@ -0,0 +1,313 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// 单次执行结果
+const (
+	StatusSuccess  = "success"
+	StatusFailed   = "failed"
+	StatusTimeout  = "timeout"
+	StatusPanicked = "panicked"
+)
+
+var (
+	ErrUnknownJob   = errors.New("unknown job")
+	ErrDuplicateJob = errors.New("job already registered")
+	ErrRunning      = errors.New("job is already running")
+	ErrStarted      = errors.New("scheduler already started")
+)
+
+// Job 按cron表达式执行的任务。Run 需要响应ctx取消，超时后调度器只取消ctx，
+// 仍会等待本次执行返回，期间不会开始新的一次
+type Job struct {
+	Name    string
+	Spec    string        // 调度表达式，见 Parse
+	Timeout time.Duration // 单次执行超时，0表示不限制
+	Run     func(ctx context.Context) error
+}
+
+// Status 任务的调度状态和最近一次执行结果
+type Status struct {
+	Name           string        `json:"name"`
+	Schedule       string        `json:"schedule"`
+	Timeout        time.Duration `json:"timeout"`
+	Paused         bool          `json:"paused"`
+	Running        bool          `json:"running"`
+	NextRunAt      *time.Time    `json:"next_run_at,omitempty"`
+	LastStartedAt  *time.Time    `json:"last_started_at,omitempty"`
+	LastFinishedAt *time.Time    `json:"last_finished_at,omitempty"`
+	LastStatus     string        `json:"last_status,omitempty"`
+	LastError      string        `json:"last_error,omitempty"`
+	LastDuration   time.Duration `json:"last_duration"`
+	Runs           int64         `json:"runs"`
+	Failures       int64         `json:"failures"`
+}
+
+type entry struct {
+	job      Job
+	schedule Schedule
+	status   Status
+	trigger  chan struct{}
+}
+
+// Scheduler 任务注册表。每个任务一个goroutine按自身表达式计算下一次执行时间，
+// 同一任务同时最多执行一次：到点时仍在执行则跳过该次，手动触发返回 ErrRunning
+type Scheduler struct {
+	// Guard 定时触发执行前调用(手动触发不调用)，返回false时跳过本次，用于多实例间的分布式锁。
+	// tick 为本次的计划触发时刻，各实例相同；ttl 为距下一次执行的时长
+	Guard func(ctx context.Context, name string, tick time.Time, ttl time.Duration) (release func(), ok bool)
+	// OnStatus 任务状态变化后调用，用于持久化，在调度goroutine中同步执行
+	OnStatus func(Status)
+	// OnFinish 每次执行结束后调用，Status 中为本次的结果
+	OnFinish func(Status)
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	order   []string
+	started bool
+}
+
+func New() *Scheduler {
+	return &Scheduler{entries: make(map[string]*entry)}
+}
+
+// Register 注册任务，表达式无效或名称重复时返回错误。必须在 Start 之前调用
+func (s *Scheduler) Register(job Job) error {
+	schedule, err := Parse(job.Spec)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return ErrStarted
+	}
+	if _, ok := s.entries[job.Name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateJob, job.Name)
+	}
+	s.entries[job.Name] = &entry{
+		job:      job,
+		schedule: schedule,
+		status:   Status{Name: job.Name, Schedule: job.Spec, Timeout: job.Timeout},
+		trigger:  make(chan struct{}, 1),
+	}
+	s.order = append(s.order, job.Name)
+	return nil
+}
+
+// Start 为每个任务启动调度goroutine，ctx取消时全部退出。
+// 返回的WaitGroup在所有任务(包括正在执行的一次)结束后完成，用于优雅退出
+func (s *Scheduler) Start(ctx context.Context) *sync.WaitGroup {
+	s.mu.Lock()
+	s.started = true
+	entries := make([]*entry, 0, len(s.order))
+	for _, name := range s.order {
+		entries = append(entries, s.entries[name])
+	}
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, e := range entries {
+		wg.Add(1)
+		go func(e *entry) {
+			defer wg.Done()
+			s.loop(ctx, e)
+		}(e)
+	}
+	return &wg
+}
+
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	for {
+		next := e.schedule.Next(time.Now())
+		var fire <-chan time.Time
+		var timer *time.Timer
+		if !next.IsZero() {
+			timer = time.NewTimer(time.Until(next))
+			fire = timer.C
+			s.update(e, func(st *Status) { st.NextRunAt = &next })
+		} else {
+			s.update(e, func(st *Status) { st.NextRunAt = nil })
+		}
+
+		manual := false
+		select {
+		case <-ctx.Done():
+			stopTimer(timer)
+			return
+		case <-e.trigger:
+			stopTimer(timer)
+			manual = true
+		case <-fire:
+		}
+		s.run(ctx, e, next, manual)
+	}
+}
+
+func stopTimer(t *time.Timer) {
+	if t != nil {
+		t.Stop()
+	}
+}
+
+// run 执行一次任务。定时触发时暂停的任务跳过；超时以ctx取消通知任务，panic被捕获记为失败
+func (s *Scheduler) run(ctx context.Context, e *entry, tick time.Time, manual bool) {
+	s.mu.Lock()
+	if e.status.Running || (!manual && e.status.Paused) {
+		s.mu.Unlock()
+		return
+	}
+	e.status.Running = true
+	s.mu.Unlock()
+
+	if s.Guard != nil && !manual {
+		ttl := time.Minute
+		if next := e.schedule.Next(time.Now()); !next.IsZero() {
+			ttl = max(time.Until(next), time.Second)
+		}
+		release, ok := s.Guard(ctx, e.job.Name, tick, ttl)
+		if !ok {
+			s.mu.Lock()
+			e.status.Running = false
+			s.mu.Unlock()
+			return
+		}
+		defer release()
+	}
+
+	start := time.Now()
+	s.update(e, func(st *Status) { st.LastStartedAt = &start })
+
+	runCtx := ctx
+	if e.job.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, e.job.Timeout)
+		defer cancel()
+	}
+	err := call(runCtx, e.job.Run)
+
+	finished := time.Now()
+	result := StatusSuccess
+	var panicked *panicError
+	switch {
+	case errors.As(err, &panicked):
+		result = StatusPanicked
+	case err != nil && errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		result = StatusTimeout
+	case err != nil:
+		result = StatusFailed
+	}
+	s.update(e, func(st *Status) {
+		st.Running = false
+		st.LastFinishedAt = &finished
+		st.LastDuration = finished.Sub(start)
+		st.LastStatus = result
+		st.LastError = ""
+		st.Runs++
+		if err != nil {
+			st.LastError = err.Error()
+			st.Failures++
+		}
+	})
+	if s.OnFinish != nil {
+		s.mu.Lock()
+		status := e.status
+		s.mu.Unlock()
+		s.OnFinish(status)
+	}
+}
+
+// panicError 任务执行中的panic
+type panicError struct {
+	value any
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.value)
+}
+
+// call 执行任务并把panic转换为错误，避免拖垮整个进程
+func call(ctx context.Context, run func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &panicError{value: r}
+		}
+	}()
+	return run(ctx)
+}
+
+// update 修改状态后通知 OnStatus
+func (s *Scheduler) update(e *entry, change func(*Status)) {
+	s.mu.Lock()
+	change(&e.status)
+	status := e.status
+	s.mu.Unlock()
+	if s.OnStatus != nil {
+		s.OnStatus(status)
+	}
+}
+
+// Statuses 按注册顺序返回全部任务的状态
+func (s *Scheduler) Statuses() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]Status, 0, len(s.order))
+	for _, name := range s.order {
+		statuses = append(statuses, s.entries[name].status)
+	}
+	return statuses
+}
+
+// Trigger 立即执行一次任务，不受暂停和Guard限制。已有一次待执行的手动触发时合并为一次
+func (s *Scheduler) Trigger(name string) error {
+	s.mu.Lock()
+	e, ok := s.entries[name]
+	if !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+	running := e.status.Running
+	s.mu.Unlock()
+	if running {
+		return ErrRunning
+	}
+	select {
+	case e.trigger <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Pause 暂停定时执行，正在执行的一次不受影响
+func (s *Scheduler) Pause(name string) error {
+	return s.setPaused(name, true)
+}
+
+// Resume 恢复定时执行
+func (s *Scheduler) Resume(name string) error {
+	return s.setPaused(name, false)
+}
+
+func (s *Scheduler) setPaused(name string, paused bool) error {
+	s.mu.Lock()
+	e, ok := s.entries[name]
+	if !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+	if e.status.Paused == paused {
+		s.mu.Unlock()
+		return nil
+	}
+	s.mu.Unlock()
+
+	s.update(e, func(st *Status) { st.Paused = paused })
+	return nil
+}
@@ -0,0 +1,60 @@
+package screening
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ChainalysisProvider 通过Chainalysis Sanctions API筛查地址，名单覆盖OFAC等主要制裁名单
+type ChainalysisProvider struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+func NewChainalysisProvider(baseURL, apiKey string) *ChainalysisProvider {
+	return &ChainalysisProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *ChainalysisProvider) Name() string {
+	return "chainalysis"
+}
+
+func (p *ChainalysisProvider) Screen(ctx context.Context, address string) (*Result, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/address/"+address, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-API-Key", p.apiKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("chainalysis request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("chainalysis returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Identifications []Identification `json:"identifications"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode chainalysis response: %w", err)
+	}
+	return &Result{
+		Provider:        p.Name(),
+		Flagged:         len(body.Identifications) > 0,
+		Identifications: body.Identifications,
+	}, nil
+}
@@ -0,0 +1,43 @@
+package screening
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/pkg/config"
+)
+
+var ErrUnknownProvider = errors.New("unknown screening provider")
+
+// Identification 提供方给出的一条命中记录
+type Identification struct {
+	Category    string `json:"category"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url,omitempty"`
+}
+
+// Result 一个地址的筛查结果，Identifications 非空即视为命中
+type Result struct {
+	Provider        string           `json:"provider"`
+	Flagged         bool             `json:"flagged"`
+	Identifications []Identification `json:"identifications,omitempty"`
+}
+
+// Provider 制裁筛查服务
+type Provider interface {
+	Name() string
+	Screen(ctx context.Context, address string) (*Result, error)
+}
+
+// New 按配置创建提供方，provider为空时返回nil，表示只按本地名单拦截
+func New(cfg config.ScreeningConfig) (Provider, error) {
+	switch strings.ToLower(cfg.Provider) {
+	case "":
+		return nil, nil
+	case "chainalysis":
+		return NewChainalysisProvider(cfg.APIURL, cfg.APIKey), nil
+	}
+	return nil, ErrUnknownProvider
+}
@@ -0,0 +1,36 @@
+package signature
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	ErrMalformed = errors.New("signature must be 65 bytes of 0x-prefixed hex")
+	ErrMismatch  = errors.New("signature was not produced by the address")
+)
+
+// VerifyPersonal 校验钱包 personal_sign(EIP-191) 签名是否由 address 对 message 签出。
+// v 同时接受 27/28 和 0/1 两种写法
+func VerifyPersonal(address, message, sig string) error {
+	raw, err := hexutil.Decode(sig)
+	if err != nil || len(raw) != crypto.SignatureLength {
+		return ErrMalformed
+	}
+	if raw[crypto.RecoveryIDOffset] >= 27 {
+		raw[crypto.RecoveryIDOffset] -= 27
+	}
+
+	pub, err := crypto.SigToPub(accounts.TextHash([]byte(message)), raw)
+	if err != nil {
+		return ErrMalformed
+	}
+	if !strings.EqualFold(crypto.PubkeyToAddress(*pub).Hex(), address) {
+		return ErrMismatch
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package swap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// OneInchSource 通过1inch Swap API(v6)获取报价
+type OneInchSource struct {
+	baseURL string
+	apiKey  string
+}
+
+func NewOneInchSource(baseURL, apiKey string) *OneInchSource {
+	return &OneInchSource{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+	}
+}
+
+func (s *OneInchSource) Name() string {
+	return "1inch"
+}
+
+func (s *OneInchSource) Quote(ctx context.Context, req Request) (*Quote, error) {
+	query := url.Values{}
+	query.Set("src", req.SellToken)
+	query.Set("dst", req.BuyToken)
+	query.Set("amount", req.SellAmount.String())
+	query.Set("from", req.Taker)
+	query.Set("origin", req.Taker)
+	// 1inch的滑点为百分比
+	query.Set("slippage", strconv.FormatFloat(float64(req.SlippageBps)/100, 'f', -1, 64))
+	query.Set("disableEstimate", "true")
+	query.Set("includeProtocols", "true")
+
+	endpoint := fmt.Sprintf("%s/swap/v6.0/%d/swap?%s", s.baseURL, req.ChainID, query.Encode())
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+s.apiKey)
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusBadRequest:
+		return nil, ErrNoRoute
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("1inch returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		DstAmount string `json:"dstAmount"`
+		Protocols [][][]struct {
+			Name string  `json:"name"`
+			Part float64 `json:"part"`
+		} `json:"protocols"`
+		Tx struct {
+			To    string `json:"to"`
+			Data  string `json:"data"`
+			Value string `json:"value"`
+			Gas   uint64 `json:"gas"`
+		} `json:"tx"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	buyAmount, ok := new(big.Int).SetString(body.DstAmount, 10)
+	if !ok {
+		return nil, fmt.Errorf("unexpected 1inch dstAmount %q", body.DstAmount)
+	}
+	data, err := hexutil.Decode(body.Tx.Data)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected 1inch calldata: %w", err)
+	}
+	value, ok := new(big.Int).SetString(body.Tx.Value, 10)
+	if !ok {
+		value = new(big.Int)
+	}
+
+	// protocols 为 路径 -> 跳 -> 来源，只统计第一跳各来源的占比(part为百分比)
+	var route []Fill
+	if len(body.Protocols) > 0 && len(body.Protocols[0]) > 0 {
+		for _, p := range body.Protocols[0][0] {
+			route = append(route, Fill{Source: p.Name, Proportion: p.Part / 100})
+		}
+	}
+
+	to := strings.ToLower(common.HexToAddress(body.Tx.To).Hex())
+	return &Quote{
+		Source:       s.Name(),
+		BuyAmount:    buyAmount,
+		MinBuyAmount: minAmount(buyAmount, req.SlippageBps),
+		To:           to,
+		Spender:      to, // 1inch路由合约本身即授权对象
+		Data:         data,
+		Value:        value,
+		Gas:          body.Tx.Gas,
+		Route:        route,
+	}, nil
+}
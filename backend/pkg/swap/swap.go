@@ -0,0 +1,63 @@
+package swap
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// NativeToken 聚合器约定的原生代币(ETH等)地址
+const NativeToken = "0xeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee"
+
+var ErrNoRoute = errors.New("no swap route available")
+
+// Request 兑换报价请求，数量均为代币最小单位
+type Request struct {
+	ChainID     uint
+	SellToken   string
+	BuyToken    string
+	SellAmount  *big.Int
+	Taker       string // 执行兑换的地址，zap时为路由合约
+	SlippageBps uint16
+}
+
+// Fill 路由中单个流动性来源及其占比(0-1)
+type Fill struct {
+	Source     string  `json:"source"`
+	Proportion float64 `json:"proportion"`
+}
+
+// Quote 兑换报价及可直接执行的交易
+type Quote struct {
+	Source       string
+	BuyAmount    *big.Int
+	MinBuyAmount *big.Int
+	To           string // 兑换交易的目标合约
+	Spender      string // 卖出ERC20时需要授权的地址
+	Data         []byte
+	Value        *big.Int
+	Gas          uint64
+	Route        []Fill
+}
+
+// Source 兑换聚合器
+type Source interface {
+	Name() string
+	Quote(ctx context.Context, req Request) (*Quote, error)
+}
+
+// IsNative 是否为原生代币
+func IsNative(token string) bool {
+	return strings.EqualFold(token, NativeToken)
+}
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// minAmount 按滑点计算最少可得数量
+func minAmount(amount *big.Int, slippageBps uint16) *big.Int {
+	min := new(big.Int).Mul(amount, big.NewInt(int64(10000-int(slippageBps))))
+	return min.Quo(min, big.NewInt(10000))
+}
@@ -0,0 +1,130 @@
+package swap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ZeroExSource 通过0x Swap API(v2, allowance-holder)获取报价
+type ZeroExSource struct {
+	baseURL string
+	apiKey  string
+}
+
+func NewZeroExSource(baseURL, apiKey string) *ZeroExSource {
+	return &ZeroExSource{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+	}
+}
+
+func (s *ZeroExSource) Name() string {
+	return "0x"
+}
+
+func (s *ZeroExSource) Quote(ctx context.Context, req Request) (*Quote, error) {
+	query := url.Values{}
+	query.Set("chainId", strconv.FormatUint(uint64(req.ChainID), 10))
+	query.Set("sellToken", req.SellToken)
+	query.Set("buyToken", req.BuyToken)
+	query.Set("sellAmount", req.SellAmount.String())
+	query.Set("taker", req.Taker)
+	query.Set("slippageBps", strconv.Itoa(int(req.SlippageBps)))
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"/swap/allowance-holder/quote?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("0x-api-key", s.apiKey)
+	httpReq.Header.Set("0x-version", "v2")
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("0x returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		LiquidityAvailable bool   `json:"liquidityAvailable"`
+		BuyAmount          string `json:"buyAmount"`
+		MinBuyAmount       string `json:"minBuyAmount"`
+		Issues             struct {
+			Allowance *struct {
+				Spender string `json:"spender"`
+			} `json:"allowance"`
+		} `json:"issues"`
+		Route struct {
+			Fills []struct {
+				Source        string `json:"source"`
+				ProportionBps string `json:"proportionBps"`
+			} `json:"fills"`
+		} `json:"route"`
+		Transaction struct {
+			To    string `json:"to"`
+			Data  string `json:"data"`
+			Value string `json:"value"`
+			Gas   string `json:"gas"`
+		} `json:"transaction"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	if !body.LiquidityAvailable {
+		return nil, ErrNoRoute
+	}
+
+	buyAmount, ok := new(big.Int).SetString(body.BuyAmount, 10)
+	if !ok {
+		return nil, fmt.Errorf("unexpected 0x buyAmount %q", body.BuyAmount)
+	}
+	minBuyAmount, ok := new(big.Int).SetString(body.MinBuyAmount, 10)
+	if !ok {
+		minBuyAmount = minAmount(buyAmount, req.SlippageBps)
+	}
+	data, err := hexutil.Decode(body.Transaction.Data)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected 0x calldata: %w", err)
+	}
+	value, ok := new(big.Int).SetString(body.Transaction.Value, 10)
+	if !ok {
+		value = new(big.Int)
+	}
+	gas, _ := strconv.ParseUint(body.Transaction.Gas, 10, 64)
+
+	// allowance-holder模式下授权给报价返回的spender，没有时说明无需授权
+	spender := body.Transaction.To
+	if body.Issues.Allowance != nil && body.Issues.Allowance.Spender != "" {
+		spender = body.Issues.Allowance.Spender
+	}
+
+	route := make([]Fill, 0, len(body.Route.Fills))
+	for _, fill := range body.Route.Fills {
+		bps, _ := strconv.Atoi(fill.ProportionBps)
+		route = append(route, Fill{Source: fill.Source, Proportion: float64(bps) / 10000})
+	}
+
+	return &Quote{
+		Source:       s.Name(),
+		BuyAmount:    buyAmount,
+		MinBuyAmount: minBuyAmount,
+		To:           strings.ToLower(common.HexToAddress(body.Transaction.To).Hex()),
+		Spender:      strings.ToLower(common.HexToAddress(spender).Hex()),
+		Data:         data,
+		Value:        value,
+		Gas:          gas,
+		Route:        route,
+	}, nil
+}
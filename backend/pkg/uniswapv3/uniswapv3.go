@@ -0,0 +1,246 @@
+package uniswapv3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/chspring1/mya-platform/backend/pkg/blockchain"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var ErrPoolMismatch = errors.New("position does not belong to the pool")
+
+// 头寸读取用到的池和 NonfungiblePositionManager 方法
+var (
+	poolABI = mustParse(`[
+		{"type":"function","name":"slot0","stateMutability":"view","inputs":[],"outputs":[
+			{"name":"sqrtPriceX96","type":"uint160"},{"name":"tick","type":"int24"},
+			{"name":"observationIndex","type":"uint16"},{"name":"observationCardinality","type":"uint16"},
+			{"name":"observationCardinalityNext","type":"uint16"},{"name":"feeProtocol","type":"uint8"},
+			{"name":"unlocked","type":"bool"}]},
+		{"type":"function","name":"feeGrowthGlobal0X128","stateMutability":"view","inputs":[],"outputs":[{"type":"uint256"}]},
+		{"type":"function","name":"feeGrowthGlobal1X128","stateMutability":"view","inputs":[],"outputs":[{"type":"uint256"}]},
+		{"type":"function","name":"ticks","stateMutability":"view","inputs":[{"name":"tick","type":"int24"}],"outputs":[
+			{"name":"liquidityGross","type":"uint128"},{"name":"liquidityNet","type":"int128"},
+			{"name":"feeGrowthOutside0X128","type":"uint256"},{"name":"feeGrowthOutside1X128","type":"uint256"},
+			{"name":"tickCumulativeOutside","type":"int56"},{"name":"secondsPerLiquidityOutsideX128","type":"uint160"},
+			{"name":"secondsOutside","type":"uint32"},{"name":"initialized","type":"bool"}]},
+		{"type":"function","name":"token0","stateMutability":"view","inputs":[],"outputs":[{"type":"address"}]},
+		{"type":"function","name":"token1","stateMutability":"view","inputs":[],"outputs":[{"type":"address"}]},
+		{"type":"function","name":"fee","stateMutability":"view","inputs":[],"outputs":[{"type":"uint24"}]}
+	]`)
+	managerABI = mustParse(`[
+		{"type":"function","name":"positions","stateMutability":"view","inputs":[{"name":"tokenId","type":"uint256"}],"outputs":[
+			{"name":"nonce","type":"uint96"},{"name":"operator","type":"address"},
+			{"name":"token0","type":"address"},{"name":"token1","type":"address"},{"name":"fee","type":"uint24"},
+			{"name":"tickLower","type":"int24"},{"name":"tickUpper","type":"int24"},{"name":"liquidity","type":"uint128"},
+			{"name":"feeGrowthInside0LastX128","type":"uint256"},{"name":"feeGrowthInside1LastX128","type":"uint256"},
+			{"name":"tokensOwed0","type":"uint128"},{"name":"tokensOwed1","type":"uint128"}]}
+	]`)
+)
+
+var (
+	q96  = new(big.Int).Lsh(big.NewInt(1), 96)
+	q128 = new(big.Int).Lsh(big.NewInt(1), 128)
+	q256 = new(big.Int).Lsh(big.NewInt(1), 256)
+)
+
+// Position 头寸和所在池在同一区块上的状态。FeeGrowthInside 由池的全局和区间边界手续费增长计算，
+// 与头寸是否领取过手续费无关，两次读取之差乘以流动性即为期间赚取的手续费
+type Position struct {
+	BlockNumber          uint64
+	Token0               common.Address
+	Token1               common.Address
+	Fee                  uint32
+	TickLower            int32
+	TickUpper            int32
+	Liquidity            *big.Int
+	Tick                 int32
+	SqrtPriceX96         *big.Int
+	FeeGrowthInside0X128 *big.Int
+	FeeGrowthInside1X128 *big.Int
+}
+
+// InRange 当前价格是否在头寸区间内，只有区间内的流动性赚取手续费
+func (p *Position) InRange() bool {
+	return p.Tick >= p.TickLower && p.Tick < p.TickUpper
+}
+
+// Read 在最新区块上读取 NonfungiblePositionManager 中tokenID对应的头寸及池状态
+func Read(ctx context.Context, chainID uint, manager string, tokenID *big.Int, pool string) (*Position, error) {
+	block, err := blockchain.BlockNumber(ctx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	r := reader{ctx: ctx, chainID: chainID, block: block}
+
+	out, err := r.call(manager, managerABI, "positions", tokenID)
+	if err != nil {
+		return nil, fmt.Errorf("read position %s: %w", tokenID, err)
+	}
+	pos := &Position{
+		BlockNumber: block,
+		Token0:      out[2].(common.Address),
+		Token1:      out[3].(common.Address),
+		Fee:         uint32(toBig(out[4]).Uint64()),
+		TickLower:   int32(toBig(out[5]).Int64()),
+		TickUpper:   int32(toBig(out[6]).Int64()),
+		Liquidity:   toBig(out[7]),
+	}
+
+	if out, err = r.call(pool, poolABI, "slot0"); err != nil {
+		return nil, fmt.Errorf("read slot0: %w", err)
+	}
+	pos.SqrtPriceX96 = toBig(out[0])
+	pos.Tick = int32(toBig(out[1]).Int64())
+
+	var global [2]*big.Int
+	for i, method := range []string{"feeGrowthGlobal0X128", "feeGrowthGlobal1X128"} {
+		if out, err = r.call(pool, poolABI, method); err != nil {
+			return nil, fmt.Errorf("read %s: %w", method, err)
+		}
+		global[i] = toBig(out[0])
+	}
+	var outside [2][2]*big.Int // [lower/upper][token0/token1]
+	for i, tick := range []int32{pos.TickLower, pos.TickUpper} {
+		if out, err = r.call(pool, poolABI, "ticks", big.NewInt(int64(tick))); err != nil {
+			return nil, fmt.Errorf("read tick %d: %w", tick, err)
+		}
+		outside[i] = [2]*big.Int{toBig(out[2]), toBig(out[3])}
+	}
+	pos.FeeGrowthInside0X128 = feeGrowthInside(pos.Tick, pos.TickLower, pos.TickUpper, global[0], outside[0][0], outside[1][0])
+	pos.FeeGrowthInside1X128 = feeGrowthInside(pos.Tick, pos.TickLower, pos.TickUpper, global[1], outside[0][1], outside[1][1])
+	return pos, nil
+}
+
+// Verify 校验头寸的代币和费率与池一致，防止登记时填错池地址
+func Verify(ctx context.Context, chainID uint, pool string, pos *Position) error {
+	r := reader{ctx: ctx, chainID: chainID, block: pos.BlockNumber}
+	var tokens [2]common.Address
+	for i, method := range []string{"token0", "token1"} {
+		out, err := r.call(pool, poolABI, method)
+		if err != nil {
+			return fmt.Errorf("read pool %s: %w", method, err)
+		}
+		tokens[i] = out[0].(common.Address)
+	}
+	out, err := r.call(pool, poolABI, "fee")
+	if err != nil {
+		return fmt.Errorf("read pool fee: %w", err)
+	}
+	if tokens[0] != pos.Token0 || tokens[1] != pos.Token1 || uint32(toBig(out[0]).Uint64()) != pos.Fee {
+		return fmt.Errorf("%w: position is %s/%s at fee %d", ErrPoolMismatch, pos.Token0.Hex(), pos.Token1.Hex(), pos.Fee)
+	}
+	return nil
+}
+
+// FeesEarned 两次读取之间流动性liquidity赚取的手续费(代币最小单位)
+func FeesEarned(liquidity, growthBefore, growthAfter *big.Int) *big.Int {
+	delta := sub256(growthAfter, growthBefore)
+	return new(big.Int).Div(new(big.Int).Mul(liquidity, delta), q128)
+}
+
+// Amounts 头寸在当前价格下对应的两种代币数量(最小单位)
+func Amounts(pos *Position) (float64, float64) {
+	liquidity, _ := new(big.Float).SetInt(pos.Liquidity).Float64()
+	sqrtPrice, _ := new(big.Float).Quo(new(big.Float).SetInt(pos.SqrtPriceX96), new(big.Float).SetInt(q96)).Float64()
+	sqrtLower := math.Pow(1.0001, float64(pos.TickLower)/2)
+	sqrtUpper := math.Pow(1.0001, float64(pos.TickUpper)/2)
+
+	switch {
+	case sqrtPrice <= sqrtLower:
+		return liquidity * (1/sqrtLower - 1/sqrtUpper), 0
+	case sqrtPrice >= sqrtUpper:
+		return 0, liquidity * (sqrtUpper - sqrtLower)
+	}
+	return liquidity * (1/sqrtPrice - 1/sqrtUpper), liquidity * (sqrtPrice - sqrtLower)
+}
+
+// TickPrice tick对应的价格，以token1计价的1个token0，已按两种代币的精度换算
+func TickPrice(tick int32, decimals0, decimals1 int32) float64 {
+	return math.Pow(1.0001, float64(tick)) * math.Pow10(int(decimals0-decimals1))
+}
+
+// Price 池当前价格，口径同 TickPrice
+func Price(pos *Position, decimals0, decimals1 int32) float64 {
+	sqrtPrice, _ := new(big.Float).Quo(new(big.Float).SetInt(pos.SqrtPriceX96), new(big.Float).SetInt(q96)).Float64()
+	return sqrtPrice * sqrtPrice * math.Pow10(int(decimals0-decimals1))
+}
+
+// feeGrowthInside 区间内每单位流动性累计的手续费：全局增长减去区间下方和上方的增长，按uint256溢出语义计算
+func feeGrowthInside(tick, lower, upper int32, global, outsideLower, outsideUpper *big.Int) *big.Int {
+	below := outsideLower
+	if tick < lower {
+		below = sub256(global, outsideLower)
+	}
+	above := outsideUpper
+	if tick >= upper {
+		above = sub256(global, outsideUpper)
+	}
+	return sub256(sub256(global, below), above)
+}
+
+func sub256(a, b *big.Int) *big.Int {
+	diff := new(big.Int).Sub(a, b)
+	if diff.Sign() < 0 {
+		diff.Add(diff, q256)
+	}
+	return diff
+}
+
+type reader struct {
+	ctx     context.Context
+	chainID uint
+	block   uint64
+}
+
+func (r reader) call(to string, contract abi.ABI, method string, args ...interface{}) ([]interface{}, error) {
+	data, err := contract.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	out, err := blockchain.CallAt(r.ctx, r.chainID, to, data, r.block)
+	if err != nil {
+		return nil, err
+	}
+	return contract.Unpack(method, out)
+}
+
+// toBig ABI解码出的整数，非标准位宽(int24、uint128等)解码为*big.Int，其余为Go整数类型
+func toBig(value interface{}) *big.Int {
+	switch v := value.(type) {
+	case *big.Int:
+		return v
+	case uint8:
+		return new(big.Int).SetUint64(uint64(v))
+	case uint16:
+		return new(big.Int).SetUint64(uint64(v))
+	case uint32:
+		return new(big.Int).SetUint64(uint64(v))
+	case uint64:
+		return new(big.Int).SetUint64(v)
+	case int8:
+		return big.NewInt(int64(v))
+	case int16:
+		return big.NewInt(int64(v))
+	case int32:
+		return big.NewInt(int64(v))
+	case int64:
+		return big.NewInt(v)
+	}
+	return new(big.Int)
+}
+
+func mustParse(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(fmt.Sprintf("uniswap v3 ABI: %v", err))
+	}
+	return parsed
+}
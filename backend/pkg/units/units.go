@@ -0,0 +1,81 @@
+// Package units 代币数量在最小单位(wei)和按精度换算的十进制数量之间的转换。
+//
+// 链上调用和事件中的数量为最小单位的整数，API和数据库中为 decimal.Decimal。两者的换算只在这里进行，
+// 精度取自代币登记表或链上 decimals()，全程不经过浮点数；超出代币精度的小数直接拒绝，不做舍入
+package units
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/shopspring/decimal"
+)
+
+// maxBaseDigits uint256 最大值的十进制位数
+const maxBaseDigits = 78
+
+var (
+	ErrTooPrecise  = errors.New("amount has more decimal places than the token supports")
+	ErrInvalidBase = errors.New("base unit amount must be a non-negative integer string")
+)
+
+// Amount 同一数量的两种表示，Base 为最小单位
+type Amount struct {
+	Value    decimal.Decimal
+	Base     *big.Int
+	Decimals int32
+}
+
+// FromValue 把十进制数量换算为最小单位，小数位超过精度时返回 ErrTooPrecise
+func FromValue(value decimal.Decimal, decimals int32) (*Amount, error) {
+	base, err := ToBase(value, decimals)
+	if err != nil {
+		return nil, err
+	}
+	return &Amount{Value: value, Base: base, Decimals: decimals}, nil
+}
+
+// FromBaseString 解析最小单位的十进制整数字符串并按精度换算
+func FromBaseString(raw string, decimals int32) (*Amount, error) {
+	base, err := ParseBase(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &Amount{Value: FromBase(base, decimals), Base: base, Decimals: decimals}, nil
+}
+
+// BaseString 最小单位的十进制字符串，API返回的 *_wei 字段
+func (a *Amount) BaseString() string {
+	return a.Base.String()
+}
+
+// ToBase 十进制数量对应的最小单位整数
+func ToBase(value decimal.Decimal, decimals int32) (*big.Int, error) {
+	shifted := value.Shift(decimals)
+	if !shifted.Equal(shifted.Truncate(0)) {
+		return nil, ErrTooPrecise
+	}
+	return shifted.BigInt(), nil
+}
+
+// FromBase 最小单位整数对应的十进制数量
+func FromBase(base *big.Int, decimals int32) decimal.Decimal {
+	return decimal.NewFromBigInt(base, -decimals)
+}
+
+// ParseBase 解析最小单位的十进制整数字符串，只接受数字，不接受符号、小数点和科学计数法
+func ParseBase(raw string) (*big.Int, error) {
+	if raw == "" || len(raw) > maxBaseDigits {
+		return nil, ErrInvalidBase
+	}
+	for _, r := range raw {
+		if r < '0' || r > '9' {
+			return nil, ErrInvalidBase
+		}
+	}
+	base, ok := new(big.Int).SetString(raw, 10)
+	if !ok || base.BitLen() > 256 {
+		return nil, ErrInvalidBase
+	}
+	return base, nil
+}
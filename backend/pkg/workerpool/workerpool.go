@@ -0,0 +1,54 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ForEach 用最多workers个goroutine并发处理items，返回所有失败项合并后的错误。
+// 单项失败不影响其他项；ctx取消后不再分发新的项
+func ForEach[T any](ctx context.Context, workers int, items []T, fn func(ctx context.Context, item T) error) error {
+	if workers <= 0 {
+		workers = 1
+	}
+	if workers > len(items) {
+		workers = len(items)
+	}
+
+	queue := make(chan T)
+	var (
+		wg    sync.WaitGroup
+		mutex sync.Mutex
+		errs  []error
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range queue {
+				if err := fn(ctx, item); err != nil {
+					mutex.Lock()
+					errs = append(errs, err)
+					mutex.Unlock()
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, item := range items {
+		select {
+		case <-ctx.Done():
+			mutex.Lock()
+			errs = append(errs, ctx.Err())
+			mutex.Unlock()
+			break feed
+		case queue <- item:
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	return errors.Join(errs...)
+}